@@ -0,0 +1,198 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package safehttp
+
+import (
+	"errors"
+	"io"
+	"sync"
+	"time"
+)
+
+// ErrBodyTooLarge is returned by the readers created with
+// IncomingRequest.LimitedBody when the body exceeds the maximum size.
+var ErrBodyTooLarge = errors.New("safehttp: request body too large")
+
+// ErrBodyReadTimeout is returned by the readers created with
+// IncomingRequest.LimitedBody when the body isn't read in time.
+var ErrBodyReadTimeout = errors.New("safehttp: request body read timeout")
+
+// BodyLimits are the limits enforced by IncomingRequest.LimitedBody.
+type BodyLimits struct {
+	// MaxBytes is the maximum size of the body. It must be positive.
+	MaxBytes int64
+	// ReadTimeout is the time allowed to read the whole body, starting when
+	// LimitedBody is called. If zero, there's no timeout.
+	ReadTimeout time.Duration
+}
+
+// LimitedBody returns a reader of the request body that enforces the given
+// limits. Once a limit is exceeded, reads return ErrBodyTooLarge or
+// ErrBodyReadTimeout. If the handler then returns without writing a response,
+// a 413 Request Entity Too Large or a 408 Request Timeout error is written
+// instead of the default 204 No Content, e.g.
+//
+//	body := r.LimitedBody(safehttp.BodyLimits{MaxBytes: 1 << 20})
+//	if _, err := io.Copy(dst, body); err != nil {
+//		return safehttp.NotWritten()
+//	}
+//
+// Bodies announcing a Content-Length larger than MaxBytes are rejected
+// without being read. A read still blocked when the timeout expires keeps the
+// connection busy until it completes or the server's ReadTimeout expires.
+//
+// LimitedBody panics if MaxBytes isn't positive.
+func (r *IncomingRequest) LimitedBody(l BodyLimits) io.ReadCloser {
+	if l.MaxBytes <= 0 {
+		panic("LimitedBody requires a positive MaxBytes")
+	}
+	b := &limitedBody{
+		body:      r.req.Body,
+		remaining: l.MaxBytes,
+		status:    r.bodyStatus,
+	}
+	if l.ReadTimeout > 0 {
+		b.deadline = time.Now().Add(l.ReadTimeout)
+	}
+	if r.req.ContentLength > l.MaxBytes {
+		b.fail(ErrBodyTooLarge)
+	}
+	return b
+}
+
+// bodyStatus records the first limit exceeded while reading the body, shared
+// by the copies of an IncomingRequest.
+type bodyStatus struct {
+	mu  sync.Mutex
+	err error
+}
+
+func (s *bodyStatus) set(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err == nil {
+		s.err = err
+	}
+}
+
+func (s *bodyStatus) get() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
+
+// bodyErrorStatus returns the error response written when a limit of
+// LimitedBody or the MinTransferRate of the Server was exceeded and the
+// handler didn't write a response.
+func bodyErrorStatus(err error) StatusCode {
+	if err == ErrBodyReadTimeout || err == ErrBodyTooSlow {
+		return StatusRequestTimeout
+	}
+	return StatusRequestEntityTooLarge
+}
+
+type limitedBody struct {
+	body      io.ReadCloser
+	remaining int64
+	deadline  time.Time
+	status    *bodyStatus
+	// err is returned by all the reads after a limit was exceeded.
+	err error
+	// buf is reused by the reads with a deadline.
+	buf []byte
+}
+
+type readResult struct {
+	n   int
+	err error
+}
+
+func (b *limitedBody) fail(err error) {
+	b.err = err
+	b.status.set(err)
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if b.err != nil {
+		return 0, b.err
+	}
+	if len(p) == 0 {
+		return 0, nil
+	}
+	// Read one more byte than allowed to detect bodies that are too large.
+	if int64(len(p)) > b.remaining+1 {
+		p = p[:b.remaining+1]
+	}
+	n, err := b.read(p)
+	if err == ErrBodyReadTimeout {
+		b.fail(err)
+		return 0, err
+	}
+	b.remaining -= int64(n)
+	if b.remaining < 0 {
+		b.fail(ErrBodyTooLarge)
+		return n - 1, ErrBodyTooLarge
+	}
+	return n, err
+}
+
+// read reads from the body, giving up when the deadline expires.
+func (b *limitedBody) read(p []byte) (int, error) {
+	if b.deadline.IsZero() {
+		return b.body.Read(p)
+	}
+	n, err := readBefore(b.body, p, &b.buf, b.deadline)
+	if err == errDeadlineExpired {
+		return 0, ErrBodyReadTimeout
+	}
+	return n, err
+}
+
+// errDeadlineExpired is returned by readBefore when the deadline expires.
+var errDeadlineExpired = errors.New("deadline expired")
+
+// readBefore reads from r into p, giving up with errDeadlineExpired when the
+// deadline expires. The read is done in buf, which is reused by the next calls
+// unless the deadline expired: the pending read then still owns it, so r must
+// not be read anymore.
+func readBefore(r io.Reader, p []byte, buf *[]byte, deadline time.Time) (int, error) {
+	d := time.Until(deadline)
+	if d <= 0 {
+		return 0, errDeadlineExpired
+	}
+	if cap(*buf) < len(p) {
+		*buf = make([]byte, len(p))
+	}
+	b := (*buf)[:len(p)]
+	ch := make(chan readResult, 1)
+	go func() {
+		n, err := r.Read(b)
+		ch <- readResult{n: n, err: err}
+	}()
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case res := <-ch:
+		copy(p, b[:res.n])
+		return res.n, res.err
+	case <-t.C:
+		*buf = nil
+		return 0, errDeadlineExpired
+	}
+}
+
+func (b *limitedBody) Close() error {
+	return b.body.Close()
+}
@@ -0,0 +1,159 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package safehttp_test
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-safeweb/safehttp"
+	"github.com/google/go-safeweb/safehttp/safehttptest"
+	"github.com/google/safehtml"
+)
+
+func TestLimitedBody(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		maxBytes int64
+		want     string
+		wantErr  error
+	}{
+		{
+			name:     "Within limit",
+			body:     "hello",
+			maxBytes: 10,
+			want:     "hello",
+		},
+		{
+			name:     "Exact limit",
+			body:     "hello",
+			maxBytes: 5,
+			want:     "hello",
+		},
+		{
+			name:     "Too large",
+			body:     "hello world",
+			maxBytes: 5,
+			want:     "hello",
+			wantErr:  safehttp.ErrBodyTooLarge,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Hide the Content-Length to test the limit while reading.
+			r := safehttptest.NewRequest(safehttp.MethodPost, "/", ioutil.NopCloser(strings.NewReader(tt.body)))
+			got, err := ioutil.ReadAll(r.LimitedBody(safehttp.BodyLimits{MaxBytes: tt.maxBytes}))
+			if err != tt.wantErr {
+				t.Errorf("ioutil.ReadAll() got err %v, want %v", err, tt.wantErr)
+			}
+			if string(got) != tt.want {
+				t.Errorf("ioutil.ReadAll() got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLimitedBodyContentLength(t *testing.T) {
+	r := safehttptest.NewRequest(safehttp.MethodPost, "/", strings.NewReader("hello world"))
+	got, err := ioutil.ReadAll(r.LimitedBody(safehttp.BodyLimits{MaxBytes: 5}))
+	if err != safehttp.ErrBodyTooLarge {
+		t.Errorf("ioutil.ReadAll() got err %v, want %v", err, safehttp.ErrBodyTooLarge)
+	}
+	if len(got) != 0 {
+		t.Errorf("ioutil.ReadAll() got %q, want nothing read", got)
+	}
+}
+
+func TestLimitedBodyInvalidMaxBytes(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Error("r.LimitedBody() expected panic")
+		}
+	}()
+	safehttptest.NewRequest(safehttp.MethodPost, "/", nil).LimitedBody(safehttp.BodyLimits{})
+}
+
+func TestLimitedBodyAutomaticErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       func() (io.Reader, func())
+		write      bool
+		wantStatus safehttp.StatusCode
+	}{
+		{
+			name: "Within limit",
+			body: func() (io.Reader, func()) {
+				return strings.NewReader("hello"), func() {}
+			},
+			wantStatus: safehttp.StatusNoContent,
+		},
+		{
+			name: "Too large",
+			body: func() (io.Reader, func()) {
+				return ioutil.NopCloser(strings.NewReader("hello world")), func() {}
+			},
+			wantStatus: safehttp.StatusRequestEntityTooLarge,
+		},
+		{
+			name: "Too large, handler writes",
+			body: func() (io.Reader, func()) {
+				return strings.NewReader("hello world"), func() {}
+			},
+			write:      true,
+			wantStatus: safehttp.StatusOK,
+		},
+		{
+			name: "Timeout",
+			body: func() (io.Reader, func()) {
+				pr, pw := io.Pipe()
+				return pr, func() { pw.Close() }
+			},
+			wantStatus: safehttp.StatusRequestTimeout,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var readErr error
+			mux := safehttp.NewServeMuxConfig(nil).Mux()
+			mux.Handle("/", safehttp.MethodPost, safehttp.HandlerFunc(func(w safehttp.ResponseWriter, r *safehttp.IncomingRequest) safehttp.Result {
+				_, readErr = ioutil.ReadAll(r.LimitedBody(safehttp.BodyLimits{MaxBytes: 5, ReadTimeout: 10 * time.Millisecond}))
+				if tt.write {
+					return w.Write(safehtml.HTMLEscaped("ok"))
+				}
+				return safehttp.NotWritten()
+			}))
+
+			body, cleanup := tt.body()
+			defer cleanup()
+			rw := httptest.NewRecorder()
+			mux.ServeHTTP(rw, httptest.NewRequest(safehttp.MethodPost, "http://foo.com/", body))
+
+			if got, want := rw.Code, int(tt.wantStatus); got != want {
+				t.Errorf("rw.Code: got %v want %v", got, want)
+			}
+			if tt.wantStatus == safehttp.StatusRequestTimeout && !errors.Is(readErr, safehttp.ErrBodyReadTimeout) {
+				t.Errorf("read err: got %v want %v", readErr, safehttp.ErrBodyReadTimeout)
+			}
+		})
+	}
+}
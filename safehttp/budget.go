@@ -0,0 +1,154 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package safehttp
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// RequestBudget limits the resources used by the requests of a Server, so
+// that it degrades gracefully when it's overloaded instead of slowing down
+// every request.
+//
+// The requests over MaxInFlight wait in a queue. The requests which can't be
+// queued or which wait longer than QueueTimeout are shed: they're answered
+// with 503 Service Unavailable and a Retry-After header by the Dispatcher of
+// the Mux, without running the interceptors.
+type RequestBudget struct {
+	// MaxInFlight, if positive, is the maximum number of requests handled
+	// concurrently.
+	MaxInFlight int
+	// MaxQueued is the maximum number of requests waiting for one of the
+	// MaxInFlight requests to complete. If zero, the requests over
+	// MaxInFlight are shed immediately.
+	MaxQueued int
+	// QueueTimeout is the maximum time a request waits in the queue. If zero,
+	// one second is used.
+	QueueTimeout time.Duration
+	// RetryAfter is the duration of the Retry-After header of the shed
+	// requests, rounded up to seconds. If zero, one second is used.
+	RetryAfter time.Duration
+
+	// MaxBodyBytes, if positive, is the maximum size of the request bodies,
+	// enforced as by IncomingRequest.LimitedBody.
+	MaxBodyBytes int64
+
+	// OnAdmit, if non-nil, is called when a request is admitted, with the time
+	// it waited in the queue and the number of requests in flight, including
+	// it. It can be used to export metrics and must not block.
+	OnAdmit func(wait time.Duration, inFlight int)
+	// OnShed, if non-nil, is called when a request is shed. queueFull reports
+	// whether the queue was full, rather than the request timing out in the
+	// queue. It can be used to export metrics and must not block.
+	OnShed func(queueFull bool)
+}
+
+const defaultBudgetTimeout = time.Second
+
+type maxBodyBytesCtxKey struct{}
+
+// budget enforces a RequestBudget.
+type budget struct {
+	cfg        RequestBudget
+	slots      chan struct{}
+	queued     int32
+	retryAfter string
+}
+
+func newBudget(cfg RequestBudget) *budget {
+	b := &budget{cfg: cfg}
+	if b.cfg.QueueTimeout == 0 {
+		b.cfg.QueueTimeout = defaultBudgetTimeout
+	}
+	if b.cfg.RetryAfter == 0 {
+		b.cfg.RetryAfter = defaultBudgetTimeout
+	}
+	b.retryAfter = strconv.Itoa(int((b.cfg.RetryAfter + time.Second - 1) / time.Second))
+	if cfg.MaxInFlight > 0 {
+		b.slots = make(chan struct{}, cfg.MaxInFlight)
+	}
+	return b
+}
+
+// acquire waits for a slot to handle a request. If it returns true, release
+// must be called once the request was handled.
+func (b *budget) acquire(ctx context.Context) bool {
+	start := time.Now()
+	select {
+	case b.slots <- struct{}{}:
+		b.admitted(start)
+		return true
+	default:
+	}
+	if int(atomic.AddInt32(&b.queued, 1)) > b.cfg.MaxQueued {
+		atomic.AddInt32(&b.queued, -1)
+		b.shed(true)
+		return false
+	}
+	defer atomic.AddInt32(&b.queued, -1)
+	t := time.NewTimer(b.cfg.QueueTimeout)
+	defer t.Stop()
+	select {
+	case b.slots <- struct{}{}:
+		b.admitted(start)
+		return true
+	case <-t.C:
+	case <-ctx.Done():
+	}
+	b.shed(false)
+	return false
+}
+
+func (b *budget) release() {
+	<-b.slots
+}
+
+func (b *budget) admitted(start time.Time) {
+	if b.cfg.OnAdmit != nil {
+		b.cfg.OnAdmit(time.Since(start), len(b.slots))
+	}
+}
+
+func (b *budget) shed(queueFull bool) {
+	if b.cfg.OnShed != nil {
+		b.cfg.OnShed(queueFull)
+	}
+}
+
+// budgetHandler wraps the handler of the server to enforce its Budget.
+func (s *Server) budgetHandler(h http.Handler) http.Handler {
+	if s.Budget == nil || s.Budget.MaxInFlight <= 0 && s.Budget.MaxBodyBytes <= 0 {
+		return h
+	}
+	b := newBudget(*s.Budget)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if b.slots != nil {
+			if !b.acquire(r.Context()) {
+				w.Header().Set("Retry-After", b.retryAfter)
+				s.Mux.serveError(w, r, StatusServiceUnavailable)
+				return
+			}
+			defer b.release()
+		}
+		if b.cfg.MaxBodyBytes > 0 && r.Body != nil && r.Body != http.NoBody {
+			r = r.WithContext(context.WithValue(r.Context(), maxBodyBytesCtxKey{}, b.cfg.MaxBodyBytes))
+		}
+		h.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,199 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package safehttp
+
+import (
+	"context"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/safehtml"
+)
+
+func TestBudgetQueue(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		sheds []bool
+		waits []time.Duration
+	)
+	b := newBudget(RequestBudget{
+		MaxInFlight:  1,
+		MaxQueued:    1,
+		QueueTimeout: 50 * time.Millisecond,
+		OnAdmit: func(wait time.Duration, inFlight int) {
+			mu.Lock()
+			defer mu.Unlock()
+			waits = append(waits, wait)
+			if inFlight != 1 {
+				t.Errorf("inFlight: got %v want 1", inFlight)
+			}
+		},
+		OnShed: func(queueFull bool) {
+			mu.Lock()
+			defer mu.Unlock()
+			sheds = append(sheds, queueFull)
+		},
+	})
+	ctx := context.Background()
+
+	if !b.acquire(ctx) {
+		t.Fatal("first acquire: got false, want true")
+	}
+	// The queue is empty: the request waits and times out.
+	if b.acquire(ctx) {
+		t.Fatal("acquire with a full budget: got true, want false")
+	}
+
+	// A queued request is admitted once the slot is released.
+	admitted := make(chan bool)
+	go func() { admitted <- b.acquire(ctx) }()
+	for atomic.LoadInt32(&b.queued) != 1 {
+		time.Sleep(time.Millisecond)
+	}
+	// The queue is full: the request is shed immediately.
+	if b.acquire(ctx) {
+		t.Fatal("acquire with a full queue: got true, want false")
+	}
+	b.release()
+	if !<-admitted {
+		t.Fatal("queued acquire: got false, want true")
+	}
+	b.release()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if want := []bool{false, true}; len(sheds) != 2 || sheds[0] != want[0] || sheds[1] != want[1] {
+		t.Errorf("OnShed calls: got %v want %v", sheds, want)
+	}
+	if len(waits) != 2 || waits[1] == 0 {
+		t.Errorf("OnAdmit waits: got %v, want two with the second non-zero", waits)
+	}
+}
+
+func TestBudgetQueueContextDone(t *testing.T) {
+	b := newBudget(RequestBudget{MaxInFlight: 1, MaxQueued: 1, QueueTimeout: time.Hour})
+	b.acquire(context.Background())
+	defer b.release()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if b.acquire(ctx) {
+		t.Error("acquire with a done context: got true, want false")
+	}
+}
+
+func TestServerBudget(t *testing.T) {
+	mux := NewServeMuxConfig(nil).Mux()
+	block := make(chan struct{})
+	started := make(chan struct{})
+	mux.Handle("/block", MethodGet, HandlerFunc(func(w ResponseWriter, r *IncomingRequest) Result {
+		close(started)
+		<-block
+		return w.Write(safehtml.HTMLEscaped("response"))
+	}))
+	mux.Handle("/", MethodGet, HandlerFunc(func(w ResponseWriter, r *IncomingRequest) Result {
+		return w.Write(safehtml.HTMLEscaped("response"))
+	}))
+	mux.Handle("/upload", MethodPost, HandlerFunc(func(w ResponseWriter, r *IncomingRequest) Result {
+		if _, err := ioutil.ReadAll(r.Body()); err != nil {
+			return NotWritten()
+		}
+		return w.Write(safehtml.HTMLEscaped("uploaded"))
+	}))
+	s := &Server{
+		Mux: mux,
+		Budget: &RequestBudget{
+			MaxInFlight:  1,
+			RetryAfter:   1500 * time.Millisecond,
+			MaxBodyBytes: 5,
+		},
+	}
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("net.Listen: %v", err)
+	}
+	go s.Serve(l)
+	defer s.Close()
+	url := "http://" + l.Addr().String()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		resp, err := http.Get(url + "/block")
+		if err != nil {
+			t.Errorf("http.Get: %v", err)
+			return
+		}
+		resp.Body.Close()
+	}()
+	<-started
+
+	resp, err := http.Get(url + "/")
+	if err != nil {
+		t.Fatalf("http.Get: %v", err)
+	}
+	resp.Body.Close()
+	if got, want := resp.StatusCode, http.StatusServiceUnavailable; got != want {
+		t.Errorf("resp.StatusCode over budget: got %v want %v", got, want)
+	}
+	if got, want := resp.Header.Get("Retry-After"), "2"; got != want {
+		t.Errorf("Retry-After: got %q want %q", got, want)
+	}
+	close(block)
+	<-done
+
+	tests := []struct {
+		name string
+		req  func() (*http.Response, error)
+		want int
+	}{
+		{
+			name: "Within budget",
+			req:  func() (*http.Response, error) { return http.Get(url + "/") },
+			want: http.StatusOK,
+		},
+		{
+			name: "Small body",
+			req: func() (*http.Response, error) {
+				return http.Post(url+"/upload", "text/plain", strings.NewReader("abc"))
+			},
+			want: http.StatusOK,
+		},
+		{
+			name: "Body too large",
+			req: func() (*http.Response, error) {
+				return http.Post(url+"/upload", "text/plain", strings.NewReader("abcdef"))
+			},
+			want: http.StatusRequestEntityTooLarge,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := tt.req()
+			if err != nil {
+				t.Fatalf("request: %v", err)
+			}
+			resp.Body.Close()
+			if got := resp.StatusCode; got != tt.want {
+				t.Errorf("resp.StatusCode: got %v want %v", got, tt.want)
+			}
+		})
+	}
+}
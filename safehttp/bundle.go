@@ -0,0 +1,122 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package safehttp
+
+// Bundle composes several interceptors into one. Installing the bundle is
+// equivalent to installing its members individually, in the given order: their
+// Before phases run in order, their Commit phases run in reverse order and each
+// member receives the InterceptorConfig it matches.
+//
+// Bundles can be used to define a named set of interceptors (e.g. the defaults
+// of a web application) and install it with a single call. Bundles can be
+// nested.
+func Bundle(interceptors ...Interceptor) Interceptor {
+	return &bundle{members: flattenInterceptors(interceptors)}
+}
+
+type bundle struct {
+	members []Interceptor
+}
+
+// flattenInterceptors replaces the bundles in the list with their members, so
+// that each member is configured individually by the ServeMux.
+func flattenInterceptors(interceptors []Interceptor) []Interceptor {
+	var flat []Interceptor
+	for _, it := range interceptors {
+		if b, ok := it.(*bundle); ok {
+			flat = append(flat, b.members...)
+			continue
+		}
+		flat = append(flat, it)
+	}
+	return flat
+}
+
+// Before runs the Before phases of the members in order, stopping after the
+// first one that writes a response.
+func (b *bundle) Before(w ResponseWriter, r *IncomingRequest, cfg InterceptorConfig) Result {
+	bw := &bundleWriter{ResponseWriter: w}
+	for _, it := range b.members {
+		it.Before(bw, r, memberConfig(it, cfg))
+		if bw.written {
+			break
+		}
+	}
+	return Result{}
+}
+
+// Commit runs the Commit phases of the members in reverse order, stopping if
+// one of them replaces the response.
+func (b *bundle) Commit(w ResponseHeadersWriter, r *IncomingRequest, resp Response, cfg InterceptorConfig) {
+	var bw *bundleWriter
+	if rw, ok := w.(ResponseWriter); ok {
+		bw = &bundleWriter{ResponseWriter: rw}
+		w = bw
+	}
+	for i := len(b.members) - 1; i >= 0; i-- {
+		b.members[i].Commit(w, r, resp, memberConfig(b.members[i], cfg))
+		if bw != nil && bw.written {
+			return
+		}
+	}
+}
+
+// OnError runs the OnError hooks of the members that are ErrorObservers, in
+// reverse order, stopping if one of them replaces the response.
+func (b *bundle) OnError(w ResponseWriter, r *IncomingRequest, err error) {
+	bw := &bundleWriter{ResponseWriter: w}
+	for i := len(b.members) - 1; i >= 0; i-- {
+		if o, ok := b.members[i].(ErrorObserver); ok {
+			o.OnError(bw, r, err)
+			if bw.written {
+				return
+			}
+		}
+	}
+}
+
+// Match returns true if any of the members matches the configuration.
+func (b *bundle) Match(cfg InterceptorConfig) bool {
+	for _, it := range b.members {
+		if it.Match(cfg) {
+			return true
+		}
+	}
+	return false
+}
+
+// memberConfig returns cfg if the member matches it and nil otherwise.
+func memberConfig(it Interceptor, cfg InterceptorConfig) InterceptorConfig {
+	if cfg != nil && it.Match(cfg) {
+		return cfg
+	}
+	return nil
+}
+
+// bundleWriter records whether a member of a bundle wrote a response.
+type bundleWriter struct {
+	ResponseWriter
+	written bool
+}
+
+func (w *bundleWriter) Write(resp Response) Result {
+	w.written = true
+	return w.ResponseWriter.Write(resp)
+}
+
+func (w *bundleWriter) WriteError(resp ErrorResponse) Result {
+	w.written = true
+	return w.ResponseWriter.WriteError(resp)
+}
@@ -0,0 +1,191 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package safehttp_test
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-safeweb/safehttp"
+	"github.com/google/go-safeweb/safehttp/safehttptest"
+	"github.com/google/safehtml"
+)
+
+type recordingConfig struct {
+	name, value string
+}
+
+// recordingInterceptor records its phases in log. If reject is set, Before
+// writes a 403 Forbidden response.
+type recordingInterceptor struct {
+	name   string
+	reject bool
+	log    *[]string
+}
+
+func (it recordingInterceptor) Before(w safehttp.ResponseWriter, r *safehttp.IncomingRequest, cfg safehttp.InterceptorConfig) safehttp.Result {
+	entry := "before " + it.name
+	if c, ok := cfg.(recordingConfig); ok {
+		entry += " " + c.value
+	}
+	*it.log = append(*it.log, entry)
+	if it.reject {
+		return w.WriteError(safehttp.StatusForbidden)
+	}
+	return safehttp.NotWritten()
+}
+
+func (it recordingInterceptor) Commit(w safehttp.ResponseHeadersWriter, r *safehttp.IncomingRequest, resp safehttp.Response, cfg safehttp.InterceptorConfig) {
+	*it.log = append(*it.log, "commit "+it.name)
+	w.Header().Add("Commit", it.name)
+}
+
+func (it recordingInterceptor) Match(cfg safehttp.InterceptorConfig) bool {
+	c, ok := cfg.(recordingConfig)
+	return ok && c.name == it.name
+}
+
+func serveRecorded(t *testing.T, install func(mb *safehttp.ServeMuxConfig, log *[]string), cfgs ...safehttp.InterceptorConfig) ([]string, *httptest.ResponseRecorder) {
+	t.Helper()
+	var log []string
+	mb := safehttp.NewServeMuxConfig(nil)
+	install(mb, &log)
+	mux := mb.Mux()
+	mux.Handle("/", safehttp.MethodGet, safehttp.HandlerFunc(func(w safehttp.ResponseWriter, r *safehttp.IncomingRequest) safehttp.Result {
+		log = append(log, "handler")
+		return w.Write(safehtml.HTMLEscaped("hello"))
+	}), cfgs...)
+
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, httptest.NewRequest(safehttp.MethodGet, "http://foo.com/", nil))
+	return log, rr
+}
+
+func TestBundleMatchesIndividualInstallation(t *testing.T) {
+	tests := []struct {
+		name     string
+		rejectB  bool
+		cfgs     []safehttp.InterceptorConfig
+		wantLog  []string
+		wantCode int
+	}{
+		{
+			name: "no short-circuit",
+			wantLog: []string{
+				"before a", "before b", "before c", "before d",
+				"handler",
+				"commit d", "commit c", "commit b", "commit a",
+			},
+			wantCode: int(safehttp.StatusOK),
+		},
+		{
+			name:    "short-circuit in bundle",
+			rejectB: true,
+			wantLog: []string{
+				"before a", "before b",
+				"commit d", "commit c", "commit b", "commit a",
+			},
+			wantCode: int(safehttp.StatusForbidden),
+		},
+		{
+			name: "configs",
+			cfgs: []safehttp.InterceptorConfig{
+				recordingConfig{name: "b", value: "x"},
+				recordingConfig{name: "c", value: "y"},
+			},
+			wantLog: []string{
+				"before a", "before b x", "before c y", "before d",
+				"handler",
+				"commit d", "commit c", "commit b", "commit a",
+			},
+			wantCode: int(safehttp.StatusOK),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			interceptors := func(log *[]string) (a, b, c, d safehttp.Interceptor) {
+				return recordingInterceptor{name: "a", log: log},
+					recordingInterceptor{name: "b", reject: tt.rejectB, log: log},
+					recordingInterceptor{name: "c", log: log},
+					recordingInterceptor{name: "d", log: log}
+			}
+			individualLog, individualRR := serveRecorded(t, func(mb *safehttp.ServeMuxConfig, log *[]string) {
+				a, b, c, d := interceptors(log)
+				mb.Intercept(a, b, c, d)
+			}, tt.cfgs...)
+			bundleLog, bundleRR := serveRecorded(t, func(mb *safehttp.ServeMuxConfig, log *[]string) {
+				a, b, c, d := interceptors(log)
+				mb.Intercept(a, safehttp.Bundle(b, safehttp.Bundle(c)), d)
+			}, tt.cfgs...)
+
+			if diff := cmp.Diff(tt.wantLog, individualLog); diff != "" {
+				t.Errorf("individual installation log mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(individualLog, bundleLog); diff != "" {
+				t.Errorf("bundle log mismatch (-individual +bundle):\n%s", diff)
+			}
+			if got := bundleRR.Code; got != tt.wantCode {
+				t.Errorf("bundleRR.Code got: %v want: %v", got, tt.wantCode)
+			}
+			if diff := cmp.Diff(individualRR.Header(), bundleRR.Header()); diff != "" {
+				t.Errorf("bundleRR.Header() mismatch (-individual +bundle):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestBundleShortCircuit(t *testing.T) {
+	var log []string
+	b := safehttp.Bundle(
+		recordingInterceptor{name: "a", log: &log},
+		recordingInterceptor{name: "b", reject: true, log: &log},
+		recordingInterceptor{name: "c", log: &log},
+	)
+	fakeRW, rr := safehttptest.NewFakeResponseWriter()
+	req := safehttptest.NewRequest(safehttp.MethodGet, "http://foo.com/", nil)
+
+	b.Before(fakeRW, req, nil)
+
+	if diff := cmp.Diff([]string{"before a", "before b"}, log); diff != "" {
+		t.Errorf("log mismatch (-want +got):\n%s", diff)
+	}
+	if got, want := rr.Code, int(safehttp.StatusForbidden); got != want {
+		t.Errorf("rr.Code got: %v want: %v", got, want)
+	}
+}
+
+func TestBundleMatch(t *testing.T) {
+	var log []string
+	b := safehttp.Bundle(
+		recordingInterceptor{name: "a", log: &log},
+		safehttp.Bundle(recordingInterceptor{name: "b", log: &log}),
+	)
+	tests := []struct {
+		cfg  safehttp.InterceptorConfig
+		want bool
+	}{
+		{cfg: recordingConfig{name: "a"}, want: true},
+		{cfg: recordingConfig{name: "b"}, want: true},
+		{cfg: recordingConfig{name: "c"}, want: false},
+		{cfg: nil, want: false},
+	}
+	for _, tt := range tests {
+		if got := b.Match(tt.cfg); got != tt.want {
+			t.Errorf("b.Match(%#v) got: %v want: %v", tt.cfg, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package safehttp
+
+import (
+	"fmt"
+	"time"
+)
+
+// CachePublic returns a configuration that allows shared caches to store the
+// responses of a handler for the given duration. It can be passed when
+// registering the handler, like an InterceptorConfig, e.g.
+//
+//	mux.Handle("/logo.png", MethodGet, logoHandler, CachePublic(24*time.Hour))
+//
+// Error responses are never cached: they are sent with
+// "Cache-Control: no-store".
+//
+// The Cache-Control header is claimed before the handler runs, hence
+// handlers and interceptors can't change it.
+func CachePublic(maxAge time.Duration) InterceptorConfig {
+	return cacheConfig{value: fmt.Sprintf("public, max-age=%d", int64(maxAge/time.Second))}
+}
+
+// NoStore returns a configuration that forbids caches to store the responses
+// of a handler, e.g. for endpoints that serve user data. It can be passed when
+// registering the handler, like an InterceptorConfig.
+//
+// The Cache-Control header is claimed before the handler runs, hence
+// handlers and interceptors can't change it.
+func NoStore() InterceptorConfig {
+	return cacheConfig{value: "no-store"}
+}
+
+type cacheConfig struct {
+	value string
+}
+
+// cacheInterceptor sets the Cache-Control header of the responses of the
+// handlers registered with CachePublic or NoStore. It runs before the other
+// interceptors, so that the header is also set if they reject the request.
+type cacheInterceptor struct{}
+
+func (cacheInterceptor) Before(w ResponseWriter, r *IncomingRequest, cfg InterceptorConfig) Result {
+	c, ok := cfg.(cacheConfig)
+	if !ok {
+		return NotWritten()
+	}
+	set := w.Header().Claim("Cache-Control")
+	set([]string{c.value})
+	FlightValues(r.Context()).Put(cacheControlKey, set)
+	return NotWritten()
+}
+
+func (cacheInterceptor) Commit(w ResponseHeadersWriter, r *IncomingRequest, resp Response, cfg InterceptorConfig) {
+	if _, ok := resp.(ErrorResponse); !ok {
+		return
+	}
+	if set, ok := FlightValues(r.Context()).Get(cacheControlKey).(func([]string)); ok {
+		set([]string{"no-store"})
+	}
+}
+
+func (cacheInterceptor) Match(cfg InterceptorConfig) bool {
+	_, ok := cfg.(cacheConfig)
+	return ok
+}
+
+type cacheControlKeyType struct{}
+
+var cacheControlKey = cacheControlKeyType{}
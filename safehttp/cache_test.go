@@ -0,0 +1,116 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package safehttp_test
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/go-safeweb/safehttp"
+	"github.com/google/safehtml"
+)
+
+func TestCacheControl(t *testing.T) {
+	ok := safehttp.HandlerFunc(func(w safehttp.ResponseWriter, r *safehttp.IncomingRequest) safehttp.Result {
+		return w.Write(safehtml.HTMLEscaped("ok"))
+	})
+	fail := safehttp.HandlerFunc(func(w safehttp.ResponseWriter, r *safehttp.IncomingRequest) safehttp.Result {
+		return w.WriteError(safehttp.StatusNotFound)
+	})
+
+	tests := []struct {
+		name       string
+		h          safehttp.Handler
+		reject     bool
+		cfg        safehttp.InterceptorConfig
+		wantStatus safehttp.StatusCode
+		want       string
+	}{
+		{
+			name:       "Public",
+			h:          ok,
+			cfg:        safehttp.CachePublic(time.Hour),
+			wantStatus: safehttp.StatusOK,
+			want:       "public, max-age=3600",
+		},
+		{
+			name:       "Public error",
+			h:          fail,
+			cfg:        safehttp.CachePublic(time.Hour),
+			wantStatus: safehttp.StatusNotFound,
+			want:       "no-store",
+		},
+		{
+			name:       "Public rejected by interceptor",
+			h:          ok,
+			reject:     true,
+			cfg:        safehttp.CachePublic(time.Hour),
+			wantStatus: safehttp.StatusForbidden,
+			want:       "no-store",
+		},
+		{
+			name:       "No store",
+			h:          ok,
+			cfg:        safehttp.NoStore(),
+			wantStatus: safehttp.StatusOK,
+			want:       "no-store",
+		},
+		{
+			name:       "No configuration",
+			h:          ok,
+			wantStatus: safehttp.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var log []string
+			mb := safehttp.NewServeMuxConfig(nil)
+			mb.Intercept(recordingInterceptor{name: "auth", reject: tt.reject, log: &log})
+			mux := mb.Mux()
+			var cfgs []safehttp.InterceptorConfig
+			if tt.cfg != nil {
+				cfgs = append(cfgs, tt.cfg)
+			}
+			mux.Handle("/", safehttp.MethodGet, tt.h, cfgs...)
+
+			rw := httptest.NewRecorder()
+			mux.ServeHTTP(rw, httptest.NewRequest(safehttp.MethodGet, "http://foo.com/", nil))
+
+			if got, want := rw.Code, int(tt.wantStatus); got != want {
+				t.Errorf("rw.Code: got %v want %v", got, want)
+			}
+			if got := rw.Header().Get("Cache-Control"); got != tt.want {
+				t.Errorf(`rw.Header().Get("Cache-Control"): got %q want %q`, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCacheControlClaimed(t *testing.T) {
+	mux := safehttp.NewServeMuxConfig(nil).Mux()
+	mux.Handle("/", safehttp.MethodGet, safehttp.HandlerFunc(func(w safehttp.ResponseWriter, r *safehttp.IncomingRequest) safehttp.Result {
+		defer func() {
+			if r := recover(); r == nil {
+				t.Error(`w.Header().Set("Cache-Control", ...) expected panic`)
+			}
+		}()
+		w.Header().Set("Cache-Control", "public, max-age=60")
+		return safehttp.NotWritten()
+	}), safehttp.NoStore())
+
+	mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(safehttp.MethodGet, "http://foo.com/", nil))
+}
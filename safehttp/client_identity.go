@@ -0,0 +1,69 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package safehttp
+
+import (
+	"crypto/x509"
+	"net/url"
+)
+
+// ClientIdentity is the identity of a client authenticated with a TLS client
+// certificate (mutual TLS).
+type ClientIdentity struct {
+	// Certificate is the verified leaf certificate of the client.
+	Certificate *x509.Certificate
+	// Chain is the verified chain of the certificate, from the leaf to the
+	// root.
+	Chain []*x509.Certificate
+	// SPIFFEID is the SPIFFE ID of the client, i.e. the only "spiffe" URI SAN
+	// of the certificate, or nil if it doesn't have one.
+	//
+	// See https://github.com/spiffe/spiffe/blob/main/standards/X509-SVID.md.
+	SPIFFEID *url.URL
+}
+
+// TLSClientIdentity returns the identity of the client, if the request was
+// received over TLS and the client certificate was verified by the server,
+// i.e. the tls.Config of the server uses VerifyClientCertIfGiven or
+// RequireAndVerifyClientCert. Certificates that were sent by the client but not
+// verified are never returned.
+func (r *IncomingRequest) TLSClientIdentity() (*ClientIdentity, bool) {
+	if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 || len(r.TLS.VerifiedChains[0]) == 0 {
+		return nil, false
+	}
+	chain := r.TLS.VerifiedChains[0]
+	return &ClientIdentity{
+		Certificate: chain[0],
+		Chain:       chain,
+		SPIFFEID:    spiffeID(chain[0]),
+	}, true
+}
+
+// spiffeID returns the SPIFFE ID of the certificate. An X509-SVID must contain
+// exactly one URI SAN, so certificates with several SPIFFE IDs are considered
+// not to have one.
+func spiffeID(c *x509.Certificate) *url.URL {
+	var id *url.URL
+	for _, u := range c.URIs {
+		if u.Scheme != "spiffe" {
+			continue
+		}
+		if id != nil || u.Host == "" {
+			return nil
+		}
+		id = u
+	}
+	return id
+}
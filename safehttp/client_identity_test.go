@@ -0,0 +1,97 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package safehttp_test
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net/url"
+	"testing"
+
+	"github.com/google/go-safeweb/safehttp"
+	"github.com/google/go-safeweb/safehttp/safehttptest"
+)
+
+func TestTLSClientIdentity(t *testing.T) {
+	mustParse := func(s string) *url.URL {
+		u, err := url.Parse(s)
+		if err != nil {
+			t.Fatalf("url.Parse(%q): %v", s, err)
+		}
+		return u
+	}
+	tests := []struct {
+		name     string
+		uris     []string
+		wantSPID string
+	}{
+		{name: "SPIFFE ID", uris: []string{"spiffe://example.org/ns/prod/sa/billing"}, wantSPID: "spiffe://example.org/ns/prod/sa/billing"},
+		{name: "No SPIFFE ID", uris: []string{"https://example.org/"}},
+		{name: "Multiple SPIFFE IDs", uris: []string{"spiffe://example.org/a", "spiffe://example.org/b"}},
+		{name: "No URIs"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			leaf := &x509.Certificate{}
+			for _, u := range tt.uris {
+				leaf.URIs = append(leaf.URIs, mustParse(u))
+			}
+			root := &x509.Certificate{}
+			r := safehttptest.NewRequest(safehttp.MethodGet, "https://foo.com/", nil)
+			r.TLS = &tls.ConnectionState{
+				PeerCertificates: []*x509.Certificate{leaf},
+				VerifiedChains:   [][]*x509.Certificate{{leaf, root}},
+			}
+
+			id, ok := r.TLSClientIdentity()
+			if !ok {
+				t.Fatal("r.TLSClientIdentity() got ok false, want true")
+			}
+			if id.Certificate != leaf {
+				t.Errorf("id.Certificate: got %v want leaf", id.Certificate)
+			}
+			if len(id.Chain) != 2 {
+				t.Errorf("len(id.Chain): got %d want 2", len(id.Chain))
+			}
+			got := ""
+			if id.SPIFFEID != nil {
+				got = id.SPIFFEID.String()
+			}
+			if got != tt.wantSPID {
+				t.Errorf("id.SPIFFEID: got %q want %q", got, tt.wantSPID)
+			}
+		})
+	}
+}
+
+func TestTLSClientIdentityUnverified(t *testing.T) {
+	tests := []struct {
+		name string
+		tls  *tls.ConnectionState
+	}{
+		{name: "No TLS"},
+		{name: "No client certificate", tls: &tls.ConnectionState{}},
+		{name: "Unverified client certificate", tls: &tls.ConnectionState{PeerCertificates: []*x509.Certificate{{}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := safehttptest.NewRequest(safehttp.MethodGet, "https://foo.com/", nil)
+			r.TLS = tt.tls
+			if id, ok := r.TLSClientIdentity(); ok {
+				t.Errorf("r.TLSClientIdentity() got %v, true, want nil, false", id)
+			}
+		})
+	}
+}
@@ -0,0 +1,161 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package safehttp
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// ClientIPConfig configures how the IP address of the client is resolved when
+// the server is behind proxies or load balancers.
+type ClientIPConfig struct {
+	// TrustedProxies are the networks of the proxies that are trusted to
+	// report the address of the client. See ParseCIDRs.
+	TrustedProxies []*net.IPNet
+	// Header is the header listing the addresses of the forwarding chain:
+	// "X-Forwarded-For" or "Forwarded" (RFC 7239). If empty, X-Forwarded-For
+	// is used.
+	Header string
+}
+
+// ParseCIDRs parses a list of networks in CIDR notation, e.g. "10.0.0.0/8".
+// Single addresses are accepted as well.
+func ParseCIDRs(cidrs ...string) ([]*net.IPNet, error) {
+	var nets []*net.IPNet
+	for _, c := range cidrs {
+		if !strings.Contains(c, "/") {
+			ip := net.ParseIP(c)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid IP address %q", c)
+			}
+			bits := 8 * net.IPv6len
+			if ip4 := ip.To4(); ip4 != nil {
+				ip, bits = ip4, 8*net.IPv4len
+			}
+			nets = append(nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, n, err := net.ParseCIDR(c)
+		if err != nil {
+			return nil, err
+		}
+		nets = append(nets, n)
+	}
+	return nets, nil
+}
+
+// ResolveClientIP configures the muxes to resolve the IP address of the
+// client, as returned by IncomingRequest.ClientIP, from the forwarding header
+// set by the trusted proxies.
+//
+// It panics if the header is not supported.
+func (s *ServeMuxConfig) ResolveClientIP(cfg ClientIPConfig) {
+	switch http.CanonicalHeaderKey(cfg.Header) {
+	case "":
+		cfg.Header = "X-Forwarded-For"
+	case "X-Forwarded-For", "Forwarded":
+		cfg.Header = http.CanonicalHeaderKey(cfg.Header)
+	default:
+		panic(fmt.Sprintf("unsupported client IP header %q", cfg.Header))
+	}
+	s.clientIP = &cfg
+}
+
+type clientIPCtxKey struct{}
+
+// ClientIP returns the IP address of the client.
+//
+// By default, it's the address of the peer of the connection. If the
+// ServeMuxConfig was configured with ResolveClientIP and the peer is a trusted
+// proxy, the forwarding header is walked from the closest hop: the first
+// address that doesn't belong to a trusted proxy is the client's. If all of
+// them do, the farthest one is returned.
+//
+// Servers that accept the PROXY protocol see the address reported by the
+// proxy as the address of the peer.
+//
+// ClientIP returns nil if the address of the peer can't be parsed.
+func (r *IncomingRequest) ClientIP() net.IP {
+	ip := parseHostIP(r.req.RemoteAddr)
+	cfg, ok := r.req.Context().Value(clientIPCtxKey{}).(*ClientIPConfig)
+	if !ok || ip == nil || !cfg.trusted(ip) {
+		return ip
+	}
+	var hops []string
+	if cfg.Header == "Forwarded" {
+		hops = forwardedFor(r.req.Header.Values("Forwarded"))
+	} else {
+		for _, v := range r.req.Header.Values("X-Forwarded-For") {
+			hops = append(hops, strings.Split(v, ",")...)
+		}
+	}
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := parseHostIP(strings.TrimSpace(hops[i]))
+		if hop == nil {
+			// Obfuscated or malformed addresses can't be resolved further.
+			break
+		}
+		ip = hop
+		if !cfg.trusted(ip) {
+			break
+		}
+	}
+	return ip
+}
+
+func (cfg *ClientIPConfig) trusted(ip net.IP) bool {
+	for _, n := range cfg.TrustedProxies {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func withClientIPConfig(r *http.Request, cfg *ClientIPConfig) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), clientIPCtxKey{}, cfg))
+}
+
+// parseHostIP parses an address with an optional port, e.g. "192.0.2.1",
+// "192.0.2.1:80", "2001:db8::1" or "[2001:db8::1]:80".
+func parseHostIP(addr string) net.IP {
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		addr = host
+	}
+	return net.ParseIP(strings.TrimSuffix(strings.TrimPrefix(addr, "["), "]"))
+}
+
+// forwardedFor returns the "for" parameters of the Forwarded headers, in
+// order. Elements without one are returned as empty strings.
+func forwardedFor(values []string) []string {
+	var hops []string
+	for _, v := range values {
+		for _, elem := range strings.Split(v, ",") {
+			hop := ""
+			for _, pair := range strings.Split(elem, ";") {
+				pair = strings.TrimSpace(pair)
+				if i := strings.Index(pair, "="); i > 0 && strings.EqualFold(pair[:i], "for") {
+					hop = strings.Trim(pair[i+1:], `"`)
+				}
+			}
+			hops = append(hops, hop)
+		}
+	}
+	return hops
+}
@@ -0,0 +1,134 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package safehttp_test
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-safeweb/safehttp"
+	"github.com/google/safehtml"
+)
+
+func TestClientIP(t *testing.T) {
+	proxies, err := safehttp.ParseCIDRs("10.0.0.0/8", "2001:db8::/32", "192.0.2.1")
+	if err != nil {
+		t.Fatalf("safehttp.ParseCIDRs: %v", err)
+	}
+	tests := []struct {
+		name       string
+		cfg        *safehttp.ClientIPConfig
+		remoteAddr string
+		headers    map[string]string
+		want       string
+	}{
+		{
+			name:       "Not configured",
+			remoteAddr: "10.0.0.1:1234",
+			headers:    map[string]string{"X-Forwarded-For": "203.0.113.7"},
+			want:       "10.0.0.1",
+		},
+		{
+			name:       "Untrusted peer",
+			cfg:        &safehttp.ClientIPConfig{TrustedProxies: proxies},
+			remoteAddr: "198.51.100.1:1234",
+			headers:    map[string]string{"X-Forwarded-For": "203.0.113.7"},
+			want:       "198.51.100.1",
+		},
+		{
+			name:       "X-Forwarded-For",
+			cfg:        &safehttp.ClientIPConfig{TrustedProxies: proxies},
+			remoteAddr: "10.0.0.1:1234",
+			headers:    map[string]string{"X-Forwarded-For": "1.1.1.1, 203.0.113.7, 10.0.0.2"},
+			want:       "203.0.113.7",
+		},
+		{
+			name:       "Single trusted address",
+			cfg:        &safehttp.ClientIPConfig{TrustedProxies: proxies},
+			remoteAddr: "192.0.2.1:1234",
+			headers:    map[string]string{"X-Forwarded-For": "203.0.113.7"},
+			want:       "203.0.113.7",
+		},
+		{
+			name:       "All trusted",
+			cfg:        &safehttp.ClientIPConfig{TrustedProxies: proxies},
+			remoteAddr: "10.0.0.1:1234",
+			headers:    map[string]string{"X-Forwarded-For": "10.0.0.3, 10.0.0.2"},
+			want:       "10.0.0.3",
+		},
+		{
+			name:       "Malformed hop",
+			cfg:        &safehttp.ClientIPConfig{TrustedProxies: proxies},
+			remoteAddr: "10.0.0.1:1234",
+			headers:    map[string]string{"X-Forwarded-For": "203.0.113.7, garbage, 10.0.0.2"},
+			want:       "10.0.0.2",
+		},
+		{
+			name:       "Forwarded",
+			cfg:        &safehttp.ClientIPConfig{TrustedProxies: proxies, Header: "forwarded"},
+			remoteAddr: "[2001:db8::1]:1234",
+			headers:    map[string]string{"Forwarded": `for="[2001:db8:cafe::17]:4711";proto=https, for=203.0.113.7;by=10.0.0.9`},
+			want:       "203.0.113.7",
+		},
+		{
+			name:       "Forwarded obfuscated",
+			cfg:        &safehttp.ClientIPConfig{TrustedProxies: proxies, Header: "Forwarded"},
+			remoteAddr: "10.0.0.1:1234",
+			headers:    map[string]string{"Forwarded": "for=_hidden, for=10.0.0.2", "X-Forwarded-For": "203.0.113.7"},
+			want:       "10.0.0.2",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mb := safehttp.NewServeMuxConfig(nil)
+			if tt.cfg != nil {
+				mb.ResolveClientIP(*tt.cfg)
+			}
+			mux := mb.Mux()
+			mux.Handle("/", safehttp.MethodGet, safehttp.HandlerFunc(func(w safehttp.ResponseWriter, r *safehttp.IncomingRequest) safehttp.Result {
+				return w.Write(safehtml.HTMLEscaped(r.ClientIP().String()))
+			}))
+
+			req := httptest.NewRequest(safehttp.MethodGet, "http://foo.com/", nil)
+			req.RemoteAddr = tt.remoteAddr
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+			rr := httptest.NewRecorder()
+			mux.ServeHTTP(rr, req)
+
+			if got := rr.Body.String(); got != tt.want {
+				t.Errorf("r.ClientIP(): got %q want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseCIDRsInvalid(t *testing.T) {
+	for _, c := range []string{"10.0.0.0/33", "not-an-ip"} {
+		if _, err := safehttp.ParseCIDRs(c); err == nil {
+			t.Errorf("safehttp.ParseCIDRs(%q): got nil err, want error", c)
+		}
+	}
+}
+
+func TestResolveClientIPUnsupportedHeader(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Error(`mb.ResolveClientIP(Header: "X-Real-IP") expected panic`)
+		}
+	}()
+	safehttp.NewServeMuxConfig(nil).ResolveClientIP(safehttp.ClientIPConfig{Header: "X-Real-IP"})
+}
@@ -0,0 +1,48 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package safehttp
+
+import (
+	"crypto/rand"
+	"io"
+	"time"
+)
+
+// Clock provides the current time. Components of the framework which depend
+// on time accept a Clock, so that it can be replaced in tests.
+type Clock interface {
+	Now() time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// SystemClock returns the Clock reporting the system time.
+func SystemClock() Clock {
+	return systemClock{}
+}
+
+// SystemRand returns the cryptographically secure random number generator of
+// the operating system, i.e. crypto/rand.Reader. Components of the framework
+// which generate secrets, like tokens, nonces and keys, accept an io.Reader,
+// so that it can be replaced in tests or with an approved source, e.g. in FIPS
+// builds. The default source itself can't be replaced, so that a package can't
+// weaken the secrets of the others.
+func SystemRand() io.Reader {
+	return rand.Reader
+}
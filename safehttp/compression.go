@@ -0,0 +1,269 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package safehttp
+
+import (
+	"compress/gzip"
+	"context"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// Encoder compresses responses with a content coding.
+//
+// The standard library only implements gzip, see GzipEncoder. Other codings,
+// like Brotli, can be supported by implementing an Encoder on top of a
+// third-party library.
+type Encoder interface {
+	// Encoding returns the name of the content coding, e.g. "gzip" or "br".
+	Encoding() string
+	// NewWriter returns a writer that compresses to w. The writer is closed
+	// once the response is written. If it has a Flush() error method, it is
+	// called when the response is flushed.
+	NewWriter(w io.Writer) io.WriteCloser
+}
+
+// GzipEncoder compresses responses with gzip.
+type GzipEncoder struct {
+	// Level is the compression level, see compress/gzip. The zero value
+	// means gzip.DefaultCompression.
+	Level int
+}
+
+// Encoding implements Encoder.
+func (GzipEncoder) Encoding() string {
+	return "gzip"
+}
+
+// NewWriter implements Encoder.
+func (e GzipEncoder) NewWriter(w io.Writer) io.WriteCloser {
+	level := e.Level
+	if level == 0 {
+		level = gzip.DefaultCompression
+	}
+	gw, err := gzip.NewWriterLevel(w, level)
+	if err != nil {
+		panic(err)
+	}
+	return gw
+}
+
+// DefaultSecretFuncs are the names of the template functions that inject
+// secrets in the responses of the plugins of this module (currently, the XSRF
+// token of the xsrfhtml plugin).
+var DefaultSecretFuncs = []string{"XSRFToken"}
+
+// CompressionConfig configures the compression of responses, see
+// ServeMuxConfig.Compress.
+type CompressionConfig struct {
+	// Encoders are the supported content codings, in order of preference. If
+	// empty, only gzip is supported.
+	Encoders []Encoder
+	// SecretFuncs are the names of the template functions that inject
+	// secrets, like XSRF tokens, in TemplateResponses. Responses that use
+	// them are never compressed. If nil, DefaultSecretFuncs is used.
+	SecretFuncs []string
+}
+
+// Compress enables the compression of responses according to the
+// Accept-Encoding header of the requests.
+//
+// Only responses passed to ResponseWriter.Write are compressed, and only if
+// their Content-Type isn't already compressed (e.g. images, archives). These
+// responses get the "Vary: Accept-Encoding" header. Compressed responses lose
+// their Content-Length and Accept-Ranges headers and their ETag is made weak.
+//
+// Compressing secrets together with attacker-controlled content exposes them
+// to the BREACH attack. TemplateResponses using one of the
+// CompressionConfig.SecretFuncs aren't compressed. Handlers writing other
+// secrets, e.g. API keys, should be registered with DisableCompression.
+func (s *ServeMuxConfig) Compress(cfg CompressionConfig) {
+	if len(cfg.Encoders) == 0 {
+		cfg.Encoders = []Encoder{GzipEncoder{}}
+	}
+	if cfg.SecretFuncs == nil {
+		cfg.SecretFuncs = DefaultSecretFuncs
+	}
+	s.compression = &cfg
+}
+
+// DisableCompression returns a configuration that disables the compression of
+// the responses of a handler, see ServeMuxConfig.Compress. It can be passed
+// when registering the handler, like an InterceptorConfig.
+func DisableCompression() InterceptorConfig {
+	return disableCompressionConfig{}
+}
+
+type disableCompressionConfig struct{}
+
+type compressionCtxKey struct{}
+
+func withCompressionConfig(r *http.Request, cfg *CompressionConfig) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), compressionCtxKey{}, cfg))
+}
+
+// compresses reports whether resp might be compressed.
+func (cfg *CompressionConfig) compresses(resp Response) bool {
+	switch x := resp.(type) {
+	case FileServerResponse, LegacyResponse:
+		// The body is written after the Dispatcher returns.
+		return false
+	case UpgradeResponse, NoContentResponse, NotModifiedResponse:
+		return false
+	case *TemplateResponse:
+		for _, name := range cfg.SecretFuncs {
+			if _, ok := x.FuncMap[name]; ok {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// encoder returns the Encoder of the coding the client prefers, or nil if
+// the client doesn't accept any or prefers identity.
+func (cfg *CompressionConfig) encoder(r *IncomingRequest) Encoder {
+	if r.req.Header.Get("Accept-Encoding") == "" {
+		return nil
+	}
+	offers := make([]string, 0, len(cfg.Encoders)+1)
+	for _, e := range cfg.Encoders {
+		offers = append(offers, e.Encoding())
+	}
+	offers = append(offers, "identity")
+	coding := r.NegotiateEncoding(offers...)
+	for _, e := range cfg.Encoders {
+		if e.Encoding() == coding {
+			return e
+		}
+	}
+	return nil
+}
+
+// newCompressWriter returns a writer that compresses resp, if the request was
+// served by a ServeMux configured with Compress. It returns nil otherwise.
+func newCompressWriter(f *flight, resp Response) *compressWriter {
+	cfg, ok := f.req.Context().Value(compressionCtxKey{}).(*CompressionConfig)
+	if !ok || f.cfg.NoCompression || !cfg.compresses(resp) {
+		return nil
+	}
+	return &compressWriter{ResponseWriter: f.rw, enc: cfg.encoder(f.req)}
+}
+
+// compressWriter decides whether to compress the response once its status
+// code and headers are written.
+type compressWriter struct {
+	http.ResponseWriter
+	enc Encoder
+
+	wroteHeader bool
+	// w is the compressing writer, if the response is compressed.
+	w io.WriteCloser
+}
+
+func (c *compressWriter) WriteHeader(code int) {
+	if c.wroteHeader {
+		return
+	}
+	c.wroteHeader = true
+	c.start(code)
+	c.ResponseWriter.WriteHeader(code)
+}
+
+func (c *compressWriter) start(code int) {
+	h := c.ResponseWriter.Header()
+	if code < 200 || code == http.StatusNoContent || code == http.StatusPartialContent || code == http.StatusNotModified {
+		return
+	}
+	if h.Get("Content-Encoding") != "" || !compressible(h.Get("Content-Type")) {
+		return
+	}
+	addVary(h, "Accept-Encoding")
+	if c.enc == nil {
+		return
+	}
+	h.Set("Content-Encoding", c.enc.Encoding())
+	h.Del("Content-Length")
+	h.Del("Accept-Ranges")
+	if etag := h.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+		h.Set("ETag", "W/"+etag)
+	}
+	c.w = c.enc.NewWriter(c.ResponseWriter)
+}
+
+func (c *compressWriter) Write(b []byte) (int, error) {
+	c.WriteHeader(http.StatusOK)
+	if c.w != nil {
+		return c.w.Write(b)
+	}
+	return c.ResponseWriter.Write(b)
+}
+
+// Flush flushes the compressed data written so far and the underlying
+// http.ResponseWriter.
+func (c *compressWriter) Flush() {
+	if f, ok := c.w.(interface{ Flush() error }); ok {
+		f.Flush()
+	}
+	if f, ok := c.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Close finishes the compressed stream, if any.
+func (c *compressWriter) Close() error {
+	if c.w == nil {
+		return nil
+	}
+	return c.w.Close()
+}
+
+// compressible reports whether content of the given type is worth compressing,
+// i.e. it's known and it isn't compressed already.
+func compressible(ct string) bool {
+	mt, _, err := mime.ParseMediaType(ct)
+	if err != nil {
+		return false
+	}
+	switch {
+	case mt == "image/svg+xml":
+		return true
+	case strings.HasPrefix(mt, "image/"), strings.HasPrefix(mt, "audio/"), strings.HasPrefix(mt, "video/"), strings.HasPrefix(mt, "font/woff"):
+		return false
+	case strings.HasPrefix(mt, "text/"), strings.HasSuffix(mt, "+json"), strings.HasSuffix(mt, "+xml"):
+		return true
+	}
+	switch mt {
+	case "application/json", "application/x-ndjson", "application/javascript", "application/xml",
+		"application/wasm", "font/ttf", "font/otf":
+		return true
+	}
+	// This includes application/octet-stream, archives and documents like
+	// application/pdf, which are usually compressed.
+	return false
+}
+
+func addVary(h http.Header, name string) {
+	for _, v := range h.Values("Vary") {
+		for _, f := range strings.Split(v, ",") {
+			if f = strings.TrimSpace(f); f == "*" || strings.EqualFold(f, name) {
+				return
+			}
+		}
+	}
+	h.Add("Vary", name)
+}
@@ -0,0 +1,248 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package safehttp_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-safeweb/safehttp"
+	"github.com/google/safehtml/template"
+)
+
+// fakeBrotli "compresses" responses by prefixing them with "br:".
+type fakeBrotli struct{}
+
+func (fakeBrotli) Encoding() string { return "br" }
+
+func (fakeBrotli) NewWriter(w io.Writer) io.WriteCloser {
+	io.WriteString(w, "br:")
+	return nopWriteCloser{w}
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error { return nil }
+
+func gunzip(t *testing.T, b []byte) string {
+	t.Helper()
+	r, err := gzip.NewReader(bytes.NewReader(b))
+	if err != nil {
+		t.Fatalf("gzip.NewReader: %v", err)
+	}
+	got, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatalf("ioutil.ReadAll: %v", err)
+	}
+	return string(got)
+}
+
+var secretTemplate = template.Must(template.New("").Funcs(template.FuncMap{"XSRFToken": func() string { return "" }}).Parse(`<input value="{{XSRFToken}}">`))
+
+func TestCompress(t *testing.T) {
+	modTime := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name           string
+		cfg            safehttp.CompressionConfig
+		acceptEncoding string
+		write          func(w safehttp.ResponseWriter, r *safehttp.IncomingRequest) safehttp.Result
+		routeCfgs      []safehttp.InterceptorConfig
+		wantHeaders    map[string][]string
+		wantBody       string
+	}{
+		{
+			name:           "JSON",
+			acceptEncoding: "gzip, deflate",
+			write: func(w safehttp.ResponseWriter, r *safehttp.IncomingRequest) safehttp.Result {
+				return safehttp.WriteJSON(w, "hello")
+			},
+			wantHeaders: map[string][]string{
+				"Content-Encoding": {"gzip"},
+				"Content-Type":     {"application/json; charset=utf-8"},
+				"Vary":             {"Accept-Encoding"},
+			},
+			wantBody: ")]}',\n\"hello\"\n",
+		},
+		{
+			name: "No Accept-Encoding",
+			write: func(w safehttp.ResponseWriter, r *safehttp.IncomingRequest) safehttp.Result {
+				return safehttp.WriteJSON(w, "hello")
+			},
+			wantHeaders: map[string][]string{
+				"Content-Type": {"application/json; charset=utf-8"},
+				"Vary":         {"Accept-Encoding"},
+			},
+			wantBody: ")]}',\n\"hello\"\n",
+		},
+		{
+			name:           "Identity preferred",
+			acceptEncoding: "gzip;q=0.5, identity",
+			write: func(w safehttp.ResponseWriter, r *safehttp.IncomingRequest) safehttp.Result {
+				return safehttp.WriteJSON(w, "hello")
+			},
+			wantHeaders: map[string][]string{
+				"Content-Type": {"application/json; charset=utf-8"},
+				"Vary":         {"Accept-Encoding"},
+			},
+			wantBody: ")]}',\n\"hello\"\n",
+		},
+		{
+			name:           "Preferred encoder",
+			cfg:            safehttp.CompressionConfig{Encoders: []safehttp.Encoder{fakeBrotli{}, safehttp.GzipEncoder{}}},
+			acceptEncoding: "gzip, br",
+			write: func(w safehttp.ResponseWriter, r *safehttp.IncomingRequest) safehttp.Result {
+				return safehttp.WriteJSON(w, "hello")
+			},
+			wantHeaders: map[string][]string{
+				"Content-Encoding": {"br"},
+				"Content-Type":     {"application/json; charset=utf-8"},
+				"Vary":             {"Accept-Encoding"},
+			},
+			wantBody: "br:)]}',\n\"hello\"\n",
+		},
+		{
+			name:           "Already compressed",
+			acceptEncoding: "gzip",
+			write: func(w safehttp.ResponseWriter, r *safehttp.IncomingRequest) safehttp.Result {
+				return safehttp.WriteFile(w, r, "logo.png", modTime, strings.NewReader("\x89PNG"))
+			},
+			wantHeaders: map[string][]string{
+				"Accept-Ranges":  {"bytes"},
+				"Content-Length": {"4"},
+				"Content-Type":   {"image/png"},
+				"Last-Modified":  {"Thu, 01 Jan 2026 00:00:00 GMT"},
+			},
+			wantBody: "\x89PNG",
+		},
+		{
+			name:           "File",
+			acceptEncoding: "gzip",
+			write: func(w safehttp.ResponseWriter, r *safehttp.IncomingRequest) safehttp.Result {
+				w.Header().Set("ETag", `"v1"`)
+				return safehttp.WriteFile(w, r, "notes.txt", modTime, strings.NewReader("some notes"))
+			},
+			wantHeaders: map[string][]string{
+				"Content-Encoding": {"gzip"},
+				"Content-Type":     {"text/plain; charset=utf-8"},
+				"Etag":             {`W/"v1"`},
+				"Last-Modified":    {"Thu, 01 Jan 2026 00:00:00 GMT"},
+				"Vary":             {"Accept-Encoding"},
+			},
+			wantBody: "some notes",
+		},
+		{
+			name:           "XSRF token",
+			acceptEncoding: "gzip",
+			write: func(w safehttp.ResponseWriter, r *safehttp.IncomingRequest) safehttp.Result {
+				return safehttp.ExecuteTemplateWithFuncs(w, secretTemplate, nil, map[string]interface{}{
+					"XSRFToken": func() string { return "secret" },
+				})
+			},
+			wantHeaders: map[string][]string{
+				"Content-Type": {"text/html; charset=utf-8"},
+			},
+			wantBody: `<input value="secret">`,
+		},
+		{
+			name:           "DisableCompression",
+			acceptEncoding: "gzip",
+			write: func(w safehttp.ResponseWriter, r *safehttp.IncomingRequest) safehttp.Result {
+				return safehttp.WriteJSON(w, "api-key")
+			},
+			routeCfgs: []safehttp.InterceptorConfig{safehttp.DisableCompression()},
+			wantHeaders: map[string][]string{
+				"Content-Type": {"application/json; charset=utf-8"},
+			},
+			wantBody: ")]}',\n\"api-key\"\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mb := safehttp.NewServeMuxConfig(nil)
+			mb.Compress(tt.cfg)
+			mux := mb.Mux()
+			mux.Handle("/", safehttp.MethodGet, safehttp.HandlerFunc(tt.write), tt.routeCfgs...)
+
+			req := httptest.NewRequest(safehttp.MethodGet, "http://foo.com/", nil)
+			if tt.acceptEncoding != "" {
+				req.Header.Set("Accept-Encoding", tt.acceptEncoding)
+			}
+			rr := httptest.NewRecorder()
+			mux.ServeHTTP(rr, req)
+
+			if got, want := rr.Code, int(safehttp.StatusOK); got != want {
+				t.Errorf("rr.Code: got %v want %v", got, want)
+			}
+			if diff := cmp.Diff(tt.wantHeaders, map[string][]string(rr.Header())); diff != "" {
+				t.Errorf("rr.Header() mismatch (-want +got):\n%s", diff)
+			}
+			body := rr.Body.String()
+			if rr.Header().Get("Content-Encoding") == "gzip" {
+				body = gunzip(t, rr.Body.Bytes())
+			}
+			if body != tt.wantBody {
+				t.Errorf("response body: got %q want %q", body, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestCompressNotConfigured(t *testing.T) {
+	mux := safehttp.NewServeMuxConfig(nil).Mux()
+	mux.Handle("/", safehttp.MethodGet, safehttp.HandlerFunc(func(w safehttp.ResponseWriter, r *safehttp.IncomingRequest) safehttp.Result {
+		return safehttp.WriteJSON(w, "hello")
+	}))
+	req := httptest.NewRequest(safehttp.MethodGet, "http://foo.com/", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, req)
+
+	if got := rr.Header().Get("Content-Encoding"); got != "" {
+		t.Errorf(`rr.Header().Get("Content-Encoding"): got %q, want ""`, got)
+	}
+}
+
+func TestCompressJSONStream(t *testing.T) {
+	mb := safehttp.NewServeMuxConfig(nil)
+	mb.Compress(safehttp.CompressionConfig{})
+	mux := mb.Mux()
+	mux.Handle("/", safehttp.MethodGet, safehttp.HandlerFunc(func(w safehttp.ResponseWriter, r *safehttp.IncomingRequest) safehttp.Result {
+		return w.Write(safehttp.JSONStreamFromSlice([]interface{}{1, 2}))
+	}))
+	req := httptest.NewRequest(safehttp.MethodGet, "http://foo.com/", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, req)
+
+	if got, want := rr.Header().Get("Content-Encoding"), "gzip"; got != want {
+		t.Errorf(`rr.Header().Get("Content-Encoding"): got %q want %q`, got, want)
+	}
+	if !rr.Flushed {
+		t.Error("rr.Flushed: got false want true")
+	}
+	if got, want := gunzip(t, rr.Body.Bytes()), "1\n2\n"; got != want {
+		t.Errorf("response body: got %q want %q", got, want)
+	}
+}
@@ -0,0 +1,90 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package safehttp
+
+import "reflect"
+
+// InterceptorIf returns an interceptor that runs it only for the requests
+// that satisfy pred, e.g. to skip the XSRF protection for machine clients
+// authenticated with a header:
+//
+//	cfg.Intercept(InterceptorIf(func(r *IncomingRequest) bool {
+//		return r.Header.Get("Authorization") == ""
+//	}, xsrfInterceptor))
+//
+// The predicate is evaluated before both the Before and Commit phases, hence
+// it must only depend on the request. The returned interceptor matches the
+// configurations of it, and is disabled by restricted.DisableInterceptor like
+// it.
+func InterceptorIf(pred func(*IncomingRequest) bool, it Interceptor) Interceptor {
+	return &conditional{pred: pred, it: it}
+}
+
+type conditional struct {
+	pred func(*IncomingRequest) bool
+	it   Interceptor
+}
+
+// Before runs the Before phase of the interceptor if the request satisfies the
+// predicate.
+func (c *conditional) Before(w ResponseWriter, r *IncomingRequest, cfg InterceptorConfig) Result {
+	if !c.pred(r) {
+		return NotWritten()
+	}
+	return c.it.Before(w, r, cfg)
+}
+
+// Commit runs the Commit phase of the interceptor if the request satisfies the
+// predicate.
+func (c *conditional) Commit(w ResponseHeadersWriter, r *IncomingRequest, resp Response, cfg InterceptorConfig) {
+	if !c.pred(r) {
+		return
+	}
+	c.it.Commit(w, r, resp, cfg)
+}
+
+// OnError runs the OnError hook of the interceptor, if it's an ErrorObserver,
+// if the request satisfies the predicate.
+func (c *conditional) OnError(w ResponseWriter, r *IncomingRequest, err error) {
+	if o, ok := c.it.(ErrorObserver); ok && c.pred(r) {
+		o.OnError(w, r, err)
+	}
+}
+
+// Match returns true if the interceptor matches the configuration.
+func (c *conditional) Match(cfg InterceptorConfig) bool {
+	return c.it.Match(cfg)
+}
+
+// interceptorType returns the type of the interceptor, looking through
+// InterceptorIf and Reloadable.
+func interceptorType(it Interceptor) reflect.Type {
+	return reflect.TypeOf(unwrapConditional(it))
+}
+
+// unwrapConditional returns the interceptor wrapped by InterceptorIf or the
+// current implementation of a Reloadable, if any.
+func unwrapConditional(it Interceptor) Interceptor {
+	for {
+		switch x := it.(type) {
+		case *conditional:
+			it = x.it
+		case *Reloadable:
+			it = x.Load()
+		default:
+			return it
+		}
+	}
+}
@@ -0,0 +1,143 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package safehttp
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// NotModifiedResponse is used to write a "304 Not Modified" response. It's
+// usually written by CheckPreconditions.
+type NotModifiedResponse struct{}
+
+// Validators identify the current representation of a resource. They are
+// compared with the preconditions of conditional requests by
+// CheckPreconditions.
+type Validators struct {
+	// ETag is the entity-tag of the representation, including the quotes and
+	// the weakness indicator, e.g. `"v1"` or `W/"v1"`. See StrongETag.
+	ETag string
+	// LastModified is the modification time of the representation, if not
+	// zero. It has a precision of one second.
+	LastModified time.Time
+}
+
+// StrongETag returns a strong entity-tag computed from the hash of the given
+// content.
+func StrongETag(content []byte) string {
+	sum := sha256.Sum256(content)
+	return `"` + base64.RawURLEncoding.EncodeToString(sum[:18]) + `"`
+}
+
+// CheckPreconditions evaluates the preconditions of the request against the
+// validators of the current representation, following RFC 7232, Section 6.
+// It sets the ETag and Last-Modified response headers from v.
+//
+// If a precondition fails, it writes 304 Not Modified (for GET and HEAD
+// requests whose representation didn't change) or 412 Precondition Failed,
+// and returns true. The handler should then return the Result:
+//
+//	v := safehttp.Validators{ETag: safehttp.StrongETag(content)}
+//	if res, done := safehttp.CheckPreconditions(w, r, v); done {
+//		return res
+//	}
+//	return w.Write(content)
+//
+// Otherwise it returns false and the handler should write the response.
+func CheckPreconditions(w ResponseWriter, r *IncomingRequest, v Validators) (Result, bool) {
+	if v.ETag != "" {
+		w.Header().Set("ETag", v.ETag)
+	}
+	if !v.LastModified.IsZero() {
+		w.Header().Set("Last-Modified", v.LastModified.UTC().Format(http.TimeFormat))
+	}
+
+	h := r.req.Header
+	safe := r.Method() == MethodGet || r.Method() == MethodHead
+	if im := h.Get("If-Match"); im != "" {
+		if !matchETags(im, v.ETag, false) {
+			return w.WriteError(StatusPreconditionFailed), true
+		}
+	} else if ius, ok := parseHTTPDate(h.Get("If-Unmodified-Since")); ok && !v.LastModified.IsZero() {
+		if v.LastModified.Truncate(time.Second).After(ius) {
+			return w.WriteError(StatusPreconditionFailed), true
+		}
+	}
+
+	if inm := h.Get("If-None-Match"); inm != "" {
+		if matchETags(inm, v.ETag, true) {
+			if safe {
+				return w.Write(NotModifiedResponse{}), true
+			}
+			return w.WriteError(StatusPreconditionFailed), true
+		}
+	} else if ims, ok := parseHTTPDate(h.Get("If-Modified-Since")); ok && safe && !v.LastModified.IsZero() {
+		if !v.LastModified.Truncate(time.Second).After(ims) {
+			return w.Write(NotModifiedResponse{}), true
+		}
+	}
+	return Result{}, false
+}
+
+func parseHTTPDate(s string) (time.Time, bool) {
+	if s == "" {
+		return time.Time{}, false
+	}
+	t, err := http.ParseTime(s)
+	return t, err == nil
+}
+
+// matchETags reports whether the list of entity-tags of an If-Match or
+// If-None-Match header matches etag, using the weak comparison if weak is set
+// and the strong one otherwise.
+func matchETags(list, etag string, weak bool) bool {
+	if etag == "" {
+		return false
+	}
+	if strings.TrimSpace(list) == "*" {
+		return true
+	}
+	for list != "" {
+		list = strings.TrimLeft(list, " \t,")
+		if list == "" {
+			break
+		}
+		tag := list
+		start := 0
+		if strings.HasPrefix(tag, "W/") {
+			start = 2
+		}
+		if len(tag) <= start || tag[start] != '"' {
+			// Malformed entity-tag.
+			return false
+		}
+		end := strings.IndexByte(tag[start+1:], '"')
+		if end < 0 {
+			return false
+		}
+		tag, list = tag[:start+end+2], tag[start+end+2:]
+		if weak && strings.TrimPrefix(tag, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+		if !weak && tag == etag && !strings.HasPrefix(tag, "W/") {
+			return true
+		}
+	}
+	return false
+}
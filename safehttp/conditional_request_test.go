@@ -0,0 +1,96 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package safehttp_test
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-safeweb/safehttp"
+	"github.com/google/safehtml"
+)
+
+func TestCheckPreconditions(t *testing.T) {
+	modified := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	v := safehttp.Validators{ETag: `"v2"`, LastModified: modified}
+	tests := []struct {
+		name     string
+		method   string
+		headers  map[string]string
+		wantCode safehttp.StatusCode
+	}{
+		{name: "Unconditional", method: safehttp.MethodGet, wantCode: safehttp.StatusOK},
+		{name: "If-None-Match match", method: safehttp.MethodGet, headers: map[string]string{"If-None-Match": `"v1", "v2"`}, wantCode: safehttp.StatusNotModified},
+		{name: "If-None-Match weak match", method: safehttp.MethodGet, headers: map[string]string{"If-None-Match": `W/"v2"`}, wantCode: safehttp.StatusNotModified},
+		{name: "If-None-Match star", method: safehttp.MethodHead, headers: map[string]string{"If-None-Match": "*"}, wantCode: safehttp.StatusNotModified},
+		{name: "If-None-Match mismatch", method: safehttp.MethodGet, headers: map[string]string{"If-None-Match": `"v1"`}, wantCode: safehttp.StatusOK},
+		{name: "If-None-Match on unsafe method", method: safehttp.MethodPut, headers: map[string]string{"If-None-Match": "*"}, wantCode: safehttp.StatusPreconditionFailed},
+		{name: "If-None-Match takes precedence", method: safehttp.MethodGet, headers: map[string]string{"If-None-Match": `"v1"`, "If-Modified-Since": "Sat, 03 Jan 2026 00:00:00 GMT"}, wantCode: safehttp.StatusOK},
+		{name: "If-Modified-Since not modified", method: safehttp.MethodGet, headers: map[string]string{"If-Modified-Since": "Fri, 02 Jan 2026 03:04:05 GMT"}, wantCode: safehttp.StatusNotModified},
+		{name: "If-Modified-Since modified", method: safehttp.MethodGet, headers: map[string]string{"If-Modified-Since": "Thu, 01 Jan 2026 00:00:00 GMT"}, wantCode: safehttp.StatusOK},
+		{name: "If-Modified-Since invalid", method: safehttp.MethodGet, headers: map[string]string{"If-Modified-Since": "yesterday"}, wantCode: safehttp.StatusOK},
+		{name: "If-Match match", method: safehttp.MethodPut, headers: map[string]string{"If-Match": `"v2"`}, wantCode: safehttp.StatusOK},
+		{name: "If-Match weak", method: safehttp.MethodPut, headers: map[string]string{"If-Match": `W/"v2"`}, wantCode: safehttp.StatusPreconditionFailed},
+		{name: "If-Match mismatch", method: safehttp.MethodPut, headers: map[string]string{"If-Match": `"v1"`}, wantCode: safehttp.StatusPreconditionFailed},
+		{name: "If-Unmodified-Since modified", method: safehttp.MethodPut, headers: map[string]string{"If-Unmodified-Since": "Thu, 01 Jan 2026 00:00:00 GMT"}, wantCode: safehttp.StatusPreconditionFailed},
+		{name: "If-Unmodified-Since not modified", method: safehttp.MethodPut, headers: map[string]string{"If-Unmodified-Since": "Fri, 02 Jan 2026 03:04:05 GMT"}, wantCode: safehttp.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mux := safehttp.NewServeMuxConfig(nil).Mux()
+			mux.Handle("/", tt.method, safehttp.HandlerFunc(func(w safehttp.ResponseWriter, r *safehttp.IncomingRequest) safehttp.Result {
+				if res, done := safehttp.CheckPreconditions(w, r, v); done {
+					return res
+				}
+				return w.Write(safehtml.HTMLEscaped("content"))
+			}))
+
+			req := httptest.NewRequest(tt.method, "http://foo.com/", nil)
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+			rr := httptest.NewRecorder()
+			mux.ServeHTTP(rr, req)
+
+			if got, want := rr.Code, int(tt.wantCode); got != want {
+				t.Errorf("rr.Code: got %v want %v", got, want)
+			}
+			if got, want := rr.Header().Get("ETag"), `"v2"`; got != want {
+				t.Errorf(`rr.Header().Get("ETag"): got %q want %q`, got, want)
+			}
+			if got, want := rr.Header().Get("Last-Modified"), "Fri, 02 Jan 2026 03:04:05 GMT"; got != want {
+				t.Errorf(`rr.Header().Get("Last-Modified"): got %q want %q`, got, want)
+			}
+			if tt.wantCode == safehttp.StatusNotModified && rr.Body.Len() != 0 {
+				t.Errorf("rr.Body: got %q want empty", rr.Body.String())
+			}
+		})
+	}
+}
+
+func TestStrongETag(t *testing.T) {
+	a, b := safehttp.StrongETag([]byte("a")), safehttp.StrongETag([]byte("b"))
+	if a == b {
+		t.Errorf("StrongETag: got the same tag %s for different content", a)
+	}
+	if a != safehttp.StrongETag([]byte("a")) {
+		t.Error("StrongETag: got different tags for the same content")
+	}
+	if !strings.HasPrefix(a, `"`) || !strings.HasSuffix(a, `"`) {
+		t.Errorf("StrongETag: got %s, want a quoted tag", a)
+	}
+}
@@ -0,0 +1,81 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package safehttp_test
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-safeweb/safehttp"
+	"github.com/google/go-safeweb/safehttp/restricted"
+	"github.com/google/safehtml"
+)
+
+func TestInterceptorIf(t *testing.T) {
+	tests := []struct {
+		name    string
+		machine bool
+		want    []string
+	}{
+		{
+			name: "Runs",
+			want: []string{"before a", "before b x", "commit b", "commit a"},
+		},
+		{
+			name:    "Skipped",
+			machine: true,
+			want:    []string{"before a", "handler", "commit a"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var log []string
+			mb := safehttp.NewServeMuxConfig(nil)
+			mb.Intercept(
+				recordingInterceptor{name: "a", log: &log},
+				safehttp.InterceptorIf(func(r *safehttp.IncomingRequest) bool {
+					return r.Header.Get("Authorization") == ""
+				}, recordingInterceptor{name: "b", reject: true, log: &log}),
+			)
+			mux := mb.Mux()
+			mux.Handle("/", safehttp.MethodGet, safehttp.HandlerFunc(func(w safehttp.ResponseWriter, r *safehttp.IncomingRequest) safehttp.Result {
+				log = append(log, "handler")
+				return w.Write(safehtml.HTMLEscaped("hello"))
+			}), recordingConfig{name: "b", value: "x"})
+
+			req := httptest.NewRequest(safehttp.MethodGet, "http://foo.com/", nil)
+			if tt.machine {
+				req.Header.Set("Authorization", "Bearer token")
+			}
+			mux.ServeHTTP(httptest.NewRecorder(), req)
+
+			if diff := cmp.Diff(tt.want, log); diff != "" {
+				t.Errorf("log mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestInterceptorIfDisabled(t *testing.T) {
+	_, rr := serveRecorded(t, func(mb *safehttp.ServeMuxConfig, log *[]string) {
+		mb.Intercept(safehttp.InterceptorIf(func(*safehttp.IncomingRequest) bool { return true }, setHeaderInterceptor{name: "Foo", value: "bar"}))
+	}, restricted.DisableInterceptor(setHeaderInterceptor{}, "not needed"))
+
+	if got := rr.Header().Get("Foo"); got != "" {
+		t.Errorf(`rr.Header().Get("Foo"): got %q want ""`, got)
+	}
+}
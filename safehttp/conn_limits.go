@@ -0,0 +1,186 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package safehttp
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ErrBodyTooSlow is returned by the reads of the request body when the client
+// sends it slower than the MinTransferRate of the Server.
+var ErrBodyTooSlow = errors.New("safehttp: request body sent too slowly")
+
+// defaultTransferRateGrace is used if the TransferRateGrace of the Server is
+// zero.
+const defaultTransferRateGrace = 5 * time.Second
+
+// connLimiter limits the number of concurrent connections, in total and per
+// client IP. The connections over the limits are still accepted, but their
+// requests are answered with 503 Service Unavailable and then closed, so that
+// clients can tell an overloaded server from a dead one.
+type connLimiter struct {
+	max      int
+	maxPerIP int
+
+	mu    sync.Mutex
+	total int
+	perIP map[string]int
+	// conns maps the counted connections to their client IP.
+	conns map[net.Conn]string
+}
+
+type connRejectedCtxKey struct{}
+
+func newConnLimiter(max, maxPerIP int) *connLimiter {
+	return &connLimiter{
+		max:      max,
+		maxPerIP: maxPerIP,
+		perIP:    map[string]int{},
+		conns:    map[net.Conn]string{},
+	}
+}
+
+// connContext counts the new connection c and marks its context if it's over
+// the limits. It's used as the ConnContext of the http.Server.
+func (l *connLimiter) connContext(ctx context.Context, c net.Conn) context.Context {
+	ip := ""
+	if addr, ok := c.RemoteAddr().(*net.TCPAddr); ok {
+		ip = addr.IP.String()
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	rejected := l.max > 0 && l.total >= l.max ||
+		l.maxPerIP > 0 && ip != "" && l.perIP[ip] >= l.maxPerIP
+	l.total++
+	if ip != "" {
+		l.perIP[ip]++
+	}
+	l.conns[c] = ip
+	if rejected {
+		return context.WithValue(ctx, connRejectedCtxKey{}, true)
+	}
+	return ctx
+}
+
+// connState stops counting the connections which were closed or hijacked.
+// It's used as the ConnState of the http.Server.
+func (l *connLimiter) connState(c net.Conn, state http.ConnState) {
+	if state != http.StateClosed && state != http.StateHijacked {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	ip, ok := l.conns[c]
+	if !ok {
+		return
+	}
+	delete(l.conns, c)
+	l.total--
+	if ip == "" {
+		return
+	}
+	if l.perIP[ip]--; l.perIP[ip] == 0 {
+		delete(l.perIP, ip)
+	}
+}
+
+// limitHandler wraps the handler of the server to reject the requests of the
+// connections over the limits and to enforce the minimum transfer rate.
+func (s *Server) limitHandler(h http.Handler) http.Handler {
+	if s.MaxConnections <= 0 && s.MaxConnectionsPerIP <= 0 && s.MinTransferRate <= 0 {
+		return h
+	}
+	rate := &transferRate{bytesPerSecond: s.MinTransferRate, grace: s.TransferRateGrace}
+	if rate.grace == 0 {
+		rate.grace = defaultTransferRateGrace
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if rejected, _ := r.Context().Value(connRejectedCtxKey{}).(bool); rejected {
+			w.Header().Set("Connection", "close")
+			s.Mux.serveError(w, r, StatusServiceUnavailable)
+			return
+		}
+		if rate.bytesPerSecond > 0 && r.Body != nil && r.Body != http.NoBody {
+			r = r.WithContext(context.WithValue(r.Context(), transferRateCtxKey{}, rate))
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+// transferRate is the minimum rate at which request bodies must be sent,
+// after a grace period.
+type transferRate struct {
+	bytesPerSecond int64
+	grace          time.Duration
+}
+
+type transferRateCtxKey struct{}
+
+// minRateBody fails with ErrBodyTooSlow when the average rate at which the
+// body is received drops below the minimum, after the grace period.
+type minRateBody struct {
+	body   io.ReadCloser
+	rate   *transferRate
+	start  time.Time
+	read   int64
+	status *bodyStatus
+	err    error
+	buf    []byte
+	// header is the header of the response, in which "Connection: close" is
+	// set on failure: otherwise, net/http would wait for the rest of the body
+	// before sending the response.
+	header http.Header
+}
+
+func newMinRateBody(r *IncomingRequest, rate *transferRate, header http.Header) *minRateBody {
+	return &minRateBody{
+		body:   r.req.Body,
+		rate:   rate,
+		start:  time.Now().Add(rate.grace),
+		status: r.bodyStatus,
+		header: header,
+	}
+}
+
+func (b *minRateBody) Read(p []byte) (int, error) {
+	if b.err != nil {
+		return 0, b.err
+	}
+	if len(p) == 0 {
+		return 0, nil
+	}
+	// The next byte must be received before the average rate drops below the
+	// minimum.
+	deadline := b.start.Add(time.Duration(float64(b.read+1) / float64(b.rate.bytesPerSecond) * float64(time.Second)))
+	n, err := readBefore(b.body, p, &b.buf, deadline)
+	if err == errDeadlineExpired {
+		b.err = ErrBodyTooSlow
+		b.status.set(ErrBodyTooSlow)
+		b.header.Set("Connection", "close")
+		return 0, ErrBodyTooSlow
+	}
+	b.read += int64(n)
+	return n, err
+}
+
+func (b *minRateBody) Close() error {
+	return b.body.Close()
+}
@@ -0,0 +1,170 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package safehttp
+
+import (
+	"bufio"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/safehtml"
+)
+
+func startLimitedServer(t *testing.T, s *Server) string {
+	t.Helper()
+	mux := NewServeMuxConfig(nil).Mux()
+	mux.Handle("/", MethodGet, HandlerFunc(func(w ResponseWriter, r *IncomingRequest) Result {
+		return w.Write(safehtml.HTMLEscaped("response"))
+	}))
+	mux.Handle("/upload", MethodPost, HandlerFunc(func(w ResponseWriter, r *IncomingRequest) Result {
+		if _, err := ioutil.ReadAll(r.Body()); err != nil {
+			return NotWritten()
+		}
+		return w.Write(safehtml.HTMLEscaped("uploaded"))
+	}))
+	s.Mux = mux
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("net.Listen: %v", err)
+	}
+	go s.Serve(l)
+	t.Cleanup(func() { s.Close() })
+	return l.Addr().String()
+}
+
+// sendRequest sends a keep-alive GET request on conn and reads the response.
+func sendRequest(t *testing.T, conn net.Conn) *http.Response {
+	t.Helper()
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+	if _, err := conn.Write([]byte("GET / HTTP/1.1\r\nHost: foo.com\r\n\r\n")); err != nil {
+		t.Fatalf("conn.Write: %v", err)
+	}
+	resp, err := http.ReadResponse(bufio.NewReader(conn), nil)
+	if err != nil {
+		t.Fatalf("http.ReadResponse: %v", err)
+	}
+	ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	return resp
+}
+
+func dial(t *testing.T, addr string) net.Conn {
+	t.Helper()
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatalf("net.Dial: %v", err)
+	}
+	return conn
+}
+
+func TestServerConnectionLimits(t *testing.T) {
+	tests := []struct {
+		name   string
+		server *Server
+	}{
+		{
+			name:   "MaxConnections",
+			server: &Server{MaxConnections: 1},
+		},
+		{
+			name:   "MaxConnectionsPerIP",
+			server: &Server{MaxConnectionsPerIP: 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			addr := startLimitedServer(t, tt.server)
+
+			first := dial(t, addr)
+			if got, want := sendRequest(t, first).StatusCode, http.StatusOK; got != want {
+				t.Errorf("first connection: got %v want %v", got, want)
+			}
+
+			second := dial(t, addr)
+			defer second.Close()
+			resp := sendRequest(t, second)
+			if got, want := resp.StatusCode, http.StatusServiceUnavailable; got != want {
+				t.Errorf("second connection: got %v want %v", got, want)
+			}
+			if !resp.Close {
+				t.Error("second connection: got kept alive, want closed")
+			}
+
+			first.Close()
+			deadline := time.Now().Add(5 * time.Second)
+			for {
+				conn := dial(t, addr)
+				code := sendRequest(t, conn).StatusCode
+				conn.Close()
+				if code == http.StatusOK {
+					break
+				}
+				if time.Now().After(deadline) {
+					t.Fatalf("after closing the first connection: got %v, want %v", code, http.StatusOK)
+				}
+				time.Sleep(time.Millisecond)
+			}
+		})
+	}
+}
+
+func TestServerMinTransferRate(t *testing.T) {
+	addr := startLimitedServer(t, &Server{MinTransferRate: 1000, TransferRateGrace: 50 * time.Millisecond})
+
+	upload := func(body string, trickle bool) int {
+		conn := dial(t, addr)
+		defer conn.Close()
+		conn.SetDeadline(time.Now().Add(5 * time.Second))
+		req := "POST /upload HTTP/1.1\r\nHost: foo.com\r\nContent-Length: 1000\r\n\r\n"
+		if _, err := conn.Write([]byte(req + body)); err != nil {
+			t.Fatalf("conn.Write: %v", err)
+		}
+		if trickle {
+			// Much slower than 1000 bytes per second.
+			for i := 0; i < 3; i++ {
+				time.Sleep(100 * time.Millisecond)
+				conn.Write([]byte("a"))
+			}
+		}
+		resp, err := http.ReadResponse(bufio.NewReader(conn), nil)
+		if err != nil {
+			t.Fatalf("http.ReadResponse: %v", err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	if got, want := upload(strings.Repeat("a", 1000), false), http.StatusOK; got != want {
+		t.Errorf("fast upload: got %v want %v", got, want)
+	}
+	if got, want := upload("a", true), http.StatusRequestTimeout; got != want {
+		t.Errorf("slow upload: got %v want %v", got, want)
+	}
+}
+
+func TestServerReadHeaderTimeout(t *testing.T) {
+	s := Server{Mux: NewServeMuxConfig(nil).Mux(), ReadHeaderTimeout: time.Second}
+	if err := s.buildStd(); err != nil {
+		t.Fatalf("buildStd: %v", err)
+	}
+	if got, want := s.srv.ReadHeaderTimeout, time.Second; got != want {
+		t.Errorf("s.srv.ReadHeaderTimeout: got %v want %v", got, want)
+	}
+}
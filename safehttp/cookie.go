@@ -15,7 +15,9 @@
 package safehttp
 
 import (
+	"errors"
 	"net/http"
+	"strings"
 )
 
 // A Cookie represents an HTTP cookie as sent in the Set-Cookie header of an
@@ -124,3 +126,43 @@ func (c *Cookie) Value() string {
 func (c *Cookie) String() string {
 	return c.wrapped.String()
 }
+
+// ErrInsecureCookie is returned when a cookie with the "__Host-" or
+// "__Secure-" prefix is received on a request that wasn't sent over HTTPS and
+// the CookiePolicy requires secure prefixes. Browsers only set and send such
+// cookies over secure connections, hence they must have been injected.
+var ErrInsecureCookie = errors.New("safehttp: prefixed cookie received on an insecure request")
+
+// ErrCrossSiteCookie is returned when a cookie is received on a cross-site
+// request and the CookiePolicy rejects those.
+var ErrCrossSiteCookie = errors.New("safehttp: cookie received on a cross-site request")
+
+// CookiePolicy controls which cookies of a request are accepted by
+// IncomingRequest.CookieWithPolicy. The zero CookiePolicy accepts all cookies.
+type CookiePolicy struct {
+	// RequireSecurePrefixes rejects the cookies with the "__Host-" or
+	// "__Secure-" prefix received on requests that weren't sent over HTTPS,
+	// unless the framework is in dev mode. The attributes that the prefixes
+	// require (e.g. the Path of "__Host-" cookies) are enforced by the browser
+	// when the cookie is set and aren't sent back with requests.
+	//
+	// It must not be enabled if the server is behind a proxy that terminates
+	// HTTPS traffic, since all the requests would then look insecure.
+	RequireSecurePrefixes bool
+	// RejectCrossSite rejects all the cookies of the requests that the browser
+	// reports as cross-site, through the Sec-Fetch-Site header.
+	RejectCrossSite bool
+}
+
+func (p CookiePolicy) check(r *IncomingRequest, name string) error {
+	if p.RejectCrossSite && r.Header.Get("Sec-Fetch-Site") == "cross-site" {
+		return ErrCrossSiteCookie
+	}
+	if !p.RequireSecurePrefixes || r.TLS != nil || IsLocalDev() {
+		return nil
+	}
+	if strings.HasPrefix(name, "__Host-") || strings.HasPrefix(name, "__Secure-") {
+		return ErrInsecureCookie
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package safehttp
+
+import (
+	"encoding/csv"
+	"net/http"
+	"strings"
+)
+
+// CSVResponse is a CSV document ("text/csv"), e.g. a spreadsheet export.
+//
+// Rows are pulled from Next one at a time and streamed to the client, so
+// exports don't need to be held in memory. The fields are quoted as needed by
+// RFC 4180, and the fields starting with '=', '+', '-', '@', a tab or a
+// carriage return are prefixed with a single quote, so that spreadsheet
+// applications don't evaluate them as formulas (CSV injection). This also
+// applies to negative numbers, which are exported as text.
+//
+// If Next returns an error before anything was sent, 500 Internal Server Error
+// is written. Afterwards the status code can't be changed anymore: the connection
+// is aborted instead, so that the client doesn't mistake the partial export
+// for a complete one.
+type CSVResponse struct {
+	// Header is the first row, if any. It's neutralized like the other rows.
+	Header []string
+	// Next returns the next row, or false when there are no more rows.
+	Next func() (row []string, ok bool, err error)
+	// Filename, if set, makes the response a download saved with this name,
+	// like a DownloadResponse.
+	Filename string
+}
+
+// CSVFromRows creates a CSVResponse writing the given header and rows.
+func CSVFromRows(header []string, rows [][]string) CSVResponse {
+	i := 0
+	return CSVResponse{Header: header, Next: func() ([]string, bool, error) {
+		if i == len(rows) {
+			return nil, false, nil
+		}
+		i++
+		return rows[i-1], true, nil
+	}}
+}
+
+// NeutralizeCSVField returns the field, prefixed with a single quote if
+// spreadsheet applications would interpret it as a formula.
+func NeutralizeCSVField(field string) string {
+	if field != "" && strings.IndexByte("=+-@\t\r", field[0]) >= 0 {
+		return "'" + field
+	}
+	return field
+}
+
+func writeCSV(rw http.ResponseWriter, resp CSVResponse) error {
+	h := rw.Header()
+	h.Set("Content-Type", "text/csv; charset=utf-8")
+	h.Set("X-Content-Type-Options", "nosniff")
+	if resp.Filename != "" {
+		h.Set("Content-Disposition", contentDisposition(resp.Filename))
+	}
+	sw := &sentWriter{w: rw}
+	w := csv.NewWriter(sw)
+	if resp.Header != nil {
+		if err := writeCSVRow(w, resp.Header); err != nil {
+			// The client is gone.
+			return nil
+		}
+	}
+	for {
+		row, ok, err := resp.Next()
+		if err != nil {
+			if !sw.sent {
+				writeTextError(rw, StatusInternalServerError)
+				return nil
+			}
+			w.Flush()
+			return http.ErrAbortHandler
+		}
+		if !ok {
+			w.Flush()
+			return nil
+		}
+		if err := writeCSVRow(w, row); err != nil {
+			return nil
+		}
+	}
+}
+
+func writeCSVRow(w *csv.Writer, row []string) error {
+	neutralized := make([]string, len(row))
+	for i, f := range row {
+		neutralized[i] = NeutralizeCSVField(f)
+	}
+	return w.Write(neutralized)
+}
+
+// sentWriter records whether anything was written to w.
+type sentWriter struct {
+	w    http.ResponseWriter
+	sent bool
+}
+
+func (s *sentWriter) Write(b []byte) (int, error) {
+	s.sent = true
+	return s.w.Write(b)
+}
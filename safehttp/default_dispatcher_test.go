@@ -66,7 +66,7 @@ func TestDefaultDispatcherValidResponse(t *testing.T) {
 							New("associated").Parse("<h2>{{.}}</h2>")))
 				var data interface{}
 				data = "This is an actual heading, though."
-				return d.Write(w, &safehttp.TemplateResponse{t, "associated", data, nil})
+				return d.Write(w, &safehttp.TemplateResponse{t, "associated", data, nil, ""})
 			},
 			wantBody: "<h2>This is an actual heading, though.</h2>",
 		},
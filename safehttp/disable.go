@@ -0,0 +1,61 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package safehttp
+
+import (
+	"reflect"
+	"strings"
+)
+
+// disableInterceptor is exposed as restricted.DisableInterceptor.
+func disableInterceptor(it Interceptor, reason string) InterceptorConfig {
+	if strings.TrimSpace(reason) == "" {
+		panic("DisableInterceptor requires a reason")
+	}
+	return disableConfig{typ: reflect.TypeOf(it), reason: reason}
+}
+
+type disableConfig struct {
+	typ    reflect.Type
+	reason string
+}
+
+// DisabledInterceptor is an interceptor disabled for a route with
+// restricted.DisableInterceptor.
+type DisabledInterceptor struct {
+	Interceptor Interceptor
+	Reason      string
+}
+
+// disableInterceptors removes the interceptors disabled by the given
+// configurations from the list.
+func disableInterceptors(interceptors []Interceptor, cfgs []disableConfig) ([]Interceptor, []DisabledInterceptor) {
+	if len(cfgs) == 0 {
+		return interceptors, nil
+	}
+	var enabled []Interceptor
+	var disabled []DisabledInterceptor
+outer:
+	for _, it := range flattenInterceptors(interceptors) {
+		for _, c := range cfgs {
+			if interceptorType(it) == c.typ {
+				disabled = append(disabled, DisabledInterceptor{Interceptor: it, Reason: c.reason})
+				continue outer
+			}
+		}
+		enabled = append(enabled, it)
+	}
+	return enabled, disabled
+}
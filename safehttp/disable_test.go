@@ -0,0 +1,86 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package safehttp_test
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-safeweb/safehttp"
+	"github.com/google/go-safeweb/safehttp/restricted"
+	"github.com/google/safehtml"
+)
+
+func TestDisableInterceptor(t *testing.T) {
+	var log []string
+	mb := safehttp.NewServeMuxConfig(nil)
+	mb.Intercept(safehttp.Bundle(
+		recordingInterceptor{name: "a", log: &log},
+		setHeaderInterceptor{name: "Foo", value: "bar"},
+	))
+	mux := mb.Mux()
+	h := safehttp.HandlerFunc(func(w safehttp.ResponseWriter, r *safehttp.IncomingRequest) safehttp.Result {
+		return w.Write(safehtml.HTMLEscaped("ok"))
+	})
+	mux.Handle("/enabled", safehttp.MethodGet, h)
+	mux.Handle("/disabled", safehttp.MethodGet, h,
+		restricted.DisableInterceptor(recordingInterceptor{}, "not needed for this route"))
+
+	tests := []struct {
+		path    string
+		wantLog []string
+	}{
+		{path: "/enabled", wantLog: []string{"before a", "commit a"}},
+		{path: "/disabled"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			log = nil
+			rw := httptest.NewRecorder()
+			mux.ServeHTTP(rw, httptest.NewRequest(safehttp.MethodGet, "http://foo.com"+tt.path, nil))
+
+			if got, want := rw.Code, int(safehttp.StatusOK); got != want {
+				t.Errorf("rw.Code: got %v want %v", got, want)
+			}
+			if diff := cmp.Diff(tt.wantLog, log); diff != "" {
+				t.Errorf("log mismatch (-want +got):\n%s", diff)
+			}
+			if got, want := rw.Header().Get("Foo"), "bar"; got != want {
+				t.Errorf(`rw.Header().Get("Foo"): got %q want %q`, got, want)
+			}
+		})
+	}
+
+	var got []string
+	for _, r := range mux.Routes() {
+		for _, d := range r.Disabled {
+			got = append(got, r.Pattern+": "+d.Interceptor.(recordingInterceptor).name+": "+d.Reason)
+		}
+	}
+	want := []string{"/disabled: a: not needed for this route"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("disabled interceptors mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestDisableInterceptorNoReason(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error(`restricted.DisableInterceptor(..., " ") expected panic`)
+		}
+	}()
+	restricted.DisableInterceptor(recordingInterceptor{}, " ")
+}
@@ -0,0 +1,155 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package safehttp
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+)
+
+// WriteFunc writes a response of a type registered in a DispatcherRegistry.
+type WriteFunc func(rw http.ResponseWriter, resp Response) error
+
+// DispatcherRegistry is a Dispatcher that can be extended with new response
+// types, e.g. protobuf messages or MessagePack, without replacing the
+// Dispatcher it wraps:
+//
+//	d := safehttp.NewDispatcherRegistry(nil)
+//	d.Register(MsgpackResponse{}, writeMsgpack)
+//	mb := safehttp.NewServeMuxConfig(d)
+//
+// The responses of a registered type are written by its WriteFunc, the other
+// ones by the wrapped Dispatcher. The WriteFunc must set the Content-Type
+// header before writing the response: to keep the responses safe, the
+// DispatcherRegistry refuses to send a response without a Content-Type or
+// with a type which browsers might render as a document, like HTML, XML or
+// any type which isn't known to be passive (e.g. JSON, protocol buffers,
+// MessagePack, images or text/plain). HTML must be written with templates. It also sets the "X-Content-Type-Options: nosniff" header.
+//
+// Types must be registered before the DispatcherRegistry is used.
+type DispatcherRegistry struct {
+	dispatcher Dispatcher
+	writers    map[reflect.Type]WriteFunc
+}
+
+// NewDispatcherRegistry creates a DispatcherRegistry wrapping the given
+// Dispatcher. If it's nil, the DefaultDispatcher is used.
+func NewDispatcherRegistry(d Dispatcher) *DispatcherRegistry {
+	if d == nil {
+		d = DefaultDispatcher{}
+	}
+	return &DispatcherRegistry{dispatcher: d, writers: map[reflect.Type]WriteFunc{}}
+}
+
+// Register registers the dynamic type of resp, e.g. MyResponse{} or
+// &MyResponse{}, to be written by write. It panics if the type was already
+// registered or if it's one of the response types of this package.
+func (d *DispatcherRegistry) Register(resp Response, write WriteFunc) {
+	t := reflect.TypeOf(resp)
+	if t == nil || write == nil {
+		panic("DispatcherRegistry.Register called with a nil response or WriteFunc")
+	}
+	named := t
+	if named.Kind() == reflect.Ptr {
+		named = named.Elem()
+	}
+	if named.PkgPath() == reflect.TypeOf(DispatcherRegistry{}).PkgPath() {
+		panic(fmt.Sprintf("%v is a built-in response type", t))
+	}
+	if _, ok := d.writers[t]; ok {
+		panic(fmt.Sprintf("%v is already registered", t))
+	}
+	d.writers[t] = write
+}
+
+// Write writes the response with the WriteFunc registered for its type, or
+// with the wrapped Dispatcher.
+func (d *DispatcherRegistry) Write(rw http.ResponseWriter, resp Response) error {
+	if write, ok := d.writers[reflect.TypeOf(resp)]; ok {
+		return writeRegistered(rw, resp, write)
+	}
+	return d.dispatcher.Write(rw, resp)
+}
+
+// Error writes the error response with the WriteFunc registered for its type,
+// or with the wrapped Dispatcher.
+func (d *DispatcherRegistry) Error(rw http.ResponseWriter, resp ErrorResponse) error {
+	if write, ok := d.writers[reflect.TypeOf(resp)]; ok {
+		return writeRegistered(rw, resp, write)
+	}
+	return d.dispatcher.Error(rw, resp)
+}
+
+func writeRegistered(rw http.ResponseWriter, resp Response, write WriteFunc) error {
+	rw.Header().Set("X-Content-Type-Options", "nosniff")
+	cw := &contentTypeCheckWriter{ResponseWriter: rw, resp: resp}
+	err := write(cw, resp)
+	if cw.err != nil {
+		return cw.err
+	}
+	if err != nil {
+		return err
+	}
+	// The WriteFunc might have written nothing.
+	return cw.check()
+}
+
+// contentTypeCheckWriter refuses to send a response without a Content-Type or
+// with a content type which isn't passive.
+type contentTypeCheckWriter struct {
+	http.ResponseWriter
+	resp Response
+
+	checked bool
+	err     error
+}
+
+func (w *contentTypeCheckWriter) check() error {
+	if w.checked {
+		return w.err
+	}
+	w.checked = true
+	switch ct := w.Header().Get("Content-Type"); {
+	case ct == "":
+		w.err = fmt.Errorf("%T was written without a Content-Type", w.resp)
+	case !isPassiveContent(ct):
+		w.err = fmt.Errorf("%T with Content-Type %q cannot be written", w.resp, ct)
+	}
+	return w.err
+}
+
+func (w *contentTypeCheckWriter) WriteHeader(code int) {
+	if w.check() != nil {
+		return
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *contentTypeCheckWriter) Write(b []byte) (int, error) {
+	if err := w.check(); err != nil {
+		return 0, err
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *contentTypeCheckWriter) Flush() {
+	if w.check() != nil {
+		return
+	}
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
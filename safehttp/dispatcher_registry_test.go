@@ -0,0 +1,220 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package safehttp_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-safeweb/safehttp"
+)
+
+type csvRecord struct {
+	Fields []string
+}
+
+type teapotError struct {
+	Reason string
+}
+
+func (teapotError) Code() safehttp.StatusCode {
+	return safehttp.StatusTeapot
+}
+
+func newTestRegistry(contentType string) *safehttp.DispatcherRegistry {
+	d := safehttp.NewDispatcherRegistry(nil)
+	d.Register(csvRecord{}, func(rw http.ResponseWriter, resp safehttp.Response) error {
+		if contentType != "" {
+			rw.Header().Set("Content-Type", contentType)
+		}
+		for i, f := range resp.(csvRecord).Fields {
+			if i > 0 {
+				fmt.Fprint(rw, ",")
+			}
+			fmt.Fprint(rw, f)
+		}
+		return nil
+	})
+	d.Register(&teapotError{}, func(rw http.ResponseWriter, resp safehttp.Response) error {
+		rw.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		rw.WriteHeader(int(safehttp.StatusTeapot))
+		_, err := fmt.Fprint(rw, resp.(*teapotError).Reason)
+		return err
+	})
+	return d
+}
+
+func TestDispatcherRegistry(t *testing.T) {
+	tests := []struct {
+		name        string
+		write       func(w safehttp.ResponseWriter) safehttp.Result
+		wantStatus  safehttp.StatusCode
+		wantHeaders map[string][]string
+		wantBody    string
+	}{
+		{
+			name: "Registered response",
+			write: func(w safehttp.ResponseWriter) safehttp.Result {
+				return w.Write(csvRecord{Fields: []string{"a", "b"}})
+			},
+			wantStatus: safehttp.StatusOK,
+			wantHeaders: map[string][]string{
+				"Content-Type":           {"text/csv; charset=utf-8"},
+				"X-Content-Type-Options": {"nosniff"},
+			},
+			wantBody: "a,b",
+		},
+		{
+			name: "Built-in response",
+			write: func(w safehttp.ResponseWriter) safehttp.Result {
+				return w.Write(safehttp.JSONResponse{Data: "a"})
+			},
+			wantStatus: safehttp.StatusOK,
+			wantHeaders: map[string][]string{
+				"Content-Type": {"application/json; charset=utf-8"},
+			},
+			wantBody: ")]}',\n\"a\"\n",
+		},
+		{
+			name: "Registered error",
+			write: func(w safehttp.ResponseWriter) safehttp.Result {
+				return w.WriteError(&teapotError{Reason: "short and stout"})
+			},
+			wantStatus: safehttp.StatusTeapot,
+			wantHeaders: map[string][]string{
+				"Content-Type":           {"text/plain; charset=utf-8"},
+				"X-Content-Type-Options": {"nosniff"},
+			},
+			wantBody: "short and stout",
+		},
+		{
+			name: "Built-in error",
+			write: func(w safehttp.ResponseWriter) safehttp.Result {
+				return w.WriteError(safehttp.StatusForbidden)
+			},
+			wantStatus: safehttp.StatusForbidden,
+			wantHeaders: map[string][]string{
+				"Content-Type":           {"text/plain; charset=utf-8"},
+				"X-Content-Type-Options": {"nosniff"},
+			},
+			wantBody: "Forbidden\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mux := safehttp.NewServeMuxConfig(newTestRegistry("text/csv; charset=utf-8")).Mux()
+			mux.Handle("/", safehttp.MethodGet, safehttp.HandlerFunc(func(w safehttp.ResponseWriter, r *safehttp.IncomingRequest) safehttp.Result {
+				return tt.write(w)
+			}))
+			rr := httptest.NewRecorder()
+			mux.ServeHTTP(rr, httptest.NewRequest(safehttp.MethodGet, "http://foo.com/", nil))
+
+			if got, want := rr.Code, int(tt.wantStatus); got != want {
+				t.Errorf("rr.Code: got %v want %v", got, want)
+			}
+			for k, want := range tt.wantHeaders {
+				if diff := cmp.Diff(want, rr.Header().Values(k)); diff != "" {
+					t.Errorf("rr.Header().Values(%q) mismatch (-want +got):\n%s", k, diff)
+				}
+			}
+			if got := rr.Body.String(); got != tt.wantBody {
+				t.Errorf("rr.Body: got %q want %q", got, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestDispatcherRegistryUnsafeContentType(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+	}{
+		{
+			name:        "Missing",
+			contentType: "",
+		},
+		{
+			name:        "HTML",
+			contentType: "text/html; charset=utf-8",
+		},
+		{
+			name:        "XSLT",
+			contentType: "application/xslt+xml",
+		},
+		{
+			name:        "Unknown",
+			contentType: "application/x-custom",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			defer func() {
+				if r := recover(); r == nil {
+					t.Error("expected panic")
+				}
+				if got := rr.Body.String(); got != "" {
+					t.Errorf("rr.Body: got %q, want nothing written", got)
+				}
+			}()
+			mux := safehttp.NewServeMuxConfig(newTestRegistry(tt.contentType)).Mux()
+			mux.Handle("/", safehttp.MethodGet, safehttp.HandlerFunc(func(w safehttp.ResponseWriter, r *safehttp.IncomingRequest) safehttp.Result {
+				return w.Write(csvRecord{Fields: []string{"<script>"}})
+			}))
+			mux.ServeHTTP(rr, httptest.NewRequest(safehttp.MethodGet, "http://foo.com/", nil))
+		})
+	}
+}
+
+func TestDispatcherRegistryRegisterPanics(t *testing.T) {
+	tests := []struct {
+		name string
+		resp safehttp.Response
+	}{
+		{
+			name: "Duplicate",
+			resp: csvRecord{},
+		},
+		{
+			name: "Built-in",
+			resp: safehttp.JSONResponse{},
+		},
+		{
+			name: "Built-in pointer",
+			resp: &safehttp.TemplateResponse{},
+		},
+		{
+			name: "Nil",
+			resp: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := newTestRegistry("text/csv")
+			defer func() {
+				if r := recover(); r == nil {
+					t.Error("expected panic")
+				}
+			}()
+			d.Register(tt.resp, func(http.ResponseWriter, safehttp.Response) error { return nil })
+		})
+	}
+}
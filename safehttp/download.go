@@ -0,0 +1,136 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package safehttp
+
+import (
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// DownloadContentTypes are the media types a DownloadResponse can have. It
+// can be extended when the program starts, but it must never include types
+// that browsers render as active content, like HTML or SVG.
+var DownloadContentTypes = []string{
+	"application/octet-stream",
+	"application/json",
+	"application/pdf",
+	"application/zip",
+	"application/gzip",
+	"image/png",
+	"image/jpeg",
+	"image/gif",
+	"image/webp",
+	"text/csv",
+	"text/plain",
+}
+
+// DownloadResponse is used to send content that browsers save as a file
+// rather than display, e.g. a report or an export.
+//
+// The response has a "Content-Disposition: attachment" header with the
+// Filename, and the "X-Content-Type-Options: nosniff" header. Together with
+// the explicit Content-Type, they prevent reflected file download attacks,
+// where the attacker controls the content or the name of the file. The
+// DefaultDispatcher refuses a ContentType which isn't one of the
+// DownloadContentTypes.
+type DownloadResponse struct {
+	// Content is the content of the file.
+	Content io.Reader
+	// Filename is the name browsers save the file as. Path separators,
+	// quotes and control characters are replaced with underscores.
+	Filename string
+	// ContentType is the Content-Type of the response. If empty,
+	// "application/octet-stream" is used.
+	ContentType string
+}
+
+// WriteDownload creates a DownloadResponse and writes it to w.
+func WriteDownload(w ResponseWriter, content io.Reader, filename string) Result {
+	return w.Write(DownloadResponse{Content: content, Filename: filename})
+}
+
+func (resp DownloadResponse) contentType() string {
+	if resp.ContentType != "" {
+		return resp.ContentType
+	}
+	return "application/octet-stream"
+}
+
+func writeDownload(rw http.ResponseWriter, resp DownloadResponse) error {
+	ct := resp.contentType()
+	mt, _, err := mime.ParseMediaType(ct)
+	if err != nil || !downloadAllowed(mt) {
+		return fmt.Errorf("DownloadResponse with Content-Type %q cannot be written", ct)
+	}
+	h := rw.Header()
+	h.Set("Content-Type", ct)
+	h.Set("Content-Disposition", contentDisposition(resp.Filename))
+	h.Set("X-Content-Type-Options", "nosniff")
+	_, err = io.Copy(rw, resp.Content)
+	return err
+}
+
+func downloadAllowed(mt string) bool {
+	for _, t := range DownloadContentTypes {
+		if mt == t {
+			return true
+		}
+	}
+	return false
+}
+
+// contentDisposition returns the attachment Content-Disposition for the
+// filename. The filename parameter holds an ASCII fallback, the filename*
+// parameter the UTF-8 filename, as defined by RFC 6266 and RFC 5987.
+func contentDisposition(filename string) string {
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r < 0x20, r == 0x7f, r == '/', r == '\\', r == '"':
+			return '_'
+		}
+		return r
+	}, filename)
+	if name == "" {
+		return "attachment"
+	}
+	ascii := strings.Map(func(r rune) rune {
+		if r > 0x7e || r == '%' {
+			return '_'
+		}
+		return r
+	}, name)
+	if ascii == name {
+		return fmt.Sprintf("attachment; filename=%q", name)
+	}
+	return fmt.Sprintf("attachment; filename=%q; filename*=UTF-8''%s", ascii, encodeExtValue(name))
+}
+
+// encodeExtValue percent-encodes the bytes of s which aren't an attr-char of
+// RFC 5987.
+func encodeExtValue(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' || strings.IndexByte("!#$&+-.^_`|~", c) >= 0 {
+			b.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&b, "%%%02X", c)
+	}
+	return b.String()
+}
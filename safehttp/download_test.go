@@ -0,0 +1,134 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package safehttp_test
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-safeweb/safehttp"
+)
+
+func TestDownloadResponse(t *testing.T) {
+	tests := []struct {
+		name        string
+		resp        safehttp.DownloadResponse
+		wantHeaders map[string][]string
+	}{
+		{
+			name: "Default Content-Type",
+			resp: safehttp.DownloadResponse{Filename: "report.bin"},
+			wantHeaders: map[string][]string{
+				"Content-Disposition":    {`attachment; filename="report.bin"`},
+				"Content-Type":           {"application/octet-stream"},
+				"X-Content-Type-Options": {"nosniff"},
+			},
+		},
+		{
+			name: "Allowed Content-Type",
+			resp: safehttp.DownloadResponse{Filename: "report.csv", ContentType: "text/csv; charset=utf-8"},
+			wantHeaders: map[string][]string{
+				"Content-Disposition":    {`attachment; filename="report.csv"`},
+				"Content-Type":           {"text/csv; charset=utf-8"},
+				"X-Content-Type-Options": {"nosniff"},
+			},
+		},
+		{
+			name: "Unsafe characters",
+			resp: safehttp.DownloadResponse{Filename: "../\"evil\"\r\n.bat"},
+			wantHeaders: map[string][]string{
+				"Content-Disposition":    {`attachment; filename="..__evil___.bat"`},
+				"Content-Type":           {"application/octet-stream"},
+				"X-Content-Type-Options": {"nosniff"},
+			},
+		},
+		{
+			name: "Non-ASCII",
+			resp: safehttp.DownloadResponse{Filename: "résumé (1).pdf", ContentType: "application/pdf"},
+			wantHeaders: map[string][]string{
+				"Content-Disposition":    {`attachment; filename="r_sum_ (1).pdf"; filename*=UTF-8''r%C3%A9sum%C3%A9%20%281%29.pdf`},
+				"Content-Type":           {"application/pdf"},
+				"X-Content-Type-Options": {"nosniff"},
+			},
+		},
+		{
+			name: "No filename",
+			resp: safehttp.DownloadResponse{},
+			wantHeaders: map[string][]string{
+				"Content-Disposition":    {"attachment"},
+				"Content-Type":           {"application/octet-stream"},
+				"X-Content-Type-Options": {"nosniff"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mux := safehttp.NewServeMuxConfig(nil).Mux()
+			mux.Handle("/", safehttp.MethodGet, safehttp.HandlerFunc(func(w safehttp.ResponseWriter, r *safehttp.IncomingRequest) safehttp.Result {
+				resp := tt.resp
+				resp.Content = strings.NewReader("a,b\n1,2\n")
+				return w.Write(resp)
+			}))
+			rr := httptest.NewRecorder()
+			mux.ServeHTTP(rr, httptest.NewRequest(safehttp.MethodGet, "http://foo.com/", nil))
+
+			if got, want := rr.Code, int(safehttp.StatusOK); got != want {
+				t.Errorf("rr.Code: got %v want %v", got, want)
+			}
+			if diff := cmp.Diff(tt.wantHeaders, map[string][]string(rr.Header())); diff != "" {
+				t.Errorf("rr.Header() mismatch (-want +got):\n%s", diff)
+			}
+			if got, want := rr.Body.String(), "a,b\n1,2\n"; got != want {
+				t.Errorf("response body: got %q want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestWriteDownload(t *testing.T) {
+	mux := safehttp.NewServeMuxConfig(nil).Mux()
+	mux.Handle("/", safehttp.MethodGet, safehttp.HandlerFunc(func(w safehttp.ResponseWriter, r *safehttp.IncomingRequest) safehttp.Result {
+		return safehttp.WriteDownload(w, strings.NewReader("data"), "export.bin")
+	}))
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, httptest.NewRequest(safehttp.MethodGet, "http://foo.com/", nil))
+
+	if got, want := rr.Header().Get("Content-Disposition"), `attachment; filename="export.bin"`; got != want {
+		t.Errorf(`rr.Header().Get("Content-Disposition"): got %q want %q`, got, want)
+	}
+	if got, want := rr.Body.String(), "data"; got != want {
+		t.Errorf("response body: got %q want %q", got, want)
+	}
+}
+
+func TestDownloadResponseActiveContent(t *testing.T) {
+	for _, ct := range []string{"text/html", "image/svg+xml", "application/xhtml+xml", "not a type"} {
+		t.Run(ct, func(t *testing.T) {
+			defer func() {
+				if r := recover(); r == nil {
+					t.Error("expected panic")
+				}
+			}()
+			mux := safehttp.NewServeMuxConfig(nil).Mux()
+			mux.Handle("/", safehttp.MethodGet, safehttp.HandlerFunc(func(w safehttp.ResponseWriter, r *safehttp.IncomingRequest) safehttp.Result {
+				return w.Write(safehttp.DownloadResponse{Content: strings.NewReader("<script>"), Filename: "x.html", ContentType: ct})
+			}))
+			mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(safehttp.MethodGet, "http://foo.com/", nil))
+		})
+	}
+}
@@ -0,0 +1,54 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package safehttp
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// SendEarlyHints sends a 103 Early Hints interim response, see
+// ResponseWriter.SendEarlyHints.
+func (f *flight) SendEarlyHints(links ...string) error {
+	if f.written {
+		return errors.New("SendEarlyHints called after the response was written")
+	}
+	for _, l := range links {
+		if !strings.HasPrefix(l, "<") || strings.ContainsAny(l, "\r\n") {
+			return fmt.Errorf("invalid Link header value %q", l)
+		}
+	}
+	if len(links) == 0 || !f.req.req.ProtoAtLeast(1, 1) {
+		// 1xx responses can't be sent to HTTP/1.0 clients.
+		return nil
+	}
+	// The interim response carries all the headers set so far: only send the
+	// links, then restore the headers for the final response.
+	h := f.rw.Header()
+	saved := make(http.Header, len(h))
+	for k, v := range h {
+		saved[k] = v
+		delete(h, k)
+	}
+	h["Link"] = links
+	f.rw.WriteHeader(http.StatusEarlyHints)
+	delete(h, "Link")
+	for k, v := range saved {
+		h[k] = v
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package safehttp_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/http/httptrace"
+	"net/textproto"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-safeweb/safehttp"
+)
+
+func TestSendEarlyHints(t *testing.T) {
+	mux := safehttp.NewServeMuxConfig(nil).Mux()
+	mux.Handle("/", safehttp.MethodGet, safehttp.HandlerFunc(func(w safehttp.ResponseWriter, r *safehttp.IncomingRequest) safehttp.Result {
+		w.Header().Set("Foo", "bar")
+		if err := w.SendEarlyHints("</app.js>; rel=preload; as=script", "</app.css>; rel=preload; as=style"); err != nil {
+			t.Errorf("w.SendEarlyHints: got err %v", err)
+		}
+		return safehttp.WriteJSON(w, "ok")
+	}))
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	var interim []textproto.MIMEHeader
+	req, err := http.NewRequest(http.MethodGet, srv.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req = req.WithContext(httptrace.WithClientTrace(req.Context(), &httptrace.ClientTrace{
+		Got1xxResponse: func(code int, header textproto.MIMEHeader) error {
+			if code == http.StatusEarlyHints {
+				interim = append(interim, header)
+			}
+			return nil
+		},
+	}))
+	resp, err := srv.Client().Do(req)
+	if err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+	resp.Body.Close()
+
+	want := []textproto.MIMEHeader{{"Link": {"</app.js>; rel=preload; as=script", "</app.css>; rel=preload; as=style"}}}
+	if diff := cmp.Diff(want, interim); diff != "" {
+		t.Errorf("103 responses mismatch (-want +got):\n%s", diff)
+	}
+	if got, want := resp.StatusCode, http.StatusOK; got != want {
+		t.Errorf("resp.StatusCode: got %v want %v", got, want)
+	}
+	if got, want := resp.Header.Get("Foo"), "bar"; got != want {
+		t.Errorf(`resp.Header.Get("Foo"): got %q want %q`, got, want)
+	}
+	if got := resp.Header.Values("Link"); len(got) != 0 {
+		t.Errorf(`resp.Header.Values("Link"): got %v, want none`, got)
+	}
+}
+
+func TestSendEarlyHintsErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler safehttp.HandlerFunc
+	}{
+		{
+			name: "Invalid link",
+			handler: func(w safehttp.ResponseWriter, r *safehttp.IncomingRequest) safehttp.Result {
+				if err := w.SendEarlyHints("/app.js"); err == nil {
+					t.Error("w.SendEarlyHints: got nil err, want error")
+				}
+				return safehttp.NotWritten()
+			},
+		},
+		{
+			name: "After writing",
+			handler: func(w safehttp.ResponseWriter, r *safehttp.IncomingRequest) safehttp.Result {
+				res := safehttp.WriteJSON(w, "ok")
+				if err := w.SendEarlyHints("</app.js>; rel=preload; as=script"); err == nil {
+					t.Error("w.SendEarlyHints: got nil err, want error")
+				}
+				return res
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mux := safehttp.NewServeMuxConfig(nil).Mux()
+			mux.Handle("/", safehttp.MethodGet, tt.handler)
+			mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(safehttp.MethodGet, "http://foo.com/", nil))
+		})
+	}
+}
@@ -0,0 +1,147 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package safehttp
+
+import (
+	"context"
+	"net/http"
+)
+
+// ErrorPages renders the error responses written with ResponseWriter.WriteError
+// as HTML pages, see ServeMuxConfig.RenderErrors.
+type ErrorPages struct {
+	// Template holds the pages, as associated templates. It must be a
+	// safehtml/template.Template. The pages are executed with an
+	// ErrorPageData.
+	Template Template
+	// ByStatus maps status codes to the names of their pages.
+	ByStatus map[StatusCode]string
+	// Default is the name of the page of the status codes that aren't in
+	// ByStatus. If empty, these errors are written as usual.
+	Default string
+	// Layout is the name of the layout the pages are rendered in, if any.
+	// See ExecuteNamedTemplateWithLayout.
+	Layout string
+}
+
+// ErrorPageData is the data error pages are executed with.
+type ErrorPageData struct {
+	// Code is the status code of the error, e.g. 404.
+	Code int
+	// Text is the text of the status code, e.g. "Not Found".
+	Text string
+}
+
+// ErrorPageResponse is an error response rendered as an HTML page. It's an
+// ErrorResponse with the status code of the error.
+type ErrorPageResponse struct {
+	// Error is the error response that is rendered.
+	Error ErrorResponse
+	// Page is the template response of the page.
+	Page *TemplateResponse
+}
+
+// Code implements ErrorResponse.
+func (p *ErrorPageResponse) Code() StatusCode {
+	return p.Error.Code()
+}
+
+// TemplateOf returns the TemplateResponse rendered by resp: resp itself if
+// it's a *TemplateResponse or the Page of an *ErrorPageResponse. Interceptors
+// use it to provide functions to the templates in their Commit phase.
+func TemplateOf(resp Response) (*TemplateResponse, bool) {
+	switch x := resp.(type) {
+	case *TemplateResponse:
+		return x, true
+	case *ErrorPageResponse:
+		return x.Page, x.Page != nil
+	}
+	return nil, false
+}
+
+// RenderErrors makes the ServeMux render the error responses written with
+// ResponseWriter.WriteError as the pages of p, e.g. to brand them.
+//
+// The error is replaced by an *ErrorPageResponse before the Commit phases of
+// the interceptors run, so that they can provide functions to the page (see
+// TemplateOf), e.g. the CSP nonces. The ErrorObservers still see the original
+// error. A *ProblemResponse or a *QueryError is written as usual, since it has
+// its own format.
+//
+// The 404 Not Found response to requests that don't match any pattern doesn't
+// go through the ServeMux, see HandleNotFound to render it too.
+func (s *ServeMuxConfig) RenderErrors(p ErrorPages) {
+	s.errorPages = &p
+}
+
+type errorPagesCtxKey struct{}
+
+func withErrorPages(r *http.Request, p *ErrorPages) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), errorPagesCtxKey{}, p))
+}
+
+// errorPage returns the page resp is rendered as, or nil if it's not rendered.
+func (f *flight) errorPage(resp ErrorResponse) *ErrorPageResponse {
+	p, ok := f.req.Context().Value(errorPagesCtxKey{}).(*ErrorPages)
+	if !ok {
+		return nil
+	}
+	switch resp.(type) {
+	case *ProblemResponse, *QueryError, *ErrorPageResponse:
+		return nil
+	}
+	code := resp.Code()
+	name, ok := p.ByStatus[code]
+	if !ok {
+		name = p.Default
+	}
+	if name == "" {
+		return nil
+	}
+	return &ErrorPageResponse{
+		Error: resp,
+		Page: &TemplateResponse{
+			Template: p.Template,
+			Name:     name,
+			Data:     ErrorPageData{Code: int(code), Text: http.StatusText(int(code))},
+			Layout:   p.Layout,
+		},
+	}
+}
+
+func writeErrorPage(rw http.ResponseWriter, p *ErrorPageResponse) error {
+	rw.Header().Set("X-Content-Type-Options", "nosniff")
+	return DefaultDispatcher{}.Write(&errorPageWriter{ResponseWriter: rw, code: int(p.Code())}, p.Page)
+}
+
+// errorPageWriter writes the status code of the error instead of 200 OK.
+type errorPageWriter struct {
+	http.ResponseWriter
+	code        int
+	wroteHeader bool
+}
+
+func (w *errorPageWriter) WriteHeader(int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+	w.ResponseWriter.WriteHeader(w.code)
+}
+
+func (w *errorPageWriter) Write(b []byte) (int, error) {
+	w.WriteHeader(w.code)
+	return w.ResponseWriter.Write(b)
+}
@@ -0,0 +1,172 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package safehttp_test
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-safeweb/safehttp"
+	"github.com/google/safehtml/template"
+)
+
+// brandInterceptor provides the Brand function to the templates.
+type brandInterceptor struct{}
+
+func (brandInterceptor) Before(w safehttp.ResponseWriter, r *safehttp.IncomingRequest, cfg safehttp.InterceptorConfig) safehttp.Result {
+	return safehttp.NotWritten()
+}
+
+func (brandInterceptor) Commit(w safehttp.ResponseHeadersWriter, r *safehttp.IncomingRequest, resp safehttp.Response, cfg safehttp.InterceptorConfig) {
+	tr, ok := safehttp.TemplateOf(resp)
+	if !ok {
+		return
+	}
+	if _, ok := resp.(safehttp.ErrorResponse); !ok {
+		return
+	}
+	if tr.FuncMap == nil {
+		tr.FuncMap = map[string]interface{}{}
+	}
+	tr.FuncMap["Brand"] = func() string { return "Acme" }
+}
+
+func (brandInterceptor) Match(cfg safehttp.InterceptorConfig) bool {
+	return false
+}
+
+func newErrorPagesTemplate() *template.Template {
+	return template.Must(template.New("").Funcs(template.FuncMap{"Brand": func() string { return "" }}).Parse(`
+{{- define "layout"}}<header>{{Brand}}</header>{{.Content}}{{end -}}
+{{- define "404"}}<h1>We couldn't find that page</h1>{{end -}}
+{{- define "error"}}<h1>{{.Code}} {{.Text}}</h1>{{end -}}
+`))
+}
+
+func TestRenderErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		pages       safehttp.ErrorPages
+		err         safehttp.ErrorResponse
+		wantStatus  safehttp.StatusCode
+		wantHeaders map[string][]string
+		wantBody    string
+	}{
+		{
+			name:       "Status page",
+			pages:      safehttp.ErrorPages{ByStatus: map[safehttp.StatusCode]string{safehttp.StatusNotFound: "404"}, Default: "error"},
+			err:        safehttp.StatusNotFound,
+			wantStatus: safehttp.StatusNotFound,
+			wantHeaders: map[string][]string{
+				"Content-Type":           {"text/html; charset=utf-8"},
+				"X-Content-Type-Options": {"nosniff"},
+			},
+			wantBody: "<h1>We couldn't find that page</h1>",
+		},
+		{
+			name:       "Default page",
+			pages:      safehttp.ErrorPages{ByStatus: map[safehttp.StatusCode]string{safehttp.StatusNotFound: "404"}, Default: "error"},
+			err:        safehttp.StatusForbidden,
+			wantStatus: safehttp.StatusForbidden,
+			wantHeaders: map[string][]string{
+				"Content-Type":           {"text/html; charset=utf-8"},
+				"X-Content-Type-Options": {"nosniff"},
+			},
+			wantBody: "<h1>403 Forbidden</h1>",
+		},
+		{
+			name:       "Layout",
+			pages:      safehttp.ErrorPages{Default: "error", Layout: "layout"},
+			err:        safehttp.StatusInternalServerError,
+			wantStatus: safehttp.StatusInternalServerError,
+			wantHeaders: map[string][]string{
+				"Content-Type":           {"text/html; charset=utf-8"},
+				"X-Content-Type-Options": {"nosniff"},
+			},
+			wantBody: "<header>Acme</header><h1>500 Internal Server Error</h1>",
+		},
+		{
+			name:       "No page",
+			pages:      safehttp.ErrorPages{ByStatus: map[safehttp.StatusCode]string{safehttp.StatusNotFound: "404"}},
+			err:        safehttp.StatusForbidden,
+			wantStatus: safehttp.StatusForbidden,
+			wantHeaders: map[string][]string{
+				"Content-Type":           {"text/plain; charset=utf-8"},
+				"X-Content-Type-Options": {"nosniff"},
+			},
+			wantBody: "Forbidden\n",
+		},
+		{
+			name:       "Problem",
+			pages:      safehttp.ErrorPages{Default: "error"},
+			err:        &safehttp.ProblemResponse{Status: safehttp.StatusConflict},
+			wantStatus: safehttp.StatusConflict,
+			wantHeaders: map[string][]string{
+				"Content-Type":           {"application/problem+json"},
+				"X-Content-Type-Options": {"nosniff"},
+			},
+			wantBody: `{"status":409,"title":"Conflict","type":"about:blank"}` + "\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mb := safehttp.NewServeMuxConfig(nil)
+			mb.Intercept(brandInterceptor{})
+			tt.pages.Template = newErrorPagesTemplate()
+			mb.RenderErrors(tt.pages)
+			mux := mb.Mux()
+			mux.Handle("/", safehttp.MethodGet, safehttp.HandlerFunc(func(w safehttp.ResponseWriter, r *safehttp.IncomingRequest) safehttp.Result {
+				return w.WriteError(tt.err)
+			}))
+			rr := httptest.NewRecorder()
+			mux.ServeHTTP(rr, httptest.NewRequest(safehttp.MethodGet, "http://foo.com/", nil))
+
+			if got, want := rr.Code, int(tt.wantStatus); got != want {
+				t.Errorf("rr.Code: got %v want %v", got, want)
+			}
+			if diff := cmp.Diff(tt.wantHeaders, map[string][]string(rr.Header())); diff != "" {
+				t.Errorf("rr.Header() mismatch (-want +got):\n%s", diff)
+			}
+			if got := rr.Body.String(); got != tt.wantBody {
+				t.Errorf("response body: got %q want %q", got, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestRenderErrorsCommit(t *testing.T) {
+	var log []string
+	mb := safehttp.NewServeMuxConfig(nil)
+	mb.Intercept(recordingInterceptor{name: "a", log: &log})
+	mb.RenderErrors(safehttp.ErrorPages{Template: newErrorPagesTemplate(), Default: "error"})
+	mux := mb.Mux()
+	mux.Handle("/", safehttp.MethodGet, safehttp.HandlerFunc(func(w safehttp.ResponseWriter, r *safehttp.IncomingRequest) safehttp.Result {
+		return w.WriteError(safehttp.StatusBadRequest)
+	}))
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, httptest.NewRequest(safehttp.MethodGet, "http://foo.com/", nil))
+
+	if diff := cmp.Diff([]string{"before a", "commit a"}, log); diff != "" {
+		t.Errorf("log mismatch (-want +got):\n%s", diff)
+	}
+	if got, want := rr.Header().Get("Commit"), "a"; got != want {
+		t.Errorf(`rr.Header().Get("Commit"): got %q want %q`, got, want)
+	}
+	if got, want := rr.Body.String(), "<h1>400 Bad Request</h1>"; got != want {
+		t.Errorf("response body: got %q want %q", got, want)
+	}
+}
@@ -0,0 +1,32 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package safehttp
+
+import "testing"
+
+// SetLocalDevForTest sets the local development mode for the duration of the
+// test, bypassing the check that UseLocalDev is called before any Mux is
+// created.
+func SetLocalDevForTest(t testing.TB, dev bool) {
+	devMu.Lock()
+	old := isLocalDev
+	isLocalDev = dev
+	devMu.Unlock()
+	t.Cleanup(func() {
+		devMu.Lock()
+		isLocalDev = old
+		devMu.Unlock()
+	})
+}
@@ -0,0 +1,65 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package safehttp
+
+import (
+	"io"
+	"mime"
+	"path"
+	"time"
+)
+
+// FileResponse is used to serve the content of a file, or of any seekable
+// content, with support for range requests: the DefaultDispatcher honors the
+// Range and If-Range headers, answering with 206 Partial Content and, for
+// multiple ranges, a multipart/byteranges body. Conditional requests based on
+// ModTime are answered as well.
+//
+// The Content-Type is ContentType or, if empty, is derived from the
+// extension of Name, falling back to "application/octet-stream". Content is
+// never sniffed. Only types which browsers don't render as a document, like
+// images, video, text/plain or application/json, are served by the
+// DefaultDispatcher: HTML, XML (e.g. RSS feeds or XSLT stylesheets) and
+// unknown types are refused. Use templates, FileServer or DownloadResponse
+// for them.
+type FileResponse struct {
+	// Request is the matching request for which this response is being
+	// written. It is used to evaluate the Range and conditional headers.
+	Request *IncomingRequest
+	// Name is the name of the file.
+	Name string
+	// ModTime is the modification time of the content, used for the
+	// Last-Modified header, if not zero.
+	ModTime time.Time
+	// Content is the content to serve.
+	Content io.ReadSeeker
+	// ContentType is the Content-Type of the response.
+	ContentType string
+}
+
+// WriteFile creates a FileResponse and writes it to w.
+func WriteFile(w ResponseWriter, r *IncomingRequest, name string, modTime time.Time, content io.ReadSeeker) Result {
+	return w.Write(FileResponse{Request: r, Name: name, ModTime: modTime, Content: content})
+}
+
+func (resp FileResponse) contentType() string {
+	if resp.ContentType != "" {
+		return resp.ContentType
+	}
+	if ct := mime.TypeByExtension(path.Ext(resp.Name)); ct != "" {
+		return ct
+	}
+	return "application/octet-stream"
+}
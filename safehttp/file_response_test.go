@@ -0,0 +1,191 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package safehttp_test
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-safeweb/safehttp"
+)
+
+func serveFile(t *testing.T, resp safehttp.FileResponse, headers map[string]string) *httptest.ResponseRecorder {
+	t.Helper()
+	mux := safehttp.NewServeMuxConfig(nil).Mux()
+	mux.Handle("/", safehttp.MethodGet, safehttp.HandlerFunc(func(w safehttp.ResponseWriter, r *safehttp.IncomingRequest) safehttp.Result {
+		resp.Request = r
+		return w.Write(resp)
+	}))
+	req := httptest.NewRequest(safehttp.MethodGet, "http://foo.com/", nil)
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, req)
+	return rr
+}
+
+func TestFileResponse(t *testing.T) {
+	modTime := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	tests := []struct {
+		name      string
+		file      string
+		ct        string
+		headers   map[string]string
+		wantCode  safehttp.StatusCode
+		wantCT    string
+		wantRange string
+		wantBody  string
+	}{
+		{
+			name:     "Full",
+			file:     "video.mp4",
+			wantCode: safehttp.StatusOK,
+			wantCT:   "video/mp4",
+			wantBody: "0123456789",
+		},
+		{
+			name:      "Range",
+			file:      "video.mp4",
+			headers:   map[string]string{"Range": "bytes=2-5"},
+			wantCode:  safehttp.StatusPartialContent,
+			wantCT:    "video/mp4",
+			wantRange: "bytes 2-5/10",
+			wantBody:  "2345",
+		},
+		{
+			name:      "Suffix range",
+			file:      "data.bin",
+			ct:        "application/zip",
+			headers:   map[string]string{"Range": "bytes=-3"},
+			wantCode:  safehttp.StatusPartialContent,
+			wantCT:    "application/zip",
+			wantRange: "bytes 7-9/10",
+			wantBody:  "789",
+		},
+		{
+			name:     "If-Range mismatch",
+			file:     "video.mp4",
+			headers:  map[string]string{"Range": "bytes=2-5", "If-Range": "Thu, 01 Jan 2026 00:00:00 GMT"},
+			wantCode: safehttp.StatusOK,
+			wantCT:   "video/mp4",
+			wantBody: "0123456789",
+		},
+		{
+			name:      "If-Range match",
+			file:      "video.mp4",
+			headers:   map[string]string{"Range": "bytes=2-5", "If-Range": "Fri, 02 Jan 2026 03:04:05 GMT"},
+			wantCode:  safehttp.StatusPartialContent,
+			wantCT:    "video/mp4",
+			wantRange: "bytes 2-5/10",
+			wantBody:  "2345",
+		},
+		{
+			name:     "Unknown extension",
+			file:     "blob",
+			wantCode: safehttp.StatusOK,
+			wantCT:   "application/octet-stream",
+			wantBody: "0123456789",
+		},
+		{
+			name:      "Not satisfiable",
+			file:      "video.mp4",
+			headers:   map[string]string{"Range": "bytes=20-30"},
+			wantCode:  safehttp.StatusRequestedRangeNotSatisfiable,
+			wantCT:    "text/plain; charset=utf-8",
+			wantRange: "bytes */10",
+			wantBody:  "invalid range: failed to overlap\n",
+		},
+		{
+			name:     "Not modified",
+			file:     "video.mp4",
+			headers:  map[string]string{"If-Modified-Since": "Fri, 02 Jan 2026 03:04:05 GMT"},
+			wantCode: safehttp.StatusNotModified,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := serveFile(t, safehttp.FileResponse{
+				Name:        tt.file,
+				ModTime:     modTime,
+				Content:     strings.NewReader("0123456789"),
+				ContentType: tt.ct,
+			}, tt.headers)
+
+			if got, want := rr.Code, int(tt.wantCode); got != want {
+				t.Errorf("rr.Code: got %v want %v", got, want)
+			}
+			if got := rr.Header().Get("Content-Type"); tt.wantCT != "" && got != tt.wantCT {
+				t.Errorf(`rr.Header().Get("Content-Type"): got %q want %q`, got, tt.wantCT)
+			}
+			if got := rr.Header().Get("Content-Range"); got != tt.wantRange {
+				t.Errorf(`rr.Header().Get("Content-Range"): got %q want %q`, got, tt.wantRange)
+			}
+			if got := rr.Body.String(); got != tt.wantBody {
+				t.Errorf("rr.Body: got %q want %q", got, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestFileResponseMultipleRanges(t *testing.T) {
+	rr := serveFile(t, safehttp.FileResponse{
+		Name:    "video.mp4",
+		Content: strings.NewReader("0123456789"),
+	}, map[string]string{"Range": "bytes=0-1,8-9"})
+
+	if got, want := rr.Code, int(safehttp.StatusPartialContent); got != want {
+		t.Errorf("rr.Code: got %v want %v", got, want)
+	}
+	if got := rr.Header().Get("Content-Type"); !strings.HasPrefix(got, "multipart/byteranges; boundary=") {
+		t.Errorf(`rr.Header().Get("Content-Type"): got %q want multipart/byteranges`, got)
+	}
+	body := rr.Body.String()
+	for _, want := range []string{"Content-Range: bytes 0-1/10\r\nContent-Type: video/mp4\r\n\r\n01", "Content-Range: bytes 8-9/10\r\nContent-Type: video/mp4\r\n\r\n89"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("rr.Body: got %q, want it to contain %q", body, want)
+		}
+	}
+}
+
+func TestFileResponseActiveContent(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+		ct   string
+	}{
+		{name: "HTML", file: "index.html"},
+		{name: "SVG", file: "image.svg"},
+		{name: "RSS", file: "feed.rss", ct: "application/rss+xml"},
+		{name: "XSLT", file: "style.xslt", ct: "application/xslt+xml"},
+		{name: "Unknown type", file: "data.bin", ct: "application/x-custom"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if r := recover(); r == nil {
+					t.Errorf("writing a FileResponse with %q expected panic", tt.file)
+				}
+			}()
+			serveFile(t, safehttp.FileResponse{
+				Name:        tt.file,
+				Content:     strings.NewReader("<script>alert(1)</script>"),
+				ContentType: tt.ct,
+			}, nil)
+		})
+	}
+}
@@ -16,7 +16,11 @@ package safehttp
 
 import (
 	"context"
+	"errors"
+	"io"
 	"net/http"
+	"strings"
+	"time"
 )
 
 // A single request "flight".
@@ -30,6 +34,17 @@ type flight struct {
 	header Header
 
 	written bool
+	// dispatched is set when the response is passed to the Dispatcher, after
+	// which it can't be reset.
+	dispatched bool
+
+	// before is the number of interceptors whose Before phase ran before the
+	// current stage of the request processing.
+	before int
+	// observing is set while the ErrorObservers run.
+	observing bool
+
+	trace *interceptorTrace
 }
 
 // handlerConfig is the safe HTTP handler configuration, including the
@@ -38,15 +53,55 @@ type handlerConfig struct {
 	Handler      Handler
 	Dispatcher   Dispatcher
 	Interceptors []configuredInterceptor
+	// Trace enables tracing of the interceptors' decisions. See
+	// ServeMuxConfig.TraceInterceptors.
+	Trace bool
+	// Timeout is the time limit for processing the request, if positive. See
+	// WithTimeout.
+	Timeout time.Duration
+	// Disabled lists the interceptors disabled with restricted.DisableInterceptor.
+	Disabled []DisabledInterceptor
+	// NoCompression is set by DisableCompression.
+	NoCompression bool
+	// ResponseLimit is set by WithMaxResponseSize.
+	ResponseLimit *responseLimit
 }
 
-func processRequest(cfg handlerConfig, rw http.ResponseWriter, req *http.Request) {
+func processRequest(cfg handlerConfig, rw http.ResponseWriter, req *http.Request, match routeMatch) {
+	// The context is always canceled once the request is processed, even if
+	// the handler panics, so that interceptors can rely on it to release
+	// per-request resources.
+	var (
+		ctx    context.Context
+		cancel context.CancelFunc
+	)
+	if cfg.Timeout > 0 {
+		ctx, cancel = context.WithTimeout(req.Context(), cfg.Timeout)
+	} else {
+		ctx, cancel = context.WithCancel(req.Context())
+	}
+	defer cancel()
+	req = req.WithContext(ctx)
 	f := &flight{
 		cfg:    cfg,
 		rw:     rw,
 		header: NewHeader(rw.Header()),
 		req:    NewIncomingRequest(req),
 	}
+	f.req.wildcard = match.wildcard
+	if m, ok := req.Context().Value(originalMethodCtxKey{}).(string); ok {
+		f.req.originalMethod = m
+	}
+	f.req.pathParams = match.params
+	if rate, ok := req.Context().Value(transferRateCtxKey{}).(*transferRate); ok {
+		f.req.req.Body = newMinRateBody(f.req, rate, rw.Header())
+	}
+	if max, ok := req.Context().Value(maxBodyBytesCtxKey{}).(int64); ok {
+		f.req.req.Body = f.req.LimitedBody(BodyLimits{MaxBytes: max})
+	}
+	if cfg.Trace {
+		f.trace = newInterceptorTrace(f.req)
+	}
 
 	// The net/http package handles all panics. In the early days of the
 	// framework we were handling them ourselves and running interceptors after
@@ -63,31 +118,93 @@ func processRequest(cfg handlerConfig, rw http.ResponseWriter, req *http.Request
 		}
 	}()
 
-	for _, it := range f.cfg.Interceptors {
+	for i, it := range f.cfg.Interceptors {
+		f.before = i
+		f.trace.begin(it.interceptor)
 		it.Before(f, f.req)
+		f.trace.end()
 		if f.written {
 			return
 		}
 	}
+	f.before = len(f.cfg.Interceptors)
 	f.cfg.Handler.ServeHTTP(f, f.req)
 	if !f.written {
+		if f.timedOut() {
+			f.WriteError(StatusGatewayTimeout)
+			return
+		}
+		if err := f.req.bodyStatus.get(); err != nil {
+			f.WriteError(bodyErrorStatus(err))
+			return
+		}
 		cfg.Dispatcher.Write(rw, NoContentResponse{})
 	}
 }
 
+// timedOut reports whether the timeout set with WithTimeout expired.
+func (f *flight) timedOut() bool {
+	return f.cfg.Timeout > 0 && errors.Is(f.req.Context().Err(), context.DeadlineExceeded)
+}
+
 // Write dispatches the response to the Dispatcher. This will be written to the
 // underlying http.ResponseWriter if the Dispatcher decides it's safe to do so.
 func (f *flight) Write(resp Response) Result {
 	if f.written {
 		panic("ResponseWriter was already written to")
 	}
+	if f.timedOut() {
+		// The response is too late.
+		return f.WriteError(StatusGatewayTimeout)
+	}
 	f.written = true
 	f.commitPhase(resp)
+	if f.dispatched {
+		// The response was replaced in the Commit phase.
+		return Result{}
+	}
+	// The response might have been reset, but not replaced, in the Commit
+	// phase.
+	f.written = true
+	f.trace.written(f.header, resp)
+
+	f.dispatched = true
+	f.dispatch(resp)
+	return Result{}
+}
 
-	if err := f.cfg.Dispatcher.Write(f.rw, resp); err != nil {
+// dispatch passes the response to the Dispatcher, compressing it and
+// limiting its size if configured.
+func (f *flight) dispatch(resp Response) {
+	var rw http.ResponseWriter = f.rw
+	cw := newCompressWriter(f, resp)
+	if cw != nil {
+		rw = cw
+	}
+	lw := newLimitWriter(f, rw, resp)
+	if lw != nil {
+		rw = lw
+	}
+	err := f.cfg.Dispatcher.Write(rw, resp)
+	if lw != nil {
+		abort, ferr := lw.finish()
+		if abort {
+			panic(http.ErrAbortHandler)
+		}
+		if lw.replaced {
+			// The response was replaced with an error.
+			return
+		}
+		if err == nil {
+			err = ferr
+		}
+	}
+	if err == nil && cw != nil {
+		err = cw.Close()
+	}
+	if err != nil {
 		panic(err)
 	}
-	return Result{}
 }
 
 // WriteError writes an error response (400-599) according to the provided
@@ -98,8 +215,26 @@ func (f *flight) WriteError(resp ErrorResponse) Result {
 	if f.written {
 		panic("ResponseWriter was already written to")
 	}
+	if !f.observing {
+		f.observeError(resp)
+		if f.written {
+			// The response was replaced by an ErrorObserver.
+			return Result{}
+		}
+	}
 	f.written = true
+	if page := f.errorPage(resp); page != nil {
+		resp = page
+	}
 	f.commitPhase(resp)
+	if f.dispatched {
+		// The response was replaced in the Commit phase.
+		return Result{}
+	}
+	f.written = true
+	f.trace.written(f.header, resp)
+
+	f.dispatched = true
 	if err := f.cfg.Dispatcher.Error(f.rw, resp); err != nil {
 		panic(err)
 	}
@@ -119,6 +254,36 @@ func (f *flight) AddCookie(c *Cookie) error {
 	return f.header.addCookie(c)
 }
 
+// Reset discards the headers and cookies set so far, except for the claimed
+// headers, and allows a new response to be written. It returns
+// ErrResponseFlushed if the response was already passed to the Dispatcher.
+func (f *flight) Reset() error {
+	if f.dispatched {
+		return ErrResponseFlushed
+	}
+	f.header.reset()
+	f.written = false
+	return nil
+}
+
+// SendContinue sends a 100 Continue interim response if the request has the
+// "Expect: 100-continue" header and the body wasn't read yet.
+func (f *flight) SendContinue() error {
+	if f.written {
+		return errors.New("SendContinue called after the response was written")
+	}
+	if !strings.EqualFold(f.req.req.Header.Get("Expect"), "100-continue") {
+		return nil
+	}
+	// The net/http server sends the 100 Continue response on the first read of
+	// the body, if the handler didn't write a response yet.
+	_, err := f.req.req.Body.Read(nil)
+	if err == io.EOF {
+		return nil
+	}
+	return err
+}
+
 // commitPhase calls the Commit phases of all the interceptors. This stage will
 // run before a response is written to the ResponseWriter. If a response is
 // written to the ResponseWriter in a Commit phase then the Commit phases of the
@@ -126,6 +291,10 @@ func (f *flight) AddCookie(c *Cookie) error {
 func (f *flight) commitPhase(resp Response) {
 	for i := len(f.cfg.Interceptors) - 1; i >= 0; i-- {
 		f.cfg.Interceptors[i].Commit(f, f.req, resp)
+		if f.dispatched {
+			// The response was replaced.
+			return
+		}
 	}
 }
 
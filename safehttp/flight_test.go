@@ -15,10 +15,19 @@
 package safehttp_test
 
 import (
+	"bufio"
 	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
 	"net/http/httptest"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-safeweb/safehttp"
 	"github.com/google/safehtml"
 )
@@ -163,3 +172,226 @@ func TestFlightDoubleWritePanics(t *testing.T) {
 	}
 
 }
+
+func TestFlightResetBeforeFlush(t *testing.T) {
+	mb := safehttp.NewServeMuxConfig(nil)
+	mb.Intercept(&claimHeaderInterceptor{headerToClaim: "Claimed"})
+	mux := mb.Mux()
+	mux.Handle("/search", safehttp.MethodGet, safehttp.HandlerFunc(func(w safehttp.ResponseWriter, r *safehttp.IncomingRequest) safehttp.Result {
+		claimInterceptorSetHeader(w, r, "kept")
+		w.Header().Set("Foo", "bar")
+		if err := w.AddCookie(safehttp.NewCookie("session", "secret")); err != nil {
+			t.Fatalf("w.AddCookie() got err: %v", err)
+		}
+		if err := w.Reset(); err != nil {
+			t.Errorf("w.Reset() got err: %v, want nil", err)
+		}
+		return w.WriteError(safehttp.StatusForbidden)
+	}))
+
+	rw := httptest.NewRecorder()
+	mux.ServeHTTP(rw, httptest.NewRequest(safehttp.MethodGet, "http://foo.com/search", nil))
+
+	if got, want := rw.Code, int(safehttp.StatusForbidden); got != want {
+		t.Errorf("rw.Code: got %v want %v", got, want)
+	}
+	wantHeaders := map[string][]string{
+		"Claimed":                {"kept"},
+		"Content-Type":           {"text/plain; charset=utf-8"},
+		"X-Content-Type-Options": {"nosniff"},
+	}
+	if diff := cmp.Diff(wantHeaders, map[string][]string(rw.Header())); diff != "" {
+		t.Errorf("rw.Header() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestFlightResetAfterFlush(t *testing.T) {
+	mux := safehttp.NewServeMuxConfig(nil).Mux()
+	mux.Handle("/search", safehttp.MethodGet, safehttp.HandlerFunc(func(w safehttp.ResponseWriter, r *safehttp.IncomingRequest) safehttp.Result {
+		res := w.Write(safehtml.HTMLEscaped("Hello"))
+		if err := w.Reset(); err != safehttp.ErrResponseFlushed {
+			t.Errorf("w.Reset() got err: %v, want %v", err, safehttp.ErrResponseFlushed)
+		}
+		return res
+	}))
+
+	rw := httptest.NewRecorder()
+	mux.ServeHTTP(rw, httptest.NewRequest(safehttp.MethodGet, "http://foo.com/search", nil))
+
+	if got, want := rw.Code, int(safehttp.StatusOK); got != want {
+		t.Errorf("rw.Code: got %v want %v", got, want)
+	}
+	if got, want := rw.Body.String(), "Hello"; got != want {
+		t.Errorf("rw.Body.String(): got %q want %q", got, want)
+	}
+}
+
+// replacingInterceptor replaces all non-error responses with a 500 error in
+// the Commit phase.
+type replacingInterceptor struct {
+	commits *int
+}
+
+func (replacingInterceptor) Before(w safehttp.ResponseWriter, _ *safehttp.IncomingRequest, cfg safehttp.InterceptorConfig) safehttp.Result {
+	return safehttp.NotWritten()
+}
+
+func (p replacingInterceptor) Commit(w safehttp.ResponseHeadersWriter, r *safehttp.IncomingRequest, resp safehttp.Response, cfg safehttp.InterceptorConfig) {
+	*p.commits++
+	if _, ok := resp.(safehttp.ErrorResponse); ok {
+		return
+	}
+	rw := w.(safehttp.ResponseWriter)
+	if err := rw.Reset(); err != nil {
+		panic(err)
+	}
+	rw.WriteError(safehttp.StatusInternalServerError)
+}
+
+func (replacingInterceptor) Match(safehttp.InterceptorConfig) bool {
+	return false
+}
+
+func TestFlightResetInCommit(t *testing.T) {
+	var commits int
+	mb := safehttp.NewServeMuxConfig(nil)
+	mb.Intercept(replacingInterceptor{commits: &commits})
+	mux := mb.Mux()
+	mux.Handle("/search", safehttp.MethodGet, safehttp.HandlerFunc(func(w safehttp.ResponseWriter, r *safehttp.IncomingRequest) safehttp.Result {
+		w.Header().Set("Foo", "bar")
+		return w.Write(safehtml.HTMLEscaped("Hello"))
+	}))
+
+	rw := httptest.NewRecorder()
+	mux.ServeHTTP(rw, httptest.NewRequest(safehttp.MethodGet, "http://foo.com/search", nil))
+
+	if got, want := rw.Code, int(safehttp.StatusInternalServerError); got != want {
+		t.Errorf("rw.Code: got %v want %v", got, want)
+	}
+	if got, want := rw.Body.String(), "Internal Server Error\n"; got != want {
+		t.Errorf("rw.Body.String(): got %q want %q", got, want)
+	}
+	if got := rw.Header().Get("Foo"); got != "" {
+		t.Errorf(`rw.Header().Get("Foo"): got %q want ""`, got)
+	}
+	// Once for the original response and once for the replacement.
+	if got, want := commits, 2; got != want {
+		t.Errorf("commits: got %v want %v", got, want)
+	}
+}
+
+// maxLengthInterceptor rejects requests declaring a body longer than max
+// without reading it.
+type maxLengthInterceptor struct {
+	max int
+}
+
+func (p maxLengthInterceptor) Before(w safehttp.ResponseWriter, r *safehttp.IncomingRequest, cfg safehttp.InterceptorConfig) safehttp.Result {
+	if n, err := strconv.Atoi(r.Header.Get("Content-Length")); err != nil || n > p.max {
+		return w.WriteError(safehttp.StatusRequestEntityTooLarge)
+	}
+	return safehttp.NotWritten()
+}
+
+func (maxLengthInterceptor) Commit(w safehttp.ResponseHeadersWriter, r *safehttp.IncomingRequest, resp safehttp.Response, cfg safehttp.InterceptorConfig) {
+}
+
+func (maxLengthInterceptor) Match(safehttp.InterceptorConfig) bool {
+	return false
+}
+
+func newContinueServer(t *testing.T, continued chan struct{}) *httptest.Server {
+	t.Helper()
+	mb := safehttp.NewServeMuxConfig(nil)
+	mb.Intercept(maxLengthInterceptor{max: 10})
+	mux := mb.Mux()
+	mux.Handle("/upload", safehttp.MethodPost, safehttp.HandlerFunc(func(w safehttp.ResponseWriter, r *safehttp.IncomingRequest) safehttp.Result {
+		if err := w.SendContinue(); err != nil {
+			t.Errorf("w.SendContinue() got err: %v", err)
+		}
+		// Only read the body after the client received the 100 Continue,
+		// so that it's not sent implicitly.
+		<-continued
+		b, err := ioutil.ReadAll(r.Body())
+		if err != nil {
+			t.Errorf("ioutil.ReadAll(r.Body()) got err: %v", err)
+		}
+		return w.Write(safehtml.HTMLEscaped(string(b)))
+	}))
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func sendExpectContinue(t *testing.T, srv *httptest.Server, length int) (net.Conn, *bufio.Reader) {
+	t.Helper()
+	conn, err := net.Dial("tcp", srv.Listener.Addr().String())
+	if err != nil {
+		t.Fatalf("net.Dial() got err: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+	fmt.Fprintf(conn, "POST /upload HTTP/1.1\r\nHost: foo.com\r\nContent-Length: %d\r\nExpect: 100-continue\r\n\r\n", length)
+	return conn, bufio.NewReader(conn)
+}
+
+func TestFlightSendContinue(t *testing.T) {
+	continued := make(chan struct{})
+	srv := newContinueServer(t, continued)
+	conn, br := sendExpectContinue(t, srv, 5)
+
+	resp, err := http.ReadResponse(br, nil)
+	if err != nil {
+		t.Fatalf("http.ReadResponse() got err: %v", err)
+	}
+	if got, want := resp.StatusCode, int(safehttp.StatusContinue); got != want {
+		t.Fatalf("interim response status: got %v want %v", got, want)
+	}
+	close(continued)
+	io.WriteString(conn, "hello")
+
+	resp, err = http.ReadResponse(br, nil)
+	if err != nil {
+		t.Fatalf("http.ReadResponse() got err: %v", err)
+	}
+	defer resp.Body.Close()
+	if got, want := resp.StatusCode, int(safehttp.StatusOK); got != want {
+		t.Errorf("resp.StatusCode: got %v want %v", got, want)
+	}
+	if b, _ := ioutil.ReadAll(resp.Body); string(b) != "hello" {
+		t.Errorf("resp.Body: got %q want %q", b, "hello")
+	}
+}
+
+func TestFlightExpectContinueRejected(t *testing.T) {
+	continued := make(chan struct{})
+	close(continued)
+	srv := newContinueServer(t, continued)
+	_, br := sendExpectContinue(t, srv, 100)
+
+	// The request is rejected before the body is sent, so the first response
+	// is the final one.
+	resp, err := http.ReadResponse(br, nil)
+	if err != nil {
+		t.Fatalf("http.ReadResponse() got err: %v", err)
+	}
+	defer resp.Body.Close()
+	if got, want := resp.StatusCode, int(safehttp.StatusRequestEntityTooLarge); got != want {
+		t.Errorf("resp.StatusCode: got %v want %v", got, want)
+	}
+}
+
+func TestFlightSendContinueAfterWrite(t *testing.T) {
+	mux := safehttp.NewServeMuxConfig(nil).Mux()
+	mux.Handle("/upload", safehttp.MethodPost, safehttp.HandlerFunc(func(w safehttp.ResponseWriter, r *safehttp.IncomingRequest) safehttp.Result {
+		res := w.Write(safehttp.NoContentResponse{})
+		if err := w.SendContinue(); err == nil {
+			t.Error("w.SendContinue() got nil, want error")
+		}
+		return res
+	}))
+
+	req := httptest.NewRequest(safehttp.MethodPost, "http://foo.com/upload", strings.NewReader("hello"))
+	req.Header.Set("Expect", "100-continue")
+	mux.ServeHTTP(httptest.NewRecorder(), req)
+}
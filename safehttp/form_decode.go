@@ -0,0 +1,216 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package safehttp
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// DefaultMultipartMaxMemory is the maxMemory used by DecodeForm to parse
+// multipart forms.
+const DefaultMultipartMaxMemory = 32 << 20
+
+// FieldError is an error decoding a field of a struct with DecodeForm.
+type FieldError struct {
+	// Field is the name of the form parameter.
+	Field string
+	Err   error
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Field, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// FieldErrors are the errors of all the fields that couldn't be decoded by
+// DecodeForm.
+type FieldErrors []*FieldError
+
+func (es FieldErrors) Error() string {
+	msgs := make([]string, 0, len(es))
+	for _, e := range es {
+		msgs = append(msgs, e.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// ErrMissingParameter is the error of a required parameter that is missing.
+var ErrMissingParameter = errors.New("missing required parameter")
+
+// DecodeForm populates the struct pointed to by dst with the query parameters
+// of the request and, for POST, PATCH and PUT requests, the parameters of the
+// form in its body (url-encoded or multipart). The body parameters take
+// precedence over the query parameters with the same name.
+//
+// The parameter of a field is set with the "form" tag: `form:"name"`, or
+// `form:"name,required"` for a parameter that must be present. Untagged and
+// unexported fields are ignored. Fields can be strings, bools, integers,
+// floats, or slices of those, which receive all the values of the parameter.
+//
+//	var q struct {
+//		Name  string   `form:"name,required"`
+//		Age   int      `form:"age"`
+//		Langs []string `form:"lang"`
+//	}
+//	if err := safehttp.DecodeForm(r, &q); err != nil {
+//		return w.WriteError(safehttp.StatusBadRequest)
+//	}
+//
+// If some parameters are missing or invalid, the other fields are still
+// populated and the error is a FieldErrors listing all the failures. Other
+// errors are returned if the form can't be parsed or if dst isn't a pointer to
+// a struct with supported field types.
+func DecodeForm(r *IncomingRequest, dst interface{}) error {
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("DecodeForm: got %T, want a pointer to a struct", dst)
+	}
+	values, err := formValues(r)
+	if err != nil {
+		return err
+	}
+
+	var errs FieldErrors
+	v = v.Elem()
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag, ok := f.Tag.Lookup("form")
+		if !ok || tag == "-" || f.PkgPath != "" {
+			continue
+		}
+		if !supportedField(f.Type) {
+			return fmt.Errorf("DecodeForm: field %s has unsupported type %v", f.Name, f.Type)
+		}
+		name, opts := tag, ""
+		if i := strings.Index(tag, ","); i >= 0 {
+			name, opts = tag[:i], tag[i+1:]
+		}
+		vals, ok := values[name]
+		if !ok || len(vals) == 0 {
+			if opts == "required" {
+				errs = append(errs, &FieldError{Field: name, Err: ErrMissingParameter})
+			}
+			continue
+		}
+		if err := setField(v.Field(i), vals); err != nil {
+			errs = append(errs, &FieldError{Field: name, Err: err})
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// formValues returns the query parameters of the request, overridden by the
+// form parameters of its body, if any.
+func formValues(r *IncomingRequest) (map[string][]string, error) {
+	values := map[string][]string{}
+	for k, v := range r.req.URL.Query() {
+		values[k] = v
+	}
+	if m := r.OriginalMethod(); m != MethodPost && m != MethodPatch && m != MethodPut {
+		return values, nil
+	}
+	var body map[string][]string
+	ct := r.req.Header.Get("Content-Type")
+	switch {
+	case ct == "application/x-www-form-urlencoded":
+		f, err := r.PostForm()
+		if err != nil {
+			return nil, err
+		}
+		body = f.values
+	case strings.HasPrefix(ct, "multipart/form-data"):
+		f, err := r.MultipartForm(DefaultMultipartMaxMemory)
+		if err != nil {
+			return nil, err
+		}
+		body = f.values
+	}
+	for k, v := range body {
+		values[k] = v
+	}
+	return values, nil
+}
+
+func supportedField(t reflect.Type) bool {
+	if t.Kind() == reflect.Slice {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+func setField(f reflect.Value, vals []string) error {
+	if f.Kind() != reflect.Slice {
+		return setValue(f, vals[0])
+	}
+	s := reflect.MakeSlice(f.Type(), len(vals), len(vals))
+	for i, val := range vals {
+		if err := setValue(s.Index(i), val); err != nil {
+			return err
+		}
+	}
+	f.Set(s)
+	return nil
+}
+
+func setValue(f reflect.Value, val string) error {
+	switch f.Kind() {
+	case reflect.String:
+		f.SetString(val)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(val)
+		if err != nil {
+			return err
+		}
+		f.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(val, 10, f.Type().Bits())
+		if err != nil {
+			return err
+		}
+		f.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(val, 10, f.Type().Bits())
+		if err != nil {
+			return err
+		}
+		f.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		fl, err := strconv.ParseFloat(val, f.Type().Bits())
+		if err != nil {
+			return err
+		}
+		f.SetFloat(fl)
+	}
+	return nil
+}
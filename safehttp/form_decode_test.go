@@ -0,0 +1,126 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package safehttp_test
+
+import (
+	"bytes"
+	"errors"
+	"mime/multipart"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-safeweb/safehttp"
+	"github.com/google/go-safeweb/safehttp/safehttptest"
+)
+
+type profileForm struct {
+	Name    string   `form:"name,required"`
+	Age     int      `form:"age"`
+	Score   float64  `form:"score"`
+	Admin   bool     `form:"admin"`
+	Langs   []string `form:"lang"`
+	IDs     []uint8  `form:"id"`
+	Ignored string
+	Skipped string `form:"-"`
+}
+
+func TestDecodeForm(t *testing.T) {
+	tests := []struct {
+		name string
+		req  func() *safehttp.IncomingRequest
+		want profileForm
+	}{
+		{
+			name: "Query",
+			req: func() *safehttp.IncomingRequest {
+				return safehttptest.NewRequest(safehttp.MethodGet, "/?name=alice&age=42&score=1.5&admin=true&lang=go&lang=c&id=1&id=2&Ignored=x&Skipped=y", nil)
+			},
+			want: profileForm{Name: "alice", Age: 42, Score: 1.5, Admin: true, Langs: []string{"go", "c"}, IDs: []uint8{1, 2}},
+		},
+		{
+			name: "Post form overrides query",
+			req: func() *safehttp.IncomingRequest {
+				r := safehttptest.NewRequest(safehttp.MethodPost, "/?name=alice&age=42", strings.NewReader("name=bob"))
+				r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+				return r
+			},
+			want: profileForm{Name: "bob", Age: 42},
+		},
+		{
+			name: "Multipart form",
+			req: func() *safehttp.IncomingRequest {
+				var b bytes.Buffer
+				mw := multipart.NewWriter(&b)
+				mw.WriteField("name", "carol")
+				mw.WriteField("lang", "rust")
+				mw.Close()
+				r := safehttptest.NewRequest(safehttp.MethodPost, "/", &b)
+				r.Header.Set("Content-Type", mw.FormDataContentType())
+				return r
+			},
+			want: profileForm{Name: "carol", Langs: []string{"rust"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got profileForm
+			if err := safehttp.DecodeForm(tt.req(), &got); err != nil {
+				t.Fatalf("safehttp.DecodeForm() got err: %v", err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("safehttp.DecodeForm() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestDecodeFormFieldErrors(t *testing.T) {
+	r := safehttptest.NewRequest(safehttp.MethodGet, "/?age=old&admin=maybe&id=1&id=300&lang=go", nil)
+	var got profileForm
+	err := safehttp.DecodeForm(r, &got)
+
+	var errs safehttp.FieldErrors
+	if !errors.As(err, &errs) {
+		t.Fatalf("safehttp.DecodeForm() got err %v, want safehttp.FieldErrors", err)
+	}
+	var fields []string
+	for _, e := range errs {
+		fields = append(fields, e.Field)
+	}
+	if diff := cmp.Diff([]string{"name", "age", "admin", "id"}, fields); diff != "" {
+		t.Errorf("failed fields mismatch (-want +got):\n%s", diff)
+	}
+	if !errors.Is(errs[0], safehttp.ErrMissingParameter) {
+		t.Errorf("errs[0]: got %v, want %v", errs[0], safehttp.ErrMissingParameter)
+	}
+	// The valid fields are still decoded.
+	if diff := cmp.Diff([]string{"go"}, got.Langs); diff != "" {
+		t.Errorf("got.Langs mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestDecodeFormInvalidDestination(t *testing.T) {
+	r := safehttptest.NewRequest(safehttp.MethodGet, "/?name=alice", nil)
+	var unsupported struct {
+		Name map[string]string `form:"name"`
+	}
+	for _, dst := range []interface{}{nil, profileForm{}, new(string), &unsupported} {
+		if err := safehttp.DecodeForm(r, dst); err == nil {
+			t.Errorf("safehttp.DecodeForm(%T) got nil err, want error", dst)
+		}
+	}
+}
@@ -0,0 +1,208 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package safehttp
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Group is a set of handlers registered under a common path prefix which
+// share a set of interceptors and InterceptorConfigs, in addition to the ones
+// of the ServeMuxConfig. Groups are created with ServeMuxConfig.Group.
+//
+// The handlers of a group are registered in every ServeMux created by the
+// ServeMuxConfig with Mux, hence a group has to be fully configured before
+// Mux is called.
+type Group struct {
+	prefix       string
+	interceptors []Interceptor
+	cfgs         []InterceptorConfig
+	handlers     []groupHandler
+}
+
+type groupHandler struct {
+	pattern string
+	method  string
+	h       Handler
+	cfgs    []InterceptorConfig
+}
+
+// Group creates a group of handlers whose patterns start with the given
+// prefix. The prefix must begin with a slash, or with a host name like the
+// patterns passed to ServeMux.Handle; a trailing slash is ignored. For
+// example, after
+//
+//	api := cfg.Group("/api")
+//	api.Handle("/users", MethodGet, usersHandler)
+//
+// usersHandler serves requests for "/api/users".
+func (s *ServeMuxConfig) Group(prefix string) *Group {
+	if prefix == "" {
+		panic(fmt.Sprintf("invalid group prefix %q", prefix))
+	}
+	g := &Group{prefix: strings.TrimSuffix(prefix, "/")}
+	s.groups = append(s.groups, g)
+	return g
+}
+
+// Host creates a group of handlers that only serve requests for the given
+// host, e.g. to serve several domains with different interceptors from a
+// single ServeMux. The host must not contain a port.
+func (s *ServeMuxConfig) Host(host string) *Group {
+	checkHost(host)
+	return s.Group(host)
+}
+
+// Intercept installs the given interceptors for the handlers of the group.
+// They run after the interceptors installed in the ServeMuxConfig, in the
+// order they've been installed.
+func (g *Group) Intercept(is ...Interceptor) {
+	g.interceptors = append(g.interceptors, is...)
+}
+
+// Configure sets InterceptorConfigs that apply to all the handlers of the
+// group, e.g. to exempt an API from a protection. A configuration passed to
+// Handle takes precedence over a group configuration for the same
+// interceptor.
+func (g *Group) Configure(cfgs ...InterceptorConfig) {
+	g.cfgs = append(g.cfgs, cfgs...)
+}
+
+// Handle registers a handler for the given pattern, relative to the group
+// prefix, and method. The pattern must begin with a slash. If a handler is
+// registered twice for the same pattern and method, Mux will panic.
+func (g *Group) Handle(pattern string, method string, h Handler, cfgs ...InterceptorConfig) {
+	if !strings.HasPrefix(pattern, "/") {
+		panic(fmt.Sprintf("group pattern %q doesn't begin with a slash", pattern))
+	}
+	g.handlers = append(g.handlers, groupHandler{
+		pattern: pattern,
+		method:  method,
+		h:       h,
+		cfgs:    cfgs,
+	})
+}
+
+// register registers the handlers of the group in the given ServeMux, with
+// the given dispatcher and the interceptors of the group running after the
+// given ones. The mount prefix is inserted before the path of the patterns.
+func (g *Group) register(m *ServeMux, disp Dispatcher, mountPrefix string, interceptors []Interceptor) {
+	its := append(append([]Interceptor(nil), interceptors...), withoutInstalled(interceptors, g.interceptors)...)
+	for _, gh := range g.handlers {
+		cfg := newHandlerConfig(disp, gh.h, its, overrideConfigs(its, g.cfgs, gh.cfgs), m.traceInterceptors)
+		pattern := g.prefix + gh.pattern
+		i := strings.Index(pattern, "/")
+		m.handle(pattern[:i]+mountPrefix+pattern[i:], gh.method, cfg)
+	}
+}
+
+// mount is a ServeMuxConfig mounted under a prefix.
+type mount struct {
+	prefix string
+	sub    *ServeMuxConfig
+}
+
+// Mount registers the handlers of the groups of sub, and of the
+// configurations mounted in it, under the given prefix in every ServeMux
+// created by s. This allows libraries to provide pre-built sets of handlers,
+// e.g. an admin console, which applications mount under a path of their
+// choice. The prefix must begin with a slash; a trailing slash is ignored.
+//
+// The mounted handlers keep their own configuration: they are written with the
+// Dispatcher of sub and the interceptors installed in sub run for them, after
+// the interceptors installed in s. An interceptor installed in sub which is
+// equal to one installed in s, e.g. hsts.Default() installed in both, only runs
+// once, at the position it has in s. Interceptors of the same type but with a
+// different configuration run twice, so the ones that set headers which can
+// only be set once (e.g. hsts, csp and staticheaders) must be installed in
+// either s or sub, not both.
+//
+// The configuration of sub is read when Mux is called.
+func (s *ServeMuxConfig) Mount(prefix string, sub *ServeMuxConfig) {
+	if !strings.HasPrefix(prefix, "/") {
+		panic(fmt.Sprintf("mount prefix %q doesn't begin with a slash", prefix))
+	}
+	if sub == s {
+		panic("a ServeMuxConfig can't be mounted in itself")
+	}
+	s.mounts = append(s.mounts, mount{prefix: strings.TrimSuffix(prefix, "/"), sub: sub})
+}
+
+// registerRoutes registers the handlers of the groups of s, and of the
+// configurations mounted in it, in the given ServeMux. The interceptors of s
+// run after the given ones.
+func (s *ServeMuxConfig) registerRoutes(m *ServeMux, mountPrefix string, interceptors []Interceptor) {
+	its := append(append([]Interceptor(nil), interceptors...), withoutInstalled(interceptors, s.orderedInterceptors())...)
+	for _, g := range s.groups {
+		g.register(m, s.dispatcher, mountPrefix, its)
+	}
+	for _, mt := range s.mounts {
+		mt.sub.registerRoutes(m, mountPrefix+mt.prefix, its)
+	}
+}
+
+func (g *Group) clone() *Group {
+	return &Group{
+		prefix:       g.prefix,
+		interceptors: append([]Interceptor(nil), g.interceptors...),
+		cfgs:         append([]InterceptorConfig(nil), g.cfgs...),
+		handlers:     append([]groupHandler(nil), g.handlers...),
+	}
+}
+
+// overrideConfigs returns the configurations in base that don't configure the
+// same interceptor as any of the overrides, followed by the overrides.
+func overrideConfigs(interceptors []Interceptor, base, overrides []InterceptorConfig) []InterceptorConfig {
+	var cfgs []InterceptorConfig
+	for _, b := range base {
+		overridden := false
+		for _, it := range flattenInterceptors(interceptors) {
+			if !it.Match(b) {
+				continue
+			}
+			for _, o := range overrides {
+				if it.Match(o) {
+					overridden = true
+				}
+			}
+		}
+		if !overridden {
+			cfgs = append(cfgs, b)
+		}
+	}
+	return append(cfgs, overrides...)
+}
+
+// withoutInstalled returns the interceptors of its which aren't equal to any of
+// the installed ones, so that an interceptor installed both in a ServeMuxConfig
+// and in a group or configuration mounted in it doesn't run twice.
+func withoutInstalled(installed, its []Interceptor) []Interceptor {
+	var res []Interceptor
+	for _, it := range its {
+		dup := false
+		for _, in := range installed {
+			if interceptorType(in) == interceptorType(it) && reflect.DeepEqual(in, it) {
+				dup = true
+				break
+			}
+		}
+		if !dup {
+			res = append(res, it)
+		}
+	}
+	return res
+}
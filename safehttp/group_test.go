@@ -0,0 +1,310 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package safehttp_test
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-safeweb/safehttp"
+	"github.com/google/go-safeweb/safehttp/plugins/hsts"
+	"github.com/google/safehtml"
+)
+
+func TestGroup(t *testing.T) {
+	var log []string
+	mb := safehttp.NewServeMuxConfig(nil)
+	mb.Intercept(recordingInterceptor{name: "global", log: &log})
+
+	api := mb.Group("/api/")
+	api.Intercept(recordingInterceptor{name: "api", log: &log})
+	api.Configure(recordingConfig{name: "global", value: "group"})
+	api.Handle("/users", safehttp.MethodGet, safehttp.HandlerFunc(func(w safehttp.ResponseWriter, r *safehttp.IncomingRequest) safehttp.Result {
+		log = append(log, "users")
+		return w.Write(safehtml.HTMLEscaped("users"))
+	}))
+	api.Handle("/admin", safehttp.MethodGet, safehttp.HandlerFunc(func(w safehttp.ResponseWriter, r *safehttp.IncomingRequest) safehttp.Result {
+		log = append(log, "admin")
+		return w.Write(safehtml.HTMLEscaped("admin"))
+	}), recordingConfig{name: "global", value: "handler"}, recordingConfig{name: "api", value: "handler"})
+
+	mux := mb.Mux()
+	mux.Handle("/users", safehttp.MethodGet, safehttp.HandlerFunc(func(w safehttp.ResponseWriter, r *safehttp.IncomingRequest) safehttp.Result {
+		log = append(log, "root users")
+		return w.Write(safehtml.HTMLEscaped("root users"))
+	}))
+
+	tests := []struct {
+		path    string
+		wantLog []string
+	}{
+		{
+			path: "/api/users",
+			wantLog: []string{
+				"before global group", "before api",
+				"users",
+				"commit api", "commit global",
+			},
+		},
+		{
+			path: "/api/admin",
+			wantLog: []string{
+				"before global handler", "before api handler",
+				"admin",
+				"commit api", "commit global",
+			},
+		},
+		{
+			path: "/users",
+			wantLog: []string{
+				"before global",
+				"root users",
+				"commit global",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			log = nil
+			rr := httptest.NewRecorder()
+			mux.ServeHTTP(rr, httptest.NewRequest(safehttp.MethodGet, "http://foo.com"+tt.path, nil))
+
+			if got, want := rr.Code, int(safehttp.StatusOK); got != want {
+				t.Errorf("rr.Code got: %v want: %v", got, want)
+			}
+			if diff := cmp.Diff(tt.wantLog, log); diff != "" {
+				t.Errorf("log mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestGroupClone(t *testing.T) {
+	mb := safehttp.NewServeMuxConfig(nil)
+	g := mb.Group("/api")
+	h := safehttp.HandlerFunc(func(w safehttp.ResponseWriter, r *safehttp.IncomingRequest) safehttp.Result {
+		return w.Write(safehtml.HTMLEscaped("ok"))
+	})
+	g.Handle("/a", safehttp.MethodGet, h)
+	clone := mb.Clone()
+	g.Handle("/b", safehttp.MethodGet, h)
+
+	mux := clone.Mux()
+	tests := []struct {
+		path string
+		want int
+	}{
+		{path: "/api/a", want: int(safehttp.StatusOK)},
+		{path: "/api/b", want: int(safehttp.StatusNotFound)},
+	}
+	for _, tt := range tests {
+		rr := httptest.NewRecorder()
+		mux.ServeHTTP(rr, httptest.NewRequest(safehttp.MethodGet, "http://foo.com"+tt.path, nil))
+		if got := rr.Code; got != tt.want {
+			t.Errorf("%s: rr.Code got: %v want: %v", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestGroupPanics(t *testing.T) {
+	tests := []struct {
+		name string
+		f    func(mb *safehttp.ServeMuxConfig)
+	}{
+		{
+			name: "empty prefix",
+			f:    func(mb *safehttp.ServeMuxConfig) { mb.Group("") },
+		},
+		{
+			name: "relative pattern",
+			f: func(mb *safehttp.ServeMuxConfig) {
+				mb.Group("/api").Handle("users", safehttp.MethodGet, nil)
+			},
+		},
+		{
+			name: "double registration",
+			f: func(mb *safehttp.ServeMuxConfig) {
+				mb.Group("/api").Handle("/users", safehttp.MethodGet, nil)
+				mb.Group("/api").Handle("/users", safehttp.MethodGet, nil)
+				mb.Mux()
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Error("expected panic")
+				}
+			}()
+			tt.f(safehttp.NewServeMuxConfig(nil))
+		})
+	}
+}
+
+func TestMount(t *testing.T) {
+	var log []string
+	handler := func(name string) safehttp.Handler {
+		return safehttp.HandlerFunc(func(w safehttp.ResponseWriter, r *safehttp.IncomingRequest) safehttp.Result {
+			log = append(log, name)
+			return w.Write(safehtml.HTMLEscaped(name))
+		})
+	}
+
+	metrics := safehttp.NewServeMuxConfig(nil)
+	metrics.Intercept(recordingInterceptor{name: "metrics", log: &log})
+	metrics.Group("/").Handle("/vars", safehttp.MethodGet, handler("vars"))
+
+	admin := safehttp.NewServeMuxConfig(nil)
+	admin.Intercept(recordingInterceptor{name: "admin", log: &log})
+	admin.Group("/").Handle("/users", safehttp.MethodGet, handler("users"))
+	admin.Mount("/metrics", metrics)
+
+	mb := safehttp.NewServeMuxConfig(nil)
+	mb.Intercept(recordingInterceptor{name: "global", log: &log})
+	mb.Mount("/admin/", admin)
+	mux := mb.Mux()
+
+	tests := []struct {
+		path    string
+		wantLog []string
+	}{
+		{
+			path: "/admin/users",
+			wantLog: []string{
+				"before global", "before admin",
+				"users",
+				"commit admin", "commit global",
+			},
+		},
+		{
+			path: "/admin/metrics/vars",
+			wantLog: []string{
+				"before global", "before admin", "before metrics",
+				"vars",
+				"commit metrics", "commit admin", "commit global",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			log = nil
+			rr := httptest.NewRecorder()
+			mux.ServeHTTP(rr, httptest.NewRequest(safehttp.MethodGet, "http://foo.com"+tt.path, nil))
+
+			if got, want := rr.Code, int(safehttp.StatusOK); got != want {
+				t.Errorf("rr.Code got: %v want: %v", got, want)
+			}
+			if diff := cmp.Diff(tt.wantLog, log); diff != "" {
+				t.Errorf("log mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	for _, path := range []string{"/users", "/metrics/vars"} {
+		rr := httptest.NewRecorder()
+		mux.ServeHTTP(rr, httptest.NewRequest(safehttp.MethodGet, "http://foo.com"+path, nil))
+		if got, want := rr.Code, int(safehttp.StatusNotFound); got != want {
+			t.Errorf("%s: rr.Code got: %v want: %v", path, got, want)
+		}
+	}
+}
+
+func TestMountHostGroup(t *testing.T) {
+	sub := safehttp.NewServeMuxConfig(nil)
+	sub.Host("api.com").Handle("/users", safehttp.MethodGet, safehttp.HandlerFunc(func(w safehttp.ResponseWriter, r *safehttp.IncomingRequest) safehttp.Result {
+		return w.Write(safehtml.HTMLEscaped("users"))
+	}))
+	mb := safehttp.NewServeMuxConfig(nil)
+	mb.Mount("/v1", sub)
+	mux := mb.Mux()
+
+	tests := []struct {
+		target string
+		want   int
+	}{
+		{target: "http://api.com/v1/users", want: int(safehttp.StatusOK)},
+		{target: "http://other.com/v1/users", want: int(safehttp.StatusNotFound)},
+	}
+	for _, tt := range tests {
+		rr := httptest.NewRecorder()
+		mux.ServeHTTP(rr, httptest.NewRequest(safehttp.MethodGet, tt.target, nil))
+		if got := rr.Code; got != tt.want {
+			t.Errorf("%s: rr.Code got: %v want: %v", tt.target, got, tt.want)
+		}
+	}
+}
+
+func TestMountSharedInterceptor(t *testing.T) {
+	var log []string
+	shared := recordingInterceptor{name: "shared", log: &log}
+
+	sub := safehttp.NewServeMuxConfig(nil)
+	sub.Intercept(hsts.Default(), shared, recordingInterceptor{name: "sub", log: &log})
+	sub.Group("/").Handle("/users", safehttp.MethodGet, safehttp.HandlerFunc(func(w safehttp.ResponseWriter, r *safehttp.IncomingRequest) safehttp.Result {
+		log = append(log, "users")
+		return w.Write(safehtml.HTMLEscaped("users"))
+	}))
+
+	mb := safehttp.NewServeMuxConfig(nil)
+	mb.Intercept(hsts.Default(), shared)
+	mb.Mount("/admin", sub)
+	mux := mb.Mux()
+
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, httptest.NewRequest(safehttp.MethodGet, "https://foo.com/admin/users", nil))
+
+	if got, want := rr.Code, int(safehttp.StatusOK); got != want {
+		t.Errorf("rr.Code got: %v want: %v", got, want)
+	}
+	if got, want := rr.Header().Values("Strict-Transport-Security"), []string{"max-age=63072000; includeSubDomains"}; !cmp.Equal(want, got) {
+		t.Errorf("Strict-Transport-Security got: %q want: %q", got, want)
+	}
+	wantLog := []string{
+		"before shared", "before sub",
+		"users",
+		"commit sub", "commit shared",
+	}
+	if diff := cmp.Diff(wantLog, log); diff != "" {
+		t.Errorf("log mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestMountPanics(t *testing.T) {
+	tests := []struct {
+		name string
+		f    func(mb *safehttp.ServeMuxConfig)
+	}{
+		{
+			name: "relative prefix",
+			f:    func(mb *safehttp.ServeMuxConfig) { mb.Mount("admin", safehttp.NewServeMuxConfig(nil)) },
+		},
+		{
+			name: "self",
+			f:    func(mb *safehttp.ServeMuxConfig) { mb.Mount("/admin", mb) },
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Error("expected panic")
+				}
+			}()
+			tt.f(safehttp.NewServeMuxConfig(nil))
+		})
+	}
+}
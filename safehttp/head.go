@@ -0,0 +1,62 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package safehttp
+
+import (
+	"net/http"
+	"strconv"
+)
+
+// DisableAutoHead makes the ServeMux reject HEAD requests with 405 Method Not
+// Allowed, unless a handler was registered for HEAD.
+//
+// By default, HEAD requests for a pattern with no HEAD handler are served by
+// its GET handler. The response body is discarded, but the Content-Length
+// header is set as if it was sent.
+func (s *ServeMuxConfig) DisableAutoHead() {
+	s.disableAutoHead = true
+}
+
+// headResponseWriter discards the body of the response to a HEAD request
+// served by a GET handler, computing its Content-Length.
+type headResponseWriter struct {
+	http.ResponseWriter
+	code int
+	n    int
+}
+
+func (w *headResponseWriter) WriteHeader(code int) {
+	if w.code == 0 {
+		w.code = code
+	}
+}
+
+func (w *headResponseWriter) Write(b []byte) (int, error) {
+	w.WriteHeader(http.StatusOK)
+	w.n += len(b)
+	return len(b), nil
+}
+
+// finish writes the header of the response.
+func (w *headResponseWriter) finish() {
+	if w.code == 0 {
+		return
+	}
+	h := w.ResponseWriter.Header()
+	if h.Get("Content-Length") == "" && h.Get("Transfer-Encoding") == "" && w.code != http.StatusNoContent && w.code != http.StatusNotModified {
+		h.Set("Content-Length", strconv.Itoa(w.n))
+	}
+	w.ResponseWriter.WriteHeader(w.code)
+}
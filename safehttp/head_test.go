@@ -0,0 +1,91 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package safehttp_test
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-safeweb/safehttp"
+	"github.com/google/safehtml"
+)
+
+func TestMuxAutoHead(t *testing.T) {
+	tests := []struct {
+		name         string
+		disable      bool
+		registerHead bool
+		wantStatus   safehttp.StatusCode
+		wantLength   string
+		wantFoo      string
+	}{
+		{
+			name:       "GET handler",
+			wantStatus: safehttp.StatusOK,
+			wantLength: "10",
+			wantFoo:    "get",
+		},
+		{
+			name:         "HEAD handler",
+			registerHead: true,
+			wantStatus:   safehttp.StatusOK,
+			wantFoo:      "head",
+		},
+		{
+			name:       "disabled",
+			disable:    true,
+			wantStatus: safehttp.StatusMethodNotAllowed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := safehttp.NewServeMuxConfig(nil)
+			if tt.disable {
+				cfg.DisableAutoHead()
+			}
+			mux := cfg.Mux()
+			mux.Handle("/", safehttp.MethodGet, safehttp.HandlerFunc(func(w safehttp.ResponseWriter, r *safehttp.IncomingRequest) safehttp.Result {
+				w.Header().Set("Foo", "get")
+				return w.Write(safehtml.HTMLEscaped("<h1>"))
+			}))
+			if tt.registerHead {
+				mux.Handle("/", safehttp.MethodHead, safehttp.HandlerFunc(func(w safehttp.ResponseWriter, r *safehttp.IncomingRequest) safehttp.Result {
+					w.Header().Set("Foo", "head")
+					return w.Write(safehtml.HTML{})
+				}))
+			}
+
+			rw := httptest.NewRecorder()
+			mux.ServeHTTP(rw, httptest.NewRequest(safehttp.MethodHead, "http://foo.com/", nil))
+
+			if got, want := rw.Code, int(tt.wantStatus); got != want {
+				t.Errorf("rw.Code: got %v want %v", got, want)
+			}
+			if tt.wantStatus != safehttp.StatusOK {
+				return
+			}
+			if got := rw.Body.String(); got != "" {
+				t.Errorf("response body: got %q want empty", got)
+			}
+			if got, want := rw.Header().Get("Content-Length"), tt.wantLength; got != want {
+				t.Errorf(`rw.Header().Get("Content-Length"): got %q want %q`, got, want)
+			}
+			if got, want := rw.Header().Get("Foo"), tt.wantFoo; got != want {
+				t.Errorf(`rw.Header().Get("Foo"): got %q want %q`, got, want)
+			}
+		})
+	}
+}
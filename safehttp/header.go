@@ -27,6 +27,9 @@ import (
 type Header struct {
 	wrapped http.Header
 	claimed map[string]bool
+	// onRead, if set, is called with the name of every header read with Get
+	// or Values. It's used to trace interceptors.
+	onRead func(name string)
 }
 
 // NewHeader creates a new Header.
@@ -110,6 +113,9 @@ func (h Header) Del(name string) {
 // The name is first canonicalized using textproto.CanonicalMIMEHeaderKey.
 // If no header exists with the given name then "" is returned.
 func (h Header) Get(name string) string {
+	if h.onRead != nil {
+		h.onRead(name)
+	}
 	return h.wrapped.Get(name)
 }
 
@@ -121,12 +127,25 @@ func (h Header) Get(name string) string {
 // slice. If no header exists with the given name then an empty slice is
 // returned.
 func (h Header) Values(name string) []string {
+	if h.onRead != nil {
+		h.onRead(name)
+	}
 	v := h.wrapped.Values(name)
 	clone := make([]string, len(v))
 	copy(clone, v)
 	return clone
 }
 
+// reset deletes all the headers which weren't claimed, including the
+// Set-Cookie ones.
+func (h Header) reset() {
+	for name := range h.wrapped {
+		if !h.claimed[name] {
+			delete(h.wrapped, name)
+		}
+	}
+}
+
 // addCookie adds the cookie provided as a Set-Cookie header in the header
 // collection. If the cookie is nil or cookie.Name() is invalid, no header is
 // added and an error is returned. This is the only method that can modify the
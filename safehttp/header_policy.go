@@ -0,0 +1,167 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package safehttp
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+)
+
+// HeaderPolicy restricts the headers of the responses, see
+// HeaderPolicyDispatcher.
+type HeaderPolicy struct {
+	// Forbidden are the headers responses must not have, e.g. "Server" or
+	// headers that only a proxy in front of the application may set.
+	Forbidden []string
+	// Required are the headers every response must have, e.g.
+	// "X-Content-Type-Options" or "Content-Security-Policy".
+	Required []string
+	// MaxSize is the maximum size of the header block of the responses, in
+	// bytes, including the Set-Cookie headers. Zero means no limit.
+	MaxSize int
+}
+
+// Check returns an error if the headers violate the policy.
+func (p HeaderPolicy) Check(h http.Header) error {
+	for _, name := range p.Forbidden {
+		if _, ok := h[http.CanonicalHeaderKey(name)]; ok {
+			return fmt.Errorf("header policy: forbidden header %q is set", name)
+		}
+	}
+	for _, name := range p.Required {
+		if len(h.Values(name)) == 0 {
+			return fmt.Errorf("header policy: required header %q is missing", name)
+		}
+	}
+	if p.MaxSize > 0 {
+		if size := headerSize(h); size > p.MaxSize {
+			return fmt.Errorf("header policy: header block is %d bytes, limit is %d", size, p.MaxSize)
+		}
+	}
+	return nil
+}
+
+// headerSize returns the size of the headers on the wire in HTTP/1.1.
+func headerSize(h http.Header) int {
+	n := 0
+	for k, vs := range h {
+		for _, v := range vs {
+			n += len(k) + len(": ") + len(v) + len("\r\n")
+		}
+	}
+	return n
+}
+
+// HeaderPolicyDispatcher is a Dispatcher that checks the final headers of
+// every response against the Policy just before they are sent, i.e. after
+// the Commit phases of the interceptors and after the wrapped Dispatcher set
+// its own headers (e.g. the Content-Type). It ensures that a misbehaving
+// handler or interceptor can't, for example, remove or take over a header
+// the application relies on or emit an oversized header block.
+//
+// A response that violates the Policy isn't sent: the Dispatcher returns an
+// error, which makes the ResponseWriter panic, and the headers are cleared.
+type HeaderPolicyDispatcher struct {
+	// Dispatcher writes the responses. If nil, the DefaultDispatcher is
+	// used.
+	Dispatcher Dispatcher
+	Policy     HeaderPolicy
+}
+
+func (d HeaderPolicyDispatcher) dispatcher() Dispatcher {
+	if d.Dispatcher == nil {
+		return DefaultDispatcher{}
+	}
+	return d.Dispatcher
+}
+
+// Write writes the response with the wrapped Dispatcher, if its headers
+// comply with the Policy.
+func (d HeaderPolicyDispatcher) Write(rw http.ResponseWriter, resp Response) error {
+	pw := &headerPolicyWriter{ResponseWriter: rw, policy: d.Policy}
+	return pw.finish(d.dispatcher().Write(pw, resp))
+}
+
+// Error writes the error response with the wrapped Dispatcher, if its headers
+// comply with the Policy.
+func (d HeaderPolicyDispatcher) Error(rw http.ResponseWriter, resp ErrorResponse) error {
+	pw := &headerPolicyWriter{ResponseWriter: rw, policy: d.Policy}
+	return pw.finish(d.dispatcher().Error(pw, resp))
+}
+
+// headerPolicyWriter checks the headers against the policy before they are
+// written.
+type headerPolicyWriter struct {
+	http.ResponseWriter
+	policy HeaderPolicy
+
+	checked bool
+	err     error
+}
+
+func (w *headerPolicyWriter) check() error {
+	if !w.checked {
+		w.checked = true
+		w.err = w.policy.Check(w.ResponseWriter.Header())
+	}
+	return w.err
+}
+
+func (w *headerPolicyWriter) WriteHeader(code int) {
+	if w.check() != nil {
+		return
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *headerPolicyWriter) Write(b []byte) (int, error) {
+	if err := w.check(); err != nil {
+		return 0, err
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *headerPolicyWriter) Flush() {
+	if w.check() != nil {
+		return
+	}
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (w *headerPolicyWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if err := w.check(); err != nil {
+		return nil, nil, err
+	}
+	hj, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("the http.ResponseWriter doesn't support hijacking")
+	}
+	return hj.Hijack()
+}
+
+// finish checks the headers, if nothing was written yet, e.g. because the
+// body is written after the Dispatcher returns, and returns the error of the
+// Dispatcher or the policy violation.
+func (w *headerPolicyWriter) finish(err error) error {
+	if perr := w.check(); perr != nil {
+		return perr
+	}
+	return err
+}
@@ -0,0 +1,121 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package safehttp_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-safeweb/safehttp"
+)
+
+func TestHeaderPolicyCheck(t *testing.T) {
+	policy := safehttp.HeaderPolicy{
+		Forbidden: []string{"server"},
+		Required:  []string{"X-Content-Type-Options"},
+		MaxSize:   64,
+	}
+	tests := []struct {
+		name    string
+		h       http.Header
+		wantErr bool
+	}{
+		{name: "Valid", h: http.Header{"X-Content-Type-Options": {"nosniff"}}},
+		{name: "Forbidden", h: http.Header{"X-Content-Type-Options": {"nosniff"}, "Server": {"x"}}, wantErr: true},
+		{name: "Missing", h: http.Header{}, wantErr: true},
+		{name: "Too big", h: http.Header{"X-Content-Type-Options": {"nosniff"}, "Foo": {strings.Repeat("a", 32)}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := policy.Check(tt.h); (err != nil) != tt.wantErr {
+				t.Errorf("policy.Check(%v): got err %v, want err: %v", tt.h, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestHeaderPolicyDispatcher(t *testing.T) {
+	tests := []struct {
+		name      string
+		handler   safehttp.HandlerFunc
+		wantPanic bool
+	}{
+		{
+			name: "Valid",
+			handler: func(w safehttp.ResponseWriter, r *safehttp.IncomingRequest) safehttp.Result {
+				return safehttp.WriteJSON(w, "ok")
+			},
+		},
+		{
+			name: "Valid error",
+			handler: func(w safehttp.ResponseWriter, r *safehttp.IncomingRequest) safehttp.Result {
+				return w.WriteError(safehttp.StatusNotFound)
+			},
+		},
+		{
+			name: "Forbidden header",
+			handler: func(w safehttp.ResponseWriter, r *safehttp.IncomingRequest) safehttp.Result {
+				w.Header().Set("X-Powered-By", "PHP")
+				return safehttp.WriteJSON(w, "ok")
+			},
+			wantPanic: true,
+		},
+		{
+			name: "Claimed forbidden header",
+			handler: func(w safehttp.ResponseWriter, r *safehttp.IncomingRequest) safehttp.Result {
+				w.Header().Claim("X-Powered-By")([]string{"PHP"})
+				return w.WriteError(safehttp.StatusNotFound)
+			},
+			wantPanic: true,
+		},
+		{
+			name: "Oversized header block",
+			handler: func(w safehttp.ResponseWriter, r *safehttp.IncomingRequest) safehttp.Result {
+				w.Header().Set("X-Debug", strings.Repeat("a", 1024))
+				return safehttp.WriteJSON(w, "ok")
+			},
+			wantPanic: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := safehttp.HeaderPolicyDispatcher{Policy: safehttp.HeaderPolicy{
+				Forbidden: []string{"X-Powered-By"},
+				Required:  []string{"Content-Type"},
+				MaxSize:   512,
+			}}
+			mux := safehttp.NewServeMuxConfig(d).Mux()
+			mux.Handle("/", safehttp.MethodGet, tt.handler)
+			rr := httptest.NewRecorder()
+
+			defer func() {
+				r := recover()
+				if gotPanic := r != nil; gotPanic != tt.wantPanic {
+					t.Errorf("panic: got %v, want panic: %v", r, tt.wantPanic)
+				}
+				if !tt.wantPanic {
+					return
+				}
+				if len(rr.Header()) != 0 || rr.Body.Len() != 0 {
+					t.Errorf("got headers %v and body %q, want nothing", rr.Header(), rr.Body.String())
+				}
+			}()
+			mux.ServeHTTP(rr, httptest.NewRequest(safehttp.MethodGet, "http://foo.com/", nil))
+		})
+	}
+}
@@ -0,0 +1,79 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package safehttp
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+)
+
+// HTTP2Config configures HTTP/2. The zero values use the defaults of the
+// golang.org/x/net/http2 package.
+type HTTP2Config struct {
+	// MaxConcurrentStreams is the number of concurrent streams each client
+	// may have open at a time.
+	MaxConcurrentStreams uint32
+
+	// MaxReadFrameSize is the largest frame the server is willing to read.
+	// Valid values are between 16KiB and 16MiB.
+	MaxReadFrameSize uint32
+
+	// IdleTimeout is how long idle connections are kept open. If zero, the
+	// IdleTimeout of the Server is used.
+	IdleTimeout time.Duration
+
+	// H2C enables HTTP/2 over cleartext TCP connections, for servers behind a
+	// load balancer which terminates TLS and speaks HTTP/2 to its backends.
+	// It must not be enabled for servers reachable from the Internet.
+	//
+	// Only clients with prior knowledge are supported: requests asking to be
+	// upgraded with "Upgrade: h2c" are served over HTTP/1.1, since the
+	// upgrade can be abused to smuggle requests past proxies.
+	H2C bool
+}
+
+// configureHTTP2 configures HTTP/2 on srv, whose Handler and TLSConfig must
+// already be set.
+func configureHTTP2(srv *http.Server, cfg *HTTP2Config) error {
+	h2s := &http2.Server{
+		MaxConcurrentStreams: cfg.MaxConcurrentStreams,
+		MaxReadFrameSize:     cfg.MaxReadFrameSize,
+		IdleTimeout:          cfg.IdleTimeout,
+	}
+	if err := http2.ConfigureServer(srv, h2s); err != nil {
+		return err
+	}
+	if cfg.H2C {
+		srv.Handler = dropH2CUpgrade(h2c.NewHandler(srv.Handler, h2s))
+	}
+	return nil
+}
+
+// dropH2CUpgrade removes the h2c upgrade requests from the Connection and
+// Upgrade headers, so that only the connections with prior knowledge are
+// served over HTTP/2.
+func dropH2CUpgrade(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.ProtoMajor == 1 && strings.Contains(strings.ToLower(r.Header.Get("Upgrade")), "h2c") {
+			r.Header.Del("Upgrade")
+			r.Header.Del("Http2-Settings")
+		}
+		h.ServeHTTP(w, r)
+	})
+}
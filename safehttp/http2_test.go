@@ -0,0 +1,93 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package safehttp
+
+import (
+	"crypto/tls"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"testing"
+
+	"github.com/google/safehtml"
+	"golang.org/x/net/http2"
+)
+
+func startH2CServer(t *testing.T) string {
+	t.Helper()
+	mux := NewServeMuxConfig(nil).Mux()
+	mux.Handle("/", "GET", HandlerFunc(func(w ResponseWriter, r *IncomingRequest) Result {
+		return w.Write(safehtml.HTMLEscaped(r.req.Proto))
+	}))
+	s := &Server{
+		Mux:   mux,
+		HTTP2: &HTTP2Config{MaxConcurrentStreams: 10, H2C: true},
+	}
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("net.Listen: %v", err)
+	}
+	go s.Serve(l)
+	t.Cleanup(func() { s.Close() })
+	return "http://" + l.Addr().String() + "/"
+}
+
+func readBody(t *testing.T, resp *http.Response) string {
+	t.Helper()
+	defer resp.Body.Close()
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("reading the body: %v", err)
+	}
+	return string(b)
+}
+
+func TestH2CPriorKnowledge(t *testing.T) {
+	url := startH2CServer(t)
+	client := &http.Client{Transport: &http2.Transport{
+		AllowHTTP: true,
+		DialTLS: func(network, addr string, _ *tls.Config) (net.Conn, error) {
+			return net.Dial(network, addr)
+		},
+	}}
+	resp, err := client.Get(url)
+	if err != nil {
+		t.Fatalf("client.Get: %v", err)
+	}
+	if got, want := readBody(t, resp), "HTTP/2.0"; got != want {
+		t.Errorf("body: got %q want %q", got, want)
+	}
+}
+
+func TestH2CUpgradeIgnored(t *testing.T) {
+	url := startH2CServer(t)
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		t.Fatalf("http.NewRequest: %v", err)
+	}
+	req.Header.Set("Connection", "Upgrade, HTTP2-Settings")
+	req.Header.Set("Upgrade", "h2c")
+	req.Header.Set("HTTP2-Settings", "AAMAAABkAARAAAAAAAIAAAAA")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("http.DefaultClient.Do: %v", err)
+	}
+	if got, want := resp.StatusCode, http.StatusOK; got != want {
+		t.Errorf("resp.StatusCode: got %v want %v", got, want)
+	}
+	if got, want := readBody(t, resp), "HTTP/1.1"; got != want {
+		t.Errorf("body: got %q want %q", got, want)
+	}
+}
@@ -0,0 +1,78 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package safehttp
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"sync/atomic"
+)
+
+// HTTP3Server is an experimental HTTP/3 server, run alongside a Server
+// serving TLS. It's implemented by the *http3.Server of the
+// github.com/quic-go/quic-go/http3 package, which isn't a dependency of this
+// module:
+//
+//	s := &safehttp.Server{Addr: ":443", Mux: mux, TLSConfig: cfg}
+//	s.HTTP3 = &http3.Server{Addr: ":443", Handler: mux, TLSConfig: cfg}
+//
+// Its Handler must be the Mux of the Server, so that the requests received
+// over HTTP/3 go through the same interceptors and Dispatcher.
+type HTTP3Server interface {
+	// ListenAndServe listens on the UDP address and serves requests until
+	// Close is called.
+	ListenAndServe() error
+	// Close closes the server.
+	Close() error
+}
+
+// http3State tracks whether the HTTP3Server of a Server is running.
+type http3State struct {
+	serving int32
+	altSvc  string
+}
+
+func newHTTP3State(addr string) (*http3State, error) {
+	port := "443"
+	if addr != "" {
+		_, p, err := net.SplitHostPort(addr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid address %q: %v", addr, err)
+		}
+		port = p
+	}
+	return &http3State{altSvc: fmt.Sprintf(`h3=":%s"; ma=86400`, port)}, nil
+}
+
+// start starts h in the background. Alt-Svc is advertised until it stops.
+func (st *http3State) start(h HTTP3Server) {
+	atomic.StoreInt32(&st.serving, 1)
+	go func() {
+		h.ListenAndServe()
+		atomic.StoreInt32(&st.serving, 0)
+	}()
+}
+
+// advertise wraps the handler of the server to send the Alt-Svc header on
+// the responses over TLS while the HTTP/3 server is running.
+func (st *http3State) advertise(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.TLS != nil && r.ProtoMajor < 3 && atomic.LoadInt32(&st.serving) == 1 {
+			w.Header().Set("Alt-Svc", st.altSvc)
+		}
+		h.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,89 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package safehttp
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/safehtml"
+)
+
+// fakeHTTP3Server runs until closed.
+type fakeHTTP3Server struct {
+	closed chan struct{}
+}
+
+func (s *fakeHTTP3Server) ListenAndServe() error {
+	<-s.closed
+	return http.ErrServerClosed
+}
+
+func (s *fakeHTTP3Server) Close() error {
+	close(s.closed)
+	return nil
+}
+
+func TestHTTP3AltSvc(t *testing.T) {
+	mux := NewServeMuxConfig(nil).Mux()
+	mux.Handle("/", "GET", HandlerFunc(func(w ResponseWriter, r *IncomingRequest) Result {
+		return w.Write(safehtml.HTMLEscaped("response"))
+	}))
+	h3 := &fakeHTTP3Server{closed: make(chan struct{})}
+	s := &Server{Addr: ":8443", Mux: mux, HTTP3: h3}
+	if err := s.buildStd(); err != nil {
+		t.Fatalf("buildStd: %v", err)
+	}
+	s.started = true
+	s.http3.start(h3)
+
+	altSvc := func(tlsConn bool) string {
+		req := httptest.NewRequest("GET", "https://foo.com/", nil)
+		if !tlsConn {
+			req.TLS = nil
+		} else if req.TLS == nil {
+			req.TLS = &tls.ConnectionState{}
+		}
+		rr := httptest.NewRecorder()
+		s.srv.Handler.ServeHTTP(rr, req)
+		return rr.Header().Get("Alt-Svc")
+	}
+
+	if got, want := altSvc(true), `h3=":8443"; ma=86400`; got != want {
+		t.Errorf("Alt-Svc over TLS: got %q want %q", got, want)
+	}
+	if got := altSvc(false); got != "" {
+		t.Errorf("Alt-Svc over cleartext: got %q want none", got)
+	}
+
+	s.Close()
+	deadline := time.Now().Add(5 * time.Second)
+	for altSvc(true) != "" {
+		if time.Now().After(deadline) {
+			t.Fatal("Alt-Svc still advertised after Close")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestHTTP3InvalidAddr(t *testing.T) {
+	s := &Server{Addr: "no-port", Mux: NewServeMuxConfig(nil).Mux(), HTTP3: &fakeHTTP3Server{}}
+	if err := s.buildStd(); err == nil {
+		t.Error("buildStd: got nil, want error")
+	}
+}
@@ -0,0 +1,64 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package safehttp
+
+import (
+	"net"
+	"strings"
+)
+
+// RedirectToHTTPSServer returns a Server listening on addr, ":http" if
+// empty, which redirects the GET and HEAD requests to the same URL over
+// HTTPS, with 301 Moved Permanently. Other methods are answered with 405
+// Method Not Allowed, since their bodies were already sent in cleartext.
+//
+// If hosts are given, the requests for other hosts are answered with 404 Not
+// Found instead of being redirected. The host of the redirects is the one of
+// the request, as required by the HSTS preload list: the Strict-Transport-
+// Security header isn't sent, since browsers ignore it over HTTP, and must be
+// set by the HTTPS server, e.g. with the hsts plugin.
+//
+// More handlers can be registered in the Mux of the Server, e.g. the ACME
+// HTTP-01 challenge handler of the autocert plugin:
+//
+//	redirect := safehttp.RedirectToHTTPSServer(":80", m.Hosts()...)
+//	m.RegisterChallengeHandler(redirect.Mux)
+//	go redirect.ListenAndServe()
+func RedirectToHTTPSServer(addr string, hosts ...string) *Server {
+	if addr == "" {
+		addr = ":http"
+	}
+	allowed := map[string]bool{}
+	for _, h := range hosts {
+		allowed[strings.ToLower(h)] = true
+	}
+	h := HandlerFunc(func(w ResponseWriter, r *IncomingRequest) Result {
+		host := r.Host()
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		if host == "" || len(allowed) > 0 && !allowed[strings.ToLower(host)] {
+			return w.WriteError(StatusNotFound)
+		}
+		if strings.Contains(host, ":") {
+			host = "[" + host + "]"
+		}
+		target := "https://" + host + r.req.URL.RequestURI()
+		return Redirect(w, r, target, StatusMovedPermanently)
+	})
+	mux := NewServeMuxConfig(nil).Mux()
+	mux.Handle("/", MethodGet, h)
+	return &Server{Addr: addr, Mux: mux}
+}
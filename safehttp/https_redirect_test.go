@@ -0,0 +1,113 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package safehttp_test
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-safeweb/safehttp"
+)
+
+func TestRedirectToHTTPSServer(t *testing.T) {
+	tests := []struct {
+		name         string
+		hosts        []string
+		method       string
+		url          string
+		wantCode     safehttp.StatusCode
+		wantLocation string
+	}{
+		{
+			name:         "Redirect",
+			method:       safehttp.MethodGet,
+			url:          "http://foo.com/path?q=1",
+			wantCode:     safehttp.StatusMovedPermanently,
+			wantLocation: "https://foo.com/path?q=1",
+		},
+		{
+			name:         "Port removed",
+			method:       safehttp.MethodGet,
+			url:          "http://foo.com:8080/",
+			wantCode:     safehttp.StatusMovedPermanently,
+			wantLocation: "https://foo.com/",
+		},
+		{
+			name:         "IPv6",
+			method:       safehttp.MethodGet,
+			url:          "http://[::1]:80/",
+			wantCode:     safehttp.StatusMovedPermanently,
+			wantLocation: "https://[::1]/",
+		},
+		{
+			name:         "HEAD",
+			method:       safehttp.MethodHead,
+			url:          "http://foo.com/",
+			wantCode:     safehttp.StatusMovedPermanently,
+			wantLocation: "https://foo.com/",
+		},
+		{
+			name:     "POST",
+			method:   safehttp.MethodPost,
+			url:      "http://foo.com/",
+			wantCode: safehttp.StatusMethodNotAllowed,
+		},
+		{
+			name:         "Allowed host",
+			hosts:        []string{"foo.com"},
+			method:       safehttp.MethodGet,
+			url:          "http://FOO.com/",
+			wantCode:     safehttp.StatusMovedPermanently,
+			wantLocation: "https://FOO.com/",
+		},
+		{
+			name:     "Other host",
+			hosts:    []string{"foo.com"},
+			method:   safehttp.MethodGet,
+			url:      "http://evil.com/",
+			wantCode: safehttp.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := safehttp.RedirectToHTTPSServer("", tt.hosts...)
+			if got, want := s.Addr, ":http"; got != want {
+				t.Errorf("s.Addr: got %q want %q", got, want)
+			}
+			rr := httptest.NewRecorder()
+			s.Mux.ServeHTTP(rr, httptest.NewRequest(tt.method, tt.url, nil))
+
+			if got, want := rr.Code, int(tt.wantCode); got != want {
+				t.Errorf("rr.Code: got %v want %v", got, want)
+			}
+			if got := rr.Header().Get("Location"); got != tt.wantLocation {
+				t.Errorf(`rr.Header().Get("Location"): got %q want %q`, got, tt.wantLocation)
+			}
+		})
+	}
+}
+
+func TestRedirectToHTTPSServerMoreHandlers(t *testing.T) {
+	s := safehttp.RedirectToHTTPSServer(":8080")
+	s.Mux.Handle("/.well-known/acme-challenge/...", safehttp.MethodGet, safehttp.HandlerFunc(func(w safehttp.ResponseWriter, r *safehttp.IncomingRequest) safehttp.Result {
+		return w.Write(safehttp.NoContentResponse{})
+	}))
+	rr := httptest.NewRecorder()
+	s.Mux.ServeHTTP(rr, httptest.NewRequest(safehttp.MethodGet, "http://foo.com/.well-known/acme-challenge/token", nil))
+	if got, want := rr.Code, int(safehttp.StatusNoContent); got != want {
+		t.Errorf("rr.Code: got %v want %v", got, want)
+	}
+}
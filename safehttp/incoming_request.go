@@ -36,10 +36,20 @@ type IncomingRequest struct {
 	TLS *tls.ConnectionState
 	req *http.Request
 
+	values *Values
+	// wildcard is the part of the path matched by the wildcard of the
+	// pattern, if any.
+	wildcard string
+	// pathParams are the values of the path parameters of the pattern.
+	pathParams map[string]string
+	// originalMethod is the method sent by the client, if it was overridden.
+	originalMethod string
+
 	// The fields below are kept as pointers to allow cloning through
 	// IncomingRequest.WithContext. Otherwise, we'd need to copy locks.
 	postParseOnce      *sync.Once
 	multipartParseOnce *sync.Once
+	bodyStatus         *bodyStatus
 }
 
 // NewIncomingRequest creates an IncomingRequest
@@ -48,14 +58,18 @@ func NewIncomingRequest(req *http.Request) *IncomingRequest {
 	if req == nil {
 		return nil
 	}
-	req = req.WithContext(context.WithValue(req.Context(),
-		flightValuesCtxKey{}, flightValues{m: make(map[interface{}]interface{})}))
+	values := &Values{}
+	ctx := context.WithValue(req.Context(), flightValuesCtxKey{}, flightValues{m: make(map[interface{}]interface{})})
+	ctx = context.WithValue(ctx, valuesCtxKey{}, values)
+	req = req.WithContext(ctx)
 	return &IncomingRequest{
 		req:                req,
 		Header:             NewHeader(req.Header),
 		TLS:                req.TLS,
+		values:             values,
 		postParseOnce:      &sync.Once{},
 		multipartParseOnce: &sync.Once{},
+		bodyStatus:         &bodyStatus{},
 	}
 }
 
@@ -65,6 +79,17 @@ func (r *IncomingRequest) Body() io.ReadCloser {
 	return r.req.Body
 }
 
+// SetBody replaces the request body. It is meant to be used by interceptors
+// that transform the body (e.g. decompress it) before it reaches the handler.
+// Since the length of the new body is not known, the Content-Length of the
+// request is reset.
+//
+// SetBody must be called before the body is read.
+func (r *IncomingRequest) SetBody(body io.ReadCloser) {
+	r.req.Body = body
+	r.req.ContentLength = -1
+}
+
 // Host returns the host the request is targeted to. This value comes from the
 // Host header.
 func (r *IncomingRequest) Host() string {
@@ -76,6 +101,16 @@ func (r *IncomingRequest) Method() string {
 	return r.req.Method
 }
 
+// OriginalMethod returns the method of the request as sent by the client. It
+// differs from Method if the method was overridden, see
+// ServeMuxConfig.AllowMethodOverride.
+func (r *IncomingRequest) OriginalMethod() string {
+	if r.originalMethod != "" {
+		return r.originalMethod
+	}
+	return r.req.Method
+}
+
 // PostForm parses the form parameters provided in the body of a POST, PATCH or
 // PUT request that does not have Content-Type: multipart/form-data. It returns
 // the parsed form parameters as a Form object. If a parsing
@@ -85,7 +120,8 @@ func (r *IncomingRequest) Method() string {
 func (r *IncomingRequest) PostForm() (*Form, error) {
 	var err error
 	r.postParseOnce.Do(func() {
-		if m := r.req.Method; m != MethodPost && m != MethodPatch && m != MethodPut {
+		m := r.OriginalMethod()
+		if m != MethodPost && m != MethodPatch && m != MethodPut {
 			err = fmt.Errorf("got request method %s, want POST/PATCH/PUT", m)
 			return
 		}
@@ -95,7 +131,16 @@ func (r *IncomingRequest) PostForm() (*Form, error) {
 			return
 		}
 
-		err = r.req.ParseForm()
+		req := r.req
+		if req.Method != m {
+			// The method was overridden, see ServeMuxConfig.AllowMethodOverride.
+			// net/http only parses the body of POST, PATCH and PUT requests.
+			cp := *req
+			cp.Method = m
+			req = &cp
+		}
+		err = req.ParseForm()
+		r.req.Form, r.req.PostForm = req.Form, req.PostForm
 	})
 	if err != nil {
 		return nil, err
@@ -115,7 +160,8 @@ func (r *IncomingRequest) PostForm() (*Form, error) {
 func (r *IncomingRequest) MultipartForm(maxMemory int64) (*MultipartForm, error) {
 	var err error
 	r.multipartParseOnce.Do(func() {
-		if m := r.req.Method; m != MethodPost && m != MethodPatch && m != MethodPut {
+		m := r.OriginalMethod()
+		if m != MethodPost && m != MethodPatch && m != MethodPut {
 			err = fmt.Errorf("got request method %s, want POST/PATCH/PUT", m)
 			return
 		}
@@ -135,11 +181,22 @@ func (r *IncomingRequest) MultipartForm(maxMemory int64) (*MultipartForm, error)
 // Cookie returns the named cookie provided in the request or
 // net/http.ErrNoCookie if not found. If multiple cookies match the given name,
 // only one cookie will be returned.
+// Use CookieWithPolicy to verify the cookie.
 func (r *IncomingRequest) Cookie(name string) (*Cookie, error) {
+	return r.CookieWithPolicy(name, CookiePolicy{})
+}
+
+// CookieWithPolicy returns the named cookie provided in the request, if it's
+// accepted by the policy. It returns net/http.ErrNoCookie if the cookie is not
+// found, and ErrInsecureCookie or ErrCrossSiteCookie if it's rejected.
+func (r *IncomingRequest) CookieWithPolicy(name string, p CookiePolicy) (*Cookie, error) {
 	c, err := r.req.Cookie(name)
 	if err != nil {
 		return nil, err
 	}
+	if err := p.check(r, c.Name); err != nil {
+		return nil, err
+	}
 	return &Cookie{wrapped: c}, nil
 }
 
@@ -153,6 +210,26 @@ func (r *IncomingRequest) Cookies() []*Cookie {
 	return res
 }
 
+// Values returns the typed key/value store associated with the request. It is
+// shared by all the shallow copies of the request (e.g. the ones created by
+// WithContext) and can be used by interceptors to pass values to handlers.
+func (r *IncomingRequest) Values() *Values {
+	return r.values
+}
+
+// SetValue stores v under the given key in the Values of the request. It's a
+// shorthand for r.Values().Set(key, v).
+func (r *IncomingRequest) SetValue(key Key, v interface{}) {
+	r.values.Set(key, v)
+}
+
+// Value returns the value stored under the given key in the Values of the
+// request, or nil if there's none.
+func (r *IncomingRequest) Value(key Key) interface{} {
+	v, _ := r.values.Get(key)
+	return v
+}
+
 // Context returns the context of a safehttp.IncomingRequest. This is always
 // non-nil and will default to the background context. The context of a
 // safehttp.IncomingRequest is the context of the underlying http.Request.
@@ -175,6 +252,23 @@ func (r *IncomingRequest) WithContext(ctx context.Context) *IncomingRequest {
 	return r2
 }
 
+// PathWildcard returns the part of the (unescaped) request path matched by the
+// trailing "..." of the pattern the handler was registered with. For example,
+// for a handler registered with "/static/..." and a request for
+// "/static/css/style.css", it returns "css/style.css". It returns an empty
+// string if the pattern didn't end with a wildcard.
+func (r *IncomingRequest) PathWildcard() string {
+	return r.wildcard
+}
+
+// PathParam returns the value of the named path parameter of the pattern the
+// handler was registered with, e.g. "42" for the "id" parameter of the
+// "/users/{id}" pattern and a request for "/users/42". The value is
+// unescaped. It returns an empty string if the pattern has no such parameter.
+func (r *IncomingRequest) PathParam(name string) string {
+	return r.pathParams[name]
+}
+
 // URL specifies the URL that is parsed from the Request-Line. For most requests,
 // only URL.Path() will return a non-empty result. (See RFC 7230, Section 5.3)
 func (r *IncomingRequest) URL() *URL {
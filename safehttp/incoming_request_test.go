@@ -16,6 +16,7 @@ package safehttp_test
 
 import (
 	"context"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -83,6 +84,58 @@ func TestIncomingRequestCookieNotFound(t *testing.T) {
 	}
 }
 
+func TestIncomingRequestCookieWithPolicy(t *testing.T) {
+	var tests = []struct {
+		name    string
+		url     string
+		cookie  string
+		site    string
+		policy  safehttp.CookiePolicy
+		wantErr error
+	}{
+		{name: "Host prefix over HTTPS", url: "https://foo.com/", cookie: "__Host-id", policy: safehttp.CookiePolicy{RequireSecurePrefixes: true}},
+		{name: "Secure prefix over HTTPS", url: "https://foo.com/", cookie: "__Secure-id", policy: safehttp.CookiePolicy{RequireSecurePrefixes: true}},
+		{name: "Host prefix over HTTP", url: "http://foo.com/", cookie: "__Host-id", policy: safehttp.CookiePolicy{RequireSecurePrefixes: true}, wantErr: safehttp.ErrInsecureCookie},
+		{name: "Secure prefix over HTTP", url: "http://foo.com/", cookie: "__Secure-id", policy: safehttp.CookiePolicy{RequireSecurePrefixes: true}, wantErr: safehttp.ErrInsecureCookie},
+		{name: "Prefix over HTTP by default", url: "http://foo.com/", cookie: "__Host-id"},
+		{name: "No prefix over HTTP", url: "http://foo.com/", cookie: "id", policy: safehttp.CookiePolicy{RequireSecurePrefixes: true}},
+		{name: "Cross-site allowed", url: "https://foo.com/", cookie: "id", site: "cross-site"},
+		{name: "Cross-site rejected", url: "https://foo.com/", cookie: "id", site: "cross-site", policy: safehttp.CookiePolicy{RejectCrossSite: true}, wantErr: safehttp.ErrCrossSiteCookie},
+		{name: "Same-site with cross-site rejection", url: "https://foo.com/", cookie: "id", site: "same-site", policy: safehttp.CookiePolicy{RejectCrossSite: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := safehttptest.NewRequest(safehttp.MethodGet, tt.url, nil)
+			r.Header.Set("Cookie", tt.cookie+"=x")
+			if tt.site != "" {
+				r.Header.Set("Sec-Fetch-Site", tt.site)
+			}
+			_, err := r.CookieWithPolicy(tt.cookie, tt.policy)
+			if err != tt.wantErr {
+				t.Errorf("r.CookieWithPolicy(%q) got err: %v want: %v", tt.cookie, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestIncomingRequestCookiesInsecurePrefix(t *testing.T) {
+	// Requests forwarded by a proxy terminating HTTPS look insecure, so
+	// prefixed cookies are only rejected if the policy requires it.
+	r := safehttptest.NewRequest(safehttp.MethodGet, "http://foo.com/", nil)
+	r.Header.Set("Cookie", "__Host-id=x; theme=dark")
+	if _, err := r.Cookie("__Host-id"); err != nil {
+		t.Errorf(`r.Cookie("__Host-id") got err: %v want: nil`, err)
+	}
+	var got []string
+	for _, c := range r.Cookies() {
+		got = append(got, c.Name())
+	}
+	if diff := cmp.Diff([]string{"__Host-id", "theme"}, got); diff != "" {
+		t.Errorf("r.Cookies() names mismatch (-want +got):\n%s", diff)
+	}
+}
+
 func TestIncomingRequestCookies(t *testing.T) {
 	var tests = []struct {
 		name       string
@@ -466,3 +519,16 @@ func TestIncomingRequestFileUploadMissingContent(t *testing.T) {
 		t.Errorf("file.Read(content): got %s, want %s", got, want)
 	}
 }
+
+func TestIncomingRequestSetBody(t *testing.T) {
+	ir := safehttptest.NewRequest(safehttp.MethodPost, "/", strings.NewReader("old"))
+	ir.SetBody(ioutil.NopCloser(strings.NewReader("new")))
+
+	b, err := ioutil.ReadAll(ir.Body())
+	if err != nil {
+		t.Fatalf("ioutil.ReadAll(ir.Body()) got err: %v", err)
+	}
+	if got, want := string(b), "new"; got != want {
+		t.Errorf("ir.Body() got %q, want %q", got, want)
+	}
+}
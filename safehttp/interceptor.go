@@ -21,7 +21,8 @@ package safehttp
 // interceptor methods are guaranteed to be run) etc.
 //
 // Interceptors keep their state across many requests and their methods can be
-// called concurrently. If you need per-request state, use FlightValues.
+// called concurrently. If you need per-request state, use FlightValues or
+// IncomingRequest.Values.
 type Interceptor interface {
 	// Before runs before the IncomingRequest is sent to the handler. If a
 	// response is written to the ResponseWriter, then the remaining
@@ -41,6 +42,17 @@ type Interceptor interface {
 // InterceptorConfig is a configuration for an interceptor.
 type InterceptorConfig interface{}
 
+// ConfigCombiner is implemented by interceptors which accept several
+// configurations for the same route, e.g. a configuration relaxing the checks
+// of the interceptor together with one switching it to report-only.
+type ConfigCombiner interface {
+	// CombineConfigs is called when more than one configuration of a route
+	// matches the interceptor. It returns the configuration passed to the
+	// interceptor for the route, or an error if the configurations can't be
+	// used together.
+	CombineConfigs(cfgs []InterceptorConfig) (InterceptorConfig, error)
+}
+
 // configuredInterceptor holds an interceptor together with its configuration.
 type configuredInterceptor struct {
 	interceptor Interceptor
@@ -20,3 +20,16 @@ package internal
 //
 // After safehttp.init(), this becomes a func(*safehttp.IncomingRequest) *http.Request.
 var RawRequest interface{}
+
+// UnsafeAllowActiveContent is a restricted API. See
+// github.com/google/go-safeweb/safehttp/restricted.UnsafeAllowActiveContent.
+//
+// After safehttp.init(), this becomes a func(string) safehttp.UnsafeOption.
+var UnsafeAllowActiveContent interface{}
+
+// DisableInterceptor is a restricted API. See
+// github.com/google/go-safeweb/safehttp/restricted.DisableInterceptor.
+//
+// After safehttp.init(), this becomes a
+// func(safehttp.Interceptor, string) safehttp.InterceptorConfig.
+var DisableInterceptor interface{}
@@ -20,4 +20,6 @@ import (
 
 func init() {
 	internal.RawRequest = rawRequest
+	internal.UnsafeAllowActiveContent = unsafeAllowActiveContent
+	internal.DisableInterceptor = disableInterceptor
 }
@@ -0,0 +1,145 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package safehttp
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"mime"
+)
+
+const (
+	// DefaultJSONMaxBytes is the default maximum size of the bodies parsed by
+	// ParseJSON.
+	DefaultJSONMaxBytes = 1 << 20
+	// DefaultJSONMaxDepth is the default maximum nesting depth of the bodies
+	// parsed by ParseJSON.
+	DefaultJSONMaxDepth = 32
+)
+
+// JSONOptions are the limits enforced by ParseJSON.
+type JSONOptions struct {
+	// MaxBytes is the maximum size of the body. If zero, DefaultJSONMaxBytes
+	// is used.
+	MaxBytes int64
+	// MaxDepth is the maximum nesting depth of objects and arrays. If zero,
+	// DefaultJSONMaxDepth is used.
+	MaxDepth int
+	// AllowUnknownFields allows object keys that don't match any field of the
+	// destination. By default they are rejected.
+	AllowUnknownFields bool
+}
+
+// JSONError is an error parsing a JSON request body. It's also an
+// ErrorResponse, so that handlers can write it directly:
+//
+//	if err := safehttp.ParseJSON(r, &dst, safehttp.JSONOptions{}); err != nil {
+//		return w.WriteError(err.(*safehttp.JSONError))
+//	}
+type JSONError struct {
+	// Status is the status code of the error: 415 Unsupported Media Type, 413
+	// Request Entity Too Large or 400 Bad Request.
+	Status StatusCode
+	Err    error
+}
+
+func (e *JSONError) Error() string {
+	return fmt.Sprintf("%d %v", e.Status, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *JSONError) Unwrap() error {
+	return e.Err
+}
+
+// Code returns the status code of the error.
+func (e *JSONError) Code() StatusCode {
+	return e.Status
+}
+
+// ParseJSON decodes the JSON body of the request into dst, which must be a
+// pointer. It checks that the Content-Type is application/json and enforces
+// the given limits. The body must contain a single JSON value. Errors are
+// *JSONErrors.
+func ParseJSON(r *IncomingRequest, dst interface{}, opts JSONOptions) error {
+	mt, _, err := mime.ParseMediaType(r.req.Header.Get("Content-Type"))
+	if err != nil || mt != "application/json" {
+		return &JSONError{Status: StatusUnsupportedMediaType, Err: fmt.Errorf("got Content-Type %q, want application/json", r.req.Header.Get("Content-Type"))}
+	}
+	maxBytes := opts.MaxBytes
+	if maxBytes == 0 {
+		maxBytes = DefaultJSONMaxBytes
+	}
+	maxDepth := opts.MaxDepth
+	if maxDepth == 0 {
+		maxDepth = DefaultJSONMaxDepth
+	}
+
+	body, err := ioutil.ReadAll(r.LimitedBody(BodyLimits{MaxBytes: maxBytes}))
+	if err == ErrBodyTooLarge {
+		return &JSONError{Status: StatusRequestEntityTooLarge, Err: err}
+	}
+	if err != nil {
+		return &JSONError{Status: StatusBadRequest, Err: err}
+	}
+	if err := checkJSONDepth(body, maxDepth); err != nil {
+		return &JSONError{Status: StatusBadRequest, Err: err}
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(body))
+	if !opts.AllowUnknownFields {
+		dec.DisallowUnknownFields()
+	}
+	if err := dec.Decode(dst); err != nil {
+		return &JSONError{Status: StatusBadRequest, Err: err}
+	}
+	if dec.More() {
+		return &JSONError{Status: StatusBadRequest, Err: errors.New("unexpected data after the JSON value")}
+	}
+	return nil
+}
+
+// checkJSONDepth returns an error if the objects and arrays in data are nested
+// deeper than max. Invalid JSON is left to the decoder.
+func checkJSONDepth(data []byte, max int) error {
+	depth := 0
+	inString, escaped := false, false
+	for _, c := range data {
+		switch {
+		case inString:
+			switch {
+			case escaped:
+				escaped = false
+			case c == '\\':
+				escaped = true
+			case c == '"':
+				inString = false
+			}
+		case c == '"':
+			inString = true
+		case c == '{' || c == '[':
+			depth++
+			if depth > max {
+				return fmt.Errorf("JSON nested deeper than %d levels", max)
+			}
+		case c == '}' || c == ']':
+			depth--
+		}
+	}
+	return nil
+}
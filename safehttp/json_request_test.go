@@ -0,0 +1,123 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package safehttp_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-safeweb/safehttp"
+	"github.com/google/go-safeweb/safehttp/safehttptest"
+)
+
+type jsonUser struct {
+	Name string   `json:"name"`
+	Tags []string `json:"tags"`
+}
+
+func TestParseJSON(t *testing.T) {
+	r := safehttptest.NewRequest(safehttp.MethodPost, "/", strings.NewReader(`{"name": "alice", "tags": ["a", "b"]}`))
+	r.Header.Set("Content-Type", "application/json; charset=utf-8")
+
+	var got jsonUser
+	if err := safehttp.ParseJSON(r, &got, safehttp.JSONOptions{}); err != nil {
+		t.Fatalf("safehttp.ParseJSON() got err: %v", err)
+	}
+	want := jsonUser{Name: "alice", Tags: []string{"a", "b"}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("safehttp.ParseJSON() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestParseJSONErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		opts        safehttp.JSONOptions
+		wantStatus  safehttp.StatusCode
+	}{
+		{
+			name:        "Wrong Content-Type",
+			contentType: "text/plain",
+			body:        `{"name": "alice"}`,
+			wantStatus:  safehttp.StatusUnsupportedMediaType,
+		},
+		{
+			name:        "Too large",
+			contentType: "application/json",
+			body:        `{"name": "alice"}`,
+			opts:        safehttp.JSONOptions{MaxBytes: 10},
+			wantStatus:  safehttp.StatusRequestEntityTooLarge,
+		},
+		{
+			name:        "Unknown field",
+			contentType: "application/json",
+			body:        `{"name": "alice", "admin": true}`,
+			wantStatus:  safehttp.StatusBadRequest,
+		},
+		{
+			name:        "Too deep",
+			contentType: "application/json",
+			body:        `{"name": "alice", "tags": [[["a"]]]}`,
+			opts:        safehttp.JSONOptions{MaxDepth: 3},
+			wantStatus:  safehttp.StatusBadRequest,
+		},
+		{
+			name:        "Invalid",
+			contentType: "application/json",
+			body:        `{"name": `,
+			wantStatus:  safehttp.StatusBadRequest,
+		},
+		{
+			name:        "Trailing data",
+			contentType: "application/json",
+			body:        `{"name": "alice"} {"name": "bob"}`,
+			wantStatus:  safehttp.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := safehttptest.NewRequest(safehttp.MethodPost, "/", strings.NewReader(tt.body))
+			r.Header.Set("Content-Type", tt.contentType)
+
+			var got jsonUser
+			err := safehttp.ParseJSON(r, &got, tt.opts)
+			var jsonErr *safehttp.JSONError
+			if !errors.As(err, &jsonErr) {
+				t.Fatalf("safehttp.ParseJSON() got err %v, want *safehttp.JSONError", err)
+			}
+			if got := jsonErr.Code(); got != tt.wantStatus {
+				t.Errorf("jsonErr.Code() got %v, want %v", got, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestParseJSONAllowUnknownFields(t *testing.T) {
+	r := safehttptest.NewRequest(safehttp.MethodPost, "/", strings.NewReader(`{"name": "alice", "admin": true, "nested": {"a": "]"}}`))
+	r.Header.Set("Content-Type", "application/json")
+
+	var got jsonUser
+	if err := safehttp.ParseJSON(r, &got, safehttp.JSONOptions{AllowUnknownFields: true, MaxDepth: 2}); err != nil {
+		t.Fatalf("safehttp.ParseJSON() got err: %v", err)
+	}
+	if got.Name != "alice" {
+		t.Errorf("got.Name: got %q want %q", got.Name, "alice")
+	}
+}
@@ -0,0 +1,88 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package safehttp
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// JSONStreamResponse is a stream of JSON records, written as newline-delimited
+// JSON ("application/x-ndjson").
+//
+// Records are pulled from Next one at a time, only after the previous one was
+// written and flushed to the client, so a slow client slows down the producer
+// instead of making the server buffer the results.
+//
+// If Next returns an error, or a record can't be encoded, before the first record, 500 Internal Server Error
+// is written. Afterwards the status code can't be changed anymore: the
+// connection is aborted instead, so that the client doesn't mistake the
+// partial stream for a complete one.
+type JSONStreamResponse struct {
+	// Next returns the next record, or false when there are no more records.
+	Next func() (record interface{}, ok bool, err error)
+}
+
+// JSONStreamFromChannel creates a JSONStreamResponse writing the records
+// received from ch, until it's closed. The producer should stop sending when
+// the context of the request is done, since records are not received anymore
+// if the client goes away.
+func JSONStreamFromChannel(ch <-chan interface{}) JSONStreamResponse {
+	return JSONStreamResponse{Next: func() (interface{}, bool, error) {
+		rec, ok := <-ch
+		return rec, ok, nil
+	}}
+}
+
+// JSONStreamFromSlice creates a JSONStreamResponse writing the given records.
+func JSONStreamFromSlice(records []interface{}) JSONStreamResponse {
+	i := 0
+	return JSONStreamResponse{Next: func() (interface{}, bool, error) {
+		if i == len(records) {
+			return nil, false, nil
+		}
+		i++
+		return records[i-1], true, nil
+	}}
+}
+
+func writeJSONStream(rw http.ResponseWriter, resp JSONStreamResponse) error {
+	rw.Header().Set("Content-Type", "application/x-ndjson; charset=utf-8")
+	fl, _ := rw.(http.Flusher)
+	for n := 0; ; n++ {
+		rec, ok, err := resp.Next()
+		var b []byte
+		if err == nil && ok {
+			b, err = json.Marshal(rec)
+		}
+		if err != nil {
+			if n == 0 {
+				writeTextError(rw, StatusInternalServerError)
+				return nil
+			}
+			return http.ErrAbortHandler
+		}
+		if !ok {
+			return nil
+		}
+		if _, err := rw.Write(append(b, '\n')); err != nil {
+			// The client is gone.
+			return nil
+		}
+		if fl != nil {
+			fl.Flush()
+		}
+	}
+}
@@ -0,0 +1,119 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package safehttp_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-safeweb/safehttp"
+)
+
+func serveJSONStream(t *testing.T, resp safehttp.JSONStreamResponse) *httptest.ResponseRecorder {
+	t.Helper()
+	mux := safehttp.NewServeMuxConfig(nil).Mux()
+	mux.Handle("/", safehttp.MethodGet, safehttp.HandlerFunc(func(w safehttp.ResponseWriter, r *safehttp.IncomingRequest) safehttp.Result {
+		return w.Write(resp)
+	}))
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, httptest.NewRequest(safehttp.MethodGet, "http://foo.com/", nil))
+	return rr
+}
+
+func TestJSONStreamFromSlice(t *testing.T) {
+	rr := serveJSONStream(t, safehttp.JSONStreamFromSlice([]interface{}{
+		map[string]int{"id": 1},
+		map[string]string{"name": "<script>"},
+		[]int{1, 2},
+	}))
+
+	if got, want := rr.Code, int(safehttp.StatusOK); got != want {
+		t.Errorf("rr.Code: got %v want %v", got, want)
+	}
+	if got, want := rr.Header().Get("Content-Type"), "application/x-ndjson; charset=utf-8"; got != want {
+		t.Errorf(`rr.Header().Get("Content-Type"): got %q want %q`, got, want)
+	}
+	if got, want := rr.Body.String(), "{\"id\":1}\n{\"name\":\"\\u003cscript\\u003e\"}\n[1,2]\n"; got != want {
+		t.Errorf("rr.Body: got %q want %q", got, want)
+	}
+	if !rr.Flushed {
+		t.Error("rr.Flushed: got false want true")
+	}
+}
+
+// flushRecorder records the number of bytes written at each flush.
+type flushRecorder struct {
+	*httptest.ResponseRecorder
+	flushes []int
+}
+
+func (r *flushRecorder) Flush() {
+	r.flushes = append(r.flushes, r.Body.Len())
+	r.ResponseRecorder.Flush()
+}
+
+func TestJSONStreamFromChannel(t *testing.T) {
+	ch := make(chan interface{})
+	go func() {
+		defer close(ch)
+		for i := 0; i < 3; i++ {
+			ch <- i
+		}
+	}()
+	mux := safehttp.NewServeMuxConfig(nil).Mux()
+	mux.Handle("/", safehttp.MethodGet, safehttp.HandlerFunc(func(w safehttp.ResponseWriter, r *safehttp.IncomingRequest) safehttp.Result {
+		return w.Write(safehttp.JSONStreamFromChannel(ch))
+	}))
+	rr := &flushRecorder{ResponseRecorder: httptest.NewRecorder()}
+	mux.ServeHTTP(rr, httptest.NewRequest(safehttp.MethodGet, "http://foo.com/", nil))
+
+	if got, want := rr.Body.String(), "0\n1\n2\n"; got != want {
+		t.Errorf("rr.Body: got %q want %q", got, want)
+	}
+	if got, want := len(rr.flushes), 3; got != want {
+		t.Errorf("flushes: got %v, want one per record", rr.flushes)
+	}
+}
+
+func TestJSONStreamErrors(t *testing.T) {
+	failAfter := func(n int) safehttp.JSONStreamResponse {
+		return safehttp.JSONStreamResponse{Next: func() (interface{}, bool, error) {
+			if n == 0 {
+				return nil, false, errors.New("database is down")
+			}
+			n--
+			return n, true, nil
+		}}
+	}
+
+	rr := serveJSONStream(t, failAfter(0))
+	if got, want := rr.Code, int(safehttp.StatusInternalServerError); got != want {
+		t.Errorf("error before the first record: rr.Code got %v want %v", got, want)
+	}
+
+	rr = serveJSONStream(t, safehttp.JSONStreamFromSlice([]interface{}{make(chan int)}))
+	if got, want := rr.Code, int(safehttp.StatusInternalServerError); got != want {
+		t.Errorf("unencodable record: rr.Code got %v want %v", got, want)
+	}
+
+	defer func() {
+		if r := recover(); r != http.ErrAbortHandler {
+			t.Errorf("error after the first record: got panic %v, want %v", r, http.ErrAbortHandler)
+		}
+	}()
+	serveJSONStream(t, failAfter(1))
+}
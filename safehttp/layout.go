@@ -0,0 +1,88 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package safehttp
+
+import (
+	"io"
+	"strings"
+
+	"github.com/google/safehtml"
+	"github.com/google/safehtml/template"
+)
+
+// LayoutData is the data a layout template is executed with.
+//
+// The page template is rendered first and its output is made available to the
+// layout as Content. Every associated template named "<page>:<block>", e.g.
+// "users.html:header" for the "users.html" page, is rendered too and made
+// available as Block "<block>". Blocks let pages fill named parts of the
+// layout other than the main content. For example:
+//
+//	{{define "layout.html"}}
+//	<header>{{.Block "header"}}</header>
+//	<main>{{.Content}}</main>
+//	{{end}}
+//
+// The page and its blocks are executed with Data.
+type LayoutData struct {
+	Content safehtml.HTML
+	Blocks  map[string]safehtml.HTML
+	Data    interface{}
+}
+
+// Block returns the named block of the page, or an empty HTML if the page
+// doesn't define it.
+func (d LayoutData) Block(name string) safehtml.HTML {
+	return d.Blocks[name]
+}
+
+// ExecuteNamedTemplateWithLayout creates a TemplateResponse that renders the
+// named associated template (the page) inside the layout, another associated
+// template of t, and calls the Write function of the ResponseWriter, passing
+// the response. See LayoutData for the data the layout is executed with.
+//
+// Leaving layout empty renders the page on its own, which is useful for
+// partial responses, e.g. to requests that replace a fragment of a page that
+// was already rendered with the layout.
+func ExecuteNamedTemplateWithLayout(w ResponseWriter, t Template, layout, name string, data interface{}) Result {
+	return w.Write(&TemplateResponse{Template: t, Name: name, Data: data, Layout: layout})
+}
+
+// executeWithLayout renders the page and its blocks and then writes the layout
+// of the response. Nothing is written if rendering the page fails.
+func executeWithLayout(w io.Writer, t *template.Template, resp *TemplateResponse) error {
+	page := resp.Name
+	if page == "" {
+		page = t.Name()
+	}
+	content, err := t.ExecuteTemplateToHTML(page, resp.Data)
+	if err != nil {
+		return err
+	}
+	ld := LayoutData{Content: content, Blocks: map[string]safehtml.HTML{}, Data: resp.Data}
+	prefix := page + ":"
+	for _, b := range t.Templates() {
+		name := b.Name()
+		if !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		h, err := t.ExecuteTemplateToHTML(name, resp.Data)
+		if err != nil {
+			return err
+		}
+		ld.Blocks[strings.TrimPrefix(name, prefix)] = h
+	}
+	return t.ExecuteTemplate(w, resp.Layout, ld)
+}
@@ -0,0 +1,117 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package safehttp_test
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-safeweb/safehttp"
+	"github.com/google/safehtml/template"
+)
+
+// newLayoutTemplate returns a new template, as templates can't be cloned (to
+// add a FuncMap) once they were executed.
+func newLayoutTemplate() *template.Template {
+	return template.Must(template.New("").Funcs(template.FuncMap{"greeting": func() string { return "" }}).Parse(`
+{{- define "layout"}}<header>{{.Block "header"}}</header><main>{{.Content}}</main>{{end -}}
+{{- define "page"}}<p>{{.}}</p>{{end -}}
+{{- define "page:header"}}<h1>Page {{.}}</h1>{{end -}}
+{{- define "greet"}}{{greeting}} {{.}}{{end -}}
+{{- define "broken"}}{{template "missing"}}{{end -}}
+`))
+}
+
+func TestExecuteNamedTemplateWithLayout(t *testing.T) {
+	tests := []struct {
+		name       string
+		resp       *safehttp.TemplateResponse
+		wantStatus safehttp.StatusCode
+		wantBody   string
+	}{
+		{
+			name:       "Layout",
+			resp:       &safehttp.TemplateResponse{Template: newLayoutTemplate(), Name: "page", Data: "<b>", Layout: "layout"},
+			wantStatus: safehttp.StatusOK,
+			wantBody:   "<header><h1>Page &lt;b&gt;</h1></header><main><p>&lt;b&gt;</p></main>",
+		},
+		{
+			name:       "Partial",
+			resp:       &safehttp.TemplateResponse{Template: newLayoutTemplate(), Name: "page", Data: "<b>"},
+			wantStatus: safehttp.StatusOK,
+			wantBody:   "<p>&lt;b&gt;</p>",
+		},
+		{
+			name: "Layout with FuncMap",
+			resp: &safehttp.TemplateResponse{
+				Template: newLayoutTemplate(),
+				Name:     "greet",
+				Data:     "world",
+				FuncMap:  map[string]interface{}{"greeting": func() string { return "Hello" }},
+				Layout:   "layout",
+			},
+			wantStatus: safehttp.StatusOK,
+			wantBody:   "<header></header><main>Hello world</main>",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mux := safehttp.NewServeMuxConfig(nil).Mux()
+			mux.Handle("/", safehttp.MethodGet, safehttp.HandlerFunc(func(w safehttp.ResponseWriter, r *safehttp.IncomingRequest) safehttp.Result {
+				return w.Write(tt.resp)
+			}))
+			rr := httptest.NewRecorder()
+			mux.ServeHTTP(rr, httptest.NewRequest(safehttp.MethodGet, "http://foo.com/", nil))
+
+			if got, want := rr.Code, int(tt.wantStatus); got != want {
+				t.Errorf("rr.Code: got %v want %v", got, want)
+			}
+			if got := rr.Body.String(); got != tt.wantBody {
+				t.Errorf("rr.Body: got %q want %q", got, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestExecuteNamedTemplateWithLayoutHelper(t *testing.T) {
+	mux := safehttp.NewServeMuxConfig(nil).Mux()
+	mux.Handle("/", safehttp.MethodGet, safehttp.HandlerFunc(func(w safehttp.ResponseWriter, r *safehttp.IncomingRequest) safehttp.Result {
+		return safehttp.ExecuteNamedTemplateWithLayout(w, newLayoutTemplate(), "layout", "page", "x")
+	}))
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, httptest.NewRequest(safehttp.MethodGet, "http://foo.com/", nil))
+
+	if got, want := rr.Body.String(), "<header><h1>Page x</h1></header><main><p>x</p></main>"; got != want {
+		t.Errorf("rr.Body: got %q want %q", got, want)
+	}
+}
+
+func TestExecuteNamedTemplateWithLayoutPageFails(t *testing.T) {
+	rr := httptest.NewRecorder()
+	defer func() {
+		if r := recover(); r == nil {
+			t.Error("expected panic")
+		}
+		if got := rr.Body.String(); got != "" {
+			t.Errorf("rr.Body: got %q, want nothing written", got)
+		}
+	}()
+	mux := safehttp.NewServeMuxConfig(nil).Mux()
+	mux.Handle("/", safehttp.MethodGet, safehttp.HandlerFunc(func(w safehttp.ResponseWriter, r *safehttp.IncomingRequest) safehttp.Result {
+		return safehttp.ExecuteNamedTemplateWithLayout(w, newLayoutTemplate(), "layout", "broken", nil)
+	}))
+	mux.ServeHTTP(rr, httptest.NewRequest(safehttp.MethodGet, "http://foo.com/", nil))
+}
@@ -0,0 +1,94 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package safehttp
+
+import "sync/atomic"
+
+// ServerState is the lifecycle state of a Server, e.g. for health checks.
+type ServerState int32
+
+const (
+	// ServerNew is the state of a Server which wasn't started.
+	ServerNew ServerState = iota
+	// ServerStarting is the state of a Server running its OnStart hooks.
+	ServerStarting
+	// ServerReady is the state of a Server accepting connections.
+	ServerReady
+	// ServerDraining is the state of a Server being shut down.
+	ServerDraining
+	// ServerClosed is the state of a Server which was shut down, closed or
+	// failed to start.
+	ServerClosed
+)
+
+var serverStateNames = [...]string{"new", "starting", "ready", "draining", "closed"}
+
+func (st ServerState) String() string {
+	if st < 0 || int(st) >= len(serverStateNames) {
+		return "unknown"
+	}
+	return serverStateNames[st]
+}
+
+// State returns the lifecycle state of the server. It's safe to call it
+// concurrently.
+func (s *Server) State() ServerState {
+	return ServerState(atomic.LoadInt32(&s.state))
+}
+
+// Ready reports whether the server accepts connections, e.g. for readiness
+// probes.
+func (s *Server) Ready() bool {
+	return s.State() == ServerReady
+}
+
+// Live reports whether the server is starting or running, including while
+// it's being shut down, e.g. for liveness probes.
+func (s *Server) Live() bool {
+	st := s.State()
+	return st > ServerNew && st < ServerClosed
+}
+
+func (s *Server) setState(st ServerState) {
+	atomic.StoreInt32(&s.state, int32(st))
+}
+
+// start builds the server and runs the OnStart hooks.
+func (s *Server) start() error {
+	if err := s.buildStd(); err != nil {
+		return err
+	}
+	s.started = true
+	s.setState(ServerStarting)
+	for _, f := range s.OnStart {
+		if err := f(); err != nil {
+			s.stopTicketKeyRotation()
+			s.setState(ServerClosed)
+			return err
+		}
+	}
+	return nil
+}
+
+// ready marks the server as ready and runs the OnReady hooks.
+func (s *Server) ready() {
+	if !atomic.CompareAndSwapInt32(&s.state, int32(ServerStarting), int32(ServerReady)) {
+		// Shutdown was already called.
+		return
+	}
+	for _, f := range s.OnReady {
+		f()
+	}
+}
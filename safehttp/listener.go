@@ -0,0 +1,124 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package safehttp
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"time"
+)
+
+// ListenUnix listens on the Unix domain socket at path and sets its
+// permissions to mode, e.g. 0660 to only allow a reverse proxy running in the
+// same group to connect. A stale socket left by a previous process is
+// removed, but ListenUnix fails if path is another kind of file or if a
+// server is still listening on it. The socket is removed when the listener is
+// closed.
+//
+// The socket is created with the permissions allowed by the umask of the
+// process before its mode is changed: if that matters, it should be created
+// in a directory only accessible to the server.
+func ListenUnix(path string, mode os.FileMode) (net.Listener, error) {
+	if fi, err := os.Lstat(path); err == nil {
+		if fi.Mode()&os.ModeSocket == 0 {
+			return nil, fmt.Errorf("%s exists and is not a socket", path)
+		}
+		if c, err := net.DialTimeout("unix", path, time.Second); err == nil {
+			c.Close()
+			return nil, fmt.Errorf("%s is in use", path)
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, err
+		}
+	}
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, mode); err != nil {
+		l.Close()
+		return nil, err
+	}
+	return l, nil
+}
+
+// ServeUnix serves on the Unix domain socket at path, created with
+// ListenUnix.
+func (s *Server) ServeUnix(path string, mode os.FileMode) error {
+	l, err := ListenUnix(path, mode)
+	if err != nil {
+		return err
+	}
+	return s.Serve(l)
+}
+
+// systemdFirstFD is the first file descriptor passed by systemd.
+const systemdFirstFD = 3
+
+// SystemdListeners returns the listening sockets passed by systemd socket
+// activation, in the order of the ListenStream directives of the socket unit.
+// It returns no listeners if the process wasn't socket activated.
+//
+// The LISTEN_PID, LISTEN_FDS and LISTEN_FDNAMES environment variables are
+// unset, so that the sockets aren't used again, e.g. by child processes.
+//
+// More info:
+//   - https://www.freedesktop.org/software/systemd/man/sd_listen_fds.html
+func SystemdListeners() ([]net.Listener, error) {
+	pid, fds := os.Getenv("LISTEN_PID"), os.Getenv("LISTEN_FDS")
+	os.Unsetenv("LISTEN_PID")
+	os.Unsetenv("LISTEN_FDS")
+	os.Unsetenv("LISTEN_FDNAMES")
+	return listenersFromEnv(pid, fds, os.Getpid(), systemdFirstFD)
+}
+
+func listenersFromEnv(pid, fds string, wantPID, firstFD int) ([]net.Listener, error) {
+	if pid == "" && fds == "" {
+		return nil, nil
+	}
+	if p, err := strconv.Atoi(pid); err != nil || p != wantPID {
+		// The variables are meant for another process.
+		return nil, nil
+	}
+	n, err := strconv.Atoi(fds)
+	if err != nil || n < 0 {
+		return nil, fmt.Errorf("invalid LISTEN_FDS %q", fds)
+	}
+	ls := make([]net.Listener, 0, n)
+	for fd := firstFD; fd < firstFD+n; fd++ {
+		l, err := fileListener(fd)
+		if err != nil {
+			for _, l := range ls {
+				l.Close()
+			}
+			return nil, fmt.Errorf("file descriptor %d: %v", fd, err)
+		}
+		ls = append(ls, l)
+	}
+	return ls, nil
+}
+
+func fileListener(fd int) (net.Listener, error) {
+	f := os.NewFile(uintptr(fd), "LISTEN_FD_"+strconv.Itoa(fd))
+	if f == nil {
+		return nil, errors.New("invalid file descriptor")
+	}
+	// FileListener duplicates the file descriptor.
+	defer f.Close()
+	return net.FileListener(f)
+}
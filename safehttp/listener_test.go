@@ -0,0 +1,141 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package safehttp
+
+import (
+	"context"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/safehtml"
+)
+
+func TestListenUnix(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "server.sock")
+	mux := NewServeMuxConfig(nil).Mux()
+	mux.Handle("/", "GET", HandlerFunc(func(w ResponseWriter, r *IncomingRequest) Result {
+		return w.Write(safehtml.HTMLEscaped("response"))
+	}))
+	l, err := ListenUnix(path, 0660)
+	if err != nil {
+		t.Fatalf("ListenUnix: %v", err)
+	}
+	s := &Server{Mux: mux}
+	go s.Serve(l)
+	defer s.Close()
+
+	fi, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("os.Stat: %v", err)
+	}
+	if got, want := fi.Mode().Perm(), os.FileMode(0660); got != want {
+		t.Errorf("socket permissions: got %v want %v", got, want)
+	}
+
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", path)
+		},
+	}}
+	resp, err := client.Get("http://unix/")
+	if err != nil {
+		t.Fatalf("client.Get: %v", err)
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("reading the body: %v", err)
+	}
+	if got, want := string(body), "response"; got != want {
+		t.Errorf("body: got %q want %q", got, want)
+	}
+
+	if _, err := ListenUnix(path, 0660); err == nil {
+		t.Error("ListenUnix on a socket in use: got nil, want error")
+	}
+}
+
+func TestListenUnixStaleSocket(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "server.sock")
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatalf("net.Listen: %v", err)
+	}
+	// Leave the socket behind, like a crashed process.
+	l.(*net.UnixListener).SetUnlinkOnClose(false)
+	l.Close()
+
+	l, err = ListenUnix(path, 0600)
+	if err != nil {
+		t.Fatalf("ListenUnix: got %v, want the stale socket to be replaced", err)
+	}
+	l.Close()
+}
+
+func TestListenUnixNotSocket(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "file")
+	if err := ioutil.WriteFile(path, []byte("data"), 0600); err != nil {
+		t.Fatalf("ioutil.WriteFile: %v", err)
+	}
+	if _, err := ListenUnix(path, 0600); err == nil {
+		t.Error("ListenUnix: got nil, want error")
+	}
+	if b, err := ioutil.ReadFile(path); err != nil || string(b) != "data" {
+		t.Errorf("the file was changed: got %q, %v", b, err)
+	}
+}
+
+func TestListenersFromEnv(t *testing.T) {
+	tl, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("net.Listen: %v", err)
+	}
+	defer tl.Close()
+	f, err := tl.(*net.TCPListener).File()
+	if err != nil {
+		t.Fatalf("File: %v", err)
+	}
+	defer f.Close()
+	fd := int(f.Fd())
+
+	ls, err := listenersFromEnv("42", "1", 42, fd)
+	if err != nil {
+		t.Fatalf("listenersFromEnv: %v", err)
+	}
+	if len(ls) != 1 {
+		t.Fatalf("listenersFromEnv: got %d listeners, want 1", len(ls))
+	}
+	defer ls[0].Close()
+	if got, want := ls[0].Addr().String(), tl.Addr().String(); got != want {
+		t.Errorf("listener address: got %v want %v", got, want)
+	}
+
+	if ls, err := listenersFromEnv("", "", 42, fd); err != nil || ls != nil {
+		t.Errorf("not socket activated: got %v, %v, want no listeners", ls, err)
+	}
+	if ls, err := listenersFromEnv("7", "1", 42, fd); err != nil || ls != nil {
+		t.Errorf("another process: got %v, %v, want no listeners", ls, err)
+	}
+	if _, err := listenersFromEnv("42", "x", 42, fd); err == nil {
+		t.Error("invalid LISTEN_FDS: got nil, want error")
+	}
+	if _, err := listenersFromEnv("42", "1", 42, fd+100); err == nil {
+		t.Errorf("closed file descriptor %d: got nil, want error", fd+100)
+	}
+}
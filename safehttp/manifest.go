@@ -0,0 +1,171 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package safehttp
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Manifest describes the routes of a service as data, so that their security
+// configuration can be audited and diffed in review. It's usually decoded
+// from JSON with LoadServeMuxConfig, e.g.
+//
+//	{
+//	  "interceptors": ["hsts", "xsrf"],
+//	  "routes": [
+//	    {"pattern": "/", "methods": ["GET"], "handler": "home"},
+//	    {
+//	      "pattern": "/api/",
+//	      "methods": ["POST"],
+//	      "handler": "api",
+//	      "timeout": "5s",
+//	      "disable": [{"interceptor": "xsrf", "reason": "authenticated with API keys"}]
+//	    }
+//	  ]
+//	}
+//
+// Interceptors, handlers and configurations are referred to by name, and
+// resolved with a ManifestRegistry.
+type Manifest struct {
+	// Interceptors are the names of the interceptors installed for all the
+	// routes, in order.
+	Interceptors []string `json:"interceptors"`
+	// Routes are the handlers of the service.
+	Routes []ManifestRoute `json:"routes"`
+}
+
+// ManifestRoute is a handler registered for a pattern and a set of methods.
+type ManifestRoute struct {
+	// Pattern is the pattern of the route, as passed to ServeMux.Handle.
+	Pattern string `json:"pattern"`
+	// Methods are the HTTP methods served by the handler.
+	Methods []string `json:"methods"`
+	// Handler is the name of the handler.
+	Handler string `json:"handler"`
+	// Configs are the names of the InterceptorConfigs of the route.
+	Configs []string `json:"configs,omitempty"`
+	// Timeout is the timeout of the route, parsed with time.ParseDuration.
+	// See WithTimeout.
+	Timeout string `json:"timeout,omitempty"`
+	// Disable lists the interceptors disabled for the route. See
+	// restricted.DisableInterceptor.
+	Disable []ManifestDisable `json:"disable,omitempty"`
+}
+
+// ManifestDisable disables an interceptor for a route.
+type ManifestDisable struct {
+	// Interceptor is the name of the interceptor.
+	Interceptor string `json:"interceptor"`
+	// Reason is the mandatory justification.
+	Reason string `json:"reason"`
+}
+
+// ManifestRegistry maps the names used in a Manifest to their values.
+type ManifestRegistry struct {
+	Interceptors map[string]Interceptor
+	Handlers     map[string]Handler
+	Configs      map[string]InterceptorConfig
+}
+
+// LoadServeMuxConfig decodes a JSON Manifest from r and builds a
+// ServeMuxConfig from it, with the given Dispatcher. Unknown fields and names
+// missing from the registry are reported as errors, so that a typo can't
+// silently drop a protection. YAML manifests have to be converted to JSON
+// first.
+//
+// The returned ServeMuxConfig can be further configured before calling Mux.
+func LoadServeMuxConfig(r io.Reader, disp Dispatcher, reg ManifestRegistry) (*ServeMuxConfig, error) {
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	var m Manifest
+	if err := dec.Decode(&m); err != nil {
+		return nil, fmt.Errorf("decoding manifest: %v", err)
+	}
+	return m.ServeMuxConfig(disp, reg)
+}
+
+// ServeMuxConfig builds a ServeMuxConfig from the manifest, with the given
+// Dispatcher. See LoadServeMuxConfig.
+func (m *Manifest) ServeMuxConfig(disp Dispatcher, reg ManifestRegistry) (*ServeMuxConfig, error) {
+	s := NewServeMuxConfig(disp)
+	for _, name := range m.Interceptors {
+		it, ok := reg.Interceptors[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown interceptor %q", name)
+		}
+		s.Intercept(it)
+	}
+
+	g := &Group{}
+	for i, rt := range m.Routes {
+		if rt.Pattern == "" || !strings.Contains(rt.Pattern, "/") {
+			return nil, fmt.Errorf("route %d: invalid pattern %q", i, rt.Pattern)
+		}
+		if len(rt.Methods) == 0 {
+			return nil, fmt.Errorf("route %d (%q): no methods", i, rt.Pattern)
+		}
+		h, ok := reg.Handlers[rt.Handler]
+		if !ok {
+			return nil, fmt.Errorf("route %d (%q): unknown handler %q", i, rt.Pattern, rt.Handler)
+		}
+		cfgs, err := rt.configs(reg)
+		if err != nil {
+			return nil, fmt.Errorf("route %d (%q): %v", i, rt.Pattern, err)
+		}
+		for _, method := range rt.Methods {
+			g.handlers = append(g.handlers, groupHandler{
+				pattern: rt.Pattern,
+				method:  method,
+				h:       h,
+				cfgs:    cfgs,
+			})
+		}
+	}
+	s.groups = append(s.groups, g)
+	return s, nil
+}
+
+func (rt *ManifestRoute) configs(reg ManifestRegistry) ([]InterceptorConfig, error) {
+	var cfgs []InterceptorConfig
+	for _, name := range rt.Configs {
+		cfg, ok := reg.Configs[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown config %q", name)
+		}
+		cfgs = append(cfgs, cfg)
+	}
+	if rt.Timeout != "" {
+		d, err := time.ParseDuration(rt.Timeout)
+		if err != nil {
+			return nil, err
+		}
+		cfgs = append(cfgs, WithTimeout(d))
+	}
+	for _, d := range rt.Disable {
+		it, ok := reg.Interceptors[d.Interceptor]
+		if !ok {
+			return nil, fmt.Errorf("unknown interceptor %q", d.Interceptor)
+		}
+		if strings.TrimSpace(d.Reason) == "" {
+			return nil, fmt.Errorf("no reason to disable interceptor %q", d.Interceptor)
+		}
+		cfgs = append(cfgs, disableInterceptor(it, d.Reason))
+	}
+	return cfgs, nil
+}
@@ -0,0 +1,157 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package safehttp_test
+
+import (
+	"fmt"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-safeweb/safehttp"
+	"github.com/google/safehtml"
+)
+
+const testManifest = `{
+  "interceptors": ["log", "header"],
+  "routes": [
+    {"pattern": "/", "methods": ["GET"], "handler": "hello"},
+    {
+      "pattern": "/api/",
+      "methods": ["GET", "POST"],
+      "handler": "hello",
+      "configs": ["log-api"],
+      "timeout": "5s",
+      "disable": [{"interceptor": "header", "reason": "not served to browsers"}]
+    }
+  ]
+}`
+
+func TestLoadServeMuxConfig(t *testing.T) {
+	var log []string
+	reg := safehttp.ManifestRegistry{
+		Interceptors: map[string]safehttp.Interceptor{
+			"log":    recordingInterceptor{name: "log", log: &log},
+			"header": setHeaderInterceptor{name: "Foo", value: "bar"},
+		},
+		Handlers: map[string]safehttp.Handler{
+			"hello": safehttp.HandlerFunc(func(w safehttp.ResponseWriter, r *safehttp.IncomingRequest) safehttp.Result {
+				return w.Write(safehtml.HTMLEscaped("hello"))
+			}),
+		},
+		Configs: map[string]safehttp.InterceptorConfig{
+			"log-api": recordingConfig{name: "log", value: "api"},
+		},
+	}
+
+	mb, err := safehttp.LoadServeMuxConfig(strings.NewReader(testManifest), nil, reg)
+	if err != nil {
+		t.Fatalf("safehttp.LoadServeMuxConfig() got err: %v", err)
+	}
+	mux := mb.Mux()
+
+	var got []string
+	for _, r := range mux.Routes() {
+		s := fmt.Sprintf("%s %s %v", r.Pattern, r.Method, r.Timeout)
+		for _, it := range r.Interceptors {
+			s += fmt.Sprintf(" %T", it.Interceptor)
+		}
+		for _, d := range r.Disabled {
+			s += fmt.Sprintf(" -%T(%s)", d.Interceptor, d.Reason)
+		}
+		got = append(got, s)
+	}
+	want := []string{
+		"/ GET 0s safehttp_test.recordingInterceptor safehttp_test.setHeaderInterceptor",
+		"/api/ GET 5s safehttp_test.recordingInterceptor -safehttp_test.setHeaderInterceptor(not served to browsers)",
+		"/api/ POST 5s safehttp_test.recordingInterceptor -safehttp_test.setHeaderInterceptor(not served to browsers)",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("mux.Routes() mismatch (-want +got):\n%s", diff)
+	}
+
+	rw := httptest.NewRecorder()
+	mux.ServeHTTP(rw, httptest.NewRequest(safehttp.MethodGet, "http://foo.com/api/users", nil))
+	if got, want := rw.Body.String(), "hello"; got != want {
+		t.Errorf("response body: got %q want %q", got, want)
+	}
+	if diff := cmp.Diff([]string{"before log api", "commit log"}, log); diff != "" {
+		t.Errorf("log mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestLoadServeMuxConfigErrors(t *testing.T) {
+	reg := safehttp.ManifestRegistry{
+		Interceptors: map[string]safehttp.Interceptor{
+			"header": setHeaderInterceptor{name: "Foo", value: "bar"},
+		},
+		Handlers: map[string]safehttp.Handler{
+			"hello": safehttp.HandlerFunc(func(w safehttp.ResponseWriter, r *safehttp.IncomingRequest) safehttp.Result {
+				return w.Write(safehtml.HTMLEscaped("hello"))
+			}),
+		},
+	}
+
+	tests := []struct {
+		name     string
+		manifest string
+	}{
+		{
+			name:     "Invalid JSON",
+			manifest: `{"routes": [`,
+		},
+		{
+			name:     "Unknown field",
+			manifest: `{"routes": [{"pattern": "/", "methods": ["GET"], "handler": "hello", "disabled": []}]}`,
+		},
+		{
+			name:     "Unknown interceptor",
+			manifest: `{"interceptors": ["xsrf"]}`,
+		},
+		{
+			name:     "Unknown handler",
+			manifest: `{"routes": [{"pattern": "/", "methods": ["GET"], "handler": "bye"}]}`,
+		},
+		{
+			name:     "Unknown config",
+			manifest: `{"routes": [{"pattern": "/", "methods": ["GET"], "handler": "hello", "configs": ["x"]}]}`,
+		},
+		{
+			name:     "No methods",
+			manifest: `{"routes": [{"pattern": "/", "handler": "hello"}]}`,
+		},
+		{
+			name:     "Invalid pattern",
+			manifest: `{"routes": [{"pattern": "foo", "methods": ["GET"], "handler": "hello"}]}`,
+		},
+		{
+			name:     "Invalid timeout",
+			manifest: `{"routes": [{"pattern": "/", "methods": ["GET"], "handler": "hello", "timeout": "soon"}]}`,
+		},
+		{
+			name:     "Disable without reason",
+			manifest: `{"routes": [{"pattern": "/", "methods": ["GET"], "handler": "hello", "disable": [{"interceptor": "header"}]}]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := safehttp.LoadServeMuxConfig(strings.NewReader(tt.manifest), nil, reg); err == nil {
+				t.Error("safehttp.LoadServeMuxConfig() got nil err, want error")
+			}
+		})
+	}
+}
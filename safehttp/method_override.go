@@ -0,0 +1,76 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package safehttp
+
+import (
+	"context"
+	"net/http"
+	"strings"
+)
+
+// MethodOverrideHeader is the header that can be used to override the method
+// of a request. See ServeMuxConfig.AllowMethodOverride.
+const MethodOverrideHeader = "X-HTTP-Method-Override"
+
+// MethodOverrideParam is the query parameter that can be used to override the
+// method of a request. See ServeMuxConfig.AllowMethodOverride.
+const MethodOverrideParam = "_method"
+
+// overridableMethods are the methods a POST request can be turned into. They
+// are all state changing, so that the request is still subject to the
+// protections against cross-site requests, like XSRF and Fetch Metadata.
+var overridableMethods = map[string]bool{
+	MethodDelete: true,
+	MethodPatch:  true,
+	MethodPut:    true,
+}
+
+// AllowMethodOverride makes the ServeMux route POST requests as if they used
+// the method specified in the X-HTTP-Method-Override header or in the
+// "_method" query parameter. This allows HTML forms, which only support GET
+// and POST, to be submitted to handlers registered for other methods, e.g.
+//
+//	<form method="post" action="/items/1?_method=DELETE">
+//
+// The body of the request isn't read to find the method, since this happens
+// before the request is routed and the limits on the body are applied.
+//
+// The method can only be overridden with DELETE, PATCH or PUT. Since they are
+// state changing methods, the request is still subject to the checks
+// interceptors run for POST requests, like XSRF and Fetch Metadata. The
+// original method is available through IncomingRequest.OriginalMethod.
+func (s *ServeMuxConfig) AllowMethodOverride() {
+	s.methodOverride = true
+}
+
+type originalMethodCtxKey struct{}
+
+// overrideMethod returns the request with the overridden method, if any.
+func overrideMethod(r *http.Request) *http.Request {
+	if r.Method != MethodPost {
+		return r
+	}
+	method := r.Header.Get(MethodOverrideHeader)
+	if method == "" {
+		method = r.URL.Query().Get(MethodOverrideParam)
+	}
+	method = strings.ToUpper(method)
+	if !overridableMethods[method] {
+		return r
+	}
+	r2 := r.WithContext(context.WithValue(r.Context(), originalMethodCtxKey{}, r.Method))
+	r2.Method = method
+	return r2
+}
@@ -0,0 +1,159 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package safehttp_test
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-safeweb/safehttp"
+	"github.com/google/go-safeweb/safehttp/plugins/fetchmetadata"
+	"github.com/google/safehtml"
+)
+
+func TestMethodOverride(t *testing.T) {
+	tests := []struct {
+		name       string
+		disabled   bool
+		method     string
+		header     string
+		query      string
+		body       string
+		fetchSite  string
+		wantStatus safehttp.StatusCode
+		wantBody   string
+	}{
+		{
+			name:       "Query parameter",
+			method:     safehttp.MethodPost,
+			query:      "_method=DELETE",
+			body:       "name=foo",
+			wantStatus: safehttp.StatusOK,
+			wantBody:   "DELETE (POST) name=foo",
+		},
+		{
+			name:       "Lowercase query parameter",
+			method:     safehttp.MethodPost,
+			query:      "_method=patch",
+			body:       "name=foo",
+			wantStatus: safehttp.StatusOK,
+			wantBody:   "PATCH (POST) name=foo",
+		},
+		{
+			name:       "Header",
+			method:     safehttp.MethodPost,
+			header:     "PUT",
+			body:       "name=foo",
+			wantStatus: safehttp.StatusOK,
+			wantBody:   "PUT (POST) name=foo",
+		},
+		{
+			name:       "No override",
+			method:     safehttp.MethodPost,
+			body:       "name=foo",
+			wantStatus: safehttp.StatusOK,
+			wantBody:   "POST (POST) name=foo",
+		},
+		{
+			name:       "Override with safe method ignored",
+			method:     safehttp.MethodPost,
+			query:      "_method=GET",
+			body:       "name=foo",
+			wantStatus: safehttp.StatusOK,
+			wantBody:   "POST (POST) name=foo",
+		},
+		{
+			// The body isn't read before the request limits apply.
+			name:       "Form parameter ignored",
+			method:     safehttp.MethodPost,
+			body:       "_method=DELETE&name=foo",
+			wantStatus: safehttp.StatusOK,
+			wantBody:   "POST (POST) name=foo",
+		},
+		{
+			name:       "Only POST can be overridden",
+			method:     safehttp.MethodGet,
+			header:     "DELETE",
+			wantStatus: safehttp.StatusOK,
+			wantBody:   "GET (GET)",
+		},
+		{
+			name:       "Disabled",
+			disabled:   true,
+			method:     safehttp.MethodPost,
+			query:      "_method=DELETE",
+			body:       "name=foo",
+			wantStatus: safehttp.StatusOK,
+			wantBody:   "POST (POST) name=foo",
+		},
+		{
+			name:       "Cross-site override blocked",
+			method:     safehttp.MethodPost,
+			query:      "_method=DELETE",
+			body:       "name=foo",
+			fetchSite:  "cross-site",
+			wantStatus: safehttp.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mb := safehttp.NewServeMuxConfig(nil)
+			mb.Intercept(fetchmetadata.ResourceIsolationPolicy())
+			if !tt.disabled {
+				mb.AllowMethodOverride()
+			}
+			mux := mb.Mux()
+			h := safehttp.HandlerFunc(func(w safehttp.ResponseWriter, r *safehttp.IncomingRequest) safehttp.Result {
+				body := r.Method() + " (" + r.OriginalMethod() + ")"
+				if r.OriginalMethod() == safehttp.MethodPost {
+					f, err := r.PostForm()
+					if err != nil {
+						return w.WriteError(safehttp.StatusBadRequest)
+					}
+					body += " name=" + f.String("name", "")
+				}
+				return w.Write(safehtml.HTMLEscaped(body))
+			})
+			for _, m := range []string{safehttp.MethodGet, safehttp.MethodPost, safehttp.MethodDelete, safehttp.MethodPatch, safehttp.MethodPut} {
+				mux.Handle("/", m, h)
+			}
+
+			req := httptest.NewRequest(tt.method, "http://foo.com/?"+tt.query, strings.NewReader(tt.body))
+			if tt.body != "" {
+				req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			}
+			if tt.header != "" {
+				req.Header.Set(safehttp.MethodOverrideHeader, tt.header)
+			}
+			if tt.fetchSite != "" {
+				req.Header.Set("Sec-Fetch-Site", tt.fetchSite)
+			}
+			rw := httptest.NewRecorder()
+			mux.ServeHTTP(rw, req)
+
+			if got, want := rw.Code, int(tt.wantStatus); got != want {
+				t.Errorf("rw.Code: got %v want %v", got, want)
+			}
+			if tt.wantStatus != safehttp.StatusOK {
+				return
+			}
+			if got := rw.Body.String(); got != tt.wantBody {
+				t.Errorf("response body: got %q want %q", got, tt.wantBody)
+			}
+		})
+	}
+}
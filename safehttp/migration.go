@@ -15,7 +15,12 @@
 package safehttp
 
 import (
+	"errors"
+	"io"
+	"log"
+	"mime"
 	"net/http"
+	"strings"
 )
 
 // RegisteredHandler returns the combined (all request methods) handler
@@ -39,3 +44,260 @@ func RegisteredHandler(mux *ServeMux, pattern string) http.Handler {
 	// (*registeredHandler)(nil), which is not equal to an untyped nil.
 	return nil
 }
+
+// UnsafeOption relaxes the restrictions applied to a net/http handler wrapped
+// with RegisteredHandlerFromHTTP. The options are provided by the restricted
+// package.
+type UnsafeOption func(*legacyConfig)
+
+type legacyConfig struct {
+	allowActiveContent bool
+}
+
+// unsafeAllowActiveContent is exposed as
+// restricted.UnsafeAllowActiveContent.
+func unsafeAllowActiveContent(reason string) UnsafeOption {
+	if strings.TrimSpace(reason) == "" {
+		panic("UnsafeAllowActiveContent requires a reason")
+	}
+	return func(cfg *legacyConfig) {
+		cfg.allowActiveContent = true
+	}
+}
+
+// RegisteredHandlerFromHTTP wraps a net/http handler into a Handler, so that
+// it can be registered in a ServeMux and the installed interceptors run for
+// it. This allows to migrate big codebases incrementally: legacy handlers are
+// registered first and rewritten later.
+//
+// The writes of the wrapped handler go through a restricted
+// http.ResponseWriter:
+//
+//   - claimed headers can't be changed, and the Set-Cookie headers are parsed
+//     and added as cookies;
+//   - error responses (400-599) are written by the Dispatcher, and the body
+//     written by the handler is discarded;
+//   - redirects are written as RedirectResponses;
+//   - responses with a Content-Type which browsers might render as a
+//     document, i.e. any type but a few known to be safe like text/plain,
+//     application/json or images, are replaced by a 500 Internal Server
+//     Error, unless restricted.UnsafeAllowActiveContent is passed;
+//   - other responses are written as LegacyResponses, followed by the body
+//     written by the handler. A missing Content-Type is set to
+//     application/octet-stream rather than sniffed.
+func RegisteredHandlerFromHTTP(h http.Handler, opts ...UnsafeOption) Handler {
+	var cfg legacyConfig
+	for _, o := range opts {
+		o(&cfg)
+	}
+	return HandlerFunc(func(rw ResponseWriter, req *IncomingRequest) Result {
+		f, ok := rw.(*flight)
+		if !ok {
+			log.Printf("safehttp: handler wrapped with RegisteredHandlerFromHTTP called with a %T, it must be registered in a ServeMux", rw)
+			return rw.WriteError(StatusInternalServerError)
+		}
+		lrw := &legacyResponseWriter{flight: f, cfg: cfg, header: http.Header{}}
+		h.ServeHTTP(lrw, req.req)
+		if !lrw.committed {
+			lrw.WriteHeader(int(StatusOK))
+		}
+		if lrw.limit != nil {
+			// Write errors are reported to the handler, like the ones of
+			// the underlying http.ResponseWriter.
+			if abort, _ := lrw.limit.finish(); abort {
+				panic(http.ErrAbortHandler)
+			}
+		}
+		return lrw.result
+	})
+}
+
+// LegacyResponse is the response written by a net/http handler wrapped with
+// RegisteredHandlerFromHTTP. The Dispatcher must set the Content-Type and the
+// status code; the body is written afterwards by the wrapper.
+type LegacyResponse struct {
+	// Code is the status code of the response.
+	Code StatusCode
+
+	// private, to not allow modifications
+	contentType string
+}
+
+// ContentType is the Content-Type of the response.
+func (resp LegacyResponse) ContentType() string {
+	return resp.contentType
+}
+
+type legacyResponseWriter struct {
+	flight *flight
+	cfg    legacyConfig
+	result Result
+
+	// As for the fileServerResponseWriter, the headers are copied over to the
+	// flight on a call to WriteHeader.
+	header http.Header
+
+	// Once WriteHeader is called, any subsequent calls to it are no-ops.
+	committed bool
+
+	// discard is set if the body written by the handler isn't part of the
+	// response, e.g. for errors and redirects.
+	discard bool
+
+	// body is where the body written by the handler goes, through limit if
+	// the response size is limited.
+	body  io.Writer
+	limit *limitWriter
+}
+
+func (lrw *legacyResponseWriter) Header() http.Header {
+	return lrw.header
+}
+
+func (lrw *legacyResponseWriter) Write(b []byte) (int, error) {
+	if !lrw.committed {
+		lrw.WriteHeader(int(StatusOK))
+	}
+	if lrw.discard {
+		return 0, errors.New("discarded")
+	}
+	return lrw.body.Write(b)
+}
+
+func (lrw *legacyResponseWriter) WriteHeader(statusCode int) {
+	if lrw.committed {
+		return
+	}
+	lrw.committed = true
+
+	headers := lrw.flight.Header()
+	for k, v := range lrw.header {
+		if len(v) == 0 {
+			continue
+		}
+		switch k {
+		case "Content-Type", "Content-Length":
+			// The Content-Type is set by the Dispatcher, the Content-Length
+			// only applies to the body of the handler.
+			continue
+		case "Set-Cookie":
+			for _, c := range (&http.Response{Header: http.Header{k: v}}).Cookies() {
+				lrw.flight.AddCookie(&Cookie{wrapped: c})
+			}
+			continue
+		}
+		if headers.IsClaimed(k) {
+			continue
+		}
+		headers.Del(k)
+		for _, vv := range v {
+			headers.Add(k, vv)
+		}
+	}
+
+	code := StatusCode(statusCode)
+	ct := lrw.header.Get("Content-Type")
+	if ct == "" {
+		ct = "application/octet-stream"
+	}
+	switch {
+	case statusCode >= 400:
+		lrw.discard = true
+		lrw.result = lrw.flight.WriteError(code)
+	case statusCode >= 300 && lrw.header.Get("Location") != "":
+		lrw.discard = true
+		headers.Del("Location")
+		lrw.result = lrw.flight.Write(RedirectResponse{
+			Code:     code,
+			Location: lrw.header.Get("Location"),
+			Request:  lrw.flight.req,
+		})
+	case !isPassiveContent(ct) && !lrw.cfg.allowActiveContent:
+		lrw.discard = true
+		lrw.result = lrw.flight.WriteError(StatusInternalServerError)
+	default:
+		if cl := lrw.header.Get("Content-Length"); cl != "" && !headers.IsClaimed("Content-Length") {
+			headers.Set("Content-Length", cl)
+		}
+		lrw.body, lrw.limit = lrw.flight.writeLegacy(LegacyResponse{Code: code, contentType: ct})
+		lrw.discard = lrw.body == nil
+	}
+}
+
+// writeLegacy writes the LegacyResponse like Write and returns the writer of
+// its body, which enforces the response size limit, if any, together with the
+// limitWriter to finish once the body is written. It returns a nil writer if
+// the response was replaced, e.g. by an error in the Commit phase.
+func (f *flight) writeLegacy(resp LegacyResponse) (io.Writer, *limitWriter) {
+	if f.written {
+		panic("ResponseWriter was already written to")
+	}
+	if f.timedOut() {
+		f.WriteError(StatusGatewayTimeout)
+		return nil, nil
+	}
+	f.written = true
+	f.commitPhase(resp)
+	if f.dispatched {
+		return nil, nil
+	}
+	f.written = true
+	f.trace.written(f.header, resp)
+
+	f.dispatched = true
+	var rw http.ResponseWriter = f.rw
+	lw := newLimitWriter(f, f.rw, resp)
+	if lw != nil {
+		rw = lw
+	}
+	if err := f.cfg.Dispatcher.Write(rw, resp); err != nil {
+		panic(err)
+	}
+	return rw, lw
+}
+
+// passiveContentTypes are the media types that browsers never render as a
+// document which can run scripts. Images, audio, video and fonts are passive
+// as well, except for SVG images, and so are the JSON-based types.
+var passiveContentTypes = map[string]bool{
+	"application/cbor":                true,
+	"application/grpc-web":            true,
+	"application/grpc-web+proto":      true,
+	"application/grpc-web-text":       true,
+	"application/grpc-web-text+proto": true,
+	"application/gzip":                true,
+	"application/json":                true,
+	"application/msgpack":             true,
+	"application/octet-stream":        true,
+	"application/x-msgpack":           true,
+	"application/x-ndjson":            true,
+	"application/x-protobuf":          true,
+	"application/zip":                 true,
+	"text/css":                        true,
+	"text/csv":                        true,
+	"text/event-stream":               true,
+	"text/plain":                      true,
+}
+
+// isPassiveContent reports whether the given Content-Type is known to be safe
+// to serve without checking the content, since browsers don't render it as a
+// document. It's an allowlist: unknown and missing types, which browsers might
+// sniff or render as XML, are not passive.
+func isPassiveContent(ct string) bool {
+	mt, _, err := mime.ParseMediaType(ct)
+	if err != nil {
+		return false
+	}
+	if passiveContentTypes[mt] || strings.HasPrefix(mt, "application/") && strings.HasSuffix(mt, "+json") {
+		return true
+	}
+	if mt == "image/svg+xml" {
+		return false
+	}
+	for _, prefix := range []string{"image/", "audio/", "video/", "font/"} {
+		if strings.HasPrefix(mt, prefix) {
+			return true
+		}
+	}
+	return false
+}
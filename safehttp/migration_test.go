@@ -21,7 +21,11 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-safeweb/safehttp"
+	"github.com/google/go-safeweb/safehttp/plugins/staticheaders"
+	"github.com/google/go-safeweb/safehttp/restricted"
+	"github.com/google/go-safeweb/safehttp/safehttptest"
 	"github.com/google/safehtml"
 )
 
@@ -81,7 +85,7 @@ func TestRegisteredHandler(t *testing.T) {
 		},
 		{
 			name:       "Invalid Method",
-			req:        httptest.NewRequest(safehttp.MethodHead, "http://foo.com/abc", nil),
+			req:        httptest.NewRequest(safehttp.MethodPut, "http://foo.com/abc", nil),
 			wantStatus: safehttp.StatusMethodNotAllowed,
 			wantBody:   "Method Not Allowed\n",
 		},
@@ -119,3 +123,200 @@ func TestRegisteredHandler_StrictPatterns(t *testing.T) {
 		t.Errorf(`RegisteredHandler(_, "/foo/subpath") got %v, want nil`, got)
 	}
 }
+
+func TestRegisteredHandlerFromHTTP(t *testing.T) {
+	tests := []struct {
+		name        string
+		h           http.HandlerFunc
+		opts        []safehttp.UnsafeOption
+		wantStatus  safehttp.StatusCode
+		wantHeaders map[string][]string
+		wantBody    string
+	}{
+		{
+			name: "Text",
+			h: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+				w.Header().Set("X-Content-Type-Options", "sniff")
+				w.Header().Set("Foo", "bar")
+				w.WriteHeader(http.StatusCreated)
+				fmt.Fprint(w, "created")
+			},
+			wantStatus: safehttp.StatusCreated,
+			wantHeaders: map[string][]string{
+				"Content-Type":           {"text/plain; charset=utf-8"},
+				"Foo":                    {"bar"},
+				"X-Content-Type-Options": {"nosniff"},
+				"X-Xss-Protection":       {"0"},
+			},
+			wantBody: "created",
+		},
+		{
+			name: "No Content-Type",
+			h: func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, "<script>alert(1)</script>")
+			},
+			wantStatus: safehttp.StatusOK,
+			wantHeaders: map[string][]string{
+				"Content-Type":           {"application/octet-stream"},
+				"X-Content-Type-Options": {"nosniff"},
+				"X-Xss-Protection":       {"0"},
+			},
+			wantBody: "<script>alert(1)</script>",
+		},
+		{
+			name: "HTML",
+			h: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "text/html; charset=utf-8")
+				fmt.Fprint(w, "<script>alert(1)</script>")
+			},
+			wantStatus: safehttp.StatusInternalServerError,
+			wantHeaders: map[string][]string{
+				"Content-Type":           {"text/plain; charset=utf-8"},
+				"X-Content-Type-Options": {"nosniff"},
+				"X-Xss-Protection":       {"0"},
+			},
+			wantBody: "Internal Server Error\n",
+		},
+		{
+			name: "JavaScript",
+			h: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "text/javascript")
+				fmt.Fprint(w, "alert(1)")
+			},
+			wantStatus: safehttp.StatusInternalServerError,
+			wantHeaders: map[string][]string{
+				"Content-Type":           {"text/plain; charset=utf-8"},
+				"X-Content-Type-Options": {"nosniff"},
+				"X-Xss-Protection":       {"0"},
+			},
+			wantBody: "Internal Server Error\n",
+		},
+		{
+			name: "Unknown Content-Type",
+			h: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "multipart/x-mixed-replace; boundary=x")
+				fmt.Fprint(w, "--x\r\nContent-Type: text/html\r\n\r\n<script>alert(1)</script>")
+			},
+			wantStatus: safehttp.StatusInternalServerError,
+			wantHeaders: map[string][]string{
+				"Content-Type":           {"text/plain; charset=utf-8"},
+				"X-Content-Type-Options": {"nosniff"},
+				"X-Xss-Protection":       {"0"},
+			},
+			wantBody: "Internal Server Error\n",
+		},
+		{
+			name: "Image",
+			h: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "image/png")
+				fmt.Fprint(w, "png")
+			},
+			wantStatus: safehttp.StatusOK,
+			wantHeaders: map[string][]string{
+				"Content-Type":           {"image/png"},
+				"X-Content-Type-Options": {"nosniff"},
+				"X-Xss-Protection":       {"0"},
+			},
+			wantBody: "png",
+		},
+		{
+			name: "HTML allowed",
+			h: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "text/html; charset=utf-8")
+				fmt.Fprint(w, "<h1>Legacy</h1>")
+			},
+			opts:       []safehttp.UnsafeOption{restricted.UnsafeAllowActiveContent("audited legacy page")},
+			wantStatus: safehttp.StatusOK,
+			wantHeaders: map[string][]string{
+				"Content-Type":           {"text/html; charset=utf-8"},
+				"X-Content-Type-Options": {"nosniff"},
+				"X-Xss-Protection":       {"0"},
+			},
+			wantBody: "<h1>Legacy</h1>",
+		},
+		{
+			name: "Error",
+			h: func(w http.ResponseWriter, r *http.Request) {
+				http.Error(w, "no such user: <b>", http.StatusNotFound)
+			},
+			wantStatus: safehttp.StatusNotFound,
+			wantHeaders: map[string][]string{
+				"Content-Type":           {"text/plain; charset=utf-8"},
+				"X-Content-Type-Options": {"nosniff"},
+				"X-Xss-Protection":       {"0"},
+			},
+			wantBody: "Not Found\n",
+		},
+		{
+			name: "Redirect",
+			h: func(w http.ResponseWriter, r *http.Request) {
+				http.Redirect(w, r, "/login", http.StatusFound)
+			},
+			wantStatus: safehttp.StatusFound,
+			wantHeaders: map[string][]string{
+				"Content-Type":           {"text/html; charset=utf-8"},
+				"Location":               {"/login"},
+				"X-Content-Type-Options": {"nosniff"},
+				"X-Xss-Protection":       {"0"},
+			},
+			wantBody: "<a href=\"/login\">Found</a>.\n\n",
+		},
+		{
+			name: "Cookie",
+			h: func(w http.ResponseWriter, r *http.Request) {
+				http.SetCookie(w, &http.Cookie{Name: "session", Value: "x", Secure: true, HttpOnly: true})
+				w.WriteHeader(http.StatusNoContent)
+			},
+			wantStatus: safehttp.StatusNoContent,
+			wantHeaders: map[string][]string{
+				"Content-Type":           {"application/octet-stream"},
+				"Set-Cookie":             {"session=x; HttpOnly; Secure"},
+				"X-Content-Type-Options": {"nosniff"},
+				"X-Xss-Protection":       {"0"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mb := safehttp.NewServeMuxConfig(nil)
+			mb.Intercept(staticheaders.Interceptor{})
+			mux := mb.Mux()
+			mux.Handle("/", safehttp.MethodGet, safehttp.RegisteredHandlerFromHTTP(tt.h, tt.opts...))
+
+			rw := httptest.NewRecorder()
+			mux.ServeHTTP(rw, httptest.NewRequest(safehttp.MethodGet, "http://foo.com/", nil))
+
+			if got, want := rw.Code, int(tt.wantStatus); got != want {
+				t.Errorf("rw.Code: got %v want %v", got, want)
+			}
+			if diff := cmp.Diff(tt.wantHeaders, map[string][]string(rw.Header())); diff != "" {
+				t.Errorf("rw.Header() mismatch (-want +got):\n%s", diff)
+			}
+			if got := rw.Body.String(); got != tt.wantBody {
+				t.Errorf("response body: got %q want %q", got, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestRegisteredHandlerFromHTTPOutsideMux(t *testing.T) {
+	h := safehttp.RegisteredHandlerFromHTTP(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "legacy")
+	}))
+	fakeRW, rr := safehttptest.NewFakeResponseWriter()
+	h.ServeHTTP(fakeRW, safehttptest.NewRequest(safehttp.MethodGet, "http://foo.com/", nil))
+	if got, want := rr.Code, int(safehttp.StatusInternalServerError); got != want {
+		t.Errorf("rr.Code: got %v want %v", got, want)
+	}
+}
+
+func TestUnsafeAllowActiveContentNoReason(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Error(`restricted.UnsafeAllowActiveContent("") expected panic`)
+		}
+	}()
+	restricted.UnsafeAllowActiveContent(" ")
+}
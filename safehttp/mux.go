@@ -17,7 +17,10 @@ package safehttp
 import (
 	"fmt"
 	"log"
+	"net"
 	"net/http"
+	"sort"
+	"strings"
 )
 
 // The HTTP request methods defined by RFC.
@@ -66,14 +69,53 @@ const (
 // .. elements or repeated slashes to an equivalent, cleaner URL.
 //
 // Multiple handlers can be registered for a single pattern, as long as they
-// handle different HTTP methods.
+// handle different HTTP methods. HEAD requests are served by the GET handler
+// if no HEAD handler was registered, see ServeMuxConfig.DisableAutoHead.
+//
+// Path segments of patterns may be parameters, like "{id}" in
+// "/users/{id}/edit", which match any non-empty segment. Parameters can be
+// constrained with a regular expression, which has to match the whole
+// (unescaped) segment, like "{id:[0-9]+}": requests with malformed values
+// don't match the pattern and are rejected with 404 Not Found, unless another
+// pattern matches them. The values of the parameters are available to the
+// handler through IncomingRequest.PathParam.
+// If several patterns match a request, the most specific one is used; patterns
+// for which no precedence can be established conflict and can't be registered
+// together. See ComparePatterns.
+//
+// Patterns may end with a "/..." wildcard, like "/static/...". A wildcard
+// pattern matches the same paths as the rooted subtree "/static/" and the
+// remainder of the path, after the subtree root, is available to the handler
+// through IncomingRequest.PathWildcard.
 type ServeMux struct {
 	mux      *http.ServeMux
 	handlers map[string]*registeredHandler
+	// paramHandlers are the handlers whose patterns have path parameters.
+	// They aren't registered in mux.
+	paramHandlers []*registeredHandler
 
 	dispatcher       Dispatcher
 	interceptors     []Interceptor
 	methodNotAllowed handlerConfig
+	// notFound is nil if no custom handler was set with HandleNotFound.
+	notFound *handlerConfig
+	// autoOptions is nil unless ServeMuxConfig.AutoOptions was called. Its
+	// handler is set for each request.
+	autoOptions *handlerConfig
+
+	pathPolicy        PathPolicy
+	methodOverride    bool
+	disableAutoHead   bool
+	traceInterceptors bool
+	// clientIP is nil unless ServeMuxConfig.ResolveClientIP was called.
+	clientIP *ClientIPConfig
+	// compression is nil unless ServeMuxConfig.Compress was called.
+	compression *CompressionConfig
+	// errorPages is nil unless ServeMuxConfig.RenderErrors was called.
+	errorPages *ErrorPages
+	// responseLimit is nil unless ServeMuxConfig.LimitResponseSize was
+	// called.
+	responseLimit *responseLimit
 }
 
 // ServeHTTP dispatches the request to the handler whose method matches the
@@ -97,9 +139,78 @@ type ServeMux struct {
 //
 // Interceptors should NOT rely on the order they're run.
 func (m *ServeMux) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	r, ok := m.canonicalize(w, r)
+	if !ok {
+		return
+	}
+	if m.methodOverride {
+		r = overrideMethod(r)
+	}
+	if m.clientIP != nil {
+		r = withClientIPConfig(r, m.clientIP)
+	}
+	if m.compression != nil {
+		r = withCompressionConfig(r, m.compression)
+	}
+	if m.errorPages != nil {
+		r = withErrorPages(r, m.errorPages)
+	}
+	if m.responseLimit != nil {
+		r = withResponseLimit(r, m.responseLimit)
+	}
+	if rh, match, ok := m.matchParams(r); ok {
+		rh.serve(w, r, match)
+		return
+	}
+	if m.notFound != nil {
+		if _, pattern := m.mux.Handler(r); pattern == "" {
+			processRequest(*m.notFound, w, r, routeMatch{})
+			return
+		}
+	}
 	m.mux.ServeHTTP(w, r)
 }
 
+// serveError writes the error response with the Dispatcher, without running
+// the interceptors. It's used to reject requests before they're routed, e.g.
+// when the server is overloaded.
+func (m *ServeMux) serveError(w http.ResponseWriter, r *http.Request, code StatusCode) {
+	cfg := handlerConfig{
+		Dispatcher: m.dispatcher,
+		Handler: HandlerFunc(func(w ResponseWriter, _ *IncomingRequest) Result {
+			return w.WriteError(code)
+		}),
+	}
+	processRequest(cfg, w, r, routeMatch{})
+}
+
+// trailingSlashAlternative returns the path the request should be served
+// with if its path was not registered, but the same path with the trailing
+// slash added or removed was.
+func (m *ServeMux) trailingSlashAlternative(r *http.Request) (string, bool) {
+	p := r.URL.Path
+	if p == "/" || m.registered(r.Host, p) {
+		return "", false
+	}
+	alt := p + "/"
+	if strings.HasSuffix(p, "/") {
+		alt = strings.TrimSuffix(p, "/")
+	}
+	if !m.registered(r.Host, alt) {
+		return "", false
+	}
+	return alt, true
+}
+
+// registered reports whether a handler was registered for exactly the given
+// path, either for all hosts or for the given one.
+func (m *ServeMux) registered(host, path string) bool {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	return m.handlers[path] != nil || m.handlers[host+path] != nil
+}
+
 // Handle registers a handler for the given pattern and method. If a handler is
 // registered twice for the same pattern and method, Build will panic.
 //
@@ -108,29 +219,185 @@ func (m *ServeMux) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 // corresponding Interceptor was not installed will produce no effect. If
 // multiple configurations are passed for the same Interceptor, Mux will panic.
 func (m *ServeMux) Handle(pattern string, method string, h Handler, cfgs ...InterceptorConfig) {
-	if m.handlers[pattern] == nil {
-		m.handlers[pattern] = &registeredHandler{
-			pattern:          pattern,
-			methodNotAllowed: m.methodNotAllowed,
-			methods:          make(map[string]handlerConfig),
+	m.handle(pattern, method, m.handlerConfig(h, cfgs))
+}
+
+// HandleHost registers a handler for the given pattern and method, restricted
+// to requests for the given host. It is equivalent to calling Handle with the
+// pattern prefixed by the host name. The host must not contain a port.
+//
+// To install interceptors or configurations for all the handlers of a host,
+// use ServeMuxConfig.Host.
+func (m *ServeMux) HandleHost(host, pattern string, method string, h Handler, cfgs ...InterceptorConfig) {
+	checkHost(host)
+	if !strings.HasPrefix(pattern, "/") {
+		panic(fmt.Sprintf("pattern %q doesn't begin with a slash", pattern))
+	}
+	m.Handle(host+pattern, method, h, cfgs...)
+}
+
+func checkHost(host string) {
+	if host == "" || strings.ContainsAny(host, "/:") {
+		panic(fmt.Sprintf("invalid host %q", host))
+	}
+}
+
+// handle registers the handler configuration for the given pattern, which
+// may end with a wildcard, and method.
+func (m *ServeMux) handle(pattern string, method string, cfg handlerConfig) {
+	wildcard := strings.HasSuffix(pattern, "/...")
+	if wildcard {
+		pattern = strings.TrimSuffix(pattern, "...")
+	}
+	rh := m.registeredHandler(pattern)
+	if wildcard {
+		rh.wildcard = true
+	}
+	rh.handleMethod(method, cfg)
+}
+
+// HandlePrefix registers a handler for all requests whose path starts with the
+// given prefix, regardless of their method. The prefix must end with a slash
+// and may optionally begin with a host name, like patterns passed to Handle.
+//
+// If several prefixes match a request, the longest one is used. Handlers
+// registered with Handle always take precedence over prefix ones: a request
+// for "/static/app.js" is served by the handler registered with
+// Handle("/static/app.js", ...), if any, even if the "/static/" prefix was
+// also registered. If Handle and HandlePrefix are both called with the same
+// pattern, the prefix handler only serves the methods that weren't registered
+// with Handle.
+//
+// Interceptors run for prefix-matched requests as they do for any other
+// request. If a prefix is registered twice, HandlePrefix will panic.
+func (m *ServeMux) HandlePrefix(prefix string, h Handler, cfgs ...InterceptorConfig) {
+	if !strings.HasSuffix(prefix, "/") {
+		panic(fmt.Sprintf("prefix %q doesn't end with a slash", prefix))
+	}
+	rh := m.registeredHandler(prefix)
+	if rh.prefix != nil {
+		panic(fmt.Sprintf("double registration of prefix %q", prefix))
+	}
+	cfg := m.handlerConfig(h, cfgs)
+	rh.prefix = &cfg
+}
+
+// registeredHandler returns the registeredHandler for the given pattern,
+// registering it with the underlying http.ServeMux if needed.
+//
+// It panics if the pattern is invalid or conflicts with an already registered
+// one (see ComparePatterns).
+func (m *ServeMux) registeredHandler(pattern string) *registeredHandler {
+	if rh := m.handlers[pattern]; rh != nil {
+		return rh
+	}
+	route, err := parsePattern(pattern)
+	if err != nil {
+		panic(err.Error())
+	}
+	for _, other := range m.handlers {
+		if !route.hasParams() && !other.route.hasParams() {
+			// Patterns without parameters never conflict.
+			continue
+		}
+		if _, err := comparePatternsErr(route, other.route); err != nil {
+			panic(err.Error())
+		}
+	}
+	rh := &registeredHandler{
+		pattern:          pattern,
+		route:            route,
+		methodNotAllowed: m.methodNotAllowed,
+		autoHead:         !m.disableAutoHead,
+		autoOptions:      m.autoOptions,
+		methods:          make(map[string]handlerConfig),
+	}
+	m.handlers[pattern] = rh
+	if route.hasParams() {
+		m.paramHandlers = append(m.paramHandlers, rh)
+	} else {
+		m.mux.Handle(pattern, rh)
+	}
+	return rh
+}
+
+// matchParams returns the handler whose pattern has path parameters and
+// takes precedence for the request, if any. Patterns without parameters are
+// matched by the underlying http.ServeMux.
+func (m *ServeMux) matchParams(r *http.Request) (*registeredHandler, routeMatch, bool) {
+	// Non-canonical paths are redirected by the http.ServeMux.
+	if len(m.paramHandlers) == 0 || cleanPath(r.URL.Path) != r.URL.Path {
+		return nil, routeMatch{}, false
+	}
+	host := r.Host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	path := r.URL.EscapedPath()
+
+	var best *registeredHandler
+	var bestMatch routeMatch
+	for _, rh := range m.paramHandlers {
+		if rh.route.host != "" && rh.route.host != host {
+			continue
 		}
-		m.mux.Handle(pattern, m.handlers[pattern])
-	}
-	m.handlers[pattern].handleMethod(method,
-		handlerConfig{
-			Dispatcher:   m.dispatcher,
-			Handler:      h,
-			Interceptors: configureInterceptors(m.interceptors, cfgs),
-		})
+		params, rest, ok := rh.route.match(path)
+		if !ok {
+			continue
+		}
+		if best == nil || comparePatterns(rh.route, best.route) == moreSpecific {
+			best = rh
+			bestMatch = routeMatch{params: params}
+			if rh.wildcard {
+				bestMatch.wildcard = rest
+			}
+		}
+	}
+	if best == nil {
+		return nil, routeMatch{}, false
+	}
+
+	// A pattern without parameters might take precedence.
+	if _, pattern := m.mux.Handler(r); pattern != "" {
+		if rh := m.handlers[pattern]; rh != nil && comparePatterns(rh.route, best.route) == moreSpecific {
+			// The http.ServeMux might have returned a redirect.
+			if _, _, ok := rh.route.match(path); ok {
+				return nil, routeMatch{}, false
+			}
+		}
+	}
+	return best, bestMatch, true
+}
+
+func (m *ServeMux) handlerConfig(h Handler, cfgs []InterceptorConfig) handlerConfig {
+	return newHandlerConfig(m.dispatcher, h, m.interceptors, cfgs, m.traceInterceptors)
 }
 
 // ServeMuxConfig is a builder for ServeMux.
 type ServeMuxConfig struct {
 	dispatcher   Dispatcher
 	interceptors []Interceptor
+	// phases are the phases of the interceptors, in the same order.
+	phases []Phase
 
 	methodNotAllowed     Handler
 	methodNotAllowedCfgs []InterceptorConfig
+
+	notFound     Handler
+	notFoundCfgs []InterceptorConfig
+
+	groups []*Group
+	mounts []mount
+
+	pathPolicy        PathPolicy
+	methodOverride    bool
+	disableAutoHead   bool
+	autoOptions       bool
+	traceInterceptors bool
+	clientIP          *ClientIPConfig
+	compression       *CompressionConfig
+	errorPages        *ErrorPages
+	responseLimit     *responseLimit
 }
 
 // NewServeMuxConfig crates a ServeMuxConfig with the provided Dispatcher. If
@@ -146,12 +413,49 @@ func NewServeMuxConfig(disp Dispatcher) *ServeMuxConfig {
 }
 
 // HandleMethodNotAllowed registers a handler that runs when a given method is
-// not allowed for a registered path.
+// not allowed for a registered path. By default, a 405 Method Not Allowed
+// error is written.
+//
+// The Allow header, listing the methods registered for the path, is set on the
+// response before the handler runs.
 func (s *ServeMuxConfig) HandleMethodNotAllowed(h Handler, cfgs ...InterceptorConfig) {
 	s.methodNotAllowed = h
 	s.methodNotAllowedCfgs = cfgs
 }
 
+// HandleNotFound registers a handler that runs when no handler is registered
+// for the path of a request. Unlike the default 404 Not Found response, the
+// handler runs after the installed interceptors and writes its response
+// through the Dispatcher, so it can be used to render custom error pages.
+func (s *ServeMuxConfig) HandleNotFound(h Handler, cfgs ...InterceptorConfig) {
+	s.notFound = h
+	s.notFoundCfgs = cfgs
+}
+
+// RedirectTrailingSlash makes the ServeMux redirect requests for unregistered
+// paths with 301 Moved Permanently if the same path, with the trailing slash
+// added or removed, was registered. The query string is preserved.
+//
+// For example, if only "/foo" was registered, requests to "/foo/" will be
+// redirected to "/foo" and vice versa. If both "/foo" and "/foo/" are
+// registered, no redirect happens.
+//
+// It is equivalent to setting PathPolicy.TrailingSlash to PathRedirect.
+func (s *ServeMuxConfig) RedirectTrailingSlash() {
+	s.pathPolicy.TrailingSlash = PathRedirect
+}
+
+// TraceInterceptors makes the ServeMux record, for every request, which
+// interceptors ran, which request headers they read and which one wrote the
+// response. The trace is summarized in the X-Safeweb-Trace response header and
+// can be retrieved by handlers with InterceptorTrace.
+//
+// Tracing is only enabled in local development mode (see UseLocalDev), and is
+// a no-op otherwise.
+func (s *ServeMuxConfig) TraceInterceptors() {
+	s.traceInterceptors = true
+}
+
 var defaultMethotNotAllowed = HandlerFunc(func(w ResponseWriter, req *IncomingRequest) Result {
 	return w.WriteError(StatusMethodNotAllowed)
 })
@@ -162,9 +466,13 @@ var defaultMethotNotAllowed = HandlerFunc(func(w ResponseWriter, req *IncomingRe
 // order they've been installed.
 //
 // Calling Intercept multiple times is valid. Interceptors that are added last
-// will run last.
+// will run last. They run after the interceptors installed with
+// InterceptInPhase.
 func (s *ServeMuxConfig) Intercept(is ...Interceptor) {
 	s.interceptors = append(s.interceptors, is...)
+	for range is {
+		s.phases = append(s.phases, PhaseNone)
+	}
 }
 
 // Mux returns the ServeMux with a copy of the current configuration.
@@ -174,25 +482,47 @@ func (s *ServeMuxConfig) Mux() *ServeMux {
 	if isLocalDev {
 		log.Println("Warning: creating safehttp.Mux in dev mode. This configuration is not valid for production use")
 	}
+	trace := s.traceInterceptors && isLocalDev
 	devMu.Unlock()
 
 	if s.dispatcher == nil {
 		panic("Use NewServeMuxConfig instead of creating ServeMuxConfig using a composite literal.")
 	}
 
-	methodNotAllowed := handlerConfig{
-		Dispatcher:   s.dispatcher,
-		Handler:      s.methodNotAllowed,
-		Interceptors: configureInterceptors(s.interceptors, s.methodNotAllowedCfgs),
+	interceptors := s.orderedInterceptors()
+	methodNotAllowed := newHandlerConfig(s.dispatcher, s.methodNotAllowed, interceptors, s.methodNotAllowedCfgs, trace)
+
+	var notFound *handlerConfig
+	if s.notFound != nil {
+		cfg := newHandlerConfig(s.dispatcher, s.notFound, interceptors, s.notFoundCfgs, trace)
+		notFound = &cfg
+	}
+
+	var autoOptions *handlerConfig
+	if s.autoOptions {
+		cfg := newHandlerConfig(s.dispatcher, nil, interceptors, nil, trace)
+		autoOptions = &cfg
 	}
 
 	m := &ServeMux{
 		mux:              http.NewServeMux(),
 		handlers:         make(map[string]*registeredHandler),
 		dispatcher:       s.dispatcher,
-		interceptors:     s.interceptors,
+		interceptors:     interceptors,
 		methodNotAllowed: methodNotAllowed,
+		notFound:         notFound,
+		autoOptions:      autoOptions,
+
+		pathPolicy:        s.pathPolicy,
+		methodOverride:    s.methodOverride,
+		disableAutoHead:   s.disableAutoHead,
+		traceInterceptors: trace,
+		clientIP:          s.clientIP,
+		compression:       s.compression,
+		errorPages:        s.errorPages,
+		responseLimit:     s.responseLimit,
 	}
+	s.registerRoutes(m, "", nil)
 	return m
 }
 
@@ -200,11 +530,30 @@ func (s *ServeMuxConfig) Mux() *ServeMux {
 // This can be used to create several instances of Mux that share the same set of
 // plugins.
 func (s *ServeMuxConfig) Clone() *ServeMuxConfig {
+	var groups []*Group
+	for _, g := range s.groups {
+		groups = append(groups, g.clone())
+	}
 	return &ServeMuxConfig{
 		dispatcher:           s.dispatcher,
 		interceptors:         append([]Interceptor(nil), s.interceptors...),
+		phases:               append([]Phase(nil), s.phases...),
 		methodNotAllowed:     s.methodNotAllowed,
 		methodNotAllowedCfgs: append([]InterceptorConfig(nil), s.methodNotAllowedCfgs...),
+		notFound:             s.notFound,
+		notFoundCfgs:         append([]InterceptorConfig(nil), s.notFoundCfgs...),
+		groups:               groups,
+		mounts:               append([]mount(nil), s.mounts...),
+
+		pathPolicy:        s.pathPolicy,
+		methodOverride:    s.methodOverride,
+		disableAutoHead:   s.disableAutoHead,
+		autoOptions:       s.autoOptions,
+		traceInterceptors: s.traceInterceptors,
+		clientIP:          s.clientIP,
+		compression:       s.compression,
+		errorPages:        s.errorPages,
+		responseLimit:     s.responseLimit,
 	}
 }
 
@@ -212,14 +561,73 @@ type registeredHandler struct {
 	pattern          string
 	methods          map[string]handlerConfig
 	methodNotAllowed handlerConfig
+	// prefix is the handler registered with HandlePrefix, if any. It serves
+	// all methods not in methods.
+	prefix *handlerConfig
+	// wildcard is set if the pattern was registered with a trailing "...".
+	wildcard bool
+	route    *routePattern
+	// autoHead is set if HEAD requests are served by the GET handler, unless
+	// a HEAD handler was registered.
+	autoHead bool
+	// autoOptions serves OPTIONS requests if no OPTIONS handler was
+	// registered. It's nil unless ServeMuxConfig.AutoOptions was called.
+	autoOptions *handlerConfig
 }
 
 func (rh *registeredHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var match routeMatch
+	if rh.wildcard {
+		// The pattern may begin with a host name.
+		match.wildcard = strings.TrimPrefix(r.URL.Path, rh.pattern[strings.Index(rh.pattern, "/"):])
+	}
+	rh.serve(w, r, match)
+}
+
+func (rh *registeredHandler) serve(w http.ResponseWriter, r *http.Request, match routeMatch) {
 	cfg, ok := rh.methods[r.Method]
+	if !ok && r.Method == MethodHead && rh.autoHead {
+		if cfg, ok := rh.methods[MethodGet]; ok {
+			hw := &headResponseWriter{ResponseWriter: w}
+			processRequest(cfg, hw, r, match)
+			hw.finish()
+			return
+		}
+	}
+	if !ok && r.Method == MethodOptions && rh.autoOptions != nil && rh.prefix == nil {
+		cfg = *rh.autoOptions
+		cfg.Handler = optionsHandler(rh.allow())
+		ok = true
+	}
 	if !ok {
-		cfg = rh.methodNotAllowed
+		if rh.prefix != nil {
+			cfg = *rh.prefix
+		} else {
+			cfg = rh.methodNotAllowed
+			w.Header().Set("Allow", rh.allow())
+		}
+	}
+	processRequest(cfg, w, r, match)
+}
+
+// allow returns the value of the Allow header for the pattern, i.e. the
+// sorted list of the registered methods, including HEAD and OPTIONS if
+// they're served automatically.
+func (rh *registeredHandler) allow() string {
+	methods := make([]string, 0, len(rh.methods)+2)
+	for m := range rh.methods {
+		methods = append(methods, m)
+	}
+	if _, ok := rh.methods[MethodHead]; !ok && rh.autoHead {
+		if _, ok := rh.methods[MethodGet]; ok {
+			methods = append(methods, MethodHead)
+		}
+	}
+	if _, ok := rh.methods[MethodOptions]; !ok && rh.autoOptions != nil {
+		methods = append(methods, MethodOptions)
 	}
-	processRequest(cfg, w, r)
+	sort.Strings(methods)
+	return strings.Join(methods, ", ")
 }
 
 func (rh *registeredHandler) handleMethod(method string, cfg handlerConfig) {
@@ -229,9 +637,55 @@ func (rh *registeredHandler) handleMethod(method string, cfg handlerConfig) {
 	rh.methods[method] = cfg
 }
 
+// newHandlerConfig creates the configuration of a handler. The
+// configurations of the route itself, like WithTimeout or disableInterceptor,
+// are extracted from cfgs, the other ones are passed to the matching
+// interceptors. The cache configurations are enforced by a cacheInterceptor
+// installed before the given interceptors.
+func newHandlerConfig(disp Dispatcher, h Handler, interceptors []Interceptor, cfgs []InterceptorConfig, trace bool) handlerConfig {
+	hc := handlerConfig{
+		Dispatcher: disp,
+		Handler:    h,
+		Trace:      trace,
+	}
+	var icfgs []InterceptorConfig
+	var disabled []disableConfig
+	cached, schema := false, false
+	for _, c := range cfgs {
+		switch c := c.(type) {
+		case timeoutConfig:
+			hc.Timeout = c.d
+		case disableConfig:
+			disabled = append(disabled, c)
+		case disableCompressionConfig:
+			hc.NoCompression = true
+		case responseLimit:
+			hc.ResponseLimit = &c
+		case cacheConfig:
+			cached = true
+			icfgs = append(icfgs, c)
+		case QuerySchema:
+			schema = true
+			icfgs = append(icfgs, c)
+		default:
+			icfgs = append(icfgs, c)
+		}
+	}
+	if cached {
+		interceptors = append([]Interceptor{cacheInterceptor{}}, interceptors...)
+	}
+	if schema {
+		interceptors = append(interceptors[:len(interceptors):len(interceptors)], querySchemaInterceptor{})
+	}
+	interceptors, hc.Disabled = disableInterceptors(interceptors, disabled)
+	checkDependencies(flattenInterceptors(interceptors))
+	hc.Interceptors = configureInterceptors(interceptors, icfgs)
+	return hc
+}
+
 func configureInterceptors(interceptors []Interceptor, cfgs []InterceptorConfig) []configuredInterceptor {
 	var its []configuredInterceptor
-	for _, it := range interceptors {
+	for _, it := range flattenInterceptors(interceptors) {
 		var matches []InterceptorConfig
 		for _, c := range cfgs {
 			if it.Match(c) {
@@ -239,17 +693,26 @@ func configureInterceptors(interceptors []Interceptor, cfgs []InterceptorConfig)
 			}
 		}
 
-		if len(matches) > 1 {
-			msg := fmt.Sprintf("multiple configurations specified for interceptor %T: ", it)
-			for _, match := range matches {
-				msg += fmt.Sprintf("%#v", match)
-			}
-			panic(msg)
-		}
-
 		var cfg InterceptorConfig
-		if len(matches) == 1 {
+		switch {
+		case len(matches) == 1:
 			cfg = matches[0]
+		case len(matches) > 1:
+			cc, ok := unwrapConditional(it).(ConfigCombiner)
+			var err error
+			if ok {
+				cfg, err = cc.CombineConfigs(matches)
+			}
+			if !ok || err != nil {
+				msg := fmt.Sprintf("multiple configurations specified for interceptor %T: ", it)
+				for _, match := range matches {
+					msg += fmt.Sprintf("%#v", match)
+				}
+				if err != nil {
+					msg += ": " + err.Error()
+				}
+				panic(msg)
+			}
 		}
 		its = append(its, configuredInterceptor{interceptor: it, config: cfg})
 	}
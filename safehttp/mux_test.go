@@ -47,6 +47,7 @@ func TestMuxOneHandlerOneRequest(t *testing.T) {
 			req:        httptest.NewRequest(safehttp.MethodPost, "http://foo.com/", nil),
 			wantStatus: safehttp.StatusMethodNotAllowed,
 			wantHeader: map[string][]string{
+				"Allow":                  {"GET, HEAD"},
 				"Content-Type":           {"text/plain; charset=utf-8"},
 				"X-Content-Type-Options": {"nosniff"},
 			},
@@ -669,6 +670,7 @@ func TestMuxMethodNotAllowedDefaults(t *testing.T) {
 	}
 
 	wantHeader := map[string][]string{
+		"Allow":                  {"GET, HEAD"},
 		"Content-Type":           {"text/plain; charset=utf-8"},
 		"X-Content-Type-Options": {"nosniff"},
 	}
@@ -748,6 +750,7 @@ func TestMuxMethodNotAllowedCustom(t *testing.T) {
 	}
 
 	wantHeader := map[string][]string{
+		"Allow":              {"GET, HEAD"},
 		"Content-Type":       {"text/html; charset=utf-8"},
 		"Before-Interceptor": {"foo"},
 		"Commit-Interceptor": {"bar"},
@@ -761,3 +764,476 @@ func TestMuxMethodNotAllowedCustom(t *testing.T) {
 		t.Errorf("response body: got %q want %q", got, wantBody)
 	}
 }
+
+func TestMuxHandleNotFound(t *testing.T) {
+	tests := []struct {
+		name       string
+		target     string
+		wantStatus safehttp.StatusCode
+		wantBody   string
+	}{
+		{
+			name:       "Not found",
+			target:     "http://foo.com/missing",
+			wantStatus: safehttp.StatusNotFound,
+			wantBody:   "Not Found\n",
+		},
+		{
+			name:       "Registered",
+			target:     "http://foo.com/bar",
+			wantStatus: safehttp.StatusOK,
+			wantBody:   "bar",
+		},
+		{
+			name:       "Unclean path is redirected",
+			target:     "http://foo.com/baz/../bar",
+			wantStatus: safehttp.StatusMovedPermanently,
+		},
+	}
+
+	mb := safehttp.NewServeMuxConfig(nil)
+	mb.Intercept(setHeaderInterceptor{name: "Foo", value: "bar"})
+	mb.HandleNotFound(safehttp.HandlerFunc(func(w safehttp.ResponseWriter, r *safehttp.IncomingRequest) safehttp.Result {
+		return w.WriteError(safehttp.StatusNotFound)
+	}))
+	mux := mb.Mux()
+	mux.Handle("/bar", safehttp.MethodGet, safehttp.HandlerFunc(func(w safehttp.ResponseWriter, r *safehttp.IncomingRequest) safehttp.Result {
+		return w.Write(safehtml.HTMLEscaped("bar"))
+	}))
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rw := httptest.NewRecorder()
+			mux.ServeHTTP(rw, httptest.NewRequest(safehttp.MethodGet, tt.target, nil))
+
+			if got, want := rw.Code, int(tt.wantStatus); got != want {
+				t.Errorf("rw.Code: got %v want %v", got, want)
+			}
+			if tt.wantStatus == safehttp.StatusMovedPermanently {
+				return
+			}
+			if got := rw.Body.String(); got != tt.wantBody {
+				t.Errorf("response body: got %q want %q", got, tt.wantBody)
+			}
+			if got, want := rw.Header().Get("Foo"), "bar"; got != want {
+				t.Errorf(`rw.Header().Get("Foo"): got %q want %q`, got, want)
+			}
+		})
+	}
+}
+
+func TestMuxMethodNotAllowedAllowHeader(t *testing.T) {
+	mux := safehttp.NewServeMuxConfig(nil).Mux()
+	h := safehttp.HandlerFunc(func(w safehttp.ResponseWriter, r *safehttp.IncomingRequest) safehttp.Result {
+		panic("not tested")
+	})
+	mux.Handle("/users", safehttp.MethodPost, h)
+	mux.Handle("/users", safehttp.MethodGet, h)
+	mux.Handle("/users", safehttp.MethodDelete, h)
+	mux.Handle("/other", safehttp.MethodPut, h)
+
+	rw := httptest.NewRecorder()
+	mux.ServeHTTP(rw, httptest.NewRequest(safehttp.MethodPatch, "http://foo.com/users", nil))
+
+	if got, want := rw.Code, int(safehttp.StatusMethodNotAllowed); got != want {
+		t.Errorf("rw.Code: got %v want %v", got, want)
+	}
+	if diff := cmp.Diff([]string{"DELETE, GET, HEAD, POST"}, rw.Header().Values("Allow")); diff != "" {
+		t.Errorf(`rw.Header().Values("Allow") mismatch (-want +got):\n%s`, diff)
+	}
+}
+
+func TestMuxRedirectTrailingSlash(t *testing.T) {
+	tests := []struct {
+		name         string
+		patterns     []string
+		target       string
+		wantStatus   safehttp.StatusCode
+		wantLocation string
+		wantBody     string
+	}{
+		{
+			name:         "Add trailing slash",
+			patterns:     []string{"/foo/"},
+			target:       "http://foo.com/foo",
+			wantStatus:   safehttp.StatusMovedPermanently,
+			wantLocation: "/foo/",
+		},
+		{
+			name:         "Remove trailing slash",
+			patterns:     []string{"/foo"},
+			target:       "http://foo.com/foo/",
+			wantStatus:   safehttp.StatusMovedPermanently,
+			wantLocation: "/foo",
+		},
+		{
+			name:         "Remove trailing slash with catch-all registered",
+			patterns:     []string{"/", "/foo"},
+			target:       "http://foo.com/foo/",
+			wantStatus:   safehttp.StatusMovedPermanently,
+			wantLocation: "/foo",
+		},
+		{
+			name:         "Query string preserved",
+			patterns:     []string{"/foo"},
+			target:       "http://foo.com/foo/?a=b&c=d",
+			wantStatus:   safehttp.StatusMovedPermanently,
+			wantLocation: "/foo?a=b&c=d",
+		},
+		{
+			name:       "Both registered, slashed",
+			patterns:   []string{"/foo", "/foo/"},
+			target:     "http://foo.com/foo/",
+			wantStatus: safehttp.StatusOK,
+			wantBody:   "/foo/",
+		},
+		{
+			name:       "Both registered, unslashed",
+			patterns:   []string{"/foo", "/foo/"},
+			target:     "http://foo.com/foo",
+			wantStatus: safehttp.StatusOK,
+			wantBody:   "/foo",
+		},
+		{
+			name:       "Neither registered",
+			patterns:   []string{"/bar"},
+			target:     "http://foo.com/foo/",
+			wantStatus: safehttp.StatusNotFound,
+			wantBody:   "404 page not found\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mb := safehttp.NewServeMuxConfig(nil)
+			mb.RedirectTrailingSlash()
+			mux := mb.Mux()
+			for _, p := range tt.patterns {
+				p := p
+				mux.Handle(p, safehttp.MethodGet, safehttp.HandlerFunc(func(w safehttp.ResponseWriter, r *safehttp.IncomingRequest) safehttp.Result {
+					return w.Write(safehtml.HTMLEscaped(p))
+				}))
+			}
+
+			rw := httptest.NewRecorder()
+			mux.ServeHTTP(rw, httptest.NewRequest(safehttp.MethodGet, tt.target, nil))
+
+			if got, want := rw.Code, int(tt.wantStatus); got != want {
+				t.Errorf("rw.Code: got %v want %v", got, want)
+			}
+			if got := rw.Header().Get("Location"); got != tt.wantLocation {
+				t.Errorf(`rw.Header().Get("Location"): got %q want %q`, got, tt.wantLocation)
+			}
+			if tt.wantBody != "" {
+				if got := rw.Body.String(); got != tt.wantBody {
+					t.Errorf("response body: got %q want %q", got, tt.wantBody)
+				}
+			}
+		})
+	}
+}
+
+func TestMuxRedirectTrailingSlashDisabled(t *testing.T) {
+	mux := safehttp.NewServeMuxConfig(nil).Mux()
+	mux.Handle("/foo", safehttp.MethodGet, safehttp.HandlerFunc(func(w safehttp.ResponseWriter, r *safehttp.IncomingRequest) safehttp.Result {
+		panic("not tested")
+	}))
+
+	rw := httptest.NewRecorder()
+	mux.ServeHTTP(rw, httptest.NewRequest(safehttp.MethodGet, "http://foo.com/foo/", nil))
+
+	if got, want := rw.Code, int(safehttp.StatusNotFound); got != want {
+		t.Errorf("rw.Code: got %v want %v", got, want)
+	}
+}
+
+func TestMuxHandlePrefix(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		target     string
+		wantStatus safehttp.StatusCode
+		wantBody   string
+	}{
+		{
+			name:       "Prefix",
+			method:     safehttp.MethodGet,
+			target:     "http://foo.com/static/style.css",
+			wantStatus: safehttp.StatusOK,
+			wantBody:   "prefix /static/",
+		},
+		{
+			name:       "Prefix, any method",
+			method:     safehttp.MethodPost,
+			target:     "http://foo.com/static/style.css",
+			wantStatus: safehttp.StatusOK,
+			wantBody:   "prefix /static/",
+		},
+		{
+			name:       "Longest prefix",
+			method:     safehttp.MethodGet,
+			target:     "http://foo.com/static/img/logo.png",
+			wantStatus: safehttp.StatusOK,
+			wantBody:   "prefix /static/img/",
+		},
+		{
+			name:       "Exact over prefix",
+			method:     safehttp.MethodGet,
+			target:     "http://foo.com/static/img/favicon.ico",
+			wantStatus: safehttp.StatusOK,
+			wantBody:   "exact /static/img/favicon.ico",
+		},
+		{
+			name:       "Exact over prefix, same pattern",
+			method:     safehttp.MethodGet,
+			target:     "http://foo.com/static/js/",
+			wantStatus: safehttp.StatusOK,
+			wantBody:   "exact /static/js/",
+		},
+		{
+			name:       "Prefix serves unregistered methods of same pattern",
+			method:     safehttp.MethodPut,
+			target:     "http://foo.com/static/js/",
+			wantStatus: safehttp.StatusOK,
+			wantBody:   "prefix /static/js/",
+		},
+		{
+			name:       "Exact pattern doesn't fall back to prefix",
+			method:     safehttp.MethodPost,
+			target:     "http://foo.com/static/img/favicon.ico",
+			wantStatus: safehttp.StatusMethodNotAllowed,
+		},
+		{
+			name:       "No prefix",
+			method:     safehttp.MethodGet,
+			target:     "http://foo.com/other/style.css",
+			wantStatus: safehttp.StatusNotFound,
+		},
+	}
+
+	mb := safehttp.NewServeMuxConfig(nil)
+	mb.Intercept(setHeaderInterceptor{name: "Foo", value: "bar"})
+	mux := mb.Mux()
+	for _, p := range []string{"/static/", "/static/img/", "/static/js/"} {
+		p := p
+		mux.HandlePrefix(p, safehttp.HandlerFunc(func(w safehttp.ResponseWriter, r *safehttp.IncomingRequest) safehttp.Result {
+			return w.Write(safehtml.HTMLEscaped("prefix " + p))
+		}))
+	}
+	for _, p := range []string{"/static/img/favicon.ico", "/static/js/"} {
+		p := p
+		mux.Handle(p, safehttp.MethodGet, safehttp.HandlerFunc(func(w safehttp.ResponseWriter, r *safehttp.IncomingRequest) safehttp.Result {
+			return w.Write(safehtml.HTMLEscaped("exact " + p))
+		}))
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rw := httptest.NewRecorder()
+			mux.ServeHTTP(rw, httptest.NewRequest(tt.method, tt.target, nil))
+
+			if got, want := rw.Code, int(tt.wantStatus); got != want {
+				t.Errorf("rw.Code: got %v want %v", got, want)
+			}
+			if tt.wantStatus != safehttp.StatusOK {
+				return
+			}
+			if got := rw.Body.String(); got != tt.wantBody {
+				t.Errorf("response body: got %q want %q", got, tt.wantBody)
+			}
+			if got, want := rw.Header().Get("Foo"), "bar"; got != want {
+				t.Errorf(`rw.Header().Get("Foo"): got %q want %q`, got, want)
+			}
+		})
+	}
+}
+
+func TestMuxHandlePrefixPanics(t *testing.T) {
+	h := safehttp.HandlerFunc(func(w safehttp.ResponseWriter, r *safehttp.IncomingRequest) safehttp.Result {
+		panic("not tested")
+	})
+	tests := []struct {
+		name     string
+		prefixes []string
+	}{
+		{name: "No trailing slash", prefixes: []string{"/static"}},
+		{name: "Double registration", prefixes: []string{"/static/", "/static/"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mux := safehttp.NewServeMuxConfig(nil).Mux()
+			defer func() {
+				if r := recover(); r == nil {
+					t.Errorf("mux.HandlePrefix(...) expected panic")
+				}
+			}()
+			for _, p := range tt.prefixes {
+				mux.HandlePrefix(p, h)
+			}
+		})
+	}
+}
+
+func TestMuxWildcard(t *testing.T) {
+	tests := []struct {
+		name       string
+		target     string
+		wantStatus safehttp.StatusCode
+		wantBody   string
+	}{
+		{
+			name:       "Remainder",
+			target:     "http://foo.com/static/css/style.css",
+			wantStatus: safehttp.StatusOK,
+			wantBody:   "static css/style.css",
+		},
+		{
+			name:       "Empty remainder",
+			target:     "http://foo.com/static/",
+			wantStatus: safehttp.StatusOK,
+			wantBody:   "static ",
+		},
+		{
+			name:       "Unescaped remainder",
+			target:     "http://foo.com/static/a%20b",
+			wantStatus: safehttp.StatusOK,
+			wantBody:   "static a b",
+		},
+		{
+			name:       "Host pattern",
+			target:     "http://bar.com/proxy/a/b",
+			wantStatus: safehttp.StatusOK,
+			wantBody:   "proxy a/b",
+		},
+		{
+			name:       "Host pattern, other host",
+			target:     "http://foo.com/proxy/a/b",
+			wantStatus: safehttp.StatusNotFound,
+		},
+		{
+			name:       "Exact pattern has no wildcard",
+			target:     "http://foo.com/exact/",
+			wantStatus: safehttp.StatusOK,
+			wantBody:   "exact ",
+		},
+	}
+
+	mux := safehttp.NewServeMuxConfig(nil).Mux()
+	for _, p := range []struct{ pattern, name string }{
+		{"/static/...", "static"},
+		{"bar.com/proxy/...", "proxy"},
+		{"/exact/", "exact"},
+	} {
+		name := p.name
+		mux.Handle(p.pattern, safehttp.MethodGet, safehttp.HandlerFunc(func(w safehttp.ResponseWriter, r *safehttp.IncomingRequest) safehttp.Result {
+			return w.Write(safehtml.HTMLEscaped(name + " " + r.PathWildcard()))
+		}))
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rw := httptest.NewRecorder()
+			mux.ServeHTTP(rw, httptest.NewRequest(safehttp.MethodGet, tt.target, nil))
+
+			if got, want := rw.Code, int(tt.wantStatus); got != want {
+				t.Errorf("rw.Code: got %v want %v", got, want)
+			}
+			if tt.wantStatus != safehttp.StatusOK {
+				return
+			}
+			if got := rw.Body.String(); got != tt.wantBody {
+				t.Errorf("response body: got %q want %q", got, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestMuxHandleHost(t *testing.T) {
+	tests := []struct {
+		name       string
+		target     string
+		wantStatus safehttp.StatusCode
+		wantBody   string
+		wantFoo    string
+	}{
+		{
+			name:       "API host",
+			target:     "http://api.example.com/users",
+			wantStatus: safehttp.StatusOK,
+			wantBody:   "api users",
+			wantFoo:    "api",
+		},
+		{
+			name:       "API host with port",
+			target:     "http://api.example.com:8080/users",
+			wantStatus: safehttp.StatusOK,
+			wantBody:   "api users",
+			wantFoo:    "api",
+		},
+		{
+			name:       "WWW host",
+			target:     "http://www.example.com/users",
+			wantStatus: safehttp.StatusOK,
+			wantBody:   "www users",
+		},
+		{
+			name:       "Other host",
+			target:     "http://other.example.com/users",
+			wantStatus: safehttp.StatusNotFound,
+		},
+	}
+
+	mb := safehttp.NewServeMuxConfig(nil)
+	api := mb.Host("api.example.com")
+	api.Intercept(setHeaderInterceptor{name: "Foo", value: "api"})
+	api.Handle("/users", safehttp.MethodGet, safehttp.HandlerFunc(func(w safehttp.ResponseWriter, r *safehttp.IncomingRequest) safehttp.Result {
+		return w.Write(safehtml.HTMLEscaped("api users"))
+	}))
+	mux := mb.Mux()
+	mux.HandleHost("www.example.com", "/users", safehttp.MethodGet, safehttp.HandlerFunc(func(w safehttp.ResponseWriter, r *safehttp.IncomingRequest) safehttp.Result {
+		return w.Write(safehtml.HTMLEscaped("www users"))
+	}))
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rw := httptest.NewRecorder()
+			mux.ServeHTTP(rw, httptest.NewRequest(safehttp.MethodGet, tt.target, nil))
+
+			if got, want := rw.Code, int(tt.wantStatus); got != want {
+				t.Errorf("rw.Code: got %v want %v", got, want)
+			}
+			if tt.wantStatus != safehttp.StatusOK {
+				return
+			}
+			if got := rw.Body.String(); got != tt.wantBody {
+				t.Errorf("response body: got %q want %q", got, tt.wantBody)
+			}
+			if got := rw.Header().Get("Foo"); got != tt.wantFoo {
+				t.Errorf(`rw.Header().Get("Foo"): got %q want %q`, got, tt.wantFoo)
+			}
+		})
+	}
+}
+
+func TestMuxHandleHostPanics(t *testing.T) {
+	tests := []struct {
+		name          string
+		host, pattern string
+	}{
+		{name: "Empty host", host: "", pattern: "/"},
+		{name: "Host with port", host: "example.com:80", pattern: "/"},
+		{name: "Host with path", host: "example.com/foo", pattern: "/"},
+		{name: "Relative pattern", host: "example.com", pattern: "foo"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mux := safehttp.NewServeMuxConfig(nil).Mux()
+			defer func() {
+				if r := recover(); r == nil {
+					t.Errorf("mux.HandleHost(%q, %q, ...) expected panic", tt.host, tt.pattern)
+				}
+			}()
+			mux.HandleHost(tt.host, tt.pattern, safehttp.MethodGet, nil)
+		})
+	}
+}
@@ -0,0 +1,153 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package safehttp
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Negotiate returns the media type of offers that best matches the Accept
+// header of the request, taking quality values into account, e.g.
+//
+//	switch r.Negotiate("text/html", "application/json") {
+//	case "application/json":
+//		return w.Write(safehttp.JSONResponse{Data: data})
+//	case "text/html":
+//		return safehttp.ExecuteTemplate(w, tmpl, data)
+//	default:
+//		return w.WriteError(safehttp.StatusNotAcceptable)
+//	}
+//
+// Ties are broken by the order of offers. If the request has no Accept
+// header, the first offer is returned. If no offer is acceptable, Negotiate
+// returns the empty string.
+func (r *IncomingRequest) Negotiate(offers ...string) string {
+	return negotiate(r.req.Header.Values("Accept"), offers, matchMediaType, false)
+}
+
+// NegotiateLanguage returns the language tag of offers that best matches the
+// Accept-Language header of the request. It follows the same rules as
+// Negotiate. Language ranges match the tags they are a prefix of, e.g. "en"
+// matches "en-GB".
+func (r *IncomingRequest) NegotiateLanguage(offers ...string) string {
+	return negotiate(r.req.Header.Values("Accept-Language"), offers, matchLanguage, false)
+}
+
+// NegotiateEncoding returns the content coding of offers that best matches the
+// Accept-Encoding header of the request. It follows the same rules as
+// Negotiate, except that "identity" is acceptable unless the header explicitly
+// excludes it.
+func (r *IncomingRequest) NegotiateEncoding(offers ...string) string {
+	return negotiate(r.req.Header.Values("Accept-Encoding"), offers, matchEncoding, true)
+}
+
+// acceptRange is an element of an Accept-like header.
+type acceptRange struct {
+	value string
+	q     float64
+}
+
+func parseAccept(values []string) []acceptRange {
+	var ranges []acceptRange
+	for _, v := range values {
+		for _, elem := range strings.Split(v, ",") {
+			params := strings.Split(elem, ";")
+			ar := acceptRange{value: strings.ToLower(strings.TrimSpace(params[0])), q: 1}
+			if ar.value == "" {
+				continue
+			}
+			for _, p := range params[1:] {
+				p = strings.TrimSpace(p)
+				if !strings.HasPrefix(p, "q=") && !strings.HasPrefix(p, "Q=") {
+					continue
+				}
+				q, err := strconv.ParseFloat(p[2:], 64)
+				if err != nil || q < 0 || q > 1 {
+					q = 0
+				}
+				ar.q = q
+			}
+			ranges = append(ranges, ar)
+		}
+	}
+	return ranges
+}
+
+// negotiate returns the offer with the highest quality. The quality of an
+// offer is the one of the most specific range matching it, as reported by
+// match, which returns a negative specificity if the range doesn't match.
+func negotiate(header []string, offers []string, match func(rng, offer string) int, identity bool) string {
+	ranges := parseAccept(header)
+	if len(ranges) == 0 {
+		if len(offers) == 0 {
+			return ""
+		}
+		return offers[0]
+	}
+	best, bestQ := "", 0.0
+	for _, offer := range offers {
+		o := strings.ToLower(offer)
+		q, spec := 0.0, -1
+		if identity && o == "identity" {
+			// Acceptable unless explicitly excluded.
+			q = 1
+		}
+		for _, ar := range ranges {
+			if s := match(ar.value, o); s > spec {
+				q, spec = ar.q, s
+			}
+		}
+		if q > bestQ {
+			best, bestQ = offer, q
+		}
+	}
+	return best
+}
+
+func matchMediaType(rng, offer string) int {
+	switch {
+	case rng == "*/*":
+		return 0
+	case strings.HasSuffix(rng, "/*") && strings.HasPrefix(offer, rng[:len(rng)-1]):
+		return 1
+	case rng == offer:
+		return 2
+	default:
+		return -1
+	}
+}
+
+func matchLanguage(rng, offer string) int {
+	switch {
+	case rng == "*":
+		return 0
+	case rng == offer || strings.HasPrefix(offer, rng+"-"):
+		return len(rng)
+	default:
+		return -1
+	}
+}
+
+func matchEncoding(rng, offer string) int {
+	switch {
+	case rng == "*":
+		return 0
+	case rng == offer:
+		return 1
+	default:
+		return -1
+	}
+}
@@ -0,0 +1,97 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package safehttp_test
+
+import (
+	"testing"
+
+	"github.com/google/go-safeweb/safehttp"
+	"github.com/google/go-safeweb/safehttp/safehttptest"
+)
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		name   string
+		accept string
+		offers []string
+		want   string
+	}{
+		{name: "No header", offers: []string{"text/html", "application/json"}, want: "text/html"},
+		{name: "Exact", accept: "application/json", offers: []string{"text/html", "application/json"}, want: "application/json"},
+		{name: "Quality", accept: "text/html;q=0.5, application/json", offers: []string{"text/html", "application/json"}, want: "application/json"},
+		{name: "Tie keeps offer order", accept: "text/html, application/json", offers: []string{"application/json", "text/html"}, want: "application/json"},
+		{name: "Subtype wildcard", accept: "text/*", offers: []string{"application/json", "text/html"}, want: "text/html"},
+		{name: "Most specific wins", accept: "*/*;q=0.1, text/*;q=0.5, text/html;q=0", offers: []string{"text/html", "text/plain", "application/json"}, want: "text/plain"},
+		{name: "Browser", accept: "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8", offers: []string{"application/json", "text/html"}, want: "text/html"},
+		{name: "Case insensitive", accept: "Application/JSON", offers: []string{"text/html", "application/json"}, want: "application/json"},
+		{name: "Not acceptable", accept: "image/png", offers: []string{"text/html", "application/json"}, want: ""},
+		{name: "Invalid quality", accept: "text/html;q=2, application/json;q=0.1", offers: []string{"text/html", "application/json"}, want: "application/json"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := safehttptest.NewRequest(safehttp.MethodGet, "http://foo.com/", nil)
+			if tt.accept != "" {
+				r.Header.Set("Accept", tt.accept)
+			}
+			if got := r.Negotiate(tt.offers...); got != tt.want {
+				t.Errorf("r.Negotiate(%q): got %q want %q", tt.offers, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNegotiateLanguage(t *testing.T) {
+	tests := []struct {
+		accept string
+		offers []string
+		want   string
+	}{
+		{accept: "", offers: []string{"en", "it"}, want: "en"},
+		{accept: "it-IT, it;q=0.9, en;q=0.8", offers: []string{"en-US", "it-IT"}, want: "it-IT"},
+		{accept: "en", offers: []string{"it", "en-GB"}, want: "en-GB"},
+		{accept: "fr, *;q=0.1", offers: []string{"it", "en"}, want: "it"},
+		{accept: "fr", offers: []string{"it", "en"}, want: ""},
+	}
+	for _, tt := range tests {
+		r := safehttptest.NewRequest(safehttp.MethodGet, "http://foo.com/", nil)
+		if tt.accept != "" {
+			r.Header.Set("Accept-Language", tt.accept)
+		}
+		if got := r.NegotiateLanguage(tt.offers...); got != tt.want {
+			t.Errorf("Accept-Language %q: r.NegotiateLanguage(%q) got %q want %q", tt.accept, tt.offers, got, tt.want)
+		}
+	}
+}
+
+func TestNegotiateEncoding(t *testing.T) {
+	tests := []struct {
+		accept string
+		offers []string
+		want   string
+	}{
+		{accept: "gzip, br;q=0.9", offers: []string{"br", "gzip", "identity"}, want: "gzip"},
+		{accept: "deflate", offers: []string{"gzip", "identity"}, want: "identity"},
+		{accept: "gzip;q=0, identity;q=0", offers: []string{"gzip", "identity"}, want: ""},
+		{accept: "*;q=0", offers: []string{"gzip", "identity"}, want: ""},
+		{accept: "*", offers: []string{"br", "identity"}, want: "br"},
+	}
+	for _, tt := range tests {
+		r := safehttptest.NewRequest(safehttp.MethodGet, "http://foo.com/", nil)
+		r.Header.Set("Accept-Encoding", tt.accept)
+		if got := r.NegotiateEncoding(tt.offers...); got != tt.want {
+			t.Errorf("Accept-Encoding %q: r.NegotiateEncoding(%q) got %q want %q", tt.accept, tt.offers, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package safehttp
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// ErrorObserver is implemented by interceptors that need to know when the
+// handler, or an interceptor running after them, writes an error response,
+// e.g. to record security-relevant failures.
+//
+// OnError runs before the Commit phases. The error is a *ResponseError. An
+// ErrorObserver can replace the error response, e.g. to scrub it, by writing
+// another response to w: the ErrorObservers that didn't run yet are then
+// skipped.
+//
+// Panics aren't reported, they are handled by net/http.
+type ErrorObserver interface {
+	OnError(w ResponseWriter, r *IncomingRequest, err error)
+}
+
+// ResponseError is the error passed to ErrorObserver.OnError.
+type ResponseError struct {
+	// Response is the error response written.
+	Response ErrorResponse
+	// Err is the cause of the error, if known. It's the error of the context
+	// of the request if the timeout set with WithTimeout expired.
+	Err error
+}
+
+func (e *ResponseError) Error() string {
+	code := e.Response.Code()
+	if e.Err != nil {
+		return fmt.Sprintf("%d %s: %v", code, http.StatusText(int(code)), e.Err)
+	}
+	return fmt.Sprintf("%d %s", code, http.StatusText(int(code)))
+}
+
+// Unwrap returns the cause of the error.
+func (e *ResponseError) Unwrap() error {
+	return e.Err
+}
+
+// observeError calls the ErrorObservers among the interceptors whose Before
+// phase ran before the error, in reverse order.
+func (f *flight) observeError(resp ErrorResponse) {
+	err := &ResponseError{Response: resp}
+	if f.timedOut() {
+		err.Err = f.req.Context().Err()
+	}
+	f.observing = true
+	defer func() { f.observing = false }()
+	for i := f.before - 1; i >= 0; i-- {
+		o, ok := f.cfg.Interceptors[i].interceptor.(ErrorObserver)
+		if !ok {
+			continue
+		}
+		o.OnError(f, f.req, err)
+		if f.written {
+			// The response was replaced.
+			return
+		}
+	}
+}
@@ -0,0 +1,177 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package safehttp_test
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-safeweb/safehttp"
+	"github.com/google/safehtml"
+)
+
+// observingInterceptor records the errors it observes in log. If replace is
+// set, OnError replaces the error response with a 404 Not Found.
+type observingInterceptor struct {
+	recordingInterceptor
+	replace bool
+}
+
+func (it observingInterceptor) OnError(w safehttp.ResponseWriter, r *safehttp.IncomingRequest, err error) {
+	*it.log = append(*it.log, "error "+it.name+" "+err.Error())
+	if it.replace {
+		w.WriteError(safehttp.StatusNotFound)
+	}
+}
+
+func TestOnError(t *testing.T) {
+	tests := []struct {
+		name       string
+		install    func(mb *safehttp.ServeMuxConfig, log *[]string)
+		h          safehttp.Handler
+		wantStatus safehttp.StatusCode
+		want       []string
+	}{
+		{
+			name: "Handler error",
+			install: func(mb *safehttp.ServeMuxConfig, log *[]string) {
+				mb.Intercept(
+					observingInterceptor{recordingInterceptor: recordingInterceptor{name: "a", log: log}},
+					observingInterceptor{recordingInterceptor: recordingInterceptor{name: "b", log: log}},
+				)
+			},
+			h: safehttp.HandlerFunc(func(w safehttp.ResponseWriter, r *safehttp.IncomingRequest) safehttp.Result {
+				return w.WriteError(safehttp.StatusForbidden)
+			}),
+			wantStatus: safehttp.StatusForbidden,
+			want: []string{
+				"before a",
+				"before b",
+				"error b 403 Forbidden",
+				"error a 403 Forbidden",
+				"commit b",
+				"commit a",
+			},
+		},
+		{
+			name: "Interceptor error",
+			install: func(mb *safehttp.ServeMuxConfig, log *[]string) {
+				mb.Intercept(
+					observingInterceptor{recordingInterceptor: recordingInterceptor{name: "a", log: log}},
+					observingInterceptor{recordingInterceptor: recordingInterceptor{name: "b", reject: true, log: log}},
+					observingInterceptor{recordingInterceptor: recordingInterceptor{name: "c", log: log}},
+				)
+			},
+			wantStatus: safehttp.StatusForbidden,
+			want: []string{
+				"before a",
+				"before b",
+				"error a 403 Forbidden",
+				"commit c",
+				"commit b",
+				"commit a",
+			},
+		},
+		{
+			name: "Replaced",
+			install: func(mb *safehttp.ServeMuxConfig, log *[]string) {
+				mb.Intercept(
+					observingInterceptor{recordingInterceptor: recordingInterceptor{name: "a", log: log}},
+					observingInterceptor{recordingInterceptor: recordingInterceptor{name: "b", log: log}, replace: true},
+				)
+			},
+			h: safehttp.HandlerFunc(func(w safehttp.ResponseWriter, r *safehttp.IncomingRequest) safehttp.Result {
+				return w.WriteError(safehttp.StatusForbidden)
+			}),
+			wantStatus: safehttp.StatusNotFound,
+			want: []string{
+				"before a",
+				"before b",
+				"error b 403 Forbidden",
+				"commit b",
+				"commit a",
+			},
+		},
+		{
+			name: "No error",
+			install: func(mb *safehttp.ServeMuxConfig, log *[]string) {
+				mb.Intercept(observingInterceptor{recordingInterceptor: recordingInterceptor{name: "a", log: log}})
+			},
+			h: safehttp.HandlerFunc(func(w safehttp.ResponseWriter, r *safehttp.IncomingRequest) safehttp.Result {
+				return w.Write(safehtml.HTMLEscaped("ok"))
+			}),
+			wantStatus: safehttp.StatusOK,
+			want:       []string{"before a", "commit a"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var log []string
+			mb := safehttp.NewServeMuxConfig(nil)
+			tt.install(mb, &log)
+			mux := mb.Mux()
+			mux.Handle("/", safehttp.MethodGet, tt.h)
+
+			rw := httptest.NewRecorder()
+			mux.ServeHTTP(rw, httptest.NewRequest(safehttp.MethodGet, "http://foo.com/", nil))
+
+			if got, want := rw.Code, int(tt.wantStatus); got != want {
+				t.Errorf("rw.Code: got %v want %v", got, want)
+			}
+			if diff := cmp.Diff(tt.want, log); diff != "" {
+				t.Errorf("log mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+type errorCapture struct {
+	recordingInterceptor
+	err *error
+}
+
+func (it errorCapture) OnError(w safehttp.ResponseWriter, r *safehttp.IncomingRequest, err error) {
+	*it.err = err
+}
+
+func TestOnErrorTimeout(t *testing.T) {
+	var log []string
+	var err error
+	mb := safehttp.NewServeMuxConfig(nil)
+	mb.Intercept(errorCapture{recordingInterceptor: recordingInterceptor{name: "a", log: &log}, err: &err})
+	mux := mb.Mux()
+	mux.Handle("/", safehttp.MethodGet, safehttp.HandlerFunc(func(w safehttp.ResponseWriter, r *safehttp.IncomingRequest) safehttp.Result {
+		<-r.Context().Done()
+		return safehttp.NotWritten()
+	}), safehttp.WithTimeout(time.Millisecond))
+
+	mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(safehttp.MethodGet, "http://foo.com/", nil))
+
+	var respErr *safehttp.ResponseError
+	if !errors.As(err, &respErr) {
+		t.Fatalf("OnError err: got %v, want *safehttp.ResponseError", err)
+	}
+	if got, want := respErr.Response.Code(), safehttp.StatusGatewayTimeout; got != want {
+		t.Errorf("respErr.Response.Code(): got %v want %v", got, want)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("errors.Is(%v, context.DeadlineExceeded): got false want true", err)
+	}
+}
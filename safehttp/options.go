@@ -0,0 +1,34 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package safehttp
+
+// AutoOptions makes the ServeMux answer OPTIONS requests for the patterns
+// with no OPTIONS handler with 204 No Content, listing the methods served for
+// the pattern in the Allow header.
+//
+// The interceptors installed in the ServeMuxConfig run for these requests,
+// hence CORS preflight requests are answered by the CORS plugin, if it's
+// installed, and the Allow header is only set for non-CORS requests.
+func (s *ServeMuxConfig) AutoOptions() {
+	s.autoOptions = true
+}
+
+// optionsHandler answers OPTIONS requests with the given Allow header.
+func optionsHandler(allow string) Handler {
+	return HandlerFunc(func(w ResponseWriter, r *IncomingRequest) Result {
+		w.Header().Set("Allow", allow)
+		return w.Write(NoContentResponse{})
+	})
+}
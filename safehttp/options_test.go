@@ -0,0 +1,99 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package safehttp_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-safeweb/safehttp"
+	"github.com/google/go-safeweb/safehttp/plugins/cors"
+	"github.com/google/safehtml"
+)
+
+func TestMuxAutoOptions(t *testing.T) {
+	tests := []struct {
+		name        string
+		autoOptions bool
+		req         *http.Request
+		wantStatus  safehttp.StatusCode
+		wantHeaders map[string][]string
+	}{
+		{
+			name:        "enabled",
+			autoOptions: true,
+			req:         httptest.NewRequest(safehttp.MethodOptions, "http://foo.com/users", nil),
+			wantStatus:  safehttp.StatusNoContent,
+			wantHeaders: map[string][]string{
+				"Allow": {"GET, HEAD, OPTIONS, POST"},
+			},
+		},
+		{
+			name:        "CORS preflight",
+			autoOptions: true,
+			req: func() *http.Request {
+				r := httptest.NewRequest(safehttp.MethodOptions, "http://foo.com/users", nil)
+				r.Header.Set("Origin", "https://bar.com")
+				r.Header.Set("Access-Control-Request-Method", safehttp.MethodPost)
+				return r
+			}(),
+			wantStatus: safehttp.StatusNoContent,
+			wantHeaders: map[string][]string{
+				"Access-Control-Allow-Methods": {"POST"},
+				"Access-Control-Allow-Origin":  {"https://bar.com"},
+				"Access-Control-Max-Age":       {"5"},
+				"Vary":                         {"Origin"},
+			},
+		},
+		{
+			name:       "disabled",
+			req:        httptest.NewRequest(safehttp.MethodOptions, "http://foo.com/users", nil),
+			wantStatus: safehttp.StatusMethodNotAllowed,
+			wantHeaders: map[string][]string{
+				"Allow":                  {"GET, HEAD, POST"},
+				"Content-Type":           {"text/plain; charset=utf-8"},
+				"X-Content-Type-Options": {"nosniff"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := safehttp.NewServeMuxConfig(nil)
+			cfg.Intercept(cors.Default("https://bar.com"))
+			if tt.autoOptions {
+				cfg.AutoOptions()
+			}
+			mux := cfg.Mux()
+			h := safehttp.HandlerFunc(func(w safehttp.ResponseWriter, r *safehttp.IncomingRequest) safehttp.Result {
+				return w.Write(safehtml.HTMLEscaped("users"))
+			})
+			mux.Handle("/users", safehttp.MethodGet, h)
+			mux.Handle("/users", safehttp.MethodPost, h)
+
+			rw := httptest.NewRecorder()
+			mux.ServeHTTP(rw, tt.req)
+
+			if got, want := rw.Code, int(tt.wantStatus); got != want {
+				t.Errorf("rw.Code: got %v want %v", got, want)
+			}
+			if diff := cmp.Diff(tt.wantHeaders, map[string][]string(rw.Header())); diff != "" {
+				t.Errorf("rw.Header() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,203 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package safehttp
+
+import (
+	"net"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// PathAction is the action taken by the ServeMux for a request whose path
+// isn't canonical.
+type PathAction int
+
+const (
+	// PathDefault keeps the default behavior: non-canonical paths are
+	// redirected and the trailing slash is left untouched.
+	PathDefault PathAction = iota
+	// PathRedirect redirects the request to the canonical path with 301 Moved
+	// Permanently. The query string is preserved.
+	PathRedirect
+	// PathRewrite serves the request as if it was for the canonical path,
+	// without redirecting. Interceptors and handlers only see the canonical
+	// path.
+	PathRewrite
+	// PathReject rejects the request with 400 Bad Request.
+	PathReject
+)
+
+// PathPolicy configures how the ServeMux canonicalizes request paths before
+// routing them. The zero value keeps the default behavior.
+type PathPolicy struct {
+	// NonCanonical is the action for paths with duplicate slashes or dot
+	// segments, like "/a//b/../c", whose canonical form is "/a/c".
+	NonCanonical PathAction
+	// TrailingSlash is the action for unregistered paths for which the same
+	// path, with the trailing slash added or removed, was registered. See
+	// ServeMuxConfig.RedirectTrailingSlash.
+	TrailingSlash PathAction
+	// CaseInsensitive is the action for paths that only match a registered
+	// pattern, or a more specific one, when ignoring case, like "/Login" for
+	// the "/login" pattern. The canonical path uses the case of the pattern.
+	// By default, matching is case-sensitive.
+	//
+	// Use it with PathRedirect or PathRewrite to make matching
+	// case-insensitive, so that interceptors keying off the path see the
+	// canonical one.
+	CaseInsensitive PathAction
+	// RejectAmbiguous rejects with 400 Bad Request the paths that different
+	// components might interpret differently, leading to path confusion
+	// attacks: paths containing encoded slashes, backslashes or dots,
+	// literal backslashes or NUL bytes.
+	RejectAmbiguous bool
+}
+
+// SetPathPolicy sets the policy used to canonicalize request paths.
+func (s *ServeMuxConfig) SetPathPolicy(p PathPolicy) {
+	s.pathPolicy = p
+}
+
+// canonicalize applies the path policy to the request. It returns the request
+// to route, or false if a response was already written.
+func (m *ServeMux) canonicalize(w http.ResponseWriter, r *http.Request) (*http.Request, bool) {
+	p := m.pathPolicy
+	if p.RejectAmbiguous && ambiguousPath(r.URL) {
+		writeTextError(w, StatusBadRequest)
+		return nil, false
+	}
+	// The net/http ServeMux doesn't clean the path of CONNECT requests.
+	if r.Method != MethodConnect && p.NonCanonical != PathDefault {
+		if clean := cleanPath(r.URL.Path); clean != r.URL.Path {
+			var ok bool
+			if r, ok = applyPathAction(w, r, p.NonCanonical, clean); !ok {
+				return nil, false
+			}
+		}
+	}
+	if p.TrailingSlash != PathDefault {
+		if alt, ok := m.trailingSlashAlternative(r); ok {
+			return applyPathAction(w, r, p.TrailingSlash, alt)
+		}
+	}
+	if p.CaseInsensitive != PathDefault {
+		if folded, ok := m.caseFoldedPath(r); ok {
+			return applyPathAction(w, r, p.CaseInsensitive, folded)
+		}
+	}
+	return r, true
+}
+
+// caseFoldedPath returns the request path with the case of the longest
+// pattern that matches it case-insensitively, if that pattern is more specific
+// than the one matching the path as is.
+func (m *ServeMux) caseFoldedPath(r *http.Request) (string, bool) {
+	host := r.Host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	p := r.URL.Path
+	var best string
+	for pattern, rh := range m.handlers {
+		if rh.route.hasParams() {
+			continue
+		}
+		if !strings.HasPrefix(pattern, "/") {
+			if !strings.HasPrefix(pattern, host+"/") {
+				continue
+			}
+			pattern = pattern[len(host):]
+		}
+		// Ties are broken deterministically.
+		longer := len(pattern) > len(best) || len(pattern) == len(best) && pattern < best
+		if longer && matchFold(pattern, p) {
+			best = pattern
+		}
+	}
+	if best == "" {
+		return "", false
+	}
+	folded := best
+	if strings.HasSuffix(best, "/") {
+		folded += p[len(best):]
+	}
+	if folded == p {
+		return "", false
+	}
+	_, pattern := m.mux.Handler(r)
+	if i := strings.Index(pattern, "/"); i >= 0 && len(pattern)-i >= len(best) {
+		return "", false
+	}
+	return folded, true
+}
+
+// matchFold reports whether the pattern matches the path, ignoring case.
+func matchFold(pattern, p string) bool {
+	if !strings.HasSuffix(pattern, "/") {
+		return strings.EqualFold(pattern, p)
+	}
+	return len(p) >= len(pattern) && strings.EqualFold(p[:len(pattern)], pattern)
+}
+
+func applyPathAction(w http.ResponseWriter, r *http.Request, action PathAction, p string) (*http.Request, bool) {
+	switch action {
+	case PathRewrite:
+		r2 := r.WithContext(r.Context())
+		u := *r.URL
+		u.Path = p
+		u.RawPath = ""
+		r2.URL = &u
+		return r2, true
+	case PathReject:
+		writeTextError(w, StatusBadRequest)
+		return nil, false
+	default:
+		u := url.URL{Path: p, RawQuery: r.URL.RawQuery}
+		http.Redirect(w, r, u.String(), int(StatusMovedPermanently))
+		return nil, false
+	}
+}
+
+// cleanPath returns the canonical path for p, eliminating . and .. elements
+// and duplicate slashes, like the net/http ServeMux does.
+func cleanPath(p string) string {
+	if p == "" {
+		return "/"
+	}
+	if p[0] != '/' {
+		p = "/" + p
+	}
+	np := path.Clean(p)
+	// path.Clean removes the trailing slash, except for the root.
+	if p[len(p)-1] == '/' && np != "/" {
+		np += "/"
+	}
+	return np
+}
+
+func ambiguousPath(u *url.URL) bool {
+	if strings.ContainsAny(u.Path, "\\\x00") {
+		return true
+	}
+	escaped := strings.ToLower(u.EscapedPath())
+	for _, s := range []string{"%2f", "%5c", "%2e"} {
+		if strings.Contains(escaped, s) {
+			return true
+		}
+	}
+	return false
+}
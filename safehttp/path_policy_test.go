@@ -0,0 +1,204 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package safehttp_test
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-safeweb/safehttp"
+	"github.com/google/safehtml"
+)
+
+func TestPathPolicy(t *testing.T) {
+	tests := []struct {
+		name         string
+		policy       safehttp.PathPolicy
+		target       string
+		wantStatus   safehttp.StatusCode
+		wantBody     string
+		wantLocation string
+	}{
+		{
+			name:         "Default, non-canonical",
+			target:       "http://foo.com/a//b/../c",
+			wantStatus:   safehttp.StatusMovedPermanently,
+			wantLocation: "/a/c",
+		},
+		{
+			name:       "Default, trailing slash",
+			target:     "http://foo.com/a/c/",
+			wantStatus: safehttp.StatusNotFound,
+		},
+		{
+			name:         "Redirect, non-canonical",
+			policy:       safehttp.PathPolicy{NonCanonical: safehttp.PathRedirect},
+			target:       "http://foo.com/a//b/../c?x=1",
+			wantStatus:   safehttp.StatusMovedPermanently,
+			wantLocation: "/a/c?x=1",
+		},
+		{
+			name:       "Rewrite, non-canonical",
+			policy:     safehttp.PathPolicy{NonCanonical: safehttp.PathRewrite},
+			target:     "http://foo.com/a//b/../c",
+			wantStatus: safehttp.StatusOK,
+			wantBody:   "/a/c",
+		},
+		{
+			name:       "Reject, non-canonical",
+			policy:     safehttp.PathPolicy{NonCanonical: safehttp.PathReject},
+			target:     "http://foo.com/a/./c",
+			wantStatus: safehttp.StatusBadRequest,
+		},
+		{
+			name:       "Reject, canonical",
+			policy:     safehttp.PathPolicy{NonCanonical: safehttp.PathReject},
+			target:     "http://foo.com/a/c",
+			wantStatus: safehttp.StatusOK,
+			wantBody:   "/a/c",
+		},
+		{
+			name:         "Redirect, trailing slash",
+			policy:       safehttp.PathPolicy{TrailingSlash: safehttp.PathRedirect},
+			target:       "http://foo.com/a/c/",
+			wantStatus:   safehttp.StatusMovedPermanently,
+			wantLocation: "/a/c",
+		},
+		{
+			name:       "Rewrite, trailing slash",
+			policy:     safehttp.PathPolicy{TrailingSlash: safehttp.PathRewrite},
+			target:     "http://foo.com/a/c/",
+			wantStatus: safehttp.StatusOK,
+			wantBody:   "/a/c",
+		},
+		{
+			name: "Rewrite, non-canonical and trailing slash",
+			policy: safehttp.PathPolicy{
+				NonCanonical:  safehttp.PathRewrite,
+				TrailingSlash: safehttp.PathRewrite,
+			},
+			target:     "http://foo.com/a//c/",
+			wantStatus: safehttp.StatusOK,
+			wantBody:   "/a/c",
+		},
+		{
+			name:       "Reject, trailing slash",
+			policy:     safehttp.PathPolicy{TrailingSlash: safehttp.PathReject},
+			target:     "http://foo.com/a/c/",
+			wantStatus: safehttp.StatusBadRequest,
+		},
+		{
+			name:       "Ambiguous, encoded slash",
+			policy:     safehttp.PathPolicy{RejectAmbiguous: true},
+			target:     "http://foo.com/a%2Fc",
+			wantStatus: safehttp.StatusBadRequest,
+		},
+		{
+			name:       "Ambiguous, encoded backslash",
+			policy:     safehttp.PathPolicy{RejectAmbiguous: true},
+			target:     "http://foo.com/a/b%5c..%5cc",
+			wantStatus: safehttp.StatusBadRequest,
+		},
+		{
+			name:       "Ambiguous, encoded dots",
+			policy:     safehttp.PathPolicy{RejectAmbiguous: true},
+			target:     "http://foo.com/a/b/%2e%2e/c",
+			wantStatus: safehttp.StatusBadRequest,
+		},
+		{
+			name:       "Ambiguous, NUL byte",
+			policy:     safehttp.PathPolicy{RejectAmbiguous: true},
+			target:     "http://foo.com/a/c%00",
+			wantStatus: safehttp.StatusBadRequest,
+		},
+		{
+			name:       "Not ambiguous",
+			policy:     safehttp.PathPolicy{RejectAmbiguous: true},
+			target:     "http://foo.com/a/c",
+			wantStatus: safehttp.StatusOK,
+			wantBody:   "/a/c",
+		},
+		{
+			name:       "Default, case mismatch",
+			target:     "http://foo.com/A/C",
+			wantStatus: safehttp.StatusNotFound,
+		},
+		{
+			name:         "Redirect, case mismatch",
+			policy:       safehttp.PathPolicy{CaseInsensitive: safehttp.PathRedirect},
+			target:       "http://foo.com/A/c?x=1",
+			wantStatus:   safehttp.StatusMovedPermanently,
+			wantLocation: "/a/c?x=1",
+		},
+		{
+			name:       "Rewrite, case mismatch",
+			policy:     safehttp.PathPolicy{CaseInsensitive: safehttp.PathRewrite},
+			target:     "http://foo.com/A/C",
+			wantStatus: safehttp.StatusOK,
+			wantBody:   "/a/c",
+		},
+		{
+			name:       "Rewrite, case mismatch in subtree",
+			policy:     safehttp.PathPolicy{CaseInsensitive: safehttp.PathRewrite},
+			target:     "http://foo.com/Static/Style.css",
+			wantStatus: safehttp.StatusOK,
+			wantBody:   "/static/Style.css",
+		},
+		{
+			name:       "Rewrite, exact case match preferred",
+			policy:     safehttp.PathPolicy{CaseInsensitive: safehttp.PathRewrite},
+			target:     "http://foo.com/A/b",
+			wantStatus: safehttp.StatusOK,
+			wantBody:   "/A/b",
+		},
+		{
+			name:       "Reject, case mismatch",
+			policy:     safehttp.PathPolicy{CaseInsensitive: safehttp.PathReject},
+			target:     "http://foo.com/a/C",
+			wantStatus: safehttp.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mb := safehttp.NewServeMuxConfig(nil)
+			mb.SetPathPolicy(tt.policy)
+			mux := mb.Mux()
+			h := safehttp.HandlerFunc(func(w safehttp.ResponseWriter, r *safehttp.IncomingRequest) safehttp.Result {
+				return w.Write(safehtml.HTMLEscaped(r.URL().Path()))
+			})
+			mux.Handle("/a/c", safehttp.MethodGet, h)
+			mux.Handle("/A/b", safehttp.MethodGet, h)
+			mux.Handle("/a/b", safehttp.MethodGet, h)
+			mux.Handle("/static/", safehttp.MethodGet, h)
+
+			rw := httptest.NewRecorder()
+			mux.ServeHTTP(rw, httptest.NewRequest(safehttp.MethodGet, tt.target, nil))
+
+			if got, want := rw.Code, int(tt.wantStatus); got != want {
+				t.Errorf("rw.Code: got %v want %v", got, want)
+			}
+			if got := rw.Header().Get("Location"); got != tt.wantLocation {
+				t.Errorf(`rw.Header().Get("Location"): got %q want %q`, got, tt.wantLocation)
+			}
+			if tt.wantStatus != safehttp.StatusOK {
+				return
+			}
+			if got := rw.Body.String(); got != tt.wantBody {
+				t.Errorf("response body: got %q want %q", got, tt.wantBody)
+			}
+		})
+	}
+}
@@ -0,0 +1,346 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package safehttp
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// routeMatch holds the parts of the request path matched by the pattern.
+type routeMatch struct {
+	wildcard string
+	params   map[string]string
+}
+
+// routePattern is a parsed ServeMux pattern.
+type routePattern struct {
+	str  string
+	host string
+	segs []patternSegment
+	// subtree is set if the pattern ends with a slash, i.e. it matches all the
+	// paths below it.
+	subtree bool
+}
+
+// patternSegment is a path segment of a pattern, either a literal or a
+// parameter.
+type patternSegment struct {
+	literal string
+	// param is the name of the parameter, or empty for literals.
+	param string
+	// re is the constraint on the value of the parameter, if any.
+	re *regexp.Regexp
+}
+
+// matches reports whether the parameter matches the (non-empty) value.
+func (s patternSegment) matches(v string) bool {
+	return s.re == nil || s.re.MatchString(v)
+}
+
+// constraint returns the regular expression constraining the parameter, or
+// an empty string.
+func (s patternSegment) constraint() string {
+	if s.re == nil {
+		return ""
+	}
+	return s.re.String()
+}
+
+func parsePattern(pattern string) (*routePattern, error) {
+	p := &routePattern{str: pattern}
+	i := strings.Index(pattern, "/")
+	if i < 0 {
+		return nil, fmt.Errorf("pattern %q doesn't contain a path", pattern)
+	}
+	p.host = pattern[:i]
+	path := pattern[i+1:]
+	if path == "" || strings.HasSuffix(path, "/") {
+		p.subtree = true
+		path = strings.TrimSuffix(path, "/")
+	}
+	if path == "" {
+		return p, nil
+	}
+	names := map[string]bool{}
+	for _, s := range strings.Split(path, "/") {
+		if !strings.HasPrefix(s, "{") {
+			if strings.ContainsAny(s, "{}") {
+				return nil, fmt.Errorf("pattern %q: parameters must span a whole path segment, got %q", pattern, s)
+			}
+			p.segs = append(p.segs, patternSegment{literal: s})
+			continue
+		}
+		name := strings.TrimSuffix(strings.TrimPrefix(s, "{"), "}")
+		var expr string
+		if i := strings.Index(name, ":"); i >= 0 {
+			name, expr = name[:i], name[i+1:]
+		}
+		if !strings.HasSuffix(s, "}") || !isParamName(name) {
+			return nil, fmt.Errorf("pattern %q: invalid parameter %q", pattern, s)
+		}
+		if names[name] {
+			return nil, fmt.Errorf("pattern %q: duplicate parameter %q", pattern, name)
+		}
+		names[name] = true
+		seg := patternSegment{param: name}
+		if expr != "" {
+			// The expression has to match the whole value.
+			re, err := regexp.Compile("^(?:" + expr + ")$")
+			if err != nil {
+				return nil, fmt.Errorf("pattern %q: invalid constraint for parameter %q: %v", pattern, name, err)
+			}
+			seg.re = re
+		}
+		p.segs = append(p.segs, seg)
+	}
+	return p, nil
+}
+
+func isParamName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i, c := range name {
+		if c != '_' && (c < 'a' || c > 'z') && (c < 'A' || c > 'Z') && (i == 0 || c < '0' || c > '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// hasParams reports whether the pattern has path parameters.
+func (p *routePattern) hasParams() bool {
+	for _, s := range p.segs {
+		if s.param != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// match reports whether the escaped path matches the pattern and returns the
+// unescaped values of the parameters and the remainder of the path matched by
+// a subtree pattern.
+func (p *routePattern) match(escapedPath string) (params map[string]string, rest string, ok bool) {
+	segs := strings.Split(strings.TrimPrefix(escapedPath, "/"), "/")
+	if p.subtree && len(segs) <= len(p.segs) || !p.subtree && len(segs) != len(p.segs) {
+		return nil, "", false
+	}
+	for i, ps := range p.segs {
+		v, err := url.PathUnescape(segs[i])
+		if err != nil {
+			return nil, "", false
+		}
+		if ps.param == "" {
+			if v != ps.literal {
+				return nil, "", false
+			}
+			continue
+		}
+		if v == "" || !ps.matches(v) {
+			return nil, "", false
+		}
+		if params == nil {
+			params = make(map[string]string)
+		}
+		params[ps.param] = v
+	}
+	if p.subtree {
+		rest, err := url.PathUnescape(strings.Join(segs[len(p.segs):], "/"))
+		if err != nil {
+			return nil, "", false
+		}
+		return params, rest, true
+	}
+	return params, "", true
+}
+
+// patternRelation is the relation between the sets of paths matched by two
+// patterns.
+type patternRelation int
+
+const (
+	disjoint patternRelation = iota
+	equivalent
+	moreSpecific
+	lessSpecific
+	overlapping
+)
+
+// comparePaths compares the paths matched by two patterns, ignoring hosts. A
+// pattern is more specific than another if it matches a strict subset of its
+// paths.
+func comparePaths(a, b *routePattern) patternRelation {
+	var aMore, bMore bool
+	for i := 0; i < len(a.segs) && i < len(b.segs); i++ {
+		as, bs := a.segs[i], b.segs[i]
+		switch {
+		case as.param == "" && bs.param == "":
+			if as.literal != bs.literal {
+				return disjoint
+			}
+		case as.param == "":
+			if as.literal == "" || !bs.matches(as.literal) {
+				// The parameter can't match the literal.
+				return disjoint
+			}
+			aMore = true
+		case bs.param == "":
+			if bs.literal == "" || !as.matches(bs.literal) {
+				return disjoint
+			}
+			bMore = true
+		case as.constraint() == bs.constraint():
+		case bs.re == nil:
+			// A constrained parameter is more specific than an unconstrained
+			// one.
+			aMore = true
+		case as.re == nil:
+			bMore = true
+		default:
+			// Different constraints can't be compared.
+			aMore, bMore = true, true
+		}
+	}
+	switch {
+	case len(a.segs) == len(b.segs):
+		if a.subtree != b.subtree {
+			return disjoint
+		}
+	case len(a.segs) < len(b.segs):
+		if !a.subtree {
+			return disjoint
+		}
+		bMore = true
+	default:
+		if !b.subtree {
+			return disjoint
+		}
+		aMore = true
+	}
+	switch {
+	case aMore && bMore:
+		return overlapping
+	case aMore:
+		return moreSpecific
+	case bMore:
+		return lessSpecific
+	default:
+		return equivalent
+	}
+}
+
+// comparePatterns compares two patterns. Host-specific patterns take
+// precedence over general ones.
+func comparePatterns(a, b *routePattern) patternRelation {
+	rel := comparePaths(a, b)
+	if a.host == b.host || rel == disjoint {
+		return rel
+	}
+	switch {
+	case a.host != "" && b.host != "":
+		return disjoint
+	case a.host != "":
+		return moreSpecific
+	default:
+		return lessSpecific
+	}
+}
+
+// examplePath returns a path matched by both patterns, which must overlap.
+func examplePath(a, b *routePattern) string {
+	if len(a.segs) < len(b.segs) {
+		a, b = b, a
+	}
+	var segs []string
+	for i, s := range a.segs {
+		switch {
+		case s.param == "":
+			segs = append(segs, s.literal)
+		case i < len(b.segs) && b.segs[i].param == "":
+			segs = append(segs, b.segs[i].literal)
+		default:
+			segs = append(segs, "x")
+		}
+	}
+	path := "/" + strings.Join(segs, "/")
+	if a.subtree && len(segs) > 0 {
+		path += "/"
+	}
+	return path
+}
+
+// PatternConflictError is the error returned when two patterns match some
+// requests in common but neither takes precedence over the other.
+type PatternConflictError struct {
+	Pattern1, Pattern2 string
+	// Path is an example path matched by both patterns.
+	Path string
+	// Equivalent is set if the patterns match the same requests.
+	Equivalent bool
+}
+
+func (e *PatternConflictError) Error() string {
+	if e.Equivalent {
+		return fmt.Sprintf("patterns %q and %q match the same requests, e.g. %q", e.Pattern1, e.Pattern2, e.Path)
+	}
+	return fmt.Sprintf("patterns %q and %q both match requests like %q, but neither is more specific than the other", e.Pattern1, e.Pattern2, e.Path)
+}
+
+// ComparePatterns reports which of two ServeMux patterns takes precedence for
+// the requests they both match. It returns a negative number if a takes
+// precedence, a positive number if b does and 0 if no request is matched by
+// both patterns.
+//
+// The precedence is determined as follows:
+//   - host-specific patterns take precedence over general ones;
+//   - otherwise, the more specific pattern takes precedence. A pattern is more
+//     specific than another one if it matches a strict subset of its requests,
+//     e.g. "/users/new" is more specific than "/users/{id:[0-9]+}", which is
+//     more specific than "/users/{id}", which is more specific than "/users/".
+//
+// If neither pattern takes precedence, as for "/users/{id}/edit" and
+// "/users/new/{action}", or "/{id:[0-9]+}" and "/{id:[a-f]+}", a
+// *PatternConflictError is returned. Such patterns
+// can't be registered in the same ServeMux.
+func ComparePatterns(a, b string) (int, error) {
+	pa, err := parsePattern(a)
+	if err != nil {
+		return 0, err
+	}
+	pb, err := parsePattern(b)
+	if err != nil {
+		return 0, err
+	}
+	return comparePatternsErr(pa, pb)
+}
+
+func comparePatternsErr(a, b *routePattern) (int, error) {
+	switch comparePatterns(a, b) {
+	case moreSpecific:
+		return -1, nil
+	case lessSpecific:
+		return 1, nil
+	case disjoint:
+		return 0, nil
+	case equivalent:
+		return 0, &PatternConflictError{Pattern1: a.str, Pattern2: b.str, Path: examplePath(a, b), Equivalent: true}
+	default:
+		return 0, &PatternConflictError{Pattern1: a.str, Pattern2: b.str, Path: examplePath(a, b)}
+	}
+}
@@ -0,0 +1,274 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package safehttp_test
+
+import (
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-safeweb/safehttp"
+	"github.com/google/safehtml"
+)
+
+func TestComparePatterns(t *testing.T) {
+	tests := []struct {
+		a, b         string
+		want         int
+		wantConflict string
+	}{
+		{a: "/users/new", b: "/users/{id}", want: -1},
+		{a: "/users/{id}", b: "/users/new", want: 1},
+		{a: "/users/{id}", b: "/users/", want: -1},
+		{a: "/users/{id}/", b: "/users/{id}", want: 0},
+		{a: "/users/{id}", b: "/users", want: 0},
+		{a: "/users/{id}", b: "/orders/{id}", want: 0},
+		{a: "/users/{id}/edit", b: "/users/{id}/", want: -1},
+		{a: "/", b: "/{x}/{y}", want: 1},
+		{a: "example.com/{x}", b: "/users", want: -1},
+		{a: "/users/{id}", b: "example.com/", want: 1},
+		{a: "a.com/{x}", b: "b.com/{x}", want: 0},
+		{a: "/users/{id}/edit", b: "/users/new/{action}", wantConflict: "/users/new/edit"},
+		{a: "/{a}/b/", b: "/a/{b}/", wantConflict: "/a/b/"},
+		{a: "/users/{id}", b: "/users/{name}", wantConflict: "/users/x"},
+		{a: "/users/{id:[0-9]+}", b: "/users/{id}", want: -1},
+		{a: "/users/new", b: "/users/{id:[0-9]+}", want: 0},
+		{a: "/users/42", b: "/users/{id:[0-9]+}", want: -1},
+		{a: "/users/{id:[0-9]+}", b: "/users/{n:[0-9]+}", wantConflict: "/users/x"},
+		{a: "/users/{id:[0-9]+}", b: "/users/{id:[a-f]+}", wantConflict: "/users/x"},
+	}
+	for _, tt := range tests {
+		got, err := safehttp.ComparePatterns(tt.a, tt.b)
+		if tt.wantConflict != "" {
+			var conflict *safehttp.PatternConflictError
+			if !errors.As(err, &conflict) {
+				t.Errorf("ComparePatterns(%q, %q) got err: %v, want *PatternConflictError", tt.a, tt.b, err)
+				continue
+			}
+			if conflict.Path != tt.wantConflict {
+				t.Errorf("ComparePatterns(%q, %q) conflict path got: %q want: %q", tt.a, tt.b, conflict.Path, tt.wantConflict)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ComparePatterns(%q, %q) got err: %v", tt.a, tt.b, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ComparePatterns(%q, %q) got: %v want: %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestComparePatternsInvalid(t *testing.T) {
+	for _, p := range []string{"users", "/users/{}", "/users/{id", "/users/id}", "/users/x{id}", "/{1id}", "/{id}/{id}", "/{id:[0-9}", "/{:[0-9]+}"} {
+		if _, err := safehttp.ComparePatterns(p, "/"); err == nil {
+			t.Errorf("ComparePatterns(%q, \"/\") got nil err, want error", p)
+		}
+	}
+}
+
+func TestMuxPathParams(t *testing.T) {
+	tests := []struct {
+		name       string
+		target     string
+		wantStatus safehttp.StatusCode
+		wantBody   string
+	}{
+		{
+			name:       "Param",
+			target:     "http://foo.com/users/42",
+			wantStatus: safehttp.StatusOK,
+			wantBody:   "user id=42",
+		},
+		{
+			name:       "Escaped param",
+			target:     "http://foo.com/users/a%2Fb",
+			wantStatus: safehttp.StatusOK,
+			wantBody:   "user id=a/b",
+		},
+		{
+			name:       "Literal takes precedence",
+			target:     "http://foo.com/users/new",
+			wantStatus: safehttp.StatusOK,
+			wantBody:   "new user",
+		},
+		{
+			name:       "Several params",
+			target:     "http://foo.com/users/42/posts/7",
+			wantStatus: safehttp.StatusOK,
+			wantBody:   "post id=42 post=7",
+		},
+		{
+			name:       "Param with wildcard",
+			target:     "http://foo.com/users/42/files/a/b.txt",
+			wantStatus: safehttp.StatusOK,
+			wantBody:   "files id=42 a/b.txt",
+		},
+		{
+			name:       "Falls back to subtree",
+			target:     "http://foo.com/users/42/other",
+			wantStatus: safehttp.StatusOK,
+			wantBody:   "users subtree",
+		},
+		{
+			name:       "Empty param doesn't match",
+			target:     "http://foo.com/users//posts/7",
+			wantStatus: safehttp.StatusMovedPermanently,
+		},
+		{
+			name:       "Host-specific takes precedence",
+			target:     "http://api.com/users/42",
+			wantStatus: safehttp.StatusOK,
+			wantBody:   "api user id=42",
+		},
+	}
+
+	mux := safehttp.NewServeMuxConfig(nil).Mux()
+	for _, p := range []struct{ pattern, name string }{
+		{"/users/{id}", "user"},
+		{"/users/new", "new user"},
+		{"/users/{id}/posts/{post}", "post"},
+		{"/users/{id}/files/...", "files"},
+		{"/users/", "users subtree"},
+		{"api.com/users/{id}", "api user"},
+	} {
+		name := p.name
+		mux.Handle(p.pattern, safehttp.MethodGet, safehttp.HandlerFunc(func(w safehttp.ResponseWriter, r *safehttp.IncomingRequest) safehttp.Result {
+			body := name
+			if id := r.PathParam("id"); id != "" {
+				body += " id=" + id
+			}
+			if post := r.PathParam("post"); post != "" {
+				body += " post=" + post
+			}
+			if wildcard := r.PathWildcard(); wildcard != "" {
+				body += " " + wildcard
+			}
+			return w.Write(safehtml.HTMLEscaped(body))
+		}))
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rw := httptest.NewRecorder()
+			mux.ServeHTTP(rw, httptest.NewRequest(safehttp.MethodGet, tt.target, nil))
+
+			if got, want := rw.Code, int(tt.wantStatus); got != want {
+				t.Errorf("rw.Code: got %v want %v", got, want)
+			}
+			if tt.wantStatus != safehttp.StatusOK {
+				return
+			}
+			if got := rw.Body.String(); got != tt.wantBody {
+				t.Errorf("response body: got %q want %q", got, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestMuxPatternConflictPanics(t *testing.T) {
+	tests := []struct {
+		name     string
+		patterns []string
+		want     string
+	}{
+		{
+			name:     "Overlapping",
+			patterns: []string{"/users/{id}/edit", "/users/new/{action}"},
+			want:     `patterns "/users/new/{action}" and "/users/{id}/edit" both match requests like "/users/new/edit", but neither is more specific than the other`,
+		},
+		{
+			name:     "Equivalent",
+			patterns: []string{"/users/{id}", "/users/{name}"},
+			want:     `patterns "/users/{name}" and "/users/{id}" match the same requests, e.g. "/users/x"`,
+		},
+		{
+			name:     "Invalid",
+			patterns: []string{"/users/{id"},
+			want:     `pattern "/users/{id": invalid parameter "{id"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mux := safehttp.NewServeMuxConfig(nil).Mux()
+			defer func() {
+				r := recover()
+				if r == nil {
+					t.Fatal("mux.Handle(...) expected panic")
+				}
+				if diff := cmp.Diff(tt.want, r); diff != "" {
+					t.Errorf("panic message mismatch (-want +got):\n%s", diff)
+				}
+			}()
+			for _, p := range tt.patterns {
+				mux.Handle(p, safehttp.MethodGet, nil)
+			}
+		})
+	}
+}
+
+func TestMuxPathParamConstraints(t *testing.T) {
+	tests := []struct {
+		target     string
+		wantStatus safehttp.StatusCode
+		wantBody   string
+	}{
+		{target: "http://foo.com/orders/42", wantStatus: safehttp.StatusOK, wantBody: "order 42"},
+		{target: "http://foo.com/orders/42abc", wantStatus: safehttp.StatusNotFound},
+		{target: "http://foo.com/orders/abc", wantStatus: safehttp.StatusNotFound},
+		{target: "http://foo.com/orders/new", wantStatus: safehttp.StatusOK, wantBody: "new order"},
+		{target: "http://foo.com/items/abc", wantStatus: safehttp.StatusOK, wantBody: "item abc"},
+		{target: "http://foo.com/items/7", wantStatus: safehttp.StatusOK, wantBody: "numeric item 7"},
+		{target: "http://foo.com/codes/ab12", wantStatus: safehttp.StatusOK, wantBody: "code ab12"},
+		{target: "http://foo.com/codes/ab123", wantStatus: safehttp.StatusNotFound},
+	}
+
+	mux := safehttp.NewServeMuxConfig(nil).Mux()
+	for _, p := range []struct{ pattern, name string }{
+		{"/orders/{id:[0-9]+}", "order"},
+		{"/orders/new", "new order"},
+		{"/items/{id}", "item"},
+		{"/items/{id:[0-9]+}", "numeric item"},
+		{"/codes/{id:[a-z]{2}[0-9]{2}}", "code"},
+	} {
+		name := p.name
+		mux.Handle(p.pattern, safehttp.MethodGet, safehttp.HandlerFunc(func(w safehttp.ResponseWriter, r *safehttp.IncomingRequest) safehttp.Result {
+			body := name
+			if id := r.PathParam("id"); id != "" {
+				body += " " + id
+			}
+			return w.Write(safehtml.HTMLEscaped(body))
+		}))
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.target, func(t *testing.T) {
+			rw := httptest.NewRecorder()
+			mux.ServeHTTP(rw, httptest.NewRequest(safehttp.MethodGet, tt.target, nil))
+
+			if got, want := rw.Code, int(tt.wantStatus); got != want {
+				t.Errorf("rw.Code: got %v want %v", got, want)
+			}
+			if tt.wantStatus != safehttp.StatusOK {
+				return
+			}
+			if got := rw.Body.String(); got != tt.wantBody {
+				t.Errorf("response body: got %q want %q", got, tt.wantBody)
+			}
+		})
+	}
+}
@@ -0,0 +1,130 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package safehttp
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// Phase is the stage of the processing of a request an interceptor belongs
+// to. Interceptors installed with InterceptInPhase run in the order of their
+// phases, regardless of the order they were installed in.
+type Phase int
+
+const (
+	// PhaseNone is the phase of the interceptors installed with Intercept.
+	// They run after the interceptors of all the other phases.
+	PhaseNone Phase = iota
+	// PhaseTransport is for interceptors that check the connection and the
+	// request as a whole, e.g. host checks or HSTS.
+	PhaseTransport
+	// PhaseAuthentication is for interceptors that identify the user, e.g.
+	// sessions.
+	PhaseAuthentication
+	// PhaseAuthorization is for interceptors that decide whether the request
+	// is allowed, e.g. XSRF or Fetch Metadata protections.
+	PhaseAuthorization
+	// PhaseFraming is for interceptors that control how the response can be
+	// embedded, e.g. X-Frame-Options or COOP.
+	PhaseFraming
+	// PhaseOutput is for interceptors that apply to the response, e.g. CSP.
+	PhaseOutput
+)
+
+var phaseNames = map[Phase]string{
+	PhaseNone:           "None",
+	PhaseTransport:      "Transport",
+	PhaseAuthentication: "Authentication",
+	PhaseAuthorization:  "Authorization",
+	PhaseFraming:        "Framing",
+	PhaseOutput:         "Output",
+}
+
+func (p Phase) String() string {
+	if n, ok := phaseNames[p]; ok {
+		return n
+	}
+	return fmt.Sprintf("Phase(%d)", int(p))
+}
+
+// InterceptInPhase installs the given interceptors in the given phase. The
+// interceptors of a phase run after the ones of the previous phases and, within
+// a phase, in the order they've been installed. For example, the session
+// interceptor runs before the XSRF one after
+//
+//	cfg.InterceptInPhase(PhaseAuthorization, xsrfInterceptor)
+//	cfg.InterceptInPhase(PhaseAuthentication, sessionInterceptor)
+//
+// InterceptInPhase panics if the phase is PhaseNone or unknown.
+func (s *ServeMuxConfig) InterceptInPhase(p Phase, is ...Interceptor) {
+	if _, ok := phaseNames[p]; !ok || p == PhaseNone {
+		panic(fmt.Sprintf("invalid interceptor phase %v", p))
+	}
+	s.interceptors = append(s.interceptors, is...)
+	for range is {
+		s.phases = append(s.phases, p)
+	}
+}
+
+// orderedInterceptors returns the installed interceptors, sorted by phase.
+func (s *ServeMuxConfig) orderedInterceptors() []Interceptor {
+	idx := make([]int, len(s.interceptors))
+	for i := range idx {
+		idx[i] = i
+	}
+	key := func(i int) Phase {
+		if p := s.phases[idx[i]]; p != PhaseNone {
+			return p
+		}
+		return PhaseOutput + 1
+	}
+	sort.SliceStable(idx, func(i, j int) bool { return key(i) < key(j) })
+	its := make([]Interceptor, 0, len(idx))
+	for _, i := range idx {
+		its = append(its, s.interceptors[i])
+	}
+	return its
+}
+
+// InterceptorDependencies is implemented by interceptors that rely on other
+// interceptors, e.g. an XSRF interceptor that reads the session set up by a
+// session interceptor.
+type InterceptorDependencies interface {
+	// Requires returns interceptors of the types that must run before this
+	// one. Only their type matters.
+	Requires() []Interceptor
+}
+
+// checkDependencies panics if an interceptor that implements
+// InterceptorDependencies doesn't run after the interceptors it requires.
+func checkDependencies(interceptors []Interceptor) {
+	for i, it := range interceptors {
+		deps, ok := unwrapConditional(it).(InterceptorDependencies)
+		if !ok {
+			continue
+		}
+	outer:
+		for _, req := range deps.Requires() {
+			for _, before := range interceptors[:i] {
+				if interceptorType(before) == reflect.TypeOf(req) {
+					continue outer
+				}
+			}
+			panic(fmt.Sprintf("interceptor %T requires %T to run before it", it, req))
+		}
+	}
+}
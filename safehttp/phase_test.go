@@ -0,0 +1,136 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package safehttp_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-safeweb/safehttp"
+	"github.com/google/go-safeweb/safehttp/restricted"
+)
+
+func TestInterceptInPhase(t *testing.T) {
+	log, _ := serveRecorded(t, func(mb *safehttp.ServeMuxConfig, log *[]string) {
+		mb.Intercept(recordingInterceptor{name: "custom", log: log})
+		mb.InterceptInPhase(safehttp.PhaseOutput, recordingInterceptor{name: "csp", log: log})
+		mb.InterceptInPhase(safehttp.PhaseAuthorization, recordingInterceptor{name: "xsrf", log: log})
+		mb.InterceptInPhase(safehttp.PhaseAuthentication, recordingInterceptor{name: "session", log: log})
+		mb.InterceptInPhase(safehttp.PhaseTransport, recordingInterceptor{name: "hsts", log: log})
+		mb.InterceptInPhase(safehttp.PhaseAuthorization, recordingInterceptor{name: "fetchmetadata", log: log})
+	})
+
+	want := []string{
+		"before hsts",
+		"before session",
+		"before xsrf",
+		"before fetchmetadata",
+		"before csp",
+		"before custom",
+		"handler",
+		"commit custom",
+		"commit csp",
+		"commit fetchmetadata",
+		"commit xsrf",
+		"commit session",
+		"commit hsts",
+	}
+	if diff := cmp.Diff(want, log); diff != "" {
+		t.Errorf("log mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestInterceptInPhaseInvalid(t *testing.T) {
+	for _, p := range []safehttp.Phase{safehttp.PhaseNone, safehttp.Phase(42)} {
+		t.Run(p.String(), func(t *testing.T) {
+			defer func() {
+				if r := recover(); r == nil {
+					t.Errorf("InterceptInPhase(%v) expected panic", p)
+				}
+			}()
+			safehttp.NewServeMuxConfig(nil).InterceptInPhase(p, setHeaderInterceptor{})
+		})
+	}
+}
+
+type sessionInterceptor struct {
+	setHeaderInterceptor
+}
+
+type xsrfInterceptor struct {
+	setHeaderInterceptor
+}
+
+func (xsrfInterceptor) Requires() []safehttp.Interceptor {
+	return []safehttp.Interceptor{sessionInterceptor{}}
+}
+
+func TestInterceptorDependencies(t *testing.T) {
+	tests := []struct {
+		name      string
+		install   func(mb *safehttp.ServeMuxConfig)
+		cfgs      []safehttp.InterceptorConfig
+		wantPanic bool
+	}{
+		{
+			name: "In order",
+			install: func(mb *safehttp.ServeMuxConfig) {
+				mb.Intercept(sessionInterceptor{}, xsrfInterceptor{})
+			},
+		},
+		{
+			name: "Ordered by phase",
+			install: func(mb *safehttp.ServeMuxConfig) {
+				mb.InterceptInPhase(safehttp.PhaseAuthorization, xsrfInterceptor{})
+				mb.InterceptInPhase(safehttp.PhaseAuthentication, sessionInterceptor{})
+			},
+		},
+		{
+			name: "Wrong order",
+			install: func(mb *safehttp.ServeMuxConfig) {
+				mb.Intercept(xsrfInterceptor{}, sessionInterceptor{})
+			},
+			wantPanic: true,
+		},
+		{
+			name: "Missing",
+			install: func(mb *safehttp.ServeMuxConfig) {
+				mb.Intercept(xsrfInterceptor{})
+			},
+			wantPanic: true,
+		},
+		{
+			name: "Dependency disabled",
+			install: func(mb *safehttp.ServeMuxConfig) {
+				mb.Intercept(sessionInterceptor{}, xsrfInterceptor{})
+			},
+			cfgs:      []safehttp.InterceptorConfig{restricted.DisableInterceptor(sessionInterceptor{}, "public")},
+			wantPanic: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if r := recover(); (r != nil) != tt.wantPanic {
+					t.Errorf("mux.Handle() panic: got %v, want panic %v", r, tt.wantPanic)
+				}
+			}()
+			mb := safehttp.NewServeMuxConfig(nil)
+			tt.install(mb)
+			mb.Mux().Handle("/", safehttp.MethodGet, nil, tt.cfgs...)
+		})
+	}
+}
@@ -0,0 +1,143 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package safehttp
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Pipeline is a named, ordered list of interceptors, e.g. the standard set of
+// protections of an organization, which can be installed in several
+// ServeMuxConfigs with a single call. Each interceptor is a named stage, so
+// that services can override it when installing the pipeline, within the
+// limits set by the pipeline: required stages can't be removed and a stage
+// can only be replaced by an interceptor of the same type.
+//
+//	corp := safehttp.NewPipeline("corp-standard-web").
+//		Require("hsts", hsts.Default()).
+//		Add("csp", csp.Default(""))
+//
+//	corp.Install(cfg, safehttp.ReplaceStage("csp", customCSP))
+type Pipeline struct {
+	name   string
+	stages []pipelineStage
+}
+
+type pipelineStage struct {
+	name     string
+	it       Interceptor
+	required bool
+}
+
+// NewPipeline creates an empty pipeline with the given name.
+func NewPipeline(name string) *Pipeline {
+	return &Pipeline{name: name}
+}
+
+// Add appends a stage, which services can replace or remove, to the pipeline.
+// It panics if the pipeline already has a stage with the same name.
+func (p *Pipeline) Add(name string, it Interceptor) *Pipeline {
+	return p.add(name, it, false)
+}
+
+// Require appends a stage, which services can reconfigure but not remove, to
+// the pipeline. It panics if the pipeline already has a stage with the same
+// name.
+func (p *Pipeline) Require(name string, it Interceptor) *Pipeline {
+	return p.add(name, it, true)
+}
+
+func (p *Pipeline) add(name string, it Interceptor, required bool) *Pipeline {
+	if _, ok := p.stage(name); ok {
+		panic(fmt.Sprintf("pipeline %q: duplicate stage %q", p.name, name))
+	}
+	p.stages = append(p.stages, pipelineStage{name: name, it: it, required: required})
+	return p
+}
+
+func (p *Pipeline) stage(name string) (pipelineStage, bool) {
+	for _, s := range p.stages {
+		if s.name == name {
+			return s, true
+		}
+	}
+	return pipelineStage{}, false
+}
+
+// PipelineOverride is a change to a stage of a Pipeline made by a service
+// when installing it.
+type PipelineOverride struct {
+	stage  string
+	it     Interceptor
+	reason string
+}
+
+// ReplaceStage returns an override that replaces the interceptor of the named
+// stage, e.g. to configure it differently. The interceptor must have the same
+// type as the one it replaces.
+func ReplaceStage(name string, it Interceptor) PipelineOverride {
+	return PipelineOverride{stage: name, it: it}
+}
+
+// RemoveStage returns an override that removes the named stage. Required
+// stages can't be removed. The reason is mandatory, and RemoveStage panics if
+// it is empty.
+func RemoveStage(name, reason string) PipelineOverride {
+	if strings.TrimSpace(reason) == "" {
+		panic("RemoveStage requires a reason")
+	}
+	return PipelineOverride{stage: name, reason: reason}
+}
+
+// Install installs the interceptors of the pipeline in s, in order, after
+// applying the overrides. It panics if an override refers to an unknown stage,
+// removes a required stage, replaces an interceptor with one of another type,
+// or if several overrides apply to the same stage.
+func (p *Pipeline) Install(s *ServeMuxConfig, overrides ...PipelineOverride) {
+	s.Intercept(p.interceptors(overrides)...)
+}
+
+func (p *Pipeline) interceptors(overrides []PipelineOverride) []Interceptor {
+	byStage := map[string]PipelineOverride{}
+	for _, o := range overrides {
+		st, ok := p.stage(o.stage)
+		if !ok {
+			panic(fmt.Sprintf("pipeline %q: unknown stage %q", p.name, o.stage))
+		}
+		if _, ok := byStage[o.stage]; ok {
+			panic(fmt.Sprintf("pipeline %q: multiple overrides of stage %q", p.name, o.stage))
+		}
+		if o.it == nil && st.required {
+			panic(fmt.Sprintf("pipeline %q: stage %q is required and can't be removed", p.name, o.stage))
+		}
+		if o.it != nil && interceptorType(o.it) != interceptorType(st.it) {
+			panic(fmt.Sprintf("pipeline %q: stage %q can't be replaced by %T, want %T", p.name, o.stage, o.it, st.it))
+		}
+		byStage[o.stage] = o
+	}
+
+	var its []Interceptor
+	for _, st := range p.stages {
+		o, ok := byStage[st.name]
+		switch {
+		case !ok:
+			its = append(its, st.it)
+		case o.it != nil:
+			its = append(its, o.it)
+		}
+	}
+	return its
+}
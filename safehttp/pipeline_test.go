@@ -0,0 +1,118 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package safehttp_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-safeweb/safehttp"
+)
+
+func TestPipelineInstall(t *testing.T) {
+	tests := []struct {
+		name      string
+		overrides func(log *[]string) []safehttp.PipelineOverride
+		want      []string
+	}{
+		{
+			name: "No overrides",
+			want: []string{"before a", "before b", "before c", "handler", "commit c", "commit b", "commit a"},
+		},
+		{
+			name: "Replace",
+			overrides: func(log *[]string) []safehttp.PipelineOverride {
+				return []safehttp.PipelineOverride{safehttp.ReplaceStage("b", recordingInterceptor{name: "b2", log: log})}
+			},
+			want: []string{"before a", "before b2", "before c", "handler", "commit c", "commit b2", "commit a"},
+		},
+		{
+			name: "Remove",
+			overrides: func(log *[]string) []safehttp.PipelineOverride {
+				return []safehttp.PipelineOverride{safehttp.RemoveStage("c", "not needed")}
+			},
+			want: []string{"before a", "before b", "handler", "commit b", "commit a"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			log, _ := serveRecorded(t, func(mb *safehttp.ServeMuxConfig, log *[]string) {
+				p := safehttp.NewPipeline("standard").
+					Require("a", recordingInterceptor{name: "a", log: log}).
+					Require("b", recordingInterceptor{name: "b", log: log}).
+					Add("c", recordingInterceptor{name: "c", log: log})
+				var overrides []safehttp.PipelineOverride
+				if tt.overrides != nil {
+					overrides = tt.overrides(log)
+				}
+				p.Install(mb, overrides...)
+			})
+			if diff := cmp.Diff(tt.want, log); diff != "" {
+				t.Errorf("log mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestPipelineInstallPanics(t *testing.T) {
+	tests := []struct {
+		name      string
+		overrides []safehttp.PipelineOverride
+	}{
+		{
+			name:      "Unknown stage",
+			overrides: []safehttp.PipelineOverride{safehttp.RemoveStage("x", "not needed")},
+		},
+		{
+			name:      "Remove required",
+			overrides: []safehttp.PipelineOverride{safehttp.RemoveStage("a", "not needed")},
+		},
+		{
+			name:      "Replace with another type",
+			overrides: []safehttp.PipelineOverride{safehttp.ReplaceStage("b", setHeaderInterceptor{})},
+		},
+		{
+			name: "Multiple overrides",
+			overrides: []safehttp.PipelineOverride{
+				safehttp.RemoveStage("b", "not needed"),
+				safehttp.ReplaceStage("b", recordingInterceptor{name: "b2"}),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := safehttp.NewPipeline("standard").
+				Require("a", recordingInterceptor{name: "a"}).
+				Add("b", recordingInterceptor{name: "b"})
+			defer func() {
+				if r := recover(); r == nil {
+					t.Error("p.Install() expected panic")
+				}
+			}()
+			p.Install(safehttp.NewServeMuxConfig(nil), tt.overrides...)
+		})
+	}
+}
+
+func TestPipelineDuplicateStage(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Error("p.Add() expected panic")
+		}
+	}()
+	safehttp.NewPipeline("standard").Add("a", recordingInterceptor{name: "a"}).Add("a", recordingInterceptor{name: "b"})
+}
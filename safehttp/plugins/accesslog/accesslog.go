@@ -0,0 +1,162 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package accesslog provides a safehttp.Interceptor which logs the requests
+// served by the mux, optionally sampling successful ones.
+package accesslog
+
+import (
+	"encoding/hex"
+	"io"
+	"log"
+	"time"
+
+	"github.com/google/go-safeweb/safehttp"
+)
+
+// DefaultRequestIDHeader is the default header the request ID is read from.
+const DefaultRequestIDHeader = "X-Request-Id"
+
+// Entry is a logged request.
+type Entry struct {
+	// RequestID is the value of the request ID header, or a random ID if the
+	// header was not set.
+	RequestID string
+	Method    string
+	URL       string
+	// Code is the status code of the response.
+	Code safehttp.StatusCode
+	// Duration is the time between the Before phase of the Interceptor and
+	// the response being committed.
+	Duration time.Duration
+}
+
+// Interceptor logs the requests served by the mux.
+//
+// Only requests for which a response is written through the ResponseWriter
+// are logged.
+//
+// The zero value is valid and ready to use: all requests are logged with the
+// standard logger.
+type Interceptor struct {
+	// Log is called with every logged request. If nil, requests are logged
+	// with log.Printf.
+	Log func(Entry)
+	// Sampler decides which requests are logged. If nil, all requests are
+	// logged.
+	Sampler *Sampler
+	// RequestIDHeader is the header the request ID is read from. If empty,
+	// DefaultRequestIDHeader is used.
+	RequestIDHeader string
+	// Clock is used to measure the Duration of the requests. If nil,
+	// safehttp.SystemClock is used.
+	Clock safehttp.Clock
+	// Rand is the source of the request IDs which aren't read from
+	// RequestIDHeader. If nil, safehttp.SystemRand is used.
+	Rand io.Reader
+}
+
+var _ safehttp.Interceptor = Interceptor{}
+
+type start struct {
+	id   string
+	time time.Time
+}
+
+var startKey = safehttp.NewKey("accesslog.start")
+
+// Before records the request ID and the time the request was received.
+func (it Interceptor) Before(w safehttp.ResponseWriter, r *safehttp.IncomingRequest, _ safehttp.InterceptorConfig) safehttp.Result {
+	h := it.RequestIDHeader
+	if h == "" {
+		h = DefaultRequestIDHeader
+	}
+	id := r.Header.Get(h)
+	if id == "" {
+		id = randomID(it.random())
+	}
+	r.Values().Set(startKey, start{id: id, time: it.now()})
+	return safehttp.NotWritten()
+}
+
+// Commit logs the request, if it's sampled.
+func (it Interceptor) Commit(w safehttp.ResponseHeadersWriter, r *safehttp.IncomingRequest, resp safehttp.Response, _ safehttp.InterceptorConfig) {
+	v, ok := r.Values().Get(startKey)
+	if !ok {
+		return
+	}
+	s := v.(start)
+	code := statusCode(resp)
+	if it.Sampler != nil && !it.Sampler.Sample(s.id, code) {
+		return
+	}
+	e := Entry{
+		RequestID: s.id,
+		Method:    r.Method(),
+		URL:       r.URL().String(),
+		Code:      code,
+		Duration:  it.now().Sub(s.time),
+	}
+	if it.Log != nil {
+		it.Log(e)
+		return
+	}
+	log.Printf("%s %s %s %d %v", e.RequestID, e.Method, e.URL, e.Code, e.Duration)
+}
+
+// Match returns false since there are no supported configurations.
+func (Interceptor) Match(safehttp.InterceptorConfig) bool {
+	return false
+}
+
+// statusCode returns the status code the response will be written with by the
+// default dispatcher.
+func statusCode(resp safehttp.Response) safehttp.StatusCode {
+	switch x := resp.(type) {
+	case safehttp.ErrorResponse:
+		return x.Code()
+	case safehttp.RedirectResponse:
+		return x.Code
+	case safehttp.NoContentResponse:
+		return safehttp.StatusNoContent
+	case safehttp.NotModifiedResponse:
+		return safehttp.StatusNotModified
+	case safehttp.UpgradeResponse:
+		return safehttp.StatusSwitchingProtocols
+	default:
+		return safehttp.StatusOK
+	}
+}
+
+func (it Interceptor) now() time.Time {
+	if it.Clock == nil {
+		return safehttp.SystemClock().Now()
+	}
+	return it.Clock.Now()
+}
+
+func (it Interceptor) random() io.Reader {
+	if it.Rand == nil {
+		return safehttp.SystemRand()
+	}
+	return it.Rand
+}
+
+func randomID(rand io.Reader) string {
+	b := make([]byte, 8)
+	if _, err := io.ReadFull(rand, b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,159 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package accesslog_test
+
+import (
+	"bytes"
+	"math"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/google/go-safeweb/safehttp"
+	"github.com/google/go-safeweb/safehttp/plugins/accesslog"
+	"github.com/google/safehtml"
+)
+
+func newMux(it accesslog.Interceptor) *safehttp.ServeMux {
+	mb := safehttp.NewServeMuxConfig(nil)
+	mb.Intercept(it)
+	mux := mb.Mux()
+	mux.Handle("/ok", safehttp.MethodGet, safehttp.HandlerFunc(func(w safehttp.ResponseWriter, r *safehttp.IncomingRequest) safehttp.Result {
+		return w.Write(safehtml.HTMLEscaped("ok"))
+	}))
+	mux.Handle("/redirect", safehttp.MethodGet, safehttp.HandlerFunc(func(w safehttp.ResponseWriter, r *safehttp.IncomingRequest) safehttp.Result {
+		return safehttp.Redirect(w, r, "/ok", safehttp.StatusFound)
+	}))
+	mux.Handle("/error", safehttp.MethodGet, safehttp.HandlerFunc(func(w safehttp.ResponseWriter, r *safehttp.IncomingRequest) safehttp.Result {
+		return w.WriteError(safehttp.StatusInternalServerError)
+	}))
+	return mux
+}
+
+func TestLogEntry(t *testing.T) {
+	var got []accesslog.Entry
+	mux := newMux(accesslog.Interceptor{Log: func(e accesslog.Entry) { got = append(got, e) }})
+
+	for _, target := range []string{"/ok?q=1", "/redirect", "/error", "/notfound"} {
+		req := httptest.NewRequest(safehttp.MethodGet, "http://foo.com"+target, nil)
+		req.Header.Set("X-Request-Id", "id"+target)
+		mux.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	want := []accesslog.Entry{
+		{RequestID: "id/ok?q=1", Method: "GET", URL: "http://foo.com/ok?q=1", Code: safehttp.StatusOK},
+		{RequestID: "id/redirect", Method: "GET", URL: "http://foo.com/redirect", Code: safehttp.StatusFound},
+		{RequestID: "id/error", Method: "GET", URL: "http://foo.com/error", Code: safehttp.StatusInternalServerError},
+	}
+	if diff := cmp.Diff(want, got, cmpopts.IgnoreFields(accesslog.Entry{}, "Duration")); diff != "" {
+		t.Errorf("logged entries mismatch (-want +got):\n%s", diff)
+	}
+}
+
+type stepClock struct {
+	now  time.Time
+	step time.Duration
+}
+
+func (c *stepClock) Now() time.Time {
+	c.now = c.now.Add(c.step)
+	return c.now
+}
+
+func TestClockAndRand(t *testing.T) {
+	var got []accesslog.Entry
+	mux := newMux(accesslog.Interceptor{
+		Log:   func(e accesslog.Entry) { got = append(got, e) },
+		Clock: &stepClock{step: 3 * time.Second},
+		Rand:  bytes.NewReader([]byte{1, 2, 3, 4, 5, 6, 7, 8}),
+	})
+	mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(safehttp.MethodGet, "http://foo.com/ok", nil))
+
+	want := []accesslog.Entry{
+		{RequestID: "0102030405060708", Method: "GET", URL: "http://foo.com/ok", Code: safehttp.StatusOK, Duration: 3 * time.Second},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("logged entries mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestCustomRequestIDHeader(t *testing.T) {
+	var got string
+	mux := newMux(accesslog.Interceptor{
+		Log:             func(e accesslog.Entry) { got = e.RequestID },
+		RequestIDHeader: "X-Cloud-Trace-Context",
+	})
+
+	req := httptest.NewRequest(safehttp.MethodGet, "http://foo.com/ok", nil)
+	req.Header.Set("X-Cloud-Trace-Context", "trace")
+	mux.ServeHTTP(httptest.NewRecorder(), req)
+
+	if want := "trace"; got != want {
+		t.Errorf("e.RequestID: got %q, want %q", got, want)
+	}
+}
+
+func TestSampledErrorsAlwaysLogged(t *testing.T) {
+	logged := map[safehttp.StatusCode]int{}
+	mux := newMux(accesslog.Interceptor{
+		Log:     func(e accesslog.Entry) { logged[e.Code]++ },
+		Sampler: accesslog.NewSampler(0),
+	})
+
+	const n = 100
+	for i := 0; i < n; i++ {
+		for _, target := range []string{"/ok", "/redirect", "/error"} {
+			mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(safehttp.MethodGet, "http://foo.com"+target, nil))
+		}
+	}
+
+	want := map[safehttp.StatusCode]int{
+		safehttp.StatusFound:               n,
+		safehttp.StatusInternalServerError: n,
+	}
+	if diff := cmp.Diff(want, logged); diff != "" {
+		t.Errorf("logged status codes mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestSampledSuccessRate(t *testing.T) {
+	logged := 0
+	s := accesslog.NewSampler(0.25)
+	mux := newMux(accesslog.Interceptor{
+		Log:     func(e accesslog.Entry) { logged++ },
+		Sampler: s,
+	})
+
+	const n = 4000
+	for i := 0; i < n; i++ {
+		// No request ID header, a random ID is generated.
+		mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(safehttp.MethodGet, "http://foo.com/ok", nil))
+	}
+	if got := float64(logged) / n; math.Abs(got-0.25) > 0.05 {
+		t.Errorf("logged fraction: got %v, want 0.25 ± 0.05", got)
+	}
+
+	// The rate can be changed at runtime.
+	s.SetRate(1)
+	logged = 0
+	for i := 0; i < 10; i++ {
+		mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(safehttp.MethodGet, "http://foo.com/ok", nil))
+	}
+	if got, want := logged, 10; got != want {
+		t.Errorf("logged requests after s.SetRate(1): got %v, want %v", got, want)
+	}
+}
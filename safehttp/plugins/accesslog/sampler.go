@@ -0,0 +1,84 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package accesslog
+
+import (
+	"hash/fnv"
+	"math"
+	"sync/atomic"
+
+	"github.com/google/go-safeweb/safehttp"
+)
+
+// Sampler decides which requests are logged. Responses with a status code
+// other than 2xx are always logged, while successful ones are logged with a
+// given probability.
+//
+// Sampling is deterministic: it depends on a hash of the request ID, so all
+// services logging the same request make the same decision.
+//
+// Sampler is safe for concurrent use. The sampling rate can be changed at
+// runtime with SetRate.
+type Sampler struct {
+	// rate holds the bits of a float64.
+	rate uint64
+}
+
+// NewSampler creates a Sampler which logs the given fraction of successful
+// responses. See SetRate.
+func NewSampler(rate float64) *Sampler {
+	s := &Sampler{}
+	s.SetRate(rate)
+	return s
+}
+
+// SetRate sets the fraction of successful responses which are logged. It's
+// clamped to [0, 1].
+func (s *Sampler) SetRate(rate float64) {
+	rate = math.Max(0, math.Min(1, rate))
+	atomic.StoreUint64(&s.rate, math.Float64bits(rate))
+}
+
+// Rate returns the fraction of successful responses which are logged.
+func (s *Sampler) Rate() float64 {
+	return math.Float64frombits(atomic.LoadUint64(&s.rate))
+}
+
+// Sample reports whether the response to the request with the given ID should
+// be logged.
+func (s *Sampler) Sample(requestID string, code safehttp.StatusCode) bool {
+	if code < 200 || code > 299 {
+		return true
+	}
+	rate := s.Rate()
+	if rate >= 1 {
+		return true
+	}
+	h := fnv.New64a()
+	h.Write([]byte(requestID))
+	return float64(mix(h.Sum64())) < rate*math.MaxUint64
+}
+
+// mix spreads the bits of the FNV hash, whose high bits are poorly distributed
+// for short inputs that only differ at the end, like sequential IDs. This is
+// the finalizer of the SplitMix64 generator.
+func mix(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
@@ -0,0 +1,93 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package accesslog_test
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/google/go-safeweb/safehttp"
+	"github.com/google/go-safeweb/safehttp/plugins/accesslog"
+)
+
+func TestSamplerErrorsAlwaysSampled(t *testing.T) {
+	s := accesslog.NewSampler(0)
+	codes := []safehttp.StatusCode{
+		safehttp.StatusMovedPermanently,
+		safehttp.StatusBadRequest,
+		safehttp.StatusForbidden,
+		safehttp.StatusNotFound,
+		safehttp.StatusInternalServerError,
+	}
+	for _, code := range codes {
+		for i := 0; i < 100; i++ {
+			id := fmt.Sprintf("request-%d", i)
+			if !s.Sample(id, code) {
+				t.Errorf("s.Sample(%q, %v) got false, want true", id, code)
+			}
+		}
+	}
+}
+
+func TestSamplerRate(t *testing.T) {
+	const n = 20000
+	for _, rate := range []float64{0, 0.01, 0.1, 0.5, 0.9, 1} {
+		t.Run(fmt.Sprintf("%v", rate), func(t *testing.T) {
+			s := accesslog.NewSampler(rate)
+			sampled := 0
+			for i := 0; i < n; i++ {
+				if s.Sample(fmt.Sprintf("request-%d", i), safehttp.StatusOK) {
+					sampled++
+				}
+			}
+			if got := float64(sampled) / n; math.Abs(got-rate) > 0.02 {
+				t.Errorf("sampled fraction: got %v, want %v ± 0.02", got, rate)
+			}
+		})
+	}
+}
+
+func TestSamplerDeterministic(t *testing.T) {
+	s := accesslog.NewSampler(0.5)
+	for i := 0; i < 100; i++ {
+		id := fmt.Sprintf("request-%d", i)
+		if first, second := s.Sample(id, safehttp.StatusOK), s.Sample(id, safehttp.StatusOK); first != second {
+			t.Errorf("s.Sample(%q) got %v, then %v", id, first, second)
+		}
+	}
+}
+
+func TestSamplerSetRate(t *testing.T) {
+	s := accesslog.NewSampler(0)
+	if s.Sample("request", safehttp.StatusOK) {
+		t.Error("rate 0: s.Sample() got true, want false")
+	}
+	s.SetRate(1)
+	if got, want := s.Rate(), 1.0; got != want {
+		t.Errorf("s.Rate() got %v, want %v", got, want)
+	}
+	if !s.Sample("request", safehttp.StatusOK) {
+		t.Error("rate 1: s.Sample() got false, want true")
+	}
+	s.SetRate(2)
+	if got, want := s.Rate(), 1.0; got != want {
+		t.Errorf("s.SetRate(2): s.Rate() got %v, want %v", got, want)
+	}
+	s.SetRate(-1)
+	if got, want := s.Rate(), 0.0; got != want {
+		t.Errorf("s.SetRate(-1): s.Rate() got %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,121 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package autocert obtains and renews the certificates of a safehttp.Server
+// with ACME, e.g. from Let's Encrypt.
+//
+// The certificates are only requested for an allowlist of hosts, which is
+// shared with the hostcheck plugin: otherwise, anyone could make the server
+// request certificates for arbitrary names and exhaust its rate limits.
+//
+// The TLS-ALPN-01 challenge is answered by the TLS configuration of the
+// server. The HTTP-01 challenge, which needs a server on port 80, is answered
+// by a handler registered in the ServeMux of that server.
+//
+// # Usage
+//
+//	m := autocert.New(autocert.DirCache("/var/cache/certs"), "example.com", "www.example.com")
+//	mb := safehttp.NewServeMuxConfig(nil)
+//	mb.Intercept(m.HostCheck())
+//	// ...
+//	s := &safehttp.Server{Addr: ":443", Mux: mb.Mux()}
+//	if err := m.Configure(s); err != nil {
+//		// ...
+//	}
+//	s.ListenAndServeTLS("", "")
+//
+// More info:
+//   - RFC 8555: https://tools.ietf.org/html/rfc8555
+//   - RFC 8737: https://tools.ietf.org/html/rfc8737
+package autocert
+
+import (
+	"crypto/tls"
+
+	"github.com/google/go-safeweb/safehttp"
+	"github.com/google/go-safeweb/safehttp/plugins/hostcheck"
+	"golang.org/x/crypto/acme"
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// Cache stores the certificates and the account key. See
+// https://pkg.go.dev/golang.org/x/crypto/acme/autocert#Cache.
+type Cache = autocert.Cache
+
+// DirCache is a Cache storing the data in a directory.
+type DirCache = autocert.DirCache
+
+// challengePattern is the pattern of the HTTP-01 challenge requests.
+const challengePattern = "/.well-known/acme-challenge/..."
+
+// Manager obtains and renews the certificates.
+type Manager struct {
+	// ACME is the underlying manager. It can be used to set the contact Email
+	// or the Client, e.g. to use the staging environment of the CA, before
+	// the server is started. Its HostPolicy must not be changed.
+	ACME *autocert.Manager
+
+	hosts []string
+}
+
+// New creates a Manager which accepts the Terms of Service of the CA and
+// requests certificates for the given hosts. It panics if no hosts are given.
+func New(cache Cache, hosts ...string) *Manager {
+	if len(hosts) == 0 {
+		panic("autocert.New requires at least one host")
+	}
+	return &Manager{
+		ACME: &autocert.Manager{
+			Prompt:     autocert.AcceptTOS,
+			Cache:      cache,
+			HostPolicy: autocert.HostWhitelist(hosts...),
+		},
+		hosts: append([]string(nil), hosts...),
+	}
+}
+
+// Hosts returns the hosts certificates are requested for.
+func (m *Manager) Hosts() []string {
+	return append([]string(nil), m.hosts...)
+}
+
+// HostCheck returns a hostcheck plugin allowing the same hosts.
+func (m *Manager) HostCheck() hostcheck.Interceptor {
+	return hostcheck.New(m.hosts...)
+}
+
+// TLSConfig returns the safehttp.TLSIntermediate configuration, getting the
+// certificates from the Manager and answering the TLS-ALPN-01 challenges.
+func (m *Manager) TLSConfig() *tls.Config {
+	cfg := safehttp.TLSIntermediate()
+	cfg.GetCertificate = m.ACME.GetCertificate
+	cfg.NextProtos = []string{"h2", "http/1.1", acme.ALPNProto}
+	return cfg
+}
+
+// Configure sets the TLSConfig of the server. The certificates are obtained
+// when the first TLS connection for a host is made.
+func (m *Manager) Configure(s *safehttp.Server) error {
+	return s.SetTLSConfig(m.TLSConfig())
+}
+
+// RegisterChallengeHandler registers the handler answering the HTTP-01
+// challenges in the ServeMux, which must be served on port 80, e.g. the one of
+// safehttp.RedirectToHTTPSServer. The CA then
+// also tries this challenge, which is useful if port 443 is behind a proxy
+// terminating TLS.
+func (m *Manager) RegisterChallengeHandler(mux *safehttp.ServeMux) {
+	h := safehttp.RegisteredHandlerFromHTTP(m.ACME.HTTPHandler(nil))
+	mux.Handle(challengePattern, safehttp.MethodGet, h)
+}
@@ -0,0 +1,151 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package autocert
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-safeweb/safehttp"
+	"golang.org/x/crypto/acme"
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// memCache is an in-memory Cache.
+type memCache map[string][]byte
+
+func (c memCache) Get(ctx context.Context, key string) ([]byte, error) {
+	if b, ok := c[key]; ok {
+		return b, nil
+	}
+	return nil, autocert.ErrCacheMiss
+}
+
+func (c memCache) Put(ctx context.Context, key string, data []byte) error {
+	c[key] = data
+	return nil
+}
+
+func (c memCache) Delete(ctx context.Context, key string) error {
+	delete(c, key)
+	return nil
+}
+
+func TestNewNoHosts(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Error("expected panic")
+		}
+	}()
+	New(memCache{})
+}
+
+func TestConfigure(t *testing.T) {
+	m := New(memCache{}, "example.com")
+	var s safehttp.Server
+	if err := m.Configure(&s); err != nil {
+		t.Fatalf("Configure: %v", err)
+	}
+	if got, want := s.TLSConfig.MinVersion, uint16(tls.VersionTLS12); got != want {
+		t.Errorf("s.TLSConfig.MinVersion: got %#04x want %#04x", got, want)
+	}
+	if s.TLSConfig.GetCertificate == nil {
+		t.Error("s.TLSConfig.GetCertificate: got nil")
+	}
+	if diff := cmp.Diff([]string{"h2", "http/1.1", acme.ALPNProto}, s.TLSConfig.NextProtos); diff != "" {
+		t.Errorf("s.TLSConfig.NextProtos mismatch (-want +got):\n%s", diff)
+	}
+
+	// Certificates are not requested for other hosts.
+	_, err := s.TLSConfig.GetCertificate(&tls.ClientHelloInfo{ServerName: "evil.com"})
+	if err == nil {
+		t.Error("GetCertificate(evil.com): got nil error")
+	}
+}
+
+func TestHostCheck(t *testing.T) {
+	m := New(memCache{}, "example.com")
+	mb := safehttp.NewServeMuxConfig(nil)
+	mb.Intercept(m.HostCheck())
+	mux := mb.Mux()
+	mux.Handle("/", safehttp.MethodGet, safehttp.HandlerFunc(func(w safehttp.ResponseWriter, r *safehttp.IncomingRequest) safehttp.Result {
+		return w.Write(safehttp.NoContentResponse{})
+	}))
+
+	tests := []struct {
+		host     string
+		wantCode safehttp.StatusCode
+	}{
+		{host: "example.com", wantCode: safehttp.StatusNoContent},
+		{host: "evil.com", wantCode: safehttp.StatusNotFound},
+	}
+	for _, tt := range tests {
+		rr := httptest.NewRecorder()
+		mux.ServeHTTP(rr, httptest.NewRequest(safehttp.MethodGet, "http://"+tt.host+"/", nil))
+		if got, want := rr.Code, int(tt.wantCode); got != want {
+			t.Errorf("%s: rr.Code got %v want %v", tt.host, got, want)
+		}
+	}
+}
+
+func TestChallengeHandler(t *testing.T) {
+	cache := memCache{"token+http-01": []byte("token.thumbprint")}
+	m := New(cache, "example.com")
+	mux := safehttp.NewServeMuxConfig(nil).Mux()
+	m.RegisterChallengeHandler(mux)
+
+	tests := []struct {
+		name     string
+		url      string
+		wantCode safehttp.StatusCode
+		wantBody string
+	}{
+		{
+			name:     "Token",
+			url:      "http://example.com/.well-known/acme-challenge/token",
+			wantCode: safehttp.StatusOK,
+			wantBody: "token.thumbprint",
+		},
+		{
+			name:     "Unknown token",
+			url:      "http://example.com/.well-known/acme-challenge/other",
+			wantCode: safehttp.StatusNotFound,
+			wantBody: "Not Found\n",
+		},
+		{
+			name:     "Other host",
+			url:      "http://evil.com/.well-known/acme-challenge/token",
+			wantCode: safehttp.StatusForbidden,
+			wantBody: "Forbidden\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			mux.ServeHTTP(rr, httptest.NewRequest(safehttp.MethodGet, tt.url, nil))
+
+			if got, want := rr.Code, int(tt.wantCode); got != want {
+				t.Errorf("rr.Code: got %v want %v", got, want)
+			}
+			if got := rr.Body.String(); got != tt.wantBody {
+				t.Errorf("rr.Body: got %q want %q", got, tt.wantBody)
+			}
+		})
+	}
+}
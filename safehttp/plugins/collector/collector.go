@@ -21,6 +21,7 @@ package collector
 import (
 	"encoding/json"
 	"io/ioutil"
+	"mime"
 
 	"github.com/google/go-safeweb/safehttp"
 )
@@ -99,7 +100,10 @@ func Handler(handler func(Report), cspHandler func(CSPReport)) safehttp.Handler
 			return w.WriteError(safehttp.StatusBadRequest)
 		}
 
-		ct := r.Header.Get("Content-Type")
+		ct, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		if err != nil {
+			return w.WriteError(safehttp.StatusUnsupportedMediaType)
+		}
 		if ct == "application/csp-report" {
 			return handleDeprecatedCSPReports(cspHandler, w, b)
 		} else if ct == "application/reports+json" {
@@ -110,6 +114,30 @@ func Handler(handler func(Report), cspHandler func(CSPReport)) safehttp.Handler
 	})
 }
 
+// CSPHandler builds a safehttp.Handler which calls cspHandler when a CSP
+// violation report is received, either in the deprecated format sent to the
+// report-uri directive or through the Reporting API, as configured by the
+// ReportingAPI option of the csp plugin. Both are delivered to the same URI, so
+// registering CSPHandler there collects the reports of all browsers:
+//
+//	mb.Intercept(csp.Interceptor{
+//		Policy:       csp.StrictPolicy{ReportURI: "https://example.com/collector"},
+//		ReportingAPI: true,
+//	})
+//	mux := mb.Mux()
+//	mux.Handle("/collector", safehttp.MethodPost, collector.CSPHandler(func(r collector.CSPReport) {
+//		log.Printf("CSP violation: %+v", r)
+//	}))
+//
+// Reports of other types are ignored.
+func CSPHandler(cspHandler func(CSPReport)) safehttp.Handler {
+	return Handler(func(r Report) {
+		if b, ok := r.Body.(CSPReport); ok {
+			cspHandler(b)
+		}
+	}, cspHandler)
+}
+
 func handleDeprecatedCSPReports(h func(CSPReport), w safehttp.ResponseWriter, b []byte) safehttp.Result {
 	// In CSP2 it is clearly stated that a report has a single key 'csp-report'
 	// which holds the report object. Like this:
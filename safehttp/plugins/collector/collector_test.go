@@ -456,3 +456,72 @@ func TestInvalidRequest(t *testing.T) {
 		})
 	}
 }
+
+func TestCSPHandler(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		report      string
+		want        []collector.CSPReport
+	}{
+		{
+			name:        "Deprecated format",
+			contentType: "application/csp-report",
+			report: `{"csp-report": {
+				"blocked-uri": "https://evil.com/script.js",
+				"document-uri": "https://example.com/",
+				"effective-directive": "script-src-elem"
+			}}`,
+			want: []collector.CSPReport{{
+				BlockedURL:         "https://evil.com/script.js",
+				DocumentURL:        "https://example.com/",
+				EffectiveDirective: "script-src-elem",
+			}},
+		},
+		{
+			name:        "Reporting API",
+			contentType: "application/reports+json; charset=utf-8",
+			report: `[{
+				"type": "csp-violation",
+				"url": "https://example.com/",
+				"body": {
+					"blockedURL": "https://evil.com/script.js",
+					"documentURL": "https://example.com/",
+					"effectiveDirective": "script-src-elem"
+				}
+			}, {
+				"type": "deprecation",
+				"url": "https://example.com/",
+				"body": {"id": "x"}
+			}]`,
+			want: []collector.CSPReport{{
+				BlockedURL:         "https://evil.com/script.js",
+				DocumentURL:        "https://example.com/",
+				EffectiveDirective: "script-src-elem",
+				ViolatedDirective:  "script-src-elem",
+			}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []collector.CSPReport
+			h := collector.CSPHandler(func(r collector.CSPReport) {
+				got = append(got, r)
+			})
+
+			req := safehttptest.NewRequest(safehttp.MethodPost, "/collector", strings.NewReader(tt.report))
+			req.Header.Set("Content-Type", tt.contentType)
+
+			fakeRW, rr := safehttptest.NewFakeResponseWriter()
+			h.ServeHTTP(fakeRW, req)
+
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("reports gotten mismatch (-want +got):\n%s", diff)
+			}
+			if got, want := rr.Code, int(safehttp.StatusNoContent); got != want {
+				t.Errorf("rr.Code got: %v want: %v", got, want)
+			}
+		})
+	}
+}
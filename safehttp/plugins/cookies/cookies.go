@@ -0,0 +1,275 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package cookies provides signed and encrypted cookies holding typed values.
+//
+// Values are encoded as JSON and protected with HMAC-SHA256 or, for values
+// that must stay confidential, with AES-GCM. The name of the cookie is bound
+// to its value, so that a protected value can't be moved to another cookie.
+//
+// Keys can be rotated: values are always protected with the first key of the
+// Jar, and are accepted if they were protected with any of its keys.
+//
+// # Usage
+//
+//	jar := &cookies.Jar{Keys: [][]byte{newKey, oldKey}, Encrypted: true, MaxAge: 24 * time.Hour}
+//
+//	// In a handler:
+//	var prefs Preferences
+//	if err := jar.Get(r, "__Host-prefs", &prefs); err != nil {
+//		prefs = defaultPreferences
+//	}
+//	...
+//	if err := jar.Set(w, "__Host-prefs", prefs); err != nil {
+//		return w.WriteError(safehttp.StatusInternalServerError)
+//	}
+package cookies
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/google/go-safeweb/safehttp"
+)
+
+// MaxLength is the maximum length of the value of a cookie written by a Jar.
+// Browsers are required to store cookies of up to 4096 bytes, including
+// their name and attributes.
+const MaxLength = 3800
+
+var (
+	// ErrInvalid is returned when the value of a cookie wasn't protected with
+	// any of the keys of the Jar, or was tampered with.
+	ErrInvalid = errors.New("cookies: invalid cookie value")
+	// ErrExpired is returned when the value of a cookie is older than the
+	// MaxAge of the Jar.
+	ErrExpired = errors.New("cookies: expired cookie value")
+)
+
+// Jar reads and writes protected cookies.
+type Jar struct {
+	// Keys are the keys used to protect the values. The first one is used to
+	// protect new values. Signing keys must be at least 32 bytes long;
+	// encryption keys must be 16, 24 or 32 bytes long, to select AES-128,
+	// AES-192 or AES-256.
+	Keys [][]byte
+	// Encrypted selects AES-GCM encryption instead of HMAC-SHA256 signatures.
+	Encrypted bool
+	// MaxAge, if positive, is both the Max-Age of the cookies and the
+	// validity of their values, which is enforced on Get.
+	MaxAge time.Duration
+	// Clock is used to check whether the values are expired. If nil,
+	// safehttp.SystemClock is used.
+	Clock safehttp.Clock
+	// Rand is the source of the nonces of the encrypted values. If nil,
+	// safehttp.SystemRand is used.
+	Rand io.Reader
+	// CookiePolicy is used to read the cookies in Get. See
+	// safehttp.CookiePolicy.
+	CookiePolicy safehttp.CookiePolicy
+}
+
+// Set protects the JSON encoding of value and sets it as the named cookie. The
+// cookie is created with safehttp.NewCookie, hence it's Secure, HttpOnly and
+// SameSite=Lax, and its Path is "/". The options are applied to the cookie
+// before it's added, e.g. to make it SameSite=Strict.
+func (j *Jar) Set(w safehttp.ResponseWriter, name string, value interface{}, opts ...func(*safehttp.Cookie)) error {
+	v, err := j.Encode(name, value)
+	if err != nil {
+		return err
+	}
+	c := safehttp.NewCookie(name, v)
+	c.Path("/")
+	if j.MaxAge > 0 {
+		c.SetMaxAge(int(j.MaxAge / time.Second))
+	}
+	for _, o := range opts {
+		o(c)
+	}
+	return w.AddCookie(c)
+}
+
+// Get verifies the value of the named cookie and decodes it into dst. It
+// returns net/http.ErrNoCookie if the cookie is missing, the errors of
+// IncomingRequest.CookieWithPolicy if the CookiePolicy rejects it, and
+// ErrInvalid or ErrExpired if the value can't be trusted.
+func (j *Jar) Get(r *safehttp.IncomingRequest, name string, dst interface{}) error {
+	c, err := r.CookieWithPolicy(name, j.CookiePolicy)
+	if err != nil {
+		return err
+	}
+	return j.Decode(name, c.Value(), dst)
+}
+
+// Delete removes the named cookie from the browser.
+func (j *Jar) Delete(w safehttp.ResponseWriter, name string) error {
+	c := safehttp.NewCookie(name, "")
+	c.Path("/")
+	c.SetMaxAge(-1)
+	return w.AddCookie(c)
+}
+
+// Encode returns the protected value of the named cookie, as written by Set.
+func (j *Jar) Encode(name string, value interface{}) (string, error) {
+	if len(j.Keys) == 0 {
+		return "", errors.New("cookies: no keys")
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	var expiry uint64
+	if j.MaxAge > 0 {
+		expiry = uint64(j.now().Add(j.MaxAge).Unix())
+	}
+	payload := make([]byte, 8, 8+len(data))
+	binary.BigEndian.PutUint64(payload, expiry)
+	payload = append(payload, data...)
+
+	var v string
+	if j.Encrypted {
+		v, err = seal(j.random(), j.Keys[0], name, payload)
+	} else {
+		v, err = sign(j.Keys[0], name, payload)
+	}
+	if err != nil {
+		return "", err
+	}
+	if len(v) > MaxLength {
+		return "", fmt.Errorf("cookies: value of cookie %q is %d bytes long, the maximum is %d", name, len(v), MaxLength)
+	}
+	return v, nil
+}
+
+// Decode verifies the protected value of the named cookie and decodes it into
+// dst.
+func (j *Jar) Decode(name, value string, dst interface{}) error {
+	var payload []byte
+	for _, k := range j.Keys {
+		var err error
+		if j.Encrypted {
+			payload, err = open(k, name, value)
+		} else {
+			payload, err = verify(k, name, value)
+		}
+		if err == nil {
+			break
+		}
+	}
+	if len(payload) < 8 {
+		return ErrInvalid
+	}
+	if expiry := binary.BigEndian.Uint64(payload); expiry != 0 && j.now().Unix() >= int64(expiry) {
+		return ErrExpired
+	}
+	d := json.NewDecoder(bytes.NewReader(payload[8:]))
+	if err := d.Decode(dst); err != nil {
+		return fmt.Errorf("cookies: decoding cookie %q: %v", name, err)
+	}
+	return nil
+}
+
+func (j *Jar) now() time.Time {
+	if j.Clock == nil {
+		return safehttp.SystemClock().Now()
+	}
+	return j.Clock.Now()
+}
+
+func (j *Jar) random() io.Reader {
+	if j.Rand == nil {
+		return safehttp.SystemRand()
+	}
+	return j.Rand
+}
+
+var encoding = base64.RawURLEncoding
+
+func mac(key []byte, name string, payload []byte) []byte {
+	m := hmac.New(sha256.New, key)
+	m.Write([]byte(name))
+	m.Write([]byte{0})
+	m.Write(payload)
+	return m.Sum(nil)
+}
+
+func sign(key []byte, name string, payload []byte) (string, error) {
+	if len(key) < 32 {
+		return "", errors.New("cookies: signing keys must be at least 32 bytes long")
+	}
+	return encoding.EncodeToString(payload) + "." + encoding.EncodeToString(mac(key, name, payload)), nil
+}
+
+func verify(key []byte, name, value string) ([]byte, error) {
+	i := strings.LastIndexByte(value, '.')
+	if i < 0 || len(key) < 32 {
+		return nil, ErrInvalid
+	}
+	payload, err := encoding.DecodeString(value[:i])
+	if err != nil {
+		return nil, ErrInvalid
+	}
+	sig, err := encoding.DecodeString(value[i+1:])
+	if err != nil || !hmac.Equal(sig, mac(key, name, payload)) {
+		return nil, ErrInvalid
+	}
+	return payload, nil
+}
+
+func seal(rand io.Reader, key []byte, name string, payload []byte) (string, error) {
+	aead, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(payload)+aead.Overhead())
+	if _, err := io.ReadFull(rand, nonce); err != nil {
+		return "", err
+	}
+	return encoding.EncodeToString(aead.Seal(nonce, nonce, payload, []byte(name))), nil
+}
+
+func open(key []byte, name, value string) ([]byte, error) {
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, ErrInvalid
+	}
+	b, err := encoding.DecodeString(value)
+	if err != nil || len(b) < aead.NonceSize() {
+		return nil, ErrInvalid
+	}
+	payload, err := aead.Open(nil, b[:aead.NonceSize()], b[aead.NonceSize():], []byte(name))
+	if err != nil {
+		return nil, ErrInvalid
+	}
+	return payload, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("cookies: invalid encryption key: %v", err)
+	}
+	return cipher.NewGCM(block)
+}
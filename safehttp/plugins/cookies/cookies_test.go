@@ -0,0 +1,214 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cookies_test
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-safeweb/safehttp"
+	"github.com/google/go-safeweb/safehttp/plugins/cookies"
+	"github.com/google/go-safeweb/safehttp/safehttptest"
+)
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+type prefs struct {
+	Theme string
+	Size  int
+}
+
+var (
+	key1 = bytes.Repeat([]byte{1}, 32)
+	key2 = bytes.Repeat([]byte{2}, 32)
+)
+
+// roundTrip sets the cookie with w and reads it with r.
+func roundTrip(t *testing.T, set, get *cookies.Jar, name string, value, dst interface{}) error {
+	t.Helper()
+	w, _ := safehttptest.NewFakeResponseWriter()
+	if err := set.Set(w, name, value); err != nil {
+		t.Fatalf("set.Set: %v", err)
+	}
+	if len(w.Cookies) != 1 {
+		t.Fatalf("len(w.Cookies): got %d want 1", len(w.Cookies))
+	}
+	r := safehttptest.NewRequest(safehttp.MethodGet, "https://foo.com/", nil)
+	r.Header.Set("Cookie", name+"="+w.Cookies[0].Value())
+	return get.Get(r, name, dst)
+}
+
+func TestJarRoundTrip(t *testing.T) {
+	for _, encrypted := range []bool{false, true} {
+		jar := &cookies.Jar{Keys: [][]byte{key1}, Encrypted: encrypted}
+		var got prefs
+		if err := roundTrip(t, jar, jar, "__Host-prefs", prefs{Theme: "dark", Size: 3}, &got); err != nil {
+			t.Fatalf("Encrypted: %v: jar.Get: %v", encrypted, err)
+		}
+		if diff := cmp.Diff(prefs{Theme: "dark", Size: 3}, got); diff != "" {
+			t.Errorf("Encrypted: %v: jar.Get mismatch (-want +got):\n%s", encrypted, diff)
+		}
+	}
+}
+
+func TestJarEncryptedIsConfidential(t *testing.T) {
+	jar := &cookies.Jar{Keys: [][]byte{key1}, Encrypted: true}
+	v, err := jar.Encode("prefs", prefs{Theme: "secret-theme"})
+	if err != nil {
+		t.Fatalf("jar.Encode: %v", err)
+	}
+	if strings.Contains(v, "secret") {
+		t.Errorf("jar.Encode: got %q, want an opaque value", v)
+	}
+}
+
+func TestJarKeyRotation(t *testing.T) {
+	for _, encrypted := range []bool{false, true} {
+		old := &cookies.Jar{Keys: [][]byte{key1}, Encrypted: encrypted}
+		rotated := &cookies.Jar{Keys: [][]byte{key2, key1}, Encrypted: encrypted}
+		retired := &cookies.Jar{Keys: [][]byte{key2}, Encrypted: encrypted}
+
+		var got prefs
+		if err := roundTrip(t, old, rotated, "prefs", prefs{Theme: "dark"}, &got); err != nil {
+			t.Errorf("Encrypted: %v: value protected with the old key: got err %v, want nil", encrypted, err)
+		}
+		if err := roundTrip(t, old, retired, "prefs", prefs{Theme: "dark"}, &got); err != cookies.ErrInvalid {
+			t.Errorf("Encrypted: %v: value protected with a retired key: got err %v, want %v", encrypted, err, cookies.ErrInvalid)
+		}
+	}
+}
+
+func TestJarInvalid(t *testing.T) {
+	for _, encrypted := range []bool{false, true} {
+		jar := &cookies.Jar{Keys: [][]byte{key1}, Encrypted: encrypted}
+		v, err := jar.Encode("prefs", prefs{Theme: "dark"})
+		if err != nil {
+			t.Fatalf("jar.Encode: %v", err)
+		}
+		tampered := "B" + v[1:]
+		if v[0] == 'B' {
+			tampered = "C" + v[1:]
+		}
+		tests := []struct {
+			name, cookie, value string
+		}{
+			{name: "Other cookie", cookie: "other", value: v},
+			{name: "Tampered", cookie: "prefs", value: tampered},
+			{name: "Garbage", cookie: "prefs", value: "garbage"},
+			{name: "Empty", cookie: "prefs", value: ""},
+		}
+		for _, tt := range tests {
+			var got prefs
+			if err := jar.Decode(tt.cookie, tt.value, &got); err != cookies.ErrInvalid {
+				t.Errorf("Encrypted: %v: %s: jar.Decode got err %v, want %v", encrypted, tt.name, err, cookies.ErrInvalid)
+			}
+		}
+	}
+}
+
+func TestJarExpiry(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+	jar := &cookies.Jar{Keys: [][]byte{key1}, MaxAge: time.Hour, Clock: clock}
+
+	w, _ := safehttptest.NewFakeResponseWriter()
+	if err := jar.Set(w, "prefs", prefs{}); err != nil {
+		t.Fatalf("jar.Set: %v", err)
+	}
+	if got, want := w.Cookies[0].String(), "Max-Age=3600"; !strings.Contains(got, want) {
+		t.Errorf("cookie: got %q, want it to contain %q", got, want)
+	}
+	v := w.Cookies[0].Value()
+
+	clock.now = clock.now.Add(59 * time.Minute)
+	var got prefs
+	if err := jar.Decode("prefs", v, &got); err != nil {
+		t.Errorf("jar.Decode before expiry: got err %v, want nil", err)
+	}
+	clock.now = clock.now.Add(time.Minute)
+	if err := jar.Decode("prefs", v, &got); err != cookies.ErrExpired {
+		t.Errorf("jar.Decode after expiry: got err %v, want %v", err, cookies.ErrExpired)
+	}
+}
+
+func TestJarDefaults(t *testing.T) {
+	jar := &cookies.Jar{Keys: [][]byte{key1}}
+	w, _ := safehttptest.NewFakeResponseWriter()
+	if err := jar.Set(w, "prefs", prefs{}, func(c *safehttp.Cookie) { c.SameSite(safehttp.SameSiteStrictMode) }); err != nil {
+		t.Fatalf("jar.Set: %v", err)
+	}
+	got := w.Cookies[0].String()
+	for _, want := range []string{"Path=/", "HttpOnly", "Secure", "SameSite=Strict"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("cookie: got %q, want it to contain %q", got, want)
+		}
+	}
+}
+
+func TestJarErrors(t *testing.T) {
+	r := safehttptest.NewRequest(safehttp.MethodGet, "https://foo.com/", nil)
+	jar := &cookies.Jar{Keys: [][]byte{key1}}
+	if err := jar.Get(r, "prefs", &prefs{}); !errors.Is(err, http.ErrNoCookie) {
+		t.Errorf("jar.Get without cookie: got err %v, want %v", err, http.ErrNoCookie)
+	}
+	if _, err := (&cookies.Jar{}).Encode("prefs", prefs{}); err == nil {
+		t.Error("Encode without keys: got nil err, want error")
+	}
+	if _, err := (&cookies.Jar{Keys: [][]byte{[]byte("short")}}).Encode("prefs", prefs{}); err == nil {
+		t.Error("Encode with a short signing key: got nil err, want error")
+	}
+	if _, err := (&cookies.Jar{Keys: [][]byte{make([]byte, 20)}, Encrypted: true}).Encode("prefs", prefs{}); err == nil {
+		t.Error("Encode with an invalid encryption key: got nil err, want error")
+	}
+	if _, err := jar.Encode("prefs", strings.Repeat("x", cookies.MaxLength)); err == nil {
+		t.Error("Encode with a long value: got nil err, want error")
+	}
+}
+
+func TestJarCookiePolicy(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  safehttp.CookiePolicy
+		wantErr error
+	}{
+		{name: "Default"},
+		{name: "Secure prefixes required", policy: safehttp.CookiePolicy{RequireSecurePrefixes: true}, wantErr: safehttp.ErrInsecureCookie},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			jar := &cookies.Jar{Keys: [][]byte{key1}, CookiePolicy: tt.policy}
+			v, err := jar.Encode("__Host-prefs", prefs{Theme: "dark"})
+			if err != nil {
+				t.Fatalf("jar.Encode: %v", err)
+			}
+			// The request is forwarded over HTTP by a proxy terminating HTTPS.
+			r := safehttptest.NewRequest(safehttp.MethodGet, "http://foo.com/", nil)
+			r.Header.Set("Cookie", "__Host-prefs="+v)
+			if err := jar.Get(r, "__Host-prefs", &prefs{}); err != tt.wantErr {
+				t.Errorf("jar.Get: got err %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -47,6 +47,9 @@ var disallowedContentTypes = map[string]bool{
 // The HEAD request method is disallowed.
 //
 // All of this is to prevent XSRF.
+//
+// OPTIONS requests without the Origin and Access-Control-Request-Method
+// headers aren't CORS requests and are left to the handler.
 type Interceptor struct {
 	// AllowedOrigins determines which origins should be allowed in the
 	// Access-Control-Allow-Origin header.
@@ -114,6 +117,12 @@ func (it *Interceptor) SetAllowedHeaders(headers ...string) {
 //   - Vary
 func (it *Interceptor) Before(w safehttp.ResponseWriter, r *safehttp.IncomingRequest, _ safehttp.InterceptorConfig) safehttp.Result {
 	origin := r.Header.Get("Origin")
+	if r.Method() == safehttp.MethodOptions && origin == "" && r.Header.Get("Access-Control-Request-Method") == "" {
+		// Not a CORS request, browsers always send an Origin header with
+		// OPTIONS requests. Leave it to the handler, e.g. the one of
+		// safehttp.ServeMuxConfig.AutoOptions.
+		return safehttp.NotWritten()
+	}
 	if origin != "" && !it.AllowedOrigins[origin] {
 		if safehttp.IsLocalDev() {
 			log.Println("cors plugin blocked a request due to a mismatching Origin header.")
@@ -537,3 +537,20 @@ func TestPreflightEmptyOrigin(t *testing.T) {
 		t.Errorf("rr.Header() mismatch (-want +got):\n%s", diff)
 	}
 }
+
+func TestNonCORSOptions(t *testing.T) {
+	req := safehttptest.NewRequest(safehttp.MethodOptions, "http://bar.com/asdf", nil)
+
+	fakeRW, rr := safehttptest.NewFakeResponseWriter()
+
+	it := cors.Default("https://foo.com")
+	it.Before(fakeRW, req, nil)
+
+	if want := safehttp.StatusOK; rr.Code != int(want) {
+		t.Errorf("rr.Code got: %v want: %v", rr.Code, want)
+	}
+	wantHeaders := map[string][]string{}
+	if diff := cmp.Diff(wantHeaders, map[string][]string(rr.Header())); diff != "" {
+		t.Errorf("rr.Header() mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,377 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package csp
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/google/go-safeweb/safehttp"
+)
+
+// Kinds of values of the directives.
+const (
+	sourceList = iota
+	// ancestorList is a source list which only allows the 'self' and 'none'
+	// keywords, used by frame-ancestors, base-uri and form-action.
+	ancestorList
+	noValues
+	uriList
+	token
+	sandboxFlags
+	scriptOnly
+	policyNames
+	webRTC
+)
+
+var directiveKinds = map[string]int{
+	"child-src":        sourceList,
+	"connect-src":      sourceList,
+	"default-src":      sourceList,
+	"fenced-frame-src": sourceList,
+	"font-src":         sourceList,
+	"frame-src":        sourceList,
+	"img-src":          sourceList,
+	"manifest-src":     sourceList,
+	"media-src":        sourceList,
+	"object-src":       sourceList,
+	"script-src":       sourceList,
+	"script-src-attr":  sourceList,
+	"script-src-elem":  sourceList,
+	"style-src":        sourceList,
+	"style-src-attr":   sourceList,
+	"style-src-elem":   sourceList,
+	"worker-src":       sourceList,
+
+	"base-uri":        ancestorList,
+	"form-action":     ancestorList,
+	"frame-ancestors": ancestorList,
+
+	"block-all-mixed-content":   noValues,
+	"upgrade-insecure-requests": noValues,
+
+	"report-uri":                uriList,
+	"report-to":                 token,
+	"sandbox":                   sandboxFlags,
+	"require-trusted-types-for": scriptOnly,
+	"trusted-types":             policyNames,
+	"webrtc":                    webRTC,
+}
+
+// nonceDirectives are the directives which accept nonces.
+var nonceDirectives = map[string]bool{
+	"default-src":     true,
+	"script-src":      true,
+	"script-src-elem": true,
+	"style-src":       true,
+	"style-src-elem":  true,
+}
+
+var sourceKeywords = map[string]bool{
+	"'self'":                     true,
+	"'none'":                     true,
+	"'unsafe-inline'":            true,
+	"'unsafe-eval'":              true,
+	"'strict-dynamic'":           true,
+	"'unsafe-hashes'":            true,
+	"'report-sample'":            true,
+	"'wasm-unsafe-eval'":         true,
+	"'inline-speculation-rules'": true,
+}
+
+var (
+	hashSource = regexp.MustCompile(`^'sha(256|384|512)-[A-Za-z0-9+/_-]+={0,2}'$`)
+	// schemeSource and hostSource are looser than the CSP grammar, but reject
+	// the values which would be misinterpreted.
+	schemeSource = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9+.-]*:$`)
+	hostSource   = regexp.MustCompile(`^([A-Za-z][A-Za-z0-9+.-]*://)?(\*|(\*\.)?[A-Za-z0-9-]+(\.[A-Za-z0-9-]+)*)(:(\*|[0-9]+))?(/[A-Za-z0-9._~!$&()*+=:@%/-]*)?$`)
+	tokenValue   = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+)
+
+type directive struct {
+	name   string
+	values []string
+	nonce  bool
+	// rejected is set if values were rejected, which were already reported.
+	rejected bool
+}
+
+// PolicyBuilder builds a custom Policy from its directives, validating them,
+// e.g.
+//
+//	p, err := csp.NewPolicyBuilder().
+//		Directive("default-src", "'self'").
+//		Directive("img-src", "'self'", "https://cdn.example.com").
+//		Nonce("script-src").
+//		Directive("report-uri", "https://example.com/collector").
+//		Build()
+//
+// Unknown directives (e.g. typos), keywords missing their quotes, source lists
+// combining 'none' with other sources and malformed values are reported by
+// Build. Nonces can't be part of the values, since they change with every
+// response: use Nonce instead.
+type PolicyBuilder struct {
+	directives []*directive
+	errs       []string
+}
+
+// NewPolicyBuilder returns an empty PolicyBuilder.
+func NewPolicyBuilder() *PolicyBuilder {
+	return &PolicyBuilder{}
+}
+
+// ParsePolicy returns a PolicyBuilder with the directives of a serialized
+// policy, e.g. to extend an existing policy. Errors are reported by Build.
+func ParsePolicy(policy string) *PolicyBuilder {
+	b := NewPolicyBuilder()
+	for _, d := range strings.Split(policy, ";") {
+		f := strings.Fields(d)
+		if len(f) == 0 {
+			continue
+		}
+		b.Directive(f[0], f[1:]...)
+	}
+	return b
+}
+
+func (b *PolicyBuilder) errorf(format string, args ...interface{}) {
+	b.errs = append(b.errs, fmt.Sprintf(format, args...))
+}
+
+func (b *PolicyBuilder) directive(name string) *directive {
+	name = strings.ToLower(name)
+	for _, d := range b.directives {
+		if d.name == name {
+			return d
+		}
+	}
+	if _, ok := directiveKinds[name]; !ok {
+		b.errorf("unknown directive %q", name)
+		return nil
+	}
+	d := &directive{name: name}
+	b.directives = append(b.directives, d)
+	return d
+}
+
+// Directive adds the directive with the given values to the policy. If the
+// directive was already added, the values are appended to it.
+func (b *PolicyBuilder) Directive(name string, values ...string) *PolicyBuilder {
+	d := b.directive(name)
+	if d == nil {
+		return b
+	}
+	for _, v := range values {
+		if q := "'" + strings.ToLower(v) + "'"; sourceKeywords[q] || q == "'script'" || q == "'allow-duplicates'" {
+			b.errorf("%s: keyword %s must be quoted: %s", d.name, v, q)
+			d.rejected = true
+			continue
+		}
+		if sourceKeywords[strings.ToLower(v)] {
+			v = strings.ToLower(v)
+		}
+		if strings.HasPrefix(strings.ToLower(v), "'nonce-") {
+			b.errorf("%s: nonces can't be static, use Nonce", d.name)
+			d.rejected = true
+			continue
+		}
+		d.values = append(d.values, v)
+	}
+	return b
+}
+
+// Nonce adds the nonce of the response to the directive, which must be one of
+// default-src, script-src, script-src-elem, style-src and style-src-elem.
+func (b *PolicyBuilder) Nonce(name string) *PolicyBuilder {
+	d := b.directive(name)
+	if d == nil {
+		return b
+	}
+	if !nonceDirectives[d.name] {
+		b.errorf("%s: nonces are not supported", d.name)
+		d.rejected = true
+		return b
+	}
+	d.nonce = true
+	return b
+}
+
+// String serializes the directives added so far, with a "{nonce}" placeholder
+// for the nonces.
+func (b *PolicyBuilder) String() string {
+	return serializeDirectives(b.directives, "{nonce}")
+}
+
+// Build validates the directives and returns the policy. The errors of all
+// the invalid directives are reported.
+func (b *PolicyBuilder) Build() (*CustomPolicy, error) {
+	errs := append([]string{}, b.errs...)
+	p := &CustomPolicy{}
+	for _, d := range b.directives {
+		for _, err := range validateDirective(d) {
+			errs = append(errs, d.name+": "+err)
+		}
+		c := *d
+		c.values = append([]string{}, d.values...)
+		p.directives = append(p.directives, &c)
+		if c.name == "report-uri" && len(c.values) > 0 {
+			p.reportURIValue = c.values[0]
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errors.New("invalid CSP: " + strings.Join(errs, "; "))
+	}
+	return p, nil
+}
+
+// MustBuild is like Build, but panics if the policy is invalid. It's meant to
+// initialize policies in global variables.
+func (b *PolicyBuilder) MustBuild() *CustomPolicy {
+	p, err := b.Build()
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
+func validateDirective(d *directive) []string {
+	var errs []string
+	kind := directiveKinds[d.name]
+	switch kind {
+	case sourceList, ancestorList:
+		if len(d.values) == 0 && !d.nonce && !d.rejected {
+			errs = append(errs, "empty source list, use 'none'")
+		}
+		for _, v := range d.values {
+			switch {
+			case v == "'none'":
+				if len(d.values) > 1 || d.nonce {
+					errs = append(errs, "'none' can't be combined with other sources")
+				}
+			case sourceKeywords[v]:
+				if kind == ancestorList && v != "'self'" {
+					errs = append(errs, fmt.Sprintf("keyword %s is not supported", v))
+				}
+			case hashSource.MatchString(v):
+				if kind == ancestorList {
+					errs = append(errs, fmt.Sprintf("hash %s is not supported", v))
+				}
+			case strings.HasPrefix(v, "'"):
+				errs = append(errs, fmt.Sprintf("unknown keyword %s", v))
+			case !schemeSource.MatchString(v) && !hostSource.MatchString(v):
+				errs = append(errs, fmt.Sprintf("invalid source %q", v))
+			}
+		}
+	case noValues:
+		if len(d.values) > 0 {
+			errs = append(errs, "no values are allowed")
+		}
+	case uriList:
+		if len(d.values) == 0 {
+			errs = append(errs, "no URIs")
+		}
+		for _, v := range d.values {
+			if strings.ContainsAny(v, "',") {
+				errs = append(errs, fmt.Sprintf("invalid URI %q", v))
+			}
+		}
+	case token:
+		if len(d.values) != 1 || !tokenValue.MatchString(d.values[0]) {
+			errs = append(errs, "a single endpoint name is required")
+		}
+	case sandboxFlags:
+		for _, v := range d.values {
+			if !strings.HasPrefix(v, "allow-") || !tokenValue.MatchString(v) {
+				errs = append(errs, fmt.Sprintf("invalid sandbox flag %q", v))
+			}
+		}
+	case scriptOnly:
+		if len(d.values) != 1 || d.values[0] != "'script'" {
+			errs = append(errs, "only 'script' is supported")
+		}
+	case policyNames:
+		for _, v := range d.values {
+			switch {
+			case v == "'none'":
+				if len(d.values) > 1 {
+					errs = append(errs, "'none' can't be combined with other policies")
+				}
+			case v == "'allow-duplicates'":
+			case !validTrustedTypesPolicyName(v):
+				errs = append(errs, fmt.Sprintf("invalid policy name %q", v))
+			}
+		}
+	case webRTC:
+		if len(d.values) != 1 || (d.values[0] != "'allow'" && d.values[0] != "'block'") {
+			errs = append(errs, "only one of 'allow' and 'block' is supported")
+		}
+	}
+	return errs
+}
+
+func serializeDirectives(ds []*directive, nonce string) string {
+	var b strings.Builder
+	for i, d := range ds {
+		if i > 0 {
+			b.WriteString("; ")
+		}
+		b.WriteString(d.name)
+		for _, v := range d.values {
+			b.WriteByte(' ')
+			b.WriteString(v)
+		}
+		if d.nonce {
+			b.WriteString(" 'nonce-")
+			b.WriteString(nonce)
+			b.WriteByte('\'')
+		}
+	}
+	return b.String()
+}
+
+// CustomPolicy is a Policy built by a PolicyBuilder.
+type CustomPolicy struct {
+	directives     []*directive
+	reportURIValue string
+}
+
+// Serialize serializes this policy for use in a Content-Security-Policy header
+// or in a Content-Security-Policy-Report-Only header, adding the nonce to the
+// directives configured with PolicyBuilder.Nonce.
+func (p *CustomPolicy) Serialize(nonce string, _ safehttp.InterceptorConfig) string {
+	return serializeDirectives(p.directives, nonce)
+}
+
+// String serializes the policy with a "{nonce}" placeholder for the nonces.
+func (p *CustomPolicy) String() string {
+	return serializeDirectives(p.directives, "{nonce}")
+}
+
+func (p *CustomPolicy) reportURI() string {
+	return p.reportURIValue
+}
+
+// Match returns false since there are no supported configurations. Custom
+// policies can be replaced on a route with Override.
+func (*CustomPolicy) Match(safehttp.InterceptorConfig) bool {
+	return false
+}
+
+// Overridden is never called, since Match returns false.
+func (*CustomPolicy) Overridden(safehttp.InterceptorConfig) (disabled, reportOnly bool) {
+	return false, false
+}
@@ -19,15 +19,23 @@
 //   - A strict nonce based CSP
 //   - A framing policy which sets frame-ancestors to 'self'
 //   - A Trusted Types policy which makes usage of dangerous web API functions secure by default
+//
+// The nonce of the response is added automatically to the <script> and <style>
+// tags of the templates loaded with the htmlinject package, including the
+// layouts and blocks of safehttp.ExecuteNamedTemplateWithLayout. Other
+// templates must add it with the CSPNonce function; NewNonceCheckDispatcher
+// helps finding and fixing the tags that miss it during local development.
+// Handlers only need Nonce to use it outside of templates, e.g. in
+// PreloadLink.
 package csp
 
-// TODO(empijei): add support for report-to and report groups.
-
 import (
 	"context"
 	"encoding/base64"
 	"errors"
 	"fmt"
+	"io"
+	"reflect"
 	"strings"
 
 	"github.com/google/go-safeweb/safehttp/plugins/csp/internalunsafecsp"
@@ -39,18 +47,24 @@ import (
 const (
 	responseHeaderKey           = "Content-Security-Policy"
 	responseHeaderReportOnlyKey = responseHeaderKey + "-Report-Only"
+	reportingEndpointsKey       = "Reporting-Endpoints"
 )
 
+// ReportingGroup is the name of the Reporting API endpoint used in the
+// report-to directive. If policies installed on the same mux report to
+// different URIs, the following endpoints are named ReportingGroup-1,
+// ReportingGroup-2 and so on.
+const ReportingGroup = "csp-endpoint"
+
 // nonceSize is the size of the nonces in bytes. According to the CSP3 spec it should
 // be larger than 16 bytes. 20 bytes was picked to be future proof.
 // https://www.w3.org/TR/CSP3/#security-nonces
 const nonceSize = 20
 
-func generateNonce() string {
+func generateNonce(rand io.Reader) string {
 	b := make([]byte, nonceSize)
-	_, err := internalunsafecsp.RandReader.Read(b)
-	if err != nil {
-		panic(fmt.Errorf("failed to generate entropy using crypto/rand/RandReader: %v", err))
+	if _, err := io.ReadFull(rand, b); err != nil {
+		panic(fmt.Errorf("failed to generate the CSP nonce: %v", err))
 	}
 	return base64.StdEncoding.EncodeToString(b)
 }
@@ -58,8 +72,9 @@ func generateNonce() string {
 type key string
 
 const (
-	nonceKey   key = "csp-nonce"
-	headersKey key = "csp-headers"
+	nonceKey     key = "csp-nonce"
+	headersKey   key = "csp-headers"
+	endpointsKey key = "csp-endpoints"
 )
 
 // Nonce retrieves the nonce from the given context. If there is no nonce stored
@@ -72,13 +87,34 @@ func Nonce(ctx context.Context) (string, error) {
 	return v.(string), nil
 }
 
+// PreloadLink returns a Link header value which preloads the resource at url
+// with the CSP nonce of the request, so that nonce-based policies don't block
+// it, e.g.
+//
+//	link, err := csp.PreloadLink(r.Context(), "/static/app.js", "script")
+//	...
+//	w.SendEarlyHints(link)
+//
+// The as parameter is the destination of the resource, e.g. "script" or
+// "style".
+func PreloadLink(ctx context.Context, url, as string) (string, error) {
+	n, err := Nonce(ctx)
+	if err != nil {
+		return "", err
+	}
+	if strings.ContainsAny(url, "<>\r\n") || strings.ContainsAny(as, ";,\"\r\n ") {
+		return "", fmt.Errorf("invalid preload link for %q as %q", url, as)
+	}
+	return fmt.Sprintf("<%s>; rel=preload; as=%s; nonce=%s", url, as, n), nil
+}
+
 // nonce retrieves the nonces from the request.
-// If none is available, one will be generated and added to it.
-func nonce(r *safehttp.IncomingRequest) string {
+// If none is available, one will be generated from rand and added to it.
+func nonce(r *safehttp.IncomingRequest, rand io.Reader) string {
 	v := safehttp.FlightValues(r.Context()).Get(nonceKey)
 	var nonce string
 	if v == nil {
-		nonce = generateNonce()
+		nonce = generateNonce(rand)
 		safehttp.FlightValues(r.Context()).Put(nonceKey, nonce)
 	} else {
 		nonce = v.(string)
@@ -104,6 +140,51 @@ func claimedHeaders(w safehttp.ResponseWriter, r *safehttp.IncomingRequest) (csp
 	return c.cspe, c.cspro
 }
 
+type reportingEndpoints struct {
+	set     func([]string)
+	groups  map[string]string
+	entries []string
+}
+
+// reportingGroup returns the name of the Reporting API endpoint for the given
+// report URI, adding it to the Reporting-Endpoints header if needed.
+func reportingGroup(w safehttp.ResponseWriter, r *safehttp.IncomingRequest, reportURI string) string {
+	fv := safehttp.FlightValues(r.Context())
+	v := fv.Get(endpointsKey)
+	if v == nil {
+		v = &reportingEndpoints{
+			set:    w.Header().Claim(reportingEndpointsKey),
+			groups: map[string]string{},
+		}
+		fv.Put(endpointsKey, v)
+	}
+	e := v.(*reportingEndpoints)
+	if g, ok := e.groups[reportURI]; ok {
+		return g
+	}
+	g := ReportingGroup
+	if n := len(e.groups); n > 0 {
+		g = fmt.Sprintf("%s-%d", ReportingGroup, n)
+	}
+	e.groups[reportURI] = g
+	e.entries = append(e.entries, fmt.Sprintf("%s=%q", g, reportURI))
+	e.set([]string{strings.Join(e.entries, ", ")})
+	return g
+}
+
+// reportingPolicy is implemented by policies that send violation reports to a
+// report URI.
+type reportingPolicy interface {
+	reportURI() string
+}
+
+func withReportTo(policy, group string) string {
+	if policy == "" {
+		return ""
+	}
+	return strings.TrimSuffix(policy, ";") + "; report-to " + group
+}
+
 // Policy defines a CSP policy.
 type Policy interface {
 	// Serialize serializes this policy for use in a Content-Security-Policy header
@@ -136,6 +217,18 @@ type Interceptor struct {
 	Policy Policy
 	// ReportOnly makes Policy be set report-only.
 	ReportOnly bool
+	// ReportingAPI makes violations also be reported through the Reporting
+	// API. If the Policy has a report URI, the Reporting-Endpoints header is
+	// set and a report-to directive pointing to the same endpoint is added
+	// next to the legacy report-uri one, which is kept for browsers that don't
+	// support the Reporting API. Both kinds of reports can be collected
+	// with collector.CSPHandler.
+	ReportingAPI bool
+	// Rand is the source of the nonces. If nil, safehttp.SystemRand is used,
+	// unless replaced by the unsafecspfortests package. The nonce of a
+	// request is shared by all the CSP interceptors: it's generated by the
+	// first one that runs.
+	Rand io.Reader
 }
 
 var _ safehttp.Interceptor = Interceptor{}
@@ -150,7 +243,58 @@ func Default(reportURI string) []Interceptor {
 	}
 }
 
+func (it Interceptor) random() io.Reader {
+	if it.Rand != nil {
+		return it.Rand
+	}
+	if internalunsafecsp.RandReader != nil {
+		return internalunsafecsp.RandReader
+	}
+	return safehttp.SystemRand()
+}
+
+// Override is a route configuration which replaces the policy of the
+// interceptors enforcing a policy of the same type, to tighten or relax it for
+// a single route, e.g. to allow eval() only on a legacy page:
+//
+//	mux.Handle("/admin", safehttp.MethodGet, h, csp.Override{Policy: csp.StrictPolicy{UnsafeEval: true}})
+//
+// Relaxing a policy should require a security review. Framing should be
+// allowed with the unsafeframing package instead, which also relaxes the other
+// framing protections.
+type Override struct {
+	// Policy replaces the policy of the interceptor for the route.
+	Policy Policy
+	// ReportOnly makes Policy be set report-only for the route.
+	ReportOnly bool
+}
+
+// overrides reports whether cfg is an Override of the policy of the
+// interceptor.
+func (it Interceptor) overrides(cfg safehttp.InterceptorConfig) (Override, bool) {
+	o, ok := cfg.(Override)
+	if !ok || o.Policy == nil || reflect.TypeOf(o.Policy) != reflect.TypeOf(it.Policy) {
+		return Override{}, false
+	}
+	return o, true
+}
+
+// policy returns the policy the interceptor enforces for the route.
+func (it Interceptor) policy(cfg safehttp.InterceptorConfig) Policy {
+	if o, ok := it.overrides(cfg); ok {
+		return o.Policy
+	}
+	return it.Policy
+}
+
 func (it Interceptor) processOverride(cfg safehttp.InterceptorConfig, nonce string) (enf, ro string) {
+	if o, ok := it.overrides(cfg); ok {
+		p := o.Policy.Serialize(nonce, nil)
+		if o.ReportOnly || it.ReportOnly {
+			return "", p
+		}
+		return p, ""
+	}
 	disabled, reportOnly := false, false
 	if it.Policy.Match(cfg) {
 		disabled, reportOnly = it.Policy.Overridden(cfg)
@@ -166,10 +310,17 @@ func (it Interceptor) processOverride(cfg safehttp.InterceptorConfig, nonce stri
 }
 
 // Before claims and sets the Content-Security-Policy header and the
-// Content-Security-Policy-Report-Only header.
+// Content-Security-Policy-Report-Only header. If ReportingAPI is enabled, it
+// also claims and sets the Reporting-Endpoints header.
 func (it Interceptor) Before(w safehttp.ResponseWriter, r *safehttp.IncomingRequest, cfg safehttp.InterceptorConfig) safehttp.Result {
-	nonce := nonce(r)
+	nonce := nonce(r, it.random())
 	enf, ro := it.processOverride(cfg, nonce)
+	if it.ReportingAPI && (enf != "" || ro != "") {
+		if rp, ok := it.policy(cfg).(reportingPolicy); ok && rp.reportURI() != "" {
+			group := reportingGroup(w, r, rp.reportURI())
+			enf, ro = withReportTo(enf, group), withReportTo(ro, group)
+		}
+	}
 	setCSP, setCSPReportOnly := claimedHeaders(w, r)
 	if enf != "" {
 		prev := w.Header().Values(responseHeaderKey)
@@ -186,7 +337,7 @@ func (it Interceptor) Before(w safehttp.ResponseWriter, r *safehttp.IncomingRequ
 // injected as the value of the nonce attribute in <script> and <link> tags. The
 // nonce is going to be unique for each safehttp.IncomingRequest.
 func (it Interceptor) Commit(w safehttp.ResponseHeadersWriter, r *safehttp.IncomingRequest, resp safehttp.Response, cfg safehttp.InterceptorConfig) {
-	tmplResp, ok := resp.(*safehttp.TemplateResponse)
+	tmplResp, ok := safehttp.TemplateOf(resp)
 	if !ok {
 		return
 	}
@@ -204,7 +355,11 @@ func (it Interceptor) Commit(w safehttp.ResponseHeadersWriter, r *safehttp.Incom
 	tmplResp.FuncMap[htmlinject.CSPNoncesDefaultFuncName] = func() string { return nonce }
 }
 
-// Match returns false since there are no supported configurations.
+// Match matches the configurations of the Policy, and the Overrides of a
+// policy of the same type.
 func (it Interceptor) Match(cfg safehttp.InterceptorConfig) bool {
+	if _, ok := it.overrides(cfg); ok {
+		return true
+	}
 	return it.Policy.Match(cfg)
 }
@@ -15,7 +15,10 @@
 package csp
 
 import (
+	"bytes"
+	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -28,6 +31,8 @@ import (
 	"github.com/google/go-safeweb/safehttp/plugins/framing/internalunsafeframing"
 	"github.com/google/go-safeweb/safehttp/plugins/framing/internalunsafeframing/unsafeframing"
 	"github.com/google/go-safeweb/safehttp/safehttptest"
+	"github.com/google/safehtml/template"
+	"github.com/google/safehtml/template/uncheckedconversions"
 )
 
 func TestMain(m *testing.M) {
@@ -101,6 +106,21 @@ func TestSerialize(t *testing.T) {
 			policy:     TrustedTypesPolicy{ReportURI: "httsp://example.com/collector"},
 			wantString: "require-trusted-types-for 'script'; report-uri httsp://example.com/collector",
 		},
+		{
+			name:       "TrustedTypesCSP with policies",
+			policy:     TrustedTypesPolicy{Policies: []string{"default", "dompurify#1"}, ReportURI: "https://example.com/collector"},
+			wantString: "require-trusted-types-for 'script'; trusted-types default dompurify#1; report-uri https://example.com/collector",
+		},
+		{
+			name:       "TrustedTypesCSP with duplicate policies",
+			policy:     TrustedTypesPolicy{Policies: []string{"default"}, AllowDuplicates: true},
+			wantString: "require-trusted-types-for 'script'; trusted-types default 'allow-duplicates'",
+		},
+		{
+			name:       "TrustedTypesCSP without policies",
+			policy:     TrustedTypesPolicy{Policies: []string{}, AllowDuplicates: true},
+			wantString: "require-trusted-types-for 'script'; trusted-types 'none'",
+		},
 	}
 
 	for _, tt := range tests {
@@ -203,9 +223,90 @@ func TestBefore(t *testing.T) {
 	}
 }
 
+func TestBeforeReportingAPI(t *testing.T) {
+	tests := []struct {
+		name                   string
+		interceptors           []Interceptor
+		wantEnforcePolicy      []string
+		wantReportOnlyPolicy   []string
+		wantReportingEndpoints []string
+	}{
+		{
+			name: "Reporting API",
+			interceptors: []Interceptor{
+				{Policy: StrictPolicy{ReportURI: "https://example.com/collector"}, ReportingAPI: true},
+				{Policy: TrustedTypesPolicy{ReportURI: "https://example.com/collector"}, ReportingAPI: true},
+			},
+			wantEnforcePolicy: []string{
+				"object-src 'none'; script-src 'unsafe-inline' 'nonce-KSkpKSkpKSkpKSkpKSkpKSkpKSk=' 'strict-dynamic' https: http:; base-uri 'none'; report-uri https://example.com/collector; report-to csp-endpoint",
+				"require-trusted-types-for 'script'; report-uri https://example.com/collector; report-to csp-endpoint",
+			},
+			wantReportingEndpoints: []string{`csp-endpoint="https://example.com/collector"`},
+		},
+		{
+			name: "Reporting API, report only",
+			interceptors: []Interceptor{
+				{Policy: FramingPolicy{ReportURI: "https://example.com/collector"}, ReportOnly: true, ReportingAPI: true},
+			},
+			wantReportOnlyPolicy: []string{
+				"frame-ancestors 'self'; report-uri https://example.com/collector; report-to csp-endpoint",
+			},
+			wantReportingEndpoints: []string{`csp-endpoint="https://example.com/collector"`},
+		},
+		{
+			name: "Reporting API, different report URIs",
+			interceptors: []Interceptor{
+				{Policy: StrictPolicy{ReportURI: "https://example.com/strict"}, ReportingAPI: true},
+				{Policy: TrustedTypesPolicy{ReportURI: "https://example.com/tt"}, ReportingAPI: true},
+			},
+			wantEnforcePolicy: []string{
+				"object-src 'none'; script-src 'unsafe-inline' 'nonce-KSkpKSkpKSkpKSkpKSkpKSkpKSk=' 'strict-dynamic' https: http:; base-uri 'none'; report-uri https://example.com/strict; report-to csp-endpoint",
+				"require-trusted-types-for 'script'; report-uri https://example.com/tt; report-to csp-endpoint-1",
+			},
+			wantReportingEndpoints: []string{`csp-endpoint="https://example.com/strict", csp-endpoint-1="https://example.com/tt"`},
+		},
+		{
+			name: "Reporting API without report URI",
+			interceptors: []Interceptor{
+				{Policy: TrustedTypesPolicy{}, ReportingAPI: true},
+			},
+			wantEnforcePolicy: []string{"require-trusted-types-for 'script'"},
+		},
+		{
+			name: "Legacy only",
+			interceptors: []Interceptor{
+				{Policy: TrustedTypesPolicy{ReportURI: "https://example.com/collector"}},
+			},
+			wantEnforcePolicy: []string{"require-trusted-types-for 'script'; report-uri https://example.com/collector"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeRW, rr := safehttptest.NewFakeResponseWriter()
+			req := safehttptest.NewRequest(safehttp.MethodGet, "/", nil)
+
+			for _, i := range tt.interceptors {
+				i.Before(fakeRW, req, nil)
+			}
+
+			h := rr.Header()
+			if diff := cmp.Diff(tt.wantEnforcePolicy, h.Values("Content-Security-Policy"), cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("h.Values(\"Content-Security-Policy\") mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tt.wantReportOnlyPolicy, h.Values("Content-Security-Policy-Report-Only"), cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("h.Values(\"Content-Security-Policy-Report-Only\") mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tt.wantReportingEndpoints, h.Values("Reporting-Endpoints"), cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("h.Values(\"Reporting-Endpoints\") mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
 func TestValidNonce(t *testing.T) {
 	req := safehttptest.NewRequest(safehttp.MethodGet, "https://foo.com/pizza", nil)
-	_ = nonce(req)
+	_ = nonce(req, internalunsafecsp.RandReader)
 
 	n, err := Nonce(req.Context())
 	if err != nil {
@@ -217,6 +318,60 @@ func TestValidNonce(t *testing.T) {
 	}
 }
 
+func TestInterceptorRand(t *testing.T) {
+	fakeRW, _ := safehttptest.NewFakeResponseWriter()
+	req := safehttptest.NewRequest(safehttp.MethodGet, "https://foo.com/pizza", nil)
+	it := Interceptor{Policy: StrictPolicy{}, Rand: bytes.NewReader(bytes.Repeat([]byte{1}, nonceSize))}
+	it.Before(fakeRW, req, nil)
+
+	n, err := Nonce(req.Context())
+	if err != nil {
+		t.Fatalf("Nonce(ctx) got err: %v want: nil", err)
+	}
+	if want := "AQEBAQEBAQEBAQEBAQEBAQEBAQE="; n != want {
+		t.Errorf("Nonce(ctx) got nonce: %v want: %v", n, want)
+	}
+}
+
+func TestInterceptorSystemRand(t *testing.T) {
+	static := internalunsafecsp.RandReader
+	internalunsafecsp.RandReader = nil
+	defer func() { internalunsafecsp.RandReader = static }()
+
+	fakeRW, _ := safehttptest.NewFakeResponseWriter()
+	req := safehttptest.NewRequest(safehttp.MethodGet, "https://foo.com/pizza", nil)
+	it := Interceptor{Policy: StrictPolicy{}}
+	it.Before(fakeRW, req, nil)
+
+	n, err := Nonce(req.Context())
+	if err != nil {
+		t.Fatalf("Nonce(ctx) got err: %v want: nil", err)
+	}
+	if static := "KSkpKSkpKSkpKSkpKSkpKSkpKSk="; n == "" || n == static {
+		t.Errorf("Nonce(ctx) got nonce: %q, want a random one", n)
+	}
+}
+
+func TestPreloadLink(t *testing.T) {
+	req := safehttptest.NewRequest(safehttp.MethodGet, "https://foo.com/pizza", nil)
+	_ = nonce(req, internalunsafecsp.RandReader)
+
+	got, err := PreloadLink(req.Context(), "/static/app.js", "script")
+	if err != nil {
+		t.Fatalf("PreloadLink got err: %v want: nil", err)
+	}
+	if want := "</static/app.js>; rel=preload; as=script; nonce=KSkpKSkpKSkpKSkpKSkpKSkpKSk="; got != want {
+		t.Errorf("PreloadLink got: %v want: %v", got, want)
+	}
+
+	if _, err := PreloadLink(req.Context(), "/a.js>; rel=stylesheet", "script"); err == nil {
+		t.Error("PreloadLink with an invalid URL got err: nil want: error")
+	}
+	if _, err := PreloadLink(safehttptest.NewRequest(safehttp.MethodGet, "https://foo.com/pizza", nil).Context(), "/app.js", "script"); err == nil {
+		t.Error("PreloadLink without a nonce got err: nil want: error")
+	}
+}
+
 func TestNonceEmptyContext(t *testing.T) {
 	req := safehttptest.NewRequest(safehttp.MethodGet, "https://foo.com/pizza", nil)
 	// Not using nonce() to insert the nonce in context.
@@ -362,6 +517,71 @@ func TestOverride(t *testing.T) {
 			wantEnforcePolicy: []string{
 				"frame-ancestors 'self' https://a.example.org https://b.example.org;"},
 		},
+		{
+			name:         "Strict policy relaxed",
+			interceptors: Default(""),
+			overrides: []safehttp.InterceptorConfig{
+				Override{Policy: StrictPolicy{UnsafeEval: true}},
+			},
+			wantEnforcePolicy: []string{
+				"object-src 'none'; script-src 'unsafe-inline' 'nonce-KSkpKSkpKSkpKSkpKSkpKSkpKSk=' 'strict-dynamic' https: http: 'unsafe-eval'; base-uri 'none'",
+				"require-trusted-types-for 'script'",
+			},
+		},
+		{
+			name:         "Strict policy tightened, report-only",
+			interceptors: Default(""),
+			overrides: []safehttp.InterceptorConfig{
+				Override{Policy: StrictPolicy{NoStrictDynamic: true}, ReportOnly: true},
+			},
+			wantEnforcePolicy: []string{"require-trusted-types-for 'script'"},
+			wantReportOnlyPolicy: []string{
+				"object-src 'none'; script-src 'unsafe-inline' 'nonce-KSkpKSkpKSkpKSkpKSkpKSkpKSk='; base-uri 'none'",
+			},
+		},
+		{
+			name:         "Override and disable different policies",
+			interceptors: Default(""),
+			overrides: []safehttp.InterceptorConfig{
+				Override{Policy: TrustedTypesPolicy{Policies: []string{"default"}}},
+				internalunsafecsp.DisableStrict{SkipReports: true},
+			},
+			wantEnforcePolicy: []string{"require-trusted-types-for 'script'; trusted-types default"},
+		},
+		{
+			name:         "Override of a policy not installed",
+			interceptors: []Interceptor{{Policy: StrictPolicy{}}},
+			overrides: []safehttp.InterceptorConfig{
+				Override{Policy: TrustedTypesPolicy{}},
+			},
+			wantEnforcePolicy: []string{
+				"object-src 'none'; script-src 'unsafe-inline' 'nonce-KSkpKSkpKSkpKSkpKSkpKSkpKSk=' 'strict-dynamic' https: http:; base-uri 'none'",
+			},
+		},
+		{
+			name:         "TrustedTypes route policies",
+			interceptors: []Interceptor{{Policy: TrustedTypesPolicy{Policies: []string{"default"}}}},
+			overrides: []safehttp.InterceptorConfig{
+				TrustedTypes{Policies: []string{"dompurify", "*"}},
+			},
+			wantEnforcePolicy: []string{"require-trusted-types-for 'script'; trusted-types default dompurify *"},
+		},
+		{
+			name:         "TrustedTypes route policies without directive",
+			interceptors: []Interceptor{{Policy: TrustedTypesPolicy{}}},
+			overrides: []safehttp.InterceptorConfig{
+				TrustedTypes{Policies: []string{"dompurify"}},
+			},
+			wantEnforcePolicy: []string{"require-trusted-types-for 'script'; trusted-types dompurify"},
+		},
+		{
+			name:         "TrustedTypes route report-only",
+			interceptors: []Interceptor{{Policy: TrustedTypesPolicy{Policies: []string{"default"}}}},
+			overrides: []safehttp.InterceptorConfig{
+				TrustedTypes{ReportOnly: true},
+			},
+			wantReportOnlyPolicy: []string{"require-trusted-types-for 'script'; trusted-types default"},
+		},
 	}
 
 	for _, tt := range tests {
@@ -393,3 +613,349 @@ func TestOverride(t *testing.T) {
 		})
 	}
 }
+
+func setLocalDev(t *testing.T, dev bool) {
+	old := isLocalDev
+	isLocalDev = func() bool { return dev }
+	t.Cleanup(func() { isLocalDev = old })
+}
+
+func nonceCheckResponse(t *testing.T, src string) *safehttp.TemplateResponse {
+	t.Helper()
+	tmpl, err := template.New("test").Funcs(template.FuncMap{"CSPNonce": func() string { return "" }, "XSRFToken": func() string { return "" }}).
+		ParseFromTrustedTemplate(uncheckedconversions.TrustedTemplateFromStringKnownToSatisfyTypeContract(src))
+	if err != nil {
+		t.Fatalf("template.Parse(%q) got err: %v", src, err)
+	}
+	return &safehttp.TemplateResponse{Template: tmpl}
+}
+
+func TestNonceCheckDispatcher(t *testing.T) {
+	tests := []struct {
+		name       string
+		check      NonceCheck
+		dev        bool
+		template   string
+		wantStatus int
+		wantOutput string
+	}{
+		{
+			name:       "Compliant",
+			check:      NonceCheckFail,
+			dev:        true,
+			template:   `<script nonce="{{CSPNonce}}">a()</script><script src="/b.js" nonce="{{CSPNonce}}"></script>`,
+			wantOutput: `<script nonce="pizza">a()</script><script src="/b.js" nonce="pizza"></script>`,
+		},
+		{
+			name:       "Missing nonce, fail in dev",
+			check:      NonceCheckFail,
+			dev:        true,
+			template:   `<p>Hi</p><script nonce="{{CSPNonce}}">a()</script><script>b()</script>`,
+			wantStatus: int(safehttp.StatusInternalServerError),
+			wantOutput: "Internal Server Error\n",
+		},
+		{
+			name:       "Wrong nonce, fail in dev",
+			check:      NonceCheckFail,
+			dev:        true,
+			template:   `<script nonce="pasta">a()</script>`,
+			wantStatus: int(safehttp.StatusInternalServerError),
+			wantOutput: "Internal Server Error\n",
+		},
+		{
+			name:       "Missing nonce, fail in production",
+			check:      NonceCheckFail,
+			template:   `<script>b()</script>`,
+			wantOutput: `<script>b()</script>`,
+		},
+		{
+			name:       "Missing nonce, no check",
+			check:      NoNonceCheck,
+			dev:        true,
+			template:   `<script>b()</script>`,
+			wantOutput: `<script>b()</script>`,
+		},
+		{
+			name:       "Inject",
+			check:      NonceCheckInject,
+			dev:        true,
+			template:   `<p>Hi</p><SCRIPT src="/a.js"></SCRIPT><script nonce="{{CSPNonce}}">b()</script><script>c()</script>`,
+			wantOutput: `<p>Hi</p><script nonce="pizza" src="/a.js"></SCRIPT><script nonce="pizza">b()</script><script nonce="pizza">c()</script>`,
+		},
+		{
+			name:       "Inject doesn't change wrong nonces",
+			check:      NonceCheckInject,
+			dev:        true,
+			template:   `<script nonce="pasta">a()</script>`,
+			wantOutput: `<script nonce="pasta">a()</script>`,
+		},
+		{
+			name:       "Inject in production",
+			check:      NonceCheckInject,
+			template:   `<script>c()</script>`,
+			wantOutput: `<script>c()</script>`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setLocalDev(t, tt.dev)
+			rr := httptest.NewRecorder()
+			rr.Header().Set("Content-Security-Policy", "script-src 'nonce-pizza'")
+			resp := nonceCheckResponse(t, tt.template)
+			tmpl := resp.Template
+			resp.FuncMap = map[string]interface{}{"CSPNonce": func() string { return "pizza" }}
+
+			if err := NewNonceCheckDispatcher(nil, tt.check).Write(rr, resp); err != nil {
+				t.Fatalf("Write() got err: %v", err)
+			}
+
+			wantStatus := tt.wantStatus
+			if wantStatus == 0 {
+				wantStatus = int(safehttp.StatusOK)
+			}
+			if got := rr.Code; got != wantStatus {
+				t.Errorf("rr.Code: got %v, want %v", got, wantStatus)
+			}
+			if got := rr.Body.String(); got != tt.wantOutput {
+				t.Errorf("rr.Body: got %q, want %q", got, tt.wantOutput)
+			}
+			if resp.Template != tmpl || resp.FuncMap == nil {
+				t.Error("the response was modified")
+			}
+		})
+	}
+}
+
+// xsrfFuncInterceptor provides the XSRFToken function to the templates in the
+// Commit phase, like xsrfhtml.Interceptor.
+type xsrfFuncInterceptor struct{}
+
+func (xsrfFuncInterceptor) Before(safehttp.ResponseWriter, *safehttp.IncomingRequest, safehttp.InterceptorConfig) safehttp.Result {
+	return safehttp.NotWritten()
+}
+
+func (xsrfFuncInterceptor) Commit(_ safehttp.ResponseHeadersWriter, _ *safehttp.IncomingRequest, resp safehttp.Response, _ safehttp.InterceptorConfig) {
+	if t, ok := safehttp.TemplateOf(resp); ok {
+		if t.FuncMap == nil {
+			t.FuncMap = map[string]interface{}{}
+		}
+		t.FuncMap["XSRFToken"] = func() string { return "token" }
+	}
+}
+
+func (xsrfFuncInterceptor) Match(safehttp.InterceptorConfig) bool {
+	return false
+}
+
+func TestNonceCheckAfterCommits(t *testing.T) {
+	tests := []struct {
+		name       string
+		template   string
+		wantStatus int
+	}{
+		{
+			name:       "Compliant",
+			template:   `<form><input value="{{XSRFToken}}"></form><script nonce="{{CSPNonce}}">a()</script>`,
+			wantStatus: int(safehttp.StatusOK),
+		},
+		{
+			name:       "Missing nonce",
+			template:   `<form><input value="{{XSRFToken}}"></form><script>a()</script>`,
+			wantStatus: int(safehttp.StatusInternalServerError),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setLocalDev(t, true)
+			resp := nonceCheckResponse(t, tt.template)
+			// The XSRF interceptor runs its Commit after the CSP one.
+			mb := safehttp.NewServeMuxConfig(NewNonceCheckDispatcher(nil, NonceCheckFail))
+			mb.Intercept(xsrfFuncInterceptor{}, Interceptor{Policy: StrictPolicy{}})
+			mux := mb.Mux()
+			mux.Handle("/", safehttp.MethodGet, safehttp.HandlerFunc(func(w safehttp.ResponseWriter, r *safehttp.IncomingRequest) safehttp.Result {
+				return w.Write(resp)
+			}))
+
+			rr := httptest.NewRecorder()
+			mux.ServeHTTP(rr, httptest.NewRequest(safehttp.MethodGet, "https://foo.com/", nil))
+
+			if got := rr.Code; got != tt.wantStatus {
+				t.Errorf("rr.Code: got %v, want %v", got, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestTrustedTypesInvalidPolicyName(t *testing.T) {
+	for _, name := range []string{"", "a b", "a;script-src *", "'none'"} {
+		t.Run(name, func(t *testing.T) {
+			defer func() {
+				if r := recover(); r == nil {
+					t.Errorf("TrustedTypesPolicy{}.Match(TrustedTypes{Policies: %q}) expected panic", name)
+				}
+			}()
+			TrustedTypesPolicy{}.Match(TrustedTypes{Policies: []string{name}})
+		})
+	}
+}
+
+func TestPolicyBuilder(t *testing.T) {
+	tests := []struct {
+		name    string
+		builder *PolicyBuilder
+		want    string
+	}{
+		{
+			name: "Directives",
+			builder: NewPolicyBuilder().
+				Directive("default-src", "'self'").
+				Directive("img-src", "'self'", "https://cdn.example.com", "data:").
+				Directive("IMG-SRC", "*.example.org:443").
+				Nonce("script-src").
+				Directive("script-src", "'STRICT-DYNAMIC'", "'sha256-CihokcEcBW4atb/CW/XWsvWwbTjqwQlE9nj9ii5ww5M='").
+				Directive("object-src", "'none'").
+				Directive("frame-ancestors", "'self'", "https://a.example.org").
+				Directive("upgrade-insecure-requests").
+				Directive("sandbox", "allow-scripts", "allow-forms").
+				Directive("require-trusted-types-for", "'script'").
+				Directive("trusted-types", "default", "'allow-duplicates'").
+				Directive("report-uri", "https://example.com/collector").
+				Directive("report-to", "csp-endpoint"),
+			want: "default-src 'self'; img-src 'self' https://cdn.example.com data: *.example.org:443; " +
+				"script-src 'strict-dynamic' 'sha256-CihokcEcBW4atb/CW/XWsvWwbTjqwQlE9nj9ii5ww5M=' 'nonce-super-secret'; " +
+				"object-src 'none'; frame-ancestors 'self' https://a.example.org; upgrade-insecure-requests; " +
+				"sandbox allow-scripts allow-forms; require-trusted-types-for 'script'; trusted-types default 'allow-duplicates'; " +
+				"report-uri https://example.com/collector; report-to csp-endpoint",
+		},
+		{
+			name:    "Parsed",
+			builder: ParsePolicy("object-src 'none';  script-src 'unsafe-inline' https: ; base-uri 'self';").Nonce("script-src"),
+			want:    "object-src 'none'; script-src 'unsafe-inline' https: 'nonce-super-secret'; base-uri 'self'",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := tt.builder.Build()
+			if err != nil {
+				t.Fatalf("Build() got err: %v", err)
+			}
+			if got := p.Serialize("super-secret", nil); got != tt.want {
+				t.Errorf("p.Serialize() got: %q want: %q", got, tt.want)
+			}
+			want := strings.ReplaceAll(tt.want, "super-secret", "{nonce}")
+			if got := p.String(); got != want {
+				t.Errorf("p.String() got: %q want: %q", got, want)
+			}
+			if got := tt.builder.String(); got != want {
+				t.Errorf("tt.builder.String() got: %q want: %q", got, want)
+			}
+		})
+	}
+}
+
+func TestPolicyBuilderErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		builder *PolicyBuilder
+		wantErr string
+	}{
+		{
+			name:    "Typo",
+			builder: NewPolicyBuilder().Directive("script-scr", "'self'"),
+			wantErr: `unknown directive "script-scr"`,
+		},
+		{
+			name:    "Missing quotes",
+			builder: NewPolicyBuilder().Directive("default-src", "self"),
+			wantErr: "default-src: keyword self must be quoted: 'self'",
+		},
+		{
+			name:    "Conflicting keywords",
+			builder: NewPolicyBuilder().Directive("img-src", "'none'", "https://cdn.example.com"),
+			wantErr: "img-src: 'none' can't be combined with other sources",
+		},
+		{
+			name:    "'none' and nonce",
+			builder: NewPolicyBuilder().Directive("script-src", "'none'").Nonce("script-src"),
+			wantErr: "script-src: 'none' can't be combined with other sources",
+		},
+		{
+			name:    "Unknown keyword",
+			builder: NewPolicyBuilder().Directive("script-src", "'unsafe-everything'"),
+			wantErr: "script-src: unknown keyword 'unsafe-everything'",
+		},
+		{
+			name:    "Static nonce",
+			builder: ParsePolicy("script-src 'nonce-abc'"),
+			wantErr: "script-src: nonces can't be static, use Nonce",
+		},
+		{
+			name:    "Unsupported nonce",
+			builder: NewPolicyBuilder().Nonce("img-src"),
+			wantErr: "img-src: nonces are not supported",
+		},
+		{
+			name:    "Empty source list",
+			builder: NewPolicyBuilder().Directive("object-src"),
+			wantErr: "object-src: empty source list, use 'none'",
+		},
+		{
+			name:    "Invalid source",
+			builder: NewPolicyBuilder().Directive("img-src", "https://example.com,evil.com"),
+			wantErr: `img-src: invalid source "https://example.com,evil.com"`,
+		},
+		{
+			name:    "Unsupported keyword",
+			builder: NewPolicyBuilder().Directive("frame-ancestors", "'unsafe-inline'"),
+			wantErr: "frame-ancestors: keyword 'unsafe-inline' is not supported",
+		},
+		{
+			name:    "Unexpected values",
+			builder: NewPolicyBuilder().Directive("upgrade-insecure-requests", "'self'"),
+			wantErr: "upgrade-insecure-requests: no values are allowed",
+		},
+		{
+			name:    "Invalid sandbox flag",
+			builder: NewPolicyBuilder().Directive("sandbox", "scripts"),
+			wantErr: `sandbox: invalid sandbox flag "scripts"`,
+		},
+		{
+			name:    "Invalid trusted types",
+			builder: NewPolicyBuilder().Directive("require-trusted-types-for", "'style'"),
+			wantErr: "require-trusted-types-for: only 'script' is supported",
+		},
+		{
+			name:    "Multiple errors",
+			builder: NewPolicyBuilder().Directive("script-scr", "'self'").Directive("default-src", "none"),
+			wantErr: `unknown directive "script-scr"; default-src: keyword none must be quoted: 'none'`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := tt.builder.Build()
+			if err == nil {
+				t.Fatalf("Build() got: %q, want error", p)
+			}
+			if want := "invalid CSP: " + tt.wantErr; err.Error() != want {
+				t.Errorf("Build() got err: %q want: %q", err.Error(), want)
+			}
+		})
+	}
+}
+
+func TestCustomPolicyReportingAPI(t *testing.T) {
+	fakeRW, rr := safehttptest.NewFakeResponseWriter()
+	req := safehttptest.NewRequest(safehttp.MethodGet, "/", nil)
+
+	p := NewPolicyBuilder().
+		Nonce("script-src").
+		Directive("report-uri", "https://example.com/collector").
+		MustBuild()
+	Interceptor{Policy: p, ReportingAPI: true}.Before(fakeRW, req, nil)
+
+	want := []string{"script-src 'nonce-KSkpKSkpKSkpKSkpKSkpKSkpKSk='; report-uri https://example.com/collector; report-to csp-endpoint"}
+	if diff := cmp.Diff(want, rr.Header().Values("Content-Security-Policy")); diff != "" {
+		t.Errorf("rr.Header().Values(\"Content-Security-Policy\") mismatch (-want +got):\n%s", diff)
+	}
+}
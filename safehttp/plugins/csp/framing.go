@@ -47,6 +47,10 @@ func (f FramingPolicy) Serialize(nonce string, cfg safehttp.InterceptorConfig) s
 	return strings.TrimSpace(b.String())
 }
 
+func (f FramingPolicy) reportURI() string {
+	return f.ReportURI
+}
+
 // Match matches strict policies overrides.
 func (FramingPolicy) Match(cfg safehttp.InterceptorConfig) bool {
 	switch cfg.(type) {
@@ -15,10 +15,11 @@
 // Package internalunsafecsp is used internally to override CSP.
 package internalunsafecsp
 
-import "crypto/rand"
+import "io"
 
-// RandReader is a source of random.
-var RandReader = rand.Reader
+// RandReader, if non-nil, replaces safehttp.SystemRand as the default source
+// of the CSP nonces. It's only set by the unsafecspfortests package.
+var RandReader io.Reader
 
 // DisableTrustedTypes switches TT to report-only.
 type DisableTrustedTypes struct {
@@ -0,0 +1,222 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package csp
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/google/go-safeweb/safehttp"
+	"github.com/google/safehtml/template"
+	"github.com/google/safehtml/uncheckedconversions"
+	"golang.org/x/net/html"
+)
+
+// NonceCheck configures whether the <script> tags in template responses are
+// checked for the CSP nonce of the request. See NewNonceCheckDispatcher.
+//
+// Scripts without the nonce are blocked by the strict policy, so forgetting to
+// add it to a template silently breaks the page. Checking requires rendering
+// the templates twice, which is why it's only done in local development.
+type NonceCheck int
+
+const (
+	// NoNonceCheck disables the check.
+	NoNonceCheck NonceCheck = iota
+	// NonceCheckFail replaces the response with a 500 Internal Server Error
+	// if a <script> tag doesn't carry the nonce, and logs the offending tags.
+	// This only happens in local development mode (see safehttp.UseLocalDev):
+	// in production the check is disabled.
+	NonceCheckFail
+	// NonceCheckInject adds the nonce to <script> tags that don't have a
+	// nonce attribute. This only happens in local development mode (see
+	// safehttp.UseLocalDev): in production the check is disabled.
+	//
+	// Injecting the nonce in production would defeat its purpose, since it
+	// would also be added to scripts which ended up in the response without
+	// the developer's intent, e.g. through an unchecked conversion of user
+	// input to safehtml.HTML. It's only meant to get a page working while its
+	// templates are fixed.
+	NonceCheckInject
+)
+
+// isLocalDev is overridden in tests.
+var isLocalDev = safehttp.IsLocalDev
+
+// renderedTemplate is used to write the template responses to which
+// NonceCheckInject added the nonce.
+var renderedTemplate = template.Must(template.New("csp-rendered").Parse("{{.}}"))
+
+// NewNonceCheckDispatcher wraps the Dispatcher to check that the <script>
+// tags of the template responses carry the CSP nonce of the request, as
+// configured by c. If d is nil, safehttp.DefaultDispatcher is used.
+//
+//	mb := safehttp.NewServeMuxConfig(csp.NewNonceCheckDispatcher(nil, csp.NonceCheckFail))
+//
+// The check runs when the response is written, after the Commit phase of all
+// the interceptors, so that the templates using the functions they provide,
+// like XSRFToken, are checked as well. The nonce is read from the
+// Content-Security-Policy headers of the response: responses without a nonce
+// aren't checked. If the template can't be rendered, the response is left to
+// d, which reports the error.
+func NewNonceCheckDispatcher(d safehttp.Dispatcher, c NonceCheck) safehttp.Dispatcher {
+	if d == nil {
+		d = safehttp.DefaultDispatcher{}
+	}
+	return nonceCheckDispatcher{d: d, check: c}
+}
+
+type nonceCheckDispatcher struct {
+	d     safehttp.Dispatcher
+	check NonceCheck
+}
+
+// Write checks the nonces of the response and writes it with the wrapped
+// Dispatcher.
+func (nd nonceCheckDispatcher) Write(rw http.ResponseWriter, resp safehttp.Response) error {
+	resp, ok := nd.checkNonce(rw.Header(), resp)
+	if !ok {
+		return nd.d.Error(rw, safehttp.StatusInternalServerError)
+	}
+	return nd.d.Write(rw, resp)
+}
+
+// Error checks the nonces of the error page, if any, and writes the error
+// with the wrapped Dispatcher.
+func (nd nonceCheckDispatcher) Error(rw http.ResponseWriter, resp safehttp.ErrorResponse) error {
+	checked, ok := nd.checkNonce(rw.Header(), resp)
+	if !ok {
+		return nd.d.Error(rw, safehttp.StatusInternalServerError)
+	}
+	return nd.d.Error(rw, checked.(safehttp.ErrorResponse))
+}
+
+// checkNonce renders the template of the response and checks that all its
+// <script> tags carry the nonce. It reports false if tags are missing the
+// nonce and the check is NonceCheckFail. With NonceCheckInject, a copy of the
+// response with the fixed HTML is returned. Otherwise, the response is
+// returned as it is.
+func (nd nonceCheckDispatcher) checkNonce(h http.Header, resp safehttp.Response) (safehttp.Response, bool) {
+	if nd.check == NoNonceCheck || !isLocalDev() {
+		return resp, true
+	}
+	tmplResp, ok := safehttp.TemplateOf(resp)
+	if !ok {
+		return resp, true
+	}
+	nonce := headerNonce(h)
+	if nonce == "" {
+		return resp, true
+	}
+	var buf bytes.Buffer
+	if err := safehttp.RenderTemplate(&buf, tmplResp); err != nil {
+		return resp, true
+	}
+
+	out, missing, err := addNonce(buf.Bytes(), nonce, nd.check == NonceCheckInject)
+	if err != nil {
+		log.Printf("csp plugin couldn't check the CSP nonces of the response: %v", err)
+		return resp, true
+	}
+	if len(missing) == 0 {
+		return resp, true
+	}
+	if nd.check == NonceCheckFail {
+		log.Printf("csp plugin rejected a response with <script> tags without the CSP nonce: %s", strings.Join(missing, ", "))
+		return nil, false
+	}
+	log.Printf("csp plugin added the CSP nonce to %d <script> tags", len(missing))
+	page := &safehttp.TemplateResponse{
+		Template: renderedTemplate,
+		Data:     uncheckedconversions.HTMLFromStringKnownToSatisfyTypeContract(string(out)),
+	}
+	if p, ok := resp.(*safehttp.ErrorPageResponse); ok {
+		cp := *p
+		cp.Page = page
+		return &cp, true
+	}
+	return page, true
+}
+
+// headerNonce returns the nonce of the CSP headers, or an empty string.
+func headerNonce(h http.Header) string {
+	for _, k := range []string{responseHeaderKey, responseHeaderReportOnlyKey} {
+		for _, v := range h.Values(k) {
+			i := strings.Index(v, "'nonce-")
+			if i < 0 {
+				continue
+			}
+			v = v[i+len("'nonce-"):]
+			if j := strings.IndexByte(v, '\''); j > 0 {
+				return v[:j]
+			}
+		}
+	}
+	return ""
+}
+
+// addNonce returns the <script> tags in the HTML document which don't carry
+// the given nonce. If inject is true, the nonce is added to the tags which
+// have no nonce attribute and the fixed document is returned. An error is
+// returned if the document can't be tokenized.
+func addNonce(doc []byte, nonce string, inject bool) (out []byte, missing []string, err error) {
+	var b bytes.Buffer
+	z := html.NewTokenizer(bytes.NewReader(doc))
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			if z.Err() != io.EOF {
+				return nil, nil, z.Err()
+			}
+			return b.Bytes(), missing, nil
+		}
+		raw := z.Raw()
+		if tt != html.StartTagToken && tt != html.SelfClosingTagToken {
+			b.Write(raw)
+			continue
+		}
+		name, hasAttr := z.TagName()
+		if string(name) != "script" {
+			b.Write(raw)
+			continue
+		}
+		found, hasNonce := "", false
+		for hasAttr {
+			var k, v []byte
+			k, v, hasAttr = z.TagAttr()
+			if string(k) == "nonce" {
+				found, hasNonce = string(v), true
+				break
+			}
+		}
+		if found == nonce {
+			b.Write(raw)
+			continue
+		}
+		missing = append(missing, string(raw))
+		if !inject || hasNonce {
+			b.Write(raw)
+			continue
+		}
+		// The raw token starts with "<script", in any case.
+		b.Write(raw[:len("<script")])
+		fmt.Fprintf(&b, " nonce=%q", nonce)
+		b.Write(raw[len("<script"):])
+	}
+}
@@ -92,6 +92,10 @@ func (s StrictPolicy) Serialize(nonce string, _ safehttp.InterceptorConfig) stri
 	return b.String()
 }
 
+func (s StrictPolicy) reportURI() string {
+	return s.ReportURI
+}
+
 // Match matches strict policies overrides.
 func (StrictPolicy) Match(cfg safehttp.InterceptorConfig) bool {
 	_, ok := cfg.(internalunsafecsp.DisableStrict)
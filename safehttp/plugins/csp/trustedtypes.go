@@ -15,6 +15,7 @@
 package csp
 
 import (
+	"fmt"
 	"strings"
 
 	"github.com/google/go-safeweb/safehttp"
@@ -29,15 +30,63 @@ type TrustedTypesPolicy struct {
 	// ReportURI controls the report-uri directive. If ReportUri is empty, no report-uri
 	// directive will be set.
 	ReportURI string
+	// Policies controls the trusted-types directive, which restricts the
+	// names of the policies the application can create with
+	// trustedTypes.createPolicy(). If Policies is nil, the directive isn't
+	// set and any policy can be created. If it's empty, no policy can be
+	// created. Routes can allow more policies with the TrustedTypes
+	// configuration.
+	//
+	// Names can only contain alphanumeric characters and "-#=_/@.%", or be
+	// the "*" wildcard.
+	Policies []string
+	// AllowDuplicates adds the 'allow-duplicates' keyword to the
+	// trusted-types directive, which allows creating several policies with
+	// the same name. It's ignored if no policy is allowed.
+	AllowDuplicates bool
+}
+
+// TrustedTypes is a route configuration of the TrustedTypesPolicy, e.g. to
+// roll out the policy one route at a time or to allow the policies of a
+// library that only some pages use:
+//
+//	mux.Handle("/editor", safehttp.MethodGet, h, csp.TrustedTypes{Policies: []string{"dompurify"}})
+type TrustedTypes struct {
+	// Policies are allowed in addition to the Policies of the
+	// TrustedTypesPolicy. If the TrustedTypesPolicy doesn't set the
+	// trusted-types directive, they are the only ones allowed.
+	Policies []string
+	// ReportOnly makes the policy report-only for the route.
+	ReportOnly bool
 }
 
 // Serialize serializes this policy for use in a Content-Security-Policy header
 // or in a Content-Security-Policy-Report-Only header. A nonce will be provided
 // to Serialize which can be used in 'nonce-{random-nonce}' values in directives.
-func (t TrustedTypesPolicy) Serialize(nonce string, _ safehttp.InterceptorConfig) string {
+func (t TrustedTypesPolicy) Serialize(nonce string, cfg safehttp.InterceptorConfig) string {
 	var b strings.Builder
 	b.WriteString("require-trusted-types-for 'script'")
 
+	policies := t.Policies
+	if c, ok := cfg.(TrustedTypes); ok && c.Policies != nil {
+		policies = append(append([]string{}, policies...), c.Policies...)
+	}
+	if policies != nil {
+		b.WriteString("; trusted-types")
+		for _, p := range policies {
+			if !validTrustedTypesPolicyName(p) {
+				panic(fmt.Sprintf("invalid Trusted Types policy name %q", p))
+			}
+			b.WriteByte(' ')
+			b.WriteString(p)
+		}
+		if len(policies) == 0 {
+			b.WriteString(" 'none'")
+		} else if t.AllowDuplicates {
+			b.WriteString(" 'allow-duplicates'")
+		}
+	}
+
 	if t.ReportURI != "" {
 		b.WriteString("; report-uri ")
 		b.WriteString(t.ReportURI)
@@ -46,14 +95,54 @@ func (t TrustedTypesPolicy) Serialize(nonce string, _ safehttp.InterceptorConfig
 	return b.String()
 }
 
-// Match matches strict policies overrides.
+func (t TrustedTypesPolicy) reportURI() string {
+	return t.ReportURI
+}
+
+// Match matches Trusted Types policies overrides and the TrustedTypes route
+// configuration. It panics if the latter contains invalid policy names.
 func (TrustedTypesPolicy) Match(cfg safehttp.InterceptorConfig) bool {
-	_, ok := cfg.(internalunsafecsp.DisableTrustedTypes)
-	return ok
+	switch c := cfg.(type) {
+	case internalunsafecsp.DisableTrustedTypes:
+		return true
+	case TrustedTypes:
+		for _, p := range c.Policies {
+			if !validTrustedTypesPolicyName(p) {
+				panic(fmt.Sprintf("invalid Trusted Types policy name %q", p))
+			}
+		}
+		return true
+	}
+	return false
 }
 
 // Overridden checks the override level.
 func (TrustedTypesPolicy) Overridden(cfg safehttp.InterceptorConfig) (disabled, reportOnly bool) {
+	if c, ok := cfg.(TrustedTypes); ok {
+		return false, c.ReportOnly
+	}
 	disable := cfg.(internalunsafecsp.DisableTrustedTypes)
 	return disable.SkipReports, true
 }
+
+// validTrustedTypesPolicyName reports whether name is a valid policy name of
+// the trusted-types directive.
+//
+// See https://w3c.github.io/trusted-types/dist/spec/#trusted-types-csp-directive
+func validTrustedTypesPolicyName(name string) bool {
+	if name == "*" {
+		return true
+	}
+	if name == "" {
+		return false
+	}
+	for _, c := range name {
+		switch {
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9':
+		case strings.ContainsRune("-#=_/@.%", c):
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,132 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package decompress provides a safehttp.Interceptor which transparently
+// decompresses request bodies while protecting against decompression bombs.
+package decompress
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"io/ioutil"
+	"log"
+	"strings"
+
+	"github.com/google/go-safeweb/safehttp"
+)
+
+const (
+	// DefaultMaxSize is the default maximum size of a decompressed body: 10 MiB.
+	DefaultMaxSize = 10 << 20
+	// DefaultMaxRatio is the default maximum ratio between the size of a
+	// decompressed body and the size of the compressed one.
+	DefaultMaxRatio = 100
+)
+
+// Interceptor decompresses request bodies sent with the gzip or deflate
+// Content-Encoding.
+//
+// Bodies are decompressed before the handler is called. If the decompressed
+// body is larger than MaxSize or its compression ratio is higher than
+// MaxRatio, the request is rejected with 413 Request Entity Too Large. Requests
+// with any other Content-Encoding are rejected with 415 Unsupported Media Type.
+//
+// The zero value is valid and ready to use.
+type Interceptor struct {
+	// MaxSize is the maximum size, in bytes, of a decompressed body. If zero,
+	// DefaultMaxSize is used.
+	MaxSize int64
+	// MaxRatio is the maximum ratio between the size of a decompressed body
+	// and the size of the compressed one. If zero, DefaultMaxRatio is used.
+	MaxRatio int64
+}
+
+var _ safehttp.Interceptor = Interceptor{}
+
+// Before decompresses the request body, if needed, and removes the
+// Content-Encoding header from the request.
+func (it Interceptor) Before(w safehttp.ResponseWriter, r *safehttp.IncomingRequest, _ safehttp.InterceptorConfig) safehttp.Result {
+	enc := strings.ToLower(strings.TrimSpace(r.Header.Get("Content-Encoding")))
+	if enc == "" || enc == "identity" {
+		return safehttp.NotWritten()
+	}
+
+	compressed := &countingReader{r: r.Body()}
+	var zr io.Reader
+	var err error
+	switch enc {
+	case "gzip", "x-gzip":
+		zr, err = gzip.NewReader(compressed)
+	case "deflate":
+		zr, err = zlib.NewReader(compressed)
+	default:
+		if safehttp.IsLocalDev() {
+			log.Printf("decompress plugin rejected a request with Content-Encoding %q", enc)
+		}
+		return w.WriteError(safehttp.StatusUnsupportedMediaType)
+	}
+	if err != nil {
+		return w.WriteError(safehttp.StatusBadRequest)
+	}
+
+	maxSize := it.MaxSize
+	if maxSize == 0 {
+		maxSize = DefaultMaxSize
+	}
+	maxRatio := it.MaxRatio
+	if maxRatio == 0 {
+		maxRatio = DefaultMaxRatio
+	}
+
+	var buf bytes.Buffer
+	// Read one more byte than allowed to detect bodies that are too large.
+	n, err := io.Copy(&buf, io.LimitReader(zr, maxSize+1))
+	if err != nil {
+		return w.WriteError(safehttp.StatusBadRequest)
+	}
+	if n > maxSize || n > maxRatio*compressed.n {
+		if safehttp.IsLocalDev() {
+			log.Println("decompress plugin rejected a body exceeding the decompression limits")
+		}
+		return w.WriteError(safehttp.StatusRequestEntityTooLarge)
+	}
+
+	r.Body().Close()
+	r.Header.Del("Content-Encoding")
+	r.SetBody(ioutil.NopCloser(&buf))
+	return safehttp.NotWritten()
+}
+
+// Commit is a no-op, required to satisfy the safehttp.Interceptor interface.
+func (Interceptor) Commit(w safehttp.ResponseHeadersWriter, r *safehttp.IncomingRequest, resp safehttp.Response, _ safehttp.InterceptorConfig) {
+}
+
+// Match returns false since there are no supported configurations.
+func (Interceptor) Match(safehttp.InterceptorConfig) bool {
+	return false
+}
+
+// countingReader counts the bytes read from the underlying reader.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,154 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package decompress_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"io/ioutil"
+	"testing"
+
+	"github.com/google/go-safeweb/safehttp"
+	"github.com/google/go-safeweb/safehttp/plugins/decompress"
+	"github.com/google/go-safeweb/safehttp/safehttptest"
+)
+
+func gzipped(t *testing.T, b []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(b); err != nil {
+		t.Fatalf("zw.Write: %v", err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("zw.Close: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func deflated(t *testing.T, b []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zlib.NewWriter(&buf)
+	if _, err := zw.Write(b); err != nil {
+		t.Fatalf("zw.Write: %v", err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("zw.Close: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestDecompress(t *testing.T) {
+	tests := []struct {
+		name     string
+		encoding string
+		body     func(*testing.T, []byte) []byte
+	}{
+		{name: "gzip", encoding: "gzip", body: gzipped},
+		{name: "deflate", encoding: "deflate", body: deflated},
+		{name: "identity", encoding: "identity", body: func(_ *testing.T, b []byte) []byte { return b }},
+		{name: "no encoding", encoding: "", body: func(_ *testing.T, b []byte) []byte { return b }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want := "hello world"
+			req := safehttptest.NewRequest(safehttp.MethodPost, "/", bytes.NewReader(tt.body(t, []byte(want))))
+			if tt.encoding != "" {
+				req.Header.Set("Content-Encoding", tt.encoding)
+			}
+			fakeRW, rr := safehttptest.NewFakeResponseWriter()
+
+			decompress.Interceptor{}.Before(fakeRW, req, nil)
+
+			if got, want := rr.Code, int(safehttp.StatusOK); got != want {
+				t.Errorf("rr.Code got: %v want: %v", got, want)
+			}
+			b, err := ioutil.ReadAll(req.Body())
+			if err != nil {
+				t.Fatalf("ioutil.ReadAll(req.Body()): %v", err)
+			}
+			if got := string(b); got != want {
+				t.Errorf("req.Body() got: %q want: %q", got, want)
+			}
+			if got := req.Header.Get("Content-Encoding"); got != "" && got != "identity" {
+				t.Errorf(`req.Header.Get("Content-Encoding") got: %q want: ""`, got)
+			}
+		})
+	}
+}
+
+func TestDecompressionBomb(t *testing.T) {
+	tests := []struct {
+		name string
+		it   decompress.Interceptor
+	}{
+		{
+			name: "absolute size exceeded",
+			it:   decompress.Interceptor{MaxSize: 1024, MaxRatio: 1 << 20},
+		},
+		{
+			name: "ratio exceeded",
+			it:   decompress.Interceptor{MaxSize: 1 << 30, MaxRatio: 10},
+		},
+		{
+			name: "defaults",
+			it:   decompress.Interceptor{},
+		},
+	}
+
+	// 20 MiB of zeros compress to about 20 KiB.
+	bomb := gzipped(t, make([]byte, 20<<20))
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := safehttptest.NewRequest(safehttp.MethodPost, "/", bytes.NewReader(bomb))
+			req.Header.Set("Content-Encoding", "gzip")
+			fakeRW, rr := safehttptest.NewFakeResponseWriter()
+
+			tt.it.Before(fakeRW, req, nil)
+
+			if got, want := rr.Code, int(safehttp.StatusRequestEntityTooLarge); got != want {
+				t.Errorf("rr.Code got: %v want: %v", got, want)
+			}
+		})
+	}
+}
+
+func TestUnsupportedEncoding(t *testing.T) {
+	req := safehttptest.NewRequest(safehttp.MethodPost, "/", bytes.NewReader([]byte("data")))
+	req.Header.Set("Content-Encoding", "br")
+	fakeRW, rr := safehttptest.NewFakeResponseWriter()
+
+	decompress.Interceptor{}.Before(fakeRW, req, nil)
+
+	if got, want := rr.Code, int(safehttp.StatusUnsupportedMediaType); got != want {
+		t.Errorf("rr.Code got: %v want: %v", got, want)
+	}
+}
+
+func TestMalformedBody(t *testing.T) {
+	req := safehttptest.NewRequest(safehttp.MethodPost, "/", bytes.NewReader([]byte("not gzip")))
+	req.Header.Set("Content-Encoding", "gzip")
+	fakeRW, rr := safehttptest.NewFakeResponseWriter()
+
+	decompress.Interceptor{}.Before(fakeRW, req, nil)
+
+	if got, want := rr.Code, int(safehttp.StatusBadRequest); got != want {
+		t.Errorf("rr.Code got: %v want: %v", got, want)
+	}
+}
@@ -17,6 +17,7 @@ package fetchmetadata
 
 import (
 	"log"
+	"strings"
 
 	"github.com/google/go-safeweb/safehttp"
 )
@@ -28,34 +29,135 @@ var (
 	}
 )
 
+// DefaultVaryHeaders are the request headers the policies depend on. They're
+// added to the Vary header of the responses by default.
+var DefaultVaryHeaders = []string{"Sec-Fetch-Site", "Sec-Fetch-Mode", "Sec-Fetch-Dest"}
+
 // TODO(empijei): implement NIP as soon as it's production ready.
 
+// Rule decides which requests a Policy allows, based on their Fetch Metadata
+// headers.
+type Rule interface {
+	// Allow reports whether the request is allowed. It must only depend on
+	// the request.
+	Allow(r *safehttp.IncomingRequest) bool
+}
+
+// RuleFunc adapts a function to a Rule.
+type RuleFunc func(r *safehttp.IncomingRequest) bool
+
+// Allow calls f(r).
+func (f RuleFunc) Allow(r *safehttp.IncomingRequest) bool {
+	return f(r)
+}
+
+// AnyOf returns a Rule allowing the requests allowed by at least one of the
+// rules, e.g. to extend one of the built-in rules.
+func AnyOf(rules ...Rule) Rule {
+	return RuleFunc(func(r *safehttp.IncomingRequest) bool {
+		for _, rule := range rules {
+			if rule.Allow(r) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
 // Policy is a security policy based on Fetch Metadata.
 //
 // See https://web.dev/fetch-metadata/ for more.
 type Policy struct {
-	isAllowed  func(*safehttp.IncomingRequest) bool
-	match      func(safehttp.InterceptorConfig) bool
-	skip       func(cfg safehttp.InterceptorConfig) (skip, skipReports bool)
-	signal     string
-	navigate   *safehttp.URL
+	rule     Rule
+	match    func(safehttp.InterceptorConfig) bool
+	skip     func(cfg safehttp.InterceptorConfig) (skip, skipReports bool)
+	signal   string
+	navigate *safehttp.URL
+	// optIn policies are only enforced on the routes registered with one of
+	// their configurations, hence they ignore the route overrides.
+	optIn      bool
 	ReportOnly bool
+	// TrustedOrigins are origins, in the "scheme://host[:port]" form, whose
+	// cross-site requests the policy allows, e.g. a sibling domain of the
+	// application. They're matched against the Origin header of the requests
+	// or, if it's missing, the origin of their Referer header. Route
+	// configurations like StrictIsolation still apply to them.
+	TrustedOrigins []string
+	// RejectHandler, if non-nil, writes the response to the requests the
+	// policy rejects, instead of 403 Forbidden or the redirect to the
+	// navigation fallback, e.g. to return a machine-readable error:
+	//
+	//	p.RejectHandler = safehttp.HandlerFunc(func(w safehttp.ResponseWriter, r *safehttp.IncomingRequest) safehttp.Result {
+	//		return w.WriteError(&safehttp.ProblemResponse{
+	//			Status:  safehttp.StatusForbidden,
+	//			Detail:  "Cross-site requests are not allowed.",
+	//			Request: r,
+	//		})
+	//	})
+	RejectHandler safehttp.Handler
+	// Rollout, if non-nil, limits the enforcement of the policy to a
+	// percentage of the clients. See Rollout.
+	Rollout *Rollout
+	// Reporter receives the reports of the requests the policy rejects, or
+	// would reject if it was enforced. If nil, the reports are logged.
+	Reporter Reporter
+	// VaryHeaders are added to the Vary header of the responses when the
+	// policy is enforced, so that shared caches don't serve a response to a
+	// request the policy would have rejected. If nil, DefaultVaryHeaders are
+	// used. If empty, the Vary header isn't changed.
+	VaryHeaders []string
+}
+
+// NewPolicy returns a Policy enforcing a custom rule, e.g. to allow images to
+// be loaded cross-site from a CDN-backed asset host:
+//
+//	fetchmetadata.NewPolicy("CDN_ASSETS", fetchmetadata.AnyOf(
+//		fetchmetadata.ResourceIsolationRule,
+//		fetchmetadata.RuleFunc(func(r *safehttp.IncomingRequest) bool {
+//			return r.Header.Get("Sec-Fetch-Dest") == "image"
+//		}),
+//	))
+//
+// The name identifies the policy in the reports. The policy can be made
+// report-only and accepts the route configurations of the built-in policies,
+// except the ones disabling them.
+func NewPolicy(name string, rule Rule) *Policy {
+	return &Policy{
+		rule:   rule,
+		match:  func(safehttp.InterceptorConfig) bool { return false },
+		skip:   func(safehttp.InterceptorConfig) (bool, bool) { return false, false },
+		signal: name,
+	}
+}
+
+// report reports the violation to the Reporter, or logs it. Only the
+// headers the policies depend on are read when it's logged, so that they're
+// the only ones in the interceptor traces.
+func (p *Policy) report(r *safehttp.IncomingRequest, enforced bool) {
+	if p.Reporter == nil {
+		log.Printf("Request for %s %q should be blocked by %s. Actually_blocked=%v", r.Method(), r.URL().String(), p.signal, enforced)
+		return
+	}
+	p.Reporter.Report(newReport(r, p.signal, enforced))
 }
 
 // Before implements the Fetch Metadata validation and signals logic.
 func (p *Policy) Before(w safehttp.ResponseWriter, r *safehttp.IncomingRequest, cfg safehttp.InterceptorConfig) safehttp.Result {
+	override, cfg := p.splitConfig(cfg)
 	skip, skipReports := p.skip(cfg)
 	if p.ReportOnly {
 		skip = true
 	}
 
-	if p.isAllowed(r) {
+	if p.allowed(r, override) {
 		return safehttp.NotWritten()
 	}
+	if !skip && p.Rollout != nil && !p.Rollout.enforced(r) {
+		skip = true
+	}
 
 	if !skipReports {
-		// TODO(empijei): report this.
-		log.Printf("Request for %s %q should be blocked by %s. Actually_blocked=%v", r.Method(), r.URL().String(), p.signal, !skip)
+		p.report(r, !skip)
 	}
 	if skip {
 		return safehttp.NotWritten()
@@ -63,15 +165,54 @@ func (p *Policy) Before(w safehttp.ResponseWriter, r *safehttp.IncomingRequest,
 	if safehttp.IsLocalDev() {
 		log.Println("fetchmetadata plugin blocked a potentially malicious request")
 	}
+	if p.RejectHandler != nil {
+		return p.RejectHandler.ServeHTTP(w, r)
+	}
 	if p.navigate != nil {
 		return safehttp.Redirect(w, r, p.navigate.String(), safehttp.StatusSeeOther)
 	}
 	return w.WriteError(safehttp.StatusForbidden)
 }
 
-// Commit is a no-op, required to satisfy the safehttp.Interceptor interface.
-func (p *Policy) Commit(w safehttp.ResponseHeadersWriter, r *safehttp.IncomingRequest, resp safehttp.Response, _ safehttp.InterceptorConfig) {
+// Commit adds the VaryHeaders to the Vary header of the response, unless the
+// policy is report-only or disabled for the request, or the Vary header was
+// claimed.
+func (p *Policy) Commit(w safehttp.ResponseHeadersWriter, r *safehttp.IncomingRequest, resp safehttp.Response, cfg safehttp.InterceptorConfig) {
+	_, cfg = p.splitConfig(cfg)
+	if skip, _ := p.skip(cfg); skip || p.ReportOnly {
+		return
+	}
+	names := p.VaryHeaders
+	if names == nil {
+		names = DefaultVaryHeaders
+	}
+	h := w.Header()
+	if len(names) == 0 || h.IsClaimed("Vary") {
+		return
+	}
+	for _, name := range names {
+		addVary(h, name)
+	}
+}
+
+// addVary adds name to the Vary header, unless it's already there.
+func addVary(h safehttp.Header, name string) {
+	for _, v := range h.Values("Vary") {
+		for _, f := range strings.Split(v, ",") {
+			if f = strings.TrimSpace(f); f == "*" || strings.EqualFold(f, name) {
+				return
+			}
+		}
+	}
+	h.Add("Vary", name)
 }
 
-// Match recongnizes configs to disable fetch metadata protection.
-func (p *Policy) Match(cfg safehttp.InterceptorConfig) bool { return p.match(cfg) }
+// Match recongnizes configs to disable fetch metadata protection, and the
+// CrossSite and StrictIsolation route configurations. A route can have one of
+// each, see CombineConfigs.
+func (p *Policy) Match(cfg safehttp.InterceptorConfig) bool {
+	if p.optIn {
+		return p.match(cfg)
+	}
+	return isOverride(cfg) || p.match(cfg)
+}
@@ -16,10 +16,13 @@ package fetchmetadata_test
 
 import (
 	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/google/go-safeweb/safehttp/plugins/fetchmetadata"
+	"github.com/google/go-safeweb/safehttp/plugins/fetchmetadata/internalunsafefetchmetadata/unsafefetchmetadatafortests"
 
 	"github.com/google/go-safeweb/safehttp"
 	"github.com/google/go-safeweb/safehttp/safehttptest"
@@ -76,3 +79,191 @@ func TestReportOnly(t *testing.T) {
 		})
 	}
 }
+
+func TestVary(t *testing.T) {
+	tests := []struct {
+		name     string
+		policy   func() *fetchmetadata.Policy
+		cfg      safehttp.InterceptorConfig
+		prevVary string
+		want     []string
+	}{
+		{
+			name:   "Default",
+			policy: fetchmetadata.ResourceIsolationPolicy,
+			want:   []string{"Sec-Fetch-Site", "Sec-Fetch-Mode", "Sec-Fetch-Dest"},
+		},
+		{
+			name:     "Appended",
+			policy:   fetchmetadata.FramingIsolationPolicy,
+			prevVary: "Accept-Encoding, sec-fetch-site",
+			want:     []string{"Accept-Encoding, sec-fetch-site", "Sec-Fetch-Mode", "Sec-Fetch-Dest"},
+		},
+		{
+			name: "Custom",
+			policy: func() *fetchmetadata.Policy {
+				p := fetchmetadata.ResourceIsolationPolicy()
+				p.VaryHeaders = []string{"Sec-Fetch-Site"}
+				return p
+			},
+			want: []string{"Sec-Fetch-Site"},
+		},
+		{
+			name: "Disabled",
+			policy: func() *fetchmetadata.Policy {
+				p := fetchmetadata.ResourceIsolationPolicy()
+				p.VaryHeaders = []string{}
+				return p
+			},
+		},
+		{
+			name: "Report only",
+			policy: func() *fetchmetadata.Policy {
+				p := fetchmetadata.ResourceIsolationPolicy()
+				p.ReportOnly = true
+				return p
+			},
+		},
+		{
+			name:   "Policy disabled for the route",
+			policy: fetchmetadata.ResourceIsolationPolicy,
+			cfg:    unsafefetchmetadatafortests.DisableResourceIsolationPolicy(),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mb := safehttp.NewServeMuxConfig(nil)
+			mb.Intercept(tt.policy())
+			mux := mb.Mux()
+			var cfgs []safehttp.InterceptorConfig
+			if tt.cfg != nil {
+				cfgs = append(cfgs, tt.cfg)
+			}
+			mux.Handle("/", safehttp.MethodGet, safehttp.HandlerFunc(func(w safehttp.ResponseWriter, r *safehttp.IncomingRequest) safehttp.Result {
+				if tt.prevVary != "" {
+					w.Header().Set("Vary", tt.prevVary)
+				}
+				return w.Write(safehttp.NoContentResponse{})
+			}), cfgs...)
+
+			rr := httptest.NewRecorder()
+			mux.ServeHTTP(rr, httptest.NewRequest(safehttp.MethodGet, "https://spaghetti.com/", nil))
+
+			if diff := cmp.Diff(tt.want, rr.Header().Values("Vary"), cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("rr.Header().Values(\"Vary\") mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestCustomPolicy(t *testing.T) {
+	images := fetchmetadata.RuleFunc(func(r *safehttp.IncomingRequest) bool {
+		return r.Header.Get("Sec-Fetch-Dest") == "image"
+	})
+	tests := []struct {
+		name       string
+		dest       string
+		reportOnly bool
+		want       safehttp.StatusCode
+	}{
+		{
+			name: "Allowed by the custom rule",
+			dest: "image",
+			want: safehttp.StatusOK,
+		},
+		{
+			name: "Rejected",
+			dest: "script",
+			want: safehttp.StatusForbidden,
+		},
+		{
+			name:       "Report only",
+			dest:       "script",
+			reportOnly: true,
+			want:       safehttp.StatusOK,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := safehttptest.NewRequest(safehttp.MethodGet, "https://spaghetti.com/carbonara", nil)
+			req.Header.Add("Sec-Fetch-Site", "cross-site")
+			req.Header.Add("Sec-Fetch-Mode", "no-cors")
+			req.Header.Add("Sec-Fetch-Dest", tt.dest)
+			fakeRW, rr := safehttptest.NewFakeResponseWriter()
+
+			p := fetchmetadata.NewPolicy("CDN_ASSETS", fetchmetadata.AnyOf(fetchmetadata.ResourceIsolationRule, images))
+			p.ReportOnly = tt.reportOnly
+			p.Before(fakeRW, req, nil)
+
+			if got := safehttp.StatusCode(rr.Code); got != tt.want {
+				t.Errorf("rr.Code got: %v want: %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCustomPolicyMatch(t *testing.T) {
+	p := fetchmetadata.NewPolicy("CUSTOM", fetchmetadata.ResourceIsolationRule)
+	if p.Match(unsafefetchmetadatafortests.DisableResourceIsolationPolicy()) {
+		t.Error("p.Match(DisableResourceIsolationPolicy()): got true, want false")
+	}
+	if !p.Match(fetchmetadata.StrictIsolation{}) {
+		t.Error("p.Match(StrictIsolation{}): got false, want true")
+	}
+}
+
+func TestRejectHandler(t *testing.T) {
+	tests := []struct {
+		name        string
+		site        string
+		reportOnly  bool
+		wantCode    safehttp.StatusCode
+		wantHandled bool
+	}{
+		{
+			name:        "Rejected",
+			site:        "cross-site",
+			wantCode:    safehttp.StatusBadRequest,
+			wantHandled: true,
+		},
+		{
+			name:     "Allowed",
+			site:     "same-origin",
+			wantCode: safehttp.StatusOK,
+		},
+		{
+			name:       "Report only",
+			site:       "cross-site",
+			reportOnly: true,
+			wantCode:   safehttp.StatusOK,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := safehttptest.NewRequest(safehttp.MethodPost, "https://spaghetti.com/carbonara", nil)
+			req.Header.Add("Sec-Fetch-Site", tt.site)
+			req.Header.Add("Sec-Fetch-Mode", "cors")
+			req.Header.Add("Sec-Fetch-Dest", "empty")
+			fakeRW, rr := safehttptest.NewFakeResponseWriter()
+
+			handled := false
+			p := fetchmetadata.ResourceIsolationPolicy()
+			p.ReportOnly = tt.reportOnly
+			p.RejectHandler = safehttp.HandlerFunc(func(w safehttp.ResponseWriter, r *safehttp.IncomingRequest) safehttp.Result {
+				handled = true
+				return w.WriteError(&safehttp.ProblemResponse{
+					Status: safehttp.StatusBadRequest,
+					Detail: "Cross-site requests are not allowed.",
+				})
+			})
+			p.Before(fakeRW, req, nil)
+
+			if handled != tt.wantHandled {
+				t.Errorf("RejectHandler called: got %v, want %v", handled, tt.wantHandled)
+			}
+			if got := safehttp.StatusCode(rr.Code); got != tt.wantCode {
+				t.Errorf("rr.Code got: %v want: %v", got, tt.wantCode)
+			}
+		})
+	}
+}
@@ -30,35 +30,41 @@ var (
 	}
 )
 
+// FramingIsolationRule rejects the cross-site requests which would embed the
+// response in a frame. It's the rule of the FramingIsolationPolicy.
+var FramingIsolationRule Rule = RuleFunc(framingIsolation)
+
+func framingIsolation(r *safehttp.IncomingRequest) bool {
+	h := r.Header
+	mode := h.Get("Sec-Fetch-Mode")
+	dest := h.Get("Sec-Fetch-Dest")
+	site := h.Get("Sec-Fetch-Site")
+	if mode == "" || dest == "" || site == "" {
+		return true
+	}
+	if !navigationalModes[mode] {
+		// Allow non-navigational requests.
+		return true
+	}
+	if !frameableDests[dest] {
+		// Allow non-frameable requests.
+		return true
+	}
+	if site == "same-origin" || site == "none" {
+		return true
+	}
+	if safehttp.IsLocalDev() {
+		log.Println("fetchmetadata plugin framing protection detected a potentially malicious request")
+	}
+	return false
+}
+
 // FramingIsolationPolicy protects from framing attacks.
 //
 // See https://xsleaks.dev/docs/defenses/isolation-policies/framing-isolation/#implementation-with-fetch-metadata
 func FramingIsolationPolicy() *Policy {
 	return &Policy{
-		isAllowed: func(r *safehttp.IncomingRequest) bool {
-			h := r.Header
-			mode := h.Get("Sec-Fetch-Mode")
-			dest := h.Get("Sec-Fetch-Dest")
-			site := h.Get("Sec-Fetch-Site")
-			if mode == "" || dest == "" || site == "" {
-				return true
-			}
-			if !navigationalModes[mode] {
-				// Allow non-navigational requests.
-				return true
-			}
-			if !frameableDests[dest] {
-				// Allow non-frameable requests.
-				return true
-			}
-			if site == "same-origin" || site == "none" {
-				return true
-			}
-			if safehttp.IsLocalDev() {
-				log.Println("fetchmetadata plugin framing protection detected a potentially malicious request")
-			}
-			return false
-		},
+		rule: FramingIsolationRule,
 		match: func(cfg safehttp.InterceptorConfig) bool {
 			switch cfg.(type) {
 			case internalunsafeframing.Disable, internalunsafeframing.AllowList:
@@ -0,0 +1,84 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fetchmetadata
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/google/go-safeweb/safehttp"
+)
+
+// trusted reports whether the request comes from one of the TrustedOrigins.
+//
+// The origin is taken from the Origin header or, if it's missing, from the
+// Referer header, which is the only one sent with cross-site GET navigations.
+// Both are parsed strictly: an opaque ("null") or malformed Origin isn't
+// trusted, and the Referer isn't looked at in that case.
+func (p *Policy) trusted(r *safehttp.IncomingRequest) bool {
+	if len(p.TrustedOrigins) == 0 || r.Header.Get("Sec-Fetch-Site") != "cross-site" {
+		return false
+	}
+	var origin string
+	if o := r.Header.Values("Origin"); len(o) > 0 {
+		if len(o) > 1 {
+			return false
+		}
+		origin = parseOrigin(o[0])
+	} else if ref := r.Header.Values("Referer"); len(ref) == 1 {
+		origin = refererOrigin(ref[0])
+	}
+	if origin == "" {
+		return false
+	}
+	for _, t := range p.TrustedOrigins {
+		if strings.ToLower(t) == origin {
+			return true
+		}
+	}
+	return false
+}
+
+// parseOrigin returns the lowercase form of o if it's a serialized HTTP(S)
+// origin, i.e. "scheme://host[:port]", or an empty string otherwise.
+func parseOrigin(o string) string {
+	u, err := url.Parse(o)
+	if err != nil || u.Scheme+"://"+u.Host != o {
+		return ""
+	}
+	if !validOrigin(u) {
+		return ""
+	}
+	return strings.ToLower(o)
+}
+
+// refererOrigin returns the lowercase origin of the Referer header value ref,
+// or an empty string if it isn't an absolute HTTP(S) URL.
+func refererOrigin(ref string) string {
+	u, err := url.Parse(ref)
+	if err != nil || u.User != nil || u.Opaque != "" || !validOrigin(u) {
+		return ""
+	}
+	return strings.ToLower(u.Scheme + "://" + u.Host)
+}
+
+func validOrigin(u *url.URL) bool {
+	switch strings.ToLower(u.Scheme) {
+	case "https", "http":
+	default:
+		return false
+	}
+	return u.Host != "" && u.Hostname() != ""
+}
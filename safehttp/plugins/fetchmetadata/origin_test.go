@@ -0,0 +1,167 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fetchmetadata_test
+
+import (
+	"testing"
+
+	"github.com/google/go-safeweb/safehttp"
+	"github.com/google/go-safeweb/safehttp/plugins/fetchmetadata"
+	"github.com/google/go-safeweb/safehttp/safehttptest"
+)
+
+func TestTrustedOrigins(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  func() *fetchmetadata.Policy
+		cfg     safehttp.InterceptorConfig
+		site    string
+		headers map[string]string
+		want    safehttp.StatusCode
+	}{
+		{
+			name:    "Trusted Origin",
+			policy:  fetchmetadata.ResourceIsolationPolicy,
+			site:    "cross-site",
+			headers: map[string]string{"Origin": "https://corp.example.com"},
+			want:    safehttp.StatusOK,
+		},
+		{
+			name:    "Trusted Origin with port",
+			policy:  fetchmetadata.ResourceIsolationPolicy,
+			site:    "cross-site",
+			headers: map[string]string{"Origin": "https://corp.example.com:8443"},
+			want:    safehttp.StatusOK,
+		},
+		{
+			name:    "Trusted Referer",
+			policy:  fetchmetadata.ResourceIsolationPolicy,
+			site:    "cross-site",
+			headers: map[string]string{"Referer": "https://corp.example.com/some/page?q=1"},
+			want:    safehttp.StatusOK,
+		},
+		{
+			name:    "Trusted Origin for framing policy",
+			policy:  fetchmetadata.FramingIsolationPolicy,
+			site:    "cross-site",
+			headers: map[string]string{"Origin": "https://corp.example.com"},
+			want:    safehttp.StatusOK,
+		},
+		{
+			name:    "Untrusted Origin",
+			policy:  fetchmetadata.ResourceIsolationPolicy,
+			site:    "cross-site",
+			headers: map[string]string{"Origin": "https://evil.com"},
+			want:    safehttp.StatusForbidden,
+		},
+		{
+			name:    "Different scheme",
+			policy:  fetchmetadata.ResourceIsolationPolicy,
+			site:    "cross-site",
+			headers: map[string]string{"Origin": "http://corp.example.com"},
+			want:    safehttp.StatusForbidden,
+		},
+		{
+			name:    "Different port",
+			policy:  fetchmetadata.ResourceIsolationPolicy,
+			site:    "cross-site",
+			headers: map[string]string{"Origin": "https://corp.example.com:444"},
+			want:    safehttp.StatusForbidden,
+		},
+		{
+			name:    "Subdomain",
+			policy:  fetchmetadata.ResourceIsolationPolicy,
+			site:    "cross-site",
+			headers: map[string]string{"Origin": "https://evil.corp.example.com"},
+			want:    safehttp.StatusForbidden,
+		},
+		{
+			name:    "Origin with path",
+			policy:  fetchmetadata.ResourceIsolationPolicy,
+			site:    "cross-site",
+			headers: map[string]string{"Origin": "https://corp.example.com/"},
+			want:    safehttp.StatusForbidden,
+		},
+		{
+			name:    "Origin with userinfo",
+			policy:  fetchmetadata.ResourceIsolationPolicy,
+			site:    "cross-site",
+			headers: map[string]string{"Origin": "https://corp.example.com@evil.com"},
+			want:    safehttp.StatusForbidden,
+		},
+		{
+			name:    "Null Origin doesn't fall back to Referer",
+			policy:  fetchmetadata.ResourceIsolationPolicy,
+			site:    "cross-site",
+			headers: map[string]string{"Origin": "null", "Referer": "https://corp.example.com/"},
+			want:    safehttp.StatusForbidden,
+		},
+		{
+			name:    "Referer with userinfo",
+			policy:  fetchmetadata.ResourceIsolationPolicy,
+			site:    "cross-site",
+			headers: map[string]string{"Referer": "https://user@corp.example.com/"},
+			want:    safehttp.StatusForbidden,
+		},
+		{
+			name:    "Relative Referer",
+			policy:  fetchmetadata.ResourceIsolationPolicy,
+			site:    "cross-site",
+			headers: map[string]string{"Referer": "//corp.example.com/"},
+			want:    safehttp.StatusForbidden,
+		},
+		{
+			name:    "Non-HTTP Referer",
+			policy:  fetchmetadata.ResourceIsolationPolicy,
+			site:    "cross-site",
+			headers: map[string]string{"Referer": "ftp://corp.example.com/"},
+			want:    safehttp.StatusForbidden,
+		},
+		{
+			name:   "No Origin nor Referer",
+			policy: fetchmetadata.ResourceIsolationPolicy,
+			site:   "cross-site",
+			want:   safehttp.StatusForbidden,
+		},
+		{
+			name:    "Strict isolation still applies",
+			policy:  fetchmetadata.ResourceIsolationPolicy,
+			cfg:     fetchmetadata.StrictIsolation{},
+			site:    "cross-site",
+			headers: map[string]string{"Origin": "https://corp.example.com"},
+			want:    safehttp.StatusForbidden,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := safehttptest.NewRequest(safehttp.MethodPost, "https://spaghetti.com/carbonara", nil)
+			req.Header.Add("Sec-Fetch-Site", tt.site)
+			req.Header.Add("Sec-Fetch-Mode", "cors")
+			req.Header.Add("Sec-Fetch-Dest", "iframe")
+			for k, v := range tt.headers {
+				req.Header.Add(k, v)
+			}
+			fakeRW, rr := safehttptest.NewFakeResponseWriter()
+
+			p := tt.policy()
+			p.TrustedOrigins = []string{"https://corp.example.com", "https://CORP.example.com:8443"}
+			p.Before(fakeRW, req, tt.cfg)
+
+			if got := safehttp.StatusCode(rr.Code); got != tt.want {
+				t.Errorf("rr.Code got: %v want: %v", got, tt.want)
+			}
+		})
+	}
+}
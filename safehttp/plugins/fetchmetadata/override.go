@@ -0,0 +1,144 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fetchmetadata
+
+import (
+	"errors"
+
+	"github.com/google/go-safeweb/safehttp"
+)
+
+// CrossSite is a route configuration which allows the cross-site requests
+// matching it, on top of the ones the policies allow, e.g. to let other sites
+// embed the images served by a route:
+//
+//	mux.Handle("/avatar", safehttp.MethodGet, h, fetchmetadata.CrossSite{
+//		Modes: []string{"no-cors"},
+//		Dests: []string{"image"},
+//	})
+//
+// It applies to all the policies, hence allowing "iframe" destinations also
+// disables the FramingIsolationPolicy for them.
+type CrossSite struct {
+	// Modes are the allowed values of Sec-Fetch-Mode. If empty, all modes are
+	// allowed.
+	Modes []string
+	// Dests are the allowed values of Sec-Fetch-Dest. If empty, all
+	// destinations are allowed.
+	Dests []string
+	// Methods are the allowed methods. If empty, only GET and HEAD requests
+	// are allowed.
+	Methods []string
+}
+
+func (c CrossSite) allows(r *safehttp.IncomingRequest) bool {
+	h := r.Header
+	if len(c.Methods) == 0 {
+		if !statePreservingMethods[r.Method()] {
+			return false
+		}
+	} else if !contains(c.Methods, r.Method()) {
+		return false
+	}
+	if len(c.Modes) > 0 && !contains(c.Modes, h.Get("Sec-Fetch-Mode")) {
+		return false
+	}
+	return len(c.Dests) == 0 || contains(c.Dests, h.Get("Sec-Fetch-Dest"))
+}
+
+// StrictIsolation is a route configuration which also rejects the requests
+// the policies allow from other origins, including cross-site navigations,
+// e.g. for the routes that are only meant to be used by the application
+// itself. Only the same-origin and user-initiated requests, and the requests
+// of browsers which don't send Fetch Metadata, are allowed.
+type StrictIsolation struct{}
+
+func (StrictIsolation) allows(r *safehttp.IncomingRequest) bool {
+	switch r.Header.Get("Sec-Fetch-Site") {
+	case "", "same-origin", "none":
+		return true
+	}
+	return false
+}
+
+// allowed reports whether the policy allows the request, given the route
+// override.
+func (p *Policy) allowed(r *safehttp.IncomingRequest, override safehttp.InterceptorConfig) bool {
+	allowed := p.rule.Allow(r) || p.trusted(r)
+	switch c := override.(type) {
+	case CrossSite:
+		return allowed || c.allows(r)
+	case StrictIsolation:
+		return allowed && c.allows(r)
+	}
+	return allowed
+}
+
+func isOverride(cfg safehttp.InterceptorConfig) bool {
+	switch cfg.(type) {
+	case CrossSite, StrictIsolation:
+		return true
+	}
+	return false
+}
+
+// combinedConfig is the configuration of a route with both a route override
+// and another configuration of the policy, e.g. one disabling it.
+type combinedConfig struct {
+	override safehttp.InterceptorConfig
+	cfg      safehttp.InterceptorConfig
+}
+
+// CombineConfigs allows a route to have a CrossSite or StrictIsolation
+// configuration together with another configuration of the policy, e.g.
+// internalunsafeframing.AllowList.
+func (p *Policy) CombineConfigs(cfgs []safehttp.InterceptorConfig) (safehttp.InterceptorConfig, error) {
+	var c combinedConfig
+	for _, cfg := range cfgs {
+		if p.optIn || !isOverride(cfg) {
+			if c.cfg != nil {
+				return nil, errors.New("only one configuration of the policy is allowed, besides CrossSite or StrictIsolation")
+			}
+			c.cfg = cfg
+			continue
+		}
+		if c.override != nil {
+			return nil, errors.New("CrossSite and StrictIsolation can't be used together")
+		}
+		c.override = cfg
+	}
+	return c, nil
+}
+
+// splitConfig returns the route override and the other configuration of the
+// policy in the configuration of a route.
+func (p *Policy) splitConfig(cfg safehttp.InterceptorConfig) (override, other safehttp.InterceptorConfig) {
+	if c, ok := cfg.(combinedConfig); ok {
+		return c.override, c.cfg
+	}
+	if !p.optIn && isOverride(cfg) {
+		return cfg, nil
+	}
+	return nil, cfg
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,227 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fetchmetadata_test
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-safeweb/safehttp"
+	"github.com/google/go-safeweb/safehttp/plugins/fetchmetadata"
+	"github.com/google/go-safeweb/safehttp/plugins/fetchmetadata/internalunsafefetchmetadata"
+	"github.com/google/go-safeweb/safehttp/plugins/framing/internalunsafeframing"
+	"github.com/google/go-safeweb/safehttp/safehttptest"
+	"github.com/google/safehtml"
+)
+
+func TestRouteOverrides(t *testing.T) {
+	image := fetchmetadata.CrossSite{Modes: []string{"no-cors"}, Dests: []string{"image"}}
+	tests := []struct {
+		name   string
+		policy func() *fetchmetadata.Policy
+		cfg    safehttp.InterceptorConfig
+		req    testHeaders
+		want   safehttp.StatusCode
+	}{
+		{
+			name:   "Cross-site image allowed",
+			policy: fetchmetadata.ResourceIsolationPolicy,
+			cfg:    image,
+			req:    testHeaders{method: safehttp.MethodGet, site: "cross-site", mode: "no-cors", dest: "image"},
+			want:   safehttp.StatusOK,
+		},
+		{
+			name:   "Cross-site script still rejected",
+			policy: fetchmetadata.ResourceIsolationPolicy,
+			cfg:    image,
+			req:    testHeaders{method: safehttp.MethodGet, site: "cross-site", mode: "no-cors", dest: "script"},
+			want:   safehttp.StatusForbidden,
+		},
+		{
+			name:   "Cross-site image POST rejected",
+			policy: fetchmetadata.ResourceIsolationPolicy,
+			cfg:    image,
+			req:    testHeaders{method: safehttp.MethodPost, site: "cross-site", mode: "no-cors", dest: "image"},
+			want:   safehttp.StatusForbidden,
+		},
+		{
+			name:   "Cross-site POST allowed by methods",
+			policy: fetchmetadata.ResourceIsolationPolicy,
+			cfg:    fetchmetadata.CrossSite{Methods: []string{safehttp.MethodPost}, Modes: []string{"cors"}},
+			req:    testHeaders{method: safehttp.MethodPost, site: "cross-site", mode: "cors", dest: "empty"},
+			want:   safehttp.StatusOK,
+		},
+		{
+			name:   "Cross-site iframe allowed by framing policy",
+			policy: fetchmetadata.FramingIsolationPolicy,
+			cfg:    fetchmetadata.CrossSite{Dests: []string{"iframe"}},
+			req:    testHeaders{method: safehttp.MethodGet, site: "cross-site", mode: "navigate", dest: "iframe"},
+			want:   safehttp.StatusOK,
+		},
+		{
+			name:   "Strict rejects cross-site navigation",
+			policy: fetchmetadata.ResourceIsolationPolicy,
+			cfg:    fetchmetadata.StrictIsolation{},
+			req:    testHeaders{method: safehttp.MethodGet, site: "cross-site", mode: "navigate", dest: "document"},
+			want:   safehttp.StatusForbidden,
+		},
+		{
+			name:   "Strict rejects same-site",
+			policy: fetchmetadata.ResourceIsolationPolicy,
+			cfg:    fetchmetadata.StrictIsolation{},
+			req:    testHeaders{method: safehttp.MethodGet, site: "same-site", mode: "cors", dest: "empty"},
+			want:   safehttp.StatusForbidden,
+		},
+		{
+			name:   "Strict allows same-origin",
+			policy: fetchmetadata.ResourceIsolationPolicy,
+			cfg:    fetchmetadata.StrictIsolation{},
+			req:    testHeaders{method: safehttp.MethodPost, site: "same-origin", mode: "cors", dest: "empty"},
+			want:   safehttp.StatusOK,
+		},
+		{
+			name:   "Strict allows browsers without Fetch Metadata",
+			policy: fetchmetadata.ResourceIsolationPolicy,
+			cfg:    fetchmetadata.StrictIsolation{},
+			req:    testHeaders{method: safehttp.MethodPost},
+			want:   safehttp.StatusOK,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := safehttptest.NewRequest(tt.req.method, "https://spaghetti.com/carbonara", nil)
+			req.Header.Add("Sec-Fetch-Site", tt.req.site)
+			req.Header.Add("Sec-Fetch-Mode", tt.req.mode)
+			req.Header.Add("Sec-Fetch-Dest", tt.req.dest)
+			fakeRW, rr := safehttptest.NewFakeResponseWriter()
+
+			p := tt.policy()
+			if !p.Match(tt.cfg) {
+				t.Fatalf("p.Match(%#v): got false, want true", tt.cfg)
+			}
+			p.Before(fakeRW, req, tt.cfg)
+
+			if got := safehttp.StatusCode(rr.Code); got != tt.want {
+				t.Errorf("rr.Code got: %v want: %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRouteOverrideWithOtherConfig(t *testing.T) {
+	mb := safehttp.NewServeMuxConfig(nil)
+	mb.Intercept(fetchmetadata.ResourceIsolationPolicy(), fetchmetadata.FramingIsolationPolicy())
+	mux := mb.Mux()
+	h := safehttp.HandlerFunc(func(w safehttp.ResponseWriter, r *safehttp.IncomingRequest) safehttp.Result {
+		return w.Write(safehtml.HTMLEscaped("ok"))
+	})
+	mux.Handle("/embed", safehttp.MethodGet, h,
+		fetchmetadata.CrossSite{Modes: []string{"no-cors"}, Dests: []string{"image"}},
+		internalunsafeframing.AllowList{Hostnames: []string{"https://other.com"}})
+	mux.Handle("/strict", safehttp.MethodGet, h,
+		fetchmetadata.StrictIsolation{},
+		internalunsafeframing.Disable{SkipReports: true})
+
+	tests := []struct {
+		name string
+		path string
+		req  testHeaders
+		want safehttp.StatusCode
+	}{
+		{
+			name: "Cross-site image allowed by CrossSite",
+			path: "/embed",
+			req:  testHeaders{site: "cross-site", mode: "no-cors", dest: "image"},
+			want: safehttp.StatusOK,
+		},
+		{
+			name: "Same-site iframe allowed by AllowList",
+			path: "/embed",
+			req:  testHeaders{site: "same-site", mode: "navigate", dest: "iframe"},
+			want: safehttp.StatusOK,
+		},
+		{
+			name: "Cross-site script rejected",
+			path: "/embed",
+			req:  testHeaders{site: "cross-site", mode: "no-cors", dest: "script"},
+			want: safehttp.StatusForbidden,
+		},
+		{
+			name: "Same-origin iframe allowed",
+			path: "/strict",
+			req:  testHeaders{site: "same-origin", mode: "navigate", dest: "iframe"},
+			want: safehttp.StatusOK,
+		},
+		{
+			name: "Same-site request rejected by StrictIsolation",
+			path: "/strict",
+			req:  testHeaders{site: "same-site", mode: "cors", dest: "empty"},
+			want: safehttp.StatusForbidden,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(safehttp.MethodGet, "https://spaghetti.com"+tt.path, nil)
+			req.Header.Add("Sec-Fetch-Site", tt.req.site)
+			req.Header.Add("Sec-Fetch-Mode", tt.req.mode)
+			req.Header.Add("Sec-Fetch-Dest", tt.req.dest)
+			rr := httptest.NewRecorder()
+			mux.ServeHTTP(rr, req)
+
+			if got := safehttp.StatusCode(rr.Code); got != tt.want {
+				t.Errorf("rr.Code got: %v want: %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRouteOverridesConflict(t *testing.T) {
+	tests := []struct {
+		name      string
+		cfgs      []safehttp.InterceptorConfig
+		wantPanic bool
+	}{
+		{
+			name: "StrictIsolation and Disable",
+			cfgs: []safehttp.InterceptorConfig{
+				fetchmetadata.StrictIsolation{},
+				internalunsafefetchmetadata.DisableResourceIsolationPolicy{},
+			},
+		},
+		{
+			name: "CrossSite and StrictIsolation",
+			cfgs: []safehttp.InterceptorConfig{
+				fetchmetadata.CrossSite{},
+				fetchmetadata.StrictIsolation{},
+			},
+			wantPanic: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mb := safehttp.NewServeMuxConfig(nil)
+			mb.Intercept(fetchmetadata.ResourceIsolationPolicy())
+			mux := mb.Mux()
+			defer func() {
+				if r := recover(); (r != nil) != tt.wantPanic {
+					t.Errorf("mux.Handle: got panic %v, want panic: %v", r, tt.wantPanic)
+				}
+			}()
+			mux.Handle("/", safehttp.MethodGet, safehttp.HandlerFunc(func(w safehttp.ResponseWriter, r *safehttp.IncomingRequest) safehttp.Result {
+				return w.Write(safehtml.HTMLEscaped("ok"))
+			}), tt.cfgs...)
+		})
+	}
+}
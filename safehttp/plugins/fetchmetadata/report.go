@@ -0,0 +1,179 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fetchmetadata
+
+import (
+	"bytes"
+	"encoding/json"
+	"log"
+	"net/http"
+	"sync"
+
+	"github.com/google/go-safeweb/safehttp"
+)
+
+// ReportType is the type of the reports sent by the HTTPReporter, in the
+// format of the Reporting API.
+const ReportType = "fetch-metadata-violation"
+
+// Report describes a request a Policy rejected, or would have rejected if it
+// wasn't report-only or disabled for the route.
+type Report struct {
+	// Policy is the name of the policy, e.g. "RESOURCE_ISOLATION".
+	Policy string `json:"policy"`
+	// Enforced reports whether the request was rejected.
+	Enforced bool `json:"enforced"`
+	// Method is the method of the request.
+	Method string `json:"method"`
+	// URL is the URL of the request, and Path its path.
+	URL  string `json:"url"`
+	Path string `json:"path"`
+	// Site, Mode, Dest and User are the values of the Sec-Fetch-Site,
+	// Sec-Fetch-Mode, Sec-Fetch-Dest and Sec-Fetch-User headers.
+	Site string `json:"site"`
+	Mode string `json:"mode"`
+	Dest string `json:"dest"`
+	User string `json:"user,omitempty"`
+	// Referrer is the value of the Referer header.
+	Referrer string `json:"referrer,omitempty"`
+}
+
+func newReport(r *safehttp.IncomingRequest, policy string, enforced bool) Report {
+	h := r.Header
+	return Report{
+		Policy:   policy,
+		Enforced: enforced,
+		Method:   r.Method(),
+		URL:      r.URL().String(),
+		Path:     r.URL().Path(),
+		Site:     h.Get("Sec-Fetch-Site"),
+		Mode:     h.Get("Sec-Fetch-Mode"),
+		Dest:     h.Get("Sec-Fetch-Dest"),
+		User:     h.Get("Sec-Fetch-User"),
+		Referrer: h.Get("Referer"),
+	}
+}
+
+// Reporter receives the reports of the policies. It's called while the
+// request is handled, hence it must not block.
+type Reporter interface {
+	Report(Report)
+}
+
+// ReporterFunc adapts a function to a Reporter.
+type ReporterFunc func(Report)
+
+// Report calls f(rep).
+func (f ReporterFunc) Report(rep Report) {
+	f(rep)
+}
+
+// LogReporter logs the reports, like the policies without a Reporter. It can
+// be used with a SampledReporter to limit the log volume.
+var LogReporter Reporter = ReporterFunc(func(rep Report) {
+	log.Printf("Request for %s %q should be blocked by %s. Actually_blocked=%v", rep.Method, rep.URL, rep.Policy, rep.Enforced)
+})
+
+// DefaultReportQueueSize is the number of reports an HTTPReporter buffers
+// while the previous ones are sent.
+const DefaultReportQueueSize = 100
+
+// HTTPReporter sends the reports to a reporting endpoint in the format of the
+// Reporting API, with the "application/reports+json" Content-Type and the
+// ReportType type, e.g. to a handler created by the collector plugin.
+//
+// The reports are sent one at a time in the background. The reports received
+// while the queue is full are dropped.
+type HTTPReporter struct {
+	url    string
+	client *http.Client
+	done   chan struct{}
+
+	mu     sync.Mutex
+	queue  chan Report
+	closed bool
+}
+
+// NewHTTPReporter returns an HTTPReporter sending the reports to url with
+// client. If client is nil, http.DefaultClient is used.
+func NewHTTPReporter(url string, client *http.Client) *HTTPReporter {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	rep := &HTTPReporter{
+		url:    url,
+		client: client,
+		queue:  make(chan Report, DefaultReportQueueSize),
+		done:   make(chan struct{}),
+	}
+	go rep.run()
+	return rep
+}
+
+// Report queues the report, or drops it if the queue is full.
+func (rep *HTTPReporter) Report(r Report) {
+	rep.mu.Lock()
+	defer rep.mu.Unlock()
+	if rep.closed {
+		return
+	}
+	select {
+	case rep.queue <- r:
+	default:
+	}
+}
+
+// Close stops sending the reports, once the queued ones were sent. The
+// reports received after Close are dropped.
+func (rep *HTTPReporter) Close() {
+	rep.mu.Lock()
+	if !rep.closed {
+		rep.closed = true
+		close(rep.queue)
+	}
+	rep.mu.Unlock()
+	<-rep.done
+}
+
+func (rep *HTTPReporter) run() {
+	defer close(rep.done)
+	for r := range rep.queue {
+		if err := rep.send(r); err != nil {
+			log.Printf("fetchmetadata: sending a report to %s: %v", rep.url, err)
+		}
+	}
+}
+
+// reportingAPIReport is the serialization of a report in the format of the
+// Reporting API, see https://w3c.github.io/reporting/#serialize-reports.
+type reportingAPIReport struct {
+	Type string `json:"type"`
+	Age  int    `json:"age"`
+	URL  string `json:"url"`
+	Body Report `json:"body"`
+}
+
+func (rep *HTTPReporter) send(r Report) error {
+	b, err := json.Marshal([]reportingAPIReport{{Type: ReportType, URL: r.URL, Body: r}})
+	if err != nil {
+		return err
+	}
+	resp, err := rep.client.Post(rep.url, "application/reports+json", bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
@@ -0,0 +1,134 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fetchmetadata_test
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-safeweb/safehttp"
+	"github.com/google/go-safeweb/safehttp/plugins/collector"
+	"github.com/google/go-safeweb/safehttp/plugins/fetchmetadata"
+	"github.com/google/go-safeweb/safehttp/safehttptest"
+)
+
+func TestReporter(t *testing.T) {
+	tests := []struct {
+		name       string
+		reportOnly bool
+		site       string
+		want       []fetchmetadata.Report
+	}{
+		{
+			name: "Enforced",
+			site: "cross-site",
+			want: []fetchmetadata.Report{{
+				Policy:   "RESOURCE_ISOLATION",
+				Enforced: true,
+				Method:   "POST",
+				URL:      "https://spaghetti.com/carbonara?q=1",
+				Path:     "/carbonara",
+				Site:     "cross-site",
+				Mode:     "no-cors",
+				Dest:     "image",
+				Referrer: "https://evil.com/",
+			}},
+		},
+		{
+			name:       "Report only",
+			reportOnly: true,
+			site:       "cross-site",
+			want: []fetchmetadata.Report{{
+				Policy:   "RESOURCE_ISOLATION",
+				Method:   "POST",
+				URL:      "https://spaghetti.com/carbonara?q=1",
+				Path:     "/carbonara",
+				Site:     "cross-site",
+				Mode:     "no-cors",
+				Dest:     "image",
+				Referrer: "https://evil.com/",
+			}},
+		},
+		{
+			name: "Allowed",
+			site: "same-origin",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := safehttptest.NewRequest(safehttp.MethodPost, "https://spaghetti.com/carbonara?q=1", nil)
+			req.Header.Add("Sec-Fetch-Site", tt.site)
+			req.Header.Add("Sec-Fetch-Mode", "no-cors")
+			req.Header.Add("Sec-Fetch-Dest", "image")
+			req.Header.Add("Referer", "https://evil.com/")
+			fakeRW, _ := safehttptest.NewFakeResponseWriter()
+
+			var got []fetchmetadata.Report
+			p := fetchmetadata.ResourceIsolationPolicy()
+			p.ReportOnly = tt.reportOnly
+			p.Reporter = fetchmetadata.ReporterFunc(func(rep fetchmetadata.Report) { got = append(got, rep) })
+			p.Before(fakeRW, req, nil)
+
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("reports mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestHTTPReporter(t *testing.T) {
+	var got []collector.Report
+	mb := safehttp.NewServeMuxConfig(nil)
+	mux := mb.Mux()
+	mux.Handle("/collector", safehttp.MethodPost, collector.Handler(func(r collector.Report) {
+		got = append(got, r)
+	}, nil))
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	rep := fetchmetadata.NewHTTPReporter(srv.URL+"/collector", nil)
+	rep.Report(fetchmetadata.Report{
+		Policy:   "RESOURCE_ISOLATION",
+		Enforced: true,
+		Method:   "GET",
+		URL:      "https://spaghetti.com/carbonara",
+		Path:     "/carbonara",
+		Site:     "cross-site",
+		Mode:     "no-cors",
+		Dest:     "script",
+	})
+	rep.Close()
+	// Reports received after Close are dropped.
+	rep.Report(fetchmetadata.Report{})
+
+	want := []collector.Report{{
+		Type: fetchmetadata.ReportType,
+		URL:  "https://spaghetti.com/carbonara",
+		Body: map[string]interface{}{
+			"policy":   "RESOURCE_ISOLATION",
+			"enforced": true,
+			"method":   "GET",
+			"url":      "https://spaghetti.com/carbonara",
+			"path":     "/carbonara",
+			"site":     "cross-site",
+			"mode":     "no-cors",
+			"dest":     "script",
+		},
+	}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("collected reports mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -32,41 +32,48 @@ var (
 	}
 )
 
+// ResourceIsolationRule allows the requests which aren't cross-site, and the
+// cross-site top-level navigations with a safe method. It's the rule of the
+// ResourceIsolationPolicy.
+var ResourceIsolationRule Rule = RuleFunc(resourceIsolation)
+
+func resourceIsolation(r *safehttp.IncomingRequest) bool {
+	h := r.Header
+	if h.Get("Sec-Fetch-Site") != "cross-site" {
+		// The request is allowed to pass because one of the following applies:
+		// - Fetch Metadata is not supported by the browser
+		// - the request is same-origin, same-site or caused by the user
+		// explicitly interacting with the user-agent
+		return true
+	}
+
+	method := r.Method()
+	mode := h.Get("Sec-Fetch-Mode")
+	dest := h.Get("Sec-Fetch-Dest")
+	// Allow CORS options requests if neither Mode nor Dest is set.
+	// https://github.com/w3c/webappsec-fetch-metadata/issues/35
+	// https://bugs.chromium.org/p/chromium/issues/detail?id=979946
+	if mode == "" && dest == "" && method == safehttp.MethodOptions {
+		return true
+	}
+
+	if navigationalModes[mode] && navigationalDests[dest] && statePreservingMethods[method] {
+		// The request is cross-site, but a simple top-level navigation from a
+		// safe destination so we allow it to pass.
+		return true
+	}
+	if safehttp.IsLocalDev() {
+		log.Println("fetchmetadata plugin resource protection detected a potentially malicious request")
+	}
+	return false
+}
+
 // ResourceIsolationPolicy protects resources.
 //
 // See https://web.dev/fetch-metadata/ for more details.
 func ResourceIsolationPolicy() *Policy {
 	return &Policy{
-		isAllowed: func(r *safehttp.IncomingRequest) bool {
-			h := r.Header
-			if h.Get("Sec-Fetch-Site") != "cross-site" {
-				// The request is allowed to pass because one of the following applies:
-				// - Fetch Metadata is not supported by the browser
-				// - the request is same-origin, same-site or caused by the user
-				// explicitly interacting with the user-agent
-				return true
-			}
-
-			method := r.Method()
-			mode := h.Get("Sec-Fetch-Mode")
-			dest := h.Get("Sec-Fetch-Dest")
-			// Allow CORS options requests if neither Mode nor Dest is set.
-			// https://github.com/w3c/webappsec-fetch-metadata/issues/35
-			// https://bugs.chromium.org/p/chromium/issues/detail?id=979946
-			if mode == "" && dest == "" && method == safehttp.MethodOptions {
-				return true
-			}
-
-			if navigationalModes[mode] && navigationalDests[dest] && statePreservingMethods[method] {
-				// The request is cross-site, but a simple top-level navigation from a
-				// safe destination so we allow it to pass.
-				return true
-			}
-			if safehttp.IsLocalDev() {
-				log.Println("fetchmetadata plugin resource protection detected a potentially malicious request")
-			}
-			return false
-		},
+		rule: ResourceIsolationRule,
 		match: func(cfg safehttp.InterceptorConfig) bool {
 			_, ok := cfg.(internalunsafefetchmetadata.DisableResourceIsolationPolicy)
 			return ok
@@ -0,0 +1,71 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fetchmetadata
+
+import (
+	"hash/fnv"
+	"math"
+
+	"github.com/google/go-safeweb/safehttp"
+)
+
+// Rollout enforces a Policy for a fraction of the clients only: the
+// violations of the other clients are reported but not rejected. It can be
+// used to turn on the enforcement of a policy progressively on large sites.
+//
+// The clients are selected by a stable hash of their key, so the same clients
+// stay selected as the percentage grows. The fields must not be changed while
+// the policy is in use: a Reloadable policy can be used to change them.
+type Rollout struct {
+	// Percent is the percentage of the clients for which the policy is
+	// enforced, between 0 and 100.
+	Percent float64
+	// Key returns the key of the client of the request, e.g. its session
+	// identifier. If nil, the IP address of the client is used, see
+	// safehttp.IncomingRequest.ClientIP.
+	Key func(*safehttp.IncomingRequest) string
+}
+
+// enforced reports whether the policy is enforced for the client of the
+// request.
+func (ro *Rollout) enforced(r *safehttp.IncomingRequest) bool {
+	if ro.Percent >= 100 {
+		return true
+	}
+	if ro.Percent <= 0 {
+		return false
+	}
+	var key string
+	if ro.Key != nil {
+		key = ro.Key(r)
+	} else if ip := r.ClientIP(); ip != nil {
+		key = ip.String()
+	}
+	h := fnv.New64a()
+	h.Write([]byte(key))
+	return float64(mix(h.Sum64())) < ro.Percent/100*math.MaxUint64
+}
+
+// mix spreads the bits of the FNV hash, whose high bits are poorly distributed
+// for short inputs that only differ at the end, like IP addresses. This is the
+// finalizer of the SplitMix64 generator.
+func mix(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
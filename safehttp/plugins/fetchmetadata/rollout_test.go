@@ -0,0 +1,84 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fetchmetadata_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/go-safeweb/safehttp"
+	"github.com/google/go-safeweb/safehttp/plugins/fetchmetadata"
+	"github.com/google/go-safeweb/safehttp/safehttptest"
+)
+
+// rolloutStatus sends a violating request from the given session through a
+// policy rolled out to percent of the sessions.
+func rolloutStatus(t *testing.T, percent float64, session string) (safehttp.StatusCode, fetchmetadata.Report) {
+	t.Helper()
+	req := safehttptest.NewRequest(safehttp.MethodPost, "https://spaghetti.com/carbonara", nil)
+	req.Header.Add("Sec-Fetch-Site", "cross-site")
+	req.Header.Add("Sec-Fetch-Mode", "no-cors")
+	req.Header.Add("Sec-Fetch-Dest", "script")
+	req.Header.Add("X-Session", session)
+	fakeRW, rr := safehttptest.NewFakeResponseWriter()
+
+	var rep fetchmetadata.Report
+	p := fetchmetadata.ResourceIsolationPolicy()
+	p.Reporter = fetchmetadata.ReporterFunc(func(r fetchmetadata.Report) { rep = r })
+	p.Rollout = &fetchmetadata.Rollout{
+		Percent: percent,
+		Key:     func(r *safehttp.IncomingRequest) string { return r.Header.Get("X-Session") },
+	}
+	p.Before(fakeRW, req, nil)
+	return safehttp.StatusCode(rr.Code), rep
+}
+
+func TestRolloutBounds(t *testing.T) {
+	code, rep := rolloutStatus(t, 0, "session")
+	if code != safehttp.StatusOK || rep.Enforced {
+		t.Errorf("0%%: got code %v enforced %v, want %v false", code, rep.Enforced, safehttp.StatusOK)
+	}
+	if rep.Policy == "" {
+		t.Error("0%: the violation wasn't reported")
+	}
+	code, rep = rolloutStatus(t, 100, "session")
+	if code != safehttp.StatusForbidden || !rep.Enforced {
+		t.Errorf("100%%: got code %v enforced %v, want %v true", code, rep.Enforced, safehttp.StatusForbidden)
+	}
+}
+
+func TestRolloutPercentage(t *testing.T) {
+	const sessions = 1000
+	enforcedAt30 := map[string]bool{}
+	count := 0
+	for i := 0; i < sessions; i++ {
+		s := fmt.Sprintf("session-%d", i)
+		if code, _ := rolloutStatus(t, 30, s); code == safehttp.StatusForbidden {
+			enforcedAt30[s] = true
+			count++
+		}
+	}
+	if count < 250 || count > 350 {
+		t.Errorf("enforced sessions at 30%%: got %d of %d", count, sessions)
+	}
+	for s := range enforcedAt30 {
+		if code, _ := rolloutStatus(t, 30, s); code != safehttp.StatusForbidden {
+			t.Errorf("session %q: the decision isn't stable", s)
+		}
+		if code, _ := rolloutStatus(t, 60, s); code != safehttp.StatusForbidden {
+			t.Errorf("session %q: enforced at 30%% but not at 60%%", s)
+		}
+	}
+}
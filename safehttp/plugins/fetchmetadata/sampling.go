@@ -0,0 +1,158 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fetchmetadata
+
+import (
+	"math"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/go-safeweb/safehttp"
+)
+
+// MaxReportKeys is the maximum number of distinct ReportKeys counted by a
+// SampledReporter. The reports with a new key once it's reached are counted
+// with the OtherPath path, since the paths come from the requests and could
+// otherwise grow the counters without bounds.
+const MaxReportKeys = 1000
+
+// OtherPath is the Path of the ReportKey counting the reports over
+// MaxReportKeys.
+const OtherPath = "<other>"
+
+// ReportKey identifies the reports aggregated by a SampledReporter.
+type ReportKey struct {
+	Policy string
+	Site   string
+	Mode   string
+	Dest   string
+	Path   string
+}
+
+// SampledReporter forwards a sample of the reports to another Reporter, e.g.
+// so that high-traffic services running policies in report-only mode don't
+// produce unbounded report volumes. All the reports are counted by ReportKey,
+// and the counts can be exported as metrics.
+//
+// SampledReporter is safe for concurrent use. The sampling rate can be changed
+// at runtime with SetRate.
+type SampledReporter struct {
+	// Clock is used to limit the number of reports forwarded per second. If
+	// nil, safehttp.SystemClock is used.
+	Clock safehttp.Clock
+
+	next         Reporter
+	maxPerSecond int
+	// rate holds the bits of a float64.
+	rate uint64
+
+	mu      sync.Mutex
+	window  time.Time
+	sent    int
+	counts  map[ReportKey]uint64
+	dropped uint64
+}
+
+// NewSampledReporter returns a SampledReporter forwarding the given fraction of
+// the reports to next, and at most maxPerSecond reports per second if it's
+// positive.
+func NewSampledReporter(next Reporter, rate float64, maxPerSecond int) *SampledReporter {
+	s := &SampledReporter{
+		next:         next,
+		maxPerSecond: maxPerSecond,
+		counts:       map[ReportKey]uint64{},
+	}
+	s.SetRate(rate)
+	return s
+}
+
+// SetRate sets the fraction of the reports which are forwarded. It's clamped
+// to [0, 1].
+func (s *SampledReporter) SetRate(rate float64) {
+	rate = math.Max(0, math.Min(1, rate))
+	atomic.StoreUint64(&s.rate, math.Float64bits(rate))
+}
+
+// Rate returns the fraction of the reports which are forwarded.
+func (s *SampledReporter) Rate() float64 {
+	return math.Float64frombits(atomic.LoadUint64(&s.rate))
+}
+
+// Report counts the report and forwards it if it's sampled and the limit of
+// reports per second wasn't reached.
+func (s *SampledReporter) Report(rep Report) {
+	if !s.count(rep) {
+		return
+	}
+	s.next.Report(rep)
+}
+
+// count counts the report and reports whether it should be forwarded.
+func (s *SampledReporter) count(rep Report) bool {
+	key := ReportKey{Policy: rep.Policy, Site: rep.Site, Mode: rep.Mode, Dest: rep.Dest, Path: rep.Path}
+	rate := s.Rate()
+	sampled := rate >= 1 || rate > 0 && rand.Float64() < rate
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.counts[key]; !ok && len(s.counts) >= MaxReportKeys {
+		key.Path = OtherPath
+	}
+	s.counts[key]++
+	if !sampled {
+		return false
+	}
+	if s.maxPerSecond > 0 {
+		now := s.now()
+		if now.Sub(s.window) >= time.Second {
+			s.window = now
+			s.sent = 0
+		}
+		if s.sent >= s.maxPerSecond {
+			s.dropped++
+			return false
+		}
+		s.sent++
+	}
+	return true
+}
+
+// Counts returns the number of reports received, by key.
+func (s *SampledReporter) Counts() map[ReportKey]uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	counts := make(map[ReportKey]uint64, len(s.counts))
+	for k, v := range s.counts {
+		counts[k] = v
+	}
+	return counts
+}
+
+// Dropped returns the number of sampled reports which weren't forwarded
+// because of the limit of reports per second.
+func (s *SampledReporter) Dropped() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.dropped
+}
+
+func (s *SampledReporter) now() time.Time {
+	if s.Clock == nil {
+		return safehttp.SystemClock().Now()
+	}
+	return s.Clock.Now()
+}
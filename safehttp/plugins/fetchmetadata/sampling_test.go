@@ -0,0 +1,103 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fetchmetadata_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-safeweb/safehttp/plugins/fetchmetadata"
+)
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func TestSampledReporterLimit(t *testing.T) {
+	var got []string
+	clock := &fakeClock{now: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)}
+	s := fetchmetadata.NewSampledReporter(fetchmetadata.ReporterFunc(func(rep fetchmetadata.Report) {
+		got = append(got, rep.Path)
+	}), 1, 2)
+	s.Clock = clock
+
+	for _, p := range []string{"/a", "/b", "/c"} {
+		s.Report(fetchmetadata.Report{Policy: "RESOURCE_ISOLATION", Site: "cross-site", Path: p})
+	}
+	clock.now = clock.now.Add(time.Second)
+	s.Report(fetchmetadata.Report{Policy: "RESOURCE_ISOLATION", Site: "cross-site", Path: "/a"})
+
+	if diff := cmp.Diff([]string{"/a", "/b", "/a"}, got); diff != "" {
+		t.Errorf("forwarded reports mismatch (-want +got):\n%s", diff)
+	}
+	if got, want := s.Dropped(), uint64(1); got != want {
+		t.Errorf("s.Dropped(): got %v want %v", got, want)
+	}
+	want := map[fetchmetadata.ReportKey]uint64{
+		{Policy: "RESOURCE_ISOLATION", Site: "cross-site", Path: "/a"}: 2,
+		{Policy: "RESOURCE_ISOLATION", Site: "cross-site", Path: "/b"}: 1,
+		{Policy: "RESOURCE_ISOLATION", Site: "cross-site", Path: "/c"}: 1,
+	}
+	if diff := cmp.Diff(want, s.Counts()); diff != "" {
+		t.Errorf("s.Counts() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestSampledReporterRate(t *testing.T) {
+	forwarded := 0
+	s := fetchmetadata.NewSampledReporter(fetchmetadata.ReporterFunc(func(fetchmetadata.Report) {
+		forwarded++
+	}), 0, 0)
+	for i := 0; i < 10; i++ {
+		s.Report(fetchmetadata.Report{Path: "/"})
+	}
+	if forwarded != 0 {
+		t.Errorf("forwarded reports with rate 0: got %d want 0", forwarded)
+	}
+	if got, want := s.Counts()[fetchmetadata.ReportKey{Path: "/"}], uint64(10); got != want {
+		t.Errorf("count with rate 0: got %d want %d", got, want)
+	}
+
+	s.SetRate(2)
+	if got, want := s.Rate(), 1.0; got != want {
+		t.Errorf("s.Rate() after SetRate(2): got %v want %v", got, want)
+	}
+	for i := 0; i < 10; i++ {
+		s.Report(fetchmetadata.Report{Path: "/"})
+	}
+	if forwarded != 10 {
+		t.Errorf("forwarded reports with rate 1: got %d want 10", forwarded)
+	}
+}
+
+func TestSampledReporterMaxKeys(t *testing.T) {
+	s := fetchmetadata.NewSampledReporter(fetchmetadata.ReporterFunc(func(fetchmetadata.Report) {}), 0, 0)
+	for i := 0; i < fetchmetadata.MaxReportKeys+5; i++ {
+		s.Report(fetchmetadata.Report{Path: fmt.Sprintf("/%d", i)})
+	}
+	counts := s.Counts()
+	if got, want := len(counts), fetchmetadata.MaxReportKeys+1; got != want {
+		t.Errorf("len(s.Counts()): got %d want %d", got, want)
+	}
+	if got, want := counts[fetchmetadata.ReportKey{Path: fetchmetadata.OtherPath}], uint64(5); got != want {
+		t.Errorf("count of the other paths: got %d want %d", got, want)
+	}
+}
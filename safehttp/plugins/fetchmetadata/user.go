@@ -0,0 +1,81 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fetchmetadata
+
+import (
+	"log"
+
+	"github.com/google/go-safeweb/safehttp"
+)
+
+// UserActivationRule rejects the navigations which weren't triggered by the
+// user, i.e. without the "Sec-Fetch-User: ?1" header, like the ones
+// initiated by scripts or auto-submitted forms. Other requests are left to the
+// other policies. It's the rule of the UserActivationPolicy.
+var UserActivationRule Rule = RuleFunc(userActivation)
+
+func userActivation(r *safehttp.IncomingRequest) bool {
+	h := r.Header
+	if h.Get("Sec-Fetch-Site") == "" {
+		// Fetch Metadata is not supported by the browser.
+		return true
+	}
+	if !navigationalModes[h.Get("Sec-Fetch-Mode")] {
+		return true
+	}
+	if h.Get("Sec-Fetch-User") == "?1" {
+		return true
+	}
+	if safehttp.IsLocalDev() {
+		log.Println("fetchmetadata plugin user activation protection detected a potentially malicious request")
+	}
+	return false
+}
+
+// RequireUserActivation is a route configuration which enables the
+// UserActivationPolicy for the route, e.g. for a link deleting the account
+// of the user:
+//
+//	mux.Handle("/account/delete", safehttp.MethodGet, h, fetchmetadata.RequireUserActivation{})
+type RequireUserActivation struct {
+	// ReportOnly makes the policy report-only for the route.
+	ReportOnly bool
+}
+
+// UserActivationPolicy protects sensitive navigation endpoints from
+// navigations which weren't triggered by the user, even same-origin ones.
+// Unlike the other policies, it's only enforced on the routes registered with
+// the RequireUserActivation configuration, and it should be installed
+// together with the ResourceIsolationPolicy, which protects the routes from
+// the other cross-site requests.
+func UserActivationPolicy() *Policy {
+	return &Policy{
+		rule: UserActivationRule,
+		match: func(cfg safehttp.InterceptorConfig) bool {
+			_, ok := cfg.(RequireUserActivation)
+			return ok
+		},
+		skip: func(cfg safehttp.InterceptorConfig) (skip, skipReports bool) {
+			c, ok := cfg.(RequireUserActivation)
+			if !ok {
+				return true, true
+			}
+			return c.ReportOnly, false
+		},
+		signal:      "USER_ACTIVATION",
+		optIn:       true,
+		VaryHeaders: append([]string{"Sec-Fetch-User"}, DefaultVaryHeaders...),
+	}
+}
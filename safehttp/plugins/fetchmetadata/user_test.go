@@ -0,0 +1,124 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fetchmetadata_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-safeweb/safehttp"
+	"github.com/google/go-safeweb/safehttp/plugins/fetchmetadata"
+	"github.com/google/go-safeweb/safehttp/safehttptest"
+)
+
+func TestUserActivation(t *testing.T) {
+	tests := []struct {
+		name     string
+		cfg      safehttp.InterceptorConfig
+		site     string
+		mode     string
+		user     string
+		want     safehttp.StatusCode
+		wantVary []string
+	}{
+		{
+			name:     "User-activated cross-site navigation",
+			cfg:      fetchmetadata.RequireUserActivation{},
+			site:     "cross-site",
+			mode:     "navigate",
+			user:     "?1",
+			want:     safehttp.StatusOK,
+			wantVary: []string{"Sec-Fetch-User", "Sec-Fetch-Site", "Sec-Fetch-Mode", "Sec-Fetch-Dest"},
+		},
+		{
+			name:     "Scripted cross-site navigation",
+			cfg:      fetchmetadata.RequireUserActivation{},
+			site:     "cross-site",
+			mode:     "navigate",
+			want:     safehttp.StatusForbidden,
+			wantVary: []string{"Sec-Fetch-User", "Sec-Fetch-Site", "Sec-Fetch-Mode", "Sec-Fetch-Dest"},
+		},
+		{
+			name:     "Scripted same-origin navigation",
+			cfg:      fetchmetadata.RequireUserActivation{},
+			site:     "same-origin",
+			mode:     "navigate",
+			want:     safehttp.StatusForbidden,
+			wantVary: []string{"Sec-Fetch-User", "Sec-Fetch-Site", "Sec-Fetch-Mode", "Sec-Fetch-Dest"},
+		},
+		{
+			name:     "Non-navigational request",
+			cfg:      fetchmetadata.RequireUserActivation{},
+			site:     "same-origin",
+			mode:     "cors",
+			want:     safehttp.StatusOK,
+			wantVary: []string{"Sec-Fetch-User", "Sec-Fetch-Site", "Sec-Fetch-Mode", "Sec-Fetch-Dest"},
+		},
+		{
+			name:     "Fetch Metadata not supported",
+			cfg:      fetchmetadata.RequireUserActivation{},
+			want:     safehttp.StatusOK,
+			wantVary: []string{"Sec-Fetch-User", "Sec-Fetch-Site", "Sec-Fetch-Mode", "Sec-Fetch-Dest"},
+		},
+		{
+			name: "Report only",
+			cfg:  fetchmetadata.RequireUserActivation{ReportOnly: true},
+			site: "cross-site",
+			mode: "navigate",
+			want: safehttp.StatusOK,
+		},
+		{
+			name: "Not required by the route",
+			site: "cross-site",
+			mode: "navigate",
+			want: safehttp.StatusOK,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := safehttptest.NewRequest(safehttp.MethodGet, "https://spaghetti.com/account/delete", nil)
+			req.Header.Add("Sec-Fetch-Site", tt.site)
+			req.Header.Add("Sec-Fetch-Mode", tt.mode)
+			req.Header.Add("Sec-Fetch-Dest", "document")
+			req.Header.Add("Sec-Fetch-User", tt.user)
+			fakeRW, rr := safehttptest.NewFakeResponseWriter()
+
+			p := fetchmetadata.UserActivationPolicy()
+			p.Before(fakeRW, req, tt.cfg)
+			p.Commit(fakeRW, req, nil, tt.cfg)
+
+			if got := safehttp.StatusCode(rr.Code); got != tt.want {
+				t.Errorf("rr.Code got: %v want: %v", got, tt.want)
+			}
+			if diff := cmp.Diff(tt.wantVary, rr.Header().Values("Vary")); diff != "" {
+				t.Errorf("rr.Header().Values(\"Vary\") mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestUserActivationMatch(t *testing.T) {
+	p := fetchmetadata.UserActivationPolicy()
+	if !p.Match(fetchmetadata.RequireUserActivation{}) {
+		t.Error("p.Match(RequireUserActivation{}): got false, want true")
+	}
+	// It must not conflict with the route overrides of the other policies.
+	if p.Match(fetchmetadata.StrictIsolation{}) {
+		t.Error("p.Match(StrictIsolation{}): got true, want false")
+	}
+	if fetchmetadata.ResourceIsolationPolicy().Match(fetchmetadata.RequireUserActivation{}) {
+		t.Error("ResourceIsolationPolicy().Match(RequireUserActivation{}): got true, want false")
+	}
+}
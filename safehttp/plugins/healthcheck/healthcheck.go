@@ -0,0 +1,77 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package healthcheck provides the handlers of the liveness and readiness
+// probes of orchestrators like Kubernetes, based on the lifecycle state of a
+// safehttp.Server.
+//
+// # Usage
+//
+//	s := &safehttp.Server{Addr: ":8080", Mux: mux}
+//	healthcheck.Register(mux, s, db.PingContext)
+//
+// The handlers are registered in a ServeMux like any other handler, so the
+// installed interceptors run for them: a hostcheck plugin, for instance, must
+// allow the host used by the probes, or the handlers can be registered in the
+// mux of a separate Server only reachable from the orchestrator.
+package healthcheck
+
+import (
+	"context"
+
+	"github.com/google/go-safeweb/safehttp"
+)
+
+const (
+	// LivenessPattern is the pattern of the liveness handler.
+	LivenessPattern = "/healthz"
+	// ReadinessPattern is the pattern of the readiness handler.
+	ReadinessPattern = "/readyz"
+)
+
+// Check is an additional readiness check, e.g. of the connectivity to a
+// database. It returns an error if the dependency isn't available, and must
+// return quickly, in particular when the context is done.
+type Check func(ctx context.Context) error
+
+// Register registers the liveness and readiness handlers of the server in
+// the mux.
+//
+// The liveness handler answers with 204 No Content while the server is
+// running, including while it's being shut down, and 503 Service Unavailable
+// otherwise.
+//
+// The readiness handler answers with 204 No Content if the server accepts
+// connections and all the checks succeed, and with 503 Service Unavailable
+// otherwise, e.g. once Shutdown was called, so that load balancers stop
+// sending traffic to the server while it drains.
+func Register(mux *safehttp.ServeMux, s *safehttp.Server, checks ...Check) {
+	mux.Handle(LivenessPattern, safehttp.MethodGet, safehttp.HandlerFunc(func(w safehttp.ResponseWriter, r *safehttp.IncomingRequest) safehttp.Result {
+		if !s.Live() {
+			return w.WriteError(safehttp.StatusServiceUnavailable)
+		}
+		return w.Write(safehttp.NoContentResponse{})
+	}))
+	mux.Handle(ReadinessPattern, safehttp.MethodGet, safehttp.HandlerFunc(func(w safehttp.ResponseWriter, r *safehttp.IncomingRequest) safehttp.Result {
+		if !s.Ready() {
+			return w.WriteError(safehttp.StatusServiceUnavailable)
+		}
+		for _, c := range checks {
+			if err := c(r.Context()); err != nil {
+				return w.WriteError(safehttp.StatusServiceUnavailable)
+			}
+		}
+		return w.Write(safehttp.NoContentResponse{})
+	}))
+}
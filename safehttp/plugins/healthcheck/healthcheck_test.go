@@ -0,0 +1,116 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package healthcheck_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/go-safeweb/safehttp"
+	"github.com/google/go-safeweb/safehttp/plugins/healthcheck"
+)
+
+func probe(t *testing.T, addr, pattern string) int {
+	t.Helper()
+	client := &http.Client{Transport: &http.Transport{DisableKeepAlives: true}}
+	resp, err := client.Get("http://" + addr + pattern)
+	if err != nil {
+		t.Fatalf("http.Get: %v", err)
+	}
+	resp.Body.Close()
+	return resp.StatusCode
+}
+
+func TestHealthcheck(t *testing.T) {
+	var dbErr error
+	mux := safehttp.NewServeMuxConfig(nil).Mux()
+	s := &safehttp.Server{Mux: mux}
+	healthcheck.Register(mux, s, func(context.Context) error { return dbErr })
+
+	var readyStates []safehttp.ServerState
+	s.OnStart = []func() error{func() error {
+		readyStates = append(readyStates, s.State())
+		return nil
+	}}
+	ready := make(chan struct{})
+	s.OnReady = []func(){func() {
+		readyStates = append(readyStates, s.State())
+		close(ready)
+	}}
+
+	if got, want := s.State(), safehttp.ServerNew; got != want {
+		t.Errorf("s.State() before start: got %v want %v", got, want)
+	}
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("net.Listen: %v", err)
+	}
+	go s.Serve(l)
+	<-ready
+	addr := l.Addr().String()
+
+	if len(readyStates) != 2 || readyStates[0] != safehttp.ServerStarting || readyStates[1] != safehttp.ServerReady {
+		t.Errorf("states seen by the hooks: got %v, want [starting ready]", readyStates)
+	}
+	if got, want := probe(t, addr, healthcheck.LivenessPattern), http.StatusNoContent; got != want {
+		t.Errorf("liveness: got %v want %v", got, want)
+	}
+	if got, want := probe(t, addr, healthcheck.ReadinessPattern), http.StatusNoContent; got != want {
+		t.Errorf("readiness: got %v want %v", got, want)
+	}
+
+	dbErr = errors.New("database is down")
+	if got, want := probe(t, addr, healthcheck.ReadinessPattern), http.StatusServiceUnavailable; got != want {
+		t.Errorf("readiness with a failed check: got %v want %v", got, want)
+	}
+	if got, want := probe(t, addr, healthcheck.LivenessPattern), http.StatusNoContent; got != want {
+		t.Errorf("liveness with a failed check: got %v want %v", got, want)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := s.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+	if got, want := s.State(), safehttp.ServerClosed; got != want {
+		t.Errorf("s.State() after Shutdown: got %v want %v", got, want)
+	}
+	if s.Live() || s.Ready() {
+		t.Errorf("after Shutdown: got Live() %v Ready() %v, want false", s.Live(), s.Ready())
+	}
+}
+
+func TestOnStartError(t *testing.T) {
+	s := &safehttp.Server{
+		Mux:     safehttp.NewServeMuxConfig(nil).Mux(),
+		OnStart: []func() error{func() error { return errors.New("no config") }},
+		OnReady: []func(){func() { t.Error("OnReady called") }},
+	}
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("net.Listen: %v", err)
+	}
+	defer l.Close()
+	if err := s.Serve(l); err == nil || err.Error() != "no config" {
+		t.Errorf("s.Serve: got %v, want the OnStart error", err)
+	}
+	if got, want := s.State(), safehttp.ServerClosed; got != want {
+		t.Errorf("s.State(): got %v want %v", got, want)
+	}
+}
@@ -37,6 +37,9 @@ type Rule struct {
 	// WithAttributes is a filter applied on tags to decide whether to run the Rule:
 	// only tags with the given attributes key:value will be matched.
 	WithAttributes map[string]string
+	// WithoutAttributes is a filter applied on tags to decide whether to run the Rule:
+	// tags with any of the given attributes, whatever their value, won't be matched.
+	WithoutAttributes []string
 	// AddAttributes is a list of strings to add to the HTML as attributes.
 	// All the given strings will be appended verbatim after the matched tag so they
 	// should be prefixed with a space.
@@ -61,24 +64,36 @@ var CSPNoncesDefault = CSPNonces(`nonce="{{` + CSPNoncesDefaultFuncName + `}}"`)
 
 // CSPNonces constructs a Config to add CSP nonces to a template. The given nonce
 // attribute will be automatically prefixed with the required empty space.
+// Tags which already have a nonce attribute are left untouched.
 func CSPNonces(nonceAttr string) TransformConfig {
 	nonceAttr = " " + nonceAttr
+	noNonce := []string{"nonce"}
 	return TransformConfig{
 		Rule{
-			Name:          "Nonces for scripts",
-			OnTag:         "script",
-			AddAttributes: []string{nonceAttr},
+			Name:              "Nonces for scripts",
+			OnTag:             "script",
+			WithoutAttributes: noNonce,
+			AddAttributes:     []string{nonceAttr},
 		},
 		Rule{
-			Name:           "Nonces for link as=script rel=preload",
-			OnTag:          "link",
-			WithAttributes: map[string]string{"rel": "preload", "as": "script"},
-			AddAttributes:  []string{nonceAttr},
+			Name:              "Nonces for link as=script rel=preload",
+			OnTag:             "link",
+			WithAttributes:    map[string]string{"rel": "preload", "as": "script"},
+			WithoutAttributes: noNonce,
+			AddAttributes:     []string{nonceAttr},
 		},
 		Rule{
-			Name:          "Nonces for styles",
-			OnTag:         "style",
-			AddAttributes: []string{nonceAttr},
+			Name:              "Nonces for link rel=modulepreload",
+			OnTag:             "link",
+			WithAttributes:    map[string]string{"rel": "modulepreload"},
+			WithoutAttributes: noNonce,
+			AddAttributes:     []string{nonceAttr},
+		},
+		Rule{
+			Name:              "Nonces for styles",
+			OnTag:             "style",
+			WithoutAttributes: noNonce,
+			AddAttributes:     []string{nonceAttr},
 		},
 	}
 }
@@ -271,6 +286,12 @@ func (r rewriter) processTag() error {
 					break
 				}
 			}
+			for _, k := range r.WithoutAttributes {
+				if _, ok := attributes[k]; ok {
+					match = false
+					break
+				}
+			}
 			if match {
 				triggeredRules = append(triggeredRules, r)
 			}
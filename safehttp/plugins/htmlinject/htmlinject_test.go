@@ -177,6 +177,20 @@ h1 {
 <script nonce="{{CSPNonce}}" type="application/javascript">alert("script")</script>
 </body>
 </html>
+`,
+	},
+	{
+		name: "keep existing CSP nonces",
+		csp:  true,
+		in: `
+<link rel=modulepreload href="gopher.mjs">
+<script nonce="{{CSPNonce}}">alert("script")</script>
+<style nonce="static">h1 {}</style>
+`,
+		want: `
+<link nonce="{{CSPNonce}}" rel=modulepreload href="gopher.mjs">
+<script nonce="{{CSPNonce}}">alert("script")</script>
+<style nonce="static">h1 {}</style>
 `,
 	},
 	{
@@ -0,0 +1,231 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package origincheck_test
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-safeweb/safehttp"
+	"github.com/google/go-safeweb/safehttp/plugins/fetchmetadata"
+	"github.com/google/go-safeweb/safehttp/plugins/origincheck"
+	"github.com/google/go-safeweb/safehttp/safehttptest"
+)
+
+func TestOriginCheck(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		headers    map[string]string
+		wantStatus safehttp.StatusCode
+	}{
+		{
+			name:       "Allowed origin POST",
+			method:     safehttp.MethodPost,
+			headers:    map[string]string{"Origin": "https://example.com"},
+			wantStatus: safehttp.StatusOK,
+		},
+		{
+			name:       "Allowed origin POST, different case",
+			method:     safehttp.MethodPost,
+			headers:    map[string]string{"Origin": "https://EXAMPLE.com"},
+			wantStatus: safehttp.StatusOK,
+		},
+		{
+			name:       "Foreign origin POST",
+			method:     safehttp.MethodPost,
+			headers:    map[string]string{"Origin": "https://evil.com"},
+			wantStatus: safehttp.StatusForbidden,
+		},
+		{
+			name:       "Null origin POST",
+			method:     safehttp.MethodPost,
+			headers:    map[string]string{"Origin": "null"},
+			wantStatus: safehttp.StatusForbidden,
+		},
+		{
+			name:       "Allowed Referer-only POST",
+			method:     safehttp.MethodPost,
+			headers:    map[string]string{"Referer": "https://example.com/some/page?q=1"},
+			wantStatus: safehttp.StatusOK,
+		},
+		{
+			name:       "Foreign Referer-only POST",
+			method:     safehttp.MethodPost,
+			headers:    map[string]string{"Referer": "https://evil.com/example.com"},
+			wantStatus: safehttp.StatusForbidden,
+		},
+		{
+			name:       "Malformed Referer-only POST",
+			method:     safehttp.MethodPost,
+			headers:    map[string]string{"Referer": "::not a url"},
+			wantStatus: safehttp.StatusForbidden,
+		},
+		{
+			name:       "No Origin nor Referer POST",
+			method:     safehttp.MethodPost,
+			wantStatus: safehttp.StatusForbidden,
+		},
+		{
+			name:       "Foreign origin GET",
+			method:     safehttp.MethodGet,
+			headers:    map[string]string{"Origin": "https://evil.com"},
+			wantStatus: safehttp.StatusOK,
+		},
+		{
+			name:       "Same-origin GET without Origin",
+			method:     safehttp.MethodGet,
+			headers:    map[string]string{"Sec-Fetch-Site": "same-origin"},
+			wantStatus: safehttp.StatusOK,
+		},
+		{
+			name:   "Same-origin Fetch Metadata with opaque Origin",
+			method: safehttp.MethodPost,
+			headers: map[string]string{
+				"Sec-Fetch-Site": "same-origin",
+				"Origin":         "null",
+			},
+			wantStatus: safehttp.StatusOK,
+		},
+		{
+			name:   "Cross-site Fetch Metadata with foreign Origin",
+			method: safehttp.MethodPut,
+			headers: map[string]string{
+				"Sec-Fetch-Site": "cross-site",
+				"Origin":         "https://evil.com",
+			},
+			wantStatus: safehttp.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := safehttptest.NewRequest(tt.method, "https://example.com/", nil)
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+			fakeRW, rr := safehttptest.NewFakeResponseWriter()
+
+			origincheck.New("https://example.com").Before(fakeRW, req, nil)
+
+			if got, want := rr.Code, int(tt.wantStatus); got != want {
+				t.Errorf("rr.Code got: %v want: %v", got, want)
+			}
+		})
+	}
+}
+
+func TestAllowMissing(t *testing.T) {
+	tests := []struct {
+		name       string
+		headers    map[string]string
+		wantStatus safehttp.StatusCode
+	}{
+		{
+			name:       "No Origin nor Referer",
+			wantStatus: safehttp.StatusOK,
+		},
+		{
+			name:       "Foreign origin",
+			headers:    map[string]string{"Origin": "https://evil.com"},
+			wantStatus: safehttp.StatusForbidden,
+		},
+		{
+			name:       "Foreign Referer",
+			headers:    map[string]string{"Referer": "https://evil.com/"},
+			wantStatus: safehttp.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := safehttptest.NewRequest(safehttp.MethodPost, "https://example.com/", nil)
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+			fakeRW, rr := safehttptest.NewFakeResponseWriter()
+
+			it := origincheck.New("https://example.com")
+			it.AllowMissing = true
+			it.Before(fakeRW, req, nil)
+
+			if got, want := rr.Code, int(tt.wantStatus); got != want {
+				t.Errorf("rr.Code got: %v want: %v", got, want)
+			}
+		})
+	}
+}
+
+func TestComposesWithFetchMetadata(t *testing.T) {
+	tests := []struct {
+		name       string
+		headers    map[string]string
+		wantStatus safehttp.StatusCode
+	}{
+		{
+			name: "Same-origin POST",
+			headers: map[string]string{
+				"Sec-Fetch-Site": "same-origin",
+				"Sec-Fetch-Mode": "navigate",
+				"Sec-Fetch-Dest": "document",
+				"Origin":         "https://example.com",
+			},
+			wantStatus: safehttp.StatusNoContent,
+		},
+		{
+			name: "Cross-site POST",
+			headers: map[string]string{
+				"Sec-Fetch-Site": "cross-site",
+				"Sec-Fetch-Mode": "navigate",
+				"Sec-Fetch-Dest": "document",
+				"Origin":         "https://evil.com",
+			},
+			wantStatus: safehttp.StatusForbidden,
+		},
+		{
+			name:       "Legacy browser same-origin POST",
+			headers:    map[string]string{"Origin": "https://example.com"},
+			wantStatus: safehttp.StatusNoContent,
+		},
+		{
+			name:       "Legacy browser cross-site POST",
+			headers:    map[string]string{"Origin": "https://evil.com"},
+			wantStatus: safehttp.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mb := safehttp.NewServeMuxConfig(nil)
+			mb.Intercept(fetchmetadata.ResourceIsolationPolicy())
+			mb.Intercept(origincheck.New("https://example.com"))
+			mux := mb.Mux()
+			mux.Handle("/", safehttp.MethodPost, safehttp.HandlerFunc(func(w safehttp.ResponseWriter, r *safehttp.IncomingRequest) safehttp.Result {
+				return w.Write(safehttp.NoContentResponse{})
+			}))
+
+			req := httptest.NewRequest(safehttp.MethodPost, "https://example.com/", nil)
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+			rr := httptest.NewRecorder()
+			mux.ServeHTTP(rr, req)
+
+			if got, want := rr.Code, int(tt.wantStatus); got != want {
+				t.Errorf("rr.Code got: %v want: %v", got, want)
+			}
+		})
+	}
+}
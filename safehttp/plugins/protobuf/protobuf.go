@@ -0,0 +1,152 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package protobuf provides a dispatcher extension writing protocol buffer
+// messages, either as "application/x-protobuf" or framed for gRPC-Web
+// clients.
+//
+// The package doesn't depend on a protocol buffer implementation: messages
+// are serialized with the MarshalFunc passed to Register, e.g.
+//
+//	protobuf.Register(d, func(m interface{}) ([]byte, error) {
+//		return proto.Marshal(m.(proto.Message))
+//	})
+//
+// where d is the safehttp.DispatcherRegistry of the ServeMuxConfig. The
+// responses are sent with the "X-Content-Type-Options: nosniff" header, so
+// that browsers never interpret the binary messages as active content.
+//
+// More info:
+//   - gRPC-Web protocol: https://github.com/grpc/grpc/blob/master/doc/PROTOCOL-WEB.md
+package protobuf
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/google/go-safeweb/safehttp"
+)
+
+// MarshalFunc serializes a protocol buffer message.
+type MarshalFunc func(m interface{}) ([]byte, error)
+
+// Response is a protocol buffer message, written as "application/x-protobuf".
+type Response struct {
+	Message interface{}
+}
+
+// GRPCWebResponse is the response to a gRPC-Web call. It's always sent with
+// 200 OK: the outcome of the call is carried by Status and StatusMessage, in
+// the trailer frame which follows the messages.
+type GRPCWebResponse struct {
+	// Messages are the response messages. Unary calls have just one.
+	Messages []interface{}
+	// Status is the gRPC status code, e.g. 0 for OK or 5 for NOT_FOUND.
+	Status int
+	// StatusMessage is an optional description of the status.
+	StatusMessage string
+	// Text selects the "application/grpc-web-text" format, where the frames
+	// are base64-encoded. It's used by clients which can't read binary
+	// responses and should be set when the request was sent in this format,
+	// see IsGRPCWebText.
+	Text bool
+}
+
+// IsGRPCWebText reports whether the request uses the
+// "application/grpc-web-text" format.
+func IsGRPCWebText(r *safehttp.IncomingRequest) bool {
+	return strings.HasPrefix(r.Header.Get("Content-Type"), "application/grpc-web-text")
+}
+
+// Register registers Response and GRPCWebResponse in the DispatcherRegistry,
+// to be serialized with marshal.
+func Register(d *safehttp.DispatcherRegistry, marshal MarshalFunc) {
+	d.Register(Response{}, func(rw http.ResponseWriter, resp safehttp.Response) error {
+		b, err := marshal(resp.(Response).Message)
+		if err != nil {
+			return err
+		}
+		rw.Header().Set("Content-Type", "application/x-protobuf")
+		_, err = rw.Write(b)
+		return err
+	})
+	d.Register(GRPCWebResponse{}, func(rw http.ResponseWriter, resp safehttp.Response) error {
+		return writeGRPCWeb(rw, resp.(GRPCWebResponse), marshal)
+	})
+}
+
+const (
+	dataFrame    byte = 0x00
+	trailerFrame byte = 0x80
+)
+
+func writeGRPCWeb(rw http.ResponseWriter, resp GRPCWebResponse, marshal MarshalFunc) error {
+	// The messages are serialized before anything is sent, so that a
+	// serialization error results in a 500 instead of a truncated stream.
+	var body bytes.Buffer
+	for _, m := range resp.Messages {
+		b, err := marshal(m)
+		if err != nil {
+			return err
+		}
+		appendFrame(&body, dataFrame, b, resp.Text)
+	}
+	trailer := fmt.Sprintf("grpc-status: %d\r\n", resp.Status)
+	if resp.StatusMessage != "" {
+		trailer += fmt.Sprintf("grpc-message: %s\r\n", percentEncode(resp.StatusMessage))
+	}
+	appendFrame(&body, trailerFrame, []byte(trailer), resp.Text)
+
+	if resp.Text {
+		rw.Header().Set("Content-Type", "application/grpc-web-text+proto")
+	} else {
+		rw.Header().Set("Content-Type", "application/grpc-web+proto")
+	}
+	_, err := body.WriteTo(rw)
+	return err
+}
+
+// appendFrame appends a frame with the given flag and payload to buf, which
+// is base64-encoded in text mode.
+func appendFrame(buf *bytes.Buffer, flag byte, payload []byte, text bool) {
+	frame := make([]byte, 5, 5+len(payload))
+	frame[0] = flag
+	binary.BigEndian.PutUint32(frame[1:], uint32(len(payload)))
+	frame = append(frame, payload...)
+	if !text {
+		buf.Write(frame)
+		return
+	}
+	// Clients decode the concatenation of padded base64 chunks.
+	buf.WriteString(base64.StdEncoding.EncodeToString(frame))
+}
+
+// percentEncode encodes the grpc-message as defined by the gRPC protocol: the
+// bytes outside of the printable ASCII range and '%' are percent-encoded.
+func percentEncode(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c < 0x20 || c > 0x7e || c == '%' {
+			fmt.Fprintf(&b, "%%%02X", c)
+			continue
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
@@ -0,0 +1,150 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package protobuf_test
+
+import (
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-safeweb/safehttp"
+	"github.com/google/go-safeweb/safehttp/plugins/protobuf"
+)
+
+// fakeMessage is serialized as its bytes.
+type fakeMessage []byte
+
+func marshal(m interface{}) ([]byte, error) {
+	msg, ok := m.(fakeMessage)
+	if !ok {
+		return nil, errors.New("not a message")
+	}
+	return msg, nil
+}
+
+func serve(t *testing.T, resp safehttp.Response, contentType string) *httptest.ResponseRecorder {
+	t.Helper()
+	d := safehttp.NewDispatcherRegistry(nil)
+	protobuf.Register(d, marshal)
+	mux := safehttp.NewServeMuxConfig(d).Mux()
+	mux.Handle("/", safehttp.MethodPost, safehttp.HandlerFunc(func(w safehttp.ResponseWriter, r *safehttp.IncomingRequest) safehttp.Result {
+		if g, ok := resp.(protobuf.GRPCWebResponse); ok {
+			g.Text = protobuf.IsGRPCWebText(r)
+			resp = g
+		}
+		return w.Write(resp)
+	}))
+	req := httptest.NewRequest(safehttp.MethodPost, "http://foo.com/", strings.NewReader(""))
+	req.Header.Set("Content-Type", contentType)
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, req)
+	return rr
+}
+
+func TestResponses(t *testing.T) {
+	tests := []struct {
+		name            string
+		resp            safehttp.Response
+		reqContentType  string
+		wantContentType string
+		wantBody        string
+	}{
+		{
+			name:            "Protobuf",
+			resp:            protobuf.Response{Message: fakeMessage("\x08\x96\x01")},
+			reqContentType:  "application/x-protobuf",
+			wantContentType: "application/x-protobuf",
+			wantBody:        "\x08\x96\x01",
+		},
+		{
+			name:            "gRPC-Web",
+			resp:            protobuf.GRPCWebResponse{Messages: []interface{}{fakeMessage("ab"), fakeMessage("c")}},
+			reqContentType:  "application/grpc-web+proto",
+			wantContentType: "application/grpc-web+proto",
+			wantBody:        "\x00\x00\x00\x00\x02ab\x00\x00\x00\x00\x01c\x80\x00\x00\x00\x10grpc-status: 0\r\n",
+		},
+		{
+			name:            "gRPC-Web error",
+			resp:            protobuf.GRPCWebResponse{Status: 5, StatusMessage: "not found: 100%"},
+			reqContentType:  "application/grpc-web+proto",
+			wantContentType: "application/grpc-web+proto",
+			wantBody:        "\x80\x00\x00\x00\x31grpc-status: 5\r\ngrpc-message: not found: 100%25\r\n",
+		},
+		{
+			name:            "gRPC-Web text",
+			resp:            protobuf.GRPCWebResponse{Messages: []interface{}{fakeMessage("ab")}},
+			reqContentType:  "application/grpc-web-text",
+			wantContentType: "application/grpc-web-text+proto",
+			wantBody:        "AAAAAAJhYg==gAAAABBncnBjLXN0YXR1czogMA0K",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := serve(t, tt.resp, tt.reqContentType)
+
+			if got, want := rr.Code, int(safehttp.StatusOK); got != want {
+				t.Errorf("rr.Code: got %v want %v", got, want)
+			}
+			if got := rr.Header().Get("Content-Type"); got != tt.wantContentType {
+				t.Errorf(`rr.Header().Get("Content-Type"): got %q want %q`, got, tt.wantContentType)
+			}
+			if got, want := rr.Header().Get("X-Content-Type-Options"), "nosniff"; got != want {
+				t.Errorf(`rr.Header().Get("X-Content-Type-Options"): got %q want %q`, got, want)
+			}
+			if got := rr.Body.String(); got != tt.wantBody {
+				t.Errorf("rr.Body: got %q want %q", got, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestMarshalError(t *testing.T) {
+	tests := []struct {
+		name string
+		resp safehttp.Response
+	}{
+		{
+			name: "Protobuf",
+			resp: protobuf.Response{Message: "not a message"},
+		},
+		{
+			name: "gRPC-Web",
+			resp: protobuf.GRPCWebResponse{Messages: []interface{}{fakeMessage("a"), "not a message"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			defer func() {
+				if r := recover(); r == nil {
+					t.Error("expected panic")
+				}
+				if got := rr.Body.String(); got != "" {
+					t.Errorf("rr.Body: got %q, want nothing written", got)
+				}
+			}()
+			d := safehttp.NewDispatcherRegistry(nil)
+			protobuf.Register(d, marshal)
+			mux := safehttp.NewServeMuxConfig(d).Mux()
+			mux.Handle("/", safehttp.MethodGet, safehttp.HandlerFunc(func(w safehttp.ResponseWriter, r *safehttp.IncomingRequest) safehttp.Result {
+				return w.Write(tt.resp)
+			}))
+			mux.ServeHTTP(rr, httptest.NewRequest(safehttp.MethodGet, "http://foo.com/", nil))
+		})
+	}
+}
@@ -0,0 +1,158 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package requireheaders_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/google/go-safeweb/safehttp"
+	"github.com/google/go-safeweb/safehttp/plugins/requireheaders"
+)
+
+type errorRecorder struct {
+	safehttp.DefaultDispatcher
+	errs []safehttp.ErrorResponse
+}
+
+func (d *errorRecorder) Error(rw http.ResponseWriter, resp safehttp.ErrorResponse) error {
+	d.errs = append(d.errs, resp)
+	return d.DefaultDispatcher.Error(rw, resp)
+}
+
+var errUnsupported = errors.New("unsupported version")
+
+func TestRequireHeaders(t *testing.T) {
+	it := requireheaders.Interceptor{
+		Headers: []requireheaders.Header{
+			{Name: "authorization"},
+			{
+				Name: "X-API-Version",
+				Validate: func(v string) error {
+					if v != "2" {
+						return errUnsupported
+					}
+					return nil
+				},
+			},
+		},
+	}
+	tests := []struct {
+		name       string
+		headers    map[string]string
+		wantStatus safehttp.StatusCode
+		wantErr    *requireheaders.Error
+	}{
+		{
+			name:       "All present",
+			headers:    map[string]string{"Authorization": "Bearer token", "X-Api-Version": "2"},
+			wantStatus: safehttp.StatusNoContent,
+		},
+		{
+			name:       "First missing",
+			headers:    map[string]string{"X-Api-Version": "2"},
+			wantStatus: safehttp.StatusBadRequest,
+			wantErr:    &requireheaders.Error{Header: "Authorization"},
+		},
+		{
+			name:       "All missing",
+			wantStatus: safehttp.StatusBadRequest,
+			wantErr:    &requireheaders.Error{Header: "Authorization"},
+		},
+		{
+			name:       "Second missing",
+			headers:    map[string]string{"Authorization": "Bearer token"},
+			wantStatus: safehttp.StatusBadRequest,
+			wantErr:    &requireheaders.Error{Header: "X-Api-Version"},
+		},
+		{
+			name:       "Present but invalid",
+			headers:    map[string]string{"Authorization": "Bearer token", "X-Api-Version": "1"},
+			wantStatus: safehttp.StatusBadRequest,
+			wantErr:    &requireheaders.Error{Header: "X-Api-Version", Err: errUnsupported},
+		},
+		{
+			name:       "Present but empty is not missing",
+			headers:    map[string]string{"Authorization": "", "X-Api-Version": "2"},
+			wantStatus: safehttp.StatusNoContent,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &errorRecorder{}
+			mb := safehttp.NewServeMuxConfig(d)
+			mb.Intercept(it)
+			mux := mb.Mux()
+			mux.Handle("/", safehttp.MethodGet, safehttp.HandlerFunc(func(w safehttp.ResponseWriter, r *safehttp.IncomingRequest) safehttp.Result {
+				return w.Write(safehttp.NoContentResponse{})
+			}))
+
+			req := httptest.NewRequest(safehttp.MethodGet, "http://foo.com/", nil)
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+			rr := httptest.NewRecorder()
+			mux.ServeHTTP(rr, req)
+
+			if got, want := rr.Code, int(tt.wantStatus); got != want {
+				t.Errorf("rr.Code got: %v want: %v", got, want)
+			}
+			var want []safehttp.ErrorResponse
+			if tt.wantErr != nil {
+				want = append(want, *tt.wantErr)
+			}
+			if diff := cmp.Diff(want, d.errs, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("written errors mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestErrorMessage(t *testing.T) {
+	tests := []struct {
+		err  requireheaders.Error
+		want string
+	}{
+		{
+			err:  requireheaders.Error{Header: "X-Api-Version"},
+			want: "missing required header X-Api-Version",
+		},
+		{
+			err:  requireheaders.Error{Header: "X-Api-Version", Err: errUnsupported},
+			want: "invalid header X-Api-Version: unsupported version",
+		},
+	}
+	for _, tt := range tests {
+		if got := tt.err.Error(); got != tt.want {
+			t.Errorf("%#v.Error() got: %q want: %q", tt.err, got, tt.want)
+		}
+	}
+	if err := (requireheaders.Error{Header: "X-Api-Version", Err: errUnsupported}); !errors.Is(err, errUnsupported) {
+		t.Errorf("errors.Is(%v, errUnsupported) got: false want: true", err)
+	}
+}
+
+func TestNew(t *testing.T) {
+	it := requireheaders.New("Authorization", "X-Api-Version")
+	want := []requireheaders.Header{{Name: "Authorization"}, {Name: "X-Api-Version"}}
+	if diff := cmp.Diff(want, it.Headers, cmpopts.IgnoreFields(requireheaders.Header{}, "Validate")); diff != "" {
+		t.Errorf("it.Headers mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,123 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"sync"
+
+	"github.com/google/go-safeweb/safehttp"
+)
+
+// FlashesFuncName is the name of the template function returning the flash
+// messages of the session.
+const FlashesFuncName = "Flashes"
+
+// FlashFuncMap declares the FlashesFuncName template function. It must be
+// added to the templates that display flash messages before they are parsed,
+// e.g.
+//
+//	t := template.Must(template.New("page").Funcs(session.FlashFuncMap).Parse(
+//		`{{range Flashes}}<p class="{{.Kind}}">{{.Message}}</p>{{end}}`))
+//
+// When a handler writes a template response, the Interceptor provides the
+// flash messages of the session through the function. They're only consumed
+// if the template calls it.
+var FlashFuncMap = map[string]interface{}{
+	FlashesFuncName: func() []Flash { return nil },
+}
+
+const flashKey = "_flash"
+
+// Flash is a one-shot message for the user, e.g. the confirmation of a form
+// submission. It's displayed by the next page rendered for the session, which
+// makes it suitable for the Post/Redirect/Get pattern.
+type Flash struct {
+	// Kind is the kind of message, e.g. "info" or "error".
+	Kind    string `json:"kind"`
+	Message string `json:"message"`
+}
+
+// AddFlash adds a flash message to the session.
+func (s *Session) AddFlash(kind, message string) error {
+	var flashes []Flash
+	if _, err := s.Get(flashKey, &flashes); err != nil {
+		return err
+	}
+	return s.Set(flashKey, append(flashes, Flash{Kind: kind, Message: message}))
+}
+
+// Flashes returns the flash messages of the session and removes them.
+func (s *Session) Flashes() []Flash {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.takeFlashes()
+}
+
+// takeFlashes must be called with s.mu held.
+func (s *Session) takeFlashes() []Flash {
+	v, ok := s.rec.Values[flashKey]
+	if !ok {
+		return nil
+	}
+	delete(s.rec.Values, flashKey)
+	s.dirty = true
+	var flashes []Flash
+	if err := json.Unmarshal(v, &flashes); err != nil {
+		return nil
+	}
+	return flashes
+}
+
+// injectFlashes provides the flash messages to the template of the response
+// through the FlashesFuncName function. The messages are taken from the
+// session when the function is first called, i.e. when the response is
+// rendered after the Commit phase, hence the session is saved again.
+func (it Interceptor) injectFlashes(ctx context.Context, s *Session, resp safehttp.Response) {
+	tmplResp, ok := safehttp.TemplateOf(resp)
+	if !ok {
+		return
+	}
+	var (
+		once    sync.Once
+		flashes []Flash
+	)
+	if tmplResp.FuncMap == nil {
+		tmplResp.FuncMap = map[string]interface{}{}
+	}
+	tmplResp.FuncMap[FlashesFuncName] = func() []Flash {
+		once.Do(func() { flashes = it.consumeFlashes(ctx, s) })
+		return flashes
+	}
+}
+
+// consumeFlashes takes the flash messages of the session and saves it.
+func (it Interceptor) consumeFlashes(ctx context.Context, s *Session) []Flash {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.rec.Values[flashKey]; !ok {
+		return nil
+	}
+	flashes := s.takeFlashes()
+	if s.id == "" || s.destroyed {
+		return flashes
+	}
+	if err := it.save(ctx, s); err != nil {
+		log.Printf("session plugin failed to save a session: %v", err)
+	}
+	return flashes
+}
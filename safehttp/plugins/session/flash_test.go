@@ -0,0 +1,146 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package session_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-safeweb/safehttp"
+	"github.com/google/go-safeweb/safehttp/plugins/session"
+	"github.com/google/safehtml/template"
+)
+
+func TestFlashPostRedirectGet(t *testing.T) {
+	tmpl := template.Must(template.New("page").Funcs(session.FlashFuncMap).Parse(
+		`{{range Flashes}}[{{.Kind}}: {{.Message}}]{{end}}`))
+
+	mb := safehttp.NewServeMuxConfig(nil)
+	mb.Intercept(session.Interceptor{Store: &session.MemoryStore{}})
+	mux := mb.Mux()
+	mux.Handle("/submit", safehttp.MethodPost, safehttp.HandlerFunc(func(w safehttp.ResponseWriter, r *safehttp.IncomingRequest) safehttp.Result {
+		s := session.FromRequest(r)
+		if err := s.AddFlash("info", "Saved <b>draft</b>"); err != nil {
+			return w.WriteError(safehttp.StatusInternalServerError)
+		}
+		if err := s.AddFlash("warning", "Quota almost full"); err != nil {
+			return w.WriteError(safehttp.StatusInternalServerError)
+		}
+		return safehttp.Redirect(w, r, "/page", safehttp.StatusSeeOther)
+	}))
+	mux.Handle("/page", safehttp.MethodGet, safehttp.HandlerFunc(func(w safehttp.ResponseWriter, r *safehttp.IncomingRequest) safehttp.Result {
+		return safehttp.ExecuteTemplate(w, tmpl, nil)
+	}))
+
+	do := func(method, path string, cookies []*http.Cookie) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "https://foo.com"+path, nil)
+		for _, c := range cookies {
+			req.AddCookie(c)
+		}
+		rr := httptest.NewRecorder()
+		mux.ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := do(safehttp.MethodPost, "/submit", nil)
+	if got, want := rr.Code, int(safehttp.StatusSeeOther); got != want {
+		t.Fatalf("rr.Code: got %v want %v", got, want)
+	}
+	cookies := rr.Result().Cookies()
+
+	rr = do(safehttp.MethodGet, "/page", cookies)
+	if got, want := rr.Body.String(), "[info: Saved &lt;b&gt;draft&lt;/b&gt;][warning: Quota almost full]"; got != want {
+		t.Errorf("first page: got %q want %q", got, want)
+	}
+	rr = do(safehttp.MethodGet, "/page", cookies)
+	if got := rr.Body.String(); got != "" {
+		t.Errorf("second page: got %q, want no flash messages", got)
+	}
+}
+
+func TestFlashes(t *testing.T) {
+	var got []session.Flash
+	mb := safehttp.NewServeMuxConfig(nil)
+	mb.Intercept(session.Interceptor{Store: &session.MemoryStore{}})
+	mux := mb.Mux()
+	mux.Handle("/", safehttp.MethodGet, safehttp.HandlerFunc(func(w safehttp.ResponseWriter, r *safehttp.IncomingRequest) safehttp.Result {
+		s := session.FromRequest(r)
+		s.AddFlash("info", "hello")
+		got = s.Flashes()
+		if f := s.Flashes(); f != nil {
+			t.Errorf("s.Flashes() after consumption: got %v, want nil", f)
+		}
+		return w.Write(safehttp.NoContentResponse{})
+	}))
+	mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(safehttp.MethodGet, "https://foo.com/", nil))
+
+	if diff := cmp.Diff([]session.Flash{{Kind: "info", Message: "hello"}}, got); diff != "" {
+		t.Errorf("s.Flashes() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestFlashesConsumedOnlyWhenDisplayed(t *testing.T) {
+	tmpl := template.Must(template.New("pages").Funcs(session.FlashFuncMap).Parse(
+		`{{define "plain"}}no flashes{{end}}` +
+			`{{define "page"}}{{range Flashes}}[{{.Message}}]{{end}}{{end}}` +
+			`{{define "error"}}{{.Code}}{{range Flashes}}[{{.Message}}]{{end}}{{end}}`))
+
+	mb := safehttp.NewServeMuxConfig(nil)
+	mb.Intercept(session.Interceptor{Store: &session.MemoryStore{}})
+	mb.RenderErrors(safehttp.ErrorPages{Template: tmpl, Default: "error"})
+	mux := mb.Mux()
+	mux.Handle("/add", safehttp.MethodGet, safehttp.HandlerFunc(func(w safehttp.ResponseWriter, r *safehttp.IncomingRequest) safehttp.Result {
+		if err := session.FromRequest(r).AddFlash("info", "hello"); err != nil {
+			return w.WriteError(safehttp.StatusInternalServerError)
+		}
+		return w.Write(safehttp.NoContentResponse{})
+	}))
+	mux.Handle("/plain", safehttp.MethodGet, safehttp.HandlerFunc(func(w safehttp.ResponseWriter, r *safehttp.IncomingRequest) safehttp.Result {
+		return safehttp.ExecuteNamedTemplate(w, tmpl, "plain", nil)
+	}))
+	mux.Handle("/page", safehttp.MethodGet, safehttp.HandlerFunc(func(w safehttp.ResponseWriter, r *safehttp.IncomingRequest) safehttp.Result {
+		return safehttp.ExecuteNamedTemplate(w, tmpl, "page", nil)
+	}))
+	mux.Handle("/error", safehttp.MethodGet, safehttp.HandlerFunc(func(w safehttp.ResponseWriter, r *safehttp.IncomingRequest) safehttp.Result {
+		return w.WriteError(safehttp.StatusNotFound)
+	}))
+
+	var cookies []*http.Cookie
+	do := func(path string) string {
+		req := httptest.NewRequest(safehttp.MethodGet, "https://foo.com"+path, nil)
+		for _, c := range cookies {
+			req.AddCookie(c)
+		}
+		rr := httptest.NewRecorder()
+		mux.ServeHTTP(rr, req)
+		if c := rr.Result().Cookies(); len(c) > 0 {
+			cookies = c
+		}
+		return rr.Body.String()
+	}
+
+	do("/add")
+	if got, want := do("/plain"), "no flashes"; got != want {
+		t.Errorf("page without flashes: got %q want %q", got, want)
+	}
+	if got, want := do("/error"), "404[hello]"; got != want {
+		t.Errorf("error page: got %q want %q", got, want)
+	}
+	if got := do("/page"); got != "" {
+		t.Errorf("page after the error page: got %q, want no flash messages", got)
+	}
+}
@@ -0,0 +1,63 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package session
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func TestMemoryStoreDropsExpired(t *testing.T) {
+	ctx := context.Background()
+	clock := &fakeClock{now: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+	m := &MemoryStore{Clock: clock}
+	for _, id := range []string{"loaded", "abandoned"} {
+		if err := m.Save(ctx, id, &Record{}, time.Second); err != nil {
+			t.Fatalf("Save(%q): %v", id, err)
+		}
+	}
+
+	clock.now = clock.now.Add(time.Second)
+	if _, err := m.Load(ctx, "loaded"); err != ErrNotFound {
+		t.Errorf("Load of an expired session: got err %v want %v", err, ErrNotFound)
+	}
+	if _, ok := m.sessions["loaded"]; ok {
+		t.Error("expired session still kept after Load")
+	}
+
+	// Sessions which aren't loaded again are dropped by the periodic sweep.
+	if err := m.Save(ctx, "new", &Record{}, time.Hour); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	if _, ok := m.sessions["abandoned"]; !ok {
+		t.Error("expired session dropped before the sweep interval")
+	}
+	clock.now = clock.now.Add(memorySweepInterval)
+	if err := m.Save(ctx, "new", &Record{}, time.Hour); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	if _, ok := m.sessions["abandoned"]; ok {
+		t.Error("expired session still kept after the sweep")
+	}
+}
@@ -0,0 +1,330 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package session provides server-side sessions.
+//
+// The Interceptor loads the session of each request from a Store, using the
+// identifier kept in a cookie, and saves it before the response is written.
+// Handlers access it with FromRequest:
+//
+//	s := session.FromRequest(r)
+//	var user string
+//	if ok, err := s.Get("user", &user); err != nil || !ok {
+//		return safehttp.Redirect(w, r, "/login", safehttp.StatusFound)
+//	}
+//
+// Values are encoded as JSON, so that they can be kept by any Store.
+//
+// Sessions also carry flash messages, which are displayed once by the next
+// rendered page. See Session.AddFlash and FlashFuncMap.
+//
+// # Security
+//
+// Sessions expire after IdleTimeout without requests and, regardless of
+// activity, AbsoluteTimeout after they were created. Handlers must call
+// Session.RenewID when the privileges of the user change (e.g. on login), so
+// that an identifier planted by an attacker before the change (session
+// fixation) can't be used afterwards.
+//
+// Stores only see a hash of the identifiers, hence a leak of the store
+// doesn't allow hijacking the sessions.
+package session
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/google/go-safeweb/safehttp"
+)
+
+const (
+	// DefaultCookieName is the default name of the session cookie.
+	DefaultCookieName = "__Host-session"
+	// DefaultIdleTimeout is the default time after which sessions without
+	// requests expire.
+	DefaultIdleTimeout = 30 * time.Minute
+	// DefaultAbsoluteTimeout is the default time after which sessions expire,
+	// regardless of their activity.
+	DefaultAbsoluteTimeout = 12 * time.Hour
+)
+
+var sessionKey = safehttp.NewKey("session")
+
+// Interceptor loads and saves the sessions of the requests.
+type Interceptor struct {
+	// Store keeps the sessions.
+	Store Store
+	// CookieName is the name of the session cookie. If empty,
+	// DefaultCookieName is used.
+	CookieName string
+	// CookiePolicy is used to read the session cookie. See
+	// safehttp.CookiePolicy.
+	CookiePolicy safehttp.CookiePolicy
+	// IdleTimeout is the time after which sessions without requests expire.
+	// Every request extends the session (rolling expiry). If zero,
+	// DefaultIdleTimeout is used.
+	IdleTimeout time.Duration
+	// AbsoluteTimeout is the time after which sessions expire, regardless of
+	// their activity. If zero, DefaultAbsoluteTimeout is used.
+	AbsoluteTimeout time.Duration
+	// Clock is used to check whether sessions are expired. If nil,
+	// safehttp.SystemClock is used.
+	Clock safehttp.Clock
+	// Rand is the source of the session identifiers. If nil,
+	// safehttp.SystemRand is used.
+	Rand io.Reader
+}
+
+var _ safehttp.Interceptor = Interceptor{}
+
+// Before loads the session of the request. Missing or expired sessions are
+// replaced by new, empty ones. If the Store fails, the request is rejected
+// with 500 Internal Server Error.
+func (it Interceptor) Before(w safehttp.ResponseWriter, r *safehttp.IncomingRequest, _ safehttp.InterceptorConfig) safehttp.Result {
+	now := it.now()
+	s := &Session{}
+	if c, err := r.CookieWithPolicy(it.cookieName(), it.CookiePolicy); err == nil && c.Value() != "" {
+		rec, err := it.Store.Load(r.Context(), storeID(c.Value()))
+		switch {
+		case errors.Is(err, ErrNotFound):
+		case err != nil:
+			log.Printf("session plugin failed to load a session: %v", err)
+			return w.WriteError(safehttp.StatusInternalServerError)
+		case now.Sub(rec.LastSeen) >= it.idleTimeout() || now.Sub(rec.Created) >= it.absoluteTimeout():
+			// The Store should have already dropped it.
+			s.oldID = c.Value()
+		default:
+			s.id = c.Value()
+			s.rec = *rec
+		}
+	}
+	if s.rec.Values == nil {
+		s.rec = Record{Values: map[string]json.RawMessage{}, Created: now}
+	}
+	r.SetValue(sessionKey, s)
+	return safehttp.NotWritten()
+}
+
+// Commit saves the session and sets the session cookie, if needed. New
+// sessions are only saved if values were set. If the response is a template
+// response, the flash messages of the session are provided to the template,
+// see FlashFuncMap.
+func (it Interceptor) Commit(w safehttp.ResponseHeadersWriter, r *safehttp.IncomingRequest, resp safehttp.Response, _ safehttp.InterceptorConfig) {
+	s := FromRequest(r)
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ctx := r.Context()
+	if s.oldID != "" {
+		if err := it.Store.Delete(ctx, storeID(s.oldID)); err != nil {
+			log.Printf("session plugin failed to delete a session: %v", err)
+		}
+	}
+	if s.destroyed {
+		if s.id != "" {
+			if err := it.Store.Delete(ctx, storeID(s.id)); err != nil {
+				log.Printf("session plugin failed to delete a session: %v", err)
+			}
+		}
+		if s.id != "" || s.oldID != "" {
+			it.setCookie(w, "", -1)
+		}
+		return
+	}
+	it.injectFlashes(ctx, s, resp)
+	if s.id == "" && !s.dirty {
+		if s.oldID != "" {
+			it.setCookie(w, "", -1)
+		}
+		return
+	}
+
+	newID := s.id == ""
+	if newID {
+		s.id = newSessionID(it.random())
+	}
+	if err := it.save(ctx, s); err != nil {
+		log.Printf("session plugin failed to save a session: %v", err)
+		return
+	}
+	if newID {
+		it.setCookie(w, s.id, 0)
+	}
+}
+
+// save saves the session in the Store, extending its idle timeout. It must be
+// called with s.mu held.
+func (it Interceptor) save(ctx context.Context, s *Session) error {
+	now := it.now()
+	s.rec.LastSeen = now
+	ttl := it.idleTimeout()
+	if left := s.rec.Created.Add(it.absoluteTimeout()).Sub(now); left < ttl {
+		ttl = left
+	}
+	return it.Store.Save(ctx, storeID(s.id), &s.rec, ttl)
+}
+
+// Match returns false since there are no supported configurations.
+func (Interceptor) Match(safehttp.InterceptorConfig) bool {
+	return false
+}
+
+func (it Interceptor) setCookie(w safehttp.ResponseHeadersWriter, value string, maxAge int) {
+	c := safehttp.NewCookie(it.cookieName(), value)
+	c.Path("/")
+	c.SetMaxAge(maxAge)
+	if err := w.AddCookie(c); err != nil {
+		log.Printf("session plugin failed to set the session cookie: %v", err)
+	}
+}
+
+func (it Interceptor) cookieName() string {
+	if it.CookieName == "" {
+		return DefaultCookieName
+	}
+	return it.CookieName
+}
+
+func (it Interceptor) idleTimeout() time.Duration {
+	if it.IdleTimeout == 0 {
+		return DefaultIdleTimeout
+	}
+	return it.IdleTimeout
+}
+
+func (it Interceptor) absoluteTimeout() time.Duration {
+	if it.AbsoluteTimeout == 0 {
+		return DefaultAbsoluteTimeout
+	}
+	return it.AbsoluteTimeout
+}
+
+func (it Interceptor) now() time.Time {
+	if it.Clock == nil {
+		return safehttp.SystemClock().Now()
+	}
+	return it.Clock.Now()
+}
+
+func (it Interceptor) random() io.Reader {
+	if it.Rand == nil {
+		return safehttp.SystemRand()
+	}
+	return it.Rand
+}
+
+// Session is the session of a request. It's safe for concurrent use.
+type Session struct {
+	mu  sync.Mutex
+	rec Record
+	// id is the identifier of the session, or empty for new sessions.
+	id string
+	// oldID is the identifier of a session that must be deleted: an expired
+	// one or the one replaced by RenewID.
+	oldID     string
+	dirty     bool
+	destroyed bool
+}
+
+// FromRequest returns the session of the request, or nil if the Interceptor
+// is not installed.
+func FromRequest(r *safehttp.IncomingRequest) *Session {
+	s, _ := r.Value(sessionKey).(*Session)
+	return s
+}
+
+// Get decodes the value stored under key into dst and reports whether it was
+// found.
+func (s *Session) Get(key string, dst interface{}) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	v, ok := s.rec.Values[key]
+	if !ok {
+		return false, nil
+	}
+	return true, json.Unmarshal(v, dst)
+}
+
+// Set stores the JSON encoding of v under key.
+func (s *Session) Set(key string, v interface{}) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rec.Values[key] = b
+	s.dirty = true
+	return nil
+}
+
+// Delete removes the value stored under key.
+func (s *Session) Delete(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.rec.Values, key)
+	s.dirty = true
+}
+
+// IsNew reports whether the session was created by the current request.
+func (s *Session) IsNew() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.id == ""
+}
+
+// RenewID replaces the identifier of the session, keeping its values. It must
+// be called when the privileges of the user change, e.g. on login, to protect
+// against session fixation.
+func (s *Session) RenewID() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.id != "" {
+		s.oldID = s.id
+		s.id = ""
+	}
+	s.dirty = true
+}
+
+// Destroy deletes the session and its values, e.g. on logout.
+func (s *Session) Destroy() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.destroyed = true
+	s.rec.Values = map[string]json.RawMessage{}
+}
+
+func newSessionID(rand io.Reader) string {
+	b := make([]byte, 32)
+	if _, err := io.ReadFull(rand, b); err != nil {
+		panic(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// storeID returns the identifier under which the session is kept in the Store.
+func storeID(id string) string {
+	sum := sha256.Sum256([]byte(id))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
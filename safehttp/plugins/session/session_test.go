@@ -0,0 +1,225 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package session_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-safeweb/safehttp"
+	"github.com/google/go-safeweb/safehttp/plugins/session"
+	"github.com/google/safehtml"
+)
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+type sessionServer struct {
+	t     *testing.T
+	clock *fakeClock
+	store session.Store
+	mux   *safehttp.ServeMux
+}
+
+func newSessionServer(t *testing.T, store session.Store) *sessionServer {
+	clock := &fakeClock{now: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+	if store == nil {
+		store = &session.MemoryStore{Clock: clock}
+	}
+	mb := safehttp.NewServeMuxConfig(nil)
+	mb.Intercept(session.Interceptor{Store: store, Clock: clock, IdleTimeout: time.Hour, AbsoluteTimeout: 3 * time.Hour})
+	mux := mb.Mux()
+
+	get := func(w safehttp.ResponseWriter, r *safehttp.IncomingRequest) safehttp.Result {
+		var user string
+		if _, err := session.FromRequest(r).Get("user", &user); err != nil {
+			return w.WriteError(safehttp.StatusInternalServerError)
+		}
+		return w.Write(safehtml.HTMLEscaped(user))
+	}
+	mux.Handle("/get", safehttp.MethodGet, safehttp.HandlerFunc(get))
+	mux.Handle("/login", safehttp.MethodGet, safehttp.HandlerFunc(func(w safehttp.ResponseWriter, r *safehttp.IncomingRequest) safehttp.Result {
+		q, err := r.URL().Query()
+		if err != nil {
+			return w.WriteError(safehttp.StatusBadRequest)
+		}
+		s := session.FromRequest(r)
+		s.RenewID()
+		if err := s.Set("user", q.String("u", "")); err != nil {
+			return w.WriteError(safehttp.StatusInternalServerError)
+		}
+		return w.Write(safehtml.HTMLEscaped("ok"))
+	}))
+	mux.Handle("/logout", safehttp.MethodGet, safehttp.HandlerFunc(func(w safehttp.ResponseWriter, r *safehttp.IncomingRequest) safehttp.Result {
+		session.FromRequest(r).Destroy()
+		return w.Write(safehtml.HTMLEscaped("bye"))
+	}))
+	return &sessionServer{t: t, clock: clock, store: store, mux: mux}
+}
+
+// do sends a request with the given session cookie and returns the response
+// body and the new value of the cookie, if it was set.
+func (s *sessionServer) do(path, cookie string) (body string, setCookie *http.Cookie) {
+	s.t.Helper()
+	req := httptest.NewRequest(safehttp.MethodGet, "https://foo.com"+path, nil)
+	if cookie != "" {
+		req.AddCookie(&http.Cookie{Name: session.DefaultCookieName, Value: cookie})
+	}
+	rr := httptest.NewRecorder()
+	s.mux.ServeHTTP(rr, req)
+	for _, c := range rr.Result().Cookies() {
+		if c.Name == session.DefaultCookieName {
+			setCookie = c
+		}
+	}
+	return rr.Body.String(), setCookie
+}
+
+func TestSessionLifecycle(t *testing.T) {
+	s := newSessionServer(t, nil)
+
+	if _, c := s.do("/get", ""); c != nil {
+		t.Errorf("unmodified new session: got cookie %v, want none", c)
+	}
+
+	_, c := s.do("/login?u=alice", "")
+	if c == nil || c.Value == "" {
+		t.Fatal("login: got no session cookie")
+	}
+	for _, want := range []string{"Path=/", "HttpOnly", "Secure", "SameSite=Lax"} {
+		if !strings.Contains(c.String(), want) {
+			t.Errorf("session cookie: got %q, want it to contain %q", c.String(), want)
+		}
+	}
+	id := c.Value
+
+	if body, c := s.do("/get", id); body != "alice" || c != nil {
+		t.Errorf("existing session: got body %q, cookie %v, want %q and no cookie", body, c, "alice")
+	}
+
+	// Logging in again renews the identifier.
+	_, c = s.do("/login?u=bob", id)
+	if c == nil || c.Value == "" || c.Value == id {
+		t.Fatalf("renewed session: got cookie %v, want a new identifier", c)
+	}
+	if body, _ := s.do("/get", id); body != "" {
+		t.Errorf("old identifier after renewal: got body %q, want empty", body)
+	}
+	id = c.Value
+	if body, _ := s.do("/get", id); body != "bob" {
+		t.Errorf("renewed session: got body %q want %q", body, "bob")
+	}
+
+	_, c = s.do("/logout", id)
+	if c == nil || c.MaxAge >= 0 {
+		t.Errorf("logout: got cookie %v, want a deletion", c)
+	}
+	if body, _ := s.do("/get", id); body != "" {
+		t.Errorf("destroyed session: got body %q, want empty", body)
+	}
+}
+
+func TestSessionTimeouts(t *testing.T) {
+	s := newSessionServer(t, nil)
+	_, c := s.do("/login?u=alice", "")
+	id := c.Value
+
+	// Requests extend the session until the absolute timeout.
+	for i := 0; i < 5; i++ {
+		s.clock.now = s.clock.now.Add(50 * time.Minute)
+		want := "alice"
+		if i == 3 {
+			// 200 minutes after the creation.
+			want = ""
+		}
+		if body, _ := s.do("/get", id); body != want {
+			t.Errorf("after %d minutes: got body %q want %q", 50*(i+1), body, want)
+		}
+		if want == "" {
+			break
+		}
+	}
+
+	s = newSessionServer(t, nil)
+	_, c = s.do("/login?u=alice", "")
+	s.clock.now = s.clock.now.Add(61 * time.Minute)
+	if body, _ := s.do("/get", c.Value); body != "" {
+		t.Errorf("idle session: got body %q, want empty", body)
+	}
+}
+
+type failingStore struct {
+	*session.MemoryStore
+}
+
+func (failingStore) Load(context.Context, string) (*session.Record, error) {
+	return nil, errors.New("connection refused")
+}
+
+func TestSessionStoreError(t *testing.T) {
+	s := newSessionServer(t, failingStore{&session.MemoryStore{}})
+	req := httptest.NewRequest(safehttp.MethodGet, "https://foo.com/get", nil)
+	req.AddCookie(&http.Cookie{Name: session.DefaultCookieName, Value: "x"})
+	rr := httptest.NewRecorder()
+	s.mux.ServeHTTP(rr, req)
+	if got, want := rr.Code, int(safehttp.StatusInternalServerError); got != want {
+		t.Errorf("rr.Code: got %v want %v", got, want)
+	}
+}
+
+func TestSessionCookiePolicy(t *testing.T) {
+	s := newSessionServer(t, nil)
+	_, c := s.do("/login?u=alice", "")
+
+	tests := []struct {
+		name   string
+		policy safehttp.CookiePolicy
+		want   string
+	}{
+		{name: "Default", want: "alice"},
+		{name: "Secure prefixes required", policy: safehttp.CookiePolicy{RequireSecurePrefixes: true}, want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mb := safehttp.NewServeMuxConfig(nil)
+			mb.Intercept(session.Interceptor{Store: s.store, Clock: s.clock, CookiePolicy: tt.policy})
+			mux := mb.Mux()
+			mux.Handle("/get", safehttp.MethodGet, safehttp.HandlerFunc(func(w safehttp.ResponseWriter, r *safehttp.IncomingRequest) safehttp.Result {
+				var user string
+				session.FromRequest(r).Get("user", &user)
+				return w.Write(safehtml.HTMLEscaped(user))
+			}))
+
+			// The request is forwarded over HTTP by a proxy terminating HTTPS.
+			req := httptest.NewRequest(safehttp.MethodGet, "http://foo.com/get", nil)
+			req.AddCookie(&http.Cookie{Name: session.DefaultCookieName, Value: c.Value})
+			rr := httptest.NewRecorder()
+			mux.ServeHTTP(rr, req)
+			if got := rr.Body.String(); got != tt.want {
+				t.Errorf("body: got %q want %q", got, tt.want)
+			}
+		})
+	}
+}
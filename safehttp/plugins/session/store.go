@@ -0,0 +1,280 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package session
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/google/go-safeweb/safehttp"
+	"github.com/google/go-safeweb/safesql"
+)
+
+// ErrNotFound is returned by Stores when a session doesn't exist or is
+// expired.
+var ErrNotFound = errors.New("session: not found")
+
+// Record is the content of a session, as kept by a Store.
+type Record struct {
+	Values   map[string]json.RawMessage `json:"values"`
+	Created  time.Time                  `json:"created"`
+	LastSeen time.Time                  `json:"last_seen"`
+}
+
+// Store keeps sessions. The identifiers passed to a Store are hashes of the
+// ones sent to the clients.
+type Store interface {
+	// Load returns the session with the given identifier, or ErrNotFound.
+	Load(ctx context.Context, id string) (*Record, error)
+	// Save creates or replaces the session with the given identifier. The
+	// Store should drop it after ttl.
+	Save(ctx context.Context, id string, rec *Record, ttl time.Duration) error
+	// Delete deletes the session with the given identifier, if it exists.
+	Delete(ctx context.Context, id string) error
+}
+
+// MemoryStore keeps the sessions in memory. It's meant for tests and for
+// servers running a single instance: sessions are lost on restart.
+type MemoryStore struct {
+	// Clock is used to drop expired sessions. If nil, safehttp.SystemClock is
+	// used.
+	Clock safehttp.Clock
+
+	mu       sync.Mutex
+	sessions map[string]memoryRecord
+	// swept is the last time the expired sessions were dropped.
+	swept time.Time
+}
+
+// memorySweepInterval is the minimum time between two scans of MemoryStore
+// for expired sessions.
+const memorySweepInterval = time.Minute
+
+type memoryRecord struct {
+	data    []byte
+	expires time.Time
+}
+
+var _ Store = (*MemoryStore)(nil)
+
+// Load implements Store. An expired session is dropped.
+func (m *MemoryStore) Load(_ context.Context, id string) (*Record, error) {
+	now := m.now()
+	m.mu.Lock()
+	mr, ok := m.sessions[id]
+	if ok && !now.Before(mr.expires) {
+		delete(m.sessions, id)
+		ok = false
+	}
+	m.mu.Unlock()
+	if !ok {
+		return nil, ErrNotFound
+	}
+	rec := &Record{}
+	if err := json.Unmarshal(mr.data, rec); err != nil {
+		return nil, err
+	}
+	return rec, nil
+}
+
+// Save implements Store. At most once a minute, the sessions which expired
+// without being loaded again are dropped as well.
+func (m *MemoryStore) Save(_ context.Context, id string, rec *Record, ttl time.Duration) error {
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	now := m.now()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.sessions == nil {
+		m.sessions = make(map[string]memoryRecord)
+	}
+	if now.Sub(m.swept) >= memorySweepInterval {
+		for k, mr := range m.sessions {
+			if !now.Before(mr.expires) {
+				delete(m.sessions, k)
+			}
+		}
+		m.swept = now
+	}
+	m.sessions[id] = memoryRecord{data: data, expires: now.Add(ttl)}
+	return nil
+}
+
+// Delete implements Store.
+func (m *MemoryStore) Delete(_ context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.sessions, id)
+	return nil
+}
+
+func (m *MemoryStore) now() time.Time {
+	if m.Clock == nil {
+		return safehttp.SystemClock().Now()
+	}
+	return m.Clock.Now()
+}
+
+// RedisClient is the subset of a Redis client used by RedisStore. It can be
+// implemented with a thin adapter over any Redis library.
+type RedisClient interface {
+	// Get returns the value of the key (GET), or nil if it doesn't exist.
+	Get(ctx context.Context, key string) ([]byte, error)
+	// Set sets the value of the key, expiring after ttl (SET key value PX ttl).
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	// Del deletes the key (DEL).
+	Del(ctx context.Context, key string) error
+}
+
+// RedisStore keeps the sessions in Redis, which drops them when they expire.
+type RedisStore struct {
+	Client RedisClient
+	// Prefix is prepended to the identifiers of the sessions to build the
+	// Redis keys, e.g. "session:".
+	Prefix string
+}
+
+var _ Store = RedisStore{}
+
+// Load implements Store.
+func (s RedisStore) Load(ctx context.Context, id string) (*Record, error) {
+	data, err := s.Client.Get(ctx, s.Prefix+id)
+	if err != nil {
+		return nil, err
+	}
+	if data == nil {
+		return nil, ErrNotFound
+	}
+	rec := &Record{}
+	if err := json.Unmarshal(data, rec); err != nil {
+		return nil, err
+	}
+	return rec, nil
+}
+
+// Save implements Store.
+func (s RedisStore) Save(ctx context.Context, id string, rec *Record, ttl time.Duration) error {
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	return s.Client.Set(ctx, s.Prefix+id, data, ttl)
+}
+
+// Delete implements Store.
+func (s RedisStore) Delete(ctx context.Context, id string) error {
+	return s.Client.Del(ctx, s.Prefix+id)
+}
+
+// SQLStore keeps the sessions in a SQL table with the following columns:
+//
+//	id VARCHAR(64) PRIMARY KEY, data TEXT NOT NULL, expires BIGINT NOT NULL
+//
+// where expires is a Unix timestamp in seconds. Expired rows are ignored, but
+// not deleted: they should be periodically deleted with
+//
+//	DELETE FROM sessions WHERE expires <= ?
+type SQLStore struct {
+	DB safesql.DB
+	// Table is the name of the table.
+	Table safesql.TrustedSQLString
+	// NumberedParams selects the "$1" placeholders of PostgreSQL instead of
+	// "?".
+	NumberedParams bool
+	// Clock is used to ignore expired sessions. If nil, safehttp.SystemClock
+	// is used.
+	Clock safehttp.Clock
+}
+
+var _ Store = SQLStore{}
+
+// Load implements Store.
+func (s SQLStore) Load(ctx context.Context, id string) (*Record, error) {
+	q := safesql.TrustedSQLStringConcat(
+		safesql.New("SELECT data FROM "), s.Table,
+		safesql.New(" WHERE id = "), s.param(1),
+		safesql.New(" AND expires > "), s.param(2),
+	)
+	var data string
+	err := s.DB.QueryRowContext(ctx, q, id, s.now().Unix()).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	rec := &Record{}
+	if err := json.Unmarshal([]byte(data), rec); err != nil {
+		return nil, err
+	}
+	return rec, nil
+}
+
+// Save implements Store. It replaces the row in a transaction.
+func (s SQLStore) Save(ctx context.Context, id string, rec *Record, ttl time.Duration) error {
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, s.deleteQuery(), id); err != nil {
+		tx.Rollback()
+		return err
+	}
+	q := safesql.TrustedSQLStringConcat(
+		safesql.New("INSERT INTO "), s.Table,
+		safesql.New(" (id, data, expires) VALUES ("), s.param(1),
+		safesql.New(", "), s.param(2),
+		safesql.New(", "), s.param(3), safesql.New(")"),
+	)
+	if _, err := tx.ExecContext(ctx, q, id, string(data), s.now().Add(ttl).Unix()); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// Delete implements Store.
+func (s SQLStore) Delete(ctx context.Context, id string) error {
+	_, err := s.DB.ExecContext(ctx, s.deleteQuery(), id)
+	return err
+}
+
+func (s SQLStore) deleteQuery() safesql.TrustedSQLString {
+	return safesql.TrustedSQLStringConcat(safesql.New("DELETE FROM "), s.Table, safesql.New(" WHERE id = "), s.param(1))
+}
+
+func (s SQLStore) param(n uint64) safesql.TrustedSQLString {
+	if s.NumberedParams {
+		return safesql.TrustedSQLStringConcat(safesql.New("$"), safesql.NewFromUint64(n))
+	}
+	return safesql.New("?")
+}
+
+func (s SQLStore) now() time.Time {
+	if s.Clock == nil {
+		return safehttp.SystemClock().Now()
+	}
+	return s.Clock.Now()
+}
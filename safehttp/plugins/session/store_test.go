@@ -0,0 +1,99 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package session_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-safeweb/safehttp/plugins/session"
+)
+
+type fakeRedis struct {
+	data map[string][]byte
+	ttls map[string]time.Duration
+}
+
+func (r *fakeRedis) Get(_ context.Context, key string) ([]byte, error) {
+	return r.data[key], nil
+}
+
+func (r *fakeRedis) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	r.data[key], r.ttls[key] = value, ttl
+	return nil
+}
+
+func (r *fakeRedis) Del(_ context.Context, key string) error {
+	delete(r.data, key)
+	return nil
+}
+
+func testStore(t *testing.T, s session.Store) {
+	t.Helper()
+	ctx := context.Background()
+	rec := &session.Record{
+		Values:   map[string]json.RawMessage{"user": json.RawMessage(`"alice"`)},
+		Created:  time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+		LastSeen: time.Date(2026, 1, 1, 0, 5, 0, 0, time.UTC),
+	}
+	if _, err := s.Load(ctx, "id"); err != session.ErrNotFound {
+		t.Errorf("Load of a missing session: got err %v want %v", err, session.ErrNotFound)
+	}
+	if err := s.Save(ctx, "id", rec, time.Hour); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	got, err := s.Load(ctx, "id")
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if diff := cmp.Diff(rec, got); diff != "" {
+		t.Errorf("Load mismatch (-want +got):\n%s", diff)
+	}
+	if err := s.Delete(ctx, "id"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := s.Load(ctx, "id"); err != session.ErrNotFound {
+		t.Errorf("Load of a deleted session: got err %v want %v", err, session.ErrNotFound)
+	}
+}
+
+func TestMemoryStore(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+	s := &session.MemoryStore{Clock: clock}
+	testStore(t, s)
+
+	if err := s.Save(context.Background(), "id", &session.Record{}, time.Minute); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	clock.now = clock.now.Add(time.Minute)
+	if _, err := s.Load(context.Background(), "id"); err != session.ErrNotFound {
+		t.Errorf("Load of an expired session: got err %v want %v", err, session.ErrNotFound)
+	}
+}
+
+func TestRedisStore(t *testing.T) {
+	r := &fakeRedis{data: map[string][]byte{}, ttls: map[string]time.Duration{}}
+	testStore(t, session.RedisStore{Client: r, Prefix: "session:"})
+
+	if err := (session.RedisStore{Client: r, Prefix: "session:"}).Save(context.Background(), "id", &session.Record{}, time.Minute); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	if got, want := r.ttls["session:id"], time.Minute; got != want {
+		t.Errorf("TTL: got %v want %v", got, want)
+	}
+}
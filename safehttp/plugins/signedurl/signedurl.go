@@ -0,0 +1,73 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package signedurl provides a safehttp.Interceptor which only allows requests
+// for URLs signed with safehttp.SignURL.
+//
+// This can be used to share time-limited links, e.g. to download a file. Since
+// all the requests served by the mux are checked, the interceptor should be
+// installed on a mux dedicated to signed URLs.
+package signedurl
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/google/go-safeweb/safehttp"
+)
+
+// Interceptor rejects requests for URLs which were not signed with its key, or
+// which are expired, with 403 Forbidden. It must be created with New; the zero
+// value rejects all requests.
+type Interceptor struct {
+	key []byte
+	// Clock is used to check whether URLs are expired. If nil,
+	// safehttp.SystemClock is used.
+	Clock safehttp.Clock
+}
+
+var _ safehttp.Interceptor = Interceptor{}
+
+// New creates an Interceptor which allows requests for URLs signed with the
+// given key. It panics if the key is shorter than safehttp.MinURLKeyLength.
+func New(key []byte) Interceptor {
+	if len(key) < safehttp.MinURLKeyLength {
+		panic(fmt.Sprintf("signedurl: key is %d bytes long, it must be at least %d", len(key), safehttp.MinURLKeyLength))
+	}
+	return Interceptor{key: key}
+}
+
+// Before verifies the signature and the expiry of the request URL.
+func (it Interceptor) Before(w safehttp.ResponseWriter, r *safehttp.IncomingRequest, _ safehttp.InterceptorConfig) safehttp.Result {
+	clock := it.Clock
+	if clock == nil {
+		clock = safehttp.SystemClock()
+	}
+	if err := safehttp.VerifyURL(r.URL(), it.key, clock.Now()); err != nil {
+		if safehttp.IsLocalDev() {
+			log.Printf("signedurl plugin rejected a request for %q: %v", r.URL().String(), err)
+		}
+		return w.WriteError(safehttp.StatusForbidden)
+	}
+	return safehttp.NotWritten()
+}
+
+// Commit is a no-op, required to satisfy the safehttp.Interceptor interface.
+func (Interceptor) Commit(w safehttp.ResponseHeadersWriter, r *safehttp.IncomingRequest, resp safehttp.Response, _ safehttp.InterceptorConfig) {
+}
+
+// Match returns false since there are no supported configurations.
+func (Interceptor) Match(safehttp.InterceptorConfig) bool {
+	return false
+}
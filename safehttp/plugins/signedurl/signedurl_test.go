@@ -0,0 +1,132 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signedurl_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-safeweb/safehttp"
+	"github.com/google/go-safeweb/safehttp/plugins/signedurl"
+	"github.com/google/go-safeweb/safehttp/safehttptest"
+)
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c fakeClock) Now() time.Time {
+	return c.now
+}
+
+var key = []byte("a super secret key of 32 bytes!!")
+
+func TestInterceptor(t *testing.T) {
+	u, err := safehttp.ParseURL("/download/report.pdf")
+	if err != nil {
+		t.Fatalf("safehttp.ParseURL() got err: %v", err)
+	}
+	signed := safehttp.SignURL(u, key, time.Hour).String()
+
+	tests := []struct {
+		name       string
+		url        string
+		now        time.Time
+		wantStatus safehttp.StatusCode
+	}{
+		{
+			name:       "Valid",
+			url:        signed,
+			now:        time.Now(),
+			wantStatus: safehttp.StatusOK,
+		},
+		{
+			name:       "Expired",
+			url:        signed,
+			now:        time.Now().Add(time.Hour + time.Minute),
+			wantStatus: safehttp.StatusForbidden,
+		},
+		{
+			name:       "Tampered signature",
+			url:        strings.Replace(signed, "signature=", "signature=A", 1),
+			now:        time.Now(),
+			wantStatus: safehttp.StatusForbidden,
+		},
+		{
+			name:       "Not signed",
+			url:        "/download/report.pdf",
+			now:        time.Now(),
+			wantStatus: safehttp.StatusForbidden,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := safehttptest.NewRequest(safehttp.MethodGet, "https://foo.com"+tt.url, nil)
+			fakeRW, rr := safehttptest.NewFakeResponseWriter()
+
+			it := signedurl.New(key)
+			it.Clock = fakeClock{now: tt.now}
+			it.Before(fakeRW, req, nil)
+
+			if got, want := rr.Code, int(tt.wantStatus); got != want {
+				t.Errorf("rr.Code got: %v want: %v", got, want)
+			}
+		})
+	}
+}
+
+func TestInterceptorSystemClock(t *testing.T) {
+	u, err := safehttp.ParseURL("/download/report.pdf")
+	if err != nil {
+		t.Fatalf("safehttp.ParseURL() got err: %v", err)
+	}
+	req := safehttptest.NewRequest(safehttp.MethodGet, "https://foo.com"+safehttp.SignURL(u, key, time.Hour).String(), nil)
+	fakeRW, rr := safehttptest.NewFakeResponseWriter()
+
+	signedurl.New(key).Before(fakeRW, req, nil)
+
+	if got, want := rr.Code, int(safehttp.StatusOK); got != want {
+		t.Errorf("rr.Code got: %v want: %v", got, want)
+	}
+}
+
+func TestZeroInterceptorRejects(t *testing.T) {
+	u, err := safehttp.ParseURL("/download/report.pdf")
+	if err != nil {
+		t.Fatalf("safehttp.ParseURL() got err: %v", err)
+	}
+	req := safehttptest.NewRequest(safehttp.MethodGet, "https://foo.com"+safehttp.SignURL(u, key, time.Hour).String(), nil)
+	fakeRW, rr := safehttptest.NewFakeResponseWriter()
+
+	signedurl.Interceptor{}.Before(fakeRW, req, nil)
+
+	if got, want := rr.Code, int(safehttp.StatusForbidden); got != want {
+		t.Errorf("rr.Code got: %v want: %v", got, want)
+	}
+}
+
+func TestNewShortKeyPanics(t *testing.T) {
+	for _, k := range [][]byte{nil, []byte(""), key[:safehttp.MinURLKeyLength-1]} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("signedurl.New with a %d bytes key: expected panic", len(k))
+				}
+			}()
+			signedurl.New(k)
+		}()
+	}
+}
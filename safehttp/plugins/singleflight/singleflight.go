@@ -0,0 +1,219 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package singleflight provides a safehttp.Interceptor which coalesces
+// concurrent identical GET and HEAD requests, so that the handler only runs
+// once and all the requests receive the same response, if it can be shared.
+//
+// This is useful to protect expensive, cacheable endpoints from thundering
+// herds.
+package singleflight
+
+import (
+	"context"
+	"net/textproto"
+	"strings"
+	"sync"
+
+	"github.com/google/go-safeweb/safehttp"
+	"github.com/google/safehtml"
+)
+
+// credentialHeaders are always part of the key, so that responses are never
+// shared between different users.
+var credentialHeaders = []string{"Authorization", "Cookie"}
+
+var coalescedMethods = map[string]bool{
+	safehttp.MethodGet:  true,
+	safehttp.MethodHead: true,
+}
+
+var callKey = safehttp.NewKey("singleflight.call")
+
+// call is an in-flight or completed handler execution.
+type call struct {
+	key  string
+	done chan struct{}
+	// ctx is the context of the request running the handler.
+	ctx context.Context
+	// resp is the response written by the handler, or nil if it can't be
+	// shared. It's only valid after done is closed.
+	resp safehttp.Response
+	// dups is the number of requests waiting for the call.
+	dups int
+}
+
+type group struct {
+	mu    sync.Mutex
+	calls map[string]*call
+}
+
+// forget removes the call from the in-flight ones, so that later requests run
+// the handler again.
+func (g *group) forget(c *call) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.calls[c.key] == c {
+		delete(g.calls, c.key)
+	}
+}
+
+// Interceptor coalesces concurrent identical GET and HEAD requests.
+//
+// Requests are identical if they have the same method, URL and values of the
+// Vary headers. The Authorization and Cookie headers are always taken into
+// account.
+//
+// The first request runs normally. Identical requests received while it's in
+// flight wait for it to complete and then write the same Response, which is
+// dispatched separately for each of them. Headers set by the handler of the
+// first request, rather than derived from the Response, are not shared.
+//
+// Only immutable responses which don't depend on the state of other
+// interceptors are shared: safehtml.HTML, JSON, redirect, No Content, Not
+// Modified and status code error responses. The waiting requests write them
+// from the Before phase, hence the interceptors installed after this one only
+// run their Commit phase. Template responses, which need e.g. the CSP nonce
+// or the XSRF token of each request, and responses with a body that can only
+// be read once, like safehttp.FileResponse, are not shared: if the first
+// request writes one of them, or completes without writing a response (e.g.
+// because the handler panicked), the waiting requests run the handler
+// themselves.
+type Interceptor struct {
+	vary []string
+	g    *group
+}
+
+var _ safehttp.Interceptor = Interceptor{}
+
+// New creates an Interceptor which coalesces requests that have the same
+// values for the given headers, in addition to the Authorization and Cookie
+// ones.
+func New(vary ...string) Interceptor {
+	it := Interceptor{g: &group{calls: map[string]*call{}}}
+	it.vary = append(it.vary, credentialHeaders...)
+	for _, h := range vary {
+		it.vary = append(it.vary, textproto.CanonicalMIMEHeaderKey(h))
+	}
+	return it
+}
+
+// Before either registers the request as the one running the handler or, if
+// an identical request is already in flight, waits for its response and
+// writes it.
+func (it Interceptor) Before(w safehttp.ResponseWriter, r *safehttp.IncomingRequest, _ safehttp.InterceptorConfig) safehttp.Result {
+	if !coalescedMethods[r.Method()] {
+		return safehttp.NotWritten()
+	}
+	key := it.key(r)
+
+	it.g.mu.Lock()
+	if c, ok := it.g.calls[key]; ok {
+		c.dups++
+		it.g.mu.Unlock()
+		return wait(w, r, c)
+	}
+	c := &call{key: key, done: make(chan struct{}), ctx: r.Context()}
+	it.g.calls[key] = c
+	it.g.mu.Unlock()
+
+	// The Commit phase doesn't run if the handler panics or doesn't write a
+	// response, so the call is also forgotten once the request is done.
+	go func() {
+		<-c.ctx.Done()
+		it.g.forget(c)
+	}()
+
+	r.Values().Set(callKey, c)
+	return safehttp.NotWritten()
+}
+
+// Commit shares the response with the requests waiting for it, if the request
+// was the one running the handler.
+func (it Interceptor) Commit(w safehttp.ResponseHeadersWriter, r *safehttp.IncomingRequest, resp safehttp.Response, _ safehttp.InterceptorConfig) {
+	v, ok := r.Values().Get(callKey)
+	if !ok {
+		return
+	}
+	c := v.(*call)
+	it.g.forget(c)
+	if shareable(resp) {
+		c.resp = resp
+	}
+	close(c.done)
+}
+
+// Match returns false since there are no supported configurations.
+func (Interceptor) Match(safehttp.InterceptorConfig) bool {
+	return false
+}
+
+func (it Interceptor) key(r *safehttp.IncomingRequest) string {
+	var b strings.Builder
+	b.WriteString(r.Method())
+	b.WriteByte(' ')
+	b.WriteString(r.URL().String())
+	for _, h := range it.vary {
+		b.WriteByte('\n')
+		b.WriteString(h)
+		for _, v := range r.Header.Values(h) {
+			b.WriteByte('\x00')
+			b.WriteString(v)
+		}
+	}
+	return b.String()
+}
+
+// wait waits for the call to complete and writes its response. If the call
+// completes without a shareable response, or the request is canceled, the
+// request is processed normally.
+func wait(w safehttp.ResponseWriter, r *safehttp.IncomingRequest, c *call) safehttp.Result {
+	select {
+	case <-c.done:
+		return write(w, r, c.resp)
+	case <-c.ctx.Done():
+	case <-r.Context().Done():
+	}
+	select {
+	case <-c.done:
+		return write(w, r, c.resp)
+	default:
+		return safehttp.NotWritten()
+	}
+}
+
+func write(w safehttp.ResponseWriter, r *safehttp.IncomingRequest, resp safehttp.Response) safehttp.Result {
+	switch resp := resp.(type) {
+	case nil:
+		return safehttp.NotWritten()
+	case safehttp.ErrorResponse:
+		return w.WriteError(resp)
+	case safehttp.RedirectResponse:
+		resp.Request = r
+		return w.Write(resp)
+	}
+	return w.Write(resp)
+}
+
+// shareable reports whether the response can be written by other requests. The
+// response types are compared exactly, so that wrapped responses aren't shared
+// either.
+func shareable(resp safehttp.Response) bool {
+	switch resp.(type) {
+	case safehtml.HTML, safehttp.JSONResponse, safehttp.RedirectResponse,
+		safehttp.NoContentResponse, safehttp.NotModifiedResponse, safehttp.StatusCode:
+		return true
+	}
+	return false
+}
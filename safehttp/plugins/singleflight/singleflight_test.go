@@ -0,0 +1,324 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package singleflight
+
+import (
+	"fmt"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/go-safeweb/safehttp"
+	"github.com/google/go-safeweb/safehttp/plugins/csp"
+	"github.com/google/safehtml"
+	"github.com/google/safehtml/template"
+)
+
+// waitForDups waits until n requests are waiting for the in-flight call.
+func waitForDups(t *testing.T, it Interceptor, n int) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		it.g.mu.Lock()
+		dups := 0
+		for _, c := range it.g.calls {
+			dups += c.dups
+		}
+		it.g.mu.Unlock()
+		if dups == n {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("timed out waiting for %d duplicate requests", n)
+}
+
+type testServer struct {
+	it      Interceptor
+	mux     *safehttp.ServeMux
+	calls   int32
+	started chan struct{}
+	release chan struct{}
+}
+
+func newTestServer(vary ...string) *testServer {
+	s := &testServer{
+		it:      New(vary...),
+		started: make(chan struct{}, 10),
+		release: make(chan struct{}),
+	}
+	mb := safehttp.NewServeMuxConfig(nil)
+	mb.Intercept(s.it)
+	s.mux = mb.Mux()
+	h := safehttp.HandlerFunc(func(w safehttp.ResponseWriter, r *safehttp.IncomingRequest) safehttp.Result {
+		n := atomic.AddInt32(&s.calls, 1)
+		s.started <- struct{}{}
+		<-s.release
+		if r.URL().Path() == "/error" {
+			return w.WriteError(safehttp.StatusTeapot)
+		}
+		return w.Write(safehtml.HTMLEscaped(fmt.Sprintf("%s %d", r.URL().Path(), n)))
+	})
+	s.mux.Handle("/", safehttp.MethodGet, h)
+	s.mux.Handle("/", safehttp.MethodPost, h)
+	return s
+}
+
+func (s *testServer) serve(wg *sync.WaitGroup, rr *httptest.ResponseRecorder, method, target string, headers map[string]string) {
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		r := httptest.NewRequest(method, target, nil)
+		for k, v := range headers {
+			r.Header.Set(k, v)
+		}
+		s.mux.ServeHTTP(rr, r)
+	}()
+}
+
+func TestCoalesced(t *testing.T) {
+	tests := []struct {
+		name     string
+		target   string
+		wantCode int
+		wantBody string
+	}{
+		{
+			name:     "OK",
+			target:   "http://foo.com/expensive",
+			wantCode: int(safehttp.StatusOK),
+			wantBody: "/expensive 1",
+		},
+		{
+			name:     "Error",
+			target:   "http://foo.com/error",
+			wantCode: int(safehttp.StatusTeapot),
+			wantBody: "I'm a teapot\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestServer()
+			var wg sync.WaitGroup
+			rr1, rr2 := httptest.NewRecorder(), httptest.NewRecorder()
+
+			s.serve(&wg, rr1, safehttp.MethodGet, tt.target, nil)
+			<-s.started
+			s.serve(&wg, rr2, safehttp.MethodGet, tt.target, nil)
+			waitForDups(t, s.it, 1)
+			close(s.release)
+			wg.Wait()
+
+			if got, want := atomic.LoadInt32(&s.calls), int32(1); got != want {
+				t.Errorf("handler calls: got %d, want %d", got, want)
+			}
+			for i, rr := range []*httptest.ResponseRecorder{rr1, rr2} {
+				if rr.Code != tt.wantCode {
+					t.Errorf("rr%d.Code: got %v, want %v", i+1, rr.Code, tt.wantCode)
+				}
+				if got := rr.Body.String(); got != tt.wantBody {
+					t.Errorf("rr%d.Body: got %q, want %q", i+1, got, tt.wantBody)
+				}
+			}
+			if len(s.it.g.calls) != 0 {
+				t.Errorf("in-flight calls: got %d, want 0", len(s.it.g.calls))
+			}
+		})
+	}
+}
+
+func TestNotCoalesced(t *testing.T) {
+	type request struct {
+		method  string
+		target  string
+		headers map[string]string
+	}
+	tests := []struct {
+		name   string
+		vary   []string
+		first  request
+		second request
+	}{
+		{
+			name:   "Different paths",
+			first:  request{method: safehttp.MethodGet, target: "http://foo.com/a"},
+			second: request{method: safehttp.MethodGet, target: "http://foo.com/b"},
+		},
+		{
+			name:   "Different queries",
+			first:  request{method: safehttp.MethodGet, target: "http://foo.com/a?q=1"},
+			second: request{method: safehttp.MethodGet, target: "http://foo.com/a?q=2"},
+		},
+		{
+			name:   "Unsafe method",
+			first:  request{method: safehttp.MethodPost, target: "http://foo.com/a"},
+			second: request{method: safehttp.MethodPost, target: "http://foo.com/a"},
+		},
+		{
+			name:   "Different cookies",
+			first:  request{method: safehttp.MethodGet, target: "http://foo.com/a", headers: map[string]string{"Cookie": "user=alice"}},
+			second: request{method: safehttp.MethodGet, target: "http://foo.com/a", headers: map[string]string{"Cookie": "user=bob"}},
+		},
+		{
+			name:   "Different Vary header",
+			vary:   []string{"accept-language"},
+			first:  request{method: safehttp.MethodGet, target: "http://foo.com/a", headers: map[string]string{"Accept-Language": "en"}},
+			second: request{method: safehttp.MethodGet, target: "http://foo.com/a", headers: map[string]string{"Accept-Language": "it"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestServer(tt.vary...)
+			var wg sync.WaitGroup
+			rr1, rr2 := httptest.NewRecorder(), httptest.NewRecorder()
+
+			s.serve(&wg, rr1, tt.first.method, tt.first.target, tt.first.headers)
+			<-s.started
+			s.serve(&wg, rr2, tt.second.method, tt.second.target, tt.second.headers)
+			// The second request reaching the handler while the first one is
+			// still in flight means it wasn't coalesced.
+			<-s.started
+			close(s.release)
+			wg.Wait()
+
+			if got, want := atomic.LoadInt32(&s.calls), int32(2); got != want {
+				t.Errorf("handler calls: got %d, want %d", got, want)
+			}
+			for i, rr := range []*httptest.ResponseRecorder{rr1, rr2} {
+				if got, want := rr.Code, int(safehttp.StatusOK); got != want {
+					t.Errorf("rr%d.Code: got %v, want %v", i+1, got, want)
+				}
+			}
+		})
+	}
+}
+
+// waitForNoCalls waits until there are no in-flight calls.
+func waitForNoCalls(t *testing.T, it Interceptor) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		it.g.mu.Lock()
+		n := len(it.g.calls)
+		it.g.mu.Unlock()
+		if n == 0 {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatal("timed out waiting for the in-flight calls to be forgotten")
+}
+
+func TestCallForgottenWithoutCommit(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler safehttp.Handler
+	}{
+		{
+			name: "Panic",
+			handler: safehttp.HandlerFunc(func(w safehttp.ResponseWriter, r *safehttp.IncomingRequest) safehttp.Result {
+				panic("handler panicked")
+			}),
+		},
+		{
+			name: "Not written",
+			handler: safehttp.HandlerFunc(func(w safehttp.ResponseWriter, r *safehttp.IncomingRequest) safehttp.Result {
+				return safehttp.NotWritten()
+			}),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			it := New()
+			mb := safehttp.NewServeMuxConfig(nil)
+			mb.Intercept(it)
+			mux := mb.Mux()
+			mux.Handle("/", safehttp.MethodGet, tt.handler)
+
+			func() {
+				defer func() { recover() }()
+				mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(safehttp.MethodGet, "http://foo.com/a", nil))
+			}()
+			waitForNoCalls(t, it)
+
+			// A later identical request isn't stuck waiting for the first one.
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				defer func() { recover() }()
+				mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(safehttp.MethodGet, "http://foo.com/a", nil))
+			}()
+			select {
+			case <-done:
+			case <-time.After(5 * time.Second):
+				t.Fatal("second request is still waiting for the first one")
+			}
+			waitForNoCalls(t, it)
+		})
+	}
+}
+
+func TestTemplateNotShared(t *testing.T) {
+	it := New()
+	mb := safehttp.NewServeMuxConfig(nil)
+	mb.Intercept(it)
+	for _, c := range csp.Default("") {
+		mb.Intercept(c)
+	}
+	mux := mb.Mux()
+	tmpl := template.Must(template.New("page").Funcs(template.FuncMap{"CSPNonce": func() string { return "" }}).
+		Parse(`<script nonce="{{CSPNonce}}"></script>`))
+	var calls int32
+	started, release := make(chan struct{}, 10), make(chan struct{})
+	mux.Handle("/", safehttp.MethodGet, safehttp.HandlerFunc(func(w safehttp.ResponseWriter, r *safehttp.IncomingRequest) safehttp.Result {
+		atomic.AddInt32(&calls, 1)
+		started <- struct{}{}
+		<-release
+		return safehttp.ExecuteTemplate(w, tmpl, nil)
+	}))
+
+	var wg sync.WaitGroup
+	rrs := []*httptest.ResponseRecorder{httptest.NewRecorder(), httptest.NewRecorder()}
+	for i, rr := range rrs {
+		wg.Add(1)
+		go func(rr *httptest.ResponseRecorder) {
+			defer wg.Done()
+			mux.ServeHTTP(rr, httptest.NewRequest(safehttp.MethodGet, "http://foo.com/page", nil))
+		}(rr)
+		if i == 0 {
+			<-started
+		}
+	}
+	waitForDups(t, it, 1)
+	close(release)
+	wg.Wait()
+
+	if got, want := atomic.LoadInt32(&calls), int32(2); got != want {
+		t.Errorf("handler calls: got %d, want %d", got, want)
+	}
+	for i, rr := range rrs {
+		if got, want := rr.Code, int(safehttp.StatusOK); got != want {
+			t.Errorf("rr%d.Code: got %v, want %v", i+1, got, want)
+		}
+		body := rr.Body.String()
+		nonce := strings.TrimSuffix(strings.TrimPrefix(body, `<script nonce="`), `"></script>`)
+		if nonce == "" || nonce == body || !strings.Contains(rr.Header().Get("Content-Security-Policy"), "'nonce-"+nonce+"'") {
+			t.Errorf("rr%d: the nonce of the body %q doesn't match the CSP header %q", i+1, body, rr.Header().Get("Content-Security-Policy"))
+		}
+	}
+}
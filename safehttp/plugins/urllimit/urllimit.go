@@ -0,0 +1,106 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package urllimit provides a safehttp.Interceptor which rejects requests with
+// overly long URLs.
+//
+// Extremely long URLs can be used to exhaust server resources and to overflow
+// logs of downstream systems.
+package urllimit
+
+import (
+	"log"
+	"strings"
+
+	"github.com/google/go-safeweb/safehttp"
+)
+
+const (
+	// DefaultMaxPathLength is the default maximum length of the URL path.
+	DefaultMaxPathLength = 2048
+	// DefaultMaxQueryLength is the default maximum length of the encoded query
+	// string.
+	DefaultMaxQueryLength = 4096
+	// DefaultMaxParams is the default maximum number of query parameters.
+	DefaultMaxParams = 100
+)
+
+// Interceptor rejects requests whose URL exceeds the configured limits with
+// 414 URI Too Long.
+//
+// The zero value is valid and ready to use.
+type Interceptor struct {
+	// MaxPathLength is the maximum length, in bytes, of the URL path. If zero,
+	// DefaultMaxPathLength is used.
+	MaxPathLength int
+	// MaxQueryLength is the maximum length, in bytes, of the encoded query
+	// string. If zero, DefaultMaxQueryLength is used.
+	MaxQueryLength int
+	// MaxParams is the maximum number of query parameters, counting repeated
+	// parameters once per occurrence. If zero, DefaultMaxParams is used.
+	MaxParams int
+}
+
+var _ safehttp.Interceptor = Interceptor{}
+
+// Before checks the length of the request URL against the configured limits.
+func (it Interceptor) Before(w safehttp.ResponseWriter, r *safehttp.IncomingRequest, _ safehttp.InterceptorConfig) safehttp.Result {
+	u := r.URL()
+	if n := len(u.Path()); n > orDefault(it.MaxPathLength, DefaultMaxPathLength) {
+		return reject(w, "path length", n)
+	}
+	q := u.RawQuery()
+	if n := len(q); n > orDefault(it.MaxQueryLength, DefaultMaxQueryLength) {
+		return reject(w, "query length", n)
+	}
+	if n := countParams(q); n > orDefault(it.MaxParams, DefaultMaxParams) {
+		return reject(w, "number of query parameters", n)
+	}
+	return safehttp.NotWritten()
+}
+
+// Commit is a no-op, required to satisfy the safehttp.Interceptor interface.
+func (Interceptor) Commit(w safehttp.ResponseHeadersWriter, r *safehttp.IncomingRequest, resp safehttp.Response, _ safehttp.InterceptorConfig) {
+}
+
+// Match returns false since there are no supported configurations.
+func (Interceptor) Match(safehttp.InterceptorConfig) bool {
+	return false
+}
+
+func reject(w safehttp.ResponseWriter, limit string, n int) safehttp.Result {
+	if safehttp.IsLocalDev() {
+		log.Printf("urllimit plugin rejected a request: %s %d exceeds the limit", limit, n)
+	}
+	return w.WriteError(safehttp.StatusRequestURITooLong)
+}
+
+func orDefault(v, def int) int {
+	if v == 0 {
+		return def
+	}
+	return v
+}
+
+// countParams counts the non-empty key=value pairs in the query, the same way
+// net/url.ParseQuery splits them.
+func countParams(query string) int {
+	n := 0
+	for _, p := range strings.Split(query, "&") {
+		if p != "" {
+			n++
+		}
+	}
+	return n
+}
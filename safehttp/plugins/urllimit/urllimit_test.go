@@ -0,0 +1,123 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package urllimit_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-safeweb/safehttp"
+	"github.com/google/go-safeweb/safehttp/plugins/urllimit"
+	"github.com/google/go-safeweb/safehttp/safehttptest"
+)
+
+func TestURLLimit(t *testing.T) {
+	it := urllimit.Interceptor{
+		MaxPathLength:  10,
+		MaxQueryLength: 20,
+		MaxParams:      3,
+	}
+	tests := []struct {
+		name       string
+		url        string
+		wantStatus safehttp.StatusCode
+	}{
+		{
+			name:       "Within limits",
+			url:        "https://example.com/short?a=1&b=2&c=3",
+			wantStatus: safehttp.StatusOK,
+		},
+		{
+			name:       "Path too long",
+			url:        "https://example.com/" + strings.Repeat("a", 10),
+			wantStatus: safehttp.StatusRequestURITooLong,
+		},
+		{
+			name:       "Query too long",
+			url:        "https://example.com/?q=" + strings.Repeat("a", 19),
+			wantStatus: safehttp.StatusRequestURITooLong,
+		},
+		{
+			name:       "Too many parameters",
+			url:        "https://example.com/?a=1&b=2&c=3&d=4",
+			wantStatus: safehttp.StatusRequestURITooLong,
+		},
+		{
+			name:       "Repeated parameters are counted",
+			url:        "https://example.com/?a=1&a=2&a=3&a=4",
+			wantStatus: safehttp.StatusRequestURITooLong,
+		},
+		{
+			name:       "Empty parameters are not counted",
+			url:        "https://example.com/?a=1&&b=2&&c=3",
+			wantStatus: safehttp.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := safehttptest.NewRequest(safehttp.MethodGet, tt.url, nil)
+			fakeRW, rr := safehttptest.NewFakeResponseWriter()
+
+			it.Before(fakeRW, req, nil)
+
+			if got, want := rr.Code, int(tt.wantStatus); got != want {
+				t.Errorf("rr.Code got: %v want: %v", got, want)
+			}
+		})
+	}
+}
+
+func TestURLLimitDefaults(t *testing.T) {
+	tests := []struct {
+		name       string
+		url        string
+		wantStatus safehttp.StatusCode
+	}{
+		{
+			name:       "Normal request",
+			url:        "https://example.com/some/path?q=search&page=2",
+			wantStatus: safehttp.StatusOK,
+		},
+		{
+			name:       "Path too long",
+			url:        "https://example.com/" + strings.Repeat("a", urllimit.DefaultMaxPathLength),
+			wantStatus: safehttp.StatusRequestURITooLong,
+		},
+		{
+			name:       "Query too long",
+			url:        "https://example.com/?q=" + strings.Repeat("a", urllimit.DefaultMaxQueryLength),
+			wantStatus: safehttp.StatusRequestURITooLong,
+		},
+		{
+			name:       "Too many parameters",
+			url:        "https://example.com/?" + strings.Repeat("a=1&", urllimit.DefaultMaxParams+1),
+			wantStatus: safehttp.StatusRequestURITooLong,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := safehttptest.NewRequest(safehttp.MethodGet, tt.url, nil)
+			fakeRW, rr := safehttptest.NewFakeResponseWriter()
+
+			urllimit.Interceptor{}.Before(fakeRW, req, nil)
+
+			if got, want := rr.Code, int(tt.wantStatus); got != want {
+				t.Errorf("rr.Code got: %v want: %v", got, want)
+			}
+		})
+	}
+}
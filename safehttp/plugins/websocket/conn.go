@@ -0,0 +1,298 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package websocket
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// MessageType is the type of a message.
+type MessageType int
+
+const (
+	// TextMessage is a message of UTF-8 encoded text.
+	TextMessage MessageType = 1
+	// BinaryMessage is a message of binary data.
+	BinaryMessage MessageType = 2
+)
+
+const (
+	opContinuation = 0x0
+	opText         = 0x1
+	opBinary       = 0x2
+	opClose        = 0x8
+	opPing         = 0x9
+	opPong         = 0xa
+)
+
+// Close codes, see RFC 6455, Section 7.4.1.
+const (
+	CloseNormal          = 1000
+	CloseGoingAway       = 1001
+	CloseProtocolError   = 1002
+	CloseNoStatus        = 1005
+	CloseInvalidPayload  = 1007
+	CloseMessageTooLarge = 1009
+)
+
+// ErrMessageTooLarge is returned by ReadMessage when a message is larger than
+// the ReadLimit. The connection is closed.
+var ErrMessageTooLarge = errors.New("websocket: message too large")
+
+// CloseError is returned by ReadMessage when the connection was closed by the
+// client or because of a protocol violation.
+type CloseError struct {
+	Code int
+	Text string
+}
+
+func (e *CloseError) Error() string {
+	return fmt.Sprintf("websocket: closed with code %d %s", e.Code, e.Text)
+}
+
+// Conn is a WebSocket connection. ReadMessage must not be called
+// concurrently, while the other methods can.
+type Conn struct {
+	conn        net.Conn
+	br          *bufio.Reader
+	subprotocol string
+	readLimit   int64
+	pongWait    time.Duration
+
+	writeMu   sync.Mutex
+	closeOnce sync.Once
+	done      chan struct{}
+}
+
+func newConn(conn net.Conn, br *bufio.Reader, subprotocol string, opts Options) *Conn {
+	c := &Conn{
+		conn:        conn,
+		br:          br,
+		subprotocol: subprotocol,
+		readLimit:   opts.ReadLimit,
+		pongWait:    opts.PongWait,
+		done:        make(chan struct{}),
+	}
+	if c.readLimit == 0 {
+		c.readLimit = DefaultReadLimit
+	}
+	if c.pongWait == 0 {
+		c.pongWait = DefaultPongWait
+	}
+	pingInterval := opts.PingInterval
+	if pingInterval == 0 {
+		pingInterval = DefaultPingInterval
+	}
+	conn.SetReadDeadline(time.Now().Add(c.pongWait))
+	go c.ping(pingInterval)
+	return c
+}
+
+// Subprotocol returns the negotiated subprotocol, if any.
+func (c *Conn) Subprotocol() string {
+	return c.subprotocol
+}
+
+// ReadMessage returns the next data message. Pings are answered and pongs are
+// consumed. It returns a *CloseError when the connection is closed by the
+// client or because the client violated the protocol, and
+// ErrMessageTooLarge when a message exceeds the ReadLimit.
+func (c *Conn) ReadMessage() (MessageType, []byte, error) {
+	var (
+		typ     MessageType
+		msg     []byte
+		reading bool
+	)
+	for {
+		fin, op, payload, err := c.readFrame(c.readLimit - int64(len(msg)))
+		if err != nil {
+			return 0, nil, err
+		}
+		c.conn.SetReadDeadline(time.Now().Add(c.pongWait))
+
+		switch op {
+		case opPing:
+			if err := c.writeFrame(opPong, payload); err != nil {
+				return 0, nil, err
+			}
+			continue
+		case opPong:
+			continue
+		case opClose:
+			return 0, nil, c.handleClose(payload)
+		case opText, opBinary:
+			if reading {
+				return 0, nil, c.fail(CloseProtocolError, "unfinished fragmented message")
+			}
+			typ, reading = MessageType(op), true
+		case opContinuation:
+			if !reading {
+				return 0, nil, c.fail(CloseProtocolError, "unexpected continuation frame")
+			}
+		default:
+			return 0, nil, c.fail(CloseProtocolError, "unknown opcode")
+		}
+
+		msg = append(msg, payload...)
+		if !fin {
+			continue
+		}
+		if typ == TextMessage && !utf8.Valid(msg) {
+			return 0, nil, c.fail(CloseInvalidPayload, "invalid UTF-8")
+		}
+		return typ, msg, nil
+	}
+}
+
+// readFrame reads a frame whose payload is at most limit bytes long, unless
+// it's a control frame.
+func (c *Conn) readFrame(limit int64) (fin bool, op byte, payload []byte, err error) {
+	var h [8]byte
+	if _, err := io.ReadFull(c.br, h[:2]); err != nil {
+		return false, 0, nil, err
+	}
+	fin, op = h[0]&0x80 != 0, h[0]&0x0f
+	if h[0]&0x70 != 0 {
+		return false, 0, nil, c.fail(CloseProtocolError, "reserved bits set")
+	}
+	if h[1]&0x80 == 0 {
+		return false, 0, nil, c.fail(CloseProtocolError, "unmasked client frame")
+	}
+	n := int64(h[1] & 0x7f)
+	switch n {
+	case 126:
+		if _, err := io.ReadFull(c.br, h[:2]); err != nil {
+			return false, 0, nil, err
+		}
+		n = int64(binary.BigEndian.Uint16(h[:2]))
+	case 127:
+		if _, err := io.ReadFull(c.br, h[:8]); err != nil {
+			return false, 0, nil, err
+		}
+		n = int64(binary.BigEndian.Uint64(h[:8]))
+		if n < 0 {
+			return false, 0, nil, c.fail(CloseProtocolError, "invalid length")
+		}
+	}
+	if op >= opClose {
+		if !fin || n > 125 {
+			return false, 0, nil, c.fail(CloseProtocolError, "invalid control frame")
+		}
+	} else if n > limit {
+		c.fail(CloseMessageTooLarge, "")
+		return false, 0, nil, ErrMessageTooLarge
+	}
+
+	var mask [4]byte
+	if _, err := io.ReadFull(c.br, mask[:]); err != nil {
+		return false, 0, nil, err
+	}
+	payload = make([]byte, n)
+	if _, err := io.ReadFull(c.br, payload); err != nil {
+		return false, 0, nil, err
+	}
+	for i := range payload {
+		payload[i] ^= mask[i%4]
+	}
+	return fin, op, payload, nil
+}
+
+func (c *Conn) handleClose(payload []byte) error {
+	ce := &CloseError{Code: CloseNoStatus}
+	if len(payload) >= 2 {
+		ce.Code = int(binary.BigEndian.Uint16(payload))
+		ce.Text = string(payload[2:])
+	}
+	c.closeWith(CloseNormal, "")
+	return ce
+}
+
+// fail closes the connection because of a protocol violation.
+func (c *Conn) fail(code int, text string) error {
+	c.closeWith(code, text)
+	return &CloseError{Code: code, Text: text}
+}
+
+// WriteMessage sends a message.
+func (c *Conn) WriteMessage(typ MessageType, data []byte) error {
+	if typ != TextMessage && typ != BinaryMessage {
+		return fmt.Errorf("websocket: invalid message type %d", typ)
+	}
+	return c.writeFrame(byte(typ), data)
+}
+
+func (c *Conn) writeFrame(op byte, payload []byte) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	h := make([]byte, 2, 10+len(payload))
+	h[0] = 0x80 | op
+	switch n := len(payload); {
+	case n <= 125:
+		h[1] = byte(n)
+	case n <= 0xffff:
+		h[1] = 126
+		h = append(h, 0, 0)
+		binary.BigEndian.PutUint16(h[2:], uint16(n))
+	default:
+		h[1] = 127
+		h = append(h, 0, 0, 0, 0, 0, 0, 0, 0)
+		binary.BigEndian.PutUint64(h[2:], uint64(n))
+	}
+	_, err := c.conn.Write(append(h, payload...))
+	return err
+}
+
+// Close sends a normal closure to the client and closes the connection.
+func (c *Conn) Close() error {
+	c.closeWith(CloseNormal, "")
+	return nil
+}
+
+func (c *Conn) closeWith(code int, text string) {
+	c.closeOnce.Do(func() {
+		close(c.done)
+		payload := make([]byte, 2, 2+len(text))
+		binary.BigEndian.PutUint16(payload, uint16(code))
+		c.conn.SetWriteDeadline(time.Now().Add(time.Second))
+		c.writeFrame(opClose, append(payload, text...))
+		c.conn.Close()
+	})
+}
+
+// ping sends pings to the client until the connection is closed.
+func (c *Conn) ping(interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-c.done:
+			return
+		case <-t.C:
+			c.conn.SetWriteDeadline(time.Now().Add(c.pongWait))
+			if err := c.writeFrame(opPing, nil); err != nil {
+				c.conn.Close()
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,186 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package websocket provides WebSocket (RFC 6455) endpoints.
+//
+// Upgrade performs the opening handshake through the safehttp.ResponseWriter,
+// so that interceptors run as for any other response, and hands a
+// message-oriented Conn to the handler:
+//
+//	func chat(w safehttp.ResponseWriter, r *safehttp.IncomingRequest) safehttp.Result {
+//		return websocket.Upgrade(w, r, websocket.Options{Subprotocols: []string{"chat.v1"}}, func(c *websocket.Conn) {
+//			for {
+//				typ, msg, err := c.ReadMessage()
+//				if err != nil {
+//					return
+//				}
+//				if err := c.WriteMessage(typ, msg); err != nil {
+//					return
+//				}
+//			}
+//		})
+//	}
+//
+// # Security
+//
+// WebSocket connections are not subject to the same-origin policy, hence any
+// website could open one to the server with the cookies of the user
+// (cross-site WebSocket hijacking). Upgrade only accepts handshakes sent by
+// pages of the same origin as the server or of the allowed origins.
+package websocket
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/base64"
+	"log"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/go-safeweb/safehttp"
+)
+
+const (
+	// DefaultReadLimit is the default maximum size of a received message.
+	DefaultReadLimit = 64 << 10
+	// DefaultPingInterval is the default interval between the pings sent to
+	// the client.
+	DefaultPingInterval = 30 * time.Second
+	// DefaultPongWait is the default time after which a connection is closed
+	// if nothing, including pongs, was received.
+	DefaultPongWait = 60 * time.Second
+)
+
+// Options configure a WebSocket endpoint.
+type Options struct {
+	// AllowedOrigins are the origins, besides the one of the server, whose
+	// pages are allowed to connect, e.g. "https://app.example.com".
+	AllowedOrigins []string
+	// Subprotocols are the supported subprotocols, in order of preference.
+	// If set, clients must request one of them.
+	Subprotocols []string
+	// ReadLimit is the maximum size of a received message. If zero,
+	// DefaultReadLimit is used.
+	ReadLimit int64
+	// PingInterval is the interval between the pings sent to the client. If
+	// zero, DefaultPingInterval is used.
+	PingInterval time.Duration
+	// PongWait is the time after which the connection is closed if nothing,
+	// including pongs, was received. Frames are only received while
+	// Conn.ReadMessage is being called. If zero, DefaultPongWait is used.
+	PongWait time.Duration
+}
+
+const acceptGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// Upgrade validates the opening handshake and switches the connection to the
+// WebSocket protocol. serve is then called with the connection, which is
+// closed when serve returns.
+//
+// Invalid handshakes are rejected with 400 Bad Request, handshakes from
+// disallowed origins with 403 Forbidden and unsupported versions with 426
+// Upgrade Required.
+func Upgrade(w safehttp.ResponseWriter, r *safehttp.IncomingRequest, opts Options, serve func(*Conn)) safehttp.Result {
+	h := r.Header
+	if r.Method() != safehttp.MethodGet || !headerContains(h.Values("Connection"), "upgrade") || !headerContains(h.Values("Upgrade"), "websocket") {
+		return w.WriteError(safehttp.StatusBadRequest)
+	}
+	if h.Get("Sec-WebSocket-Version") != "13" {
+		w.Header().Set("Sec-WebSocket-Version", "13")
+		return w.WriteError(safehttp.StatusUpgradeRequired)
+	}
+	key := h.Get("Sec-WebSocket-Key")
+	if k, err := base64.StdEncoding.DecodeString(key); err != nil || len(k) != 16 {
+		return w.WriteError(safehttp.StatusBadRequest)
+	}
+	if !allowedOrigin(r, opts.AllowedOrigins) {
+		if safehttp.IsLocalDev() {
+			log.Printf("websocket plugin rejected a handshake from origin %q", h.Get("Origin"))
+		}
+		return w.WriteError(safehttp.StatusForbidden)
+	}
+	protocol, ok := negotiateSubprotocol(h.Values("Sec-WebSocket-Protocol"), opts.Subprotocols)
+	if !ok {
+		return w.WriteError(safehttp.StatusBadRequest)
+	}
+
+	sum := sha1.Sum([]byte(key + acceptGUID))
+	w.Header().Set("Sec-WebSocket-Accept", base64.StdEncoding.EncodeToString(sum[:]))
+	if protocol != "" {
+		w.Header().Set("Sec-WebSocket-Protocol", protocol)
+	}
+	return w.Write(safehttp.UpgradeResponse{
+		Protocol: "websocket",
+		Serve: func(conn net.Conn, brw *bufio.ReadWriter) {
+			c := newConn(conn, brw.Reader, protocol, opts)
+			defer c.Close()
+			serve(c)
+		},
+	})
+}
+
+// allowedOrigin reports whether the handshake was sent by a page of the same
+// origin as the server or of an allowed origin. Handshakes without Origin are
+// not sent by browsers, hence they are allowed.
+func allowedOrigin(r *safehttp.IncomingRequest, allowed []string) bool {
+	if site := r.Header.Get("Sec-Fetch-Site"); site == "same-origin" {
+		return true
+	}
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	for _, o := range allowed {
+		if strings.EqualFold(o, origin) {
+			return true
+		}
+	}
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	return strings.EqualFold(u.Host, r.Host())
+}
+
+// negotiateSubprotocol returns the preferred supported subprotocol requested
+// by the client. It fails if subprotocols are supported but the client
+// requested none of them.
+func negotiateSubprotocol(requested []string, supported []string) (string, bool) {
+	if len(supported) == 0 {
+		return "", true
+	}
+	for _, s := range supported {
+		for _, v := range requested {
+			for _, p := range strings.Split(v, ",") {
+				if strings.TrimSpace(p) == s {
+					return s, true
+				}
+			}
+		}
+	}
+	return "", false
+}
+
+func headerContains(values []string, token string) bool {
+	for _, v := range values {
+		for _, t := range strings.Split(v, ",") {
+			if strings.EqualFold(strings.TrimSpace(t), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,281 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package websocket_test
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-safeweb/safehttp"
+	"github.com/google/go-safeweb/safehttp/plugins/websocket"
+)
+
+const testKey = "dGhlIHNhbXBsZSBub25jZQ=="
+
+func newEchoServer(t *testing.T, opts websocket.Options) *httptest.Server {
+	t.Helper()
+	mux := safehttp.NewServeMuxConfig(nil).Mux()
+	mux.Handle("/ws", safehttp.MethodGet, safehttp.HandlerFunc(func(w safehttp.ResponseWriter, r *safehttp.IncomingRequest) safehttp.Result {
+		return websocket.Upgrade(w, r, opts, func(c *websocket.Conn) {
+			for {
+				typ, msg, err := c.ReadMessage()
+				if err != nil {
+					return
+				}
+				if err := c.WriteMessage(typ, append([]byte(c.Subprotocol()+":"), msg...)); err != nil {
+					return
+				}
+			}
+		})
+	}))
+	s := httptest.NewServer(mux)
+	t.Cleanup(s.Close)
+	return s
+}
+
+type client struct {
+	t    *testing.T
+	conn net.Conn
+	br   *bufio.Reader
+}
+
+// dial performs the opening handshake with the given headers and returns the
+// response, and the client if the connection was upgraded.
+func dial(t *testing.T, s *httptest.Server, headers map[string]string) (*http.Response, *client) {
+	t.Helper()
+	conn, err := net.Dial("tcp", s.Listener.Addr().String())
+	if err != nil {
+		t.Fatalf("net.Dial: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	req, _ := http.NewRequest(safehttp.MethodGet, s.URL+"/ws", nil)
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	req.Header.Set("Sec-WebSocket-Key", testKey)
+	for k, v := range headers {
+		if v == "" {
+			req.Header.Del(k)
+		} else {
+			req.Header.Set(k, v)
+		}
+	}
+	if err := req.Write(conn); err != nil {
+		t.Fatalf("req.Write: %v", err)
+	}
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, req)
+	if err != nil {
+		t.Fatalf("http.ReadResponse: %v", err)
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		return resp, nil
+	}
+	return resp, &client{t: t, conn: conn, br: br}
+}
+
+func (c *client) send(fin bool, op byte, payload []byte) {
+	c.t.Helper()
+	b0 := op
+	if fin {
+		b0 |= 0x80
+	}
+	frame := []byte{b0}
+	switch n := len(payload); {
+	case n <= 125:
+		frame = append(frame, 0x80|byte(n))
+	default:
+		frame = append(frame, 0x80|126, byte(n>>8), byte(n))
+	}
+	mask := []byte{1, 2, 3, 4}
+	frame = append(frame, mask...)
+	for i, b := range payload {
+		frame = append(frame, b^mask[i%4])
+	}
+	if _, err := c.conn.Write(frame); err != nil {
+		c.t.Fatalf("conn.Write: %v", err)
+	}
+}
+
+func (c *client) recv() (op byte, payload []byte) {
+	c.t.Helper()
+	var h [2]byte
+	if _, err := io.ReadFull(c.br, h[:]); err != nil {
+		c.t.Fatalf("reading frame header: %v", err)
+	}
+	if h[1]&0x80 != 0 {
+		c.t.Fatal("got masked server frame")
+	}
+	n := int(h[1] & 0x7f)
+	if n == 126 {
+		var l [2]byte
+		io.ReadFull(c.br, l[:])
+		n = int(binary.BigEndian.Uint16(l[:]))
+	}
+	payload = make([]byte, n)
+	if _, err := io.ReadFull(c.br, payload); err != nil {
+		c.t.Fatalf("reading frame payload: %v", err)
+	}
+	return h[0] & 0x0f, payload
+}
+
+func TestHandshake(t *testing.T) {
+	s := newEchoServer(t, websocket.Options{})
+	resp, c := dial(t, s, map[string]string{"Origin": s.URL})
+	if c == nil {
+		t.Fatalf("resp.StatusCode: got %d want 101", resp.StatusCode)
+	}
+	// The example from RFC 6455, Section 1.3.
+	if got, want := resp.Header.Get("Sec-WebSocket-Accept"), "s3pPLMBiTxaQ9kYGzzhZRbK+xOo="; got != want {
+		t.Errorf("Sec-WebSocket-Accept: got %q want %q", got, want)
+	}
+	if got := resp.Header.Get("Content-Type"); got != "" {
+		t.Errorf("Content-Type: got %q, want none", got)
+	}
+}
+
+func TestHandshakeRejected(t *testing.T) {
+	tests := []struct {
+		name     string
+		headers  map[string]string
+		wantCode int
+	}{
+		{name: "Not an upgrade", headers: map[string]string{"Upgrade": ""}, wantCode: http.StatusBadRequest},
+		{name: "Bad version", headers: map[string]string{"Sec-WebSocket-Version": "8"}, wantCode: http.StatusUpgradeRequired},
+		{name: "Bad key", headers: map[string]string{"Sec-WebSocket-Key": "short"}, wantCode: http.StatusBadRequest},
+		{name: "Cross-origin", headers: map[string]string{"Origin": "https://evil.com"}, wantCode: http.StatusForbidden},
+		{name: "Cross-site", headers: map[string]string{"Origin": "https://evil.com", "Sec-Fetch-Site": "cross-site"}, wantCode: http.StatusForbidden},
+		{name: "Unsupported subprotocol", headers: map[string]string{"Sec-WebSocket-Protocol": "other"}, wantCode: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newEchoServer(t, websocket.Options{Subprotocols: []string{"chat.v2", "chat.v1"}, AllowedOrigins: []string{"https://app.example.com"}})
+			h := map[string]string{"Sec-WebSocket-Protocol": "chat.v1"}
+			for k, v := range tt.headers {
+				h[k] = v
+			}
+			resp, _ := dial(t, s, h)
+			if resp.StatusCode != tt.wantCode {
+				t.Errorf("resp.StatusCode: got %d want %d", resp.StatusCode, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestEcho(t *testing.T) {
+	s := newEchoServer(t, websocket.Options{Subprotocols: []string{"chat.v2", "chat.v1"}, AllowedOrigins: []string{"https://app.example.com"}})
+	resp, c := dial(t, s, map[string]string{"Origin": "https://app.example.com", "Sec-WebSocket-Protocol": "chat.v1, chat.v2"})
+	if c == nil {
+		t.Fatalf("resp.StatusCode: got %d want 101", resp.StatusCode)
+	}
+	if got, want := resp.Header.Get("Sec-WebSocket-Protocol"), "chat.v2"; got != want {
+		t.Errorf("Sec-WebSocket-Protocol: got %q want %q", got, want)
+	}
+
+	c.send(true, 0x1, []byte("hello"))
+	if op, msg := c.recv(); op != 0x1 || string(msg) != "chat.v2:hello" {
+		t.Errorf("echo: got op %d msg %q, want text %q", op, msg, "chat.v2:hello")
+	}
+
+	// Fragmented message with an interleaved ping.
+	c.send(false, 0x2, []byte("ab"))
+	c.send(true, 0x9, []byte("p"))
+	if op, msg := c.recv(); op != 0xa || string(msg) != "p" {
+		t.Errorf("ping: got op %d msg %q, want pong %q", op, msg, "p")
+	}
+	c.send(true, 0x0, bytes.Repeat([]byte("c"), 200))
+	if op, msg := c.recv(); op != 0x2 || string(msg) != "chat.v2:ab"+strings.Repeat("c", 200) {
+		t.Errorf("fragmented: got op %d msg of length %d", op, len(msg))
+	}
+
+	c.send(true, 0x8, []byte{0x03, 0xe8})
+	if op, msg := c.recv(); op != 0x8 || binary.BigEndian.Uint16(msg) != websocket.CloseNormal {
+		t.Errorf("close: got op %d msg %v, want close 1000", op, msg)
+	}
+}
+
+func TestProtocolViolations(t *testing.T) {
+	tests := []struct {
+		name     string
+		send     func(c *client)
+		wantCode uint16
+	}{
+		{
+			name:     "Too large",
+			send:     func(c *client) { c.send(true, 0x2, make([]byte, 20)) },
+			wantCode: websocket.CloseMessageTooLarge,
+		},
+		{
+			name: "Too large fragmented",
+			send: func(c *client) {
+				c.send(false, 0x2, make([]byte, 10))
+				c.send(true, 0x0, make([]byte, 10))
+			},
+			wantCode: websocket.CloseMessageTooLarge,
+		},
+		{
+			name:     "Invalid UTF-8",
+			send:     func(c *client) { c.send(true, 0x1, []byte{0xff}) },
+			wantCode: websocket.CloseInvalidPayload,
+		},
+		{
+			name:     "Unexpected continuation",
+			send:     func(c *client) { c.send(true, 0x0, []byte("x")) },
+			wantCode: websocket.CloseProtocolError,
+		},
+		{
+			name: "Unmasked",
+			send: func(c *client) {
+				c.conn.Write([]byte{0x81, 0x01, 'x'})
+			},
+			wantCode: websocket.CloseProtocolError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newEchoServer(t, websocket.Options{ReadLimit: 16})
+			_, c := dial(t, s, nil)
+			if c == nil {
+				t.Fatal("handshake failed")
+			}
+			tt.send(c)
+			op, msg := c.recv()
+			if op != 0x8 || len(msg) < 2 || binary.BigEndian.Uint16(msg) != tt.wantCode {
+				t.Errorf("got op %d msg %v, want close %d", op, msg, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestPing(t *testing.T) {
+	s := newEchoServer(t, websocket.Options{PingInterval: 10 * time.Millisecond})
+	_, c := dial(t, s, nil)
+	if c == nil {
+		t.Fatal("handshake failed")
+	}
+	if op, _ := c.recv(); op != 0x9 {
+		t.Errorf("got op %d, want ping", op)
+	}
+}
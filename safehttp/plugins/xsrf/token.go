@@ -0,0 +1,79 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xsrf
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TokenTimeout is the duration for which the tokens are valid.
+const TokenTimeout = 24 * time.Hour
+
+// GenerateToken returns a token binding the userID and the actionID, issued
+// at the given time, e.g. the Now of a safehttp.Clock. The tokens have the
+// format of the golang.org/x/net/xsrftoken package, so that the tokens of
+// either can be verified by the other.
+//
+// GenerateToken panics if key is empty.
+func GenerateToken(key, userID, actionID string, now time.Time) string {
+	if key == "" {
+		panic("zero length xsrf secret key")
+	}
+	// The time is rounded up to milliseconds.
+	millis := (now.UnixNano() + 1e6 - 1) / 1e6
+	h := hmac.New(sha1.New, []byte(key))
+	fmt.Fprintf(h, "%s:%s:%d", escapeColons(userID), escapeColons(actionID), millis)
+	tok := base64.RawURLEncoding.EncodeToString(h.Sum(nil))
+	return tok + ":" + strconv.FormatInt(millis, 10)
+}
+
+// ValidToken reports whether the token was generated by GenerateToken for the
+// userID and the actionID, and is still valid at the given time: it must have
+// been issued less than TokenTimeout before, and at most a minute after, to
+// tolerate clock skew between servers.
+//
+// ValidToken panics if key is empty.
+func ValidToken(token, key, userID, actionID string, now time.Time) bool {
+	if key == "" {
+		panic("zero length xsrf secret key")
+	}
+	i := strings.LastIndexByte(token, ':')
+	if i < 0 {
+		return false
+	}
+	millis, err := strconv.ParseInt(token[i+1:], 10, 64)
+	if err != nil {
+		return false
+	}
+	issued := time.Unix(0, millis*1e6)
+	if now.Sub(issued) >= TokenTimeout || issued.After(now.Add(time.Minute)) {
+		return false
+	}
+	want := GenerateToken(key, userID, actionID, issued)
+	return subtle.ConstantTimeCompare([]byte(token), []byte(want)) == 1
+}
+
+// escapeColons doubles the colons of s, like the golang.org/x/net/xsrftoken
+// package does for the fields of the MAC input.
+func escapeColons(s string) string {
+	return strings.Replace(s, ":", "::", -1)
+}
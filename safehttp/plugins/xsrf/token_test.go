@@ -0,0 +1,105 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xsrf_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-safeweb/safehttp/plugins/xsrf"
+	"golang.org/x/net/xsrftoken"
+)
+
+func TestTokenCompatibility(t *testing.T) {
+	now := time.Now()
+	if tok := xsrf.GenerateToken("key", "user:1", "action", now); !xsrftoken.Valid(tok, "key", "user:1", "action") {
+		t.Errorf("xsrftoken.Valid(%q): got false, want true", tok)
+	}
+	if tok := xsrftoken.Generate("key", "user:1", "action"); !xsrf.ValidToken(tok, "key", "user:1", "action", now) {
+		t.Errorf("xsrf.ValidToken(%q): got false, want true", tok)
+	}
+}
+
+func TestValidToken(t *testing.T) {
+	issued := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	tok := xsrf.GenerateToken("key", "user", "action", issued)
+	tests := []struct {
+		name     string
+		token    string
+		userID   string
+		actionID string
+		now      time.Time
+		want     bool
+	}{
+		{
+			name:     "Valid",
+			token:    tok,
+			userID:   "user",
+			actionID: "action",
+			now:      issued.Add(time.Hour),
+			want:     true,
+		},
+		{
+			name:     "Slightly in the future",
+			token:    tok,
+			userID:   "user",
+			actionID: "action",
+			now:      issued.Add(-30 * time.Second),
+			want:     true,
+		},
+		{
+			name:     "Future",
+			token:    tok,
+			userID:   "user",
+			actionID: "action",
+			now:      issued.Add(-2 * time.Minute),
+		},
+		{
+			name:     "Expired",
+			token:    tok,
+			userID:   "user",
+			actionID: "action",
+			now:      issued.Add(xsrf.TokenTimeout),
+		},
+		{
+			name:     "Other user",
+			token:    tok,
+			userID:   "other",
+			actionID: "action",
+			now:      issued,
+		},
+		{
+			name:     "Other action",
+			token:    tok,
+			userID:   "user",
+			actionID: "other",
+			now:      issued,
+		},
+		{
+			name:     "Malformed",
+			token:    "nocolon",
+			userID:   "user",
+			actionID: "action",
+			now:      issued,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := xsrf.ValidToken(tt.token, "key", tt.userID, tt.actionID, tt.now); got != tt.want {
+				t.Errorf("xsrf.ValidToken: got %v want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -15,9 +15,9 @@
 package xsrfangular
 
 import (
-	"crypto/rand"
 	"encoding/base64"
 	"fmt"
+	"io"
 	"time"
 
 	"github.com/google/go-safeweb/safehttp"
@@ -33,6 +33,8 @@ type Interceptor struct {
 	TokenCookieName string
 	// TokenHeaderName is the name of the HTTP header that holds the XSRF token.
 	TokenHeaderName string
+	// Rand is the source of the tokens. If nil, safehttp.SystemRand is used.
+	Rand io.Reader
 }
 
 var _ safehttp.Interceptor = &Interceptor{}
@@ -74,10 +76,17 @@ func (it *Interceptor) Before(w safehttp.ResponseWriter, r *safehttp.IncomingReq
 	return safehttp.NotWritten()
 }
 
+func (it *Interceptor) random() io.Reader {
+	if it.Rand == nil {
+		return safehttp.SystemRand()
+	}
+	return it.Rand
+}
+
 func (it *Interceptor) addTokenCookie(w safehttp.ResponseHeadersWriter) error {
 	tok := make([]byte, 20)
-	if _, err := rand.Read(tok); err != nil {
-		return fmt.Errorf("crypto/rand.Read: %v", err)
+	if _, err := io.ReadFull(it.random(), tok); err != nil {
+		return fmt.Errorf("reading the token: %v", err)
 	}
 	c := safehttp.NewCookie(it.TokenCookieName, base64.StdEncoding.EncodeToString(tok))
 
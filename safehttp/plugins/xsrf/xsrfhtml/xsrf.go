@@ -15,14 +15,14 @@
 package xsrfhtml
 
 import (
-	"crypto/rand"
 	"encoding/base64"
 	"fmt"
+	"io"
+	"time"
 
 	"github.com/google/go-safeweb/safehttp"
 	"github.com/google/go-safeweb/safehttp/plugins/htmlinject"
 	"github.com/google/go-safeweb/safehttp/plugins/xsrf"
-	"golang.org/x/net/xsrftoken"
 )
 
 const (
@@ -37,14 +37,34 @@ type Interceptor struct {
 	// SecretAppKey uniquely identifies each registered service and should have
 	// high entropy as it is used for generating the XSRF token.
 	SecretAppKey string
+	// Clock is used to issue the tokens and to check whether they expired. If
+	// nil, safehttp.SystemClock is used.
+	Clock safehttp.Clock
+	// Rand is the source of the cookie IDs. If nil, safehttp.SystemRand is
+	// used.
+	Rand io.Reader
 }
 
 var _ safehttp.Interceptor = &Interceptor{}
 
-func addCookieID(w safehttp.ResponseHeadersWriter) (*safehttp.Cookie, error) {
+func (it *Interceptor) now() time.Time {
+	if it.Clock == nil {
+		return safehttp.SystemClock().Now()
+	}
+	return it.Clock.Now()
+}
+
+func (it *Interceptor) random() io.Reader {
+	if it.Rand == nil {
+		return safehttp.SystemRand()
+	}
+	return it.Rand
+}
+
+func (it *Interceptor) addCookieID(w safehttp.ResponseHeadersWriter) (*safehttp.Cookie, error) {
 	buf := make([]byte, 20)
-	if _, err := rand.Read(buf); err != nil {
-		return nil, fmt.Errorf("crypto/rand.Read: %v", err)
+	if _, err := io.ReadFull(it.random(), buf); err != nil {
+		return nil, fmt.Errorf("reading the cookie ID: %v", err)
 	}
 
 	c := safehttp.NewCookie(cookieIDKey, base64.StdEncoding.EncodeToString(buf))
@@ -84,7 +104,7 @@ func (it *Interceptor) Before(w safehttp.ResponseWriter, r *safehttp.IncomingReq
 		return w.WriteError(safehttp.StatusUnauthorized)
 	}
 
-	if ok := xsrftoken.Valid(tok, it.SecretAppKey, cookieID.Value(), r.URL().Host()); !ok {
+	if ok := xsrf.ValidToken(tok, it.SecretAppKey, cookieID.Value(), r.URL().Host(), it.now()); !ok {
 		return w.WriteError(safehttp.StatusForbidden)
 	}
 
@@ -111,21 +131,21 @@ func (it *Interceptor) Commit(w safehttp.ResponseHeadersWriter, r *safehttp.Inco
 			// Not a state preserving request, so we won't be adding the cookie.
 			return
 		}
-		cookieID, err = addCookieID(w)
+		cookieID, err = it.addCookieID(w)
 		if err != nil {
 			// This is a server misconfiguration.
 			panic("cannot add cookie ID")
 		}
 	}
 
-	tmplResp, ok := resp.(*safehttp.TemplateResponse)
+	tmplResp, ok := safehttp.TemplateOf(resp)
 	if !ok {
 		// If it's not a template response, we cannot inject the token.
 		// TODO: should this be an error?
 		return
 	}
 
-	tok := xsrftoken.Generate(it.SecretAppKey, cookieID.Value(), r.URL().Host())
+	tok := xsrf.GenerateToken(it.SecretAppKey, cookieID.Value(), r.URL().Host(), it.now())
 	if tmplResp.FuncMap == nil {
 		tmplResp.FuncMap = map[string]interface{}{}
 	}
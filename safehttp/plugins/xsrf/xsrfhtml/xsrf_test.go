@@ -17,8 +17,10 @@ package xsrfhtml
 import (
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/go-safeweb/safehttp"
+	"github.com/google/go-safeweb/safehttp/plugins/xsrf"
 	"github.com/google/go-safeweb/safehttp/safehttptest"
 	"golang.org/x/net/xsrftoken"
 )
@@ -92,6 +94,48 @@ func TestTokenMultipart(t *testing.T) {
 	}
 }
 
+type fakeClock time.Time
+
+func (c fakeClock) Now() time.Time {
+	return time.Time(c)
+}
+
+func TestTokenExpiry(t *testing.T) {
+	issued := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	tok := xsrf.GenerateToken("testSecretAppKey", "abcdef", "go.dev", issued)
+	tests := []struct {
+		name       string
+		now        time.Time
+		wantStatus safehttp.StatusCode
+	}{
+		{
+			name:       "Valid",
+			now:        issued.Add(time.Hour),
+			wantStatus: safehttp.StatusOK,
+		},
+		{
+			name:       "Expired",
+			now:        issued.Add(xsrf.TokenTimeout),
+			wantStatus: safehttp.StatusForbidden,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeRW, rr := safehttptest.NewFakeResponseWriter()
+			req := safehttptest.NewRequest(safehttp.MethodPost, "https://go.dev/", strings.NewReader(TokenKey+"="+tok))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			req.Header.Set("Cookie", cookieIDKey+"=abcdef")
+
+			i := Interceptor{SecretAppKey: "testSecretAppKey", Clock: fakeClock(tt.now)}
+			i.Before(fakeRW, req, nil)
+
+			if got := rr.Code; got != int(tt.wantStatus) {
+				t.Errorf("rr.Code: got %v, want %v", got, tt.wantStatus)
+			}
+		})
+	}
+}
+
 func TestMalformedForm(t *testing.T) {
 	fakeRW, rr := safehttptest.NewFakeResponseWriter()
 	req := safehttptest.NewRequest(safehttp.MethodPost, "https://foo.com/pizza", nil)
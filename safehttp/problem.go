@@ -0,0 +1,129 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package safehttp
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/google/safehtml/template"
+)
+
+// ProblemResponse is an ErrorResponse with the details of the problem, as
+// defined by RFC 7807. It can be written with ResponseWriter.WriteError:
+//
+//	return w.WriteError(&safehttp.ProblemResponse{
+//		Status:  safehttp.StatusConflict,
+//		Type:    "https://example.com/probs/out-of-credit",
+//		Detail:  "Your current balance is 30, but that costs 50.",
+//		Request: r,
+//	})
+//
+// The DefaultDispatcher writes it as application/problem+json, or as an HTML
+// page if the Accept header of the Request prefers text/html, e.g. because the
+// request was made by a browser navigation. Without a Request, the problem is
+// always written as JSON.
+type ProblemResponse struct {
+	// Status is the HTTP status code, which must be an error (400-599). If
+	// zero, 500 Internal Server Error is used.
+	Status StatusCode
+	// Type is a URI reference identifying the problem type. If empty,
+	// "about:blank" is used.
+	Type string
+	// Title is a short summary of the problem type. If empty, the text of
+	// the status code is used.
+	Title string
+	// Detail is an explanation specific to this occurrence of the problem.
+	Detail string
+	// Instance is a URI reference identifying this occurrence of the
+	// problem.
+	Instance string
+	// Extensions are additional members of the problem details object. They
+	// are only written in the JSON format and can't override the members
+	// above.
+	Extensions map[string]interface{}
+
+	// Request is the request the problem is the response to. It's used to
+	// negotiate the format of the response.
+	Request *IncomingRequest
+}
+
+// Code implements ErrorResponse.
+func (p *ProblemResponse) Code() StatusCode {
+	if p.Status == 0 {
+		return StatusInternalServerError
+	}
+	return p.Status
+}
+
+func (p *ProblemResponse) title() string {
+	if p.Title != "" {
+		return p.Title
+	}
+	return http.StatusText(int(p.Code()))
+}
+
+// MarshalJSON returns the problem details object.
+func (p *ProblemResponse) MarshalJSON() ([]byte, error) {
+	m := make(map[string]interface{}, len(p.Extensions)+5)
+	for k, v := range p.Extensions {
+		m[k] = v
+	}
+	m["type"] = p.Type
+	if p.Type == "" {
+		m["type"] = "about:blank"
+	}
+	m["title"] = p.title()
+	m["status"] = p.Code()
+	if p.Detail != "" {
+		m["detail"] = p.Detail
+	} else {
+		delete(m, "detail")
+	}
+	if p.Instance != "" {
+		m["instance"] = p.Instance
+	} else {
+		delete(m, "instance")
+	}
+	return json.Marshal(m)
+}
+
+var problemTemplate = template.Must(template.New("problem").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>{{.Title}}</title></head>
+<body>
+<h1>{{.Title}}</h1>
+{{- with .Detail}}
+<p>{{.}}</p>
+{{- end}}
+</body>
+</html>
+`))
+
+func writeProblem(rw http.ResponseWriter, p *ProblemResponse) error {
+	h := rw.Header()
+	h.Set("X-Content-Type-Options", "nosniff")
+	if p.Request != nil {
+		h.Add("Vary", "Accept")
+		if p.Request.Negotiate("application/problem+json", "application/json", "text/html") == "text/html" {
+			h.Set("Content-Type", "text/html; charset=utf-8")
+			rw.WriteHeader(int(p.Code()))
+			return problemTemplate.Execute(rw, struct{ Title, Detail string }{p.title(), p.Detail})
+		}
+	}
+	h.Set("Content-Type", "application/problem+json")
+	rw.WriteHeader(int(p.Code()))
+	return json.NewEncoder(rw).Encode(p)
+}
@@ -0,0 +1,153 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package safehttp_test
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-safeweb/safehttp"
+)
+
+func TestProblemResponse(t *testing.T) {
+	tests := []struct {
+		name        string
+		accept      string
+		problem     func(r *safehttp.IncomingRequest) *safehttp.ProblemResponse
+		wantStatus  safehttp.StatusCode
+		wantHeaders map[string][]string
+		wantJSON    map[string]interface{}
+		wantBody    string
+	}{
+		{
+			name: "JSON",
+			problem: func(r *safehttp.IncomingRequest) *safehttp.ProblemResponse {
+				return &safehttp.ProblemResponse{
+					Status:     safehttp.StatusConflict,
+					Type:       "https://example.com/probs/out-of-credit",
+					Detail:     "Your current balance is 30, but that costs 50.",
+					Instance:   "/account/12345/msgs/abc",
+					Extensions: map[string]interface{}{"balance": 30, "status": 200},
+					Request:    r,
+				}
+			},
+			wantStatus: safehttp.StatusConflict,
+			wantHeaders: map[string][]string{
+				"Content-Type":           {"application/problem+json"},
+				"Vary":                   {"Accept"},
+				"X-Content-Type-Options": {"nosniff"},
+			},
+			wantJSON: map[string]interface{}{
+				"type":     "https://example.com/probs/out-of-credit",
+				"title":    "Conflict",
+				"status":   float64(409),
+				"detail":   "Your current balance is 30, but that costs 50.",
+				"instance": "/account/12345/msgs/abc",
+				"balance":  float64(30),
+			},
+		},
+		{
+			name: "Defaults",
+			problem: func(r *safehttp.IncomingRequest) *safehttp.ProblemResponse {
+				return &safehttp.ProblemResponse{}
+			},
+			wantStatus: safehttp.StatusInternalServerError,
+			wantHeaders: map[string][]string{
+				"Content-Type":           {"application/problem+json"},
+				"X-Content-Type-Options": {"nosniff"},
+			},
+			wantJSON: map[string]interface{}{
+				"type":   "about:blank",
+				"title":  "Internal Server Error",
+				"status": float64(500),
+			},
+		},
+		{
+			name:   "HTML",
+			accept: "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8",
+			problem: func(r *safehttp.IncomingRequest) *safehttp.ProblemResponse {
+				return &safehttp.ProblemResponse{
+					Status:  safehttp.StatusNotFound,
+					Title:   "No such <user>",
+					Detail:  "User <b>bob</b> doesn't exist.",
+					Request: r,
+				}
+			},
+			wantStatus: safehttp.StatusNotFound,
+			wantHeaders: map[string][]string{
+				"Content-Type":           {"text/html; charset=utf-8"},
+				"Vary":                   {"Accept"},
+				"X-Content-Type-Options": {"nosniff"},
+			},
+			wantBody: "<!DOCTYPE html>\n<html>\n<head><meta charset=\"utf-8\"><title>No such &lt;user&gt;</title></head>\n<body>\n" +
+				"<h1>No such &lt;user&gt;</h1>\n<p>User &lt;b&gt;bob&lt;/b&gt; doesn&#39;t exist.</p>\n</body>\n</html>\n",
+		},
+		{
+			name:   "JSON preferred",
+			accept: "application/json, text/html;q=0.5",
+			problem: func(r *safehttp.IncomingRequest) *safehttp.ProblemResponse {
+				return &safehttp.ProblemResponse{Status: safehttp.StatusForbidden, Request: r}
+			},
+			wantStatus: safehttp.StatusForbidden,
+			wantHeaders: map[string][]string{
+				"Content-Type":           {"application/problem+json"},
+				"Vary":                   {"Accept"},
+				"X-Content-Type-Options": {"nosniff"},
+			},
+			wantJSON: map[string]interface{}{
+				"type":   "about:blank",
+				"title":  "Forbidden",
+				"status": float64(403),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mux := safehttp.NewServeMuxConfig(nil).Mux()
+			mux.Handle("/", safehttp.MethodGet, safehttp.HandlerFunc(func(w safehttp.ResponseWriter, r *safehttp.IncomingRequest) safehttp.Result {
+				return w.WriteError(tt.problem(r))
+			}))
+			req := httptest.NewRequest(safehttp.MethodGet, "http://foo.com/", nil)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			rr := httptest.NewRecorder()
+			mux.ServeHTTP(rr, req)
+
+			if got, want := rr.Code, int(tt.wantStatus); got != want {
+				t.Errorf("rr.Code: got %v want %v", got, want)
+			}
+			if diff := cmp.Diff(tt.wantHeaders, map[string][]string(rr.Header())); diff != "" {
+				t.Errorf("rr.Header() mismatch (-want +got):\n%s", diff)
+			}
+			if tt.wantJSON == nil {
+				if got := rr.Body.String(); got != tt.wantBody {
+					t.Errorf("response body: got %q want %q", got, tt.wantBody)
+				}
+				return
+			}
+			var got map[string]interface{}
+			if err := json.Unmarshal(rr.Body.Bytes(), &got); err != nil {
+				t.Fatalf("json.Unmarshal(%q): %v", rr.Body.String(), err)
+			}
+			if diff := cmp.Diff(tt.wantJSON, got); diff != "" {
+				t.Errorf("problem mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
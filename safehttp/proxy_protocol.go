@@ -0,0 +1,286 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package safehttp
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ProxyProtocolConfig configures the HAProxy PROXY protocol (versions 1 and
+// 2), used by TCP load balancers to report the address of the client at the
+// beginning of the connections. The address is then seen as the address of
+// the peer, e.g. by IncomingRequest.ClientIP.
+//
+// More info:
+//   - https://www.haproxy.org/download/2.9/doc/proxy-protocol.txt
+type ProxyProtocolConfig struct {
+	// TrustedProxies are the networks of the load balancers, which must send
+	// the PROXY header: their connections without one are closed. The
+	// connections from other peers are served as they are, so that clients
+	// can't forge their address. See ParseCIDRs.
+	TrustedProxies []*net.IPNet
+
+	// HeaderTimeout is the time allowed to read the PROXY header. If zero,
+	// 5 seconds are allowed.
+	HeaderTimeout time.Duration
+
+	// MaxHandshakes is the maximum number of connections whose PROXY header
+	// is being read concurrently. New connections aren't accepted while the
+	// limit is reached, so that peers which are slow to send the header can't
+	// exhaust the resources of the server before the connection limits of the
+	// Server apply. If zero, the MaxConnections of the Server is used, or
+	// 1024 if it isn't set.
+	MaxHandshakes int
+}
+
+// defaultMaxProxyHandshakes is used if neither the MaxHandshakes of the
+// ProxyProtocolConfig nor the MaxConnections of the Server are set.
+const defaultMaxProxyHandshakes = 1024
+
+// ProxyProtocolListener wraps the listener to read the PROXY header of the
+// connections from the trusted proxies. The headers are read in the
+// background, so that slow peers don't hold up the other connections, up to
+// cfg.MaxHandshakes at a time.
+func ProxyProtocolListener(l net.Listener, cfg ProxyProtocolConfig) net.Listener {
+	if cfg.HeaderTimeout == 0 {
+		cfg.HeaderTimeout = 5 * time.Second
+	}
+	if cfg.MaxHandshakes <= 0 {
+		cfg.MaxHandshakes = defaultMaxProxyHandshakes
+	}
+	return &proxyListener{
+		Listener:   l,
+		cfg:        cfg,
+		handshakes: make(chan struct{}, cfg.MaxHandshakes),
+		conns:      make(chan net.Conn),
+		errs:       make(chan error),
+		done:       make(chan struct{}),
+	}
+}
+
+type proxyListener struct {
+	net.Listener
+	cfg ProxyProtocolConfig
+
+	// handshakes holds a token for each handshake in progress.
+	handshakes chan struct{}
+	startOnce  sync.Once
+	closeOnce  sync.Once
+	conns      chan net.Conn
+	errs       chan error
+	done       chan struct{}
+}
+
+func (l *proxyListener) Accept() (net.Conn, error) {
+	l.startOnce.Do(func() { go l.acceptLoop() })
+	select {
+	case c := <-l.conns:
+		return c, nil
+	case err := <-l.errs:
+		return nil, err
+	case <-l.done:
+		return nil, net.ErrClosed
+	}
+}
+
+func (l *proxyListener) Close() error {
+	l.closeOnce.Do(func() { close(l.done) })
+	return l.Listener.Close()
+}
+
+func (l *proxyListener) acceptLoop() {
+	for {
+		select {
+		case l.handshakes <- struct{}{}:
+		case <-l.done:
+			return
+		}
+		c, err := l.Listener.Accept()
+		if err != nil {
+			<-l.handshakes
+			select {
+			case l.errs <- err:
+			case <-l.done:
+				return
+			}
+			if te, ok := err.(interface{ Temporary() bool }); ok && te.Temporary() {
+				continue
+			}
+			return
+		}
+		go l.handshake(c)
+	}
+}
+
+// handshake reads the PROXY header of the connections from trusted proxies
+// and hands the connection to Accept. It releases the token taken by
+// acceptLoop once the connection was accepted or closed.
+func (l *proxyListener) handshake(c net.Conn) {
+	defer func() { <-l.handshakes }()
+	if addr, ok := c.RemoteAddr().(*net.TCPAddr); !ok || !ipInNets(addr.IP, l.cfg.TrustedProxies) {
+		l.hand(c)
+		return
+	}
+	c.SetReadDeadline(time.Now().Add(l.cfg.HeaderTimeout))
+	br := bufio.NewReader(c)
+	src, err := readProxyHeader(br)
+	if err != nil {
+		c.Close()
+		return
+	}
+	c.SetReadDeadline(time.Time{})
+	l.hand(&proxyConn{Conn: c, r: br, remote: src})
+}
+
+func (l *proxyListener) hand(c net.Conn) {
+	select {
+	case l.conns <- c:
+	case <-l.done:
+		c.Close()
+	}
+}
+
+func ipInNets(ip net.IP, nets []*net.IPNet) bool {
+	for _, n := range nets {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// proxyConn is a connection whose PROXY header was read.
+type proxyConn struct {
+	net.Conn
+	// r holds the data buffered after the header.
+	r *bufio.Reader
+	// remote is the address of the client, or nil if the proxy didn't report
+	// it (e.g. for health checks).
+	remote net.Addr
+}
+
+func (c *proxyConn) Read(b []byte) (int, error) {
+	return c.r.Read(b)
+}
+
+func (c *proxyConn) RemoteAddr() net.Addr {
+	if c.remote != nil {
+		return c.remote
+	}
+	return c.Conn.RemoteAddr()
+}
+
+var (
+	proxyV1Prefix  = []byte("PROXY ")
+	proxyV2Sig     = []byte("\r\n\r\n\x00\r\nQUIT\n")
+	errProxyHeader = errors.New("invalid PROXY header")
+)
+
+// readProxyHeader reads a PROXY header and returns the source address it
+// reports, or nil if there's none.
+func readProxyHeader(r *bufio.Reader) (net.Addr, error) {
+	start, err := r.Peek(len(proxyV1Prefix))
+	if err != nil {
+		return nil, err
+	}
+	if bytes.Equal(start, proxyV1Prefix) {
+		return readProxyV1(r)
+	}
+	sig, err := r.Peek(len(proxyV2Sig))
+	if err != nil || !bytes.Equal(sig, proxyV2Sig) {
+		return nil, errProxyHeader
+	}
+	return readProxyV2(r)
+}
+
+func readProxyV1(r *bufio.Reader) (net.Addr, error) {
+	// The longest v1 header is 107 bytes long.
+	var line []byte
+	for len(line) < 107 {
+		b, err := r.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		line = append(line, b)
+		if b == '\n' {
+			break
+		}
+	}
+	s := string(line)
+	if !strings.HasSuffix(s, "\r\n") {
+		return nil, errProxyHeader
+	}
+	fields := strings.Split(strings.TrimSuffix(s, "\r\n"), " ")
+	if len(fields) >= 2 && fields[1] == "UNKNOWN" {
+		return nil, nil
+	}
+	if len(fields) != 6 || fields[1] != "TCP4" && fields[1] != "TCP6" {
+		return nil, errProxyHeader
+	}
+	ip := net.ParseIP(fields[2])
+	port, err := strconv.ParseUint(fields[4], 10, 16)
+	if ip == nil || err != nil || (ip.To4() != nil) != (fields[1] == "TCP4") {
+		return nil, errProxyHeader
+	}
+	return &net.TCPAddr{IP: ip, Port: int(port)}, nil
+}
+
+func readProxyV2(r *bufio.Reader) (net.Addr, error) {
+	var hdr [16]byte
+	if _, err := io.ReadFull(r, hdr[:]); err != nil {
+		return nil, err
+	}
+	if hdr[12]>>4 != 2 {
+		return nil, fmt.Errorf("unsupported PROXY version %d", hdr[12]>>4)
+	}
+	body := make([]byte, binary.BigEndian.Uint16(hdr[14:]))
+	if _, err := io.ReadFull(r, body); err != nil {
+		return nil, err
+	}
+	switch cmd := hdr[12] & 0xf; cmd {
+	case 0:
+		// LOCAL: the connection was made by the proxy itself.
+		return nil, nil
+	case 1:
+		// PROXY
+	default:
+		return nil, fmt.Errorf("unsupported PROXY command %d", cmd)
+	}
+	switch hdr[13] {
+	case 0x11: // TCP over IPv4
+		if len(body) < 12 {
+			return nil, errProxyHeader
+		}
+		return &net.TCPAddr{IP: net.IP(body[0:4]), Port: int(binary.BigEndian.Uint16(body[8:]))}, nil
+	case 0x21: // TCP over IPv6
+		if len(body) < 36 {
+			return nil, errProxyHeader
+		}
+		return &net.TCPAddr{IP: net.IP(body[0:16]), Port: int(binary.BigEndian.Uint16(body[32:]))}, nil
+	default:
+		// Other protocols, e.g. UDP or Unix sockets, have no client IP.
+		return nil, nil
+	}
+}
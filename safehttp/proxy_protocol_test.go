@@ -0,0 +1,218 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package safehttp
+
+import (
+	"bufio"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/safehtml"
+)
+
+func startProxyProtocolServer(t *testing.T, trusted string) string {
+	t.Helper()
+	nets, err := ParseCIDRs(trusted)
+	if err != nil {
+		t.Fatalf("ParseCIDRs: %v", err)
+	}
+	mux := NewServeMuxConfig(nil).Mux()
+	mux.Handle("/", MethodGet, HandlerFunc(func(w ResponseWriter, r *IncomingRequest) Result {
+		return w.Write(safehtml.HTMLEscaped(r.ClientIP().String()))
+	}))
+	s := &Server{Mux: mux, ProxyProtocol: &ProxyProtocolConfig{TrustedProxies: nets, HeaderTimeout: time.Second}}
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("net.Listen: %v", err)
+	}
+	go s.Serve(l)
+	t.Cleanup(func() { s.Close() })
+	return l.Addr().String()
+}
+
+// proxyRequest sends the PROXY header followed by a request and returns the
+// response body, or an error if the response couldn't be read.
+func proxyRequest(t *testing.T, addr, header string) (int, string, error) {
+	t.Helper()
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatalf("net.Dial: %v", err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+	if _, err := conn.Write([]byte(header + "GET / HTTP/1.1\r\nHost: foo.com\r\n\r\n")); err != nil {
+		return 0, "", err
+	}
+	resp, err := http.ReadResponse(bufio.NewReader(conn), nil)
+	if err != nil {
+		return 0, "", err
+	}
+	defer resp.Body.Close()
+	b, err := ioutil.ReadAll(resp.Body)
+	return resp.StatusCode, string(b), err
+}
+
+func TestProxyProtocol(t *testing.T) {
+	v2 := func(cmd, fam byte, body string) string {
+		return "\r\n\r\n\x00\r\nQUIT\n" + string([]byte{0x20 | cmd, fam, 0, byte(len(body))}) + body
+	}
+	tests := []struct {
+		name   string
+		header string
+		want   string
+	}{
+		{
+			name:   "v1 TCP4",
+			header: "PROXY TCP4 192.0.2.1 198.51.100.1 56324 443\r\n",
+			want:   "192.0.2.1",
+		},
+		{
+			name:   "v1 TCP6",
+			header: "PROXY TCP6 2001:db8::1 2001:db8::2 56324 443\r\n",
+			want:   "2001:db8::1",
+		},
+		{
+			name:   "v1 UNKNOWN",
+			header: "PROXY UNKNOWN\r\n",
+			want:   "127.0.0.1",
+		},
+		{
+			name:   "v2 TCP4",
+			header: v2(1, 0x11, "\xc0\x00\x02\x01\xc6\x33\x64\x01\xdc\x04\x01\xbb"),
+			want:   "192.0.2.1",
+		},
+		{
+			name:   "v2 TCP6 with TLV",
+			header: v2(1, 0x21, "\x20\x01\x0d\xb8"+strings.Repeat("\x00", 11)+"\x01"+strings.Repeat("\x00", 16)+"\xdc\x04\x01\xbb"+"\x04\x00\x01x"),
+			want:   "2001:db8::1",
+		},
+		{
+			name:   "v2 LOCAL",
+			header: v2(0, 0x00, ""),
+			want:   "127.0.0.1",
+		},
+	}
+
+	addr := startProxyProtocolServer(t, "127.0.0.1")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, body, err := proxyRequest(t, addr, tt.header)
+			if err != nil {
+				t.Fatalf("proxyRequest: %v", err)
+			}
+			if code != http.StatusOK {
+				t.Errorf("status code: got %v want %v", code, http.StatusOK)
+			}
+			if body != tt.want {
+				t.Errorf("client IP: got %q want %q", body, tt.want)
+			}
+		})
+	}
+}
+
+func TestProxyProtocolInvalidHeader(t *testing.T) {
+	addr := startProxyProtocolServer(t, "127.0.0.1")
+	// Make sure that the server started.
+	if _, _, err := proxyRequest(t, addr, "PROXY UNKNOWN\r\n"); err != nil {
+		t.Fatalf("proxyRequest: %v", err)
+	}
+	for _, header := range []string{
+		"",
+		"PROXY TCP4 192.0.2.1\r\n",
+		"PROXY TCP4 2001:db8::1 2001:db8::2 56324 443\r\n",
+		"PROXY TCP4 192.0.2.1 198.51.100.1 99999 443\r\n",
+	} {
+		if _, _, err := proxyRequest(t, addr, header); err == nil {
+			t.Errorf("header %q: got a response, want the connection closed", header)
+		}
+	}
+}
+
+func TestProxyProtocolUntrustedPeer(t *testing.T) {
+	addr := startProxyProtocolServer(t, "10.0.0.0/8")
+
+	code, body, err := proxyRequest(t, addr, "")
+	if err != nil {
+		t.Fatalf("proxyRequest: %v", err)
+	}
+	if code != http.StatusOK || body != "127.0.0.1" {
+		t.Errorf("without header: got %v %q, want %v %q", code, body, http.StatusOK, "127.0.0.1")
+	}
+
+	code, _, err = proxyRequest(t, addr, "PROXY TCP4 192.0.2.1 198.51.100.1 56324 443\r\n")
+	if err == nil && code != http.StatusBadRequest {
+		t.Errorf("with a forged header: got %v, want %v", code, http.StatusBadRequest)
+	}
+}
+
+func TestProxyProtocolMaxHandshakes(t *testing.T) {
+	nets, err := ParseCIDRs("127.0.0.1")
+	if err != nil {
+		t.Fatalf("ParseCIDRs: %v", err)
+	}
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("net.Listen: %v", err)
+	}
+	l := ProxyProtocolListener(ln, ProxyProtocolConfig{TrustedProxies: nets, HeaderTimeout: time.Minute, MaxHandshakes: 1})
+	defer l.Close()
+	accepted := make(chan net.Conn)
+	go func() {
+		for {
+			c, err := l.Accept()
+			if err != nil {
+				return
+			}
+			accepted <- c
+		}
+	}()
+
+	// The first connection doesn't send the PROXY header.
+	slow, err := net.Dial("tcp", ln.Addr().String())
+	if err != nil {
+		t.Fatalf("net.Dial: %v", err)
+	}
+	defer slow.Close()
+	fast, err := net.Dial("tcp", ln.Addr().String())
+	if err != nil {
+		t.Fatalf("net.Dial: %v", err)
+	}
+	defer fast.Close()
+	if _, err := fast.Write([]byte("PROXY TCP4 192.0.2.1 198.51.100.1 56324 443\r\n")); err != nil {
+		t.Fatalf("fast.Write: %v", err)
+	}
+
+	select {
+	case c := <-accepted:
+		c.Close()
+		t.Fatal("Accept returned a connection while a handshake was in progress")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	slow.Close()
+	select {
+	case c := <-accepted:
+		defer c.Close()
+		if got, want := c.RemoteAddr().String(), "192.0.2.1:56324"; got != want {
+			t.Errorf("c.RemoteAddr(): got %q, want %q", got, want)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Accept didn't return the connection after the handshake in progress failed")
+	}
+}
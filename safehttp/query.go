@@ -0,0 +1,67 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package safehttp
+
+import "strconv"
+
+// QueryString returns the first value of the query parameter with the given
+// name and whether it is present in the URL.
+func (r *IncomingRequest) QueryString(name string) (string, bool) {
+	vals := r.QueryAll(name)
+	if len(vals) == 0 {
+		return "", false
+	}
+	return vals[0], true
+}
+
+// QueryInt parses the first value of the query parameter with the given name
+// as a base 10 int64. The returned bool reports whether the parameter is
+// present in the URL. An error is returned if it is present but is not a valid
+// int64.
+func (r *IncomingRequest) QueryInt(name string) (int64, bool, error) {
+	v, ok := r.QueryString(name)
+	if !ok {
+		return 0, false, nil
+	}
+	i, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		return 0, true, err
+	}
+	return i, true, nil
+}
+
+// QueryBool parses the first value of the query parameter with the given name
+// as a bool, accepting the values accepted by strconv.ParseBool. The returned
+// bool reports whether the parameter is present in the URL. An error is
+// returned if it is present but is not a valid bool.
+func (r *IncomingRequest) QueryBool(name string) (bool, bool, error) {
+	v, ok := r.QueryString(name)
+	if !ok {
+		return false, false, nil
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, true, err
+	}
+	return b, true, nil
+}
+
+// QueryAll returns all the values of the query parameter with the given name,
+// in the order they appear in the URL, or nil if it's not present.
+//
+// Malformed key/value pairs in the query are ignored.
+func (r *IncomingRequest) QueryAll(name string) []string {
+	return r.req.URL.Query()[name]
+}
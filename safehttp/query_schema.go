@@ -0,0 +1,218 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package safehttp
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+)
+
+// QueryType is the type of a query parameter validated by a QuerySchema.
+type QueryType int
+
+const (
+	// QueryString accepts any value.
+	QueryString QueryType = iota
+	// QueryInt accepts base 10 integers.
+	QueryInt
+	// QueryFloat accepts floating point numbers.
+	QueryFloat
+	// QueryBool accepts the values accepted by strconv.ParseBool.
+	QueryBool
+)
+
+// QueryRange is the inclusive range of the values of a numeric query
+// parameter, or of the length of a string one.
+type QueryRange struct {
+	Min, Max float64
+}
+
+// QueryParam describes a query parameter.
+type QueryParam struct {
+	Type QueryType
+	// Required rejects requests without the parameter.
+	Required bool
+	// Multiple allows the parameter to be repeated. By default at most one
+	// value is accepted.
+	Multiple bool
+	// Range, if set, bounds the values of numeric parameters and the length
+	// of string parameters.
+	Range *QueryRange
+	// Enum, if non-empty, is the list of the allowed values.
+	Enum []string
+}
+
+// QuerySchema declares the query parameters accepted by a handler. It can be
+// passed when registering the handler, like an InterceptorConfig, e.g.
+//
+//	mux.Handle("/search", MethodGet, searchHandler, QuerySchema{
+//		Params: map[string]QueryParam{
+//			"q":     {Required: true, Range: &QueryRange{Min: 1, Max: 256}},
+//			"page":  {Type: QueryInt, Range: &QueryRange{Min: 1, Max: 100}},
+//			"order": {Enum: []string{"asc", "desc"}},
+//		},
+//	})
+//
+// The query is validated after all the other interceptors and before the
+// handler runs. Requests violating the schema are rejected with a *QueryError.
+type QuerySchema struct {
+	Params map[string]QueryParam
+	// AllowUnknown accepts parameters that are not in Params. By default they
+	// are rejected.
+	AllowUnknown bool
+}
+
+// Validate checks the query against the schema. The returned error, if any,
+// is a *QueryError.
+func (s QuerySchema) Validate(query url.Values) error {
+	var violations []QueryViolation
+	for name, vs := range query {
+		p, ok := s.Params[name]
+		if !ok {
+			if !s.AllowUnknown {
+				violations = append(violations, QueryViolation{Name: name, Reason: "unknown parameter"})
+			}
+			continue
+		}
+		if len(vs) > 1 && !p.Multiple {
+			violations = append(violations, QueryViolation{Name: name, Reason: "repeated parameter"})
+			continue
+		}
+		for _, v := range vs {
+			if reason := p.check(v); reason != "" {
+				violations = append(violations, QueryViolation{Name: name, Reason: reason})
+				break
+			}
+		}
+	}
+	for name, p := range s.Params {
+		if _, ok := query[name]; p.Required && !ok {
+			violations = append(violations, QueryViolation{Name: name, Reason: "missing parameter"})
+		}
+	}
+	if len(violations) == 0 {
+		return nil
+	}
+	sort.Slice(violations, func(i, j int) bool { return violations[i].Name < violations[j].Name })
+	return &QueryError{Violations: violations}
+}
+
+func (p QueryParam) check(v string) string {
+	var n float64
+	switch p.Type {
+	case QueryString:
+		n = float64(len(v))
+	case QueryInt:
+		i, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return "not an integer"
+		}
+		n = float64(i)
+	case QueryFloat:
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return "not a number"
+		}
+		n = f
+	case QueryBool:
+		if _, err := strconv.ParseBool(v); err != nil {
+			return "not a boolean"
+		}
+	default:
+		panic(fmt.Sprintf("unknown QueryType %d", p.Type))
+	}
+	if p.Range != nil && p.Type != QueryBool && (n < p.Range.Min || n > p.Range.Max) {
+		if p.Type == QueryString {
+			return fmt.Sprintf("length must be between %v and %v", p.Range.Min, p.Range.Max)
+		}
+		return fmt.Sprintf("must be between %v and %v", p.Range.Min, p.Range.Max)
+	}
+	if len(p.Enum) == 0 {
+		return ""
+	}
+	for _, e := range p.Enum {
+		if v == e {
+			return ""
+		}
+	}
+	return "value not allowed"
+}
+
+// QueryViolation is a query parameter that doesn't match the QuerySchema.
+type QueryViolation struct {
+	Name   string `json:"name"`
+	Reason string `json:"reason"`
+}
+
+// QueryError is the 400 Bad Request ErrorResponse written when the query of a
+// request violates the QuerySchema of the handler.
+//
+// The DefaultDispatcher writes it as an RFC 7807 "application/problem+json"
+// document, listing the violations under "invalid-params".
+type QueryError struct {
+	Violations []QueryViolation
+}
+
+func (e *QueryError) Error() string {
+	msg := "invalid query:"
+	for _, v := range e.Violations {
+		msg += fmt.Sprintf(" %s: %s;", v.Name, v.Reason)
+	}
+	return msg[:len(msg)-1]
+}
+
+// Code returns 400 Bad Request.
+func (e *QueryError) Code() StatusCode {
+	return StatusBadRequest
+}
+
+func writeQueryError(rw http.ResponseWriter, e *QueryError) error {
+	return writeProblem(rw, &ProblemResponse{
+		Status:     e.Code(),
+		Detail:     "The query parameters are invalid.",
+		Extensions: map[string]interface{}{"invalid-params": e.Violations},
+	})
+}
+
+// querySchemaInterceptor validates the query of the requests to the handlers
+// registered with a QuerySchema. It runs after the other interceptors, so that
+// the query is only validated for authorized requests.
+type querySchemaInterceptor struct{}
+
+func (querySchemaInterceptor) Before(w ResponseWriter, r *IncomingRequest, cfg InterceptorConfig) Result {
+	s, ok := cfg.(QuerySchema)
+	if !ok {
+		return NotWritten()
+	}
+	query, err := url.ParseQuery(r.req.URL.RawQuery)
+	if err != nil {
+		return w.WriteError(StatusBadRequest)
+	}
+	if err := s.Validate(query); err != nil {
+		return w.WriteError(err.(*QueryError))
+	}
+	return NotWritten()
+}
+
+func (querySchemaInterceptor) Commit(w ResponseHeadersWriter, r *IncomingRequest, resp Response, cfg InterceptorConfig) {
+}
+
+func (querySchemaInterceptor) Match(cfg InterceptorConfig) bool {
+	_, ok := cfg.(QuerySchema)
+	return ok
+}
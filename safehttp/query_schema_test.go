@@ -0,0 +1,141 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package safehttp_test
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-safeweb/safehttp"
+	"github.com/google/safehtml"
+)
+
+var searchSchema = safehttp.QuerySchema{
+	Params: map[string]safehttp.QueryParam{
+		"q":      {Required: true, Range: &safehttp.QueryRange{Min: 1, Max: 8}},
+		"page":   {Type: safehttp.QueryInt, Range: &safehttp.QueryRange{Min: 1, Max: 100}},
+		"score":  {Type: safehttp.QueryFloat},
+		"exact":  {Type: safehttp.QueryBool},
+		"order":  {Enum: []string{"asc", "desc"}},
+		"filter": {Multiple: true},
+	},
+}
+
+func TestQuerySchemaValidate(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  []safehttp.QueryViolation
+	}{
+		{name: "Valid", query: "q=go&page=2&score=1.5&exact=true&order=asc&filter=a&filter=b"},
+		{name: "Missing", query: "page=1", want: []safehttp.QueryViolation{{Name: "q", Reason: "missing parameter"}}},
+		{name: "Unknown", query: "q=go&debug=1", want: []safehttp.QueryViolation{{Name: "debug", Reason: "unknown parameter"}}},
+		{name: "Repeated", query: "q=go&q=rust", want: []safehttp.QueryViolation{{Name: "q", Reason: "repeated parameter"}}},
+		{name: "Not an integer", query: "q=go&page=x", want: []safehttp.QueryViolation{{Name: "page", Reason: "not an integer"}}},
+		{name: "Not a number", query: "q=go&score=x", want: []safehttp.QueryViolation{{Name: "score", Reason: "not a number"}}},
+		{name: "Not a boolean", query: "q=go&exact=x", want: []safehttp.QueryViolation{{Name: "exact", Reason: "not a boolean"}}},
+		{name: "Out of range", query: "q=go&page=101", want: []safehttp.QueryViolation{{Name: "page", Reason: "must be between 1 and 100"}}},
+		{name: "Too long", query: "q=123456789", want: []safehttp.QueryViolation{{Name: "q", Reason: "length must be between 1 and 8"}}},
+		{name: "Not in enum", query: "q=go&order=random", want: []safehttp.QueryViolation{{Name: "order", Reason: "value not allowed"}}},
+		{
+			name:  "Several",
+			query: "page=0&order=random",
+			want: []safehttp.QueryViolation{
+				{Name: "order", Reason: "value not allowed"},
+				{Name: "page", Reason: "must be between 1 and 100"},
+				{Name: "q", Reason: "missing parameter"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(safehttp.MethodGet, "http://foo.com/?"+tt.query, nil)
+			err := searchSchema.Validate(req.URL.Query())
+			if tt.want == nil {
+				if err != nil {
+					t.Errorf("Validate() got err %v, want nil", err)
+				}
+				return
+			}
+			qe, ok := err.(*safehttp.QueryError)
+			if !ok {
+				t.Fatalf("Validate() got err %v, want *safehttp.QueryError", err)
+			}
+			if diff := cmp.Diff(tt.want, qe.Violations); diff != "" {
+				t.Errorf("qe.Violations mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestQuerySchemaAllowUnknown(t *testing.T) {
+	s := safehttp.QuerySchema{AllowUnknown: true}
+	req := httptest.NewRequest(safehttp.MethodGet, "http://foo.com/?utm_source=x", nil)
+	if err := s.Validate(req.URL.Query()); err != nil {
+		t.Errorf("Validate() got err %v, want nil", err)
+	}
+}
+
+func TestQuerySchemaHandler(t *testing.T) {
+	var log []string
+	mb := safehttp.NewServeMuxConfig(nil)
+	mb.Intercept(recordingInterceptor{name: "auth", log: &log})
+	mux := mb.Mux()
+	mux.Handle("/search", safehttp.MethodGet, safehttp.HandlerFunc(func(w safehttp.ResponseWriter, r *safehttp.IncomingRequest) safehttp.Result {
+		log = append(log, "handler")
+		return w.Write(safehtml.HTMLEscaped("results"))
+	}), searchSchema)
+
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, httptest.NewRequest(safehttp.MethodGet, "http://foo.com/search?q=go&page=0", nil))
+
+	if diff := cmp.Diff([]string{"before auth", "commit auth"}, log); diff != "" {
+		t.Errorf("log mismatch (-want +got):\n%s", diff)
+	}
+	if got, want := rr.Code, int(safehttp.StatusBadRequest); got != want {
+		t.Errorf("rr.Code: got %v want %v", got, want)
+	}
+	if got, want := rr.Header().Get("Content-Type"), "application/problem+json"; got != want {
+		t.Errorf(`rr.Header().Get("Content-Type"): got %q want %q`, got, want)
+	}
+	var got map[string]interface{}
+	if err := json.Unmarshal(rr.Body.Bytes(), &got); err != nil {
+		t.Fatalf("json.Unmarshal: %v", err)
+	}
+	want := map[string]interface{}{
+		"type":   "about:blank",
+		"title":  "Bad Request",
+		"status": float64(400),
+		"detail": "The query parameters are invalid.",
+		"invalid-params": []interface{}{
+			map[string]interface{}{"name": "page", "reason": "must be between 1 and 100"},
+		},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("problem mismatch (-want +got):\n%s", diff)
+	}
+
+	log = nil
+	rr = httptest.NewRecorder()
+	mux.ServeHTTP(rr, httptest.NewRequest(safehttp.MethodGet, "http://foo.com/search?q=go&page=2", nil))
+	if got, want := rr.Code, int(safehttp.StatusOK); got != want {
+		t.Errorf("rr.Code: got %v want %v", got, want)
+	}
+	if diff := cmp.Diff([]string{"before auth", "handler", "commit auth"}, log); diff != "" {
+		t.Errorf("log mismatch (-want +got):\n%s", diff)
+	}
+}
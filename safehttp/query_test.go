@@ -0,0 +1,117 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package safehttp_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-safeweb/safehttp"
+	"github.com/google/go-safeweb/safehttp/safehttptest"
+)
+
+func TestQueryInt(t *testing.T) {
+	tests := []struct {
+		name        string
+		query       string
+		want        int64
+		wantPresent bool
+		wantErr     bool
+	}{
+		{name: "Valid", query: "n=-42", want: -42, wantPresent: true},
+		{name: "First value", query: "n=1&n=2", want: 1, wantPresent: true},
+		{name: "Malformed", query: "n=forty-two", wantPresent: true, wantErr: true},
+		{name: "Overflow", query: "n=9223372036854775808", wantPresent: true, wantErr: true},
+		{name: "Empty", query: "n=", wantPresent: true, wantErr: true},
+		{name: "Absent", query: "m=1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := safehttptest.NewRequest(safehttp.MethodGet, "http://foo.com/?"+tt.query, nil)
+			got, present, err := r.QueryInt("n")
+			if got != tt.want || present != tt.wantPresent || (err != nil) != tt.wantErr {
+				t.Errorf("r.QueryInt(n) got (%v, %v, %v), want (%v, %v, err: %v)", got, present, err, tt.want, tt.wantPresent, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestQueryBool(t *testing.T) {
+	tests := []struct {
+		name        string
+		query       string
+		want        bool
+		wantPresent bool
+		wantErr     bool
+	}{
+		{name: "True", query: "b=true", want: true, wantPresent: true},
+		{name: "One", query: "b=1", want: true, wantPresent: true},
+		{name: "False", query: "b=false", wantPresent: true},
+		{name: "Malformed", query: "b=yes", wantPresent: true, wantErr: true},
+		{name: "Absent", query: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := safehttptest.NewRequest(safehttp.MethodGet, "http://foo.com/?"+tt.query, nil)
+			got, present, err := r.QueryBool("b")
+			if got != tt.want || present != tt.wantPresent || (err != nil) != tt.wantErr {
+				t.Errorf("r.QueryBool(b) got (%v, %v, %v), want (%v, %v, err: %v)", got, present, err, tt.want, tt.wantPresent, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestQueryString(t *testing.T) {
+	tests := []struct {
+		name        string
+		query       string
+		want        string
+		wantPresent bool
+	}{
+		{name: "Present", query: "s=pizza%20margherita", want: "pizza margherita", wantPresent: true},
+		{name: "Empty", query: "s=", want: "", wantPresent: true},
+		{name: "Absent", query: "t=pizza"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := safehttptest.NewRequest(safehttp.MethodGet, "http://foo.com/?"+tt.query, nil)
+			got, present := r.QueryString("s")
+			if got != tt.want || present != tt.wantPresent {
+				t.Errorf("r.QueryString(s) got (%q, %v), want (%q, %v)", got, present, tt.want, tt.wantPresent)
+			}
+		})
+	}
+}
+
+func TestQueryAll(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  []string
+	}{
+		{name: "Repeated", query: "tag=a&other=x&tag=b&tag=c", want: []string{"a", "b", "c"}},
+		{name: "Single", query: "tag=a", want: []string{"a"}},
+		{name: "Absent", query: "other=x", want: nil},
+		{name: "Malformed pairs ignored", query: "tag=a&tag=%zz&tag=b", want: []string{"a", "b"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := safehttptest.NewRequest(safehttp.MethodGet, "http://foo.com/?"+tt.query, nil)
+			if diff := cmp.Diff(tt.want, r.QueryAll("tag")); diff != "" {
+				t.Errorf("r.QueryAll(tag) mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,150 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package safehttp
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// HTTPRange is a byte range requested through the Range header.
+type HTTPRange struct {
+	// Start is the offset of the first byte of the range.
+	Start int64
+	// Length is the number of bytes in the range.
+	Length int64
+}
+
+// ContentRange returns the value of the Content-Range header for a response
+// containing this range of a resource of the given size.
+func (r HTTPRange) ContentRange(size int64) string {
+	return fmt.Sprintf("bytes %d-%d/%d", r.Start, r.Start+r.Length-1, size)
+}
+
+// ErrMalformedRange is returned by IncomingRequest.ParseRange when the Range
+// header is not syntactically valid. As per RFC 7233, the header can be
+// ignored in this case and the full resource can be served.
+var ErrMalformedRange = errors.New("malformed Range header")
+
+// RangeNotSatisfiableError is returned by IncomingRequest.ParseRange when none
+// of the requested ranges overlaps the resource.
+//
+// It implements ErrorResponse and can be written with ResponseWriter.WriteError
+// after setting the Content-Range header to the value returned by
+// ContentRange.
+type RangeNotSatisfiableError struct {
+	// Size is the size of the resource.
+	Size int64
+}
+
+func (e *RangeNotSatisfiableError) Error() string {
+	return fmt.Sprintf("no requested range overlaps the resource of size %d", e.Size)
+}
+
+// Code returns StatusRequestedRangeNotSatisfiable.
+func (e *RangeNotSatisfiableError) Code() StatusCode {
+	return StatusRequestedRangeNotSatisfiable
+}
+
+// ContentRange returns the value of the Content-Range header that should be
+// sent with the 416 Requested Range Not Satisfiable response.
+func (e *RangeNotSatisfiableError) ContentRange() string {
+	return fmt.Sprintf("bytes */%d", e.Size)
+}
+
+// ParseRange parses the Range header of the request against a resource of
+// the given size. Suffix ranges ("bytes=-500") and open-ended ranges
+// ("bytes=500-") are supported, as well as multiple ranges. Ranges extending
+// past the end of the resource are truncated.
+//
+// If the request has no Range header, a nil slice and a nil error are
+// returned. If the header is malformed, ErrMalformedRange is returned. If none
+// of the ranges overlaps the resource, a *RangeNotSatisfiableError is returned.
+func (r *IncomingRequest) ParseRange(size int64) ([]HTTPRange, error) {
+	h := r.Header.Get("Range")
+	if h == "" {
+		return nil, nil
+	}
+	return parseRange(h, size)
+}
+
+func parseRange(s string, size int64) ([]HTTPRange, error) {
+	const prefix = "bytes="
+	if !strings.HasPrefix(s, prefix) {
+		return nil, ErrMalformedRange
+	}
+	var ranges []HTTPRange
+	unsatisfiable := false
+	for _, spec := range strings.Split(s[len(prefix):], ",") {
+		spec = strings.TrimSpace(spec)
+		if spec == "" {
+			continue
+		}
+		i := strings.Index(spec, "-")
+		if i < 0 {
+			return nil, ErrMalformedRange
+		}
+		first, last := strings.TrimSpace(spec[:i]), strings.TrimSpace(spec[i+1:])
+
+		var r HTTPRange
+		if first == "" {
+			// Suffix range: the last n bytes of the resource.
+			n, err := strconv.ParseInt(last, 10, 64)
+			if err != nil || n < 0 {
+				return nil, ErrMalformedRange
+			}
+			if n == 0 || size == 0 {
+				unsatisfiable = true
+				continue
+			}
+			if n > size {
+				n = size
+			}
+			r.Start = size - n
+			r.Length = n
+		} else {
+			start, err := strconv.ParseInt(first, 10, 64)
+			if err != nil || start < 0 {
+				return nil, ErrMalformedRange
+			}
+			end := size - 1
+			if last != "" {
+				end, err = strconv.ParseInt(last, 10, 64)
+				if err != nil || end < start {
+					return nil, ErrMalformedRange
+				}
+			}
+			if start >= size {
+				unsatisfiable = true
+				continue
+			}
+			if end >= size {
+				end = size - 1
+			}
+			r.Start = start
+			r.Length = end - start + 1
+		}
+		ranges = append(ranges, r)
+	}
+	if len(ranges) == 0 {
+		if unsatisfiable {
+			return nil, &RangeNotSatisfiableError{Size: size}
+		}
+		return nil, ErrMalformedRange
+	}
+	return ranges, nil
+}
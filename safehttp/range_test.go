@@ -0,0 +1,159 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package safehttp_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-safeweb/safehttp"
+	"github.com/google/go-safeweb/safehttp/safehttptest"
+)
+
+func TestParseRange(t *testing.T) {
+	tests := []struct {
+		name              string
+		header            string
+		want              []safehttp.HTTPRange
+		wantContentRanges []string
+	}{
+		{
+			name:              "Single range",
+			header:            "bytes=0-499",
+			want:              []safehttp.HTTPRange{{Start: 0, Length: 500}},
+			wantContentRanges: []string{"bytes 0-499/1000"},
+		},
+		{
+			name:              "Suffix range",
+			header:            "bytes=-500",
+			want:              []safehttp.HTTPRange{{Start: 500, Length: 500}},
+			wantContentRanges: []string{"bytes 500-999/1000"},
+		},
+		{
+			name:              "Suffix range larger than resource",
+			header:            "bytes=-5000",
+			want:              []safehttp.HTTPRange{{Start: 0, Length: 1000}},
+			wantContentRanges: []string{"bytes 0-999/1000"},
+		},
+		{
+			name:              "Open-ended range",
+			header:            "bytes=500-",
+			want:              []safehttp.HTTPRange{{Start: 500, Length: 500}},
+			wantContentRanges: []string{"bytes 500-999/1000"},
+		},
+		{
+			name:              "Range past the end is truncated",
+			header:            "bytes=900-1999",
+			want:              []safehttp.HTTPRange{{Start: 900, Length: 100}},
+			wantContentRanges: []string{"bytes 900-999/1000"},
+		},
+		{
+			name:              "Multiple ranges",
+			header:            "bytes=0-9, 20-29,-10",
+			want:              []safehttp.HTTPRange{{Start: 0, Length: 10}, {Start: 20, Length: 10}, {Start: 990, Length: 10}},
+			wantContentRanges: []string{"bytes 0-9/1000", "bytes 20-29/1000", "bytes 990-999/1000"},
+		},
+		{
+			name:              "Multiple ranges, one unsatisfiable",
+			header:            "bytes=0-9,2000-",
+			want:              []safehttp.HTTPRange{{Start: 0, Length: 10}},
+			wantContentRanges: []string{"bytes 0-9/1000"},
+		},
+		{
+			name:   "No header",
+			header: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := safehttptest.NewRequest(safehttp.MethodGet, "/", nil)
+			if tt.header != "" {
+				req.Header.Set("Range", tt.header)
+			}
+
+			got, err := req.ParseRange(1000)
+			if err != nil {
+				t.Fatalf("req.ParseRange(1000) got err: %v", err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("req.ParseRange(1000) mismatch (-want +got):\n%s", diff)
+			}
+			var cr []string
+			for _, r := range got {
+				cr = append(cr, r.ContentRange(1000))
+			}
+			if diff := cmp.Diff(tt.wantContentRanges, cr); diff != "" {
+				t.Errorf("ContentRange() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestParseRangeUnsatisfiable(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		size   int64
+	}{
+		{name: "Start past the end", header: "bytes=1000-", size: 1000},
+		{name: "Zero length suffix", header: "bytes=-0", size: 1000},
+		{name: "Empty resource", header: "bytes=0-10", size: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := safehttptest.NewRequest(safehttp.MethodGet, "/", nil)
+			req.Header.Set("Range", tt.header)
+
+			_, err := req.ParseRange(tt.size)
+			var rerr *safehttp.RangeNotSatisfiableError
+			if !errors.As(err, &rerr) {
+				t.Fatalf("req.ParseRange(%d) got err: %v, want *RangeNotSatisfiableError", tt.size, err)
+			}
+			if got, want := rerr.Code(), safehttp.StatusRequestedRangeNotSatisfiable; got != want {
+				t.Errorf("rerr.Code() got %v, want %v", got, want)
+			}
+			if got, want := rerr.ContentRange(), fmt.Sprintf("bytes */%d", tt.size); got != want {
+				t.Errorf("rerr.ContentRange() got %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestParseRangeMalformed(t *testing.T) {
+	headers := []string{
+		"0-10",
+		"bytes=",
+		"bytes=abc",
+		"bytes=10-5",
+		"bytes=a-b",
+		"bytes=--5",
+		"items=0-10",
+	}
+
+	for _, h := range headers {
+		t.Run(h, func(t *testing.T) {
+			req := safehttptest.NewRequest(safehttp.MethodGet, "/", nil)
+			req.Header.Set("Range", h)
+
+			if _, err := req.ParseRange(1000); err != safehttp.ErrMalformedRange {
+				t.Errorf("req.ParseRange(1000) got err: %v, want: %v", err, safehttp.ErrMalformedRange)
+			}
+		})
+	}
+}
@@ -0,0 +1,186 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package safehttp
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"reflect"
+	"sync/atomic"
+	"time"
+)
+
+// Reloadable is an Interceptor whose implementation can be replaced while the
+// server runs, e.g. to apply a new CORS allowlist, new hostcheck hosts or new
+// CSP report endpoints without restarting the server:
+//
+//	hosts := safehttp.NewReloadable(hostcheck.New(loadHosts()...))
+//	cfg.Intercept(hosts)
+//	safehttp.ReloadOnSignal(func() error {
+//		hosts.Store(hostcheck.New(loadHosts()...))
+//		return nil
+//	}, nil, syscall.SIGHUP)
+//
+// A request is served by a single implementation: the one loaded when its
+// Before phase started is also used for its Commit phase, even if another one
+// was stored in between.
+//
+// The configurations of the routes are matched with the interceptor when the
+// handlers are registered, hence the replacements must have the same type as
+// the initial interceptor. Reloadable is disabled by
+// restricted.DisableInterceptor like the interceptor it wraps.
+type Reloadable struct {
+	v   atomic.Value
+	typ reflect.Type
+	// key stores the implementation used for a request in its Values.
+	key Key
+}
+
+// reloadableBox makes it possible to store interceptors of different dynamic
+// types in an atomic.Value.
+type reloadableBox struct {
+	it Interceptor
+}
+
+// NewReloadable returns a Reloadable initially running it. It panics if it is
+// nil.
+func NewReloadable(it Interceptor) *Reloadable {
+	if it == nil {
+		panic("NewReloadable called with a nil interceptor")
+	}
+	r := &Reloadable{
+		typ: reflect.TypeOf(it),
+		key: NewKey("safehttp.Reloadable"),
+	}
+	r.v.Store(reloadableBox{it: it})
+	return r
+}
+
+// Load returns the current implementation.
+func (r *Reloadable) Load() Interceptor {
+	return r.v.Load().(reloadableBox).it
+}
+
+// Store replaces the implementation for the requests starting from now on. It
+// panics if it is nil or if its type differs from the one of the initial
+// interceptor.
+func (r *Reloadable) Store(it Interceptor) {
+	if it == nil {
+		panic("Reloadable.Store called with a nil interceptor")
+	}
+	if t := reflect.TypeOf(it); t != r.typ {
+		panic(fmt.Sprintf("Reloadable.Store called with a %v, want a %v", t, r.typ))
+	}
+	r.v.Store(reloadableBox{it: it})
+}
+
+// current returns the implementation used for the request, loading it on the
+// first call.
+func (r *Reloadable) current(req *IncomingRequest) Interceptor {
+	if v, ok := req.Values().Get(r.key); ok {
+		return v.(Interceptor)
+	}
+	it := r.Load()
+	req.Values().Set(r.key, it)
+	return it
+}
+
+// Before runs the Before phase of the current implementation.
+func (r *Reloadable) Before(w ResponseWriter, req *IncomingRequest, cfg InterceptorConfig) Result {
+	return r.current(req).Before(w, req, cfg)
+}
+
+// Commit runs the Commit phase of the implementation used by the Before phase
+// of the request.
+func (r *Reloadable) Commit(w ResponseHeadersWriter, req *IncomingRequest, resp Response, cfg InterceptorConfig) {
+	r.current(req).Commit(w, req, resp, cfg)
+}
+
+// OnError runs the OnError hook of the implementation used for the request,
+// if it's an ErrorObserver.
+func (r *Reloadable) OnError(w ResponseWriter, req *IncomingRequest, err error) {
+	if o, ok := r.current(req).(ErrorObserver); ok {
+		o.OnError(w, req, err)
+	}
+}
+
+// Match returns true if the current implementation matches the configuration.
+func (r *Reloadable) Match(cfg InterceptorConfig) bool {
+	return r.Load().Match(cfg)
+}
+
+// ReloadOnSignal calls load each time the process receives one of the signals,
+// typically syscall.SIGHUP. The errors returned by load are passed to onError,
+// if it's non-nil. The returned function stops watching the signals.
+func ReloadOnSignal(load func() error, onError func(error), sigs ...os.Signal) (stop func()) {
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, sigs...)
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-ch:
+				reload(load, onError)
+			case <-done:
+				return
+			}
+		}
+	}()
+	return func() {
+		signal.Stop(ch)
+		close(done)
+	}
+}
+
+// WatchFile calls load each time the modification time or the size of the
+// file at path changes, checking them with the given period. The errors
+// returned by load, and by os.Stat except if the file doesn't exist, are
+// passed to onError, if it's non-nil. The returned function stops watching the
+// file.
+func WatchFile(path string, period time.Duration, load func() error, onError func(error)) (stop func()) {
+	last, _ := os.Stat(path)
+	done := make(chan struct{})
+	go func() {
+		t := time.NewTicker(period)
+		defer t.Stop()
+		for {
+			select {
+			case <-t.C:
+			case <-done:
+				return
+			}
+			fi, err := os.Stat(path)
+			if err != nil {
+				if !os.IsNotExist(err) && onError != nil {
+					onError(err)
+				}
+				continue
+			}
+			if last != nil && fi.ModTime().Equal(last.ModTime()) && fi.Size() == last.Size() {
+				continue
+			}
+			last = fi
+			reload(load, onError)
+		}
+	}()
+	return func() { close(done) }
+}
+
+func reload(load func() error, onError func(error)) {
+	if err := load(); err != nil && onError != nil {
+		onError(err)
+	}
+}
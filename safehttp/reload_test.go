@@ -0,0 +1,131 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package safehttp_test
+
+import (
+	"io/ioutil"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-safeweb/safehttp"
+	"github.com/google/go-safeweb/safehttp/restricted"
+	"github.com/google/safehtml"
+)
+
+func TestReloadable(t *testing.T) {
+	var log []string
+	rl := safehttp.NewReloadable(recordingInterceptor{name: "a", log: &log})
+	mb := safehttp.NewServeMuxConfig(nil)
+	mb.Intercept(rl)
+	mux := mb.Mux()
+	mux.Handle("/", safehttp.MethodGet, safehttp.HandlerFunc(func(w safehttp.ResponseWriter, r *safehttp.IncomingRequest) safehttp.Result {
+		log = append(log, "handler")
+		// The request started with "a": it must be committed by it.
+		rl.Store(recordingInterceptor{name: "b", log: &log})
+		return w.Write(safehtml.HTMLEscaped("hello"))
+	}), recordingConfig{name: "a", value: "x"})
+
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, httptest.NewRequest(safehttp.MethodGet, "http://foo.com/", nil))
+	if got, want := rr.Header().Get("Commit"), "a"; got != want {
+		t.Errorf(`rr.Header().Get("Commit"): got %q want %q`, got, want)
+	}
+	rr = httptest.NewRecorder()
+	mux.ServeHTTP(rr, httptest.NewRequest(safehttp.MethodGet, "http://foo.com/", nil))
+	if got, want := rr.Header().Get("Commit"), "b"; got != want {
+		t.Errorf(`rr.Header().Get("Commit") after Store: got %q want %q`, got, want)
+	}
+
+	want := []string{
+		"before a x", "handler", "commit a",
+		"before b x", "handler", "commit b",
+	}
+	if diff := cmp.Diff(want, log); diff != "" {
+		t.Errorf("log mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestReloadableDisabled(t *testing.T) {
+	_, rr := serveRecorded(t, func(mb *safehttp.ServeMuxConfig, log *[]string) {
+		mb.Intercept(safehttp.NewReloadable(setHeaderInterceptor{name: "Foo", value: "bar"}))
+	}, restricted.DisableInterceptor(setHeaderInterceptor{}, "not needed"))
+
+	if got := rr.Header().Get("Foo"); got != "" {
+		t.Errorf(`rr.Header().Get("Foo"): got %q want ""`, got)
+	}
+}
+
+func TestReloadableStoreOtherType(t *testing.T) {
+	rl := safehttp.NewReloadable(setHeaderInterceptor{name: "Foo", value: "bar"})
+	defer func() {
+		if r := recover(); r == nil {
+			t.Error("rl.Store with another type: expected panic")
+		}
+	}()
+	rl.Store(internalErrorInterceptor{})
+}
+
+func TestWatchFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config")
+	if err := ioutil.WriteFile(path, []byte("a"), 0600); err != nil {
+		t.Fatalf("ioutil.WriteFile: %v", err)
+	}
+	loads := make(chan string, 10)
+	stop := safehttp.WatchFile(path, 5*time.Millisecond, func() error {
+		b, err := ioutil.ReadFile(path)
+		loads <- string(b)
+		return err
+	}, func(err error) { t.Errorf("WatchFile error: %v", err) })
+	defer stop()
+
+	select {
+	case got := <-loads:
+		t.Fatalf("load called before a change, with %q", got)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	if err := ioutil.WriteFile(path, []byte("bb"), 0600); err != nil {
+		t.Fatalf("ioutil.WriteFile: %v", err)
+	}
+	select {
+	case got := <-loads:
+		if want := "bb"; got != want {
+			t.Errorf("loaded: got %q want %q", got, want)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("load wasn't called after the file changed")
+	}
+
+	// A removed file is ignored until it's created again.
+	if err := os.Remove(path); err != nil {
+		t.Fatalf("os.Remove: %v", err)
+	}
+	time.Sleep(20 * time.Millisecond)
+	if err := ioutil.WriteFile(path, []byte("ccc"), 0600); err != nil {
+		t.Fatalf("ioutil.WriteFile: %v", err)
+	}
+	select {
+	case got := <-loads:
+		if want := "ccc"; got != want {
+			t.Errorf("loaded: got %q want %q", got, want)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("load wasn't called after the file was recreated")
+	}
+}
@@ -69,6 +69,9 @@ type TemplateResponse struct {
 	Name     string
 	Data     interface{}
 	FuncMap  map[string]interface{}
+	// Layout is the name of the associated template the response is rendered
+	// in, if any. See ExecuteNamedTemplateWithLayout.
+	Layout string
 }
 
 // ExecuteTemplate creates a TemplateResponse from the provided Template and its
@@ -0,0 +1,201 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package safehttp
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+)
+
+// ErrResponseTooLarge is returned when writing more than the maximum size of
+// a response, see LimitResponseSize.
+var ErrResponseTooLarge = errors.New("the response exceeds the maximum size")
+
+// OversizePolicy is what happens to a response whose body exceeds the
+// maximum size.
+type OversizePolicy int
+
+const (
+	// OversizeError replaces the response with a 500 Internal Server Error.
+	// To do so, the response is held back, in memory, until it's complete or
+	// flushed. If it exceeds the maximum size after being flushed, the
+	// connection is aborted, so that the client doesn't take the truncated
+	// body for a complete one.
+	OversizeError OversizePolicy = iota
+	// OversizeTruncate cuts the body at the maximum size.
+	OversizeTruncate
+)
+
+type responseLimit struct {
+	max    int64
+	policy OversizePolicy
+}
+
+// LimitResponseSize limits the size of the body of the responses to max
+// bytes, before compression. It protects the server and the clients from
+// bugs that produce huge responses, e.g. serializing an unbounded list.
+//
+// The limit applies to the responses written through the Dispatcher and to
+// streamed responses (see ResponseWriter.Stream) and net/http handlers
+// wrapped with RegisteredHandlerFromHTTP, whose writes fail with
+// ErrResponseTooLarge once the limit is reached. It doesn't apply to
+// FileServerResponses, whose body is written by the net/http file server.
+//
+// It can be overridden for a handler with WithMaxResponseSize.
+func (s *ServeMuxConfig) LimitResponseSize(max int64, p OversizePolicy) {
+	s.responseLimit = &responseLimit{max: max, policy: p}
+}
+
+// WithMaxResponseSize returns a configuration that limits the size of the
+// responses of a handler, overriding ServeMuxConfig.LimitResponseSize. It can
+// be passed when registering the handler, like an InterceptorConfig.
+func WithMaxResponseSize(max int64, p OversizePolicy) InterceptorConfig {
+	return responseLimit{max: max, policy: p}
+}
+
+type responseLimitCtxKey struct{}
+
+func withResponseLimit(r *http.Request, l *responseLimit) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), responseLimitCtxKey{}, l))
+}
+
+// newLimitWriter returns a writer enforcing the response size limit of the
+// handler or of the ServeMux, or nil if there's none or it doesn't apply to
+// resp.
+func newLimitWriter(f *flight, rw http.ResponseWriter, resp Response) *limitWriter {
+	switch resp.(type) {
+	case FileServerResponse:
+		// The body is written after the Dispatcher returns.
+		return nil
+	case UpgradeResponse:
+		return nil
+	}
+	l := f.cfg.ResponseLimit
+	if l == nil {
+		var ok bool
+		if l, ok = f.req.Context().Value(responseLimitCtxKey{}).(*responseLimit); !ok {
+			return nil
+		}
+	}
+	return &limitWriter{ResponseWriter: rw, limit: *l}
+}
+
+// limitWriter enforces the response size limit. With OversizeError, it holds
+// back the response until the Dispatcher returns or the response is flushed,
+// so that it can still be replaced if it exceeds the limit.
+type limitWriter struct {
+	http.ResponseWriter
+	limit responseLimit
+
+	n int64
+	// code and buf are the status code and the body held back.
+	code        int
+	buf         bytes.Buffer
+	wroteHeader bool
+	// exceeded is set once the limit is exceeded, replaced if the response
+	// was replaced with an error.
+	exceeded, replaced bool
+}
+
+func (w *limitWriter) WriteHeader(code int) {
+	if w.code != 0 || w.wroteHeader {
+		return
+	}
+	if w.limit.policy == OversizeTruncate {
+		w.writeHeader(code)
+		return
+	}
+	w.code = code
+}
+
+func (w *limitWriter) writeHeader(code int) {
+	w.wroteHeader = true
+	h := w.ResponseWriter.Header()
+	if cl, err := strconv.ParseInt(h.Get("Content-Length"), 10, 64); err == nil && cl > w.limit.max {
+		h.Del("Content-Length")
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+// flush writes the response held back, if any.
+func (w *limitWriter) flush() error {
+	if w.wroteHeader || w.replaced {
+		return nil
+	}
+	code := w.code
+	if code == 0 {
+		code = http.StatusOK
+	}
+	w.writeHeader(code)
+	_, err := w.buf.WriteTo(w.ResponseWriter)
+	return err
+}
+
+func (w *limitWriter) Write(b []byte) (int, error) {
+	if w.replaced {
+		return 0, ErrResponseTooLarge
+	}
+	if w.n+int64(len(b)) <= w.limit.max {
+		w.n += int64(len(b))
+		if w.limit.policy == OversizeTruncate {
+			w.WriteHeader(http.StatusOK)
+		}
+		if !w.wroteHeader {
+			return w.buf.Write(b)
+		}
+		return w.ResponseWriter.Write(b)
+	}
+	w.exceeded = true
+	if w.limit.policy == OversizeTruncate {
+		w.WriteHeader(http.StatusOK)
+		rest := w.limit.max - w.n
+		w.n = w.limit.max
+		if _, err := w.ResponseWriter.Write(b[:rest]); err != nil {
+			return 0, err
+		}
+		return len(b), nil
+	}
+	if !w.wroteHeader {
+		h := w.ResponseWriter.Header()
+		for k := range h {
+			delete(h, k)
+		}
+		w.replaced = true
+		w.buf.Reset()
+		writeTextError(w.ResponseWriter, StatusInternalServerError)
+	}
+	return 0, ErrResponseTooLarge
+}
+
+func (w *limitWriter) Flush() {
+	if w.flush() != nil {
+		return
+	}
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// finish writes the response held back and reports whether the response must
+// be aborted because it was cut after it started being sent.
+func (w *limitWriter) finish() (abort bool, err error) {
+	if err := w.flush(); err != nil {
+		return false, err
+	}
+	return w.exceeded && !w.replaced && w.limit.policy == OversizeError, nil
+}
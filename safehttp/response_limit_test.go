@@ -0,0 +1,181 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package safehttp_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-safeweb/safehttp"
+	"github.com/google/safehtml/template"
+)
+
+// legacyHandler returns a wrapped net/http handler writing n bytes of text.
+func legacyHandler(t *testing.T, n int) safehttp.Handler {
+	return safehttp.RegisteredHandlerFromHTTP(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		for i := 0; i < n; i += 10 {
+			if _, err := w.Write([]byte(strings.Repeat("a", 10))); err != nil {
+				if err != safehttp.ErrResponseTooLarge {
+					t.Errorf("w.Write: got err %v, want %v", err, safehttp.ErrResponseTooLarge)
+				}
+				return
+			}
+		}
+	}))
+}
+
+func TestLimitResponseSize(t *testing.T) {
+	tests := []struct {
+		name       string
+		policy     safehttp.OversizePolicy
+		routeCfgs  []safehttp.InterceptorConfig
+		handler    safehttp.HandlerFunc
+		wantStatus safehttp.StatusCode
+		wantBody   string
+	}{
+		{
+			name: "Under the limit",
+			handler: func(w safehttp.ResponseWriter, r *safehttp.IncomingRequest) safehttp.Result {
+				return safehttp.WriteJSON(w, "ok")
+			},
+			wantStatus: safehttp.StatusOK,
+			wantBody:   ")]}',\n\"ok\"\n",
+		},
+		{
+			name: "Error",
+			handler: func(w safehttp.ResponseWriter, r *safehttp.IncomingRequest) safehttp.Result {
+				return safehttp.WriteJSON(w, strings.Repeat("a", 100))
+			},
+			wantStatus: safehttp.StatusInternalServerError,
+			wantBody:   "Internal Server Error\n",
+		},
+		{
+			name:   "Truncate",
+			policy: safehttp.OversizeTruncate,
+			handler: func(w safehttp.ResponseWriter, r *safehttp.IncomingRequest) safehttp.Result {
+				return safehttp.WriteJSON(w, strings.Repeat("a", 100))
+			},
+			wantStatus: safehttp.StatusOK,
+			wantBody:   ")]}',\n\"aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa",
+		},
+		{
+			name:      "Route override",
+			routeCfgs: []safehttp.InterceptorConfig{safehttp.WithMaxResponseSize(1000, safehttp.OversizeError)},
+			handler: func(w safehttp.ResponseWriter, r *safehttp.IncomingRequest) safehttp.Result {
+				return safehttp.WriteJSON(w, strings.Repeat("a", 100))
+			},
+			wantStatus: safehttp.StatusOK,
+			wantBody:   ")]}',\n\"" + strings.Repeat("a", 100) + "\"\n",
+		},
+		{
+			name: "Template",
+			handler: func(w safehttp.ResponseWriter, r *safehttp.IncomingRequest) safehttp.Result {
+				return safehttp.ExecuteTemplate(w, template.Must(template.New("").Parse(`<p>{{.}}</p>`)), strings.Repeat("a", 100))
+			},
+			wantStatus: safehttp.StatusInternalServerError,
+			wantBody:   "Internal Server Error\n",
+		},
+		{
+			name: "Error status kept",
+			handler: func(w safehttp.ResponseWriter, r *safehttp.IncomingRequest) safehttp.Result {
+				return w.WriteError(safehttp.StatusNotFound)
+			},
+			wantStatus: safehttp.StatusNotFound,
+			wantBody:   "Not Found\n",
+		},
+		{
+			name: "Stream",
+			handler: func(w safehttp.ResponseWriter, r *safehttp.IncomingRequest) safehttp.Result {
+				sw, err := w.Stream("text/plain")
+				if err != nil {
+					t.Fatalf("w.Stream: %v", err)
+				}
+				if _, err := sw.Write([]byte(strings.Repeat("a", 100))); err != safehttp.ErrResponseTooLarge {
+					t.Errorf("sw.Write: got err %v, want %v", err, safehttp.ErrResponseTooLarge)
+				}
+				return safehttp.Result{}
+			},
+			wantStatus: safehttp.StatusInternalServerError,
+			wantBody:   "Internal Server Error\n",
+		},
+		{
+			name: "Legacy handler",
+			handler: func(w safehttp.ResponseWriter, r *safehttp.IncomingRequest) safehttp.Result {
+				return legacyHandler(t, 100).ServeHTTP(w, r)
+			},
+			wantStatus: safehttp.StatusInternalServerError,
+			wantBody:   "Internal Server Error\n",
+		},
+		{
+			name:   "Legacy handler truncated",
+			policy: safehttp.OversizeTruncate,
+			handler: func(w safehttp.ResponseWriter, r *safehttp.IncomingRequest) safehttp.Result {
+				return legacyHandler(t, 100).ServeHTTP(w, r)
+			},
+			wantStatus: safehttp.StatusOK,
+			wantBody:   strings.Repeat("a", 50),
+		},
+		{
+			name: "Legacy handler under the limit",
+			handler: func(w safehttp.ResponseWriter, r *safehttp.IncomingRequest) safehttp.Result {
+				return legacyHandler(t, 10).ServeHTTP(w, r)
+			},
+			wantStatus: safehttp.StatusOK,
+			wantBody:   strings.Repeat("a", 10),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mb := safehttp.NewServeMuxConfig(nil)
+			mb.LimitResponseSize(50, tt.policy)
+			mux := mb.Mux()
+			mux.Handle("/", safehttp.MethodGet, tt.handler, tt.routeCfgs...)
+			rr := httptest.NewRecorder()
+			mux.ServeHTTP(rr, httptest.NewRequest(safehttp.MethodGet, "http://foo.com/", nil))
+
+			if got, want := rr.Code, int(tt.wantStatus); got != want {
+				t.Errorf("rr.Code: got %v want %v", got, want)
+			}
+			if got := rr.Body.String(); got != tt.wantBody {
+				t.Errorf("response body: got %q want %q", got, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestLimitResponseSizeAbort(t *testing.T) {
+	// The first record is flushed before the limit is reached.
+	mb := safehttp.NewServeMuxConfig(nil)
+	mb.LimitResponseSize(10, safehttp.OversizeError)
+	mux := mb.Mux()
+	mux.Handle("/", safehttp.MethodGet, safehttp.HandlerFunc(func(w safehttp.ResponseWriter, r *safehttp.IncomingRequest) safehttp.Result {
+		return w.Write(safehttp.JSONStreamFromSlice([]interface{}{1, strings.Repeat("a", 100)}))
+	}))
+	rr := httptest.NewRecorder()
+
+	defer func() {
+		if r := recover(); r != http.ErrAbortHandler {
+			t.Errorf("got panic %v, want %v", r, http.ErrAbortHandler)
+		}
+		if got, want := rr.Body.String(), "1\n"; got != want {
+			t.Errorf("response body: got %q want %q", got, want)
+		}
+	}()
+	mux.ServeHTTP(rr, httptest.NewRequest(safehttp.MethodGet, "http://foo.com/", nil))
+}
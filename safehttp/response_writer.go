@@ -14,6 +14,8 @@
 
 package safehttp
 
+import "errors"
+
 // ResponseWriter is used to construct an HTTP response. When a Response is
 // passed to the ResponseWriter, it will invoke the Dispatcher with the
 // Response. An attempt to write to the ResponseWriter twice will
@@ -30,8 +32,68 @@ type ResponseWriter interface {
 	//
 	// If the ResponseWriter has already been written to, then this method panics.
 	WriteError(resp ErrorResponse) Result
+
+	// Reset discards the headers and cookies set so far, except for the
+	// claimed headers, and allows a new response to be written. It returns
+	// ErrResponseFlushed if the response was already sent to the client.
+	//
+	// Interceptors can use it in their Commit phase, by asserting the
+	// ResponseHeadersWriter to a ResponseWriter, to replace a response with an
+	// error. The Commit phases will then run again for the new response,
+	// so interceptors must make sure not to replace it again.
+	Reset() error
+
+	// SendContinue sends a 100 Continue interim response if the client sent
+	// the "Expect: 100-continue" header, signaling it to send the request body.
+	// Otherwise, it does nothing.
+	//
+	// The response is also sent automatically when the request body is first
+	// read, so interceptors which don't read the body can reject requests
+	// (e.g. with 413 or 417) before the client sends it. SendContinue returns
+	// an error if a response was already written.
+	SendContinue() error
+
+	// SendEarlyHints sends a 103 Early Hints interim response with the given
+	// Link header values, e.g. "</app.js>; rel=preload; as=script", so that
+	// the client can start fetching the resources while the response is
+	// being produced. Only the Link headers are sent: the other headers set
+	// so far are kept for the final response, which is unaffected.
+	//
+	// It does nothing for HTTP/1.0 requests. SendEarlyHints returns an error
+	// if a response was already written or a value isn't a link.
+	SendEarlyHints(links ...string) error
+
+	// Stream writes the headers of a 200 OK response with the given
+	// Content-Type and returns a StreamWriter for its body, which can be
+	// written incrementally, e.g. for long-running exports or progress output.
+	//
+	// The Commit phase runs when Stream is called, so interceptors can still
+	// set headers before the first byte is sent. If an interceptor replaces
+	// the response, Stream returns ErrStreamReplaced and the handler must stop
+	// writing. Stream returns an error without writing anything if the
+	// Content-Type isn't known to be safe, i.e. it might be rendered as a
+	// document by browsers. Allowed types include text/plain, text/csv,
+	// text/event-stream, application/json and application/x-ndjson.
+	//
+	// If the ResponseWriter has already been written to, then this method panics.
+	Stream(contentType string) (StreamWriter, error)
+
+	// SetTrailer sets a trailer of the response, i.e. a header sent after
+	// the body, e.g. a checksum of a streamed body or the time it took to
+	// produce it. It can be called before or after the response is written,
+	// until the Handler returns. Trailers set before the response is written
+	// are also declared in the Trailer header.
+	//
+	// SetTrailer returns an error for headers that can't be trailers, like
+	// Content-Type or Set-Cookie. Clients may ignore trailers, so they must
+	// not be needed to process the response safely.
+	SetTrailer(name, value string) error
 }
 
+// ErrResponseFlushed is returned by ResponseWriter.Reset if the response was
+// already sent to the client.
+var ErrResponseFlushed = errors.New("the response was already flushed")
+
 // ResponseHeadersWriter is used to alter the HTTP response headers.
 //
 // A ResponseHeadersWriter may not be used after the Handler.ServeHTTP method has returned.
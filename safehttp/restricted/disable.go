@@ -0,0 +1,36 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package restricted
+
+import (
+	"github.com/google/go-safeweb/safehttp"
+	"github.com/google/go-safeweb/safehttp/internal"
+)
+
+var disableInterceptor = internal.DisableInterceptor.(func(safehttp.Interceptor, string) safehttp.InterceptorConfig)
+
+// DisableInterceptor returns a configuration that, passed when registering a
+// handler, disables the installed interceptors of the same type as it for that
+// handler. For example, to serve a public API without XSRF protection:
+//
+//	mux.Handle("/api/", safehttp.MethodPost, apiHandler,
+//		restricted.DisableInterceptor(&xsrf.Interceptor{}, "authenticated with API keys, not cookies"))
+//
+// The reason is mandatory, and DisableInterceptor panics if it is empty.
+// Disabled interceptors are reported by safehttp.ServeMux.Routes, so that
+// security reviews can find every exemption.
+func DisableInterceptor(it safehttp.Interceptor, reason string) safehttp.InterceptorConfig {
+	return disableInterceptor(it, reason)
+}
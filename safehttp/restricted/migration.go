@@ -0,0 +1,32 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package restricted
+
+import (
+	"github.com/google/go-safeweb/safehttp"
+	"github.com/google/go-safeweb/safehttp/internal"
+)
+
+var unsafeAllowActiveContent = internal.UnsafeAllowActiveContent.(func(string) safehttp.UnsafeOption)
+
+// UnsafeAllowActiveContent allows a net/http handler wrapped with
+// safehttp.RegisteredHandlerFromHTTP to write responses with a Content-Type
+// that browsers might render as a document, e.g. text/html or image/svg+xml.
+// These responses aren't checked by the Dispatcher and might contain XSS
+// vulnerabilities. The reason is mandatory, and UnsafeAllowActiveContent
+// panics if it is empty.
+func UnsafeAllowActiveContent(reason string) safehttp.UnsafeOption {
+	return unsafeAllowActiveContent(reason)
+}
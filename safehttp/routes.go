@@ -0,0 +1,91 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package safehttp
+
+import (
+	"sort"
+	"time"
+)
+
+// Route describes a handler registered in a ServeMux.
+type Route struct {
+	// Pattern is the pattern the handler was registered with.
+	Pattern string
+	// Method is the method the handler was registered for. It is empty for
+	// handlers registered with HandlePrefix, which serve all the methods that
+	// weren't registered with Handle.
+	Method string
+	// Interceptors lists the interceptors that run for the route, in order,
+	// with their configuration.
+	Interceptors []RouteInterceptor
+	// Timeout is the timeout set with WithTimeout, or 0.
+	Timeout time.Duration
+	// Disabled lists the interceptors disabled for the route with
+	// restricted.DisableInterceptor, which aren't in Interceptors.
+	Disabled []DisabledInterceptor
+}
+
+// RouteInterceptor is an interceptor installed for a route.
+type RouteInterceptor struct {
+	Interceptor Interceptor
+	// Config is the configuration passed to the interceptor for the route, or
+	// nil if there is none.
+	Config InterceptorConfig
+}
+
+// Routes returns the routes registered in the ServeMux, sorted by pattern and
+// method. It can be used by tools, e.g. to audit the security configuration
+// of an application.
+//
+// The handlers for method not allowed and not found requests are not
+// included.
+func (m *ServeMux) Routes() []Route {
+	var routes []Route
+	for _, rh := range m.handlers {
+		pattern := rh.pattern
+		if rh.wildcard {
+			pattern += "..."
+		}
+		for method, cfg := range rh.methods {
+			routes = append(routes, newRoute(pattern, method, cfg))
+		}
+		if rh.prefix != nil {
+			routes = append(routes, newRoute(rh.pattern, "", *rh.prefix))
+		}
+	}
+	sort.Slice(routes, func(i, j int) bool {
+		if routes[i].Pattern != routes[j].Pattern {
+			return routes[i].Pattern < routes[j].Pattern
+		}
+		return routes[i].Method < routes[j].Method
+	})
+	return routes
+}
+
+func newRoute(pattern, method string, cfg handlerConfig) Route {
+	r := Route{
+		Pattern:  pattern,
+		Method:   method,
+		Timeout:  cfg.Timeout,
+		Disabled: cfg.Disabled,
+	}
+	for _, it := range cfg.Interceptors {
+		r.Interceptors = append(r.Interceptors, RouteInterceptor{
+			Interceptor: it.interceptor,
+			Config:      it.config,
+		})
+	}
+	return r
+}
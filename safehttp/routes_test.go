@@ -0,0 +1,60 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package safehttp_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-safeweb/safehttp"
+)
+
+func TestMuxRoutes(t *testing.T) {
+	var log []string
+	mb := safehttp.NewServeMuxConfig(nil)
+	mb.Intercept(recordingInterceptor{name: "global", log: &log})
+	api := mb.Group("/api")
+	api.Intercept(recordingInterceptor{name: "api", log: &log})
+	api.Handle("/users", safehttp.MethodPost, nil, recordingConfig{name: "api", value: "x"})
+	mux := mb.Mux()
+	mux.Handle("/users", safehttp.MethodGet, nil)
+	mux.Handle("/users", safehttp.MethodDelete, nil, recordingConfig{name: "global", value: "y"})
+	mux.Handle("/static/...", safehttp.MethodGet, nil)
+	mux.HandlePrefix("/files/", nil)
+
+	var got []string
+	for _, r := range mux.Routes() {
+		s := fmt.Sprintf("%s %q", r.Pattern, r.Method)
+		for _, it := range r.Interceptors {
+			s += fmt.Sprintf(" %s", it.Interceptor.(recordingInterceptor).name)
+			if it.Config != nil {
+				s += fmt.Sprintf("(%s)", it.Config.(recordingConfig).value)
+			}
+		}
+		got = append(got, s)
+	}
+
+	want := []string{
+		`/api/users "POST" global api(x)`,
+		`/files/ "" global`,
+		`/static/... "GET" global`,
+		`/users "DELETE" global(y)`,
+		`/users "GET" global`,
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("mux.Routes() mismatch (-want +got):\n%s", diff)
+	}
+}
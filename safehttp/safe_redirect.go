@@ -0,0 +1,91 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package safehttp
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+)
+
+// SafeRedirect redirects the client to target with 303 See Other, if target
+// is a safe redirect target. Otherwise, it writes a 400 Bad Request error.
+//
+// Unlike Redirect, it's meant for targets that come from the request, e.g. the
+// page to return to after logging in, which would otherwise allow open
+// redirects. A target is safe if it's a path on the same origin as the request
+// or an absolute http(s) URL whose host is the one of the request or one of
+// allowedHosts, e.g. "accounts.example.com" or "example.com:8443". Hosts are
+// compared case-insensitively.
+//
+// Browsers are lenient when parsing URLs: backslashes are turned into slashes
+// (so that "/\evil.com" means "//evil.com") and tabs and newlines are removed.
+// SafeRedirect normalizes the target the same way before checking it.
+func SafeRedirect(w ResponseWriter, r *IncomingRequest, target string, allowedHosts []string) Result {
+	location, err := safeRedirectLocation(r, target, allowedHosts)
+	if err != nil {
+		return w.WriteError(StatusBadRequest)
+	}
+	return Redirect(w, r, location, StatusSeeOther)
+}
+
+var errUnsafeRedirect = errors.New("unsafe redirect target")
+
+// safeRedirectLocation returns the normalized target, or an error if it's
+// not a safe redirect target.
+func safeRedirectLocation(r *IncomingRequest, target string, allowedHosts []string) (string, error) {
+	for i := 0; i < len(target); i++ {
+		if c := target[i]; c < 0x20 || c == 0x7f {
+			return "", errUnsafeRedirect
+		}
+	}
+	target = strings.ReplaceAll(target, `\`, "/")
+	if target == "" {
+		return "", errUnsafeRedirect
+	}
+	u, err := url.Parse(target)
+	if err != nil || u.Opaque != "" || u.User != nil {
+		return "", errUnsafeRedirect
+	}
+	if u.Scheme == "" && u.Host == "" {
+		// A path on the same origin. Browsers treat "///evil.com" as
+		// "//evil.com".
+		if strings.HasPrefix(u.Path, "//") {
+			return "", errUnsafeRedirect
+		}
+		return u.String(), nil
+	}
+	switch u.Scheme {
+	case "", "http", "https":
+	default:
+		return "", errUnsafeRedirect
+	}
+	if u.Host == "" {
+		return "", errUnsafeRedirect
+	}
+	if strings.EqualFold(u.Host, r.Host()) {
+		// Don't downgrade the connection.
+		if u.Scheme == "http" && r.TLS != nil {
+			return "", errUnsafeRedirect
+		}
+		return u.String(), nil
+	}
+	for _, h := range allowedHosts {
+		if strings.EqualFold(u.Host, h) {
+			return u.String(), nil
+		}
+	}
+	return "", errUnsafeRedirect
+}
@@ -0,0 +1,76 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package safehttp_test
+
+import (
+	"crypto/tls"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-safeweb/safehttp"
+)
+
+func TestSafeRedirect(t *testing.T) {
+	tests := []struct {
+		name         string
+		target       string
+		tls          bool
+		wantStatus   safehttp.StatusCode
+		wantLocation string
+	}{
+		{name: "Path", target: "/account?tab=1", wantStatus: safehttp.StatusSeeOther, wantLocation: "/account?tab=1"},
+		{name: "Relative path", target: "settings", wantStatus: safehttp.StatusSeeOther, wantLocation: "/login/settings"},
+		{name: "Same host", target: "http://foo.com/account", wantStatus: safehttp.StatusSeeOther, wantLocation: "http://foo.com/account"},
+		{name: "Same host, different case", target: "https://FOO.com/", tls: true, wantStatus: safehttp.StatusSeeOther, wantLocation: "https://FOO.com/"},
+		{name: "Allowed host", target: "https://accounts.example.com/signin", wantStatus: safehttp.StatusSeeOther, wantLocation: "https://accounts.example.com/signin"},
+		{name: "Allowed host and port", target: "//example.com:8443/x", wantStatus: safehttp.StatusSeeOther, wantLocation: "//example.com:8443/x"},
+		{name: "Backslash in path", target: `/\x`, wantStatus: safehttp.StatusBadRequest},
+		{name: "Other host", target: "https://evil.com/", wantStatus: safehttp.StatusBadRequest},
+		{name: "Scheme-relative", target: "//evil.com/", wantStatus: safehttp.StatusBadRequest},
+		{name: "Backslashes", target: `/\evil.com`, wantStatus: safehttp.StatusBadRequest},
+		{name: "Only backslashes", target: `\\evil.com`, wantStatus: safehttp.StatusBadRequest},
+		{name: "Triple slash", target: "///evil.com", wantStatus: safehttp.StatusBadRequest},
+		{name: "Tab", target: "/\t/evil.com", wantStatus: safehttp.StatusBadRequest},
+		{name: "Newline", target: "/\n/evil.com", wantStatus: safehttp.StatusBadRequest},
+		{name: "Userinfo", target: "https://foo.com@evil.com/", wantStatus: safehttp.StatusBadRequest},
+		{name: "JavaScript", target: "javascript:alert(1)", wantStatus: safehttp.StatusBadRequest},
+		{name: "Opaque", target: "http:evil.com", wantStatus: safehttp.StatusBadRequest},
+		{name: "Allowed host as suffix", target: "https://accounts.example.com.evil.com/", wantStatus: safehttp.StatusBadRequest},
+		{name: "Downgrade", target: "http://foo.com/", tls: true, wantStatus: safehttp.StatusBadRequest},
+		{name: "Empty", target: "", wantStatus: safehttp.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mux := safehttp.NewServeMuxConfig(nil).Mux()
+			mux.Handle("/login/", safehttp.MethodGet, safehttp.HandlerFunc(func(w safehttp.ResponseWriter, r *safehttp.IncomingRequest) safehttp.Result {
+				return safehttp.SafeRedirect(w, r, tt.target, []string{"accounts.example.com", "example.com:8443"})
+			}))
+			req := httptest.NewRequest(safehttp.MethodGet, "http://foo.com/login/", nil)
+			if tt.tls {
+				req.TLS = &tls.ConnectionState{}
+			}
+			rr := httptest.NewRecorder()
+			mux.ServeHTTP(rr, req)
+
+			if got, want := rr.Code, int(tt.wantStatus); got != want {
+				t.Errorf("rr.Code: got %v want %v", got, want)
+			}
+			if got := rr.Header().Get("Location"); got != tt.wantLocation {
+				t.Errorf(`rr.Header().Get("Location"): got %q want %q`, got, tt.wantLocation)
+			}
+		})
+	}
+}
@@ -0,0 +1,139 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package safehttptest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+var (
+	fetchSites = []string{"cross-site", "same-site", "same-origin", "none"}
+	fetchModes = []string{"navigate", "no-cors", "cors"}
+	fetchDests = []string{"document", "iframe", "frame", "embed", "object", "image", "script", "style", "font", "worker", "empty"}
+)
+
+// FetchMetadata is a combination of the values of the Sec-Fetch-Site,
+// Sec-Fetch-Mode, Sec-Fetch-Dest and Sec-Fetch-User request headers. Empty
+// values aren't sent.
+type FetchMetadata struct {
+	Site, Mode, Dest, User string
+}
+
+// String returns the non-empty values, separated by spaces, e.g.
+// "cross-site navigate document ?1".
+func (fm FetchMetadata) String() string {
+	var s []string
+	for _, v := range []string{fm.Site, fm.Mode, fm.Dest, fm.User} {
+		if v != "" {
+			s = append(s, v)
+		}
+	}
+	return strings.Join(s, " ")
+}
+
+// Apply sets the headers of the combination on the request.
+func (fm FetchMetadata) Apply(r *http.Request) {
+	for name, v := range map[string]string{
+		"Sec-Fetch-Site": fm.Site,
+		"Sec-Fetch-Mode": fm.Mode,
+		"Sec-Fetch-Dest": fm.Dest,
+		"Sec-Fetch-User": fm.User,
+	} {
+		if v != "" {
+			r.Header.Set(name, v)
+		}
+	}
+}
+
+// FetchMetadataMatrix returns the combinations of the Fetch Metadata headers
+// with the given Sec-Fetch-Site value: every mode with every destination
+// and, for navigations, with and without user activation. If no site is
+// given, the combinations of all the sites are returned.
+func FetchMetadataMatrix(sites ...string) []FetchMetadata {
+	if len(sites) == 0 {
+		sites = fetchSites
+	}
+	var m []FetchMetadata
+	for _, site := range sites {
+		for _, mode := range fetchModes {
+			for _, dest := range fetchDests {
+				m = append(m, FetchMetadata{Site: site, Mode: mode, Dest: dest})
+				if mode == "navigate" {
+					m = append(m, FetchMetadata{Site: site, Mode: mode, Dest: dest, User: "?1"})
+				}
+			}
+		}
+	}
+	return m
+}
+
+// CrossSiteReachable sends a request to h with every cross-site combination
+// of the FetchMetadataMatrix and returns the ones, formatted with
+// FetchMetadata.String, which weren't rejected with 403 Forbidden.
+//
+// The route must be registered for the method, otherwise the 404 Not Found
+// or 405 Method Not Allowed responses make it reachable.
+func CrossSiteReachable(h http.Handler, method, target string) []string {
+	var reachable []string
+	for _, fm := range FetchMetadataMatrix("cross-site") {
+		req := httptest.NewRequest(method, target, nil)
+		fm.Apply(req)
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, req)
+		if rr.Code != http.StatusForbidden {
+			reachable = append(reachable, fm.String())
+		}
+	}
+	return reachable
+}
+
+// CrossSiteRoute is the expected cross-site reachability of a route, see
+// CheckCrossSiteReachability.
+type CrossSiteRoute struct {
+	Method, Target string
+	// Reachable are the cross-site combinations of Fetch Metadata headers,
+	// formatted with FetchMetadata.String, with which the route can be
+	// requested, in any order.
+	Reachable []string
+}
+
+// CheckCrossSiteReachability reports an error for every route which isn't
+// reachable cross-site with exactly the expected combinations of Fetch
+// Metadata headers, e.g. to lock in the isolation posture of an application:
+//
+//	safehttptest.CheckCrossSiteReachability(t, mux, []safehttptest.CrossSiteRoute{
+//		{Method: safehttp.MethodGet, Target: "/", Reachable: []string{
+//			"cross-site navigate document",
+//			"cross-site navigate document ?1",
+//		}},
+//		{Method: safehttp.MethodPost, Target: "/api/delete"},
+//	})
+//
+// See CrossSiteReachable.
+func CheckCrossSiteReachability(t testing.TB, h http.Handler, routes []CrossSiteRoute) {
+	t.Helper()
+	for _, r := range routes {
+		got := CrossSiteReachable(h, r.Method, r.Target)
+		if diff := cmp.Diff(r.Reachable, got, cmpopts.EquateEmpty(), cmpopts.SortSlices(func(a, b string) bool { return a < b })); diff != "" {
+			t.Errorf("%s %s cross-site reachability mismatch (-want +got):\n%s", r.Method, r.Target, diff)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package safehttptest_test
+
+import (
+	"testing"
+
+	"github.com/google/go-safeweb/safehttp"
+	"github.com/google/go-safeweb/safehttp/plugins/fetchmetadata"
+	"github.com/google/go-safeweb/safehttp/safehttptest"
+)
+
+func TestCheckCrossSiteReachability(t *testing.T) {
+	mb := safehttp.NewServeMuxConfig(nil)
+	mb.Intercept(fetchmetadata.ResourceIsolationPolicy())
+	mb.Intercept(fetchmetadata.FramingIsolationPolicy())
+	mb.Intercept(fetchmetadata.UserActivationPolicy())
+	mux := mb.Mux()
+	h := safehttp.HandlerFunc(func(w safehttp.ResponseWriter, r *safehttp.IncomingRequest) safehttp.Result {
+		return w.Write(safehttp.NoContentResponse{})
+	})
+	mux.Handle("/", safehttp.MethodGet, h)
+	mux.Handle("/delete", safehttp.MethodGet, h, fetchmetadata.RequireUserActivation{})
+	mux.Handle("/api", safehttp.MethodPost, h)
+	mux.Handle("/avatar", safehttp.MethodGet, h, fetchmetadata.CrossSite{Modes: []string{"no-cors"}, Dests: []string{"image"}})
+
+	safehttptest.CheckCrossSiteReachability(t, mux, []safehttptest.CrossSiteRoute{
+		{Method: safehttp.MethodGet, Target: "/", Reachable: []string{
+			"cross-site navigate document",
+			"cross-site navigate document ?1",
+		}},
+		{Method: safehttp.MethodGet, Target: "/delete", Reachable: []string{
+			"cross-site navigate document ?1",
+		}},
+		{Method: safehttp.MethodPost, Target: "/api"},
+		{Method: safehttp.MethodGet, Target: "/avatar", Reachable: []string{
+			"cross-site no-cors image",
+			"cross-site navigate document ?1",
+			"cross-site navigate document",
+		}},
+	})
+}
+
+func TestFetchMetadataMatrix(t *testing.T) {
+	seen := map[string]bool{}
+	for _, fm := range safehttptest.FetchMetadataMatrix() {
+		if seen[fm.String()] {
+			t.Errorf("FetchMetadataMatrix(): duplicate %q", fm)
+		}
+		seen[fm.String()] = true
+	}
+	for _, want := range []string{"none navigate document ?1", "same-origin cors empty", "cross-site no-cors script"} {
+		if !seen[want] {
+			t.Errorf("FetchMetadataMatrix(): missing %q", want)
+		}
+	}
+	if got, want := len(safehttptest.FetchMetadataMatrix("cross-site")), len(seen)/4; got != want {
+		t.Errorf(`len(FetchMetadataMatrix("cross-site")) got: %v want: %v`, got, want)
+	}
+}
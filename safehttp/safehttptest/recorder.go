@@ -15,6 +15,7 @@
 package safehttptest
 
 import (
+	"errors"
 	"net/http"
 	"net/http/httptest"
 
@@ -38,6 +39,8 @@ type FakeResponseWriter struct {
 
 	// Response headers.
 	Headers safehttp.Header
+
+	flushed bool
 }
 
 // FakeDispatcher provides a minimal implementation of the Dispatcher to be used for testing Interceptors.
@@ -92,22 +95,86 @@ func (frw *FakeResponseWriter) AddCookie(c *safehttp.Cookie) error {
 
 // Write forwards the response to Dispatcher.Write.
 func (frw *FakeResponseWriter) Write(resp safehttp.Response) safehttp.Result {
+	frw.flushed = true
 	if err := frw.Dispatcher.Write(frw.ResponseWriter, resp); err != nil {
 		panic(err)
 	}
 	return safehttp.Result{}
 }
 
+// Stream forwards a safehttp.StreamResponse to Dispatcher.Write and returns a
+// writer for the ResponseWriter.
+func (frw *FakeResponseWriter) Stream(contentType string) (safehttp.StreamWriter, error) {
+	frw.flushed = true
+	if err := frw.Dispatcher.Write(frw.ResponseWriter, safehttp.StreamResponse{ContentType: contentType}); err != nil {
+		return nil, err
+	}
+	return fakeStreamWriter{frw.ResponseWriter}, nil
+}
+
+type fakeStreamWriter struct {
+	http.ResponseWriter
+}
+
+func (w fakeStreamWriter) Flush() error {
+	if fl, ok := w.ResponseWriter.(http.Flusher); ok {
+		fl.Flush()
+	}
+	return nil
+}
+
+// SetTrailer sets the trailer in the ResponseWriter.
+func (frw *FakeResponseWriter) SetTrailer(name, value string) error {
+	frw.ResponseWriter.Header().Set(http.TrailerPrefix+name, value)
+	return nil
+}
+
 // NoContent writes just the NoContent status code.
 func (frw *FakeResponseWriter) NoContent() safehttp.Result {
+	frw.flushed = true
 	frw.ResponseWriter.WriteHeader(int(safehttp.StatusNoContent))
 	return safehttp.Result{}
 }
 
 // WriteError forwards the error response to Dispatcher.WriteError.
 func (frw *FakeResponseWriter) WriteError(resp safehttp.ErrorResponse) safehttp.Result {
+	frw.flushed = true
 	if err := frw.Dispatcher.Error(frw.ResponseWriter, resp); err != nil {
 		panic(err)
 	}
 	return safehttp.Result{}
 }
+
+// Reset deletes the headers which weren't claimed and the cookies. It returns
+// safehttp.ErrResponseFlushed if a response was already written.
+func (frw *FakeResponseWriter) Reset() error {
+	if frw.flushed {
+		return safehttp.ErrResponseFlushed
+	}
+	h := frw.ResponseWriter.Header()
+	for name := range h {
+		if name == "Set-Cookie" || !frw.Headers.IsClaimed(name) {
+			delete(h, name)
+		}
+	}
+	frw.Cookies = nil
+	return nil
+}
+
+// SendEarlyHints does nothing. It returns an error if a response was already
+// written.
+func (frw *FakeResponseWriter) SendEarlyHints(links ...string) error {
+	if frw.flushed {
+		return errors.New("SendEarlyHints called after the response was written")
+	}
+	return nil
+}
+
+// SendContinue does nothing. It returns an error if a response was already
+// written.
+func (frw *FakeResponseWriter) SendContinue() error {
+	if frw.flushed {
+		return errors.New("SendContinue called after the response was written")
+	}
+	return nil
+}
@@ -18,8 +18,10 @@ import (
 	"context"
 	"crypto/tls"
 	"errors"
+	"io"
 	"net"
 	"net/http"
+	"strconv"
 	"time"
 )
 
@@ -36,13 +38,16 @@ type Server struct {
 	// Mux is the ServeMux to use for the current server. A nil Mux is invalid.
 	Mux *ServeMux
 
-	// TODO(empijei): potentially consider exposing ReadHeaderTimeout for
-	// fine-grained handling (e.g. websocket endpoints).
-
 	// ReadTimeout is the maximum duration for reading the entire
 	// request, including the body.
 	ReadTimeout time.Duration
 
+	// ReadHeaderTimeout is the maximum duration for reading the request
+	// headers. If zero, ReadTimeout is used. A short ReadHeaderTimeout
+	// protects against slowloris attacks while allowing a longer ReadTimeout,
+	// e.g. for uploads.
+	ReadHeaderTimeout time.Duration
+
 	// WriteTimeout is the maximum duration before timing out
 	// writes of the response. It is reset whenever a new
 	// request's header is read.
@@ -61,12 +66,21 @@ type Server struct {
 	// TLSConfig optionally provides a TLS configuration for use
 	// by ServeTLS and ListenAndServeTLS. Note that this value is
 	// cloned on serving, so it's not possible to modify the
-	// configuration with methods like tls.Config.SetSessionTicketKeys.
+	// configuration with methods like tls.Config.SetSessionTicketKeys: use
+	// SessionTicketKeyRotation instead. Use SetTLSConfig to validate the
+	// configuration, e.g. one of the TLSModern or TLSIntermediate presets.
 	//
 	// When the server is started the cloned configuration will be changed
-	// to set the minimum TLS version to 1.2 and to prefer Server Ciphers.
+	// to set the minimum TLS version to at least 1.2 and to prefer Server
+	// Ciphers.
 	TLSConfig *tls.Config
 
+	// SessionTicketKeyRotation, if non-zero, makes the server generate a new
+	// session ticket key with this period. The previous key is still accepted
+	// for another period, so that clients can resume their sessions. It only
+	// applies if TLSConfig is set.
+	SessionTicketKeyRotation time.Duration
+
 	// OnShutdown is a slice of functions to call on Shutdown.
 	// This can be used to gracefully shutdown connections that have undergone
 	// ALPN protocol upgrade or that have been hijacked.
@@ -74,11 +88,92 @@ type Server struct {
 	// should not wait for shutdown to complete.
 	OnShutdown []func()
 
+	// OnStart is a slice of functions to call when the server is started,
+	// before it listens. If one of them fails, the server isn't started and
+	// the error is returned, e.g. by ListenAndServe.
+	OnStart []func() error
+
+	// OnReady is a slice of functions to call once the server listens and is
+	// about to accept connections. They should not block.
+	OnReady []func()
+
+	// ShutdownHooks are called in order by Shutdown, after the active handlers
+	// returned or the context passed to Shutdown is done, e.g. to flush the
+	// sessions or drain a report collector. They are called with the context
+	// passed to Shutdown, even if it's done, and should return quickly in that
+	// case.
+	ShutdownHooks []func(context.Context) error
+
+	// DrainRetryAfter, if non-zero, makes the server answer the requests
+	// received after Shutdown was called (e.g. on HTTP/2 connections which
+	// didn't see the GOAWAY frame yet) with 503 Service Unavailable and a
+	// Retry-After header with this duration, instead of handling them.
+	// Otherwise, these requests are handled normally.
+	DrainRetryAfter time.Duration
+
+	// MaxConnections, if positive, is the maximum number of concurrent
+	// connections. MaxConnectionsPerIP, if positive, is the maximum number of
+	// concurrent TCP connections from the same client IP. The connections
+	// over these limits are accepted, but their requests are answered with
+	// 503 Service Unavailable by the Dispatcher of the Mux, without running
+	// the interceptors, and the connections are then closed.
+	MaxConnections      int
+	MaxConnectionsPerIP int
+
+	// MinTransferRate, if positive, is the minimum rate in bytes per second
+	// at which clients must send request bodies, once TransferRateGrace
+	// (5 seconds if zero) elapsed since the handler started. Reads of slower
+	// bodies fail with ErrBodyTooSlow and, if the handler then returns
+	// without writing a response, 408 Request Timeout is written.
+	MinTransferRate   int64
+	TransferRateGrace time.Duration
+
+	// Budget optionally limits the number of requests handled concurrently
+	// and the size of their bodies. See RequestBudget.
+	Budget *RequestBudget
+
+	// ProxyProtocol optionally enables the PROXY protocol on the listeners of
+	// the server, for servers behind TCP load balancers. See
+	// ProxyProtocolConfig.
+	ProxyProtocol *ProxyProtocolConfig
+
+	// HTTP2 optionally configures HTTP/2, e.g. to enable it over cleartext
+	// connections. If nil, HTTP/2 is enabled over TLS with the defaults of
+	// the net/http package.
+	HTTP2 *HTTP2Config
+
+	// HTTP3 optionally configures an experimental HTTP/3 server, which is
+	// started by ListenAndServeTLS and ServeTLS and stopped by Shutdown and
+	// Close. While it runs, the responses over TLS advertise it with the
+	// Alt-Svc header, on the port of Addr.
+	HTTP3 HTTP3Server
+
 	// DisableKeepAlives controls whether HTTP keep-alives should be disabled.
 	DisableKeepAlives bool
 
+	// Clock, if non-nil, is the time source of the TLS connections, unless
+	// TLSConfig sets one.
+	Clock Clock
+
+	// Rand, if non-nil, is the source of the session ticket keys and the
+	// randomness of the TLS connections, unless TLSConfig sets one. If nil,
+	// SystemRand is used. It must be cryptographically secure.
+	Rand io.Reader
+
 	srv     *http.Server
 	started bool
+	// state is the ServerState, accessed atomically.
+	state int32
+	// stopRotation, if non-nil, stops the rotation of the session ticket keys.
+	stopRotation chan struct{}
+	http3        *http3State
+}
+
+func (s *Server) rand() io.Reader {
+	if s.Rand == nil {
+		return SystemRand()
+	}
+	return s.Rand
 }
 
 func (s *Server) buildStd() error {
@@ -95,7 +190,7 @@ func (s *Server) buildStd() error {
 
 	srv := &http.Server{
 		Addr:           s.Addr,
-		Handler:        s.Mux,
+		Handler:        s.limitHandler(s.budgetHandler(s.drainHandler())),
 		ReadTimeout:    5 * time.Second,
 		WriteTimeout:   5 * time.Second,
 		IdleTimeout:    120 * time.Second,
@@ -113,11 +208,49 @@ func (s *Server) buildStd() error {
 	if s.MaxHeaderBytes != 0 {
 		srv.MaxHeaderBytes = s.MaxHeaderBytes
 	}
+	if s.ReadHeaderTimeout != 0 {
+		srv.ReadHeaderTimeout = s.ReadHeaderTimeout
+	}
+	if s.MaxConnections > 0 || s.MaxConnectionsPerIP > 0 {
+		l := newConnLimiter(s.MaxConnections, s.MaxConnectionsPerIP)
+		srv.ConnContext = l.connContext
+		srv.ConnState = l.connState
+	}
 	if s.TLSConfig != nil {
 		cfg := s.TLSConfig.Clone()
-		cfg.MinVersion = tls.VersionTLS12
+		if cfg.MinVersion < tls.VersionTLS12 {
+			cfg.MinVersion = tls.VersionTLS12
+		}
 		cfg.PreferServerCipherSuites = true
+		if cfg.Rand == nil && s.Rand != nil {
+			cfg.Rand = s.Rand
+		}
+		if cfg.Time == nil && s.Clock != nil {
+			cfg.Time = s.Clock.Now
+		}
 		srv.TLSConfig = cfg
+		if s.SessionTicketKeyRotation > 0 {
+			stop := make(chan struct{})
+			if err := rotateTicketKeys(cfg, s.SessionTicketKeyRotation, s.rand(), stop); err != nil {
+				return err
+			}
+			s.stopRotation = stop
+		}
+	}
+	if s.HTTP3 != nil {
+		st, err := newHTTP3State(s.Addr)
+		if err != nil {
+			s.stopTicketKeyRotation()
+			return err
+		}
+		s.http3 = st
+		srv.Handler = st.advertise(srv.Handler)
+	}
+	if s.HTTP2 != nil {
+		if err := configureHTTP2(srv, s.HTTP2); err != nil {
+			s.stopTicketKeyRotation()
+			return err
+		}
 	}
 	for _, f := range s.OnShutdown {
 		srv.RegisterOnShutdown(f)
@@ -133,54 +266,155 @@ func (s *Server) buildStd() error {
 func (s *Server) Clone() *Server {
 	cln := *s
 	cln.started = false
+	cln.state = 0
+	cln.stopRotation = nil
+	cln.http3 = nil
 	cln.TLSConfig = s.TLSConfig.Clone()
+	if s.HTTP2 != nil {
+		h2 := *s.HTTP2
+		cln.HTTP2 = &h2
+	}
+	if s.Budget != nil {
+		b := *s.Budget
+		cln.Budget = &b
+	}
+	if s.ProxyProtocol != nil {
+		pp := *s.ProxyProtocol
+		cln.ProxyProtocol = &pp
+	}
 	cln.srv = nil
 	return &cln
 }
 
 // ListenAndServe is a wrapper for https://golang.org/pkg/net/http/#Server.ListenAndServe
 func (s *Server) ListenAndServe() error {
-	if err := s.buildStd(); err != nil {
+	if err := s.start(); err != nil {
 		return err
 	}
-	s.started = true
-	return s.srv.ListenAndServe()
+	l, err := s.listen(":http")
+	if err != nil {
+		s.setState(ServerClosed)
+		return err
+	}
+	s.ready()
+	return s.srv.Serve(l)
 }
 
 // ListenAndServeTLS is a wrapper for https://golang.org/pkg/net/http/#Server.ListenAndServeTLS
 func (s *Server) ListenAndServeTLS(certFile, keyFile string) error {
-	if err := s.buildStd(); err != nil {
+	if err := s.start(); err != nil {
 		return err
 	}
-	s.started = true
-	return s.srv.ListenAndServeTLS(certFile, keyFile)
+	l, err := s.listen(":https")
+	if err != nil {
+		s.setState(ServerClosed)
+		return err
+	}
+	if s.HTTP3 != nil {
+		s.http3.start(s.HTTP3)
+	}
+	s.ready()
+	return s.srv.ServeTLS(l, certFile, keyFile)
 }
 
 // Serve is a wrapper for https://golang.org/pkg/net/http/#Server.Serve
 func (s *Server) Serve(l net.Listener) error {
-	if err := s.buildStd(); err != nil {
+	if err := s.start(); err != nil {
 		return err
 	}
-	s.started = true
-	return s.srv.Serve(l)
+	s.ready()
+	return s.srv.Serve(s.wrapListener(l))
 }
 
 // ServeTLS is a wrapper for https://golang.org/pkg/net/http/#Server.ServeTLS
 func (s *Server) ServeTLS(l net.Listener, certFile, keyFile string) error {
-	if err := s.buildStd(); err != nil {
+	if err := s.start(); err != nil {
 		return err
 	}
-	s.started = true
-	return s.srv.ServeTLS(l, certFile, keyFile)
+	if s.HTTP3 != nil {
+		s.http3.start(s.HTTP3)
+	}
+	s.ready()
+	return s.srv.ServeTLS(s.wrapListener(l), certFile, keyFile)
 }
 
-// Shutdown is a wrapper for https://golang.org/pkg/net/http/#Server.Shutdown
+// listen listens on the TCP address of the server, or on defaultAddr, and
+// wraps the listener.
+func (s *Server) listen(defaultAddr string) (net.Listener, error) {
+	addr := s.Addr
+	if addr == "" {
+		addr = defaultAddr
+	}
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	return s.wrapListener(l), nil
+}
+
+// wrapListener wraps the listener to read the PROXY headers, if enabled.
+func (s *Server) wrapListener(l net.Listener) net.Listener {
+	if s.ProxyProtocol == nil {
+		return l
+	}
+	cfg := *s.ProxyProtocol
+	if cfg.MaxHandshakes <= 0 && s.MaxConnections > 0 {
+		cfg.MaxHandshakes = s.MaxConnections
+	}
+	return ProxyProtocolListener(l, cfg)
+}
+
+// Shutdown gracefully shuts down the server, like
+// https://golang.org/pkg/net/http/#Server.Shutdown: it stops accepting
+// connections and waits for the active handlers to return, or for ctx to be
+// done. Keep-alives are disabled, so the responses to the requests still in
+// flight are sent with "Connection: close". Then the ShutdownHooks are
+// called.
+//
+// Shutdown returns the error of the first failed step, if any: the context's
+// error if the handlers didn't return in time, or the error of a hook.
 func (s *Server) Shutdown(ctx context.Context) error {
 	if !s.started {
 		return errors.New("shutting down unstarted server")
 	}
+	s.setState(ServerDraining)
 	s.srv.SetKeepAlivesEnabled(false)
-	return s.srv.Shutdown(ctx)
+	err := s.srv.Shutdown(ctx)
+	s.stopTicketKeyRotation()
+	if s.HTTP3 != nil {
+		if closeErr := s.HTTP3.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	for _, f := range s.ShutdownHooks {
+		if hookErr := f(ctx); err == nil {
+			err = hookErr
+		}
+	}
+	s.setState(ServerClosed)
+	return err
+}
+
+// Draining reports whether Shutdown was called. It can be used by readiness
+// checks, so that load balancers stop sending traffic to the server.
+func (s *Server) Draining() bool {
+	return s.State() >= ServerDraining
+}
+
+func (s *Server) drainHandler() http.Handler {
+	if s.DrainRetryAfter == 0 {
+		return s.Mux
+	}
+	retryAfter := strconv.Itoa(int((s.DrainRetryAfter + time.Second - 1) / time.Second))
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !s.Draining() {
+			s.Mux.ServeHTTP(w, r)
+			return
+		}
+		w.Header().Set("Connection", "close")
+		w.Header().Set("Retry-After", retryAfter)
+		http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+	})
 }
 
 // Close is a wrapper for https://golang.org/pkg/net/http/#Server.Close
@@ -188,5 +422,17 @@ func (s *Server) Close() error {
 	if !s.started {
 		return errors.New("closing unstarted server")
 	}
+	s.stopTicketKeyRotation()
+	if s.HTTP3 != nil {
+		s.HTTP3.Close()
+	}
+	s.setState(ServerClosed)
 	return s.srv.Close()
 }
+
+func (s *Server) stopTicketKeyRotation() {
+	if s.stopRotation != nil {
+		close(s.stopRotation)
+		s.stopRotation = nil
+	}
+}
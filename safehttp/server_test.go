@@ -17,7 +17,11 @@ package safehttp
 import (
 	"bufio"
 	"bytes"
+	"context"
+	"errors"
+	"net"
 	"net/http"
+	"net/http/httptest"
 	"net/http/httputil"
 	"testing"
 	"time"
@@ -73,3 +77,141 @@ func TestServer(t *testing.T) {
 		t.Errorf("Builder did not set WriteTimeout: got %v want %v", s.srv.WriteTimeout, 5*time.Second)
 	}
 }
+
+func TestServerShutdown(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	mux := NewServeMuxConfig(nil).Mux()
+	mux.Handle("/", "GET", HandlerFunc(func(w ResponseWriter, r *IncomingRequest) Result {
+		close(started)
+		<-release
+		return w.Write(safehtml.HTMLEscaped("done"))
+	}))
+	var hooks []string
+	s := Server{
+		Mux: mux,
+		ShutdownHooks: []func(context.Context) error{
+			func(context.Context) error {
+				hooks = append(hooks, "sessions")
+				return nil
+			},
+			func(context.Context) error {
+				hooks = append(hooks, "collector")
+				return errors.New("collector failed")
+			},
+		},
+	}
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("net.Listen: %v", err)
+	}
+	go s.Serve(l)
+
+	type result struct {
+		resp *http.Response
+		err  error
+	}
+	respc := make(chan result, 1)
+	go func() {
+		resp, err := http.Get("http://" + l.Addr().String() + "/")
+		respc <- result{resp, err}
+	}()
+	<-started
+
+	shutdownErr := make(chan error, 1)
+	go func() { shutdownErr <- s.Shutdown(context.Background()) }()
+	for !s.Draining() {
+		time.Sleep(time.Millisecond)
+	}
+	select {
+	case err := <-shutdownErr:
+		t.Fatalf("Shutdown returned %v before the handler returned", err)
+	case <-time.After(10 * time.Millisecond):
+	}
+	close(release)
+
+	res := <-respc
+	if res.err != nil {
+		t.Fatalf("http.Get: %v", res.err)
+	}
+	res.resp.Body.Close()
+	if !res.resp.Close {
+		t.Error("resp.Close: got false, want the connection to be closed")
+	}
+	if err := <-shutdownErr; err == nil || err.Error() != "collector failed" {
+		t.Errorf("Shutdown: got %v, want the hook error", err)
+	}
+	if got, want := len(hooks), 2; got != want || hooks[0] != "sessions" {
+		t.Errorf("hooks: got %v, want [sessions collector]", hooks)
+	}
+}
+
+func TestServerShutdownDeadline(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	defer close(release)
+	mux := NewServeMuxConfig(nil).Mux()
+	mux.Handle("/", "GET", HandlerFunc(func(w ResponseWriter, r *IncomingRequest) Result {
+		close(started)
+		<-release
+		return w.Write(safehtml.HTMLEscaped("done"))
+	}))
+	hookCalled := false
+	s := Server{
+		Mux: mux,
+		ShutdownHooks: []func(context.Context) error{
+			func(context.Context) error {
+				hookCalled = true
+				return nil
+			},
+		},
+	}
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("net.Listen: %v", err)
+	}
+	go s.Serve(l)
+	defer s.Close()
+	go func() {
+		if resp, err := http.Get("http://" + l.Addr().String() + "/"); err == nil {
+			resp.Body.Close()
+		}
+	}()
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := s.Shutdown(ctx); err != context.DeadlineExceeded {
+		t.Errorf("Shutdown: got %v want %v", err, context.DeadlineExceeded)
+	}
+	if !hookCalled {
+		t.Error("hook not called after the deadline")
+	}
+}
+
+func TestServerDrainRetryAfter(t *testing.T) {
+	mux := NewServeMuxConfig(nil).Mux()
+	mux.Handle("/", "GET", HandlerFunc(func(w ResponseWriter, r *IncomingRequest) Result {
+		return w.Write(safehtml.HTMLEscaped("response"))
+	}))
+	s := Server{Mux: mux, DrainRetryAfter: 1500 * time.Millisecond}
+	if err := s.buildStd(); err != nil {
+		t.Fatalf("buildStd: %v", err)
+	}
+
+	rr := httptest.NewRecorder()
+	s.srv.Handler.ServeHTTP(rr, httptest.NewRequest("GET", "http://foo.com/", nil))
+	if got, want := rr.Code, http.StatusOK; got != want {
+		t.Errorf("before Shutdown: rr.Code got %v want %v", got, want)
+	}
+
+	s.setState(ServerDraining)
+	rr = httptest.NewRecorder()
+	s.srv.Handler.ServeHTTP(rr, httptest.NewRequest("GET", "http://foo.com/", nil))
+	if got, want := rr.Code, http.StatusServiceUnavailable; got != want {
+		t.Errorf("while draining: rr.Code got %v want %v", got, want)
+	}
+	if got, want := rr.Header().Get("Retry-After"), "2"; got != want {
+		t.Errorf(`while draining: rr.Header().Get("Retry-After") got %q want %q`, got, want)
+	}
+}
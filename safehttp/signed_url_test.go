@@ -0,0 +1,166 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package safehttp_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-safeweb/safehttp"
+)
+
+var urlKey = []byte("a super secret key of 32 bytes!!")
+
+func mustParseURL(t *testing.T, rawurl string) *safehttp.URL {
+	t.Helper()
+	u, err := safehttp.ParseURL(rawurl)
+	if err != nil {
+		t.Fatalf("safehttp.ParseURL(%q) got err: %v", rawurl, err)
+	}
+	return u
+}
+
+func TestSignURL(t *testing.T) {
+	u := mustParseURL(t, "https://foo.com/download/report.pdf?user=alice")
+	signed := safehttp.SignURL(u, urlKey, time.Hour)
+
+	q, err := signed.Query()
+	if err != nil {
+		t.Fatalf("signed.Query() got err: %v", err)
+	}
+	if got, want := q.String("user", ""), "alice"; got != want {
+		t.Errorf(`q.String("user") got %q, want %q`, got, want)
+	}
+	if q.String(safehttp.SignedURLSignatureParam, "") == "" {
+		t.Errorf("signed URL %q has no signature", signed)
+	}
+	if got, want := signed.Path(), u.Path(); got != want {
+		t.Errorf("signed.Path() got %q, want %q", got, want)
+	}
+	// The original URL is not modified.
+	if got, want := u.String(), "https://foo.com/download/report.pdf?user=alice"; got != want {
+		t.Errorf("u.String() got %q, want %q", got, want)
+	}
+}
+
+func TestVerifyURL(t *testing.T) {
+	signed := safehttp.SignURL(mustParseURL(t, "https://foo.com/download/report.pdf?user=alice"), urlKey, time.Hour).String()
+	now := time.Now()
+
+	tests := []struct {
+		name    string
+		url     string
+		key     []byte
+		now     time.Time
+		wantErr error
+	}{
+		{
+			name: "Valid",
+			url:  signed,
+			key:  urlKey,
+			now:  now,
+		},
+		{
+			name: "Valid, without host",
+			url:  strings.TrimPrefix(signed, "https://foo.com"),
+			key:  urlKey,
+			now:  now,
+		},
+		{
+			name:    "Expired",
+			url:     signed,
+			key:     urlKey,
+			now:     now.Add(2 * time.Hour),
+			wantErr: safehttp.ErrURLExpired,
+		},
+		{
+			name:    "Tampered signature",
+			url:     strings.Replace(signed, "signature=", "signature=A", 1),
+			key:     urlKey,
+			now:     now,
+			wantErr: safehttp.ErrInvalidURLSignature,
+		},
+		{
+			name:    "Tampered query",
+			url:     strings.Replace(signed, "user=alice", "user=bob", 1),
+			key:     urlKey,
+			now:     now,
+			wantErr: safehttp.ErrInvalidURLSignature,
+		},
+		{
+			name:    "Tampered expiry",
+			url:     strings.Replace(signed, "expires=", "expires=9", 1),
+			key:     urlKey,
+			now:     now,
+			wantErr: safehttp.ErrInvalidURLSignature,
+		},
+		{
+			name:    "Tampered path",
+			url:     strings.Replace(signed, "report.pdf", "secret.pdf", 1),
+			key:     urlKey,
+			now:     now,
+			wantErr: safehttp.ErrInvalidURLSignature,
+		},
+		{
+			name:    "Wrong key",
+			url:     signed,
+			key:     []byte("another secret key of 32 bytes!!"),
+			now:     now,
+			wantErr: safehttp.ErrInvalidURLSignature,
+		},
+		{
+			name:    "Not signed",
+			url:     "https://foo.com/download/report.pdf?user=alice",
+			key:     urlKey,
+			now:     now,
+			wantErr: safehttp.ErrInvalidURLSignature,
+		},
+		{
+			name:    "Empty key",
+			url:     signed,
+			key:     nil,
+			now:     now,
+			wantErr: safehttp.ErrShortURLKey,
+		},
+		{
+			name:    "Short key",
+			url:     signed,
+			key:     urlKey[:safehttp.MinURLKeyLength-1],
+			now:     now,
+			wantErr: safehttp.ErrShortURLKey,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := safehttp.VerifyURL(mustParseURL(t, tt.url), tt.key, tt.now); err != tt.wantErr {
+				t.Errorf("safehttp.VerifyURL(%q) got err: %v, want %v", tt.url, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSignURLShortKeyPanics(t *testing.T) {
+	for _, key := range [][]byte{nil, []byte(""), urlKey[:safehttp.MinURLKeyLength-1]} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("safehttp.SignURL with a %d bytes key: expected panic", len(key))
+				}
+			}()
+			safehttp.SignURL(mustParseURL(t, "https://foo.com/download/report.pdf"), key, time.Hour)
+		}()
+	}
+}
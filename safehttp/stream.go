@@ -0,0 +1,90 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package safehttp
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// StreamResponse is the response written by ResponseWriter.Stream. Its body
+// is written incrementally by the handler.
+type StreamResponse struct {
+	// ContentType is the Content-Type of the response. Only types which
+	// browsers don't render as a document, e.g. text/plain, text/csv or
+	// text/event-stream, can be streamed.
+	ContentType string
+}
+
+// StreamWriter writes the body of a streamed response.
+type StreamWriter interface {
+	io.Writer
+	// Flush sends the data written so far to the client, if the underlying
+	// connection supports it. Otherwise, it does nothing.
+	Flush() error
+}
+
+// ErrStreamReplaced is returned by ResponseWriter.Stream if an interceptor
+// replaced the response in the Commit phase, e.g. with an error.
+var ErrStreamReplaced = errors.New("the streamed response was replaced in the Commit phase")
+
+// Stream implements ResponseWriter.
+func (f *flight) Stream(contentType string) (StreamWriter, error) {
+	if f.written {
+		panic("ResponseWriter was already written to")
+	}
+	if !isPassiveContent(contentType) {
+		return nil, fmt.Errorf("responses with Content-Type %q cannot be streamed", contentType)
+	}
+	if f.timedOut() {
+		f.WriteError(StatusGatewayTimeout)
+		return nil, ErrStreamReplaced
+	}
+	resp := StreamResponse{ContentType: contentType}
+	f.written = true
+	f.commitPhase(resp)
+	if f.dispatched {
+		return nil, ErrStreamReplaced
+	}
+	f.written = true
+	f.trace.written(f.header, resp)
+
+	f.dispatched = true
+	var rw http.ResponseWriter = f.rw
+	if lw := newLimitWriter(f, f.rw, resp); lw != nil {
+		rw = lw
+	}
+	if err := f.cfg.Dispatcher.Write(rw, resp); err != nil {
+		panic(err)
+	}
+	return streamWriter{rw}, nil
+}
+
+type streamWriter struct {
+	rw http.ResponseWriter
+}
+
+func (w streamWriter) Write(b []byte) (int, error) {
+	return w.rw.Write(b)
+}
+
+func (w streamWriter) Flush() error {
+	if fl, ok := w.rw.(http.Flusher); ok {
+		fl.Flush()
+	}
+	return nil
+}
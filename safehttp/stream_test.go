@@ -0,0 +1,107 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package safehttp_test
+
+import (
+	"fmt"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-safeweb/safehttp"
+)
+
+func TestStream(t *testing.T) {
+	var log []string
+	mb := safehttp.NewServeMuxConfig(nil)
+	mb.Intercept(recordingInterceptor{name: "a", log: &log})
+	mux := mb.Mux()
+	mux.Handle("/export", safehttp.MethodGet, safehttp.HandlerFunc(func(w safehttp.ResponseWriter, r *safehttp.IncomingRequest) safehttp.Result {
+		sw, err := w.Stream("text/csv; charset=utf-8")
+		if err != nil {
+			t.Fatalf("w.Stream: %v", err)
+		}
+		log = append(log, "streaming")
+		for i := 0; i < 3; i++ {
+			fmt.Fprintf(sw, "row %d\n", i)
+			if err := sw.Flush(); err != nil {
+				t.Errorf("sw.Flush: %v", err)
+			}
+		}
+		return safehttp.Result{}
+	}))
+
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, httptest.NewRequest(safehttp.MethodGet, "http://foo.com/export", nil))
+
+	if diff := cmp.Diff([]string{"before a", "commit a", "streaming"}, log); diff != "" {
+		t.Errorf("log mismatch (-want +got):\n%s", diff)
+	}
+	if got, want := rr.Code, int(safehttp.StatusOK); got != want {
+		t.Errorf("rr.Code: got %v want %v", got, want)
+	}
+	wantHeaders := map[string][]string{
+		"Commit":       {"a"},
+		"Content-Type": {"text/csv; charset=utf-8"},
+	}
+	if diff := cmp.Diff(wantHeaders, map[string][]string(rr.Header())); diff != "" {
+		t.Errorf("rr.Header() mismatch (-want +got):\n%s", diff)
+	}
+	if got, want := rr.Body.String(), "row 0\nrow 1\nrow 2\n"; got != want {
+		t.Errorf("rr.Body: got %q want %q", got, want)
+	}
+	if !rr.Flushed {
+		t.Error("rr.Flushed: got false want true")
+	}
+}
+
+func TestStreamActiveContent(t *testing.T) {
+	for _, ct := range []string{"text/html; charset=utf-8", "text/javascript", "text/xsl", "multipart/x-mixed-replace; boundary=x", ""} {
+		t.Run(ct, func(t *testing.T) {
+			mux := safehttp.NewServeMuxConfig(nil).Mux()
+			mux.Handle("/", safehttp.MethodGet, safehttp.HandlerFunc(func(w safehttp.ResponseWriter, r *safehttp.IncomingRequest) safehttp.Result {
+				if _, err := w.Stream(ct); err == nil {
+					t.Errorf("w.Stream(%q) got nil err, want error", ct)
+				}
+				return w.WriteError(safehttp.StatusNotAcceptable)
+			}))
+
+			rr := httptest.NewRecorder()
+			mux.ServeHTTP(rr, httptest.NewRequest(safehttp.MethodGet, "http://foo.com/", nil))
+			if got, want := rr.Code, int(safehttp.StatusNotAcceptable); got != want {
+				t.Errorf("rr.Code: got %v want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestStreamReplaced(t *testing.T) {
+	var commits int
+	mb := safehttp.NewServeMuxConfig(nil)
+	mb.Intercept(replacingInterceptor{commits: &commits})
+	mux := mb.Mux()
+	mux.Handle("/", safehttp.MethodGet, safehttp.HandlerFunc(func(w safehttp.ResponseWriter, r *safehttp.IncomingRequest) safehttp.Result {
+		if _, err := w.Stream("text/plain; charset=utf-8"); err != safehttp.ErrStreamReplaced {
+			t.Errorf("w.Stream() got err %v, want %v", err, safehttp.ErrStreamReplaced)
+		}
+		return safehttp.Result{}
+	}))
+
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, httptest.NewRequest(safehttp.MethodGet, "http://foo.com/", nil))
+	if got, want := rr.Code, int(safehttp.StatusInternalServerError); got != want {
+		t.Errorf("rr.Code: got %v want %v", got, want)
+	}
+}
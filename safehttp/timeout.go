@@ -0,0 +1,38 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package safehttp
+
+import "time"
+
+// WithTimeout returns a configuration that limits the time to process the
+// requests served by a handler. It can be passed when registering the handler,
+// like an InterceptorConfig, e.g.
+//
+//	mux.Handle("/report", MethodGet, reportHandler, WithTimeout(5*time.Second))
+//
+// The context of the request is cancelled when the timeout expires. If the
+// handler then returns without writing a response, or writes it too late, a
+// 504 Gateway Timeout error is written instead, through the Dispatcher and
+// the Commit phases of the interceptors.
+//
+// Handlers aren't preempted: they must stop processing the request when its
+// context is cancelled.
+func WithTimeout(d time.Duration) InterceptorConfig {
+	return timeoutConfig{d: d}
+}
+
+type timeoutConfig struct {
+	d time.Duration
+}
@@ -0,0 +1,113 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package safehttp_test
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/go-safeweb/safehttp"
+	"github.com/google/safehtml"
+)
+
+func TestWithTimeout(t *testing.T) {
+	tests := []struct {
+		name       string
+		h          safehttp.Handler
+		wantStatus safehttp.StatusCode
+	}{
+		{
+			name: "In time",
+			h: safehttp.HandlerFunc(func(w safehttp.ResponseWriter, r *safehttp.IncomingRequest) safehttp.Result {
+				return w.Write(safehtml.HTMLEscaped("ok"))
+			}),
+			wantStatus: safehttp.StatusOK,
+		},
+		{
+			name: "Returns on cancellation",
+			h: safehttp.HandlerFunc(func(w safehttp.ResponseWriter, r *safehttp.IncomingRequest) safehttp.Result {
+				<-r.Context().Done()
+				return safehttp.NotWritten()
+			}),
+			wantStatus: safehttp.StatusGatewayTimeout,
+		},
+		{
+			name: "Writes too late",
+			h: safehttp.HandlerFunc(func(w safehttp.ResponseWriter, r *safehttp.IncomingRequest) safehttp.Result {
+				<-r.Context().Done()
+				return w.Write(safehtml.HTMLEscaped("too late"))
+			}),
+			wantStatus: safehttp.StatusGatewayTimeout,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var log []string
+			mb := safehttp.NewServeMuxConfig(nil)
+			mb.Intercept(recordingInterceptor{name: "a", log: &log})
+			mux := mb.Mux()
+			mux.Handle("/", safehttp.MethodGet, tt.h, safehttp.WithTimeout(10*time.Millisecond))
+
+			rw := httptest.NewRecorder()
+			mux.ServeHTTP(rw, httptest.NewRequest(safehttp.MethodGet, "http://foo.com/", nil))
+
+			if got, want := rw.Code, int(tt.wantStatus); got != want {
+				t.Errorf("rw.Code: got %v want %v", got, want)
+			}
+			if got, want := rw.Header().Get("Commit"), "a"; got != want {
+				t.Errorf(`rw.Header().Get("Commit"): got %q want %q`, got, want)
+			}
+		})
+	}
+}
+
+func TestWithTimeoutGroup(t *testing.T) {
+	mb := safehttp.NewServeMuxConfig(nil)
+	g := mb.Group("/api")
+	g.Configure(safehttp.WithTimeout(time.Hour))
+	h := safehttp.HandlerFunc(func(w safehttp.ResponseWriter, r *safehttp.IncomingRequest) safehttp.Result {
+		deadline, ok := r.Context().Deadline()
+		if !ok {
+			return w.WriteError(safehttp.StatusInternalServerError)
+		}
+		if time.Until(deadline) > time.Minute {
+			return w.Write(safehtml.HTMLEscaped("hour"))
+		}
+		return w.Write(safehtml.HTMLEscaped("minute"))
+	})
+	g.Handle("/a", safehttp.MethodGet, h)
+	g.Handle("/b", safehttp.MethodGet, h, safehttp.WithTimeout(time.Minute))
+	mux := mb.Mux()
+
+	for path, want := range map[string]string{"/api/a": "hour", "/api/b": "minute"} {
+		rw := httptest.NewRecorder()
+		mux.ServeHTTP(rw, httptest.NewRequest(safehttp.MethodGet, "http://foo.com"+path, nil))
+		if got := rw.Body.String(); got != want {
+			t.Errorf("%s: response body got %q want %q", path, got, want)
+		}
+	}
+
+	for _, r := range mux.Routes() {
+		want := time.Hour
+		if r.Pattern == "/api/b" {
+			want = time.Minute
+		}
+		if r.Timeout != want {
+			t.Errorf("%s: route.Timeout got %v want %v", r.Pattern, r.Timeout, want)
+		}
+	}
+}
@@ -0,0 +1,144 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package safehttp
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// intermediateCipherSuites are the TLS 1.2 cipher suites of the Mozilla
+// "intermediate" configuration: AEAD ciphers with forward secrecy. TLS 1.3
+// cipher suites are not configurable.
+var intermediateCipherSuites = []uint16{
+	tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
+	tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+	tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
+	tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+	tls.TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305_SHA256,
+	tls.TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305_SHA256,
+}
+
+// TLSModern returns a TLS configuration which only accepts TLS 1.3, for
+// services whose clients are all recent. The certificates still need to be
+// configured.
+func TLSModern() *tls.Config {
+	return &tls.Config{
+		MinVersion:       tls.VersionTLS13,
+		CurvePreferences: []tls.CurveID{tls.X25519, tls.CurveP256, tls.CurveP384},
+	}
+}
+
+// TLSIntermediate returns a TLS configuration which accepts TLS 1.2 with
+// forward secret AEAD cipher suites, and TLS 1.3. It's the recommended
+// configuration for general-purpose services. The certificates still need to
+// be configured.
+func TLSIntermediate() *tls.Config {
+	return &tls.Config{
+		MinVersion:       tls.VersionTLS12,
+		CipherSuites:     append([]uint16(nil), intermediateCipherSuites...),
+		CurvePreferences: []tls.CurveID{tls.X25519, tls.CurveP256, tls.CurveP384},
+	}
+}
+
+// SetTLSConfig validates the TLS configuration and sets a clone of it as the
+// TLSConfig of the server. It returns an error if the configuration allows
+// versions older than TLS 1.2 or TLS 1.2 cipher suites without forward
+// secrecy or AEAD, i.e. not in TLSIntermediate. A zero MinVersion is set to
+// TLS 1.2.
+func (s *Server) SetTLSConfig(cfg *tls.Config) error {
+	if cfg == nil {
+		return errors.New("nil TLS configuration")
+	}
+	if cfg.MinVersion != 0 && cfg.MinVersion < tls.VersionTLS12 {
+		return fmt.Errorf("minimum TLS version %#04x is older than TLS 1.2", cfg.MinVersion)
+	}
+	if cfg.MaxVersion != 0 && cfg.MaxVersion < tls.VersionTLS12 {
+		return fmt.Errorf("maximum TLS version %#04x is older than TLS 1.2", cfg.MaxVersion)
+	}
+	for _, id := range cfg.CipherSuites {
+		if !isIntermediateCipherSuite(id) {
+			return fmt.Errorf("weak cipher suite %s", tls.CipherSuiteName(id))
+		}
+	}
+	c := cfg.Clone()
+	if c.MinVersion == 0 {
+		c.MinVersion = tls.VersionTLS12
+	}
+	s.TLSConfig = c
+	return nil
+}
+
+func isIntermediateCipherSuite(id uint16) bool {
+	for _, s := range intermediateCipherSuites {
+		if id == s {
+			return true
+		}
+	}
+	return false
+}
+
+// ticketKeys generates the session ticket keys of a server. Each key is used
+// to encrypt new tickets for a rotation period, and to decrypt them for
+// another one.
+type ticketKeys struct {
+	rand    io.Reader
+	current [32]byte
+}
+
+// rotate generates a new key and returns the keys to use from now on, the new
+// one first.
+func (k *ticketKeys) rotate() ([][32]byte, error) {
+	prev := k.current
+	if _, err := io.ReadFull(k.rand, k.current[:]); err != nil {
+		return nil, err
+	}
+	if prev == ([32]byte{}) {
+		return [][32]byte{k.current}, nil
+	}
+	return [][32]byte{k.current, prev}, nil
+}
+
+// rotateTicketKeys sets new session ticket keys in cfg every period, until
+// stop is closed. The keys are read from rand.
+func rotateTicketKeys(cfg *tls.Config, period time.Duration, rand io.Reader, stop <-chan struct{}) error {
+	k := ticketKeys{rand: rand}
+	keys, err := k.rotate()
+	if err != nil {
+		return err
+	}
+	cfg.SetSessionTicketKeys(keys)
+	go func() {
+		t := time.NewTicker(period)
+		defer t.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-t.C:
+				keys, err := k.rotate()
+				if err != nil {
+					// Keep on using the current keys.
+					continue
+				}
+				cfg.SetSessionTicketKeys(keys)
+			}
+		}
+	}()
+	return nil
+}
@@ -0,0 +1,186 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package safehttp
+
+import (
+	"bytes"
+	"crypto/tls"
+	"testing"
+	"time"
+)
+
+func TestSetTLSConfig(t *testing.T) {
+	tests := []struct {
+		name           string
+		cfg            *tls.Config
+		wantErr        bool
+		wantMinVersion uint16
+	}{
+		{
+			name:           "Modern",
+			cfg:            TLSModern(),
+			wantMinVersion: tls.VersionTLS13,
+		},
+		{
+			name:           "Intermediate",
+			cfg:            TLSIntermediate(),
+			wantMinVersion: tls.VersionTLS12,
+		},
+		{
+			name:           "Default minimum version",
+			cfg:            &tls.Config{},
+			wantMinVersion: tls.VersionTLS12,
+		},
+		{
+			name:    "Nil",
+			cfg:     nil,
+			wantErr: true,
+		},
+		{
+			name:    "TLS 1.0",
+			cfg:     &tls.Config{MinVersion: tls.VersionTLS10},
+			wantErr: true,
+		},
+		{
+			name:    "Maximum TLS 1.1",
+			cfg:     &tls.Config{MaxVersion: tls.VersionTLS11},
+			wantErr: true,
+		},
+		{
+			name:    "CBC cipher suite",
+			cfg:     &tls.Config{CipherSuites: []uint16{tls.TLS_ECDHE_RSA_WITH_AES_128_CBC_SHA}},
+			wantErr: true,
+		},
+		{
+			name:    "RSA key exchange",
+			cfg:     &tls.Config{CipherSuites: []uint16{tls.TLS_RSA_WITH_AES_128_GCM_SHA256}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var s Server
+			err := s.SetTLSConfig(tt.cfg)
+			if tt.wantErr {
+				if err == nil {
+					t.Error("SetTLSConfig: got nil, want error")
+				}
+				if s.TLSConfig != nil {
+					t.Error("s.TLSConfig: got set, want nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("SetTLSConfig: got %v, want nil", err)
+			}
+			if s.TLSConfig == tt.cfg {
+				t.Error("s.TLSConfig: got the same configuration, want a clone")
+			}
+			if got := s.TLSConfig.MinVersion; got != tt.wantMinVersion {
+				t.Errorf("s.TLSConfig.MinVersion: got %#04x want %#04x", got, tt.wantMinVersion)
+			}
+		})
+	}
+}
+
+func TestServerKeepsTLS13(t *testing.T) {
+	s := Server{Mux: NewServeMuxConfig(nil).Mux(), TLSConfig: TLSModern()}
+	if err := s.buildStd(); err != nil {
+		t.Fatalf("buildStd: %v", err)
+	}
+	if got, want := s.srv.TLSConfig.MinVersion, uint16(tls.VersionTLS13); got != want {
+		t.Errorf("s.srv.TLSConfig.MinVersion: got %#04x want %#04x", got, want)
+	}
+}
+
+func TestTicketKeysRotate(t *testing.T) {
+	k := ticketKeys{rand: SystemRand()}
+	first, err := k.rotate()
+	if err != nil {
+		t.Fatalf("rotate: %v", err)
+	}
+	if len(first) != 1 {
+		t.Fatalf("first rotation: got %d keys, want 1", len(first))
+	}
+	second, err := k.rotate()
+	if err != nil {
+		t.Fatalf("rotate: %v", err)
+	}
+	if len(second) != 2 {
+		t.Fatalf("second rotation: got %d keys, want 2", len(second))
+	}
+	if second[0] == first[0] {
+		t.Error("second rotation: the key wasn't changed")
+	}
+	if second[1] != first[0] {
+		t.Error("second rotation: the previous key isn't accepted anymore")
+	}
+}
+
+func TestServerStopsTicketKeyRotation(t *testing.T) {
+	s := Server{
+		Mux:                      NewServeMuxConfig(nil).Mux(),
+		TLSConfig:                TLSIntermediate(),
+		SessionTicketKeyRotation: time.Millisecond,
+	}
+	if err := s.buildStd(); err != nil {
+		t.Fatalf("buildStd: %v", err)
+	}
+	stop := s.stopRotation
+	if stop == nil {
+		t.Fatal("the rotation wasn't started")
+	}
+	s.started = true
+	s.Close()
+	select {
+	case <-stop:
+	default:
+		t.Error("Close didn't stop the rotation")
+	}
+}
+
+type fakeClock time.Time
+
+func (c fakeClock) Now() time.Time {
+	return time.Time(c)
+}
+
+func TestServerRandAndClock(t *testing.T) {
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	rand := bytes.NewReader(bytes.Repeat([]byte{0x42}, 32))
+	s := Server{
+		Mux:                      NewServeMuxConfig(nil).Mux(),
+		TLSConfig:                TLSIntermediate(),
+		SessionTicketKeyRotation: time.Hour,
+		Clock:                    fakeClock(now),
+		Rand:                     rand,
+	}
+	if err := s.buildStd(); err != nil {
+		t.Fatalf("buildStd: %v", err)
+	}
+	s.started = true
+	defer s.Close()
+	if rand.Len() != 0 {
+		t.Errorf("the session ticket key wasn't read from Rand: %d bytes left", rand.Len())
+	}
+	cfg := s.srv.TLSConfig
+	if cfg.Rand != s.Rand {
+		t.Error("s.srv.TLSConfig.Rand isn't s.Rand")
+	}
+	if cfg.Time == nil || !cfg.Time().Equal(now) {
+		t.Error("s.srv.TLSConfig.Time isn't s.Clock.Now")
+	}
+}
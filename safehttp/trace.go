@@ -0,0 +1,132 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package safehttp
+
+import (
+	"fmt"
+	"net/textproto"
+	"strings"
+)
+
+// TraceEntry records the Before phase of a single interceptor. See
+// ServeMuxConfig.TraceInterceptors.
+type TraceEntry struct {
+	// Interceptor is the type of the interceptor, e.g.
+	// "*fetchmetadata.Policy".
+	Interceptor string
+	// Headers are the request headers the interceptor read, in canonical
+	// form and in the order they were first read.
+	Headers []string
+	// Wrote reports whether the interceptor wrote a response, stopping the
+	// processing of the request.
+	Wrote bool
+	// Code is the status code of the response, if the interceptor wrote an
+	// error. It is zero otherwise.
+	Code StatusCode
+}
+
+func (e TraceEntry) String() string {
+	decision := "pass"
+	switch {
+	case e.Wrote && e.Code != 0:
+		decision = fmt.Sprintf("error %d", e.Code)
+	case e.Wrote:
+		decision = "write"
+	}
+	s := e.Interceptor + ": " + decision
+	if len(e.Headers) > 0 {
+		s += " [" + strings.Join(e.Headers, " ") + "]"
+	}
+	return s
+}
+
+var traceKey = NewKey("safehttp.trace")
+
+// InterceptorTrace returns the trace of the Before phases of the interceptors
+// which ran on the request, or nil if tracing is disabled.
+//
+// It can be used by handlers serving debug pages. See
+// ServeMuxConfig.TraceInterceptors.
+func InterceptorTrace(r *IncomingRequest) []TraceEntry {
+	v, ok := r.Values().Get(traceKey)
+	if !ok {
+		return nil
+	}
+	t := v.(*interceptorTrace)
+	return append([]TraceEntry(nil), t.entries...)
+}
+
+// interceptorTrace records the decisions of interceptors on a single request.
+// All methods are no-ops on a nil *interceptorTrace.
+type interceptorTrace struct {
+	entries []TraceEntry
+	// current is the entry of the interceptor whose Before phase is running,
+	// or nil.
+	current *TraceEntry
+}
+
+func newInterceptorTrace(r *IncomingRequest) *interceptorTrace {
+	t := &interceptorTrace{}
+	r.Header.onRead = t.headerRead
+	r.Values().Set(traceKey, t)
+	return t
+}
+
+func (t *interceptorTrace) begin(it Interceptor) {
+	if t == nil {
+		return
+	}
+	t.entries = append(t.entries, TraceEntry{Interceptor: fmt.Sprintf("%T", it)})
+	t.current = &t.entries[len(t.entries)-1]
+}
+
+func (t *interceptorTrace) end() {
+	if t == nil {
+		return
+	}
+	t.current = nil
+}
+
+func (t *interceptorTrace) headerRead(name string) {
+	if t == nil || t.current == nil {
+		return
+	}
+	name = textproto.CanonicalMIMEHeaderKey(name)
+	for _, h := range t.current.Headers {
+		if h == name {
+			return
+		}
+	}
+	t.current.Headers = append(t.current.Headers, name)
+}
+
+// written records that a response was written and sets the X-Safeweb-Trace
+// header, summarizing the trace, on h.
+func (t *interceptorTrace) written(h Header, resp Response) {
+	if t == nil {
+		return
+	}
+	if t.current != nil {
+		t.current.Wrote = true
+		if e, ok := resp.(ErrorResponse); ok {
+			t.current.Code = e.Code()
+		}
+	}
+	entries := make([]string, 0, len(t.entries))
+	for _, e := range t.entries {
+		entries = append(entries, e.String())
+	}
+	h.wrapped.Set("X-Safeweb-Trace", strings.Join(entries, ", "))
+}
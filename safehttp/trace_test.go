@@ -0,0 +1,105 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package safehttp_test
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-safeweb/safehttp"
+	"github.com/google/go-safeweb/safehttp/plugins/fetchmetadata"
+	"github.com/google/go-safeweb/safehttp/plugins/hostcheck"
+)
+
+func newTracedMux(t *testing.T, h safehttp.Handler) *safehttp.ServeMux {
+	t.Helper()
+	mb := safehttp.NewServeMuxConfig(nil)
+	mb.Intercept(hostcheck.New("foo.com"))
+	mb.Intercept(fetchmetadata.ResourceIsolationPolicy())
+	mb.TraceInterceptors()
+	mux := mb.Mux()
+	mux.Handle("/", safehttp.MethodPost, h)
+	return mux
+}
+
+func TestTraceInterceptorsBlocked(t *testing.T) {
+	safehttp.SetLocalDevForTest(t, true)
+	mux := newTracedMux(t, safehttp.HandlerFunc(func(w safehttp.ResponseWriter, r *safehttp.IncomingRequest) safehttp.Result {
+		t.Error("handler called, want request to be blocked")
+		return w.Write(safehttp.NoContentResponse{})
+	}))
+
+	req := httptest.NewRequest(safehttp.MethodPost, "http://foo.com/", nil)
+	req.Header.Set("Sec-Fetch-Site", "cross-site")
+	req.Header.Set("Sec-Fetch-Mode", "no-cors")
+	req.Header.Set("Sec-Fetch-Dest", "image")
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, req)
+
+	if got, want := rr.Code, int(safehttp.StatusForbidden); got != want {
+		t.Errorf("rr.Code got %v, want %v", got, want)
+	}
+	want := "hostcheck.Interceptor: pass, *fetchmetadata.Policy: error 403 [Sec-Fetch-Site Sec-Fetch-Mode Sec-Fetch-Dest]"
+	if got := rr.Header().Get("X-Safeweb-Trace"); got != want {
+		t.Errorf(`rr.Header().Get("X-Safeweb-Trace") got %q, want %q`, got, want)
+	}
+}
+
+func TestTraceInterceptorsFromHandler(t *testing.T) {
+	safehttp.SetLocalDevForTest(t, true)
+	var got []safehttp.TraceEntry
+	mux := newTracedMux(t, safehttp.HandlerFunc(func(w safehttp.ResponseWriter, r *safehttp.IncomingRequest) safehttp.Result {
+		got = safehttp.InterceptorTrace(r)
+		return w.Write(safehttp.NoContentResponse{})
+	}))
+
+	req := httptest.NewRequest(safehttp.MethodPost, "http://foo.com/", nil)
+	req.Header.Set("Sec-Fetch-Site", "same-origin")
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, req)
+
+	want := []safehttp.TraceEntry{
+		{Interceptor: "hostcheck.Interceptor"},
+		{Interceptor: "*fetchmetadata.Policy", Headers: []string{"Sec-Fetch-Site"}},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("safehttp.InterceptorTrace(r) mismatch (-want +got):\n%s", diff)
+	}
+	if got, want := rr.Header().Get("X-Safeweb-Trace"), "hostcheck.Interceptor: pass, *fetchmetadata.Policy: pass [Sec-Fetch-Site]"; got != want {
+		t.Errorf(`rr.Header().Get("X-Safeweb-Trace") got %q, want %q`, got, want)
+	}
+}
+
+func TestTraceInterceptorsProduction(t *testing.T) {
+	safehttp.SetLocalDevForTest(t, false)
+	mux := newTracedMux(t, safehttp.HandlerFunc(func(w safehttp.ResponseWriter, r *safehttp.IncomingRequest) safehttp.Result {
+		if tr := safehttp.InterceptorTrace(r); tr != nil {
+			t.Errorf("safehttp.InterceptorTrace(r) got %v, want nil", tr)
+		}
+		return w.Write(safehttp.NoContentResponse{})
+	}))
+
+	for _, site := range []string{"same-origin", "cross-site"} {
+		req := httptest.NewRequest(safehttp.MethodPost, "http://foo.com/", nil)
+		req.Header.Set("Sec-Fetch-Site", site)
+		rr := httptest.NewRecorder()
+		mux.ServeHTTP(rr, req)
+
+		if got := rr.Header().Values("X-Safeweb-Trace"); len(got) != 0 {
+			t.Errorf(`Sec-Fetch-Site: %s, rr.Header().Values("X-Safeweb-Trace") got %v, want none`, site, got)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package safehttp
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// forbiddenTrailers are the headers that can't be sent as trailers, because
+// they are needed to frame, route, authenticate or process the response
+// (RFC 7230, section 4.1.2) or because they are security-relevant and must
+// be seen before the body.
+var forbiddenTrailers = map[string]bool{
+	"Authorization":             true,
+	"Cache-Control":             true,
+	"Content-Disposition":       true,
+	"Content-Encoding":          true,
+	"Content-Length":            true,
+	"Content-Range":             true,
+	"Content-Security-Policy":   true,
+	"Content-Type":              true,
+	"Host":                      true,
+	"Location":                  true,
+	"Set-Cookie":                true,
+	"Strict-Transport-Security": true,
+	"Te":                        true,
+	"Trailer":                   true,
+	"Transfer-Encoding":         true,
+	"Www-Authenticate":          true,
+	"X-Content-Type-Options":    true,
+	"X-Frame-Options":           true,
+}
+
+// SetTrailer sets a trailer of the response, see ResponseWriter.SetTrailer.
+func (f *flight) SetTrailer(name, value string) error {
+	name = http.CanonicalHeaderKey(name)
+	if name == "" || forbiddenTrailers[name] {
+		return fmt.Errorf("%q can't be a trailer", name)
+	}
+	h := f.rw.Header()
+	if _, ok := h[http.TrailerPrefix+name]; !ok && !f.dispatched {
+		h.Add("Trailer", name)
+	}
+	h.Set(http.TrailerPrefix+name, value)
+	return nil
+}
@@ -0,0 +1,101 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package safehttp_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-safeweb/safehttp"
+)
+
+func TestSetTrailer(t *testing.T) {
+	tests := []struct {
+		name        string
+		handler     safehttp.HandlerFunc
+		wantTrailer http.Header
+		wantDeclare []string
+	}{
+		{
+			name: "Before writing",
+			handler: func(w safehttp.ResponseWriter, r *safehttp.IncomingRequest) safehttp.Result {
+				if err := w.SetTrailer("x-timing", "12ms"); err != nil {
+					t.Errorf(`w.SetTrailer("x-timing"): got err %v`, err)
+				}
+				return safehttp.WriteJSON(w, "ok")
+			},
+			wantTrailer: http.Header{"X-Timing": {"12ms"}},
+			wantDeclare: []string{"X-Timing"},
+		},
+		{
+			name: "After streaming",
+			handler: func(w safehttp.ResponseWriter, r *safehttp.IncomingRequest) safehttp.Result {
+				sw, err := w.Stream("text/plain; charset=utf-8")
+				if err != nil {
+					t.Fatalf("w.Stream: %v", err)
+				}
+				h := sha256.New()
+				io.MultiWriter(sw, h).Write([]byte("hello"))
+				if err := w.SetTrailer("X-Checksum", hex.EncodeToString(h.Sum(nil))); err != nil {
+					t.Errorf(`w.SetTrailer("X-Checksum"): got err %v`, err)
+				}
+				return safehttp.Result{}
+			},
+			wantTrailer: http.Header{"X-Checksum": {"2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mux := safehttp.NewServeMuxConfig(nil).Mux()
+			mux.Handle("/", safehttp.MethodGet, tt.handler)
+			rr := httptest.NewRecorder()
+			mux.ServeHTTP(rr, httptest.NewRequest(safehttp.MethodGet, "http://foo.com/", nil))
+
+			res := rr.Result()
+			if diff := cmp.Diff(tt.wantTrailer, res.Trailer); diff != "" {
+				t.Errorf("res.Trailer mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tt.wantDeclare, res.Header.Values("Trailer")); diff != "" {
+				t.Errorf(`res.Header.Values("Trailer") mismatch (-want +got):\n%s`, diff)
+			}
+		})
+	}
+}
+
+func TestSetTrailerForbidden(t *testing.T) {
+	for _, name := range []string{"Content-Length", "set-cookie", "Content-Type", "Trailer", ""} {
+		t.Run(name, func(t *testing.T) {
+			mux := safehttp.NewServeMuxConfig(nil).Mux()
+			mux.Handle("/", safehttp.MethodGet, safehttp.HandlerFunc(func(w safehttp.ResponseWriter, r *safehttp.IncomingRequest) safehttp.Result {
+				if err := w.SetTrailer(name, "x"); err == nil {
+					t.Errorf("w.SetTrailer(%q): got nil err, want error", name)
+				}
+				return safehttp.WriteJSON(w, "ok")
+			}))
+			rr := httptest.NewRecorder()
+			mux.ServeHTTP(rr, httptest.NewRequest(safehttp.MethodGet, "http://foo.com/", nil))
+
+			if got := rr.Result().Trailer; len(got) != 0 {
+				t.Errorf("rr.Result().Trailer: got %v, want none", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package safehttp
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"net/http"
+)
+
+// UpgradeResponse switches the connection to another protocol, e.g.
+// WebSocket, with a 101 Switching Protocols response. It's meant to be written
+// by plugins implementing the protocol, such as plugins/websocket.
+//
+// The response headers, including the ones set in the Commit phase, are sent
+// with the 101 response. Then the DefaultDispatcher takes over the connection
+// and calls Serve, which owns it until it returns. The connection is closed
+// afterwards.
+//
+// Only HTTP/1.x connections can be upgraded.
+type UpgradeResponse struct {
+	// Protocol is the value of the Upgrade header, e.g. "websocket".
+	Protocol string
+	// Serve speaks the new protocol. The reader of brw might contain data
+	// already sent by the client.
+	Serve func(conn net.Conn, brw *bufio.ReadWriter)
+}
+
+func writeUpgrade(rw http.ResponseWriter, resp UpgradeResponse) error {
+	hj, ok := rw.(http.Hijacker)
+	if !ok {
+		return fmt.Errorf("%T does not support connection upgrades", rw)
+	}
+	h := rw.Header()
+	h.Set("Connection", "Upgrade")
+	h.Set("Upgrade", resp.Protocol)
+	// These are meaningless for a 101 response.
+	h.Del("Content-Type")
+	h.Del("Content-Length")
+	h.Del("Transfer-Encoding")
+
+	conn, brw, err := hj.Hijack()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	fmt.Fprintf(brw, "HTTP/1.1 %d %s\r\n", StatusSwitchingProtocols, http.StatusText(int(StatusSwitchingProtocols)))
+	h.Write(brw)
+	brw.WriteString("\r\n")
+	if err := brw.Flush(); err != nil {
+		// The client is gone and the connection can't be used anymore.
+		return nil
+	}
+	resp.Serve(conn, brw)
+	return nil
+}
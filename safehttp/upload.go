@@ -0,0 +1,294 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package safehttp
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+)
+
+// Default limits of ReceiveUploads.
+const (
+	DefaultUploadMaxFileSize     = 10 << 20
+	DefaultUploadMaxTotalSize    = 32 << 20
+	DefaultUploadMemoryThreshold = 1 << 20
+)
+
+// UploadOptions are the limits enforced by IncomingRequest.ReceiveUploads.
+type UploadOptions struct {
+	// MaxFileSize is the maximum size of a file. If zero,
+	// DefaultUploadMaxFileSize is used.
+	MaxFileSize int64
+	// MaxTotalSize is the maximum size of all the parts of the form, files
+	// and values. If zero, DefaultUploadMaxTotalSize is used.
+	MaxTotalSize int64
+	// MemoryThreshold is the size above which a file is stored in a
+	// temporary file rather than in memory. If zero,
+	// DefaultUploadMemoryThreshold is used.
+	MemoryThreshold int64
+	// AllowedExtensions are the allowed file name extensions, e.g. ".png",
+	// compared case-insensitively. If empty, all extensions are allowed.
+	AllowedExtensions []string
+	// AllowedTypes are the allowed media types, as detected from the content
+	// of the files with http.DetectContentType, e.g. "image/png". The type
+	// sent by the client is ignored. If empty, all types are allowed.
+	AllowedTypes []string
+	// TempDir is the directory of the temporary files. If empty, the default
+	// directory for temporary files is used.
+	TempDir string
+}
+
+// UploadError is an error receiving uploads. It's also an ErrorResponse, so
+// that handlers can write it directly.
+type UploadError struct {
+	// Status is the status code of the error: 415 Unsupported Media Type if
+	// the request or a file has a disallowed type, 413 Request Entity Too
+	// Large if a limit is exceeded or 400 Bad Request.
+	Status StatusCode
+	Err    error
+}
+
+func (e *UploadError) Error() string {
+	return fmt.Sprintf("%d %v", e.Status, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *UploadError) Unwrap() error {
+	return e.Err
+}
+
+// Code returns the status code of the error.
+func (e *UploadError) Code() StatusCode {
+	return e.Status
+}
+
+// Uploads are the files and values of a multipart form received with
+// IncomingRequest.ReceiveUploads.
+type Uploads struct {
+	// Files are the uploaded files, in the order they were received.
+	Files []*Upload
+	// Values are the values of the non-file parts of the form.
+	Values map[string][]string
+}
+
+// RemoveFiles removes the temporary files of the uploads and returns the first
+// error that occured, if any.
+func (u *Uploads) RemoveFiles() error {
+	var first error
+	for _, f := range u.Files {
+		if f.path == "" {
+			continue
+		}
+		if err := os.Remove(f.path); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// Upload is an uploaded file.
+type Upload struct {
+	// Field is the name of the form field.
+	Field string
+	// Filename is the sanitized base name of the file, without directories
+	// or control characters. It's never empty.
+	Filename string
+	// ContentType is the media type detected from the content of the file.
+	ContentType string
+	// Size is the size of the file in bytes.
+	Size int64
+
+	data []byte
+	path string
+}
+
+// Open returns a reader of the content of the file.
+func (u *Upload) Open() (io.ReadCloser, error) {
+	if u.path != "" {
+		return os.Open(u.path)
+	}
+	return ioutil.NopCloser(bytes.NewReader(u.data)), nil
+}
+
+// ReceiveUploads reads the multipart/form-data body of a POST, PATCH or PUT
+// request, enforcing the given limits. The files are streamed, and those
+// larger than the memory threshold are stored in temporary files, which must
+// be removed with Uploads.RemoveFiles. Errors are *UploadErrors, and no
+// temporary files are left behind in case of error.
+//
+// ReceiveUploads can't be combined with MultipartForm or PostForm, which read
+// the same body.
+func (r *IncomingRequest) ReceiveUploads(opts UploadOptions) (*Uploads, error) {
+	if m := r.OriginalMethod(); m != MethodPost && m != MethodPatch && m != MethodPut {
+		return nil, &UploadError{Status: StatusBadRequest, Err: fmt.Errorf("got request method %s, want POST/PATCH/PUT", m)}
+	}
+	mr, err := r.req.MultipartReader()
+	if err != nil {
+		return nil, &UploadError{Status: StatusUnsupportedMediaType, Err: err}
+	}
+	rcv := &uploadReceiver{
+		opts:      opts,
+		remaining: orDefault(opts.MaxTotalSize, DefaultUploadMaxTotalSize),
+		uploads:   &Uploads{Values: map[string][]string{}},
+	}
+	for {
+		p, err := mr.NextPart()
+		if err == io.EOF {
+			return rcv.uploads, nil
+		}
+		if err == nil {
+			err = rcv.receive(p.FormName(), p.FileName(), p)
+			p.Close()
+		} else {
+			err = &UploadError{Status: StatusBadRequest, Err: err}
+		}
+		if err != nil {
+			rcv.uploads.RemoveFiles()
+			return nil, err
+		}
+	}
+}
+
+func orDefault(v, def int64) int64 {
+	if v == 0 {
+		return def
+	}
+	return v
+}
+
+var errUploadTooLarge = errors.New("upload too large")
+
+type uploadReceiver struct {
+	opts      UploadOptions
+	remaining int64
+	uploads   *Uploads
+}
+
+// read reads at most max bytes from r into w, also enforcing the total size
+// limit.
+func (rcv *uploadReceiver) read(w io.Writer, r io.Reader, max int64) (int64, error) {
+	if rcv.remaining < max {
+		max = rcv.remaining
+	}
+	n, err := io.Copy(w, io.LimitReader(r, max+1))
+	rcv.remaining -= n
+	if err != nil {
+		return n, &UploadError{Status: StatusBadRequest, Err: err}
+	}
+	if n > max {
+		return n, &UploadError{Status: StatusRequestEntityTooLarge, Err: errUploadTooLarge}
+	}
+	return n, nil
+}
+
+func (rcv *uploadReceiver) receive(field, filename string, r io.Reader) error {
+	if filename == "" {
+		var b strings.Builder
+		if _, err := rcv.read(&b, r, rcv.remaining); err != nil {
+			return err
+		}
+		rcv.uploads.Values[field] = append(rcv.uploads.Values[field], b.String())
+		return nil
+	}
+
+	u := &Upload{Field: field, Filename: sanitizeUploadFilename(filename)}
+	if !allowed(rcv.opts.AllowedExtensions, strings.ToLower(filepath.Ext(u.Filename))) {
+		return &UploadError{Status: StatusUnsupportedMediaType, Err: fmt.Errorf("file extension of %q not allowed", u.Filename)}
+	}
+
+	maxFile := orDefault(rcv.opts.MaxFileSize, DefaultUploadMaxFileSize)
+	threshold := orDefault(rcv.opts.MemoryThreshold, DefaultUploadMemoryThreshold)
+	if threshold > maxFile {
+		threshold = maxFile
+	}
+	// The file is spooled to disk if it's larger than the threshold, unless a
+	// size limit is reached first.
+	spool := threshold < maxFile && threshold < rcv.remaining
+	var buf bytes.Buffer
+	n, err := rcv.read(&buf, r, threshold)
+	u.Size = n
+	u.ContentType = http.DetectContentType(buf.Bytes())
+	if err != nil && !errors.Is(err, errUploadTooLarge) {
+		return err
+	}
+	mt, _, _ := mime.ParseMediaType(u.ContentType)
+	if !allowed(rcv.opts.AllowedTypes, mt) {
+		return &UploadError{Status: StatusUnsupportedMediaType, Err: fmt.Errorf("content type %q of %q not allowed", mt, u.Filename)}
+	}
+	if err == nil {
+		u.data = buf.Bytes()
+		rcv.uploads.Files = append(rcv.uploads.Files, u)
+		return nil
+	}
+	if !spool {
+		return err
+	}
+
+	// The file is larger than the memory threshold.
+	f, err := ioutil.TempFile(rcv.opts.TempDir, "safehttp-upload-")
+	if err != nil {
+		return &UploadError{Status: StatusInternalServerError, Err: err}
+	}
+	u.path = f.Name()
+	rcv.uploads.Files = append(rcv.uploads.Files, u)
+	if _, err := f.Write(buf.Bytes()); err != nil {
+		f.Close()
+		return &UploadError{Status: StatusInternalServerError, Err: err}
+	}
+	m, err := rcv.read(f, r, maxFile-n)
+	u.Size += m
+	if cerr := f.Close(); err == nil && cerr != nil {
+		return &UploadError{Status: StatusInternalServerError, Err: cerr}
+	}
+	return err
+}
+
+func allowed(list []string, v string) bool {
+	if len(list) == 0 {
+		return true
+	}
+	for _, a := range list {
+		if strings.EqualFold(a, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// sanitizeUploadFilename returns the base name of the file name sent by the
+// client, without control characters and leading dots.
+func sanitizeUploadFilename(name string) string {
+	name = filepath.Base(strings.ReplaceAll(name, "\\", "/"))
+	name = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, name)
+	name = strings.TrimLeft(strings.TrimSpace(name), ".")
+	if name == "" {
+		return "file"
+	}
+	return name
+}
@@ -0,0 +1,188 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package safehttp_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/ioutil"
+	"mime/multipart"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-safeweb/safehttp"
+	"github.com/google/go-safeweb/safehttp/safehttptest"
+)
+
+type uploadPart struct {
+	field, filename, content string
+}
+
+func newUploadRequest(t *testing.T, parts ...uploadPart) *safehttp.IncomingRequest {
+	t.Helper()
+	var b bytes.Buffer
+	mw := multipart.NewWriter(&b)
+	for _, p := range parts {
+		var err error
+		if p.filename == "" {
+			err = mw.WriteField(p.field, p.content)
+		} else {
+			var w io.Writer
+			w, err = mw.CreateFormFile(p.field, p.filename)
+			if err == nil {
+				_, err = w.Write([]byte(p.content))
+			}
+		}
+		if err != nil {
+			t.Fatalf("writing multipart body: %v", err)
+		}
+	}
+	mw.Close()
+	r := safehttptest.NewRequest(safehttp.MethodPost, "/", &b)
+	r.Header.Set("Content-Type", mw.FormDataContentType())
+	return r
+}
+
+func TestReceiveUploads(t *testing.T) {
+	dir := t.TempDir()
+	png := "\x89PNG\x0D\x0A\x1A\x0A" + strings.Repeat("x", 100)
+	r := newUploadRequest(t,
+		uploadPart{field: "title", content: "holidays"},
+		uploadPart{field: "photo", filename: "../../etc/small.png", content: png[:20]},
+		uploadPart{field: "photo", filename: "C:\\Users\\me\\large.png", content: png},
+	)
+
+	u, err := r.ReceiveUploads(safehttp.UploadOptions{
+		MemoryThreshold:   50,
+		AllowedExtensions: []string{".PNG"},
+		AllowedTypes:      []string{"image/png"},
+		TempDir:           dir,
+	})
+	if err != nil {
+		t.Fatalf("r.ReceiveUploads() got err: %v", err)
+	}
+	defer u.RemoveFiles()
+
+	if diff := cmp.Diff(map[string][]string{"title": {"holidays"}}, u.Values); diff != "" {
+		t.Errorf("u.Values mismatch (-want +got):\n%s", diff)
+	}
+	type file struct {
+		Field, Filename, ContentType string
+		Size                         int64
+		Content                      string
+	}
+	var got []file
+	for _, f := range u.Files {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatalf("f.Open() got err: %v", err)
+		}
+		b, _ := ioutil.ReadAll(rc)
+		rc.Close()
+		got = append(got, file{f.Field, f.Filename, f.ContentType, f.Size, string(b)})
+	}
+	want := []file{
+		{"photo", "small.png", "image/png", 20, png[:20]},
+		{"photo", "large.png", "image/png", int64(len(png)), png},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("u.Files mismatch (-want +got):\n%s", diff)
+	}
+
+	if files, _ := ioutil.ReadDir(dir); len(files) != 1 {
+		t.Errorf("temporary files: got %d, want 1", len(files))
+	}
+	if err := u.RemoveFiles(); err != nil {
+		t.Errorf("u.RemoveFiles() got err: %v", err)
+	}
+	if files, _ := ioutil.ReadDir(dir); len(files) != 0 {
+		t.Errorf("temporary files after RemoveFiles: got %d, want 0", len(files))
+	}
+}
+
+func TestReceiveUploadsErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		parts      []uploadPart
+		opts       safehttp.UploadOptions
+		wantStatus safehttp.StatusCode
+	}{
+		{
+			name:       "File too large in memory",
+			parts:      []uploadPart{{field: "f", filename: "a.txt", content: strings.Repeat("a", 20)}},
+			opts:       safehttp.UploadOptions{MaxFileSize: 10},
+			wantStatus: safehttp.StatusRequestEntityTooLarge,
+		},
+		{
+			name:       "File too large on disk",
+			parts:      []uploadPart{{field: "f", filename: "a.txt", content: strings.Repeat("a", 20)}},
+			opts:       safehttp.UploadOptions{MaxFileSize: 10, MemoryThreshold: 5},
+			wantStatus: safehttp.StatusRequestEntityTooLarge,
+		},
+		{
+			name: "Total too large",
+			parts: []uploadPart{
+				{field: "f", filename: "a.txt", content: strings.Repeat("a", 8)},
+				{field: "f", filename: "b.txt", content: strings.Repeat("b", 8)},
+			},
+			opts:       safehttp.UploadOptions{MaxTotalSize: 12, MemoryThreshold: 5},
+			wantStatus: safehttp.StatusRequestEntityTooLarge,
+		},
+		{
+			name:       "Extension not allowed",
+			parts:      []uploadPart{{field: "f", filename: "a.exe", content: "a"}},
+			opts:       safehttp.UploadOptions{AllowedExtensions: []string{".txt"}},
+			wantStatus: safehttp.StatusUnsupportedMediaType,
+		},
+		{
+			name:       "Type not allowed",
+			parts:      []uploadPart{{field: "f", filename: "a.png", content: "<html><script>alert(1)</script>"}},
+			opts:       safehttp.UploadOptions{AllowedTypes: []string{"image/png"}},
+			wantStatus: safehttp.StatusUnsupportedMediaType,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			tt.opts.TempDir = dir
+			_, err := newUploadRequest(t, tt.parts...).ReceiveUploads(tt.opts)
+
+			var uploadErr *safehttp.UploadError
+			if !errors.As(err, &uploadErr) {
+				t.Fatalf("r.ReceiveUploads() got err %v, want *safehttp.UploadError", err)
+			}
+			if got := uploadErr.Code(); got != tt.wantStatus {
+				t.Errorf("uploadErr.Code() got %v, want %v", got, tt.wantStatus)
+			}
+			if files, _ := ioutil.ReadDir(dir); len(files) != 0 {
+				t.Errorf("temporary files left: got %d, want 0", len(files))
+			}
+		})
+	}
+}
+
+func TestReceiveUploadsNotMultipart(t *testing.T) {
+	r := safehttptest.NewRequest(safehttp.MethodPost, "/", strings.NewReader("a=b"))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	_, err := r.ReceiveUploads(safehttp.UploadOptions{})
+	var uploadErr *safehttp.UploadError
+	if !errors.As(err, &uploadErr) || uploadErr.Code() != safehttp.StatusUnsupportedMediaType {
+		t.Errorf("r.ReceiveUploads() got err %v, want 415 *safehttp.UploadError", err)
+	}
+}
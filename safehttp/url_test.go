@@ -154,6 +154,18 @@ func TestURLPath(t *testing.T) {
 	}
 }
 
+func TestURLRawQuery(t *testing.T) {
+	netURL, err := url.Parse("http://www.example.com/asdf?fruit=apple&veggie=a+b")
+	if err != nil {
+		t.Fatalf(`url.Parse("http://www.example.com/asdf?fruit=apple&veggie=a+b") got: %v want: nil`, err)
+	}
+
+	u := URL{url: netURL}
+	if got, want := u.RawQuery(), "fruit=apple&veggie=a+b"; got != want {
+		t.Errorf("u.RawQuery() got: %v want: %v", got, want)
+	}
+}
+
 func TestURLQuery(t *testing.T) {
 	var test = []struct {
 		name string
@@ -0,0 +1,90 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package safehttp
+
+import (
+	"context"
+	"sync"
+)
+
+// Key identifies a value stored in Values.
+//
+// Keys can only be created with NewKey and are compared by identity: two keys
+// created by separate NewKey calls never collide, even if they have the same
+// name. Packages should create their keys once, in a package-level variable.
+type Key struct {
+	k *keyData
+}
+
+type keyData struct {
+	name string
+}
+
+// NewKey creates a new Key. The name is only used for debugging purposes.
+func NewKey(name string) Key {
+	return Key{k: &keyData{name: name}}
+}
+
+// String returns the name the key was created with.
+func (k Key) String() string {
+	if k.k == nil {
+		return "<uninitialized key>"
+	}
+	return k.k.name
+}
+
+// Values is a typed key/value store with the lifetime of a single request.
+// It can be used by interceptors to pass values (e.g. the user identity) to
+// handlers.
+//
+// Values is safe for concurrent use.
+type Values struct {
+	mu sync.RWMutex
+	m  map[Key]interface{}
+}
+
+// Set stores v under the given key, replacing any previous value. It panics
+// if the key was not created with NewKey.
+func (vs *Values) Set(key Key, v interface{}) {
+	if key.k == nil {
+		panic("safehttp: Values.Set called with a Key not created by NewKey")
+	}
+	vs.mu.Lock()
+	defer vs.mu.Unlock()
+	if vs.m == nil {
+		vs.m = make(map[Key]interface{})
+	}
+	vs.m[key] = v
+}
+
+// Get returns the value stored under the given key and whether it was found.
+func (vs *Values) Get(key Key) (interface{}, bool) {
+	vs.mu.RLock()
+	defer vs.mu.RUnlock()
+	v, ok := vs.m[key]
+	return v, ok
+}
+
+type valuesCtxKey struct{}
+
+// ContextValues returns the Values of the request the context belongs to, so
+// that code which only has access to the context (e.g. a net/http handler
+// wrapped with RegisteredHandlerFromHTTP, or a storage layer) can read the
+// values set by interceptors. It returns nil if the context doesn't belong to
+// a request served by safehttp.
+func ContextValues(ctx context.Context) *Values {
+	vs, _ := ctx.Value(valuesCtxKey{}).(*Values)
+	return vs
+}
@@ -0,0 +1,200 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package safehttp_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/google/go-safeweb/safehttp"
+	"github.com/google/go-safeweb/safehttp/safehttptest"
+	"github.com/google/safehtml"
+)
+
+func TestValuesSetGet(t *testing.T) {
+	key := safehttp.NewKey("user")
+	r := safehttptest.NewRequest(safehttp.MethodGet, "/", nil)
+
+	r.Values().Set(key, "alice")
+
+	got, ok := r.Values().Get(key)
+	if !ok {
+		t.Fatalf("r.Values().Get(key) got ok=false, want true")
+	}
+	if want := "alice"; got != want {
+		t.Errorf("r.Values().Get(key) got %v, want %v", got, want)
+	}
+}
+
+func TestValuesMissingKey(t *testing.T) {
+	r := safehttptest.NewRequest(safehttp.MethodGet, "/", nil)
+
+	got, ok := r.Values().Get(safehttp.NewKey("missing"))
+	if ok || got != nil {
+		t.Errorf("r.Values().Get(missing) got (%v, %v), want (nil, false)", got, ok)
+	}
+}
+
+// Simulates two packages creating keys with the same name.
+var (
+	pkgAKey = safehttp.NewKey("user")
+	pkgBKey = safehttp.NewKey("user")
+)
+
+func TestValuesKeyCollision(t *testing.T) {
+	r := safehttptest.NewRequest(safehttp.MethodGet, "/", nil)
+
+	r.Values().Set(pkgAKey, "a")
+	if got, ok := r.Values().Get(pkgBKey); ok {
+		t.Errorf("r.Values().Get(pkgBKey) got (%v, true), want (nil, false)", got)
+	}
+	r.Values().Set(pkgBKey, "b")
+
+	if got, _ := r.Values().Get(pkgAKey); got != "a" {
+		t.Errorf("r.Values().Get(pkgAKey) got %v, want a", got)
+	}
+	if got, _ := r.Values().Get(pkgBKey); got != "b" {
+		t.Errorf("r.Values().Get(pkgBKey) got %v, want b", got)
+	}
+}
+
+func TestValuesUninitializedKey(t *testing.T) {
+	r := safehttptest.NewRequest(safehttp.MethodGet, "/", nil)
+	defer func() {
+		if recover() == nil {
+			t.Error("r.Values().Set(safehttp.Key{}, ...) expected panic")
+		}
+	}()
+	r.Values().Set(safehttp.Key{}, "value")
+}
+
+func TestValuesConcurrentAccess(t *testing.T) {
+	key := safehttp.NewKey("counter")
+	r := safehttptest.NewRequest(safehttp.MethodGet, "/", nil)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			r.Values().Set(key, i)
+			r.Values().Get(key)
+		}(i)
+	}
+	wg.Wait()
+
+	if _, ok := r.Values().Get(key); !ok {
+		t.Error("r.Values().Get(key) got ok=false, want true")
+	}
+}
+
+func TestValuesSharedWithContextCopies(t *testing.T) {
+	key := safehttp.NewKey("shared")
+	r := safehttptest.NewRequest(safehttp.MethodGet, "/", nil)
+	r2 := r.WithContext(context.Background())
+
+	r.Values().Set(key, "value")
+	if got, _ := r2.Values().Get(key); got != "value" {
+		t.Errorf("r2.Values().Get(key) got %v, want value", got)
+	}
+}
+
+var userKey = safehttp.NewKey("user")
+
+type userInterceptor struct{}
+
+func (userInterceptor) Before(w safehttp.ResponseWriter, r *safehttp.IncomingRequest, _ safehttp.InterceptorConfig) safehttp.Result {
+	r.Values().Set(userKey, "alice")
+	return safehttp.NotWritten()
+}
+
+func (userInterceptor) Commit(w safehttp.ResponseHeadersWriter, r *safehttp.IncomingRequest, resp safehttp.Response, _ safehttp.InterceptorConfig) {
+}
+
+func (userInterceptor) Match(safehttp.InterceptorConfig) bool {
+	return false
+}
+
+func TestValuesInterceptorToHandler(t *testing.T) {
+	mb := safehttp.NewServeMuxConfig(nil)
+	mb.Intercept(userInterceptor{})
+	mux := mb.Mux()
+	mux.Handle("/", safehttp.MethodGet, safehttp.HandlerFunc(func(w safehttp.ResponseWriter, r *safehttp.IncomingRequest) safehttp.Result {
+		u, ok := r.Values().Get(userKey)
+		if !ok {
+			return w.WriteError(safehttp.StatusUnauthorized)
+		}
+		return w.Write(safehtml.HTMLEscaped(u.(string)))
+	}))
+
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, httptest.NewRequest(safehttp.MethodGet, "http://foo.com/", nil))
+
+	if got, want := rr.Code, int(safehttp.StatusOK); got != want {
+		t.Errorf("rr.Code got %v, want %v", got, want)
+	}
+	if got, want := rr.Body.String(), "alice"; got != want {
+		t.Errorf("rr.Body got %q, want %q", got, want)
+	}
+}
+
+func TestIncomingRequestSetValue(t *testing.T) {
+	key := safehttp.NewKey("user")
+	r := safehttptest.NewRequest(safehttp.MethodGet, "/", nil)
+
+	if got := r.Value(key); got != nil {
+		t.Errorf("r.Value(key) before SetValue got %v, want nil", got)
+	}
+	r.SetValue(key, "alice")
+	if got, want := r.Value(key), "alice"; got != want {
+		t.Errorf("r.Value(key) got %v, want %v", got, want)
+	}
+	if got, _ := r.Values().Get(key); got != "alice" {
+		t.Errorf("r.Values().Get(key) got %v, want alice", got)
+	}
+}
+
+func TestContextValues(t *testing.T) {
+	mb := safehttp.NewServeMuxConfig(nil)
+	mb.Intercept(userInterceptor{})
+	mux := mb.Mux()
+	mux.Handle("/", safehttp.MethodGet, safehttp.RegisteredHandlerFromHTTP(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		vs := safehttp.ContextValues(r.Context())
+		if vs == nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		u, _ := vs.Get(userKey)
+		w.Header().Set("Content-Type", "text/plain")
+		fmt.Fprint(w, u)
+	})))
+
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, httptest.NewRequest(safehttp.MethodGet, "http://foo.com/", nil))
+
+	if got, want := rr.Body.String(), "alice"; got != want {
+		t.Errorf("rr.Body got %q, want %q", got, want)
+	}
+}
+
+func TestContextValuesOutsideRequest(t *testing.T) {
+	if got := safehttp.ContextValues(context.Background()); got != nil {
+		t.Errorf("safehttp.ContextValues(context.Background()) got %v, want nil", got)
+	}
+}
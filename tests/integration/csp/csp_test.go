@@ -16,12 +16,15 @@ package csp_test
 
 import (
 	"net/http/httptest"
+	"regexp"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-safeweb/safehttp"
 	"github.com/google/go-safeweb/safehttp/plugins/csp"
+	"github.com/google/go-safeweb/safehttp/plugins/htmlinject"
 	"github.com/google/safehtml/template"
+	"github.com/google/safehtml/template/uncheckedconversions"
 )
 
 func TestServeMuxInstallCSP(t *testing.T) {
@@ -83,3 +86,55 @@ func TestServeMuxInstallCSP(t *testing.T) {
 	}
 
 }
+
+func TestAutomaticNonces(t *testing.T) {
+	src := `{{define "layout"}}<script src="/app.js"></script><main>{{.Content}}</main>{{end}}` +
+		`{{define "page"}}<style>h1 {}</style><h1>{{.}}</h1>{{end}}`
+	// The "legacy" page isn't transformed by htmlinject.
+	tmpl := template.Must(htmlinject.LoadTrustedTemplate(nil, htmlinject.LoadConfig{DisableCSP: true},
+		uncheckedconversions.TrustedTemplateFromStringKnownToSatisfyTypeContract(`{{define "legacy"}}<script>a()</script>{{end}}`)))
+	tmpl = template.Must(htmlinject.LoadTrustedTemplate(tmpl, htmlinject.LoadConfig{},
+		uncheckedconversions.TrustedTemplateFromStringKnownToSatisfyTypeContract(src)))
+
+	tests := []struct {
+		name       string
+		nonceCheck csp.NonceCheck
+		page       string
+		wantBody   string
+	}{
+		{
+			name:     "htmlinject",
+			page:     "page",
+			wantBody: `<script nonce="{nonce}" src="/app.js"></script><main><style nonce="{nonce}">h1 {}</style><h1>Content</h1></main>`,
+		},
+		{
+			// NonceCheckInject only adds nonces in local development.
+			name:       "Not injected in production",
+			nonceCheck: csp.NonceCheckInject,
+			page:       "legacy",
+			wantBody:   `<script nonce="{nonce}" src="/app.js"></script><main><script>a()</script></main>`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mb := safehttp.NewServeMuxConfig(csp.NewNonceCheckDispatcher(nil, tt.nonceCheck))
+			mb.Intercept(csp.Interceptor{Policy: csp.StrictPolicy{}})
+			mux := mb.Mux()
+			mux.Handle("/", safehttp.MethodGet, safehttp.HandlerFunc(func(w safehttp.ResponseWriter, r *safehttp.IncomingRequest) safehttp.Result {
+				return safehttp.ExecuteNamedTemplateWithLayout(w, tmpl, "layout", tt.page, "Content")
+			}))
+
+			rr := httptest.NewRecorder()
+			mux.ServeHTTP(rr, httptest.NewRequest(safehttp.MethodGet, "https://foo.com/", nil))
+
+			m := regexp.MustCompile(`'nonce-([^']+)'`).FindStringSubmatch(rr.Header().Get("Content-Security-Policy"))
+			if m == nil {
+				t.Fatalf("Content-Security-Policy: got %q, want a nonce", rr.Header().Get("Content-Security-Policy"))
+			}
+			want := regexp.MustCompile(`\{nonce\}`).ReplaceAllLiteralString(tt.wantBody, m[1])
+			if got := rr.Body.String(); got != want {
+				t.Errorf("response body: got %q, want %q", got, want)
+			}
+		})
+	}
+}